@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/storage"
+)
+
+const (
+	integrityScanCheck  = "check"
+	integrityScanRepair = "repair"
+
+	// integrityScanBatchSize is the number of records checked at once, so the
+	// memory of the scan is bounded.
+	integrityScanBatchSize uint64 = 10000
+)
+
+// scanIntegrity checks the index records of the operations before the node
+// joins the consensus. With `repair`, the found issues are repaired by
+// every batch; otherwise the issues are reported and the node is not
+// started.
+func scanIntegrity(st *storage.LevelDBBackend, repair bool) error {
+	log.Info("checking the integrity of operations", "repair", repair)
+
+	var found, added, removed int
+	var cursor []byte
+	for {
+		issues, next, err := block.ScanBlockOperationIntegrity(st, cursor, integrityScanBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			log.Warn(
+				"inconsistent operation record",
+				"reason", issue.Reason,
+				"index", issue.Index,
+				"key", fmt.Sprintf("%x", issue.Key),
+				"hash", issue.Hash,
+			)
+		}
+		found += len(issues)
+
+		if repair && len(issues) > 0 {
+			a, r, err := block.RepairBlockOperationIntegrity(st, issues)
+			if err != nil {
+				return err
+			}
+			added += a
+			removed += r
+		}
+
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+
+	if found > 0 && !repair {
+		return fmt.Errorf(
+			"found %d inconsistent operation records; start with '--integrity-scan=%s' or run 'reindex'",
+			found,
+			integrityScanRepair,
+		)
+	}
+
+	log.Info("checked the integrity of operations", "found", found, "added", added, "removed", removed)
+
+	return nil
+}
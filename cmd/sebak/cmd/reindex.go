@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/storage"
+)
+
+func init() {
+	var reindexCmd = &cobra.Command{
+		Use:   "reindex",
+		Short: "rebuild the index records of operations; the node must be stopped",
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			storageConfig, err := storage.NewConfigFromString(flagStorageConfigString)
+			if err != nil {
+				cmdcommon.PrintFlagsError(c, "--storage", err)
+			}
+
+			st, err := storage.NewStorage(storageConfig)
+			if err != nil {
+				cmdcommon.PrintFlagsError(c, "--storage", fmt.Errorf("failed to initialize storage: %v", err))
+			}
+			defer st.Close()
+
+			added, removed, err := block.ReindexBlockOperations(st)
+			if err != nil {
+				cmdcommon.PrintError(c, err)
+			}
+
+			fmt.Printf("successfully reindexed operations; %d added, %d removed\n", added, removed)
+		},
+	}
+
+	reindexCmd.Flags().StringVar(&flagStorageConfigString, "storage", flagStorageConfigString, "storage uri")
+
+	rootCmd.AddCommand(reindexCmd)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/storage"
+)
+
+func init() {
+	var resetHeightCmd = &cobra.Command{
+		Use:   "reset-height <height>",
+		Short: "allow the blocks from the next of <height> to be stored again; the node must be stopped",
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			height, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				cmdcommon.PrintFlagsError(c, "<height>", err)
+			}
+
+			storageConfig, err := storage.NewConfigFromString(flagStorageConfigString)
+			if err != nil {
+				cmdcommon.PrintFlagsError(c, "--storage", err)
+			}
+
+			st, err := storage.NewStorage(storageConfig)
+			if err != nil {
+				cmdcommon.PrintFlagsError(c, "--storage", fmt.Errorf("failed to initialize storage: %v", err))
+			}
+			defer st.Close()
+
+			watermark, err := block.GetBlockHeightWatermark(st)
+			if err != nil {
+				cmdcommon.PrintError(c, err)
+			}
+			if err = block.ResetBlockHeightWatermark(st, height); err != nil {
+				cmdcommon.PrintError(c, err)
+			}
+
+			fmt.Printf("successfully reset the height watermark from %d to %d\n", watermark, height)
+		},
+	}
+
+	resetHeightCmd.Flags().StringVar(&flagStorageConfigString, "storage", flagStorageConfigString, "storage uri")
+
+	rootCmd.AddCommand(resetHeightCmd)
+}
@@ -12,6 +12,7 @@ import (
 	logging "github.com/inconshreveable/log15"
 	isatty "github.com/mattn/go-isatty"
 	"github.com/oklog/run"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/ulule/limiter"
 	"golang.org/x/net/http2"
@@ -26,6 +27,7 @@ import (
 	"boscoin.io/sebak/lib/node/runner"
 	"boscoin.io/sebak/lib/storage"
 	"boscoin.io/sebak/lib/sync"
+	"boscoin.io/sebak/lib/transaction/operation"
 )
 
 const (
@@ -35,17 +37,40 @@ const (
 )
 
 var (
+	flagAccountCheckpoint string = common.GetENVValue("SEBAK_ACCOUNT_CHECKPOINT_INTERVAL", "1000")
+	flagAllowedOps        string = common.GetENVValue("SEBAK_ALLOWED_OPERATIONS", "")
 	flagBindURL           string = common.GetENVValue("SEBAK_BIND", defaultBindURL)
 	flagBlockTime         string = common.GetENVValue("SEBAK_BLOCK_TIME", "5")
+	flagBroadcastFanout   string = common.GetENVValue("SEBAK_BROADCAST_FANOUT", "0")
+	flagCommonAccount     string = common.GetENVValue("SEBAK_COMMON_ACCOUNT", "")
 	flagDebugPProf        bool   = common.GetENVValue("SEBAK_DEBUG_PPROF", "0") == "1"
+	flagDeniedOps         string = common.GetENVValue("SEBAK_DENIED_OPERATIONS", "")
+	flagExpiredVotes      string = common.GetENVValue("SEBAK_EXPIRED_VOTES_THRESHOLD", "0")
+	flagFeeBurnRatio      string = common.GetENVValue("SEBAK_FEE_BURN_RATIO", "0")
+	flagGenesisTime       string = common.GetENVValue("SEBAK_GENESIS_TIME", "")
+	flagMaxClockSkew      string = common.GetENVValue("SEBAK_MAX_CLOCK_SKEW", common.DefaultMaxClockSkew.String())
+	flagMaxMessageSize    string = common.GetENVValue("SEBAK_MAX_MESSAGE_SIZE", strconv.Itoa(common.DefaultMaxMessageSize))
+	flagMaxSupply         string = common.GetENVValue("SEBAK_MAX_SUPPLY", "")
+	flagInitialBalance    string = common.GetENVValue("SEBAK_INITIAL_BALANCE", "")
+	flagIntegrityScan     string = common.GetENVValue("SEBAK_INTEGRITY_SCAN", "")
 	flagKPSecretSeed      string = common.GetENVValue("SEBAK_SECRET_SEED", "")
 	flagLog               string = common.GetENVValue("SEBAK_LOG", "")
 	flagLogLevel          string = common.GetENVValue("SEBAK_LOG_LEVEL", defaultLogLevel.String())
 	flagLogFormat         string = common.GetENVValue("SEBAK_LOG_FORMAT", defaultLogFormat)
+	flagMaxBlockWeight    string = common.GetENVValue("SEBAK_MAX_BLOCK_WEIGHT", "0")
+	flagMaxOpsPerBlock    string = common.GetENVValue("SEBAK_MAX_OPS_PER_BLOCK", "10000")
 	flagNetworkID         string = common.GetENVValue("SEBAK_NETWORK_ID", "")
 	flagOperationsLimit   string = common.GetENVValue("SEBAK_OPERATIONS_LIMIT", "1000")
+	flagProposalFailures  string = common.GetENVValue("SEBAK_MAX_PROPOSAL_FAILURES", "0")
+	flagProposalDeadline  string = common.GetENVValue("SEBAK_PROPOSAL_DEADLINE", common.DefaultProposalDeadline.String())
+	flagFinality          string = common.GetENVValue("SEBAK_FINALITY_CONFIRMATIONS", "0")
+	flagProposerJitter    string = common.GetENVValue("SEBAK_PROPOSER_JITTER", common.DefaultProposerJitter.String())
+	flagProposerLiveness  string = common.GetENVValue("SEBAK_PROPOSER_LIVENESS_THRESHOLD", "0")
 	flagPublishURL        string = common.GetENVValue("SEBAK_PUBLISH", "")
+	flagRetainedBlocks    string = common.GetENVValue("SEBAK_RETAINED_BLOCKS", "0")
+	flagStorageCodec      string = common.GetENVValue("SEBAK_STORAGE_CODEC", string(common.DefaultStorageCodec))
 	flagSyncCheckInterval string = common.GetENVValue("SEBAK_SYNC_CHECK_INTERVAL", "30s")
+	flagSyncOnCommit      bool   = common.GetENVValue("SEBAK_SYNC_ON_COMMIT", "1") == "1"
 	flagSyncFetchTimeout  string = common.GetENVValue("SEBAK_SYNC_FETCH_TIMEOUT", "1m")
 	flagSyncPoolSize      string = common.GetENVValue("SEBAK_SYNC_POOL_SIZE", "300")
 	flagSyncRetryInterval string = common.GetENVValue("SEBAK_SYNC_RETRY_INTERVAL", "10s")
@@ -56,9 +81,12 @@ var (
 	flagTLSCertFile       string = common.GetENVValue("SEBAK_TLS_CERT", "sebak.crt")
 	flagTLSKeyFile        string = common.GetENVValue("SEBAK_TLS_KEY", "sebak.key")
 	flagTransactionsLimit string = common.GetENVValue("SEBAK_TRANSACTIONS_LIMIT", "1000")
+	flagTxSelection       string = common.GetENVValue("SEBAK_TRANSACTION_SELECTION_POLICY", string(common.DefaultTransactionSelectionPolicy))
 	flagUnfreezingPeriod  string = common.GetENVValue("SEBAK_UNFREEZING_PERIOD", "241920")
 	flagValidators        string = common.GetENVValue("SEBAK_VALIDATORS", "")
 	flagVerbose           bool   = common.GetENVValue("SEBAK_VERBOSE", "0") == "1"
+	flagWriteBufferBlocks string = common.GetENVValue("SEBAK_WRITE_BUFFER_BLOCKS", "0")
+	flagWriteBufferTime   string = common.GetENVValue("SEBAK_WRITE_BUFFER_INTERVAL", common.DefaultWriteBufferInterval.String())
 
 	flagRateLimitAPI        cmdcommon.ListFlags // "SEBAK_RATE_LIMIT_API"
 	flagRateLimitNode       cmdcommon.ListFlags // "SEBAK_RATE_LIMIT_NODE"
@@ -68,14 +96,32 @@ var (
 var (
 	nodeCmd *cobra.Command
 
+	accountCheckpoint uint64
 	bindEndpoint      *common.Endpoint
 	blockTime         time.Duration
+	broadcastFanout   uint64
+	expiredVotes      uint64
+	feeBurnRatio      uint64
+	genesisTime       time.Time
 	kp                *keypair.Full
 	localNode         *node.LocalNode
+	maxBlockWeight    uint64
+	maxClockSkew      time.Duration
+	maxMessageSize    uint64
+	maxSupply         common.Amount
+	maxOpsPerBlock    uint64
+	networkParams     common.NetworkParams
+	operationFilter   common.OperationFilter
 	operationsLimit   uint64
+	proposalFailures  uint64
+	proposalDeadline  time.Duration
+	finality          uint64
+	proposerJitter    time.Duration
+	proposerLiveness  uint64
 	publishEndpoint   *common.Endpoint
 	rateLimitRuleAPI  common.RateLimitRule
 	rateLimitRuleNode common.RateLimitRule
+	retainedBlocks    uint64
 	storageConfig     *storage.Config
 	syncCheckInterval time.Duration
 	syncFetchTimeout  time.Duration
@@ -87,6 +133,8 @@ var (
 	timeoutSIGN       time.Duration
 	transactionsLimit uint64
 	validators        []*node.Validator
+	writeBufferBlocks uint64
+	writeBufferTime   time.Duration
 
 	logLevel logging.Lvl
 	log      logging.Logger = logging.New("module", "main")
@@ -144,6 +192,10 @@ func init() {
 	nodeCmd.Flags().StringVar(&flagGenesis, "genesis", flagGenesis, "performs the 'genesis' command before running node. Syntax: key[,balance]")
 	nodeCmd.Flags().StringVar(&flagKPSecretSeed, "secret-seed", flagKPSecretSeed, "secret seed of this node")
 	nodeCmd.Flags().StringVar(&flagNetworkID, "network-id", flagNetworkID, "network id")
+	nodeCmd.Flags().StringVar(&flagCommonAccount, "common-account", flagCommonAccount, "address of common account; if given, it must match with the genesis block")
+	nodeCmd.Flags().StringVar(&flagInitialBalance, "initial-balance", flagInitialBalance, "balance of genesis account; if given, it must match with the genesis block")
+	nodeCmd.Flags().StringVar(&flagMaxSupply, "max-supply", flagMaxSupply, "maximum supply, after which the inflation is omitted; empty means no cap")
+	nodeCmd.Flags().StringVar(&flagFeeBurnRatio, "fee-burn-ratio", flagFeeBurnRatio, fmt.Sprintf("ratio of the collected fee to burn in basis points of %d; 0 means no burn", common.FeeBurnRatioBase))
 	nodeCmd.Flags().StringVar(&flagLogLevel, "log-level", flagLogLevel, "log level, {crit, error, warn, info, debug}")
 	nodeCmd.Flags().StringVar(&flagLogFormat, "log-format", flagLogFormat, "log format, {terminal, json}")
 	nodeCmd.Flags().StringVar(&flagLog, "log", flagLog, "set log file")
@@ -160,8 +212,30 @@ func init() {
 	nodeCmd.Flags().StringVar(&flagTimeoutACCEPT, "timeout-accept", flagTimeoutACCEPT, "timeout of the accept state")
 	nodeCmd.Flags().StringVar(&flagBlockTime, "block-time", flagBlockTime, "block creation time")
 	nodeCmd.Flags().StringVar(&flagTransactionsLimit, "transactions-limit", flagTransactionsLimit, "transactions limit in a ballot")
+	nodeCmd.Flags().StringVar(&flagTxSelection, "transaction-selection-policy", flagTxSelection, "order of the transactions in a ballot: 'fee-rate' or 'fifo'")
 	nodeCmd.Flags().StringVar(&flagUnfreezingPeriod, "unfreezing-period", flagUnfreezingPeriod, "how long freezing must last")
 	nodeCmd.Flags().StringVar(&flagOperationsLimit, "operations-limit", flagOperationsLimit, "operations limit in a transaction")
+	nodeCmd.Flags().StringVar(&flagMaxOpsPerBlock, "max-ops-per-block", flagMaxOpsPerBlock, "operations limit in a block; 0 means no limit")
+	nodeCmd.Flags().StringVar(&flagMaxBlockWeight, "max-block-weight", flagMaxBlockWeight, "sum of the operation weights limit in a block; 0 means no limit")
+	nodeCmd.Flags().StringVar(&flagMaxMessageSize, "max-message-size", flagMaxMessageSize, "size limit of the inbound message in bytes; 0 means no limit")
+	nodeCmd.Flags().StringVar(&flagBroadcastFanout, "broadcast-fanout", flagBroadcastFanout, "number of validators to send the transaction at once; 0 sends to all. ballots are always sent to all")
+	nodeCmd.Flags().StringVar(&flagAccountCheckpoint, "account-checkpoint-interval", flagAccountCheckpoint, "number of blocks between the account-state checkpoints; 0 disables")
+	nodeCmd.Flags().StringVar(&flagExpiredVotes, "expired-votes-threshold", flagExpiredVotes, "number of EXP votes to increase the round before timeout; 0 disables")
+	nodeCmd.Flags().StringVar(&flagGenesisTime, "genesis-time", flagGenesisTime, "ISO8601 time to override the timestamp of genesis block for the average block time; empty uses genesis block")
+	nodeCmd.Flags().StringVar(&flagMaxClockSkew, "max-clock-skew", flagMaxClockSkew, "allowed clock difference of validators to be reported as skewed; 0 disables")
+	nodeCmd.Flags().StringVar(&flagProposerJitter, "proposer-jitter", flagProposerJitter, "maximum jitter of the proposer before proposing; 0 disables")
+	nodeCmd.Flags().StringVar(&flagProposerLiveness, "proposer-liveness-threshold", flagProposerLiveness, "number of consecutive EXPs to skip the proposer; 0 disables")
+	nodeCmd.Flags().StringVar(&flagRetainedBlocks, "retained-blocks", flagRetainedBlocks, "number of recent blocks to keep the transactions and operations; 0 keeps all")
+	nodeCmd.Flags().BoolVar(&flagSyncOnCommit, "sync-on-commit", flagSyncOnCommit, "sync the confirmed block to the disk before the next height")
+	nodeCmd.Flags().StringVar(&flagWriteBufferBlocks, "write-buffer-blocks", flagWriteBufferBlocks, "number of blocks to buffer the operations before writing; 0 writes by every block")
+	nodeCmd.Flags().StringVar(&flagWriteBufferTime, "write-buffer-interval", flagWriteBufferTime, "maximum duration to buffer the operations before writing")
+	nodeCmd.Flags().StringVar(&flagProposalFailures, "max-proposal-failures", flagProposalFailures, "number of consecutive proposal failures to halt the consensus; 0 never halts")
+	nodeCmd.Flags().StringVar(&flagProposalDeadline, "proposal-deadline", flagProposalDeadline, "deadline of making the proposal ballot, after which the proposer votes EXP; 0 disables")
+	nodeCmd.Flags().StringVar(&flagFinality, "finality-confirmations", flagFinality, "number of following blocks to notify the block as final")
+	nodeCmd.Flags().StringVar(&flagStorageCodec, "storage-codec", flagStorageCodec, "encoding of the operations in the storage: 'json' or 'msgpack'")
+	nodeCmd.Flags().StringVar(&flagIntegrityScan, "integrity-scan", flagIntegrityScan, "check the index records of operations before starting: 'check' or 'repair'")
+	nodeCmd.Flags().StringVar(&flagAllowedOps, "allowed-operations", flagAllowedOps, "comma separated operation types to accept; empty accepts all")
+	nodeCmd.Flags().StringVar(&flagDeniedOps, "denied-operations", flagDeniedOps, "comma separated operation types to reject")
 	nodeCmd.Flags().Var(
 		&flagRateLimitAPI,
 		"rate-limit-api",
@@ -270,6 +344,23 @@ func parseFlagsNode() {
 		kp = parsedKP.(*keypair.Full)
 	}
 
+	if len(flagCommonAccount) > 0 {
+		if _, err = keypair.Parse(flagCommonAccount); err != nil {
+			cmdcommon.PrintFlagsError(nodeCmd, "--common-account", err)
+		}
+		networkParams.CommonAccount = flagCommonAccount
+	}
+	if len(flagInitialBalance) > 0 {
+		if networkParams.InitialBalance, err = cmdcommon.ParseAmountFromString(flagInitialBalance); err != nil {
+			cmdcommon.PrintFlagsError(nodeCmd, "--initial-balance", err)
+		}
+	}
+	if len(flagMaxSupply) > 0 {
+		if maxSupply, err = cmdcommon.ParseAmountFromString(flagMaxSupply); err != nil {
+			cmdcommon.PrintFlagsError(nodeCmd, "--max-supply", err)
+		}
+	}
+
 	if p, err := common.ParseEndpoint(flagBindURL); err != nil {
 		cmdcommon.PrintFlagsError(nodeCmd, "--bind", err)
 	} else {
@@ -314,6 +405,12 @@ func parseFlagsNode() {
 		cmdcommon.PrintFlagsError(nodeCmd, "--storage", err)
 	}
 
+	switch flagIntegrityScan {
+	case "", integrityScanCheck, integrityScanRepair:
+	default:
+		cmdcommon.PrintFlagsError(nodeCmd, "--integrity-scan", fmt.Errorf("unknown mode: %q", flagIntegrityScan))
+	}
+
 	timeoutINIT = getTime(flagTimeoutINIT, 2*time.Second, "--timeout-init")
 	timeoutSIGN = getTime(flagTimeoutSIGN, 2*time.Second, "--timeout-sign")
 	timeoutACCEPT = getTime(flagTimeoutACCEPT, 2*time.Second, "--timeout-accept")
@@ -327,6 +424,56 @@ func parseFlagsNode() {
 		cmdcommon.PrintFlagsError(nodeCmd, "--operations-limit", err)
 	}
 
+	if maxOpsPerBlock, err = strconv.ParseUint(flagMaxOpsPerBlock, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--max-ops-per-block", err)
+	}
+
+	if maxBlockWeight, err = strconv.ParseUint(flagMaxBlockWeight, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--max-block-weight", err)
+	}
+
+	if maxMessageSize, err = strconv.ParseUint(flagMaxMessageSize, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--max-message-size", err)
+	}
+
+	if feeBurnRatio, err = strconv.ParseUint(flagFeeBurnRatio, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--fee-burn-ratio", err)
+	} else if feeBurnRatio > common.FeeBurnRatioBase {
+		cmdcommon.PrintFlagsError(nodeCmd, "--fee-burn-ratio", fmt.Errorf("must not be over %d", common.FeeBurnRatioBase))
+	}
+
+	if retainedBlocks, err = strconv.ParseUint(flagRetainedBlocks, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--retained-blocks", err)
+	}
+
+	if writeBufferBlocks, err = strconv.ParseUint(flagWriteBufferBlocks, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--write-buffer-blocks", err)
+	}
+
+	if proposalFailures, err = strconv.ParseUint(flagProposalFailures, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--max-proposal-failures", err)
+	}
+
+	if finality, err = strconv.ParseUint(flagFinality, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--finality-confirmations", err)
+	}
+
+	if proposerLiveness, err = strconv.ParseUint(flagProposerLiveness, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--proposer-liveness-threshold", err)
+	}
+
+	if expiredVotes, err = strconv.ParseUint(flagExpiredVotes, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--expired-votes-threshold", err)
+	}
+
+	if broadcastFanout, err = strconv.ParseUint(flagBroadcastFanout, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--broadcast-fanout", err)
+	}
+
+	if accountCheckpoint, err = strconv.ParseUint(flagAccountCheckpoint, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--account-checkpoint-interval", err)
+	}
+
 	var tmpUint64 uint64
 	if tmpUint64, err = strconv.ParseUint(flagThreshold, 10, 64); err != nil {
 		cmdcommon.PrintFlagsError(nodeCmd, "--threshold", err)
@@ -345,6 +492,31 @@ func parseFlagsNode() {
 	syncRetryInterval = getTimeDuration(flagSyncRetryInterval, sync.RetryInterval, "--sync-retry-interval")
 	syncFetchTimeout = getTimeDuration(flagSyncFetchTimeout, sync.FetchTimeout, "--sync-fetch-timeout")
 	syncCheckInterval = getTimeDuration(flagSyncCheckInterval, sync.CheckBlockHeightInterval, "--sync-check-interval")
+	maxClockSkew = getTimeDuration(flagMaxClockSkew, common.DefaultMaxClockSkew, "--max-clock-skew")
+	proposerJitter = getTimeDuration(flagProposerJitter, common.DefaultProposerJitter, "--proposer-jitter")
+	proposalDeadline = getTimeDuration(flagProposalDeadline, common.DefaultProposalDeadline, "--proposal-deadline")
+	writeBufferTime = getTimeDuration(flagWriteBufferTime, common.DefaultWriteBufferInterval, "--write-buffer-interval")
+
+	operationFilter = common.OperationFilter{
+		Allowed: common.ParseOperationTypes(flagAllowedOps),
+		Denied:  common.ParseOperationTypes(flagDeniedOps),
+	}
+	for flagName, operationTypes := range map[string][]string{
+		"--allowed-operations": operationFilter.Allowed,
+		"--denied-operations":  operationFilter.Denied,
+	} {
+		for _, t := range operationTypes {
+			if _, found := operation.KindsNormalTransaction[operation.OperationType(t)]; !found {
+				cmdcommon.PrintFlagsError(nodeCmd, flagName, fmt.Errorf("unknown operation type: %q", t))
+			}
+		}
+	}
+
+	if len(flagGenesisTime) > 0 {
+		if genesisTime, err = common.ParseISO8601(flagGenesisTime); err != nil {
+			cmdcommon.PrintFlagsError(nodeCmd, "--genesis-time", err)
+		}
+	}
 
 	if logLevel, err = logging.LvlFromString(flagLogLevel); err != nil {
 		cmdcommon.PrintFlagsError(nodeCmd, "--log-level", err)
@@ -500,6 +672,7 @@ func runNode() error {
 		nt,
 		policy,
 	)
+	connectionManager.(*network.ValidatorConnectionManager).SetFanout(int(broadcastFanout))
 
 	conf := common.Config{
 		TimeoutINIT:       timeoutINIT,
@@ -508,15 +681,70 @@ func runNode() error {
 		BlockTime:         blockTime,
 		TxsLimit:          int(transactionsLimit),
 		OpsLimit:          int(operationsLimit),
+		MaxOpsPerBlock:    int(maxOpsPerBlock),
 		RateLimitRuleAPI:  rateLimitRuleAPI,
 		RateLimitRuleNode: rateLimitRuleNode,
+
+		BallotProposedTimeTolerance: common.BallotConfirmedTimeAllowDuration,
+		InflationSchedule:           common.DefaultInflationSchedule,
+		InflationPolicy:             common.InflationPolicy{MaxSupply: maxSupply},
+		FeeBurnPolicy:               common.FeeBurnPolicy{Ratio: feeBurnRatio},
+		MaxOperationBodySize:        common.DefaultMaxOperationBodySize,
+		MaxTransactionSize:          common.DefaultMaxTransactionSize,
+		MaxMessageSize:              int(maxMessageSize),
+		MaxAccountDataNameSize:      common.DefaultMaxAccountDataNameSize,
+		MaxAccountDataValueSize:     common.DefaultMaxAccountDataValueSize,
+		HealthStaleWindow:           common.DefaultHealthStaleWindow,
+		IdempotencyWindow:           common.DefaultIdempotencyWindow,
+		MaxTransactionsInBatch:      common.DefaultMaxTransactionsInBatch,
+		MinFeeBump:                  common.DefaultMinFeeBump,
+		FeePolicy:                   common.DefaultFeePolicy,
+		OperationWeights:            common.DefaultOperationWeights,
+		MaxBlockWeight:              maxBlockWeight,
+		TransactionSelectionPolicy:  common.TransactionSelectionPolicy(flagTxSelection),
+		RetainedBlocks:              retainedBlocks,
+		ProposerLivenessThreshold:   proposerLiveness,
+		AccountCheckpointInterval:   accountCheckpoint,
+		ExpiredVotesThreshold:       expiredVotes,
+		MaxClockSkew:                maxClockSkew,
+		ProposerJitter:              proposerJitter,
+		SyncOnCommit:                flagSyncOnCommit,
+		WriteBufferBlocks:           writeBufferBlocks,
+		WriteBufferInterval:         writeBufferTime,
+		GenesisTime:                 genesisTime,
+		StorageCodec:                common.StorageCodec(flagStorageCodec),
+		MaxProposalFailures:         proposalFailures,
+		ProposalDeadline:            proposalDeadline,
+		OperationFilter:             operationFilter,
+		FinalityConfirmations:       finality,
+		ReconnectPolicy:             common.DefaultReconnectPolicy,
+		NetworkParams:               networkParams,
+	}
+	confWarnings, err := conf.Validate()
+	if err != nil {
+		log.Crit("invalid config", "error", err)
+		return err
 	}
+	for _, w := range confWarnings {
+		log.Warn("check config", "warning", w)
+	}
+
+	connectionManager.(*network.ValidatorConnectionManager).SetReconnectPolicy(conf.ReconnectPolicy)
+	connectionManager.(*network.ValidatorConnectionManager).SetMaxClockSkew(conf.MaxClockSkew)
+
 	st, err := storage.NewStorage(storageConfig)
 	if err != nil {
 		log.Crit("failed to initialize storage", "error", err)
 		return err
 	}
 
+	if len(flagIntegrityScan) > 0 {
+		if err := scanIntegrity(st, flagIntegrityScan == integrityScanRepair); err != nil {
+			log.Crit("failed to check the integrity of storage", "error", err)
+			return err
+		}
+	}
+
 	c := sync.NewConfig([]byte(flagNetworkID), localNode, st, nt, connectionManager, conf)
 	//Place setting config
 	c.SyncPoolSize = syncPoolSize
@@ -541,6 +769,26 @@ func runNode() error {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			return err
 		}
+		syncer.SetBlockFinisher(nr)
+
+		if err := consensus.ValidateThreshold(policy); err != nil {
+			log.Crit(
+				"threshold is unsafe for validators",
+				"threshold", policy.Threshold(),
+				"required", consensus.BFTRequiredThreshold(policy.Validators()),
+				"validators", policy.Validators(),
+			)
+			return err
+		}
+
+		if err := prometheus.Register(nr.ISAACStateManager().Metrics()); err != nil {
+			log.Crit("failed to register metrics", "error", err)
+			return err
+		}
+		if err := prometheus.Register(nr.MessageMetrics()); err != nil {
+			log.Crit("failed to register metrics", "error", err)
+			return err
+		}
 
 		g.Add(func() error {
 			if err := nr.Start(); err != nil {
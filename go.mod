@@ -1,52 +1,60 @@
 module boscoin.io/sebak
 
+go 1.27.1
+
 require (
 	github.com/GianlucaGuarini/go-observable v0.0.0-20180829201609-d386f0081a66
+	github.com/btcsuite/btcutil v0.0.0-20170726183619-501929d3d046
+	github.com/ethereum/go-ethereum v1.8.13
+	github.com/google/uuid v0.0.0-20161128191214-064e2069ce9c
+	github.com/gorilla/handlers v1.4.0
+	github.com/gorilla/mux v1.6.2
+	github.com/gorilla/websocket v1.4.0
+	github.com/inconshreveable/log15 v0.0.0-20180818164646-67afb5ed74ec
+	github.com/mattn/go-isatty v0.0.3
+	github.com/nvellon/hal v0.3.0
+	github.com/oklog/run v1.0.0
+	github.com/pkg/errors v0.8.0
+	github.com/prometheus/client_golang v0.8.0
+	github.com/satori/go.uuid v1.2.0
+	github.com/spf13/cobra v0.0.3
+	github.com/spf13/pflag v1.0.1
+	github.com/stellar/go v0.0.0-20180501231346-87a45bf9f03d
+	github.com/stretchr/testify v1.2.2
+	github.com/syndtr/goleveldb v0.0.0-20180331014930-714f901b98fd
+	github.com/ulule/limiter v2.2.0+incompatible
+	github.com/vmihailenco/msgpack v4.0.4+incompatible
+	golang.org/x/net v0.0.0-20180420171651-5f9ae10d9af5
+	gopkg.in/yaml.v2 v2.2.1
+)
+
+require (
 	github.com/agl/ed25519 v0.0.0-20170116200512-5312a6153412 // indirect
 	github.com/beorn7/perks v0.0.0-20180321164747-3a771d992973 // indirect
 	github.com/btcsuite/btcd v0.0.0-20180810000619-f899737d7f27 // indirect
-	github.com/btcsuite/btcutil v0.0.0-20170726183619-501929d3d046
 	github.com/davecgh/go-spew v1.1.0 // indirect
-	github.com/ethereum/go-ethereum v1.8.13
 	github.com/fsnotify/fsnotify v1.4.7 // indirect
 	github.com/go-stack/stack v1.7.0 // indirect
 	github.com/golang/protobuf v1.2.0 // indirect
 	github.com/golang/snappy v0.0.0-20170215233205-553a64147049 // indirect
-	github.com/google/uuid v0.0.0-20161128191214-064e2069ce9c
 	github.com/gorilla/context v1.1.1 // indirect
-	github.com/gorilla/handlers v1.4.0
-	github.com/gorilla/mux v1.6.2
 	github.com/hpcloud/tail v1.0.0 // indirect
-	github.com/inconshreveable/log15 v0.0.0-20180818164646-67afb5ed74ec
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/lib/pq v0.0.0-20180327071824-d34b9ff171c2 // indirect
 	github.com/mattn/go-colorable v0.0.9 // indirect
-	github.com/mattn/go-isatty v0.0.3
 	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
 	github.com/nullstyle/go-xdr v0.0.0-20170810174627-a875e7c9fa23 // indirect
-	github.com/nvellon/hal v0.3.0
-	github.com/oklog/run v1.0.0
 	github.com/onsi/ginkgo v1.6.0 // indirect
 	github.com/onsi/gomega v1.4.1 // indirect
-	github.com/pkg/errors v0.8.0
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/prometheus/client_golang v0.8.0
 	github.com/prometheus/client_model v0.0.0-20180712105110-5c3871d89910 // indirect
 	github.com/prometheus/common v0.0.0-20180801064454-c7de2306084e // indirect
 	github.com/prometheus/procfs v0.0.0-20180725123919-05ee40e3a273 // indirect
-	github.com/satori/go.uuid v1.2.0
-	github.com/spf13/cobra v0.0.3
-	github.com/spf13/pflag v1.0.1
-	github.com/stellar/go v0.0.0-20180501231346-87a45bf9f03d
-	github.com/stretchr/testify v1.2.2
-	github.com/syndtr/goleveldb v0.0.0-20180331014930-714f901b98fd
-	github.com/ulule/limiter v2.2.0+incompatible
-	golang.org/x/net v0.0.0-20180420171651-5f9ae10d9af5
 	golang.org/x/sync v0.0.0-20180314180146-1d60e4601c6f // indirect
 	golang.org/x/sys v0.0.0-20180501092740-78d5f264b493 // indirect
 	golang.org/x/text v0.3.0 // indirect
+	gopkg.in/check.v1 v0.0.0-20161208181325-20d25e280405 // indirect
 	gopkg.in/fsnotify.v1 v1.4.7 // indirect
 	gopkg.in/karalabe/cookiejar.v2 v2.0.0-20150724131613-8dcd6a7f4951 // indirect
 	gopkg.in/tomb.v1 v1.0.0-20141024135613-dd632973f1e7 // indirect
-	gopkg.in/yaml.v2 v2.2.1
 )
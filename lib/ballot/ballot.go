@@ -1,6 +1,7 @@
 package ballot
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
 	"time"
 
@@ -12,6 +13,12 @@ import (
 	"boscoin.io/sebak/lib/voting"
 )
 
+// BallotVersion is the current version of ballot serialization. The ballot
+// without version is treated as version 1, which is hashed by the body only
+// like the nodes before the version; from version 2, the version is covered
+// by the signature, so it must be increased only with the change of protocol.
+const BallotVersion uint = 1
+
 type Ballot struct {
 	H BallotHeader
 	B BallotBody
@@ -34,7 +41,7 @@ func NewBallot(fromAddr string, proposerAddr string, basis voting.Basis, transac
 	}
 
 	b = &Ballot{
-		H: BallotHeader{},
+		H: BallotHeader{Version: BallotVersion},
 		B: body,
 	}
 
@@ -43,12 +50,29 @@ func NewBallot(fromAddr string, proposerAddr string, basis voting.Basis, transac
 
 func NewBallotFromJSON(data []byte) (b Ballot, err error) {
 	if err = json.Unmarshal(data, &b); err != nil {
+		// the body of future version may not be decoded, so the version is
+		// checked thru the header only.
+		var h struct{ H BallotHeader }
+		if json.Unmarshal(data, &h) == nil && !IsSupportedBallotVersion(h.H.Version) {
+			err = errors.BallotUnsupportedVersion
+		}
+		return
+	}
+
+	if !IsSupportedBallotVersion(b.H.Version) {
+		err = errors.BallotUnsupportedVersion
 		return
 	}
 
 	return
 }
 
+// IsSupportedBallotVersion checks the ballot of the given version can be
+// handled by this node.
+func IsSupportedBallotVersion(version uint) bool {
+	return version <= BallotVersion
+}
+
 func (b Ballot) GetType() common.MessageType {
 	return common.BallotMessage
 }
@@ -57,6 +81,37 @@ func (b Ballot) GetHash() string {
 	return b.H.Hash
 }
 
+// Version returns the serialization version of ballot; the ballot without
+// version is version 1.
+func (b Ballot) Version() uint {
+	if b.H.Version == 0 {
+		return 1
+	}
+
+	return b.H.Version
+}
+
+// MakeHashString makes the hash of `BallotBody` with the version. The version
+// 1 keeps the hash of the body only, so the signature of the previous ballots
+// still can be verified; the version 1 ballot can not be changed into the
+// other version, because the hash of the other version is different.
+// `BallotBody.ExpiredReason` is mixed only when it is set, so the ballot
+// without the reason keeps the previous hash.
+func (b Ballot) MakeHashString() string {
+	var hash string
+	if b.Version() == 1 {
+		hash = b.B.MakeHashString()
+	} else {
+		hash = base58.Encode(common.MustMakeObjectHash([]interface{}{b.H.Version, b.B}))
+	}
+
+	if len(b.B.ExpiredReason) < 1 {
+		return hash
+	}
+
+	return base58.Encode(common.MustMakeObjectHash([]interface{}{hash, string(b.B.ExpiredReason)}))
+}
+
 func (b Ballot) Serialize() (encoded []byte, err error) {
 	encoded, err = json.Marshal(b)
 	return
@@ -68,12 +123,18 @@ func (b Ballot) String() string {
 }
 
 func (b Ballot) IsWellFormed(networkID []byte, conf common.Config) (err error) {
-	if err = b.isBallotWellFormed(networkID, conf); err != nil {
+	return b.IsWellFormedWithCache(networkID, conf, nil)
+}
+
+// IsWellFormedWithCache is same with `IsWellFormed`, but the signature
+// verifications are skipped if they are found in `SignatureCache`.
+func (b Ballot) IsWellFormedWithCache(networkID []byte, conf common.Config, cache *SignatureCache) (err error) {
+	if err = b.isBallotWellFormed(networkID, conf, cache); err != nil {
 		return
 	}
 
 	if b.Vote() != voting.EXP {
-		if err = b.isProposerInfoWellFormed(networkID, conf); err != nil {
+		if err = b.isProposerInfoWellFormed(networkID, conf, cache); err != nil {
 			return
 		}
 	}
@@ -81,7 +142,12 @@ func (b Ballot) IsWellFormed(networkID []byte, conf common.Config) (err error) {
 	return
 }
 
-func (b Ballot) isBallotWellFormed(networkID []byte, conf common.Config) (err error) {
+func (b Ballot) isBallotWellFormed(networkID []byte, conf common.Config, cache *SignatureCache) (err error) {
+	if !IsSupportedBallotVersion(b.H.Version) {
+		err = errors.BallotUnsupportedVersion
+		return
+	}
+
 	if b.TransactionsLength() > conf.TxsLimit {
 		err = errors.BallotHasOverMaxTransactionsInBallot
 		return
@@ -92,45 +158,80 @@ func (b Ballot) isBallotWellFormed(networkID []byte, conf common.Config) (err er
 		return
 	}
 
+	if len(b.B.ExpiredReason) > 0 {
+		if !b.B.ExpiredReason.IsValid() || b.Vote() != voting.EXP {
+			err = errors.BallotInvalidExpiredReason
+			return
+		}
+	}
+
+	// the signature is of `Hash`, so the version and the reason are covered
+	// by the signature only when `Hash` is made with them
+	if b.H.Hash != b.MakeHashString() {
+		err = errors.HashDoesNotMatch
+		return
+	}
+
 	var confirmed time.Time
 	if confirmed, err = common.ParseISO8601(b.B.Confirmed); err != nil {
 		return
 	}
 	now := time.Now()
-	timeStart := now.Add(time.Duration(-1) * common.BallotConfirmedTimeAllowDuration)
-	timeEnd := now.Add(common.BallotConfirmedTimeAllowDuration)
+	timeStart := now.Add(time.Duration(-1) * conf.BallotProposedTimeTolerance)
+	timeEnd := now.Add(conf.BallotProposedTimeTolerance)
 	if confirmed.Before(timeStart) || confirmed.After(timeEnd) {
 		err = errors.MessageHasIncorrectTime
 		return
 	}
 
-	if err = b.VerifySource(networkID); err != nil {
+	key := makeSignatureCacheKey(networkID, b.B.Source, b.H.Hash, b.H.Signature)
+	verify := func() error { return b.VerifySource(networkID) }
+	if err = cache.verify(b.VotingBasis().Height, key, verify); err != nil {
 		return
 	}
 
 	return
 }
 
-func (b Ballot) isProposerInfoWellFormed(networkID []byte, conf common.Config) (err error) {
-	var proposerConfirmed time.Time
-	if proposerConfirmed, err = common.ParseISO8601(b.ProposerConfirmed()); err != nil {
+func (b Ballot) isProposerInfoWellFormed(networkID []byte, conf common.Config, cache *SignatureCache) (err error) {
+	if err = checkProposedTime(b.ProposerConfirmed(), conf.BallotProposedTimeTolerance); err != nil {
 		return
 	}
 
-	now := time.Now()
-	timeStart := now.Add(time.Duration(-1) * common.BallotConfirmedTimeAllowDuration)
-	timeEnd := now.Add(common.BallotConfirmedTimeAllowDuration)
+	if err = b.ProposerTransaction().IsWellFormedWithBallot(networkID, b, conf); err != nil {
+		return
+	}
 
-	if proposerConfirmed.Before(timeStart) || proposerConfirmed.After(timeEnd) {
-		err = errors.MessageHasIncorrectTime
+	key := makeSignatureCacheKey(
+		networkID,
+		b.B.Proposed.Proposer,
+		base58.Encode(common.MustMakeObjectHash(b.B.Proposed)),
+		b.H.ProposerSignature,
+	)
+	verify := func() error { return b.VerifyProposer(networkID) }
+	if err = cache.verify(b.VotingBasis().Height, key, verify); err != nil {
 		return
 	}
 
-	if err = b.ProposerTransaction().IsWellFormedWithBallot(networkID, b, conf); err != nil {
+	return
+}
+
+// checkProposedTime checks the proposed time of ballot is within `tolerance`
+// from the local time. It prevents the replay of old ballots and the proposals
+// from the badly-skewed nodes.
+func checkProposedTime(proposed string, tolerance time.Duration) (err error) {
+	var proposedTime time.Time
+	if proposedTime, err = common.ParseISO8601(proposed); err != nil {
 		return
 	}
 
-	if err = b.VerifyProposer(networkID); err != nil {
+	now := time.Now()
+	if proposedTime.Before(now.Add(-tolerance)) {
+		err = errors.BallotProposedTimeTooOld
+		return
+	}
+	if proposedTime.After(now.Add(tolerance)) {
+		err = errors.BallotProposedTimeTooFuture
 		return
 	}
 
@@ -178,6 +279,16 @@ func (b *Ballot) SetVote(state State, vote voting.Hole) {
 	b.B.Vote = vote
 }
 
+// SetExpiredReason sets why the node votes EXP; it must be set before
+// `Sign()`.
+func (b *Ballot) SetExpiredReason(reason ExpiredReason) {
+	b.B.ExpiredReason = reason
+}
+
+func (b Ballot) ExpiredReason() ExpiredReason {
+	return b.B.ExpiredReason
+}
+
 func (b *Ballot) SetReason(reason *errors.Error) {
 	b.B.Reason = reason
 }
@@ -204,7 +315,7 @@ func (b *Ballot) Sign(kp keypair.KP, networkID []byte) {
 
 	b.B.Confirmed = common.NowISO8601()
 	b.B.Source = kp.Address()
-	b.H.Hash = b.B.MakeHashString()
+	b.H.Hash = b.MakeHashString()
 	signature, _ := keypair.MakeSignature(kp, networkID, b.H.Hash)
 	b.H.Signature = base58.Encode(signature)
 
@@ -225,6 +336,11 @@ func (b Ballot) VerifyProposer(networkID []byte) (err error) {
 }
 
 func (b Ballot) VerifySource(networkID []byte) (err error) {
+	if !IsSupportedBallotVersion(b.H.Version) {
+		err = errors.BallotUnsupportedVersion
+		return
+	}
+
 	var kp keypair.KP
 
 	if kp, err = keypair.Parse(b.B.Source); err != nil {
@@ -238,6 +354,35 @@ func (b Ballot) VerifySource(networkID []byte) (err error) {
 	return
 }
 
+// VerifyNetworkID checks the ballot is signed by the source for the network
+// before the other verifications; the signature mixes in the network ID, so
+// the ballot signed for the other network can not be told from the forged
+// one and both are rejected with `errors.MessageSignatureInvalid`. The verification is cached by `SignatureCache` like
+// `IsWellFormedWithCache()`; the malformed signature is left to it.
+func (b Ballot) VerifyNetworkID(networkID []byte, cache *SignatureCache) (err error) {
+	if !IsSupportedBallotVersion(b.H.Version) {
+		err = errors.BallotUnsupportedVersion
+		return
+	}
+
+	if _, err = keypair.Parse(b.B.Source); err != nil {
+		return
+	}
+
+	if len(base58.Decode(b.H.Signature)) != ed25519.SignatureSize {
+		return
+	}
+
+	key := makeSignatureCacheKey(networkID, b.B.Source, b.H.Hash, b.H.Signature)
+	verify := func() error { return b.VerifySource(networkID) }
+	if err = cache.verify(b.VotingBasis().Height, key, verify); err != nil {
+		err = errors.MessageSignatureInvalid
+		return
+	}
+
+	return
+}
+
 func (b Ballot) IsFromProposer() bool {
 	return b.B.Source == b.B.Proposed.Proposer
 }
@@ -257,6 +402,7 @@ func (b *Ballot) SetProposerTransaction(ptx ProposerTransaction) {
 }
 
 type BallotHeader struct {
+	Version           uint   `json:"version"`            // serialization version; covered by `Hash` from version 2
 	Hash              string `json:"hash"`               // hash of `BallotBody`
 	Signature         string `json:"signature"`          // signed by source node of <networkID> + `Hash`
 	ProposerSignature string `json:"proposer_signature"` // signed by proposer of <networkID> + `Hash` of `BallotBodyProposed`
@@ -277,6 +423,11 @@ type BallotBody struct {
 	State     State              `json:"state"`
 	Vote      voting.Hole        `json:"vote"`
 	Reason    *errors.Error      `json:"reason"`
+
+	// ExpiredReason is not in the RLP encoding of the body, so the hash of
+	// the body without it is same with the previous nodes; it is mixed into
+	// the hash by `Ballot.MakeHashString()`.
+	ExpiredReason ExpiredReason `json:"expired_reason,omitempty" rlp:"-"`
 }
 
 func (rb BallotBody) MakeHash() []byte {
@@ -10,6 +10,25 @@ const (
 	StateALLCONFIRM
 )
 
+// stateTransitions is the transition table of ballot states; the key state
+// goes to the value state. The state, which is not in the keys, does not have
+// next state.
+var stateTransitions = map[State]State{
+	StateINIT:   StateSIGN,
+	StateSIGN:   StateACCEPT,
+	StateACCEPT: StateALLCONFIRM,
+}
+
+// AllStates returns the ballot states in the order of transition.
+func AllStates() []State {
+	return []State{
+		StateINIT,
+		StateSIGN,
+		StateACCEPT,
+		StateALLCONFIRM,
+	}
+}
+
 func (s State) String() string {
 	switch s {
 	case StateINIT:
@@ -37,17 +56,36 @@ func (s State) IsValid() bool {
 	return true
 }
 
+// Next returns the next state by `stateTransitions`. If the state has no next
+// state, `StateNONE` is returned.
 func (s State) Next() State {
-	switch s {
-	case StateINIT:
-		return StateSIGN
-	case StateSIGN:
-		return StateACCEPT
-	case StateACCEPT:
-		return StateALLCONFIRM
-	default:
-		return StateNONE
+	if next, found := stateTransitions[s]; found {
+		return next
 	}
+
+	return StateNONE
+}
+
+// Prev returns the previous state by `stateTransitions`. If the state has no
+// previous state, `StateNONE` is returned.
+func (s State) Prev() State {
+	for prev, next := range stateTransitions {
+		if next == s {
+			return prev
+		}
+	}
+
+	return StateNONE
+}
+
+// IsTerminal checks the state is the last state of the transitions.
+func (s State) IsTerminal() bool {
+	if s == StateNONE {
+		return false
+	}
+
+	_, found := stateTransitions[s]
+	return !found
 }
 
 func (s State) IsValidForVote() bool {
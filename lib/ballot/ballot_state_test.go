@@ -0,0 +1,38 @@
+package ballot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBallotStateNext(t *testing.T) {
+	require.Equal(t, StateSIGN, StateINIT.Next())
+	require.Equal(t, StateACCEPT, StateSIGN.Next())
+	require.Equal(t, StateALLCONFIRM, StateACCEPT.Next())
+	require.Equal(t, StateNONE, StateALLCONFIRM.Next())
+	require.Equal(t, StateNONE, StateNONE.Next())
+}
+
+func TestBallotStateNextPrevInverse(t *testing.T) {
+	for _, s := range AllStates() {
+		if s.IsTerminal() {
+			continue
+		}
+		require.Equal(t, s, s.Next().Prev(), "state=%s", s)
+	}
+
+	require.Equal(t, StateNONE, StateINIT.Prev())
+}
+
+func TestBallotStateIsTerminal(t *testing.T) {
+	require.True(t, StateALLCONFIRM.IsTerminal())
+
+	require.False(t, StateNONE.IsTerminal())
+	require.False(t, StateINIT.IsTerminal())
+	require.False(t, StateSIGN.IsTerminal())
+	require.False(t, StateACCEPT.IsTerminal())
+
+	states := AllStates()
+	require.Equal(t, StateALLCONFIRM, states[len(states)-1])
+}
@@ -76,7 +76,7 @@ func TestBallotBadConfirmedTime(t *testing.T) {
 	basis := voting.Basis{Round: 0, Height: 0, BlockHash: "showme", TotalTxs: 0}
 
 	updateBallot := func(ballot *Ballot) {
-		ballot.H.Hash = ballot.B.MakeHashString()
+		ballot.H.Hash = ballot.MakeHashString()
 		signature, _ := keypair.MakeSignature(kp, networkID, ballot.H.Hash)
 		ballot.H.Signature = base58.Encode(signature)
 	}
@@ -145,6 +145,70 @@ func TestBallotBadConfirmedTime(t *testing.T) {
 	}
 }
 
+func TestBallotProposedTimeWindow(t *testing.T) {
+	kp := keypair.Random()
+	commonKP := keypair.Random()
+	endpoint, _ := common.NewEndpointFromString("https://localhost:1000")
+	node, _ := node.NewLocalNode(kp, endpoint, "")
+
+	basis := voting.Basis{Round: 0, Height: 0, BlockHash: "showme", TotalTxs: 0}
+
+	conf := common.NewConfig()
+	conf.BallotProposedTimeTolerance = 10 * time.Second
+
+	newBallot := func(proposed time.Time) *Ballot {
+		blt := NewBallot(node.Address(), node.Address(), basis, []string{})
+
+		opc, _ := NewCollectTxFeeFromBallot(*blt, commonKP.Address())
+		opi, _ := NewInflationFromBallot(*blt, commonKP.Address(), common.Amount(1))
+		ptx, _ := NewProposerTransactionFromBallot(*blt, opc, opi)
+		blt.SetProposerTransaction(ptx)
+		blt.Sign(kp, networkID)
+
+		blt.B.Proposed.Confirmed = common.FormatISO8601(proposed)
+		hash := common.MustMakeObjectHash(blt.B.Proposed)
+		signature, _ := keypair.MakeSignature(kp, networkID, string(hash))
+		blt.H.ProposerSignature = base58.Encode(signature)
+
+		blt.H.Hash = blt.MakeHashString()
+		signature, _ = keypair.MakeSignature(kp, networkID, blt.H.Hash)
+		blt.H.Signature = base58.Encode(signature)
+
+		return blt
+	}
+
+	{ // accepted; within tolerance
+		blt := newBallot(time.Now().Add(-5 * time.Second))
+		require.NoError(t, blt.IsWellFormed(networkID, conf))
+
+		blt = newBallot(time.Now().Add(5 * time.Second))
+		require.NoError(t, blt.IsWellFormed(networkID, conf))
+	}
+
+	{ // too old
+		blt := newBallot(time.Now().Add(-20 * time.Second))
+		err := blt.IsWellFormed(networkID, conf)
+		require.Equal(t, errors.BallotProposedTimeTooOld, err)
+	}
+
+	{ // too future
+		blt := newBallot(time.Now().Add(20 * time.Second))
+		err := blt.IsWellFormed(networkID, conf)
+		require.Equal(t, errors.BallotProposedTimeTooFuture, err)
+	}
+
+	{ // `Ballot.B.Confirmed` is also checked by the tolerance
+		blt := newBallot(time.Now())
+		blt.B.Confirmed = common.FormatISO8601(time.Now().Add(20 * time.Second))
+		blt.H.Hash = blt.MakeHashString()
+		signature, _ := keypair.MakeSignature(kp, networkID, blt.H.Hash)
+		blt.H.Signature = base58.Encode(signature)
+
+		err := blt.IsWellFormed(networkID, conf)
+		require.Equal(t, errors.MessageHasIncorrectTime, err)
+	}
+}
+
 func TestBallotEmptyHash(t *testing.T) {
 	kp := keypair.Random()
 	node, _ := node.NewLocalNode(kp, &common.Endpoint{}, "")
@@ -319,3 +383,69 @@ func TestIsExpiredBallotWithProposerTransactionWellFormed(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+func TestBallotVersion(t *testing.T) {
+	kp := keypair.Random()
+	commonKP := keypair.Random()
+	endpoint, _ := common.NewEndpointFromString("https://localhost:1000")
+	node, _ := node.NewLocalNode(kp, endpoint, "")
+
+	basis := voting.Basis{Round: 0, Height: 0, BlockHash: "showme", TotalTxs: 0}
+	conf := common.NewConfig()
+
+	newBallot := func() *Ballot {
+		blt := NewBallot(node.Address(), node.Address(), basis, []string{})
+
+		opc, _ := NewCollectTxFeeFromBallot(*blt, commonKP.Address())
+		opi, _ := NewInflationFromBallot(*blt, commonKP.Address(), common.Amount(1))
+		ptx, _ := NewProposerTransactionFromBallot(*blt, opc, opi)
+		blt.SetProposerTransaction(ptx)
+		blt.Sign(kp, networkID)
+
+		return blt
+	}
+
+	{ // new ballot is version 1 and the hash is of the body only
+		blt := newBallot()
+		require.Equal(t, BallotVersion, blt.H.Version)
+		require.Equal(t, uint(1), blt.Version())
+		require.Equal(t, blt.B.MakeHashString(), blt.H.Hash)
+		require.NoError(t, blt.IsWellFormed(networkID, conf))
+
+		b, err := blt.Serialize()
+		require.NoError(t, err)
+		decoded, err := NewBallotFromJSON(b)
+		require.NoError(t, err)
+		require.Equal(t, BallotVersion, decoded.H.Version)
+		require.NoError(t, decoded.IsWellFormed(networkID, conf))
+	}
+
+	{ // ballot without version, like the one of the previous node, is version 1
+		blt := newBallot()
+		blt.H.Version = 0
+		require.Equal(t, uint(1), blt.Version())
+		require.Equal(t, blt.B.MakeHashString(), blt.MakeHashString())
+		require.NoError(t, blt.IsWellFormed(networkID, conf))
+	}
+
+	{ // unsupported version
+		blt := newBallot()
+		blt.H.Version = BallotVersion + 1
+		blt.H.Hash = blt.MakeHashString()
+		require.NotEqual(t, blt.B.MakeHashString(), blt.H.Hash)
+		signature, _ := keypair.MakeSignature(kp, networkID, blt.H.Hash)
+		blt.H.Signature = base58.Encode(signature)
+
+		require.Equal(t, errors.BallotUnsupportedVersion, blt.IsWellFormed(networkID, conf))
+		require.Equal(t, errors.BallotUnsupportedVersion, blt.VerifySource(networkID))
+
+		b, err := blt.Serialize()
+		require.NoError(t, err)
+		_, err = NewBallotFromJSON(b)
+		require.Equal(t, errors.BallotUnsupportedVersion, err)
+
+		// the body of future version can not be decoded
+		_, err = NewBallotFromJSON([]byte(`{"H": {"version": 3}, "B": {"state": ["future"]}}`))
+		require.Equal(t, errors.BallotUnsupportedVersion, err)
+	}
+}
@@ -0,0 +1,31 @@
+package ballot
+
+// ExpiredReason is why the node votes EXP. It is optional; the EXP ballot
+// without the reason is still valid, like the ballot of the previous nodes.
+type ExpiredReason string
+
+const (
+	// ExpiredReasonTimeout is voted when the timeout of the ballot state is
+	// expired.
+	ExpiredReasonTimeout ExpiredReason = "timeout"
+	// ExpiredReasonInvalidProposer is voted when the ballot is not from the
+	// expected proposer.
+	ExpiredReasonInvalidProposer ExpiredReason = "invalid-proposer"
+	// ExpiredReasonValidationFailed is voted when the proposed ballot can not
+	// be validated.
+	ExpiredReasonValidationFailed ExpiredReason = "validation-failed"
+)
+
+// IsValid checks the reason is known; the empty reason is valid.
+func (r ExpiredReason) IsValid() bool {
+	switch r {
+	case "":
+	case ExpiredReasonTimeout:
+	case ExpiredReasonInvalidProposer:
+	case ExpiredReasonValidationFailed:
+	default:
+		return false
+	}
+
+	return true
+}
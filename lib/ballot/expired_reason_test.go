@@ -0,0 +1,88 @@
+package ballot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func TestBallotExpiredReason(t *testing.T) {
+	kp := keypair.Random()
+	proposerKP := keypair.Random()
+	commonKP := keypair.Random()
+	conf := common.NewConfig()
+	basis := voting.Basis{Round: 0, Height: 1, BlockHash: "showme", TotalTxs: 1}
+
+	// like `ISAACStateManager.broadcastExpiredBallot()`
+	newExpiredBallot := func(reason ExpiredReason) *Ballot {
+		b := NewBallot(kp.Address(), proposerKP.Address(), basis, []string{})
+		b.SetVote(StateSIGN, voting.EXP)
+		b.SetExpiredReason(reason)
+
+		opc, _ := NewCollectTxFeeFromBallot(*b, commonKP.Address())
+		opi, _ := NewInflationFromBallot(*b, commonKP.Address(), common.BaseReserve)
+		ptx, _ := NewProposerTransactionFromBallot(*b, opc, opi)
+		b.SetProposerTransaction(ptx)
+		b.SignByProposer(proposerKP, networkID)
+		b.Sign(kp, networkID)
+
+		return b
+	}
+
+	reasons := []ExpiredReason{
+		ExpiredReasonTimeout,
+		ExpiredReasonInvalidProposer,
+		ExpiredReasonValidationFailed,
+	}
+	for _, reason := range reasons {
+		b := newExpiredBallot(reason)
+		require.NoError(t, b.IsWellFormed(networkID, conf))
+
+		// the reason is covered by the signature
+		require.NotEqual(t, b.B.MakeHashString(), b.H.Hash)
+
+		s, err := b.Serialize()
+		require.NoError(t, err)
+		decoded, err := NewBallotFromJSON(s)
+		require.NoError(t, err)
+		require.Equal(t, reason, decoded.ExpiredReason())
+		require.Equal(t, b.H.Hash, decoded.H.Hash)
+		require.NoError(t, decoded.IsWellFormed(networkID, conf))
+		require.NoError(t, decoded.VerifySource(networkID))
+	}
+
+	{ // EXP without reason is still valid and has the previous hash
+		b := newExpiredBallot("")
+		require.Equal(t, b.B.MakeHashString(), b.H.Hash)
+		require.NoError(t, b.IsWellFormed(networkID, conf))
+
+		s, err := b.Serialize()
+		require.NoError(t, err)
+		require.NotContains(t, string(s), "expired_reason")
+	}
+
+	{ // the reason is changed after signing
+		b := newExpiredBallot(ExpiredReasonTimeout)
+		b.SetExpiredReason(ExpiredReasonValidationFailed)
+		require.Equal(t, errors.HashDoesNotMatch, b.IsWellFormed(networkID, conf))
+	}
+
+	{ // unknown reason
+		b := newExpiredBallot(ExpiredReason("unknown"))
+		require.Equal(t, errors.BallotInvalidExpiredReason, b.IsWellFormed(networkID, conf))
+	}
+
+	{ // reason of the ballot, which is not EXP
+		b := NewBallot(kp.Address(), proposerKP.Address(), basis, []string{})
+		b.SetVote(StateSIGN, voting.YES)
+		b.SetExpiredReason(ExpiredReasonTimeout)
+		b.Sign(kp, networkID)
+
+		require.Equal(t, errors.BallotInvalidExpiredReason, b.IsWellFormed(networkID, conf))
+	}
+}
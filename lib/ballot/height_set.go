@@ -0,0 +1,104 @@
+package ballot
+
+import (
+	"sync"
+)
+
+// heightSet is the set of keys grouped by block height. The lower heights are
+// evicted by `EvictLowerOrEqual`. If the number of entries reaches the limit,
+// the entries of the lowest height are evicted first.
+type heightSet struct {
+	sync.RWMutex
+
+	limit   int
+	length  int
+	heights map[ /* block height */ uint64]map[ /* key */ string]struct{}
+}
+
+func newHeightSet(limit int) heightSet {
+	return heightSet{
+		limit:   limit,
+		heights: map[uint64]map[string]struct{}{},
+	}
+}
+
+func (c *heightSet) Len() int {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.length
+}
+
+func (c *heightSet) Has(height uint64, key string) bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	keys, found := c.heights[height]
+	if !found {
+		return false
+	}
+	_, found = keys[key]
+
+	return found
+}
+
+// add adds the key and returns `true` if it is newly added.
+func (c *heightSet) add(height uint64, key string) bool {
+	if c.limit < 1 {
+		return true
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if keys, found := c.heights[height]; found {
+		if _, found = keys[key]; found {
+			return false
+		}
+	}
+
+	for c.length >= c.limit {
+		c.evictLowest()
+	}
+
+	keys, found := c.heights[height]
+	if !found {
+		keys = map[string]struct{}{}
+		c.heights[height] = keys
+	}
+	keys[key] = struct{}{}
+	c.length++
+
+	return true
+}
+
+// EvictLowerOrEqual removes the entries of the given height and below.
+func (c *heightSet) EvictLowerOrEqual(height uint64) {
+	c.Lock()
+	defer c.Unlock()
+
+	for h, keys := range c.heights {
+		if h > height {
+			continue
+		}
+		c.length -= len(keys)
+		delete(c.heights, h)
+	}
+}
+
+func (c *heightSet) evictLowest() {
+	var lowest uint64
+	var found bool
+	for h := range c.heights {
+		if !found || h < lowest {
+			lowest = h
+			found = true
+		}
+	}
+	if !found {
+		return
+	}
+
+	c.length -= len(c.heights[lowest])
+	delete(c.heights, lowest)
+}
@@ -32,7 +32,21 @@ func NewProposerTransaction(proposer string, ops ...operation.Operation) (ptx Pr
 	return
 }
 
+// NewCollectTxFeeFromBallot makes `CollectTxFee` of the fee of transactions
+// without burning.
 func NewCollectTxFeeFromBallot(blt Ballot, commonAccount string, txs ...transaction.Transaction) (opb operation.CollectTxFee, err error) {
+	return NewCollectTxFeeFromBallotWithPolicy(blt, commonAccount, common.DefaultFeeBurnPolicy, txs...)
+}
+
+// NewCollectTxFeeFromBallotWithPolicy makes `CollectTxFee` like
+// `NewCollectTxFeeFromBallot()`, but the part of fee is burned by the
+// `FeeBurnPolicy`.
+func NewCollectTxFeeFromBallotWithPolicy(
+	blt Ballot,
+	commonAccount string,
+	policy common.FeeBurnPolicy,
+	txs ...transaction.Transaction,
+) (opb operation.CollectTxFee, err error) {
 	rd := blt.VotingBasis()
 
 	var feeAmount common.Amount
@@ -48,14 +62,38 @@ func NewCollectTxFeeFromBallot(blt Ballot, commonAccount string, txs ...transact
 		rd.BlockHash,
 		rd.TotalTxs,
 	)
+	opb.Burned = policy.Burned(feeAmount)
+
 	return
 }
 
+// NewInflationFromBallot makes `Inflation` by the
+// `common.DefaultInflationSchedule` at the height of voting basis.
 func NewInflationFromBallot(blt Ballot, commonAccount string, initialBalance common.Amount) (opb operation.Inflation, err error) {
+	return NewInflationFromBallotWithSchedule(blt, commonAccount, initialBalance, common.DefaultInflationSchedule)
+}
+
+// NewInflationFromBallotWithSchedule makes `Inflation` like
+// `NewInflationFromBallot()`, but by the given `InflationSchedule` without
+// the cap of supply.
+func NewInflationFromBallotWithSchedule(blt Ballot, commonAccount string, initialBalance common.Amount, schedule common.InflationSchedule) (opb operation.Inflation, err error) {
+	return NewInflationFromBallotWithPolicy(blt, commonAccount, initialBalance, schedule, common.DefaultInflationPolicy)
+}
+
+// NewInflationFromBallotWithPolicy makes `Inflation` like
+// `NewInflationFromBallotWithSchedule()`, but the amount is decided by the
+// `InflationPolicy`; after the cap of supply, it is `0`.
+func NewInflationFromBallotWithPolicy(
+	blt Ballot,
+	commonAccount string,
+	initialBalance common.Amount,
+	schedule common.InflationSchedule,
+	policy common.InflationPolicy,
+) (opb operation.Inflation, err error) {
 	rd := blt.VotingBasis()
 
 	var amount common.Amount
-	if amount, err = common.CalculateInflation(initialBalance); err != nil {
+	if amount, err = policy.CalculateInflation(schedule, rd.Height, initialBalance); err != nil {
 		return
 	}
 
@@ -67,6 +105,7 @@ func NewInflationFromBallot(blt Ballot, commonAccount string, initialBalance com
 		rd.BlockHash,
 		rd.TotalTxs,
 	)
+	opb.Ratio = schedule.RatioString(rd.Height)
 
 	return
 }
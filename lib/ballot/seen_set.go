@@ -0,0 +1,29 @@
+package ballot
+
+// SeenSet keeps the hashes of the received ballots, so the same ballot
+// received from the multiple nodes is dropped before the verification and
+// voting. The ballot of the other round has the different hash, so the
+// re-proposed ballot is not dropped.
+//
+// The hashes are grouped by the height of voting basis and the lower heights
+// are evicted by `EvictLowerOrEqual`. If the number of hashes reaches the
+// limit, the hashes of the lowest height are evicted first.
+type SeenSet struct {
+	heightSet
+}
+
+func NewSeenSet(limit int) *SeenSet {
+	return &SeenSet{heightSet: newHeightSet(limit)}
+}
+
+// Seen checks whether the ballot was already received.
+func (s *SeenSet) Seen(b Ballot) bool {
+	return s.Has(b.VotingBasis().Height, b.GetHash())
+}
+
+// Add adds the ballot and returns `false` if it was already received. It
+// should be called after the ballot is verified; if not, the forged ballot
+// with the hash of the valid ballot can block it.
+func (s *SeenSet) Add(b Ballot) bool {
+	return s.add(b.VotingBasis().Height, b.GetHash())
+}
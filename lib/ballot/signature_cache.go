@@ -0,0 +1,45 @@
+package ballot
+
+// SignatureCache keeps the successful signature verifications of ballots, so
+// the same ballot received from the multiple nodes does not need to be
+// verified again. The key of cache consists of every input of verification,
+// the signer, the signed hash and the signature, so the tampered ballot can
+// not be matched with the cached one.
+//
+// The entries are grouped by block height and the lower heights are evicted by
+// `EvictLowerOrEqual`. If the number of entries reaches the limit, the entries
+// of the lowest height are evicted first.
+type SignatureCache struct {
+	heightSet
+}
+
+func NewSignatureCache(limit int) *SignatureCache {
+	return &SignatureCache{heightSet: newHeightSet(limit)}
+}
+
+func makeSignatureCacheKey(networkID []byte, signer, hash, signature string) string {
+	return string(networkID) + "|" + signer + "|" + hash + "|" + signature
+}
+
+func (c *SignatureCache) Add(height uint64, key string) {
+	c.add(height, key)
+}
+
+// verify calls `f` only if the verification of the key is not cached yet. The
+// nil `SignatureCache` does not cache anything.
+func (c *SignatureCache) verify(height uint64, key string, f func() error) (err error) {
+	if c == nil {
+		return f()
+	}
+
+	if c.Has(height, key) {
+		return
+	}
+
+	if err = f(); err != nil {
+		return
+	}
+	c.Add(height, key)
+
+	return
+}
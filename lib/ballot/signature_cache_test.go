@@ -0,0 +1,182 @@
+package ballot
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func makeSignedBallotForSignatureCache(kp *keypair.Full, height uint64) Ballot {
+	basis := voting.Basis{Round: 0, Height: height, BlockHash: "showme", TotalTxs: 1}
+
+	_, tx := transaction.TestMakeTransaction(networkID, 1)
+	commonKP := keypair.Random()
+
+	blt := NewBallot(kp.Address(), kp.Address(), basis, []string{tx.GetHash()})
+	opc, _ := NewCollectTxFeeFromBallot(*blt, commonKP.Address(), tx)
+	opi, _ := NewInflationFromBallot(*blt, commonKP.Address(), common.Amount(1))
+	ptx, _ := NewProposerTransactionFromBallot(*blt, opc, opi)
+	blt.SetProposerTransaction(ptx)
+	blt.Sign(kp, networkID)
+
+	return *blt
+}
+
+func TestSignatureCache(t *testing.T) {
+	kp := keypair.Random()
+	conf := common.NewConfig()
+	cache := NewSignatureCache(100)
+
+	blt := makeSignedBallotForSignatureCache(kp, 1)
+	require.NoError(t, blt.IsWellFormedWithCache(networkID, conf, cache))
+	require.Equal(t, 2, cache.Len()) // source and proposer
+
+	// duplicate receipt is served from cache
+	require.NoError(t, blt.IsWellFormedWithCache(networkID, conf, cache))
+	require.Equal(t, 2, cache.Len())
+
+	{ // tampered signature of source
+		tampered := blt
+		tampered.H.Signature = base58.Encode([]byte("findme"))
+		require.Error(t, tampered.IsWellFormedWithCache(networkID, conf, cache))
+	}
+
+	{ // tampered source
+		tampered := blt
+		tampered.B.Source = keypair.Random().Address()
+		require.Error(t, tampered.IsWellFormedWithCache(networkID, conf, cache))
+	}
+
+	{ // tampered signature of proposer
+		tampered := blt
+		tampered.H.ProposerSignature = base58.Encode([]byte("findme"))
+		require.Error(t, tampered.IsWellFormedWithCache(networkID, conf, cache))
+	}
+
+	{ // tampered proposed body; re-signed by the other node, but not by the proposer
+		tampered := blt
+		tampered.B.Proposed.Transactions = []string{"showme"}
+		tampered.H.Hash = tampered.MakeHashString()
+		signature, _ := keypair.MakeSignature(kp, networkID, tampered.H.Hash)
+		tampered.H.Signature = base58.Encode(signature)
+		require.Error(t, tampered.IsWellFormedWithCache(networkID, conf, cache))
+	}
+
+	// the failed verifications are not cached; only the valid signature of
+	// re-signed source is added
+	require.Equal(t, 3, cache.Len())
+
+	{ // evicted by height
+		other := makeSignedBallotForSignatureCache(kp, 2)
+		require.NoError(t, other.IsWellFormedWithCache(networkID, conf, cache))
+		require.Equal(t, 5, cache.Len())
+
+		cache.EvictLowerOrEqual(1)
+		require.Equal(t, 2, cache.Len())
+
+		cache.EvictLowerOrEqual(2)
+		require.Equal(t, 0, cache.Len())
+	}
+}
+
+func TestSignatureCacheLimit(t *testing.T) {
+	cache := NewSignatureCache(3)
+
+	cache.Add(2, "a")
+	cache.Add(1, "b")
+	cache.Add(1, "c")
+	require.Equal(t, 3, cache.Len())
+
+	// entries of the lowest height are evicted first
+	cache.Add(3, "d")
+	require.Equal(t, 2, cache.Len())
+	require.False(t, cache.Has(1, "b"))
+	require.False(t, cache.Has(1, "c"))
+	require.True(t, cache.Has(2, "a"))
+	require.True(t, cache.Has(3, "d"))
+
+	// same entry is not counted twice
+	cache.Add(3, "d")
+	require.Equal(t, 2, cache.Len())
+}
+
+func BenchmarkBallotIsWellFormed(b *testing.B) {
+	kp := keypair.Random()
+	conf := common.NewConfig()
+	blt := makeSignedBallotForSignatureCache(kp, 1)
+
+	b.Run("without-cache", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := blt.IsWellFormed(networkID, conf); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("with-cache", func(b *testing.B) {
+		cache := NewSignatureCache(common.BallotSignatureCacheLimit)
+		for i := 0; i < b.N; i++ {
+			if err := blt.IsWellFormedWithCache(networkID, conf, cache); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestSeenSet(t *testing.T) {
+	kp := keypair.Random()
+	seen := NewSeenSet(100)
+
+	blt := makeSignedBallotForSignatureCache(kp, 1)
+	require.False(t, seen.Seen(blt))
+	require.True(t, seen.Add(blt))
+
+	// duplicated
+	require.True(t, seen.Seen(blt))
+	require.False(t, seen.Add(blt))
+	require.Equal(t, 1, seen.Len())
+
+	{ // re-proposed in the next round
+		next := blt
+		next.B.Proposed.VotingBasis.Round++
+		next.Sign(kp, networkID)
+		require.NotEqual(t, blt.GetHash(), next.GetHash())
+
+		require.False(t, seen.Seen(next))
+		require.True(t, seen.Add(next))
+	}
+
+	{ // evicted by height
+		higher := makeSignedBallotForSignatureCache(kp, 2)
+		require.True(t, seen.Add(higher))
+
+		seen.EvictLowerOrEqual(1)
+		require.False(t, seen.Seen(blt))
+		require.True(t, seen.Seen(higher))
+	}
+}
+
+func TestBallotVerifyNetworkID(t *testing.T) {
+	otherNetworkID := []byte("sebak-other-network")
+
+	kp := keypair.Random()
+	blt := makeSignedBallotForSignatureCache(kp, 10)
+
+	cache := NewSignatureCache(10)
+	require.NoError(t, blt.VerifyNetworkID(networkID, cache))
+	require.NoError(t, blt.VerifyNetworkID(networkID, nil))
+
+	// the cached verification of the network does not pass the other network
+	require.Equal(t, errors.MessageSignatureInvalid, blt.VerifyNetworkID(otherNetworkID, cache))
+
+	blt.Sign(kp, otherNetworkID)
+	require.Equal(t, errors.MessageSignatureInvalid, blt.VerifyNetworkID(networkID, cache))
+	require.NoError(t, blt.VerifyNetworkID(otherNetworkID, cache))
+}
@@ -6,7 +6,9 @@ import (
 
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/observer"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
 )
 
 // BlockAccount is account model in block. the storage should support,
@@ -28,6 +30,13 @@ type BlockAccount struct {
 	Linked   string      `json:"linked"`
 	CodeHash []byte      `json:"code_hash"`
 	RootHash common.Hash `json:"root_hash"`
+	// Threshold and Signers are set by `operation.SetSigners`; if Threshold is
+	// 0, the account is signed only by its own key.
+	Threshold uint64             `json:"threshold,omitempty"`
+	Signers   []operation.Signer `json:"signers,omitempty"`
+	// Closed is set by `operation.AccountMerge`; the closed account can not
+	// be the source of transaction.
+	Closed bool `json:"closed,omitempty"`
 }
 
 func NewBlockAccount(address string, balance common.Amount) *BlockAccount {
@@ -107,6 +116,47 @@ func GetBlockAccount(st *storage.LevelDBBackend, address string) (b *BlockAccoun
 	return
 }
 
+// GetBlockAccounts returns the accounts of the addresses, which are read from
+// the single snapshot of the storage, so all of them are in the same state
+// even while the new block is stored. The address, which does not exist, is
+// not in the returned map, unlike the account of zero balance.
+func GetBlockAccounts(st *storage.LevelDBBackend, addresses []string) (accounts map[string]*BlockAccount, err error) {
+	var snapshot *storage.LevelDBBackend
+	if snapshot, err = st.OpenSnapshot(); err != nil {
+		return
+	}
+	defer snapshot.Release()
+
+	accounts = map[string]*BlockAccount{}
+	for _, address := range addresses {
+		if _, found := accounts[address]; found {
+			continue
+		}
+
+		var ba *BlockAccount
+		if ba, err = GetBlockAccount(snapshot, address); err == errors.StorageRecordDoesNotExist {
+			err = nil
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		accounts[address] = ba
+	}
+
+	return
+}
+
+// GetNextSequenceID returns the sequenceID, which the next transaction of the
+// account is expected to have.
+func GetNextSequenceID(st *storage.LevelDBBackend, address string) (sequenceID uint64, err error) {
+	var ba *BlockAccount
+	if ba, err = GetBlockAccount(st, address); err != nil {
+		return
+	}
+
+	return ba.SequenceID, nil
+}
+
 func GetBlockAccountAddressesByCreated(st *storage.LevelDBBackend, options storage.ListOptions) (func() (string, bool, []byte), func()) {
 	iterFunc, closeFunc := st.GetIterator(common.BlockAccountPrefixCreated, options)
 
@@ -150,6 +200,19 @@ func (b *BlockAccount) GetBalance() common.Amount {
 	return b.Balance
 }
 
+// SignersWeight returns the accumulated weight of the given signers of
+// account. The unknown signers are ignored.
+func (b *BlockAccount) SignersWeight(addresses ...string) uint64 {
+	var weight uint64
+	for _, s := range b.Signers {
+		if _, found := common.InStringArray(addresses, s.Address); found {
+			weight += s.Weight
+		}
+	}
+
+	return weight
+}
+
 // Add fund to an account
 //
 // If the amount would make the account overflow over the full supply of coin,
@@ -0,0 +1,199 @@
+package block
+
+import (
+	"fmt"
+	"strings"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// BlockAccountCheckpoint is the summary of the `BlockAccount`s at the block
+// of `Height`. The copies of the accounts are stored with it, so the syncing
+// node can load the checkpoint and replay only the operations of the blocks
+// after `Height` instead of all the blocks.
+//  * `Hash`: the merkle root of the hashes of the accounts in address order;
+//    see `MakeBlockAccountStateHash()`
+//  * `Accounts`: the number of the accounts
+type BlockAccountCheckpoint struct {
+	Height    uint64 `json:"height"`
+	BlockHash string `json:"block_hash"`
+	Hash      string `json:"hash"`
+	Accounts  uint64 `json:"accounts"`
+}
+
+func GetBlockAccountCheckpointKey(height uint64) string {
+	return fmt.Sprintf("%s%020d", common.BlockAccountCheckpointPrefixHeight, height)
+}
+
+func getBlockAccountCheckpointAccountKeyPrefix(height uint64) string {
+	return fmt.Sprintf("%s%020d", common.BlockAccountCheckpointPrefixAccount, height)
+}
+
+func getBlockAccountCheckpointAccountKey(height uint64, address string) string {
+	return fmt.Sprintf("%s%s", getBlockAccountCheckpointAccountKeyPrefix(height), address)
+}
+
+// makeBlockAccountHash iterates the accounts under the prefix and returns the
+// merkle root of their hashes; `f` is called with the key and the raw value
+// of every account.
+func makeBlockAccountHash(st *storage.LevelDBBackend, prefix string, f func(key, value []byte) error) (hash string, n uint64, err error) {
+	var leaves []string
+
+	iterFunc, closeFunc := st.GetIterator(prefix, nil)
+	defer closeFunc()
+
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var ba BlockAccount
+		if err = ba.Deserialize(item.Value); err != nil {
+			return
+		}
+
+		var b []byte
+		if b, err = common.MakeObjectHash(ba); err != nil {
+			return
+		}
+		leaves = append(leaves, string(b))
+
+		if f != nil {
+			if err = f(item.Key, item.Value); err != nil {
+				return
+			}
+		}
+	}
+
+	return common.MakeMerkleRoot(leaves), uint64(len(leaves)), nil
+}
+
+// MakeBlockAccountStateHash returns the hash of the current `BlockAccount`s,
+// which can be compared with `BlockAccountCheckpoint.Hash`.
+func MakeBlockAccountStateHash(st *storage.LevelDBBackend) (hash string, err error) {
+	hash, _, err = makeBlockAccountHash(st, common.BlockAccountPrefixAddress, nil)
+	return
+}
+
+// SaveBlockAccountCheckpoint copies the `BlockAccount`s of `src` into `dst`
+// as the checkpoint of `blk`. `src` must have the account states of `blk`, so
+// usually it is the snapshot taken right after `blk` is stored; see
+// `storage.LevelDBBackend.OpenSnapshot()`.
+func SaveBlockAccountCheckpoint(src, dst *storage.LevelDBBackend, blk Block) (cp BlockAccountCheckpoint, err error) {
+	key := GetBlockAccountCheckpointKey(blk.Height)
+
+	var exists bool
+	if exists, err = dst.Has(key); err != nil {
+		return
+	} else if exists {
+		err = errors.StorageRecordAlreadyExists
+		return
+	}
+
+	cp = BlockAccountCheckpoint{
+		Height:    blk.Height,
+		BlockHash: blk.Hash,
+	}
+
+	cp.Hash, cp.Accounts, err = makeBlockAccountHash(
+		src,
+		common.BlockAccountPrefixAddress,
+		func(key, value []byte) error {
+			address := strings.TrimPrefix(string(key), common.BlockAccountPrefixAddress)
+			return dst.PutRaw(getBlockAccountCheckpointAccountKey(blk.Height, address), value)
+		},
+	)
+	if err != nil {
+		return
+	}
+
+	err = dst.New(key, cp)
+
+	return
+}
+
+func GetBlockAccountCheckpoint(st *storage.LevelDBBackend, height uint64) (cp BlockAccountCheckpoint, err error) {
+	err = st.Get(GetBlockAccountCheckpointKey(height), &cp)
+	return
+}
+
+// GetLatestBlockAccountCheckpoint returns the checkpoint of the highest block;
+// if no checkpoint, it returns `errors.StorageRecordDoesNotExist`.
+func GetLatestBlockAccountCheckpoint(st *storage.LevelDBBackend) (cp BlockAccountCheckpoint, err error) {
+	iterFunc, closeFunc := st.GetIterator(
+		common.BlockAccountCheckpointPrefixHeight,
+		storage.NewDefaultListOptions(true, nil, 1),
+	)
+	item, hasNext := iterFunc()
+	closeFunc()
+
+	if !hasNext {
+		err = errors.StorageRecordDoesNotExist
+		return
+	}
+
+	err = common.DecodeJSONValue(item.Value, &cp)
+
+	return
+}
+
+// Verify recomputes the hash from the stored accounts of the checkpoint; if it
+// does not match with `Hash`, it returns
+// `errors.BlockAccountCheckpointNotMatched`.
+func (cp BlockAccountCheckpoint) Verify(st *storage.LevelDBBackend) (err error) {
+	var hash string
+	var n uint64
+	if hash, n, err = makeBlockAccountHash(st, getBlockAccountCheckpointAccountKeyPrefix(cp.Height), nil); err != nil {
+		return
+	}
+
+	if hash != cp.Hash || n != cp.Accounts {
+		return errors.BlockAccountCheckpointNotMatched.Clone().SetData("height", cp.Height)
+	}
+
+	return
+}
+
+// LoadBlockAccountCheckpoint verifies the checkpoint of `height` and replaces
+// the `BlockAccount`s of `st` with the accounts of it. After loading, the
+// operations of the blocks after `height` should be replayed.
+func LoadBlockAccountCheckpoint(st *storage.LevelDBBackend, height uint64) (cp BlockAccountCheckpoint, err error) {
+	if cp, err = GetBlockAccountCheckpoint(st, height); err != nil {
+		return
+	}
+	if err = cp.Verify(st); err != nil {
+		return
+	}
+
+	// the keys are collected before removing, so the deletes do not run under
+	// the open iterator.
+	var removes []string
+	{
+		iterFunc, closeFunc := st.GetIterator(common.BlockAccountPrefixAddress, nil)
+		for {
+			item, hasNext := iterFunc()
+			if !hasNext {
+				break
+			}
+			removes = append(removes, string(item.Key))
+		}
+		closeFunc()
+	}
+
+	for _, key := range removes {
+		if err = st.Remove(key); err != nil {
+			return
+		}
+	}
+
+	prefix := getBlockAccountCheckpointAccountKeyPrefix(height)
+	_, _, err = makeBlockAccountHash(st, prefix, func(key, value []byte) error {
+		address := strings.TrimPrefix(string(key), prefix)
+		return st.PutRaw(GetBlockAccountKey(address), value)
+	})
+
+	return
+}
@@ -0,0 +1,199 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/voting"
+)
+
+type testCheckpointPayment struct {
+	source string
+	target string
+	amount common.Amount
+}
+
+func applyTestCheckpointPayments(t *testing.T, st *storage.LevelDBBackend, payments []testCheckpointPayment) {
+	for _, p := range payments {
+		source, err := GetBlockAccount(st, p.source)
+		require.NoError(t, err)
+		require.NoError(t, source.Withdraw(p.amount))
+		source.SequenceID++
+		require.NoError(t, source.Save(st))
+
+		target, err := GetBlockAccount(st, p.target)
+		if err == errors.StorageRecordDoesNotExist {
+			target = NewBlockAccount(p.target, 0)
+		} else {
+			require.NoError(t, err)
+		}
+		require.NoError(t, target.Deposit(p.amount))
+		require.NoError(t, target.Save(st))
+	}
+}
+
+func dumpTestAccounts(st *storage.LevelDBBackend) map[string]string {
+	dumped := map[string]string{}
+
+	iterFunc, closeFunc := st.GetIterator(common.BlockAccountPrefixAddress, nil)
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+		dumped[string(item.Key)] = string(item.Value)
+	}
+	closeFunc()
+
+	return dumped
+}
+
+func TestBlockAccountCheckpointReplay(t *testing.T) {
+	var kps []*keypair.Full
+	for i := 0; i < 4; i++ {
+		kps = append(kps, keypair.Random())
+	}
+
+	// the payments by height; some accounts are created after the checkpoint
+	var heights [][]testCheckpointPayment
+	for h := 0; h < 10; h++ {
+		var payments []testCheckpointPayment
+		for i := 0; i < 3; i++ {
+			target := kps[(h+i+1)%len(kps)].Address()
+			if h%3 == 0 && i == 0 {
+				target = keypair.Random().Address()
+			}
+			payments = append(payments, testCheckpointPayment{
+				source: kps[(h+i)%len(kps)].Address(),
+				target: target,
+				amount: common.Amount(1000 * (h + i + 1)),
+			})
+		}
+		heights = append(heights, payments)
+	}
+
+	checkpointHeight := 5
+
+	// full replay
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	for _, kp := range kps {
+		require.NoError(t, NewBlockAccount(kp.Address(), common.Amount(common.BaseReserve)*100).Save(st))
+	}
+
+	var cp BlockAccountCheckpoint
+	for h, payments := range heights {
+		applyTestCheckpointPayments(t, st, payments)
+
+		if h != checkpointHeight {
+			continue
+		}
+
+		blk := NewBlock("", voting.Basis{Height: uint64(h)}, "", []string{}, nil, common.NowISO8601())
+
+		snapshot, err := st.OpenSnapshot()
+		require.NoError(t, err)
+
+		// the state after the snapshot is not in the checkpoint
+		require.NoError(t, NewBlockAccount(keypair.Random().Address(), 1).Save(st))
+
+		cp, err = SaveBlockAccountCheckpoint(snapshot, st, *blk)
+		require.NoError(t, err)
+		require.NoError(t, snapshot.Release())
+
+		{ // already saved
+			_, err := SaveBlockAccountCheckpoint(st, st, *blk)
+			require.Equal(t, errors.StorageRecordAlreadyExists, err)
+		}
+
+		require.Equal(t, uint64(h), cp.Height)
+		require.Equal(t, blk.Hash, cp.BlockHash)
+		require.NoError(t, cp.Verify(st))
+
+		hash, err := MakeBlockAccountStateHash(st)
+		require.NoError(t, err)
+		require.NotEqual(t, hash, cp.Hash)
+
+		// only the account saved after the snapshot is missing
+		require.Equal(t, int(cp.Accounts)+1, len(dumpTestAccounts(st)))
+	}
+
+	latest, err := GetLatestBlockAccountCheckpoint(st)
+	require.NoError(t, err)
+	require.Equal(t, cp, latest)
+
+	// checkpoint and the subsequent payments
+	synced := storage.NewTestStorage()
+	defer synced.Close()
+
+	for _, prefix := range []string{common.BlockAccountCheckpointPrefixHeight, common.BlockAccountCheckpointPrefixAccount} {
+		iterFunc, closeFunc := st.GetIterator(prefix, nil)
+		for {
+			item, hasNext := iterFunc()
+			if !hasNext {
+				break
+			}
+			require.NoError(t, synced.PutRaw(string(item.Key), item.Value))
+		}
+		closeFunc()
+	}
+
+	loaded, err := LoadBlockAccountCheckpoint(synced, uint64(checkpointHeight))
+	require.NoError(t, err)
+	require.Equal(t, cp, loaded)
+
+	hash, err := MakeBlockAccountStateHash(synced)
+	require.NoError(t, err)
+	require.Equal(t, cp.Hash, hash)
+
+	for _, payments := range heights[checkpointHeight+1:] {
+		applyTestCheckpointPayments(t, synced, payments)
+	}
+
+	// the account saved after the snapshot in `st` is not replayed
+	expected := dumpTestAccounts(st)
+	got := dumpTestAccounts(synced)
+	require.Equal(t, len(expected)-1, len(got))
+	for key, value := range got {
+		require.Equal(t, expected[key], value)
+	}
+}
+
+func TestBlockAccountCheckpointVerify(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	blk := GetLatestBlock(st)
+	cp, err := SaveBlockAccountCheckpoint(st, st, blk)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), cp.Accounts)
+	require.NoError(t, cp.Verify(st))
+
+	hash, err := MakeBlockAccountStateHash(st)
+	require.NoError(t, err)
+	require.Equal(t, hash, cp.Hash)
+
+	// tamper the account in checkpoint
+	ba, err := GetBlockAccount(st, GenesisKP.Address())
+	require.NoError(t, err)
+	ba.Balance--
+	require.NoError(t, st.PutRaw(getBlockAccountCheckpointAccountKey(blk.Height, ba.Address), common.MustJSONMarshal(ba)))
+
+	err = cp.Verify(st)
+	require.Error(t, err)
+	require.Equal(t, errors.BlockAccountCheckpointNotMatched.Code, err.(*errors.Error).Code)
+
+	_, err = LoadBlockAccountCheckpoint(st, blk.Height)
+	require.Equal(t, errors.BlockAccountCheckpointNotMatched.Code, err.(*errors.Error).Code)
+
+	// the accounts are not touched
+	hash, err = MakeBlockAccountStateHash(st)
+	require.NoError(t, err)
+	require.Equal(t, cp.Hash, hash)
+}
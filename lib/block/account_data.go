@@ -0,0 +1,61 @@
+package block
+
+import (
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// BlockAccountData is the named value of account, which is set by
+// `operation.ManageData`.
+type BlockAccountData struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+	Value   []byte `json:"value"`
+}
+
+func GetBlockAccountDataKey(address, name string) string {
+	return fmt.Sprintf("%s%s-%s", common.BlockAccountDataPrefixAddress, address, name)
+}
+
+// GetAccountData returns the value of the account data; if it does not
+// exist, it returns `errors.StorageRecordDoesNotExist`.
+func GetAccountData(st *storage.LevelDBBackend, address, name string) (value []byte, err error) {
+	var d BlockAccountData
+	if err = st.Get(GetBlockAccountDataKey(address, name), &d); err != nil {
+		return
+	}
+
+	value = d.Value
+	return
+}
+
+func ExistsAccountData(st *storage.LevelDBBackend, address, name string) (bool, error) {
+	return st.Has(GetBlockAccountDataKey(address, name))
+}
+
+// SaveAccountData sets the value of the account data; the empty value deletes
+// it.
+func SaveAccountData(st *storage.LevelDBBackend, address, name string, value []byte) (err error) {
+	key := GetBlockAccountDataKey(address, name)
+
+	var exists bool
+	if exists, err = st.Has(key); err != nil {
+		return
+	}
+
+	if len(value) < 1 {
+		if exists {
+			err = st.Remove(key)
+		}
+		return
+	}
+
+	d := BlockAccountData{Address: address, Name: name, Value: value}
+	if exists {
+		return st.Set(key, d)
+	}
+
+	return st.New(key, d)
+}
@@ -0,0 +1,49 @@
+package block
+
+import (
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// BlockAccountMerge is the record of `operation.AccountMerge`. The merged
+// amount is decided by the balance of the source at the block, so it is kept
+// with the closed source to index the `BlockOperation` of the merge; see
+// `updateBlockAccountRollups()`.
+type BlockAccountMerge struct {
+	Source string        `json:"source"`
+	Target string        `json:"target"`
+	Amount common.Amount `json:"amount"`
+}
+
+func GetBlockAccountMergeKey(source string) string {
+	return fmt.Sprintf("%s%s", common.BlockAccountMergePrefixSource, source)
+}
+
+// GetBlockAccountMerge returns the merge of the closed source; if the source
+// is not merged, it returns `errors.StorageRecordDoesNotExist`.
+func GetBlockAccountMerge(st *storage.LevelDBBackend, source string) (merge BlockAccountMerge, err error) {
+	err = st.Get(GetBlockAccountMergeKey(source), &merge)
+	return
+}
+
+// MergeBlockAccount deposits the whole balance of `source` to `target` and
+// closes `source`. The accounts are not saved, but the merge is.
+func MergeBlockAccount(st *storage.LevelDBBackend, source, target *BlockAccount) (merge BlockAccountMerge, err error) {
+	merge = BlockAccountMerge{
+		Source: source.Address,
+		Target: target.Address,
+		Amount: source.Balance,
+	}
+
+	if err = target.Deposit(merge.Amount); err != nil {
+		return
+	}
+	source.Balance = 0
+	source.Closed = true
+
+	err = st.New(GetBlockAccountMergeKey(source.Address), merge)
+
+	return
+}
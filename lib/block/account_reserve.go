@@ -0,0 +1,87 @@
+package block
+
+import (
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// CountAccountData returns the number of the account data of the address.
+func CountAccountData(st *storage.LevelDBBackend, address string) (count uint64, err error) {
+	iterFunc, closeFunc := st.GetIterator(GetBlockAccountDataKey(address, ""), nil)
+	defer closeFunc()
+
+	for {
+		if _, hasNext := iterFunc(); !hasNext {
+			break
+		}
+		count++
+	}
+
+	return
+}
+
+// MinimumBalance returns the balance, which the account must keep after
+// paying the transaction; every account data and signer requires
+// `common.EntryReserve`. The transaction, which leaves the balance under it,
+// is rejected with `errors.AccountBalanceUnderMinimum` except
+// `operation.AccountMerge`, which closes the account.
+func MinimumBalance(st *storage.LevelDBBackend, ba *BlockAccount) (minimum common.Amount, err error) {
+	return MinimumBalanceAfter(st, ba, nil)
+}
+
+// MinimumBalanceAfter returns `MinimumBalance()` of the account as it will be
+// after the operations of its own transaction; the account data set or
+// deleted by `operation.ManageData` and the signers set by
+// `operation.SetSigners` are counted in the order of the operations.
+func MinimumBalanceAfter(st *storage.LevelDBBackend, ba *BlockAccount, ops []operation.Operation) (minimum common.Amount, err error) {
+	var count uint64
+	if count, err = CountAccountData(st, ba.Address); err != nil {
+		return
+	}
+	signers := uint64(len(ba.Signers))
+
+	exists := map[ /* ManageData.Name */ string]bool{}
+	for _, op := range ops {
+		switch pop := op.B.(type) {
+		case operation.ManageData:
+			found, checked := exists[pop.Name]
+			if !checked {
+				if found, err = ExistsAccountData(st, ba.Address, pop.Name); err != nil {
+					return
+				}
+			}
+
+			if pop.IsDelete() && found {
+				count--
+			} else if !pop.IsDelete() && !found {
+				count++
+			}
+			exists[pop.Name] = !pop.IsDelete()
+		case operation.SetSigners:
+			signers = uint64(len(pop.Signers))
+		}
+	}
+
+	count += signers
+	if count < 1 {
+		return
+	}
+
+	return common.EntryReserve.MultUint64(count)
+}
+
+// SpendableBalance returns the balance, which the account can spend including
+// the fee without going under `MinimumBalance()`.
+func SpendableBalance(st *storage.LevelDBBackend, ba *BlockAccount) (spendable common.Amount, err error) {
+	var minimum common.Amount
+	if minimum, err = MinimumBalance(st, ba); err != nil {
+		return
+	}
+
+	if ba.Balance <= minimum {
+		return 0, nil
+	}
+
+	return ba.Balance - minimum, nil
+}
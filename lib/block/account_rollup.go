@@ -0,0 +1,161 @@
+package block
+
+import (
+	"fmt"
+	"sync"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// BlockAccountRollup is the summary of the `BlockOperation`s of an account, so
+// the account overview can be shown without scanning all the operations. It is
+// updated whenever `BlockOperation` is saved and it is not removed by pruning.
+//  * `TotalReceived`: the sum of the amount of `operation.Payable`, which the
+//    account is the target of
+//  * `TotalSent`: the sum of the amount of `operation.Payable`, which the
+//    account is the source of; `Inflation` and `CollectTxFee` are not paid by
+//    the source, so they are excluded
+//  * `OperationCount`: the number of operations, which the account is the
+//    source or the target of
+//  * `LastHeight`: the highest block height of the operations
+type BlockAccountRollup struct {
+	Address        string        `json:"address"`
+	TotalReceived  common.Amount `json:"total_received"`
+	TotalSent      common.Amount `json:"total_sent"`
+	OperationCount uint64        `json:"operation_count"`
+	LastHeight     uint64        `json:"last_height"`
+}
+
+// blockAccountRollupLock serializes the read-modify-write of the rollups,
+// because the `BlockOperation`s are saved concurrently; see
+// `runner.SavingBlockOperations`.
+var blockAccountRollupLock sync.Mutex
+
+func GetBlockAccountRollupKey(address string) string {
+	return fmt.Sprintf("%s%s", common.BlockAccountRollupPrefixAddress, address)
+}
+
+// GetBlockAccountRollup returns the rollup of the account; if the account
+// does not have any operation, it returns the empty rollup.
+func GetBlockAccountRollup(st *storage.LevelDBBackend, address string) (rollup BlockAccountRollup, err error) {
+	if err = st.Get(GetBlockAccountRollupKey(address), &rollup); err != nil {
+		if err == errors.StorageRecordDoesNotExist {
+			err = nil
+			rollup = BlockAccountRollup{Address: address}
+		}
+		return
+	}
+
+	return
+}
+
+func (r BlockAccountRollup) save(st *storage.LevelDBBackend) (err error) {
+	key := GetBlockAccountRollupKey(r.Address)
+
+	var exists bool
+	if exists, err = st.Has(key); err != nil {
+		return
+	} else if exists {
+		return st.Set(key, r)
+	}
+
+	return st.New(key, r)
+}
+
+func (r *BlockAccountRollup) add(height uint64, received, sent common.Amount) (err error) {
+	if r.TotalReceived, err = r.TotalReceived.Add(received); err != nil {
+		return
+	}
+	if r.TotalSent, err = r.TotalSent.Add(sent); err != nil {
+		return
+	}
+
+	r.OperationCount++
+	if height > r.LastHeight {
+		r.LastHeight = height
+	}
+
+	return
+}
+
+// blockOperationTransfer is the amount moved by the `BlockOperation`; the
+// source sends `sent` and the target receives `amount`.
+type blockOperationTransfer struct {
+	target string
+	amount common.Amount
+	sent   common.Amount
+}
+
+// getBlockOperationTransfer decodes the body of the `BlockOperation` into the
+// transfer; the operation, which does not move the balance, has the empty
+// transfer.
+func getBlockOperationTransfer(st *storage.LevelDBBackend, bo BlockOperation) (transfer blockOperationTransfer, err error) {
+	var opb operation.Body
+	if opb, err = operation.UnmarshalBodyJSON(bo.Type, bo.Body); err != nil {
+		return
+	}
+	if pop, ok := opb.(operation.Payable); ok {
+		transfer.target = pop.TargetAddress()
+		transfer.amount = pop.GetAmount()
+	} else if bo.Type == operation.TypeAccountMerge {
+		var merge BlockAccountMerge
+		if merge, err = GetBlockAccountMerge(st, bo.Source); err != nil {
+			return
+		}
+		transfer.target = merge.Target
+		transfer.amount = merge.Amount
+	}
+
+	transfer.sent = transfer.amount
+	switch bo.Type {
+	case operation.TypeInflation, operation.TypeCollectTxFee:
+		transfer.sent = 0
+	}
+
+	return
+}
+
+// updateBlockAccountRollups updates the rollups of the source and the target
+// of the `BlockOperation`.
+func updateBlockAccountRollups(st *storage.LevelDBBackend, bo BlockOperation, transfer blockOperationTransfer) (err error) {
+	target, amount, sent := transfer.target, transfer.amount, transfer.sent
+
+	blockAccountRollupLock.Lock()
+	defer blockAccountRollupLock.Unlock()
+
+	var source BlockAccountRollup
+	if source, err = GetBlockAccountRollup(st, bo.Source); err != nil {
+		return
+	}
+
+	if target == bo.Source { // to itself
+		if err = source.add(bo.Height, amount, sent); err != nil {
+			return
+		}
+		return source.save(st)
+	}
+
+	if err = source.add(bo.Height, 0, sent); err != nil {
+		return
+	}
+	if err = source.save(st); err != nil {
+		return
+	}
+
+	if len(target) < 1 {
+		return
+	}
+
+	var rollup BlockAccountRollup
+	if rollup, err = GetBlockAccountRollup(st, target); err != nil {
+		return
+	}
+	if err = rollup.add(bo.Height, amount, 0); err != nil {
+		return
+	}
+
+	return rollup.save(st)
+}
@@ -0,0 +1,141 @@
+package block
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func TestBlockAccountRollupMatchesFullScan(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	var kps []*keypair.Full
+	for i := 0; i < 4; i++ {
+		kps = append(kps, keypair.Random())
+	}
+	proposer := keypair.Random()
+	commonAccount := keypair.Random()
+
+	saveTransaction := func(source string, sequenceID uint64, height uint64, opbs ...operation.Body) {
+		var ops []operation.Operation
+		for _, opb := range opbs {
+			op, err := operation.NewOperation(opb)
+			require.NoError(t, err)
+			ops = append(ops, op)
+		}
+
+		tx, err := transaction.NewTransaction(source, sequenceID, ops...)
+		require.NoError(t, err)
+
+		for _, op := range tx.B.Operations {
+			bo, err := NewBlockOperationFromOperation(op, tx, height)
+			require.NoError(t, err)
+			bo.MustSave(st)
+		}
+	}
+
+	for i := 0; i < 40; i++ {
+		height := uint64(i/5 + 2)
+		source := kps[i%len(kps)]
+		target := kps[(i*3+1)%len(kps)]
+
+		saveTransaction(
+			source.Address(),
+			uint64(i),
+			height,
+			operation.NewPayment(target.Address(), common.Amount(10000*(i+1))),
+			operation.NewPayment(keypair.Random().Address(), common.Amount(1000)),
+		)
+
+		if i%10 == 0 {
+			// to itself
+			saveTransaction(
+				source.Address(),
+				uint64(i)+1000,
+				height,
+				operation.NewPayment(source.Address(), common.Amount(333)),
+			)
+
+			// proposer transaction
+			saveTransaction(
+				proposer.Address(),
+				0,
+				height,
+				operation.NewCollectTxFee(commonAccount.Address(), common.Amount(20000), 2, height, "", 0),
+				operation.NewOperationBodyInflation(commonAccount.Address(), common.Amount(50000), common.Amount(0), height, "", 0),
+			)
+		}
+	}
+
+	// full scan
+	expected := map[string]*BlockAccountRollup{}
+	get := func(address string) *BlockAccountRollup {
+		if _, found := expected[address]; !found {
+			expected[address] = &BlockAccountRollup{Address: address}
+		}
+		return expected[address]
+	}
+
+	iterFunc, closeFunc := st.GetIterator(common.BlockOperationPrefixHash, nil)
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var bo BlockOperation
+		require.NoError(t, json.Unmarshal(item.Value, &bo))
+
+		opb, err := operation.UnmarshalBodyJSON(bo.Type, bo.Body)
+		require.NoError(t, err)
+		pop := opb.(operation.Payable)
+
+		source := get(bo.Source)
+		source.OperationCount++
+		if bo.Height > source.LastHeight {
+			source.LastHeight = bo.Height
+		}
+		if bo.Type == operation.TypePayment {
+			source.TotalSent = source.TotalSent.MustAdd(pop.GetAmount())
+		}
+
+		target := get(pop.TargetAddress())
+		if target != source {
+			target.OperationCount++
+		}
+		if bo.Height > target.LastHeight {
+			target.LastHeight = bo.Height
+		}
+		target.TotalReceived = target.TotalReceived.MustAdd(pop.GetAmount())
+	}
+	closeFunc()
+
+	require.True(t, len(expected) > len(kps)+2)
+	for address, e := range expected {
+		rollup, err := GetBlockAccountRollup(st, address)
+		require.NoError(t, err)
+		require.Equal(t, *e, rollup, address)
+	}
+
+	{ // the account without operation
+		address := keypair.Random().Address()
+		rollup, err := GetBlockAccountRollup(st, address)
+		require.NoError(t, err)
+		require.Equal(t, BlockAccountRollup{Address: address}, rollup)
+	}
+
+	{ // proposer does not send the inflation and the collected fee
+		rollup, err := GetBlockAccountRollup(st, proposer.Address())
+		require.NoError(t, err)
+		require.Equal(t, common.Amount(0), rollup.TotalSent)
+		require.Equal(t, uint64(8), rollup.OperationCount)
+	}
+}
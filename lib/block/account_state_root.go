@@ -0,0 +1,34 @@
+package block
+
+import (
+	"boscoin.io/sebak/lib/storage"
+)
+
+// MakeBlockAccountStateRoot returns the state root of the `BlockAccount`s at
+// the block of `height`. It is the merkle root of the hashes of the accounts
+// in address order, so the nodes, which apply the same operations in the
+// different order, have the same state root; it is same with
+// `BlockAccountCheckpoint.Hash` of the height.
+//
+// The stored accounts are of the latest block, so for the lower height the
+// account checkpoint of the height is used; if it does not exist,
+// `errors.StorageRecordDoesNotExist` is returned.
+func MakeBlockAccountStateRoot(st *storage.LevelDBBackend, height uint64) (root string, err error) {
+	if _, err = GetBlockHeaderByHeight(st, height); err != nil {
+		return
+	}
+
+	if latest := GetLatestBlock(st); latest.Height == height {
+		return MakeBlockAccountStateHash(st)
+	}
+
+	var cp BlockAccountCheckpoint
+	if cp, err = GetBlockAccountCheckpoint(st, height); err != nil {
+		return
+	}
+	if err = cp.Verify(st); err != nil {
+		return
+	}
+
+	return cp.Hash, nil
+}
@@ -0,0 +1,118 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// TestBlockAccountStateRootOrder applies the same payments to two nodes in
+// the different order; the new accounts are also created in the different
+// order, but the state roots are same.
+func TestBlockAccountStateRootOrder(t *testing.T) {
+	var kps []*keypair.Full
+	for i := 0; i < 4; i++ {
+		kps = append(kps, keypair.Random())
+	}
+
+	var payments []testCheckpointPayment
+	for i := 0; i < 8; i++ {
+		target := kps[(i+1)%len(kps)].Address()
+		if i%2 == 0 {
+			target = keypair.Random().Address()
+		}
+		payments = append(payments, testCheckpointPayment{
+			source: kps[i%len(kps)].Address(),
+			target: target,
+			amount: common.Amount(1000 * (i + 1)),
+		})
+	}
+
+	var reversed []testCheckpointPayment
+	for i := len(payments) - 1; i >= 0; i-- {
+		reversed = append(reversed, payments[i])
+	}
+
+	var roots []string
+	for i, ps := range [][]testCheckpointPayment{payments, reversed} {
+		st := storage.NewTestStorage()
+		defer st.Close()
+
+		// the initial accounts are also saved in the different order
+		for j := range kps {
+			kp := kps[j]
+			if i == 1 {
+				kp = kps[len(kps)-1-j]
+			}
+			require.NoError(t, NewBlockAccount(kp.Address(), common.Amount(common.BaseReserve)*100).Save(st))
+		}
+		applyTestCheckpointPayments(t, st, ps)
+
+		blk := NewBlock("", voting.Basis{Height: common.GenesisBlockHeight}, "", []string{}, nil, common.NowISO8601())
+		blk.MustSave(st)
+
+		root, err := MakeBlockAccountStateRoot(st, blk.Height)
+		require.NoError(t, err)
+		require.NotEmpty(t, root)
+		roots = append(roots, root)
+	}
+
+	require.Equal(t, roots[0], roots[1])
+}
+
+func TestBlockAccountStateRootHeight(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	genesis := GetLatestBlock(st)
+	root, err := MakeBlockAccountStateRoot(st, genesis.Height)
+	require.NoError(t, err)
+
+	cp, err := SaveBlockAccountCheckpoint(st, st, genesis)
+	require.NoError(t, err)
+	require.Equal(t, cp.Hash, root)
+
+	// the accounts are changed at the next block
+	require.NoError(t, NewBlockAccount(keypair.Random().Address(), 1).Save(st))
+	blk := NewBlock(
+		"",
+		voting.Basis{Height: genesis.Height + 1, BlockHash: genesis.Hash},
+		"",
+		[]string{},
+		nil,
+		common.NowISO8601(),
+	)
+	blk.MustSave(st)
+
+	latestRoot, err := MakeBlockAccountStateRoot(st, blk.Height)
+	require.NoError(t, err)
+	require.NotEqual(t, root, latestRoot)
+
+	// the lower height is from the checkpoint
+	root, err = MakeBlockAccountStateRoot(st, genesis.Height)
+	require.NoError(t, err)
+	require.Equal(t, cp.Hash, root)
+
+	{ // unknown height
+		_, err := MakeBlockAccountStateRoot(st, blk.Height+1)
+		require.Equal(t, errors.StorageRecordDoesNotExist, err)
+	}
+
+	{ // no checkpoint of the lower height
+		st := InitTestBlockchain()
+		defer st.Close()
+
+		genesis := GetLatestBlock(st)
+		blk := NewBlock("", voting.Basis{Height: genesis.Height + 1}, "", []string{}, nil, common.NowISO8601())
+		blk.MustSave(st)
+
+		_, err := MakeBlockAccountStateRoot(st, genesis.Height)
+		require.Equal(t, errors.StorageRecordDoesNotExist, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/observer"
 	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
 
 	"github.com/stretchr/testify/require"
 )
@@ -41,6 +42,39 @@ func TestSaveExistingBlockAccount(t *testing.T) {
 	require.Equal(t, b.GetBalance(), fetched.GetBalance())
 }
 
+func TestGetBlockAccounts(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	funded := TestMakeBlockAccount()
+	funded.MustSave(st)
+
+	empty := TestMakeBlockAccount()
+	empty.Balance = 0
+	empty.MustSave(st)
+
+	missing := TestMakeBlockAccount()
+
+	accounts, err := GetBlockAccounts(st, []string{funded.Address, missing.Address, empty.Address, funded.Address})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(accounts))
+
+	require.Equal(t, funded.Balance, accounts[funded.Address].Balance)
+
+	ba, found := accounts[empty.Address]
+	require.True(t, found)
+	require.Equal(t, common.Amount(0), ba.Balance)
+
+	_, found = accounts[missing.Address]
+	require.False(t, found)
+
+	{ // nothing exists
+		accounts, err := GetBlockAccounts(st, []string{missing.Address})
+		require.NoError(t, err)
+		require.Empty(t, accounts)
+	}
+}
+
 func TestSortMultipleBlockAccount(t *testing.T) {
 	st := storage.NewTestStorage()
 
@@ -156,3 +190,95 @@ func TestBlockAccountObserver(t *testing.T) {
 	require.Equal(t, b.GetBalance(), triggered.GetBalance())
 	require.Equal(t, b.SequenceID, triggered.SequenceID)
 }
+
+func TestBlockAccountMinimumBalance(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	b := TestMakeBlockAccount()
+	require.NoError(t, b.Save(st))
+
+	minimum, err := MinimumBalance(st, b)
+	require.NoError(t, err)
+	require.Equal(t, common.Amount(0), minimum)
+
+	spendable, err := SpendableBalance(st, b)
+	require.NoError(t, err)
+	require.Equal(t, b.Balance, spendable)
+
+	// every account data increases the minimum balance
+	require.NoError(t, SaveAccountData(st, b.Address, "a", []byte("1")))
+	require.NoError(t, SaveAccountData(st, b.Address, "b", []byte("2")))
+
+	// the data of the other account is not counted
+	require.NoError(t, SaveAccountData(st, TestMakeBlockAccount().Address, "c", []byte("3")))
+
+	minimum, err = MinimumBalance(st, b)
+	require.NoError(t, err)
+	require.Equal(t, common.EntryReserve.MustMult(2), minimum)
+
+	spendable, err = SpendableBalance(st, b)
+	require.NoError(t, err)
+	require.Equal(t, b.Balance.MustSub(minimum), spendable)
+
+	// signers also
+	b.Signers = []operation.Signer{{Address: b.Address, Weight: 1}}
+	minimum, err = MinimumBalance(st, b)
+	require.NoError(t, err)
+	require.Equal(t, common.EntryReserve.MustMult(3), minimum)
+
+	// the deleted account data is not counted
+	require.NoError(t, SaveAccountData(st, b.Address, "a", nil))
+	minimum, err = MinimumBalance(st, b)
+	require.NoError(t, err)
+	require.Equal(t, common.EntryReserve.MustMult(2), minimum)
+
+	// spendable is not negative
+	b.Balance = common.EntryReserve
+	spendable, err = SpendableBalance(st, b)
+	require.NoError(t, err)
+	require.Equal(t, common.Amount(0), spendable)
+}
+
+func TestBlockAccountMinimumBalanceAfter(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	b := TestMakeBlockAccount()
+	require.NoError(t, b.Save(st))
+	require.NoError(t, SaveAccountData(st, b.Address, "a", []byte("1")))
+
+	newOps := func(bodies ...operation.Body) (ops []operation.Operation) {
+		for _, body := range bodies {
+			op, err := operation.NewOperation(body)
+			require.NoError(t, err)
+			ops = append(ops, op)
+		}
+		return
+	}
+
+	check := func(expected int, ops []operation.Operation) {
+		minimum, err := MinimumBalanceAfter(st, b, ops)
+		require.NoError(t, err)
+		require.Equal(t, common.EntryReserve*common.Amount(expected), minimum)
+	}
+
+	check(1, nil)
+
+	// new data is added, and the existing one is not counted again
+	check(2, newOps(operation.NewManageData("b", []byte("2"))))
+	check(1, newOps(operation.NewManageData("a", []byte("2"))))
+
+	// deleted data
+	check(0, newOps(operation.NewManageData("a", nil)))
+	check(1, newOps(operation.NewManageData("a", nil), operation.NewManageData("a", []byte("3"))))
+	check(1, newOps(operation.NewManageData("b", []byte("2")), operation.NewManageData("b", nil)))
+
+	// signers are replaced by the last `SetSigners`
+	signer := operation.Signer{Address: b.Address, Weight: 1}
+	check(2, newOps(operation.NewSetSigners(1, signer)))
+
+	b.Signers = []operation.Signer{signer}
+	check(2, nil)
+	check(1, newOps(operation.NewSetSigners(0)))
+}
@@ -3,6 +3,7 @@ package block
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/btcsuite/btcutil/base58"
 
@@ -15,6 +16,16 @@ import (
 
 const (
 	EventBlockPrefix string = "bk-saved"
+
+	// EventBlockConflict is triggered with the saved and the rejected
+	// `*Block`, when the different block is tried to be saved at the same
+	// height.
+	EventBlockConflict string = "bk-conflict"
+
+	// EventBlockHeightRegression is triggered with the highest stored height
+	// and the rejected `*Block`, when the block lower than the highest stored
+	// one is tried to be saved; see `GetBlockHeightWatermark()`.
+	EventBlockHeightRegression string = "bk-height-regression"
 )
 
 type Block struct {
@@ -43,22 +54,83 @@ func (bck Block) IsEmpty() bool {
 	return len(bck.Hash) < 1
 }
 
-// NewBlock creates new block; `ptx` represents the
-// `ProposerTransaction.GetHash()`.
-func NewBlock(proposer string, basis voting.Basis, ptx string, transactions []string, confirmed string) *Block {
+// BlockVersion is the current version of block. From version 1,
+// `Header.OperationsRoot` is covered by `Block.Hash`; the block of version 0,
+// like the genesis block and the blocks saved before, is hashed without it.
+const BlockVersion uint32 = 1
+
+// NewBlock creates new block of `BlockVersion`; `ptx` represents the
+// `ProposerTransaction.GetHash()` and `operations` are the hashes of
+// `BlockOperation`s of the block from `GetBlockOperationHashes()`.
+func NewBlock(proposer string, basis voting.Basis, ptx string, transactions, operations []string, confirmed string) *Block {
+	return NewBlockWithVersion(BlockVersion, proposer, basis, ptx, transactions, operations, confirmed)
+}
+
+// NewBlockWithVersion creates new block of the given version; it is used to
+// make the same hash of the block received from the other node.
+func NewBlockWithVersion(version uint32, proposer string, basis voting.Basis, ptx string, transactions, operations []string, confirmed string) *Block {
 	b := &Block{
-		Header:              *NewBlockHeader(basis, getTransactionRoot(transactions)),
+		Header:              *NewBlockHeader(basis, getTransactionRoot(transactions), common.MakeMerkleRoot(operations)),
 		Transactions:        transactions,
 		ProposerTransaction: ptx,
 		Proposer:            proposer,
 		Round:               basis.Round,
 		Confirmed:           confirmed,
 	}
+	b.Version = version
 
-	b.Hash = base58.Encode(common.MustMakeObjectHash(b))
+	b.Hash = b.MakeHash()
 	return b
 }
 
+// legacyHeader is the `Header` of block version 0, which does not have
+// `OperationsRoot`.
+type legacyHeader struct {
+	Version          uint32
+	PrevBlockHash    string
+	TransactionsRoot string
+	Timestamp        time.Time
+	Height           uint64
+	TotalTxs         uint64
+	TotalOps         uint64
+}
+
+type legacyBlock struct {
+	Header              legacyHeader
+	Transactions        []string
+	ProposerTransaction string
+	Hash                string
+	Confirmed           string
+	Proposer            string
+	Round               uint64
+}
+
+// MakeHash makes the hash of block by it's `Version`; `Block.Hash` itself is
+// not included.
+func (b Block) MakeHash() string {
+	b.Hash = ""
+	if b.Version > 0 {
+		return base58.Encode(common.MustMakeObjectHash(b))
+	}
+
+	return base58.Encode(common.MustMakeObjectHash(legacyBlock{
+		Header: legacyHeader{
+			Version:          b.Version,
+			PrevBlockHash:    b.PrevBlockHash,
+			TransactionsRoot: b.TransactionsRoot,
+			Timestamp:        b.Timestamp,
+			Height:           b.Height,
+			TotalTxs:         b.TotalTxs,
+			TotalOps:         b.TotalOps,
+		},
+		Transactions:        b.Transactions,
+		ProposerTransaction: b.ProposerTransaction,
+		Confirmed:           b.Confirmed,
+		Proposer:            b.Proposer,
+		Round:               b.Round,
+	}))
+}
+
 func getTransactionRoot(txs []string) string {
 	return common.MustMakeObjectHashString(txs) // TODO make root
 }
@@ -91,6 +163,13 @@ func (b *Block) Save(st *storage.LevelDBBackend) (err error) {
 		return errors.BlockAlreadyExists
 	}
 
+	if err = b.checkHeightRegression(st); err != nil {
+		return
+	}
+	if err = b.checkConflict(st); err != nil {
+		return
+	}
+
 	if err = st.New(key, b); err != nil {
 		return
 	}
@@ -101,12 +180,43 @@ func (b *Block) Save(st *storage.LevelDBBackend) (err error) {
 	if err = st.New(getBlockKeyPrefixHeight(b.Height), b.Hash); err != nil {
 		return
 	}
+	if err = raiseBlockHeightWatermark(st, b.Height); err != nil {
+		return
+	}
+	if err = raiseBlockLegacyHeight(st, *b); err != nil {
+		return
+	}
 
 	observer.BlockObserver.Trigger(EventBlockPrefix, b)
 
 	return
 }
 
+// checkConflict checks whether the different block was already saved at the
+// same height. With the honest majority it never happens, but the block from
+// the buggy peer must not overwrite the confirmed one, so it is rejected and
+// `EventBlockConflict` is triggered.
+func (b *Block) checkConflict(st *storage.LevelDBBackend) (err error) {
+	var saved Block
+	if saved, err = GetBlockByHeight(st, b.Height); err != nil {
+		if err == errors.StorageRecordDoesNotExist {
+			err = nil
+		}
+		return
+	}
+
+	if saved.Hash == b.Hash {
+		return errors.BlockAlreadyExists
+	}
+
+	observer.BlockObserver.Trigger(EventBlockConflict, &saved, b)
+
+	return errors.BlockConflict.Clone().
+		SetData("height", b.Height).
+		SetData("saved", saved.Hash).
+		SetData("rejected", b.Hash)
+}
+
 func (b Block) PreviousBlock(st *storage.LevelDBBackend) (blk Block, err error) {
 	if b.Height == common.GenesisBlockHeight {
 		err = errors.StorageRecordDoesNotExist
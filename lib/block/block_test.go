@@ -1,15 +1,18 @@
 package block
 
 import (
+	"encoding/json"
 	"math/rand"
 	"testing"
 	"time"
 
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/common/observer"
 	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/storage"
 	"boscoin.io/sebak/lib/transaction/operation"
+	"boscoin.io/sebak/lib/voting"
 
 	"github.com/stretchr/testify/require"
 )
@@ -102,6 +105,192 @@ func TestBlockHeightOrdering(t *testing.T) {
 
 // TestMakeGenesisBlock basically tests MakeGenesisBlock can make genesis block,
 // and further with genesis block, genesis account can be found.
+// TestBlockSaveConflict checks the different block can not be saved at the
+// height of the saved block.
+func TestBlockSaveConflict(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	genesis := GetLatestBlock(st)
+
+	var triggered []*Block
+	observerFunc := func(args ...interface{}) {
+		triggered = append(triggered, args[0].(*Block), args[1].(*Block))
+	}
+	observer.BlockObserver.On(EventBlockConflict, observerFunc)
+	defer observer.BlockObserver.Off(EventBlockConflict, observerFunc)
+
+	first := TestMakeNewBlockWithPrevBlock(genesis, []string{})
+	first.MustSave(st)
+
+	second := TestMakeNewBlockWithPrevBlock(genesis, []string{})
+	require.Equal(t, first.Height, second.Height)
+	require.NotEqual(t, first.Hash, second.Hash)
+
+	err := second.Save(st)
+	require.Error(t, err)
+	require.Equal(t, errors.BlockConflict.Code, err.(*errors.Error).Code)
+
+	// the saved block is kept
+	saved, err := GetBlockByHeight(st, first.Height)
+	require.NoError(t, err)
+	require.Equal(t, first.Hash, saved.Hash)
+
+	exists, err := ExistsBlock(st, second.Hash)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.Equal(t, 2, len(triggered))
+	require.Equal(t, first.Hash, triggered[0].Hash)
+	require.Equal(t, second.Hash, triggered[1].Hash)
+
+	// the same block is just already saved
+	require.Equal(t, errors.BlockAlreadyExists, first.Save(st))
+	require.Equal(t, 2, len(triggered))
+
+	// the next height is not affected
+	next := TestMakeNewBlockWithPrevBlock(first, []string{})
+	require.NoError(t, next.Save(st))
+}
+
+// TestBlockSaveHeightRegression checks the block lower than the highest
+// stored block can not be saved, even if the record of its height is gone,
+// until the watermark is reset.
+func TestBlockSaveHeightRegression(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	genesis := GetLatestBlock(st)
+
+	var triggered []uint64
+	observerFunc := func(args ...interface{}) {
+		triggered = append(triggered, args[0].(uint64), args[1].(*Block).Height)
+	}
+	observer.BlockObserver.On(EventBlockHeightRegression, observerFunc)
+	defer observer.BlockObserver.Off(EventBlockHeightRegression, observerFunc)
+
+	first := TestMakeNewBlockWithPrevBlock(genesis, []string{})
+	first.MustSave(st)
+	second := TestMakeNewBlockWithPrevBlock(first, []string{})
+	second.MustSave(st)
+
+	watermark, err := GetBlockHeightWatermark(st)
+	require.NoError(t, err)
+	require.Equal(t, second.Height, watermark)
+
+	// the height record of the first block is lost, like by the broken sync
+	require.NoError(t, st.Remove(getBlockKeyPrefixHeight(first.Height)))
+
+	lower := TestMakeNewBlockWithPrevBlock(genesis, []string{})
+	require.Equal(t, first.Height, lower.Height)
+
+	err = lower.Save(st)
+	require.Error(t, err)
+	require.Equal(t, errors.BlockHeightRegression.Code, err.(*errors.Error).Code)
+
+	exists, err := ExistsBlock(st, lower.Hash)
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.Equal(t, []uint64{second.Height, lower.Height}, triggered)
+
+	// the block of the highest height is not affected
+	require.Equal(t, errors.BlockAlreadyExists, second.Save(st))
+
+	// the operator resets the watermark explicitly
+	require.NoError(t, ResetBlockHeightWatermark(st, genesis.Height))
+	require.NoError(t, lower.Save(st))
+	require.Equal(t, 2, len(triggered))
+
+	watermark, err = GetBlockHeightWatermark(st)
+	require.NoError(t, err)
+	require.Equal(t, lower.Height, watermark)
+}
+
+func TestBlockVersion(t *testing.T) {
+	basis := voting.Basis{
+		Round:     3,
+		Height:    10,
+		BlockHash: "7x3Ta5ejfb1kUSQSuXBwj6hrxgfAR7bf8BtD2TSokaXX",
+		TotalTxs:  12,
+		TotalOps:  15,
+	}
+	proposer := "GBAE7LEJMSGYOBUTXDN7WWGZIE6BFKWDNBP6F7XGOJLUBIVDAPRCNAHW"
+	transactions := []string{"tx-hash-1", "tx-hash-2"}
+	operations := []string{"op-hash-1", "op-hash-2", "op-hash-3"}
+	confirmed := "2018-12-01T00:00:00.000000000Z"
+
+	// the hash of the block made before `Header.OperationsRoot`
+	legacyHash := "5PXd9fe6ACUvNU3ScohddPfVCDvxP2N6SWD5TozvAeh1"
+
+	{ // the saved block of version 0 still validates
+		var legacy Block
+		encoded := []byte(`{
+			"prev_block_hash": "7x3Ta5ejfb1kUSQSuXBwj6hrxgfAR7bf8BtD2TSokaXX",
+			"transactions_root": "` + getTransactionRoot(transactions) + `",
+			"height": 10, "total-txs": 12, "total-ops": 15,
+			"transactions": ["tx-hash-1", "tx-hash-2"],
+			"proposer_transaction": "ptx-hash",
+			"hash": "` + legacyHash + `",
+			"confirmed": "` + confirmed + `",
+			"proposer": "` + proposer + `",
+			"round": 3
+		}`)
+		require.NoError(t, json.Unmarshal(encoded, &legacy))
+		require.Equal(t, uint32(0), legacy.Version)
+		require.Equal(t, legacyHash, legacy.MakeHash())
+
+		blk := NewBlockWithVersion(legacy.Version, proposer, basis, "ptx-hash", transactions, operations, confirmed)
+		require.Equal(t, legacyHash, blk.Hash)
+		require.Equal(t, common.MakeMerkleRoot(operations), blk.OperationsRoot)
+	}
+
+	{ // `OperationsRoot` is covered by the hash from version 1
+		blk := NewBlock(proposer, basis, "ptx-hash", transactions, operations, confirmed)
+		require.Equal(t, BlockVersion, blk.Version)
+		require.NotEqual(t, legacyHash, blk.Hash)
+		require.Equal(t, blk.Hash, blk.MakeHash())
+
+		other := NewBlock(proposer, basis, "ptx-hash", transactions, operations[:2], confirmed)
+		require.NotEqual(t, blk.Hash, other.Hash)
+
+		blk.OperationsRoot = other.OperationsRoot
+		require.NotEqual(t, blk.Hash, blk.MakeHash())
+	}
+}
+
+func TestBlockLegacyHeight(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	genesis := GetLatestBlock(st)
+	require.Equal(t, uint32(0), genesis.Version)
+
+	height, err := GetBlockLegacyHeight(st)
+	require.NoError(t, err)
+	require.Equal(t, genesis.Height, height)
+
+	// the block of `BlockVersion` does not raise it
+	first := TestMakeNewBlockWithPrevBlock(genesis, []string{})
+	first.MustSave(st)
+	height, err = GetBlockLegacyHeight(st)
+	require.NoError(t, err)
+	require.Equal(t, genesis.Height, height)
+
+	{ // the blocks stored before it is recorded
+		require.NoError(t, st.Remove(GetBlockLegacyHeightKey()))
+		height, err = GetBlockLegacyHeight(st)
+		require.NoError(t, err)
+		require.Equal(t, common.GenesisBlockHeight, height)
+
+		legacy := *NewBlockWithVersion(0, first.Proposer, voting.Basis{Height: first.Height + 1, BlockHash: first.Hash}, "", []string{}, nil, common.NowISO8601())
+		legacy.MustSave(st)
+		require.NoError(t, st.Remove(GetBlockLegacyHeightKey()))
+		height, err = GetBlockLegacyHeight(st)
+		require.NoError(t, err)
+		require.Equal(t, legacy.Height, height)
+	}
+}
+
 func TestMakeGenesisBlock(t *testing.T) {
 	st := storage.NewTestStorage()
 	defer st.Close()
@@ -125,6 +314,8 @@ func TestMakeGenesisBlock(t *testing.T) {
 	require.Equal(t, "", bk.PrevBlockHash)
 	require.Equal(t, "", bk.Proposer)
 	require.Equal(t, common.GenesisBlockConfirmedTime, bk.Confirmed)
+	require.Equal(t, uint32(0), bk.Version)
+	require.Equal(t, bk.Hash, bk.MakeHash())
 
 	// transaction
 	{
@@ -283,3 +474,50 @@ func TestMakeGenesisBlockFindCommonAccount(t *testing.T) {
 		require.Equal(t, commonAccount.SequenceID, ac.SequenceID)
 	}
 }
+
+func TestVerifyGenesis(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	expected := GenesisParams{
+		NetworkID:      networkID,
+		InitialBalance: common.MaximumBalance,
+		CommonAccount:  CommonKP.Address(),
+	}
+	require.NoError(t, VerifyGenesis(st, expected))
+
+	checkMismatch := func(params GenesisParams, field string) {
+		err := VerifyGenesis(st, params)
+		require.Error(t, err)
+
+		e, ok := err.(*errors.Error)
+		require.True(t, ok)
+		require.Equal(t, errors.GenesisNotMatched.Code, e.Code)
+		require.Equal(t, field, e.Data["field"])
+	}
+
+	{ // different network id
+		params := expected
+		params.NetworkID = []byte("other-network")
+		checkMismatch(params, "network_id")
+	}
+
+	{ // different initial balance
+		params := expected
+		params.InitialBalance = common.MaximumBalance - 1
+		checkMismatch(params, "initial_balance")
+	}
+
+	{ // different common account
+		params := expected
+		params.CommonAccount = keypair.Random().Address()
+		checkMismatch(params, "common_account")
+	}
+
+	{ // no genesis block
+		st := storage.NewTestStorage()
+		defer st.Close()
+
+		require.Error(t, VerifyGenesis(st, expected))
+	}
+}
@@ -0,0 +1,72 @@
+package block
+
+import (
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// TxFeeBreakdown is the fee collected in the block by the source account of
+// transactions. `Burned` of `Total` is burned by `common.FeeBurnPolicy` and
+// the rest, `Deposited()`, is paid into the common account.
+type TxFeeBreakdown struct {
+	Total    common.Amount            `json:"total"`
+	Burned   common.Amount            `json:"burned"`
+	BySource map[string]common.Amount `json:"by_source"`
+}
+
+// Deposited returns the fee paid into the common account.
+func (b TxFeeBreakdown) Deposited() common.Amount {
+	return b.Total - b.Burned
+}
+
+// GetBlockTxFeeBreakdown returns the fee collected in the block. The total is
+// checked with `CollectTxFee.Amount` of the proposer transaction, which is the
+// whole collected fee before burning; `CollectTxFee.GetAmount()`, which was
+// paid into the common account, is same with `TxFeeBreakdown.Deposited()`.
+func GetBlockTxFeeBreakdown(st *storage.LevelDBBackend, blk Block) (breakdown TxFeeBreakdown, err error) {
+	breakdown.BySource = map[string]common.Amount{}
+
+	for _, hash := range blk.Transactions {
+		var bt BlockTransaction
+		if bt, err = GetBlockTransaction(st, hash); err != nil {
+			return
+		}
+
+		if breakdown.Total, err = breakdown.Total.Add(bt.Fee); err != nil {
+			return
+		}
+		if breakdown.BySource[bt.Source], err = breakdown.BySource[bt.Source].Add(bt.Fee); err != nil {
+			return
+		}
+	}
+
+	// genesis block does not have proposer transaction
+	if len(blk.ProposerTransaction) < 1 {
+		return
+	}
+
+	var tp TransactionPool
+	if tp, err = GetTransactionPool(st, blk.ProposerTransaction); err != nil {
+		return
+	}
+
+	var collected common.Amount
+	for _, op := range tp.Transaction().B.Operations {
+		if opb, ok := op.B.(operation.CollectTxFee); ok {
+			collected = opb.Amount
+			breakdown.Burned = opb.Burned
+			break
+		}
+	}
+
+	if collected != breakdown.Total {
+		err = errors.CollectedTxFeeNotMatched.Clone().
+			SetData("collected", collected).
+			SetData("total", breakdown.Total)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,117 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func TestGetBlockTxFeeBreakdown(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	kpA := keypair.Random()
+	kpB := keypair.Random()
+
+	// the fee of transaction is `BaseFee` * number of operations
+	txs := []transaction.Transaction{
+		transaction.TestMakeTransactionWithKeypair(networkID, 1, kpA),
+		transaction.TestMakeTransactionWithKeypair(networkID, 3, kpA),
+		transaction.TestMakeTransactionWithKeypair(networkID, 2, kpB),
+	}
+
+	var txHashes []string
+	for _, tx := range txs {
+		txHashes = append(txHashes, tx.GetHash())
+	}
+
+	makeBlock := func(st *storage.LevelDBBackend, collected common.Amount, policy common.FeeBurnPolicy) Block {
+		opb := operation.NewCollectTxFee(CommonKP.Address(), collected, uint64(len(txs)), 1, "block-hash", 1)
+		opb.Burned = policy.Burned(collected)
+		op, err := operation.NewOperation(opb)
+		require.NoError(t, err)
+		ptx, err := transaction.NewTransaction(CommonKP.Address(), 0, op)
+		require.NoError(t, err)
+		ptx.Sign(CommonKP, networkID)
+
+		blk := TestMakeNewBlock(txHashes)
+		blk.ProposerTransaction = ptx.GetHash()
+
+		for _, tx := range txs {
+			bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.Confirmed, tx)
+			bt.MustSave(st)
+		}
+		_, err = SaveTransactionPool(st, ptx)
+		require.NoError(t, err)
+
+		return blk
+	}
+
+	expected := TxFeeBreakdown{
+		Total: common.BaseFee.MustMult(6),
+		BySource: map[string]common.Amount{
+			kpA.Address(): common.BaseFee.MustMult(4),
+			kpB.Address(): common.BaseFee.MustMult(2),
+		},
+	}
+
+	{ // matched with `CollectTxFee`
+		blk := makeBlock(st, common.BaseFee.MustMult(6), common.DefaultFeeBurnPolicy)
+
+		breakdown, err := GetBlockTxFeeBreakdown(st, blk)
+		require.NoError(t, err)
+		require.Equal(t, expected, breakdown)
+		require.Equal(t, expected.Total, breakdown.Deposited())
+	}
+
+	{ // with burning, the common account gets the rest of burned
+		st := storage.NewTestStorage()
+		defer st.Close()
+
+		policy := common.FeeBurnPolicy{Ratio: 2500}
+		blk := makeBlock(st, common.BaseFee.MustMult(6), policy)
+
+		breakdown, err := GetBlockTxFeeBreakdown(st, blk)
+		require.NoError(t, err)
+		require.Equal(t, expected.Total, breakdown.Total)
+		require.Equal(t, expected.BySource, breakdown.BySource)
+		require.Equal(t, common.BaseFee.MustMult(6)/4, breakdown.Burned)
+		require.Equal(t, expected.Total-breakdown.Burned, breakdown.Deposited())
+
+		tp, err := GetTransactionPool(st, blk.ProposerTransaction)
+		require.NoError(t, err)
+		opb := tp.Transaction().B.Operations[0].B.(operation.CollectTxFee)
+		require.Equal(t, opb.GetAmount(), breakdown.Deposited())
+	}
+
+	{ // not matched with `CollectTxFee`
+		st := storage.NewTestStorage()
+		defer st.Close()
+
+		blk := makeBlock(st, common.BaseFee.MustMult(5), common.DefaultFeeBurnPolicy)
+		_, err := GetBlockTxFeeBreakdown(st, blk)
+		require.Equal(t, errors.CollectedTxFeeNotMatched.Code, err.(*errors.Error).Code)
+	}
+
+	{ // genesis block does not collect fee
+		st := InitTestBlockchain()
+		defer st.Close()
+
+		breakdown, err := GetBlockTxFeeBreakdown(st, GetGenesis(st))
+		require.NoError(t, err)
+		require.Equal(t, common.Amount(0), breakdown.Total)
+	}
+
+	{ // unknown transaction
+		blk := TestMakeNewBlock([]string{"unknown"})
+		_, err := GetBlockTxFeeBreakdown(st, blk)
+		require.Equal(t, errors.StorageRecordDoesNotExist, err)
+	}
+}
@@ -8,6 +8,8 @@ package block
 import (
 	"fmt"
 
+	"github.com/btcsuite/btcutil/base58"
+
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/keypair"
 	"boscoin.io/sebak/lib/errors"
@@ -101,7 +103,10 @@ func MakeGenesisBlock(st *storage.LevelDBBackend, genesisAccount BlockAccount, c
 	kp := keypair.Master(string(networkID))
 	tx.Sign(kp, []byte(networdID))
 
-	blk = NewBlock(
+	// the genesis block keeps version 0, so the hash of genesis block does not
+	// change by the block version
+	blk = NewBlockWithVersion(
+		0,
 		"",
 		voting.Basis{
 			Height:   common.GenesisBlockHeight,
@@ -110,6 +115,7 @@ func MakeGenesisBlock(st *storage.LevelDBBackend, genesisAccount BlockAccount, c
 		},
 		"",
 		[]string{tx.GetHash()},
+		GetBlockOperationHashes(tx),
 		common.GenesisBlockConfirmedTime,
 	)
 	if err = blk.Save(st); err != nil {
@@ -130,3 +136,79 @@ func MakeGenesisBlock(st *storage.LevelDBBackend, genesisAccount BlockAccount, c
 
 	return
 }
+
+// GenesisParams is the expected parameters of genesis block.
+type GenesisParams struct {
+	NetworkID      []byte
+	InitialBalance common.Amount // balance of genesis account
+	CommonAccount  string        // address of common account
+}
+
+func genesisNotMatched(field string, expected, actual interface{}) error {
+	return errors.GenesisNotMatched.Clone().
+		SetData("field", field).
+		SetData("expected", expected).
+		SetData("actual", actual)
+}
+
+// VerifyGenesis checks the genesis block in the storage is made with the
+// expected parameters, like `MakeGenesisBlock()`. The node, which has the
+// different genesis block, can not be in the same network.
+func VerifyGenesis(st *storage.LevelDBBackend, expected GenesisParams) (err error) {
+	var blk Block
+	if blk, err = GetBlockByHeight(st, common.GenesisBlockHeight); err != nil {
+		return
+	}
+	if len(blk.Transactions) != 1 {
+		err = errors.WrongBlockFound
+		return
+	}
+
+	var bt BlockTransaction
+	if bt, err = GetBlockTransaction(st, blk.Transactions[0]); err != nil {
+		return
+	}
+	if len(bt.Operations) != 2 {
+		err = errors.WrongBlockFound
+		return
+	}
+
+	// genesis transaction is signed by `keypair.Master(string(networkID))`
+	kp := keypair.Master(string(expected.NetworkID))
+	if bt.Source != kp.Address() ||
+		kp.Verify(append(expected.NetworkID, []byte(bt.Hash)...), base58.Decode(bt.Signature)) != nil {
+		err = genesisNotMatched("network_id", string(expected.NetworkID), "")
+		return
+	}
+
+	var ops []operation.CreateAccount
+	for _, hash := range bt.Operations {
+		var bo BlockOperation
+		if bo, err = GetBlockOperation(st, hash); err != nil {
+			return
+		}
+
+		var opb operation.Body
+		if opb, err = operation.UnmarshalBodyJSON(bo.Type, bo.Body); err != nil {
+			return
+		}
+
+		op, ok := opb.(operation.CreateAccount)
+		if !ok {
+			err = errors.WrongBlockFound
+			return
+		}
+		ops = append(ops, op)
+	}
+
+	if ops[0].Amount != expected.InitialBalance {
+		err = genesisNotMatched("initial_balance", expected.InitialBalance, ops[0].Amount)
+		return
+	}
+	if ops[1].Target != expected.CommonAccount {
+		err = genesisNotMatched("common_account", expected.CommonAccount, ops[1].Target)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,192 @@
+package block
+
+import (
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// GovernanceProposal is the proposal, which is opened by
+// `operation.GovernanceProposal`. `Hash` is the hash of the `BlockOperation`
+// of the proposal, so the votes refer to the proposal by it.
+//
+// After the block of `CloseHeight` is stored, the votes are tallied by
+// `TallyGovernanceProposals()`; the weight of vote is the balance of the voter
+// at the close height, so the coins can not be counted twice by moving them.
+type GovernanceProposal struct {
+	Hash        string `json:"hash"`
+	Source      string `json:"source"`
+	Title       string `json:"title"`
+	Contract    []byte `json:"contract"`
+	CloseHeight uint64 `json:"close_height"`
+	Height      uint64 `json:"height"` // the height of block, which the proposal is included
+
+	Closed  bool          `json:"closed"`
+	Yes     common.Amount `json:"yes"`
+	No      common.Amount `json:"no"`
+	Abstain common.Amount `json:"abstain"`
+	Passed  bool          `json:"passed"` // `Yes` is more than `No`
+}
+
+// GovernanceVote is the vote of `Voter` by `operation.GovernanceVote`.
+type GovernanceVote struct {
+	Proposal string                     `json:"proposal"`
+	Voter    string                     `json:"voter"`
+	Choice   operation.GovernanceChoice `json:"choice"`
+	Height   uint64                     `json:"height"`
+}
+
+func NewGovernanceProposal(hash, source string, op operation.GovernanceProposal, height uint64) GovernanceProposal {
+	return GovernanceProposal{
+		Hash:        hash,
+		Source:      source,
+		Title:       op.Title,
+		Contract:    op.Contract,
+		CloseHeight: op.CloseHeight,
+		Height:      height,
+	}
+}
+
+func GetGovernanceProposalKey(hash string) string {
+	return fmt.Sprintf("%s%s", common.GovernanceProposalPrefixHash, hash)
+}
+
+func getGovernanceProposalKeyPrefixCloseHeight(closeHeight uint64) string {
+	return fmt.Sprintf("%s%020d", common.GovernanceProposalPrefixCloseHeight, closeHeight)
+}
+
+func getGovernanceVoteKeyPrefixProposal(proposal string) string {
+	return fmt.Sprintf("%s%s-", common.GovernanceVotePrefixProposal, proposal)
+}
+
+func GetGovernanceVoteKey(proposal, voter string) string {
+	return fmt.Sprintf("%s%s", getGovernanceVoteKeyPrefixProposal(proposal), voter)
+}
+
+// Save stores the new proposal with the index by the close height.
+func (p GovernanceProposal) Save(st *storage.LevelDBBackend) (err error) {
+	if err = st.New(GetGovernanceProposalKey(p.Hash), p); err != nil {
+		return
+	}
+
+	return st.New(getGovernanceProposalKeyPrefixCloseHeight(p.CloseHeight)+p.Hash, p.Hash)
+}
+
+// GetGovernanceProposal returns the proposal; if it does not exist, it
+// returns `errors.GovernanceProposalDoesNotExist`.
+func GetGovernanceProposal(st *storage.LevelDBBackend, hash string) (p GovernanceProposal, err error) {
+	if err = st.Get(GetGovernanceProposalKey(hash), &p); err == errors.StorageRecordDoesNotExist {
+		err = errors.GovernanceProposalDoesNotExist
+	}
+	return
+}
+
+// Save stores the vote; the later vote of the same voter overwrites the
+// former.
+func (v GovernanceVote) Save(st *storage.LevelDBBackend) (err error) {
+	key := GetGovernanceVoteKey(v.Proposal, v.Voter)
+
+	var exists bool
+	if exists, err = st.Has(key); err != nil {
+		return
+	} else if exists {
+		return st.Set(key, v)
+	}
+
+	return st.New(key, v)
+}
+
+// GetGovernanceVotes returns the votes of the proposal in the order of voter.
+func GetGovernanceVotes(st *storage.LevelDBBackend, proposal string) (votes []GovernanceVote, err error) {
+	iterFunc, closeFunc := st.GetIterator(getGovernanceVoteKeyPrefixProposal(proposal), nil)
+	defer closeFunc()
+
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var v GovernanceVote
+		if err = common.DecodeJSONValue(item.Value, &v); err != nil {
+			return
+		}
+		votes = append(votes, v)
+	}
+
+	return
+}
+
+// TallyGovernanceProposals closes the proposals of `height` as close height
+// and counts the votes by the balance of voters. The voter, which does not
+// exist any more, is not counted. It returns the closed proposals in the order
+// of hash.
+func TallyGovernanceProposals(st *storage.LevelDBBackend, height uint64) (proposals []GovernanceProposal, err error) {
+	var hashes []string
+	{
+		iterFunc, closeFunc := st.GetIterator(getGovernanceProposalKeyPrefixCloseHeight(height), nil)
+		for {
+			item, hasNext := iterFunc()
+			if !hasNext {
+				break
+			}
+
+			var hash string
+			if err = common.DecodeJSONValue(item.Value, &hash); err != nil {
+				closeFunc()
+				return
+			}
+			hashes = append(hashes, hash)
+		}
+		closeFunc()
+	}
+
+	for _, hash := range hashes {
+		var p GovernanceProposal
+		if p, err = GetGovernanceProposal(st, hash); err != nil {
+			return
+		}
+		if p.Closed {
+			continue
+		}
+
+		var votes []GovernanceVote
+		if votes, err = GetGovernanceVotes(st, hash); err != nil {
+			return
+		}
+
+		for _, v := range votes {
+			var ba *BlockAccount
+			if ba, err = GetBlockAccount(st, v.Voter); err == errors.StorageRecordDoesNotExist {
+				err = nil
+				continue
+			} else if err != nil {
+				return
+			}
+
+			switch v.Choice {
+			case operation.GovernanceChoiceYes:
+				p.Yes, err = p.Yes.Add(ba.Balance)
+			case operation.GovernanceChoiceNo:
+				p.No, err = p.No.Add(ba.Balance)
+			default:
+				p.Abstain, err = p.Abstain.Add(ba.Balance)
+			}
+			if err != nil {
+				return
+			}
+		}
+
+		p.Closed = true
+		p.Passed = p.Yes > p.No
+		if err = st.Set(GetGovernanceProposalKey(p.Hash), p); err != nil {
+			return
+		}
+		proposals = append(proposals, p)
+	}
+
+	return
+}
@@ -0,0 +1,84 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func TestTallyGovernanceProposals(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	var voters []keypair.KP
+	for _, balance := range []common.Amount{100, 200, 400, 800} {
+		kp := keypair.Random()
+		NewBlockAccount(kp.Address(), balance).MustSave(st)
+		voters = append(voters, kp)
+	}
+
+	_, err := GetGovernanceProposal(st, "proposal")
+	require.Equal(t, errors.GovernanceProposalDoesNotExist, err)
+
+	closeHeight := uint64(5)
+	p := NewGovernanceProposal("proposal", voters[0].Address(), operation.NewGovernanceProposal("title", nil, closeHeight), 2)
+	require.NoError(t, p.Save(st))
+	other := NewGovernanceProposal("other", voters[0].Address(), operation.NewGovernanceProposal("title", nil, closeHeight+1), 2)
+	require.NoError(t, other.Save(st))
+
+	vote := func(kp keypair.KP, choice operation.GovernanceChoice) {
+		v := GovernanceVote{Proposal: p.Hash, Voter: kp.Address(), Choice: choice, Height: 3}
+		require.NoError(t, v.Save(st))
+	}
+	vote(voters[0], operation.GovernanceChoiceYes)
+	vote(voters[1], operation.GovernanceChoiceNo)
+	vote(voters[2], operation.GovernanceChoiceYes)
+	vote(voters[3], operation.GovernanceChoiceYes)
+	vote(voters[3], operation.GovernanceChoiceAbstain) // overwrites
+
+	votes, err := GetGovernanceVotes(st, p.Hash)
+	require.NoError(t, err)
+	require.Equal(t, 4, len(votes))
+
+	{ // nothing to close
+		closed, err := TallyGovernanceProposals(st, closeHeight-1)
+		require.NoError(t, err)
+		require.Empty(t, closed)
+	}
+
+	// the weight is the balance at the close height
+	ba, _ := GetBlockAccount(st, voters[1].Address())
+	ba.Balance = 1000
+	ba.MustSave(st)
+
+	closed, err := TallyGovernanceProposals(st, closeHeight)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(closed))
+	require.Equal(t, p.Hash, closed[0].Hash)
+	require.True(t, closed[0].Closed)
+	require.Equal(t, common.Amount(500), closed[0].Yes)
+	require.Equal(t, common.Amount(1000), closed[0].No)
+	require.Equal(t, common.Amount(800), closed[0].Abstain)
+	require.False(t, closed[0].Passed)
+
+	stored, err := GetGovernanceProposal(st, p.Hash)
+	require.NoError(t, err)
+	require.Equal(t, closed[0], stored)
+
+	{ // closed once
+		closed, err := TallyGovernanceProposals(st, closeHeight)
+		require.NoError(t, err)
+		require.Empty(t, closed)
+	}
+
+	{ // the other is not closed
+		stored, err := GetGovernanceProposal(st, other.Hash)
+		require.NoError(t, err)
+		require.False(t, stored.Closed)
+	}
+}
@@ -12,6 +12,7 @@ type Header struct {
 	Version          uint32    `json:"version"`
 	PrevBlockHash    string    `json:"prev_block_hash"`   // TODO Uint256 type
 	TransactionsRoot string    `json:"transactions_root"` // Merkle root of Txs // TODO Uint256 type
+	OperationsRoot   string    `json:"operations_root"`   // Merkle root of `BlockOperation.Hash`s; see `GetBlockOperationHashes()`
 	Timestamp        time.Time `json:"timestamp"`
 	Height           uint64    `json:"height"`
 	TotalTxs         uint64    `json:"total-txs"`
@@ -20,7 +21,7 @@ type Header struct {
 	// TODO smart contract fields
 }
 
-func NewBlockHeader(basis voting.Basis, txRoot string) *Header {
+func NewBlockHeader(basis voting.Basis, txRoot, opRoot string) *Header {
 	return &Header{
 		PrevBlockHash:    basis.BlockHash,
 		Timestamp:        time.Now(),
@@ -28,6 +29,7 @@ func NewBlockHeader(basis voting.Basis, txRoot string) *Header {
 		TotalTxs:         basis.TotalTxs,
 		TotalOps:         basis.TotalOps,
 		TransactionsRoot: txRoot,
+		OperationsRoot:   opRoot,
 	}
 }
 
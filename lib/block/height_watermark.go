@@ -0,0 +1,72 @@
+package block
+
+import (
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/observer"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+func GetBlockHeightWatermarkKey() string {
+	return common.BlockPrefixHeightWatermark
+}
+
+// GetBlockHeightWatermark returns the highest height of the stored blocks. It
+// is kept even if the blocks are pruned or removed, so the node never goes
+// back to the lower height by the sync or the import; if no block was stored
+// after the watermark was introduced, it returns 0.
+func GetBlockHeightWatermark(st *storage.LevelDBBackend) (height uint64, err error) {
+	if err = st.Get(GetBlockHeightWatermarkKey(), &height); err == errors.StorageRecordDoesNotExist {
+		err = nil
+	}
+	return
+}
+
+func setBlockHeightWatermark(st *storage.LevelDBBackend, height uint64) (err error) {
+	var exists bool
+	if exists, err = st.Has(GetBlockHeightWatermarkKey()); err != nil {
+		return
+	} else if exists {
+		return st.Set(GetBlockHeightWatermarkKey(), height)
+	}
+
+	return st.New(GetBlockHeightWatermarkKey(), height)
+}
+
+func raiseBlockHeightWatermark(st *storage.LevelDBBackend, height uint64) (err error) {
+	var watermark uint64
+	if watermark, err = GetBlockHeightWatermark(st); err != nil || watermark >= height {
+		return
+	}
+
+	return setBlockHeightWatermark(st, height)
+}
+
+// ResetBlockHeightWatermark lowers the watermark to `height`, so the blocks
+// from the next of `height` can be saved again. It is the only way to allow
+// the height regression and must be triggered by the operator explicitly,
+// with the node stopped; see `sebak reset-height`.
+func ResetBlockHeightWatermark(st *storage.LevelDBBackend, height uint64) (err error) {
+	return setBlockHeightWatermark(st, height)
+}
+
+// checkHeightRegression rejects the block lower than the watermark. The
+// confirmed height must not go back by the buggy peer or the mistake in sync,
+// so `EventBlockHeightRegression` is triggered to be alerted.
+func (b *Block) checkHeightRegression(st *storage.LevelDBBackend) (err error) {
+	var watermark uint64
+	if watermark, err = GetBlockHeightWatermark(st); err != nil {
+		return
+	}
+
+	if b.Height >= watermark {
+		return
+	}
+
+	observer.BlockObserver.Trigger(EventBlockHeightRegression, watermark, b)
+
+	return errors.BlockHeightRegression.Clone().
+		SetData("height", b.Height).
+		SetData("watermark", watermark).
+		SetData("rejected", b.Hash)
+}
@@ -0,0 +1,49 @@
+package block
+
+import (
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+func GetBlockLegacyHeightKey() string {
+	return common.BlockPrefixLegacyHeight
+}
+
+// GetBlockLegacyHeight returns the highest height of the stored blocks before
+// `BlockVersion`, whose hash does not cover the operations root. The blocks
+// above it must be of `BlockVersion`. If it was not recorded, like the blocks
+// were stored before it was introduced, the latest block is the legacy one or
+// only the genesis block is.
+func GetBlockLegacyHeight(st *storage.LevelDBBackend) (height uint64, err error) {
+	if err = st.Get(GetBlockLegacyHeightKey(), &height); err != errors.StorageRecordDoesNotExist {
+		return
+	}
+	err = nil
+
+	if latest := GetLatestBlock(st); latest.Version < BlockVersion {
+		return latest.Height, nil
+	}
+
+	return common.GenesisBlockHeight, nil
+}
+
+func raiseBlockLegacyHeight(st *storage.LevelDBBackend, b Block) (err error) {
+	if b.Version >= BlockVersion {
+		return
+	}
+
+	var exists bool
+	if exists, err = st.Has(GetBlockLegacyHeightKey()); err != nil {
+		return
+	} else if !exists {
+		return st.New(GetBlockLegacyHeightKey(), b.Height)
+	}
+
+	var height uint64
+	if err = st.Get(GetBlockLegacyHeightKey(), &height); err != nil || height >= b.Height {
+		return
+	}
+
+	return st.Set(GetBlockLegacyHeightKey(), b.Height)
+}
@@ -3,6 +3,9 @@ package block
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"sync"
+	"time"
 
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/observer"
@@ -30,11 +33,155 @@ type BlockOperation struct {
 	Body   []byte                  `json:"body"`
 	Height uint64                  `json:"block_height"`
 
+	// Memo is the memo of the transaction of operation.
+	Memo *transaction.Memo `json:"memo,omitempty"`
+
 	// transaction will be used only for `Save` time.
 	transaction transaction.Transaction
 	isSaved     bool
 }
 
+// BlockOperationFilter selects `BlockOperation` by the source address and
+// the operation type; the empty field matches any.
+type BlockOperationFilter struct {
+	Source string
+	Type   operation.OperationType
+}
+
+func (f BlockOperationFilter) Match(bo BlockOperation) bool {
+	if len(f.Source) > 0 && f.Source != bo.Source {
+		return false
+	}
+	if len(f.Type) > 0 && f.Type != bo.Type {
+		return false
+	}
+
+	return true
+}
+
+// event returns the narrowest event of `BlockOperation.Save()` for the
+// filter.
+func (f BlockOperationFilter) event() string {
+	switch {
+	case len(f.Source) > 0 && len(f.Type) > 0:
+		return fmt.Sprintf("source-type-%s%s", f.Source, f.Type)
+	case len(f.Source) > 0:
+		return fmt.Sprintf("source-%s", f.Source)
+	default:
+		return "saved"
+	}
+}
+
+// SubscribeBlockOperation calls `handler` with the saved `BlockOperation`,
+// which matches with the filter, so the subscriber does not need to parse the
+// event string of `observer.BlockOperationObserver`. The returned function
+// stops the subscription.
+func SubscribeBlockOperation(filter BlockOperationFilter, handler func(BlockOperation)) (unsubscribe func()) {
+	event := filter.event()
+	cb := func(bo *BlockOperation) {
+		if filter.Match(*bo) {
+			handler(*bo)
+		}
+	}
+
+	observer.BlockOperationObserver.On(event, cb)
+
+	return func() {
+		observer.BlockOperationObserver.Off(event, cb)
+	}
+}
+
+// TransactionInclusion is notified by `SubscribeTransactionInclusion()`, when
+// the `BlockOperation` of the transaction is saved.
+type TransactionInclusion struct {
+	TxHash    string `json:"hash"`
+	Operation string `json:"operation"` // `BlockOperation.Hash`
+	Height    uint64 `json:"block_height"`
+}
+
+// SubscribeTransactionInclusion calls `handler` when the `BlockOperation` of
+// the transaction is saved, so the client does not need to poll the
+// transaction. With `oneShot`, only the first `BlockOperation` is notified;
+// otherwise all the `BlockOperation`s of the transaction are notified until
+// the timeout. If nothing is notified within `timeout`, `handler` is called
+// with `errors.TransactionInclusionTimeout`; `0` means no timeout.
+//
+// The subscription is removed after the one-shot notification, after the
+// timeout or by the returned function. `handler` is called while
+// `BlockOperation.Save()` is triggering, so it must not block nor call the
+// returned function.
+func SubscribeTransactionInclusion(txHash string, timeout time.Duration, oneShot bool, handler func(TransactionInclusion, error)) (unsubscribe func()) {
+	var l sync.Mutex
+	var done, notified bool
+	var timer *time.Timer
+
+	event := fmt.Sprintf("txhash-%s", txHash)
+
+	var cb func(*BlockOperation)
+	var offOnce sync.Once
+	off := func() {
+		offOnce.Do(func() {
+			observer.BlockOperationObserver.Off(event, cb)
+		})
+	}
+
+	cb = func(bo *BlockOperation) {
+		l.Lock()
+		if done {
+			l.Unlock()
+			return
+		}
+		notified = true
+		if oneShot {
+			done = true
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+		l.Unlock()
+
+		if oneShot {
+			// `Off()` waits for the running `Trigger()`
+			go off()
+		}
+
+		handler(TransactionInclusion{TxHash: bo.TxHash, Operation: bo.Hash, Height: bo.Height}, nil)
+	}
+
+	observer.BlockOperationObserver.On(event, cb)
+
+	if timeout > 0 {
+		l.Lock()
+		timer = time.AfterFunc(timeout, func() {
+			l.Lock()
+			if done {
+				l.Unlock()
+				return
+			}
+			done = true
+			timedOut := !notified
+			l.Unlock()
+
+			off()
+			if timedOut {
+				handler(TransactionInclusion{TxHash: txHash}, errors.TransactionInclusionTimeout)
+			}
+		})
+		l.Unlock()
+	}
+
+	return func() {
+		l.Lock()
+		done = true
+		if timer != nil {
+			timer.Stop()
+		}
+		l.Unlock()
+
+		off()
+	}
+}
+
 func NewBlockOperationKey(opHash, txHash string) string {
 	return fmt.Sprintf("%s-%s", opHash, txHash)
 }
@@ -58,12 +205,20 @@ func NewBlockOperationFromOperation(op operation.Operation, tx transaction.Trans
 		Source: tx.B.Source,
 		Body:   body,
 		Height: blockHeight,
+		Memo:   tx.B.Memo,
 
 		transaction: tx,
 	}, nil
 }
 
 func (bo *BlockOperation) Save(st *storage.LevelDBBackend) (err error) {
+	return bo.SaveWithCodec(st, common.DefaultStorageCodec)
+}
+
+// SaveWithCodec saves the `BlockOperation` encoded by the codec; see
+// `common.Config.StorageCodec`. The stored `BlockOperation` of any codec is
+// read by `GetBlockOperation()`.
+func (bo *BlockOperation) SaveWithCodec(st *storage.LevelDBBackend, codec common.StorageCodec) (err error) {
 	if bo.isSaved {
 		return errors.AlreadySaved
 	}
@@ -77,7 +232,11 @@ func (bo *BlockOperation) Save(st *storage.LevelDBBackend) (err error) {
 		return errors.BlockAlreadyExists
 	}
 
-	if err = st.New(key, bo); err != nil {
+	var encoded []byte
+	if encoded, err = codec.Encode(bo); err != nil {
+		return
+	}
+	if err = st.PutRaw(key, encoded); err != nil {
 		return
 	}
 	if err = st.New(bo.NewBlockOperationTxHashKey(), bo.Hash); err != nil {
@@ -86,6 +245,13 @@ func (bo *BlockOperation) Save(st *storage.LevelDBBackend) (err error) {
 	if err = st.New(bo.NewBlockOperationSourceKey(), bo.Hash); err != nil {
 		return
 	}
+	var transfer blockOperationTransfer
+	if transfer, err = getBlockOperationTransfer(st, *bo); err != nil {
+		return
+	}
+	if err = updateBlockAccountRollups(st, *bo, transfer); err != nil {
+		return
+	}
 	bo.isSaved = true
 
 	event := "saved"
@@ -95,6 +261,8 @@ func (bo *BlockOperation) Save(st *storage.LevelDBBackend) (err error) {
 	event += " " + fmt.Sprintf("source-type-%s%s", bo.Source, bo.Type)
 	observer.BlockOperationObserver.Trigger(event, bo)
 
+	triggerBlockOperationEffects(*bo, transfer)
+
 	return nil
 }
 
@@ -116,21 +284,19 @@ func GetBlockOperationKeyPrefixSource(source string) string {
 }
 
 func (bo BlockOperation) NewBlockOperationTxHashKey() string {
-	return fmt.Sprintf(
-		"%s%s%s%s",
-		GetBlockOperationKeyPrefixTxHash(bo.TxHash),
-		common.EncodeUint64ToByteSlice(bo.Height),
-		common.EncodeUint64ToByteSlice(bo.transaction.B.SequenceID),
-		common.GetUniqueIDFromUUID(),
-	)
+	return newBlockOperationIndexKey(GetBlockOperationKeyPrefixTxHash(bo.TxHash), bo.Height, bo.transaction.B.SequenceID)
 }
 
 func (bo BlockOperation) NewBlockOperationSourceKey() string {
+	return newBlockOperationIndexKey(GetBlockOperationKeyPrefixSource(bo.Source), bo.Height, bo.transaction.B.SequenceID)
+}
+
+func newBlockOperationIndexKey(prefix string, height, sequenceID uint64) string {
 	return fmt.Sprintf(
 		"%s%s%s%s",
-		GetBlockOperationKeyPrefixSource(bo.Source),
-		common.EncodeUint64ToByteSlice(bo.Height),
-		common.EncodeUint64ToByteSlice(bo.transaction.B.SequenceID),
+		prefix,
+		common.EncodeUint64ToByteSlice(height),
+		common.EncodeUint64ToByteSlice(sequenceID),
 		common.GetUniqueIDFromUUID(),
 	)
 }
@@ -140,7 +306,11 @@ func ExistsBlockOperation(st *storage.LevelDBBackend, hash string) (bool, error)
 }
 
 func GetBlockOperation(st *storage.LevelDBBackend, hash string) (bo BlockOperation, err error) {
-	if err = st.Get(GetBlockOperationKey(hash), &bo); err != nil {
+	var b []byte
+	if b, err = st.GetRaw(GetBlockOperationKey(hash)); err != nil {
+		return
+	}
+	if err = common.DecodeStorageValue(b, &bo); err != nil {
 		return
 	}
 
@@ -148,6 +318,10 @@ func GetBlockOperation(st *storage.LevelDBBackend, hash string) (bo BlockOperati
 	return
 }
 
+// LoadBlockOperationsInsideIterator loads the `BlockOperation`s of the
+// iterator. When it returns `false`, the returned key is the cursor to resume
+// the truncated iteration; it is empty at the end of data, see
+// `storage.IterItem`.
 func LoadBlockOperationsInsideIterator(
 	st *storage.LevelDBBackend,
 	iterFunc func() (storage.IterItem, bool),
@@ -158,30 +332,103 @@ func LoadBlockOperationsInsideIterator(
 ) {
 
 	return (func() (BlockOperation, bool, []byte) {
+			for {
+				item, hasNext := iterFunc()
+				if !hasNext {
+					return BlockOperation{}, false, item.Key
+				}
+
+				var hash string
+				json.Unmarshal(item.Value, &hash)
+
+				bo, err := GetBlockOperation(st, hash)
+				if err == errors.StorageRecordDoesNotExist {
+					// pruned after the iterator was opened; skip it
+					continue
+				} else if err != nil {
+					return BlockOperation{}, false, item.Key
+				}
+
+				return bo, hasNext, item.Key
+			}
+		}), (func() {
+			closeFunc()
+		})
+}
+
+// LoadBlockOperationHashesInsideIterator loads only the hashes of the
+// `BlockOperation`s of the iterator, which are the values of the index
+// records, without reading the `BlockOperation`s; it is for counting and
+// checking the existence. Like `LoadBlockOperationsInsideIterator`, the
+// returned key is the cursor to resume the iteration.
+func LoadBlockOperationHashesInsideIterator(
+	iterFunc func() (storage.IterItem, bool),
+	closeFunc func(),
+) (
+	func() (string, bool, []byte),
+	func(),
+) {
+
+	return (func() (string, bool, []byte) {
 			item, hasNext := iterFunc()
 			if !hasNext {
-				return BlockOperation{}, false, item.Key
+				return "", false, item.Key
 			}
 
 			var hash string
-			json.Unmarshal(item.Value, &hash)
-
-			bo, err := GetBlockOperation(st, hash)
-			if err != nil {
-				return BlockOperation{}, false, item.Key
+			if err := json.Unmarshal(item.Value, &hash); err != nil {
+				return "", false, item.Key
 			}
 
-			return bo, hasNext, item.Key
+			return hash, hasNext, item.Key
 		}), (func() {
 			closeFunc()
 		})
 }
 
+// HeightRangeListOptions bounds the iterators of `BlockOperation` by the
+// source and the transaction hash, like `GetBlockOperationsBySource()`, with
+// the block height from `Start` to `End`, both inclusively; `End` of 0 means
+// no upper bound. The height is encoded in the index key, so the iterator
+// seeks to the range instead of scanning the whole history.
+type HeightRangeListOptions struct {
+	storage.ListOptions
+
+	Start uint64
+	End   uint64
+}
+
+func NewHeightRangeListOptions(options storage.ListOptions, start, end uint64) HeightRangeListOptions {
+	return HeightRangeListOptions{ListOptions: options, Start: start, End: end}
+}
+
+func getBlockOperationIndexIterator(st *storage.LevelDBBackend, prefix string, options storage.ListOptions) (
+	func() (storage.IterItem, bool),
+	func(),
+) {
+	hr, ok := options.(HeightRangeListOptions)
+	if !ok {
+		return st.GetIterator(prefix, options)
+	}
+
+	var start, limit string
+	if hr.Start > 0 {
+		b := common.EncodeUint64ToByteSlice(hr.Start)
+		start = string(b[:])
+	}
+	if hr.End > 0 && hr.End < math.MaxUint64 {
+		b := common.EncodeUint64ToByteSlice(hr.End + 1)
+		limit = string(b[:])
+	}
+
+	return st.GetIteratorRange(prefix, start, limit, hr.ListOptions)
+}
+
 func GetBlockOperationsByTxHash(st *storage.LevelDBBackend, txHash string, options storage.ListOptions) (
 	func() (BlockOperation, bool, []byte),
 	func(),
 ) {
-	iterFunc, closeFunc := st.GetIterator(GetBlockOperationKeyPrefixTxHash(txHash), options)
+	iterFunc, closeFunc := getBlockOperationIndexIterator(st, GetBlockOperationKeyPrefixTxHash(txHash), options)
 
 	return LoadBlockOperationsInsideIterator(st, iterFunc, closeFunc)
 }
@@ -190,7 +437,25 @@ func GetBlockOperationsBySource(st *storage.LevelDBBackend, source string, optio
 	func() (BlockOperation, bool, []byte),
 	func(),
 ) {
-	iterFunc, closeFunc := st.GetIterator(GetBlockOperationKeyPrefixSource(source), options)
+	iterFunc, closeFunc := getBlockOperationIndexIterator(st, GetBlockOperationKeyPrefixSource(source), options)
 
 	return LoadBlockOperationsInsideIterator(st, iterFunc, closeFunc)
 }
+
+func GetBlockOperationHashesByTxHash(st *storage.LevelDBBackend, txHash string, options storage.ListOptions) (
+	func() (string, bool, []byte),
+	func(),
+) {
+	iterFunc, closeFunc := getBlockOperationIndexIterator(st, GetBlockOperationKeyPrefixTxHash(txHash), options)
+
+	return LoadBlockOperationHashesInsideIterator(iterFunc, closeFunc)
+}
+
+func GetBlockOperationHashesBySource(st *storage.LevelDBBackend, source string, options storage.ListOptions) (
+	func() (string, bool, []byte),
+	func(),
+) {
+	iterFunc, closeFunc := getBlockOperationIndexIterator(st, GetBlockOperationKeyPrefixSource(source), options)
+
+	return LoadBlockOperationHashesInsideIterator(iterFunc, closeFunc)
+}
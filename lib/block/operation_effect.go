@@ -0,0 +1,117 @@
+package block
+
+import (
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/observer"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+type BlockOperationEffectType string
+
+const (
+	BlockOperationEffectCredit BlockOperationEffectType = "credit"
+	BlockOperationEffectDebit  BlockOperationEffectType = "debit"
+)
+
+// BlockOperationEffect is the balance change of an account by the saved
+// `BlockOperation`, so the deposit watcher does not need to decode the body
+// of `BlockOperation`. The payment makes the debit of the source and the
+// credit of the target; `Inflation` and `CollectTxFee` make only the credit,
+// because they are not paid by the source.
+type BlockOperationEffect struct {
+	Type    BlockOperationEffectType `json:"type"`
+	Address string                   `json:"address"`
+	Amount  common.Amount            `json:"amount"`
+
+	Operation     string                  `json:"operation"` // `BlockOperation.Hash`
+	OperationType operation.OperationType `json:"operation_type"`
+	TxHash        string                  `json:"tx_hash"`
+	Height        uint64                  `json:"block_height"`
+}
+
+// BlockOperationEffectFilter selects `BlockOperationEffect` by the address
+// and the type; the empty field matches any.
+type BlockOperationEffectFilter struct {
+	Address string
+	Type    BlockOperationEffectType
+}
+
+func (f BlockOperationEffectFilter) Match(effect BlockOperationEffect) bool {
+	if len(f.Address) > 0 && f.Address != effect.Address {
+		return false
+	}
+	if len(f.Type) > 0 && f.Type != effect.Type {
+		return false
+	}
+
+	return true
+}
+
+// event returns the narrowest event of `triggerBlockOperationEffects()` for
+// the filter.
+func (f BlockOperationEffectFilter) event() string {
+	switch {
+	case len(f.Address) > 0 && len(f.Type) > 0:
+		return fmt.Sprintf("%s-%s", f.Type, f.Address)
+	case len(f.Address) > 0:
+		return fmt.Sprintf("address-%s", f.Address)
+	default:
+		return "effect"
+	}
+}
+
+// newBlockOperationEffects makes the effects of the `BlockOperation` from its
+// transfer; the operation, which does not move the balance, has no effect.
+func newBlockOperationEffects(bo BlockOperation, transfer blockOperationTransfer) (effects []BlockOperationEffect) {
+	newEffect := func(effectType BlockOperationEffectType, address string, amount common.Amount) BlockOperationEffect {
+		return BlockOperationEffect{
+			Type:          effectType,
+			Address:       address,
+			Amount:        amount,
+			Operation:     bo.Hash,
+			OperationType: bo.Type,
+			TxHash:        bo.TxHash,
+			Height:        bo.Height,
+		}
+	}
+
+	if transfer.sent > 0 {
+		effects = append(effects, newEffect(BlockOperationEffectDebit, bo.Source, transfer.sent))
+	}
+	if len(transfer.target) > 0 && transfer.amount > 0 {
+		effects = append(effects, newEffect(BlockOperationEffectCredit, transfer.target, transfer.amount))
+	}
+
+	return
+}
+
+func triggerBlockOperationEffects(bo BlockOperation, transfer blockOperationTransfer) {
+	for _, effect := range newBlockOperationEffects(bo, transfer) {
+		event := "effect"
+		event += " " + fmt.Sprintf("address-%s", effect.Address)
+		event += " " + fmt.Sprintf("%s-%s", effect.Type, effect.Address)
+
+		e := effect
+		observer.BlockOperationEffectObserver.Trigger(event, &e)
+	}
+}
+
+// SubscribeBlockOperationEffect calls `handler` with the
+// `BlockOperationEffect` of the saved `BlockOperation`, which matches with
+// the filter. The returned function stops the subscription.
+func SubscribeBlockOperationEffect(filter BlockOperationEffectFilter, handler func(BlockOperationEffect)) (unsubscribe func()) {
+	event := filter.event()
+	cb := func(effect *BlockOperationEffect) {
+		if filter.Match(*effect) {
+			handler(*effect)
+		}
+	}
+
+	observer.BlockOperationEffectObserver.On(event, cb)
+
+	return func() {
+		observer.BlockOperationEffectObserver.Off(event, cb)
+	}
+}
@@ -0,0 +1,102 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func TestSubscribeBlockOperationEffect(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	kpSource := keypair.Random()
+	kpTarget := keypair.Random()
+	tx := transaction.TestMakeTransactionWithKeypair(networkID, 1, kpSource, kpTarget)
+	amount := tx.B.Operations[0].B.(operation.Payable).GetAmount()
+
+	var all, bySource, credits []BlockOperationEffect
+	unsubscribeAll := SubscribeBlockOperationEffect(
+		BlockOperationEffectFilter{},
+		func(effect BlockOperationEffect) { all = append(all, effect) },
+	)
+	defer unsubscribeAll()
+	unsubscribeSource := SubscribeBlockOperationEffect(
+		BlockOperationEffectFilter{Address: kpSource.Address()},
+		func(effect BlockOperationEffect) { bySource = append(bySource, effect) },
+	)
+	defer unsubscribeSource()
+	unsubscribeCredit := SubscribeBlockOperationEffect(
+		BlockOperationEffectFilter{Address: kpTarget.Address(), Type: BlockOperationEffectCredit},
+		func(effect BlockOperationEffect) { credits = append(credits, effect) },
+	)
+
+	bo, err := NewBlockOperationFromOperation(tx.B.Operations[0], tx, 3)
+	require.NoError(t, err)
+	bo.MustSave(st)
+
+	// the payment makes the debit of source and the credit of target
+	require.Equal(t, 2, len(all))
+	require.Equal(t, BlockOperationEffectDebit, all[0].Type)
+	require.Equal(t, kpSource.Address(), all[0].Address)
+	require.Equal(t, amount, all[0].Amount)
+	require.Equal(t, BlockOperationEffectCredit, all[1].Type)
+	require.Equal(t, kpTarget.Address(), all[1].Address)
+	require.Equal(t, amount, all[1].Amount)
+	for _, effect := range all {
+		require.Equal(t, bo.Hash, effect.Operation)
+		require.Equal(t, operation.TypePayment, effect.OperationType)
+		require.Equal(t, tx.GetHash(), effect.TxHash)
+		require.Equal(t, uint64(3), effect.Height)
+	}
+
+	require.Equal(t, 1, len(bySource))
+	require.Equal(t, BlockOperationEffectDebit, bySource[0].Type)
+
+	require.Equal(t, 1, len(credits))
+	require.Equal(t, all[1], credits[0])
+
+	{ // the operation, which does not move the balance, has no effect
+		opb := operation.NewManageData("name", []byte("value"))
+		op, err := operation.NewOperation(opb)
+		require.NoError(t, err)
+		tx, err := transaction.NewTransaction(kpSource.Address(), 1, op)
+		require.NoError(t, err)
+		tx.Sign(kpSource, networkID)
+
+		bo, err := NewBlockOperationFromOperation(op, tx, 4)
+		require.NoError(t, err)
+		bo.MustSave(st)
+
+		require.Equal(t, 2, len(all))
+	}
+
+	{ // unsubscribed
+		unsubscribeCredit()
+
+		tx := transaction.TestMakeTransactionWithKeypair(networkID, 1, kpSource, kpTarget)
+		bo, err := NewBlockOperationFromOperation(tx.B.Operations[0], tx, 5)
+		require.NoError(t, err)
+		bo.MustSave(st)
+
+		require.Equal(t, 4, len(all))
+		require.Equal(t, 2, len(bySource))
+		require.Equal(t, 1, len(credits))
+	}
+
+	{ // the collected fee is not paid by the source
+		effects := newBlockOperationEffects(
+			BlockOperation{Source: kpSource.Address(), Type: operation.TypeCollectTxFee},
+			blockOperationTransfer{target: kpTarget.Address(), amount: common.BaseFee},
+		)
+		require.Equal(t, 1, len(effects))
+		require.Equal(t, BlockOperationEffectCredit, effects[0].Type)
+		require.Equal(t, kpTarget.Address(), effects[0].Address)
+	}
+}
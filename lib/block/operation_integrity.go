@@ -0,0 +1,242 @@
+package block
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+type BlockOperationIntegrityReason string
+
+const (
+	// BlockOperationIntegrityMissingIndex is the `BlockOperation`, which has
+	// no index record.
+	BlockOperationIntegrityMissingIndex BlockOperationIntegrityReason = "missing-index"
+	// BlockOperationIntegrityDanglingIndex is the index record, which points
+	// to the missing `BlockOperation` or can not be decoded.
+	BlockOperationIntegrityDanglingIndex BlockOperationIntegrityReason = "dangling-index"
+	// BlockOperationIntegrityWrongIndex is the index record, which is under
+	// the wrong prefix for its `BlockOperation`.
+	BlockOperationIntegrityWrongIndex BlockOperationIntegrityReason = "wrong-index"
+)
+
+// BlockOperationIntegrityIssue is the inconsistency between the
+// `BlockOperation` and the index records, which is found by
+// `ScanBlockOperationIntegrity()`.
+type BlockOperationIntegrityIssue struct {
+	Reason BlockOperationIntegrityReason
+	Index  string // "txhash" or "source"
+	Key    string // key of the `BlockOperation` or the index record
+	Hash   string // `BlockOperation.Hash`; empty if it can not be decoded
+}
+
+// ScanBlockOperationIntegrity checks every `BlockOperation` has the index
+// records by `TxHash` and `Source` and every index record points to the
+// `BlockOperation` under the right prefix. It checks at most `limit` records
+// after `cursor` and returns the cursor to resume; the returned cursor is nil
+// when the scan is finished. Only the issues of the checked records are kept
+// in memory, so the large storage can be scanned by calling it repeatedly.
+//
+// The duplicated index records are not detected; `ReindexBlockOperations()`
+// removes them.
+func ScanBlockOperationIntegrity(st *storage.LevelDBBackend, cursor []byte, limit uint64) (
+	issues []BlockOperationIntegrityIssue,
+	next []byte,
+	err error,
+) {
+	prefixes := []string{common.BlockOperationPrefixHash}
+	for _, index := range blockOperationIndexes {
+		prefixes = append(prefixes, index.keyPrefix)
+	}
+
+	var start int
+	if cursor != nil {
+		start = -1
+		for i, prefix := range prefixes {
+			if strings.HasPrefix(string(cursor), prefix) {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			err = errors.InvalidQueryString.Clone().SetData("cursor", fmt.Sprintf("%x", cursor))
+			return
+		}
+	}
+
+	var n uint64
+	for i := start; i < len(prefixes); i++ {
+		var options storage.ListOptions
+		if i == start && cursor != nil {
+			options = storage.NewDefaultListOptions(false, cursor, 0)
+		}
+
+		iterFunc, closeFunc := st.GetIterator(prefixes[i], options)
+		for {
+			item, hasNext := iterFunc()
+			if !hasNext {
+				break
+			}
+			if options != nil && bytes.Equal(item.Key, cursor) { // already checked
+				continue
+			}
+
+			var found []BlockOperationIntegrityIssue
+			if i == 0 {
+				found, err = checkBlockOperationIntegrity(st, item)
+			} else {
+				found, err = checkBlockOperationIndexIntegrity(st, blockOperationIndexes[i-1], item)
+			}
+			if err != nil {
+				closeFunc()
+				return
+			}
+			issues = append(issues, found...)
+
+			n++
+			if limit > 0 && n >= limit {
+				next = append([]byte{}, item.Key...)
+				closeFunc()
+				return
+			}
+		}
+		closeFunc()
+	}
+
+	return issues, nil, nil
+}
+
+// checkBlockOperationIntegrity finds the missing index records of the
+// `BlockOperation`. The index records are looked up under the prefix with
+// the block height, so only the records of the same block are read.
+func checkBlockOperationIntegrity(st *storage.LevelDBBackend, item storage.IterItem) (issues []BlockOperationIntegrityIssue, err error) {
+	var bo BlockOperation
+	if err = common.DecodeStorageValue(item.Value, &bo); err != nil {
+		return
+	}
+
+	for _, index := range blockOperationIndexes {
+		prefix := fmt.Sprintf("%s%s", index.prefix(bo), common.EncodeUint64ToByteSlice(bo.Height))
+
+		var found bool
+		iterFunc, closeFunc := st.GetIterator(prefix, nil)
+		for {
+			indexItem, hasNext := iterFunc()
+			if !hasNext {
+				break
+			}
+
+			var hash string
+			if json.Unmarshal(indexItem.Value, &hash) == nil && hash == bo.Hash {
+				found = true
+				break
+			}
+		}
+		closeFunc()
+
+		if !found {
+			issues = append(issues, BlockOperationIntegrityIssue{
+				Reason: BlockOperationIntegrityMissingIndex,
+				Index:  index.name,
+				Key:    string(item.Key),
+				Hash:   bo.Hash,
+			})
+		}
+	}
+
+	return
+}
+
+// checkBlockOperationIndexIntegrity checks the index record points to the
+// `BlockOperation` under the right prefix.
+func checkBlockOperationIndexIntegrity(st *storage.LevelDBBackend, index blockOperationIndex, item storage.IterItem) (issues []BlockOperationIntegrityIssue, err error) {
+	issue := BlockOperationIntegrityIssue{
+		Reason: BlockOperationIntegrityDanglingIndex,
+		Index:  index.name,
+		Key:    string(item.Key),
+	}
+
+	if json.Unmarshal(item.Value, &issue.Hash) != nil {
+		issue.Hash = ""
+		return []BlockOperationIntegrityIssue{issue}, nil
+	}
+
+	var bo BlockOperation
+	if bo, err = GetBlockOperation(st, issue.Hash); err == errors.StorageRecordDoesNotExist {
+		return []BlockOperationIntegrityIssue{issue}, nil
+	} else if err != nil {
+		return
+	}
+
+	if !strings.HasPrefix(string(item.Key), index.prefix(bo)) {
+		issue.Reason = BlockOperationIntegrityWrongIndex
+		return []BlockOperationIntegrityIssue{issue}, nil
+	}
+
+	return
+}
+
+// RepairBlockOperationIntegrity fixes the issues of
+// `ScanBlockOperationIntegrity()` like `ReindexBlockOperations()`; the
+// dangling and wrong index records are removed and the missing index records
+// are added in the order of the operations of the transaction. The changes
+// are committed at once. It returns the number of the added and the removed
+// records.
+func RepairBlockOperationIntegrity(st *storage.LevelDBBackend, issues []BlockOperationIntegrityIssue) (added, removed int, err error) {
+	var removes []string
+	var txHashes []string
+	txHashesFound := map[string]bool{}
+	bos := map[string]BlockOperation{}
+	missings := map[string]map[string]bool{} // `BlockOperation.Hash`: index names
+
+	for _, issue := range issues {
+		if issue.Reason != BlockOperationIntegrityMissingIndex {
+			removes = append(removes, issue.Key)
+			continue
+		}
+
+		if _, found := bos[issue.Hash]; !found {
+			var bo BlockOperation
+			if bo, err = GetBlockOperation(st, issue.Hash); err != nil {
+				return
+			}
+			bos[issue.Hash] = bo
+			missings[issue.Hash] = map[string]bool{}
+
+			if !txHashesFound[bo.TxHash] {
+				txHashes = append(txHashes, bo.TxHash)
+				txHashesFound[bo.TxHash] = true
+			}
+		}
+		missings[issue.Hash][issue.Index] = true
+	}
+
+	var adds [][2]string // key, `BlockOperation.Hash`
+	for _, txHash := range txHashes {
+		var bt BlockTransaction
+		if bt, err = GetBlockTransaction(st, txHash); err != nil {
+			err = errors.FailedToSaveBlockOperaton.Clone().SetData("error", err.Error()).SetData("tx_hash", txHash)
+			return
+		}
+
+		for _, hash := range bt.Operations {
+			for _, index := range blockOperationIndexes {
+				if missings[hash][index.name] {
+					bo := bos[hash]
+					adds = append(adds, [2]string{newBlockOperationIndexKey(index.prefix(bo), bo.Height, bt.SequenceID), hash})
+				}
+			}
+		}
+	}
+
+	if err = commitBlockOperationIndexes(st, adds, removes); err != nil {
+		return
+	}
+
+	return len(adds), len(removes), nil
+}
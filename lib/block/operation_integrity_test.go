@@ -0,0 +1,127 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+func TestScanBlockOperationIntegrity(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	_, tx := transaction.TestMakeTransaction(networkID, 3)
+	blk := TestMakeNewBlockWithPrevBlock(GetLatestBlock(st), []string{tx.GetHash()})
+	bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.Confirmed, tx)
+	require.NoError(t, bt.Save(st))
+	require.NoError(t, bt.SaveBlockOperations(st, blk))
+
+	scanAll := func(limit uint64) (issues []BlockOperationIntegrityIssue) {
+		var cursor []byte
+		for {
+			found, next, err := ScanBlockOperationIntegrity(st, cursor, limit)
+			require.NoError(t, err)
+			require.True(t, limit == 0 || uint64(len(found)) <= limit*uint64(len(blockOperationIndexes)))
+			issues = append(issues, found...)
+			if next == nil {
+				return
+			}
+			cursor = next
+		}
+	}
+	keys := func(prefix string) (keys []string) {
+		iterFunc, closeFunc := st.GetIterator(prefix, nil)
+		defer closeFunc()
+		for {
+			item, hasNext := iterFunc()
+			if !hasNext {
+				return
+			}
+			keys = append(keys, string(item.Key))
+		}
+	}
+
+	{ // consistent
+		require.Empty(t, scanAll(0))
+		require.Empty(t, scanAll(1))
+	}
+
+	// corrupt the index records
+	missing := bt.Operations[1]
+	txHashKeys := keys(GetBlockOperationKeyPrefixTxHash(tx.GetHash()))
+	require.Equal(t, 3, len(txHashKeys))
+	require.NoError(t, st.Remove(txHashKeys[1]))
+
+	danglingKey := GetBlockOperationKeyPrefixSource(tx.B.Source) + "dangling"
+	require.NoError(t, st.New(danglingKey, "unknown"))
+
+	wrongKey := GetBlockOperationKeyPrefixTxHash("findme") + "wrong"
+	require.NoError(t, st.New(wrongKey, bt.Operations[0]))
+
+	expected := []BlockOperationIntegrityIssue{
+		{
+			Reason: BlockOperationIntegrityMissingIndex,
+			Index:  "txhash",
+			Key:    GetBlockOperationKey(missing),
+			Hash:   missing,
+		},
+		{
+			Reason: BlockOperationIntegrityWrongIndex,
+			Index:  "txhash",
+			Key:    wrongKey,
+			Hash:   bt.Operations[0],
+		},
+		{
+			Reason: BlockOperationIntegrityDanglingIndex,
+			Index:  "source",
+			Key:    danglingKey,
+			Hash:   "unknown",
+		},
+	}
+
+	issues := scanAll(0)
+	require.Equal(t, expected, issues)
+
+	{ // resumed by cursor with the small batch
+		require.Equal(t, expected, scanAll(1))
+		require.Equal(t, expected, scanAll(2))
+	}
+
+	{ // unknown cursor
+		_, _, err := ScanBlockOperationIntegrity(st, []byte("unknown"), 1)
+		require.Error(t, err)
+	}
+
+	// the storage is not changed by scanning
+	require.Equal(t, 2, len(keys(GetBlockOperationKeyPrefixTxHash(tx.GetHash()))))
+
+	added, removed, err := RepairBlockOperationIntegrity(st, issues)
+	require.NoError(t, err)
+	require.Equal(t, 1, added)
+	require.Equal(t, 2, removed)
+
+	require.Empty(t, scanAll(0))
+
+	var byTxHash []string
+	iterFunc, closeFunc := GetBlockOperationHashesByTxHash(st, tx.GetHash(), storage.NewDefaultListOptions(false, nil, 0))
+	for {
+		hash, hasNext, _ := iterFunc()
+		if !hasNext {
+			break
+		}
+		byTxHash = append(byTxHash, hash)
+	}
+	closeFunc()
+	require.Equal(t, 3, len(byTxHash))
+	require.ElementsMatch(t, bt.Operations, byTxHash)
+
+	{ // nothing to reindex after repair
+		added, removed, err := ReindexBlockOperations(st)
+		require.NoError(t, err)
+		require.Equal(t, 0, added)
+		require.Equal(t, 0, removed)
+	}
+}
@@ -0,0 +1,69 @@
+package block
+
+import (
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+// GetBlockOperationHashes returns the `BlockOperation.Hash`s of the
+// transactions in order; they are the leaves of `Header.OperationsRoot`. The
+// transactions of block are ordered like `Block.Transactions` and the
+// proposer transaction is the last.
+func GetBlockOperationHashes(txs ...transaction.Transaction) (hashes []string) {
+	for _, tx := range txs {
+		for _, op := range tx.B.Operations {
+			hashes = append(hashes, NewBlockOperationKey(op.MakeHashString(), tx.GetHash()))
+		}
+	}
+
+	return
+}
+
+// getBlockOperationHashesFromStorage returns the `BlockOperation.Hash`s of the
+// block from the stored `BlockTransaction`s; if the block is pruned, it
+// returns `errors.StorageRecordDoesNotExist`.
+func getBlockOperationHashesFromStorage(st *storage.LevelDBBackend, blk Block) (hashes []string, err error) {
+	txHashes := blk.Transactions
+	if len(blk.ProposerTransaction) > 0 {
+		txHashes = append(txHashes[:len(txHashes):len(txHashes)], blk.ProposerTransaction)
+	}
+
+	for _, txHash := range txHashes {
+		var bt BlockTransaction
+		if bt, err = GetBlockTransaction(st, txHash); err != nil {
+			return
+		}
+		hashes = append(hashes, bt.Operations...)
+	}
+
+	return
+}
+
+// NewBlockOperationProof makes the merkle inclusion proof of the
+// `BlockOperation` in the block against `Header.OperationsRoot`; if the
+// operation is not in the block, it returns
+// `errors.BlockOperationDoesNotExists`.
+func NewBlockOperationProof(st *storage.LevelDBBackend, blk Block, opHash string) (proof common.MerkleProof, err error) {
+	var hashes []string
+	if hashes, err = getBlockOperationHashesFromStorage(st, blk); err != nil {
+		return
+	}
+
+	var found bool
+	if proof, found = common.NewMerkleProof(hashes, opHash); !found {
+		err = errors.BlockOperationDoesNotExists
+		return
+	}
+
+	return
+}
+
+// VerifyBlockOperationProof checks the proof against the
+// `Header.OperationsRoot`; the header should be checked by the block hash
+// before. The block before `BlockVersion` 1 does not cover
+// `Header.OperationsRoot` by it's hash, so the proof of it is not trusted.
+func VerifyBlockOperationProof(header Header, proof common.MerkleProof) bool {
+	return proof.Verify(header.OperationsRoot)
+}
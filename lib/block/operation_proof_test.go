@@ -0,0 +1,70 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func TestBlockOperationProof(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	var txs []transaction.Transaction
+	var txHashes []string
+	for i := 0; i < 3; i++ {
+		_, tx := transaction.TestMakeTransaction(networkID, i+1)
+		txs = append(txs, tx)
+		txHashes = append(txHashes, tx.GetHash())
+	}
+	_, ptx := transaction.TestMakeTransaction(networkID, 2)
+
+	operations := GetBlockOperationHashes(append(txs, ptx)...)
+	require.Equal(t, 1+2+3+2, len(operations))
+
+	blk := NewBlock(
+		"",
+		voting.Basis{Height: common.GenesisBlockHeight},
+		ptx.GetHash(),
+		txHashes,
+		operations,
+		common.NowISO8601(),
+	)
+	require.Equal(t, common.MakeMerkleRoot(operations), blk.OperationsRoot)
+	require.NoError(t, blk.Save(st))
+
+	for _, tx := range append(txs, ptx) {
+		bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.Confirmed, tx)
+		require.NoError(t, bt.Save(st))
+	}
+
+	// the root is covered by the block hash
+	{
+		other := NewBlock("", voting.Basis{Height: common.GenesisBlockHeight}, ptx.GetHash(), txHashes, operations[1:], blk.Confirmed)
+		require.NotEqual(t, blk.Hash, other.Hash)
+	}
+
+	header, err := GetBlockHeader(st, blk.Hash)
+	require.NoError(t, err)
+
+	for _, opHash := range operations {
+		proof, err := NewBlockOperationProof(st, *blk, opHash)
+		require.NoError(t, err)
+		require.True(t, VerifyBlockOperationProof(header, proof))
+	}
+
+	{ // non-member
+		_, err := NewBlockOperationProof(st, *blk, "showme")
+		require.Equal(t, errors.BlockOperationDoesNotExists, err)
+
+		proof, _ := NewBlockOperationProof(st, *blk, operations[0])
+		proof.Leaf = "showme"
+		require.False(t, VerifyBlockOperationProof(header, proof))
+	}
+}
@@ -0,0 +1,185 @@
+package block
+
+import (
+	"encoding/json"
+	"strings"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// blockOperationIndex is the index records of `BlockOperation`; `prefix`
+// returns the key prefix of the record of the `BlockOperation`.
+type blockOperationIndex struct {
+	name      string
+	keyPrefix string
+	prefix    func(BlockOperation) string
+}
+
+var blockOperationIndexes = []blockOperationIndex{
+	{
+		name:      "txhash",
+		keyPrefix: common.BlockOperationPrefixTxHash,
+		prefix:    func(bo BlockOperation) string { return GetBlockOperationKeyPrefixTxHash(bo.TxHash) },
+	},
+	{
+		name:      "source",
+		keyPrefix: common.BlockOperationPrefixSource,
+		prefix:    func(bo BlockOperation) string { return GetBlockOperationKeyPrefixSource(bo.Source) },
+	},
+}
+
+// ReindexBlockOperations rebuilds the index records of the `BlockOperation`s
+// by `TxHash` and `Source` from the `BlockOperation`s. The record, which
+// points to the missing `BlockOperation`, is under the wrong prefix or
+// duplicates the other record, is removed and the missing record is added;
+// the valid records are kept, so the cursors of them still work and running
+// it again changes nothing. The changes are committed at once, but the
+// `BlockOperation`s must not be saved or pruned while reindexing, so the node
+// should be paused. It returns the number of the added and the removed
+// records.
+func ReindexBlockOperations(st *storage.LevelDBBackend) (added, removed int, err error) {
+	var removes []string
+	indexed := make([]map[string]bool, len(blockOperationIndexes))
+	for i, index := range blockOperationIndexes {
+		var r []string
+		if indexed[i], r, err = checkBlockOperationIndex(st, index); err != nil {
+			return
+		}
+		removes = append(removes, r...)
+	}
+
+	// the missing records
+	var txHashes []string
+	txHashesFound := map[string]bool{}
+	missings := make([]map[string]BlockOperation, len(blockOperationIndexes))
+	for i := range missings {
+		missings[i] = map[string]BlockOperation{}
+	}
+
+	iterFunc, closeFunc := st.GetIterator(common.BlockOperationPrefixHash, nil)
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var bo BlockOperation
+		if err = common.DecodeStorageValue(item.Value, &bo); err != nil {
+			closeFunc()
+			return
+		}
+
+		var isMissing bool
+		for i := range blockOperationIndexes {
+			if !indexed[i][bo.Hash] {
+				missings[i][bo.Hash] = bo
+				isMissing = true
+			}
+		}
+		if isMissing && !txHashesFound[bo.TxHash] {
+			txHashes = append(txHashes, bo.TxHash)
+			txHashesFound[bo.TxHash] = true
+		}
+	}
+	closeFunc()
+
+	// the records of a transaction are added in the order of operations, so
+	// they are listed in the same order with the saved ones.
+	var adds [][2]string // key, `BlockOperation.Hash`
+	for _, txHash := range txHashes {
+		var bt BlockTransaction
+		if bt, err = GetBlockTransaction(st, txHash); err != nil {
+			err = errors.FailedToSaveBlockOperaton.Clone().SetData("error", err.Error()).SetData("tx_hash", txHash)
+			return
+		}
+
+		for _, hash := range bt.Operations {
+			for i, index := range blockOperationIndexes {
+				if bo, found := missings[i][hash]; found {
+					adds = append(adds, [2]string{newBlockOperationIndexKey(index.prefix(bo), bo.Height, bt.SequenceID), hash})
+				}
+			}
+		}
+	}
+
+	if err = commitBlockOperationIndexes(st, adds, removes); err != nil {
+		return
+	}
+
+	return len(adds), len(removes), nil
+}
+
+// commitBlockOperationIndexes removes the index records of `removes` and
+// adds the `adds`, pairs of the key and the `BlockOperation.Hash`, at once.
+func commitBlockOperationIndexes(st *storage.LevelDBBackend, adds [][2]string, removes []string) (err error) {
+	if len(adds) < 1 && len(removes) < 1 {
+		return
+	}
+
+	var bs *storage.LevelDBBackend
+	if bs, err = st.OpenBatch(); err != nil {
+		return
+	}
+
+	for _, key := range removes {
+		if err = bs.Remove(key); err != nil {
+			bs.Discard()
+			return
+		}
+	}
+	for _, add := range adds {
+		if err = bs.New(add[0], add[1]); err != nil {
+			bs.Discard()
+			return
+		}
+	}
+
+	if err = bs.Commit(); err != nil {
+		bs.Discard()
+		return
+	}
+
+	return
+}
+
+// checkBlockOperationIndex returns the `BlockOperation`s, which are indexed
+// by the valid records, and the keys of the invalid records.
+func checkBlockOperationIndex(st *storage.LevelDBBackend, index blockOperationIndex) (indexed map[string]bool, removes []string, err error) {
+	indexed = map[string]bool{}
+
+	iterFunc, closeFunc := st.GetIterator(index.keyPrefix, nil)
+	defer closeFunc()
+
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var hash string
+		if json.Unmarshal(item.Value, &hash) != nil || indexed[hash] {
+			removes = append(removes, string(item.Key))
+			continue
+		}
+
+		var bo BlockOperation
+		if bo, err = GetBlockOperation(st, hash); err == errors.StorageRecordDoesNotExist {
+			err = nil
+			removes = append(removes, string(item.Key))
+			continue
+		} else if err != nil {
+			return
+		}
+
+		if !strings.HasPrefix(string(item.Key), index.prefix(bo)) {
+			removes = append(removes, string(item.Key))
+			continue
+		}
+
+		indexed[hash] = true
+	}
+
+	return
+}
@@ -0,0 +1,102 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+func TestReindexBlockOperations(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	_, tx := transaction.TestMakeTransaction(networkID, 3)
+	blk := TestMakeNewBlockWithPrevBlock(GetLatestBlock(st), []string{tx.GetHash()})
+	bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.Confirmed, tx)
+	require.NoError(t, bt.Save(st))
+	require.NoError(t, bt.SaveBlockOperations(st, blk))
+
+	loadHashes := func(iterFunc func() (BlockOperation, bool, []byte), closeFunc func()) (hashes []string) {
+		defer closeFunc()
+		for {
+			bo, hasNext, _ := iterFunc()
+			if !hasNext {
+				return
+			}
+			hashes = append(hashes, bo.Hash)
+		}
+	}
+	byTxHash := func() []string {
+		return loadHashes(GetBlockOperationsByTxHash(st, tx.GetHash(), nil))
+	}
+	bySource := func() []string {
+		return loadHashes(GetBlockOperationsBySource(st, tx.B.Source, nil))
+	}
+
+	expected := bt.Operations
+	require.Equal(t, expected, byTxHash())
+	require.Equal(t, expected, bySource())
+
+	{ // nothing to reindex
+		added, removed, err := ReindexBlockOperations(st)
+		require.NoError(t, err)
+		require.Equal(t, 0, added)
+		require.Equal(t, 0, removed)
+	}
+
+	// remove the index records of the transaction
+	var keys []string
+	for _, prefix := range []string{GetBlockOperationKeyPrefixTxHash(tx.GetHash()), GetBlockOperationKeyPrefixSource(tx.B.Source)} {
+		iterFunc, closeFunc := st.GetIterator(prefix, nil)
+		for {
+			item, hasNext := iterFunc()
+			if !hasNext {
+				break
+			}
+			keys = append(keys, string(item.Key))
+		}
+		closeFunc()
+	}
+	require.Equal(t, len(expected)*2, len(keys))
+	for _, key := range keys {
+		require.NoError(t, st.Remove(key))
+	}
+
+	// the record of missing `BlockOperation` and the duplicated record
+	var genesisHash string
+	{
+		iterFunc, closeFunc := st.GetIterator(common.BlockOperationPrefixTxHash, nil)
+		item, _ := iterFunc()
+		closeFunc()
+		require.NoError(t, common.DecodeJSONValue(item.Value, &genesisHash))
+		require.NoError(t, st.New(string(item.Key)+"duplicated", genesisHash))
+	}
+	require.NoError(t, st.New(GetBlockOperationKeyPrefixTxHash(tx.GetHash())+"dangling", "unknown"))
+
+	require.Empty(t, byTxHash())
+	require.Empty(t, bySource())
+
+	added, removed, err := ReindexBlockOperations(st)
+	require.NoError(t, err)
+	require.Equal(t, len(expected)*2, added)
+	require.Equal(t, 2, removed)
+
+	require.Equal(t, expected, byTxHash())
+	require.Equal(t, expected, bySource())
+
+	genesis, err := GetBlockOperation(st, genesisHash)
+	require.NoError(t, err)
+	genesisTx, err := GetBlockTransaction(st, genesis.TxHash)
+	require.NoError(t, err)
+	require.Equal(t, genesisTx.Operations, loadHashes(GetBlockOperationsByTxHash(st, genesis.TxHash, nil)))
+
+	{ // idempotent
+		added, removed, err := ReindexBlockOperations(st)
+		require.NoError(t, err)
+		require.Equal(t, 0, added)
+		require.Equal(t, 0, removed)
+	}
+}
@@ -1,10 +1,16 @@
 package block
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/common/observer"
 	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
 
 	"github.com/stretchr/testify/require"
 
@@ -42,6 +48,48 @@ func TestBlockOperationSaveAndGet(t *testing.T) {
 	require.Equal(t, bo.Body, fetched.Body)
 }
 
+func TestBlockOperationSaveAndGetStorageCodec(t *testing.T) {
+	st := storage.NewTestStorage()
+	bos := TestMakeNewBlockOperation(networkID, 2)
+
+	// the legacy `BlockOperation` is stored in JSON
+	require.NoError(t, bos[0].SaveWithCodec(st, common.StorageCodecJSON))
+	require.NoError(t, bos[1].SaveWithCodec(st, common.StorageCodecMsgpack))
+
+	b, err := st.GetRaw(GetBlockOperationKey(bos[0].Hash))
+	require.NoError(t, err)
+	require.Equal(t, byte('{'), b[0])
+	b, err = st.GetRaw(GetBlockOperationKey(bos[1].Hash))
+	require.NoError(t, err)
+	require.Equal(t, common.StorageCodecHeaderMsgpack, b[0])
+
+	for _, bo := range bos {
+		fetched, err := GetBlockOperation(st, bo.Hash)
+		require.NoError(t, err)
+
+		require.Equal(t, bo.Hash, fetched.Hash)
+		require.Equal(t, bo.OpHash, fetched.OpHash)
+		require.Equal(t, bo.TxHash, fetched.TxHash)
+		require.Equal(t, bo.Type, fetched.Type)
+		require.Equal(t, bo.Source, fetched.Source)
+		require.Equal(t, bo.Body, fetched.Body)
+		require.Equal(t, bo.Height, fetched.Height)
+	}
+
+	var fetched []BlockOperation
+	iterFunc, closeFunc := GetBlockOperationsByTxHash(st, bos[0].TxHash, nil)
+	for {
+		bo, hasNext, _ := iterFunc()
+		if !hasNext {
+			break
+		}
+		fetched = append(fetched, bo)
+	}
+	closeFunc()
+
+	require.Equal(t, 2, len(fetched))
+}
+
 func TestBlockOperationSaveExisting(t *testing.T) {
 	st := storage.NewTestStorage()
 
@@ -125,3 +173,325 @@ func TestBlockOperationSaveByTransaction(t *testing.T) {
 		require.Equal(t, bo.Body, encoded)
 	}
 }
+
+func TestSubscribeBlockOperation(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	kpA, txA := transaction.TestMakeTransaction(networkID, 2)
+	_, txB := transaction.TestMakeTransaction(networkID, 1)
+	txC := transaction.MakeTransactionCreateAccount(networkID, kpA, keypair.Random().Address(), common.BaseReserve)
+
+	var bySource, byType, bySourceType []BlockOperation
+	unsubscribeSource := SubscribeBlockOperation(
+		BlockOperationFilter{Source: kpA.Address()},
+		func(bo BlockOperation) { bySource = append(bySource, bo) },
+	)
+	defer unsubscribeSource()
+	unsubscribeType := SubscribeBlockOperation(
+		BlockOperationFilter{Type: operation.TypeCreateAccount},
+		func(bo BlockOperation) { byType = append(byType, bo) },
+	)
+	defer unsubscribeType()
+	unsubscribeSourceType := SubscribeBlockOperation(
+		BlockOperationFilter{Source: kpA.Address(), Type: operation.TypePayment},
+		func(bo BlockOperation) { bySourceType = append(bySourceType, bo) },
+	)
+
+	save := func(tx transaction.Transaction) {
+		for _, op := range tx.B.Operations {
+			bo, err := NewBlockOperationFromOperation(op, tx, 1)
+			require.NoError(t, err)
+			bo.MustSave(st)
+		}
+	}
+	save(txA)
+	save(txB)
+	save(txC)
+
+	require.Equal(t, 3, len(bySource))
+	for _, bo := range bySource {
+		require.Equal(t, kpA.Address(), bo.Source)
+	}
+
+	require.Equal(t, 1, len(byType))
+	require.Equal(t, txC.GetHash(), byType[0].TxHash)
+
+	require.Equal(t, 2, len(bySourceType))
+	for _, bo := range bySourceType {
+		require.Equal(t, txA.GetHash(), bo.TxHash)
+	}
+
+	{ // unsubscribed
+		unsubscribeSourceType()
+
+		_, tx := transaction.TestMakeTransaction(networkID, 1)
+		tx.Sign(kpA, networkID)
+		save(tx)
+
+		require.Equal(t, 4, len(bySource))
+		require.Equal(t, 2, len(bySourceType))
+	}
+}
+
+func countBlockOperationCallbacks(event string) int {
+	observer.BlockOperationObserver.RLock()
+	defer observer.BlockOperationObserver.RUnlock()
+
+	return len(observer.BlockOperationObserver.Callbacks[event])
+}
+
+func TestSubscribeTransactionInclusion(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	save := func(tx transaction.Transaction, height uint64) {
+		for _, op := range tx.B.Operations {
+			bo, err := NewBlockOperationFromOperation(op, tx, height)
+			require.NoError(t, err)
+			bo.MustSave(st)
+		}
+	}
+
+	waitUnsubscribed := func(event string) {
+		for i := 0; i < 100; i++ {
+			if countBlockOperationCallbacks(event) < 1 {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		require.Fail(t, "subscription is not removed", event)
+	}
+
+	{ // one-shot
+		_, tx := transaction.TestMakeTransaction(networkID, 2)
+		event := fmt.Sprintf("txhash-%s", tx.GetHash())
+
+		received := make(chan TransactionInclusion, 10)
+		SubscribeTransactionInclusion(tx.GetHash(), time.Minute, true, func(ti TransactionInclusion, err error) {
+			require.NoError(t, err)
+			received <- ti
+		})
+		require.Equal(t, 1, countBlockOperationCallbacks(event))
+
+		save(tx, 3)
+
+		ti := <-received
+		require.Equal(t, tx.GetHash(), ti.TxHash)
+		require.Equal(t, uint64(3), ti.Height)
+		require.Equal(t, NewBlockOperationKey(tx.B.Operations[0].MakeHashString(), tx.GetHash()), ti.Operation)
+
+		waitUnsubscribed(event)
+		require.Equal(t, 0, len(received))
+	}
+
+	{ // not one-shot
+		_, tx := transaction.TestMakeTransaction(networkID, 2)
+
+		received := make(chan TransactionInclusion, 10)
+		unsubscribe := SubscribeTransactionInclusion(tx.GetHash(), 0, false, func(ti TransactionInclusion, err error) {
+			require.NoError(t, err)
+			received <- ti
+		})
+
+		save(tx, 3)
+		require.Equal(t, 2, len(received))
+
+		unsubscribe()
+		require.Equal(t, 0, countBlockOperationCallbacks(fmt.Sprintf("txhash-%s", tx.GetHash())))
+	}
+
+	{ // timeout
+		_, tx := transaction.TestMakeTransaction(networkID, 1)
+		event := fmt.Sprintf("txhash-%s", tx.GetHash())
+
+		errs := make(chan error, 10)
+		SubscribeTransactionInclusion(tx.GetHash(), 50*time.Millisecond, true, func(ti TransactionInclusion, err error) {
+			require.Equal(t, tx.GetHash(), ti.TxHash)
+			errs <- err
+		})
+
+		require.Equal(t, errors.TransactionInclusionTimeout, <-errs)
+		require.Equal(t, 0, countBlockOperationCallbacks(event))
+
+		// saved after timeout; not notified
+		save(tx, 3)
+		require.Equal(t, 0, len(errs))
+	}
+}
+
+func TestGetBlockOperationHashes(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	bos := TestMakeNewBlockOperation(networkID, 10)
+	for _, bo := range bos {
+		bo.MustSave(st)
+	}
+
+	load := func(iterFunc func() (string, bool, []byte), closeFunc func()) (hashes []string, cursor []byte) {
+		defer closeFunc()
+		for {
+			hash, hasNext, c := iterFunc()
+			if !hasNext {
+				break
+			}
+			hashes = append(hashes, hash)
+			cursor = c
+		}
+		return
+	}
+
+	var hydrated []string
+	iterFunc, closeFunc := GetBlockOperationsBySource(st, bos[0].Source, nil)
+	for {
+		bo, hasNext, _ := iterFunc()
+		if !hasNext {
+			break
+		}
+		hydrated = append(hydrated, bo.Hash)
+	}
+	closeFunc()
+
+	bySource, _ := load(GetBlockOperationHashesBySource(st, bos[0].Source, nil))
+	require.Equal(t, 10, len(bySource))
+	require.Equal(t, hydrated, bySource)
+
+	byTxHash, _ := load(GetBlockOperationHashesByTxHash(st, bos[0].TxHash, nil))
+	require.Equal(t, hydrated, byTxHash)
+
+	{ // the cursor resumes the iteration
+		first, cursor := load(GetBlockOperationHashesBySource(st, bos[0].Source, storage.NewDefaultListOptions(false, nil, 4)))
+		require.Equal(t, hydrated[:4], first)
+
+		rest, _ := load(GetBlockOperationHashesBySource(st, bos[0].Source, storage.NewDefaultListOptions(false, cursor, 10)))
+		require.Equal(t, hydrated[4:], rest)
+	}
+}
+
+func TestGetBlockOperationsInHeightRange(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	// two operations in each block from height 1 to 10
+	kp := keypair.Random()
+	for height := uint64(1); height <= 10; height++ {
+		tx := transaction.TestMakeTransactionWithKeypair(networkID, 2, kp)
+		for _, op := range tx.B.Operations {
+			bo, err := NewBlockOperationFromOperation(op, tx, height)
+			require.NoError(t, err)
+			bo.MustSave(st)
+		}
+	}
+
+	heights := func(options storage.ListOptions) (hs []uint64) {
+		iterFunc, closeFunc := GetBlockOperationsBySource(st, kp.Address(), options)
+		defer closeFunc()
+		for {
+			bo, hasNext, _ := iterFunc()
+			if !hasNext {
+				break
+			}
+			hs = append(hs, bo.Height)
+		}
+		return
+	}
+
+	require.Equal(t, 20, len(heights(nil)))
+
+	{ // include the whole history
+		require.Equal(t, heights(nil), heights(NewHeightRangeListOptions(nil, 1, 10)))
+		require.Equal(t, heights(nil), heights(NewHeightRangeListOptions(nil, 0, 0)))
+	}
+
+	{ // inside the history; both bounds are inclusive
+		require.Equal(t, []uint64{3, 3, 4, 4, 5, 5}, heights(NewHeightRangeListOptions(nil, 3, 5)))
+		require.Equal(t, []uint64{7, 7}, heights(NewHeightRangeListOptions(nil, 7, 7)))
+	}
+
+	{ // exclude the history
+		require.Empty(t, heights(NewHeightRangeListOptions(nil, 11, 20)))
+		require.Empty(t, heights(NewHeightRangeListOptions(nil, 6, 5)))
+	}
+
+	{ // partially overlap the history
+		require.Equal(t, []uint64{9, 9, 10, 10}, heights(NewHeightRangeListOptions(nil, 9, 100)))
+		require.Equal(t, []uint64{9, 9, 10, 10}, heights(NewHeightRangeListOptions(nil, 9, 0)))
+		require.Equal(t, []uint64{1, 1, 2, 2}, heights(NewHeightRangeListOptions(nil, 0, 2)))
+	}
+
+	{ // with reverse and limit
+		options := storage.NewDefaultListOptions(true, nil, 3)
+		require.Equal(t, []uint64{5, 5, 4}, heights(NewHeightRangeListOptions(options, 3, 5)))
+	}
+
+	{ // the hashes by tx hash
+		tx := transaction.TestMakeTransactionWithKeypair(networkID, 1, kp)
+		bo, err := NewBlockOperationFromOperation(tx.B.Operations[0], tx, 15)
+		require.NoError(t, err)
+		bo.MustSave(st)
+
+		count := func(options storage.ListOptions) (n int) {
+			iterFunc, closeFunc := GetBlockOperationHashesByTxHash(st, tx.GetHash(), options)
+			defer closeFunc()
+			for {
+				if _, hasNext, _ := iterFunc(); !hasNext {
+					break
+				}
+				n++
+			}
+			return
+		}
+
+		require.Equal(t, 1, count(NewHeightRangeListOptions(nil, 10, 20)))
+		require.Equal(t, 0, count(NewHeightRangeListOptions(nil, 1, 14)))
+		require.Equal(t, 0, count(NewHeightRangeListOptions(nil, 16, 0)))
+	}
+}
+
+func benchmarkIterateBlockOperations(b *testing.B, indexOnly bool) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	bos := TestMakeNewBlockOperation(networkID, 100)
+	for _, bo := range bos {
+		bo.MustSave(st)
+	}
+	source := bos[0].Source
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var count int
+		if indexOnly {
+			iterFunc, closeFunc := GetBlockOperationHashesBySource(st, source, nil)
+			for {
+				if _, hasNext, _ := iterFunc(); !hasNext {
+					break
+				}
+				count++
+			}
+			closeFunc()
+		} else {
+			iterFunc, closeFunc := GetBlockOperationsBySource(st, source, nil)
+			for {
+				if _, hasNext, _ := iterFunc(); !hasNext {
+					break
+				}
+				count++
+			}
+			closeFunc()
+		}
+
+		if count != len(bos) {
+			b.Fatalf("expected %d operations, but %d", len(bos), count)
+		}
+	}
+}
+
+func BenchmarkIterateBlockOperationsHydrated(b *testing.B) {
+	benchmarkIterateBlockOperations(b, false)
+}
+
+func BenchmarkIterateBlockOperationsIndexOnly(b *testing.B) {
+	benchmarkIterateBlockOperations(b, true)
+}
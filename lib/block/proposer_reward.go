@@ -0,0 +1,101 @@
+package block
+
+import (
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// ProposerReward is the decoded proposer transaction of the block, which
+// pays the collected fee and the inflation into the common account.
+//  * `CollectedFee`, `CollectedTxs`, `BurnedFee`: from `operation.CollectTxFee`;
+//    `BurnedFee` of `CollectedFee` is not paid into the common account
+//  * `Inflation`, `InflationRatio`: from `operation.Inflation`
+type ProposerReward struct {
+	Height              uint64        `json:"block_height"`
+	ProposerTransaction string        `json:"proposer_transaction"`
+	Proposer            string        `json:"proposer"`
+	CommonAccount       string        `json:"common_account"`
+	CollectedFee        common.Amount `json:"collected_fee"`
+	CollectedTxs        uint64        `json:"collected_txs"`
+	BurnedFee           common.Amount `json:"burned_fee"`
+	Inflation           common.Amount `json:"inflation"`
+	InflationRatio      string        `json:"inflation_ratio"`
+}
+
+// DecodeProposerReward decodes the proposer transaction, which has
+// `operation.CollectTxFee` and `operation.Inflation` in order like
+// `ballot.NewProposerTransactionFromBallot()`; otherwise it returns
+// `errors.InvalidProposerTransaction`.
+func DecodeProposerReward(tx transaction.Transaction) (reward ProposerReward, err error) {
+	if len(tx.B.Operations) != 2 {
+		err = errors.InvalidProposerTransaction
+		return
+	}
+
+	opc, ok := tx.B.Operations[0].B.(operation.CollectTxFee)
+	if !ok {
+		err = errors.InvalidProposerTransaction
+		return
+	}
+	opi, ok := tx.B.Operations[1].B.(operation.Inflation)
+	if !ok {
+		err = errors.InvalidProposerTransaction
+		return
+	}
+
+	reward = ProposerReward{
+		ProposerTransaction: tx.GetHash(),
+		Proposer:            tx.B.Source,
+		CommonAccount:       opc.Target,
+		CollectedFee:        opc.Amount,
+		BurnedFee:           opc.Burned,
+		CollectedTxs:        opc.Txs,
+		Inflation:           opi.Amount,
+		InflationRatio:      opi.Ratio,
+	}
+
+	return
+}
+
+// GetBlockProposerTransaction returns the proposer transaction of the block
+// of the height. The genesis block does not have the proposer transaction, so
+// it returns `errors.BlockTransactionDoesNotExists`.
+func GetBlockProposerTransaction(st *storage.LevelDBBackend, height uint64) (tx transaction.Transaction, err error) {
+	var blk Block
+	if blk, err = GetBlockByHeight(st, height); err != nil {
+		return
+	}
+
+	if len(blk.ProposerTransaction) < 1 {
+		err = errors.BlockTransactionDoesNotExists
+		return
+	}
+
+	var tp TransactionPool
+	if tp, err = GetTransactionPool(st, blk.ProposerTransaction); err != nil {
+		return
+	}
+
+	tx = tp.Transaction()
+
+	return
+}
+
+// GetBlockProposerReward returns the decoded proposer transaction of the block
+// of the height; see `DecodeProposerReward()`.
+func GetBlockProposerReward(st *storage.LevelDBBackend, height uint64) (reward ProposerReward, err error) {
+	var tx transaction.Transaction
+	if tx, err = GetBlockProposerTransaction(st, height); err != nil {
+		return
+	}
+
+	if reward, err = DecodeProposerReward(tx); err != nil {
+		return
+	}
+	reward.Height = height
+
+	return
+}
@@ -0,0 +1,98 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func TestGetBlockProposerReward(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	proposer := keypair.Random()
+	txs := []transaction.Transaction{
+		transaction.TestMakeTransactionWithKeypair(networkID, 1, keypair.Random()),
+		transaction.TestMakeTransactionWithKeypair(networkID, 3, keypair.Random()),
+	}
+	var txHashes []string
+	for _, tx := range txs {
+		txHashes = append(txHashes, tx.GetHash())
+	}
+
+	latest := GetLatestBlock(st)
+	basis := voting.Basis{
+		Height:    latest.Height,
+		BlockHash: latest.Hash,
+		TotalTxs:  latest.TotalTxs,
+		TotalOps:  latest.TotalOps,
+	}
+	blt := ballot.NewBallot(proposer.Address(), proposer.Address(), basis, txHashes)
+
+	initialBalance := common.MaximumBalance
+	opc, err := ballot.NewCollectTxFeeFromBallot(*blt, CommonKP.Address(), txs...)
+	require.NoError(t, err)
+	opi, err := ballot.NewInflationFromBallot(*blt, CommonKP.Address(), initialBalance)
+	require.NoError(t, err)
+	ptx, err := ballot.NewProposerTransactionFromBallot(*blt, opc, opi)
+	require.NoError(t, err)
+
+	blk := NewBlock(
+		proposer.Address(),
+		voting.Basis{Height: latest.Height + 1, BlockHash: latest.Hash},
+		ptx.GetHash(),
+		txHashes,
+		nil,
+		common.NowISO8601(),
+	)
+	blk.MustSave(st)
+	_, err = SaveTransactionPool(st, ptx.Transaction)
+	require.NoError(t, err)
+
+	{ // amounts round-trip
+		tx, err := GetBlockProposerTransaction(st, blk.Height)
+		require.NoError(t, err)
+		require.Equal(t, ptx.GetHash(), tx.GetHash())
+
+		reward, err := GetBlockProposerReward(st, blk.Height)
+		require.NoError(t, err)
+
+		expectedInflation, err := common.DefaultInflationSchedule.CalculateInflation(basis.Height, initialBalance)
+		require.NoError(t, err)
+
+		require.Equal(t, blk.Height, reward.Height)
+		require.Equal(t, ptx.GetHash(), reward.ProposerTransaction)
+		require.Equal(t, proposer.Address(), reward.Proposer)
+		require.Equal(t, CommonKP.Address(), reward.CommonAccount)
+		require.Equal(t, common.BaseFee.MustMult(4), reward.CollectedFee)
+		require.Equal(t, uint64(2), reward.CollectedTxs)
+		require.True(t, expectedInflation > 0)
+		require.Equal(t, expectedInflation, reward.Inflation)
+		require.Equal(t, common.DefaultInflationSchedule.RatioString(basis.Height), reward.InflationRatio)
+	}
+
+	{ // genesis block does not have proposer transaction
+		_, err := GetBlockProposerReward(st, common.GenesisBlockHeight)
+		require.Equal(t, errors.BlockTransactionDoesNotExists, err)
+	}
+
+	{ // not proposer transaction
+		_, err := DecodeProposerReward(txs[0])
+		require.Equal(t, errors.InvalidProposerTransaction, err)
+
+		// the operations in the wrong order
+		ops := []operation.Operation{ptx.B.Operations[1], ptx.B.Operations[0]}
+		wrong, err := ballot.NewProposerTransaction(proposer.Address(), ops...)
+		require.NoError(t, err)
+		_, err = DecodeProposerReward(wrong.Transaction)
+		require.Equal(t, errors.InvalidProposerTransaction, err)
+	}
+}
@@ -0,0 +1,197 @@
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func GetBlockPrunedHeightKey() string {
+	return common.BlockPrefixPrunedHeight
+}
+
+// GetPrunedBlockHeight returns the height of the last pruned block; if nothing
+// was pruned, it returns `common.GenesisBlockHeight`.
+func GetPrunedBlockHeight(st *storage.LevelDBBackend) (height uint64, err error) {
+	if err = st.Get(GetBlockPrunedHeightKey(), &height); err != nil {
+		if err == errors.StorageRecordDoesNotExist {
+			err = nil
+			height = common.GenesisBlockHeight
+		}
+		return
+	}
+
+	return
+}
+
+func setPrunedBlockHeight(st *storage.LevelDBBackend, height uint64) (err error) {
+	var exists bool
+	if exists, err = st.Has(GetBlockPrunedHeightKey()); err != nil {
+		return
+	} else if exists {
+		return st.Set(GetBlockPrunedHeightKey(), height)
+	}
+
+	return st.New(GetBlockPrunedHeightKey(), height)
+}
+
+// PruneBlocks prunes the blocks from the next of the last pruned block to
+// `height`. It returns the number of the pruned blocks.
+func PruneBlocks(st *storage.LevelDBBackend, height uint64) (pruned int, err error) {
+	var last uint64
+	if last, err = GetPrunedBlockHeight(st); err != nil {
+		return
+	}
+
+	for h := last + 1; h <= height; h++ {
+		var blk Block
+		if blk, err = GetBlockByHeight(st, h); err != nil {
+			return
+		}
+		if err = PruneBlock(st, blk); err != nil {
+			return
+		}
+		if err = setPrunedBlockHeight(st, h); err != nil {
+			return
+		}
+		pruned++
+	}
+
+	return
+}
+
+// PruneBlock removes the `BlockTransaction`s and `BlockOperation`s of the
+// block with their index records. The `Block` itself and the `BlockAccount`s
+// are not touched, so the account states and the chain of block hashes are
+// kept. The genesis block can not be pruned.
+func PruneBlock(st *storage.LevelDBBackend, blk Block) (err error) {
+	if blk.Height <= common.GenesisBlockHeight {
+		return errors.WrongBlockFound
+	}
+
+	hashes := append([]string{}, blk.Transactions...)
+	if len(blk.ProposerTransaction) > 0 {
+		hashes = append(hashes, blk.ProposerTransaction)
+	}
+
+	for _, hash := range hashes {
+		if err = pruneBlockTransaction(st, blk, hash); err != nil {
+			return
+		}
+	}
+
+	return removeIndexRecords(st, GetBlockTransactionKeyPrefixBlock(blk.Hash), "")
+}
+
+func pruneBlockTransaction(st *storage.LevelDBBackend, blk Block, hash string) (err error) {
+	var bt BlockTransaction
+	if bt, err = GetBlockTransaction(st, hash); err != nil {
+		if err == errors.StorageRecordDoesNotExist {
+			err = nil
+		}
+		return
+	}
+
+	accounts := []string{bt.Source}
+	for _, opHash := range bt.Operations {
+		var bo BlockOperation
+		if bo, err = GetBlockOperation(st, opHash); err != nil {
+			if err == errors.StorageRecordDoesNotExist {
+				err = nil
+				continue
+			}
+			return
+		}
+
+		if opb, err := operation.UnmarshalBodyJSON(bo.Type, bo.Body); err == nil {
+			if pop, ok := opb.(operation.Payable); ok {
+				accounts = append(accounts, pop.TargetAddress())
+			}
+		}
+
+		// `BlockOperation` and `BlockTransaction` have the same height and
+		// sequenceID in their index keys.
+		prefix := fmt.Sprintf(
+			"%s%s%s",
+			GetBlockOperationKeyPrefixSource(bo.Source),
+			common.EncodeUint64ToByteSlice(bo.Height),
+			common.EncodeUint64ToByteSlice(bt.SequenceID),
+		)
+		if err = removeIndexRecords(st, prefix, bo.Hash); err != nil {
+			return
+		}
+		if err = st.Remove(GetBlockOperationKey(bo.Hash)); err != nil {
+			return
+		}
+	}
+	if err = removeIndexRecords(st, GetBlockOperationKeyPrefixTxHash(bt.Hash), ""); err != nil {
+		return
+	}
+
+	prefix := fmt.Sprintf(
+		"%s%s%s",
+		GetBlockTransactionKeyPrefixSource(bt.Source),
+		common.EncodeUint64ToByteSlice(blk.Height),
+		common.EncodeUint64ToByteSlice(bt.SequenceID),
+	)
+	if err = removeIndexRecords(st, prefix, bt.Hash); err != nil {
+		return
+	}
+	for _, address := range accounts {
+		prefix := fmt.Sprintf(
+			"%s%s%s",
+			GetBlockTransactionKeyPrefixAccount(address),
+			common.EncodeUint64ToByteSlice(blk.Height),
+			common.EncodeUint64ToByteSlice(bt.SequenceID),
+		)
+		if err = removeIndexRecords(st, prefix, bt.Hash); err != nil {
+			return
+		}
+	}
+	if err = removeIndexRecords(st, GetBlockTransactionKeyPrefixConfirmed(bt.Confirmed), bt.Hash); err != nil {
+		return
+	}
+
+	return st.Remove(GetBlockTransactionKey(bt.Hash))
+}
+
+// removeIndexRecords removes the index records under the prefix, which point
+// to `hash`; if `hash` is empty, all the records under the prefix are removed.
+// The keys are collected before removing, so the deletes do not run under the
+// open iterator.
+func removeIndexRecords(st *storage.LevelDBBackend, prefix, hash string) (err error) {
+	var keys []string
+
+	iterFunc, closeFunc := st.GetIterator(prefix, nil)
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		if len(hash) > 0 {
+			var h string
+			if json.Unmarshal(item.Value, &h) != nil || h != hash {
+				continue
+			}
+		}
+		keys = append(keys, string(item.Key))
+	}
+	closeFunc()
+
+	for _, key := range keys {
+		if err = st.Remove(key); err != nil {
+			if err == errors.StorageRecordDoesNotExist {
+				err = nil
+				continue
+			}
+			return
+		}
+	}
+
+	return
+}
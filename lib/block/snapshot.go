@@ -0,0 +1,306 @@
+package block
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"io"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// SnapshotMagic is the first bytes of snapshot stream.
+const SnapshotMagic = "SEBAK-SNAPSHOT"
+
+// SnapshotVersion is the format version of snapshot stream.
+const SnapshotVersion uint32 = 3
+
+// snapshotSection is the group of the storage records in snapshot.
+type snapshotSection struct {
+	Name     string
+	Prefixes []string
+}
+
+// snapshotSections are the sections of snapshot in order; the transaction
+// pool is not the ledger, so it is not included.
+var snapshotSections = []snapshotSection{
+	{
+		Name: "block",
+		Prefixes: []string{
+			common.BlockPrefixHash,
+			common.BlockPrefixConfirmed,
+			common.BlockPrefixHeight,
+			common.BlockPrefixPrunedHeight,
+		},
+	},
+	{
+		Name: "transaction",
+		Prefixes: []string{
+			common.BlockTransactionPrefixHash,
+			common.BlockTransactionPrefixSource,
+			common.BlockTransactionPrefixConfirmed,
+			common.BlockTransactionPrefixAccount,
+			common.BlockTransactionPrefixBlock,
+		},
+	},
+	{
+		Name: "operation",
+		Prefixes: []string{
+			common.BlockOperationPrefixHash,
+			common.BlockOperationPrefixTxHash,
+			common.BlockOperationPrefixSource,
+			common.BlockOperationPrefixTarget,
+			common.BlockOperationPrefixPeers,
+		},
+	},
+	{
+		Name: "account",
+		Prefixes: []string{
+			common.BlockAccountPrefixAddress,
+			common.BlockAccountPrefixCreated,
+			common.BlockAccountSequenceIDPrefix,
+			common.BlockAccountSequenceIDByAddressPrefix,
+			common.BlockAccountRollupPrefixAddress,
+			common.BlockAccountDataPrefixAddress,
+			common.BlockAccountCheckpointPrefixHeight,
+			common.BlockAccountCheckpointPrefixAccount,
+			common.BlockAccountMergePrefixSource,
+			common.BlockTimeLockPrefixTarget,
+		},
+	},
+	{
+		Name: "validator",
+		Prefixes: []string{
+			common.BlockValidatorChangePrefixHeight,
+		},
+	},
+	{
+		Name: "governance",
+		Prefixes: []string{
+			common.GovernanceProposalPrefixHash,
+			common.GovernanceProposalPrefixCloseHeight,
+			common.GovernanceVotePrefixProposal,
+		},
+	},
+}
+
+// ExportSnapshot writes all the blocks, transactions, operations and account
+// states of the storage into the single stream, so the new node can be
+// bootstrapped by `ImportSnapshot()` without replaying the consensus.
+//
+// The stream starts with `SnapshotMagic` and `SnapshotVersion`; each section
+// has the name, the records of key and value and the sha256 checksum of them.
+// The record and the name are length-prefixed and the empty key ends the
+// section.
+func ExportSnapshot(st *storage.LevelDBBackend, w io.Writer) (err error) {
+	bw := bufio.NewWriter(w)
+
+	if _, err = bw.WriteString(SnapshotMagic); err != nil {
+		return
+	}
+	if err = binary.Write(bw, binary.BigEndian, SnapshotVersion); err != nil {
+		return
+	}
+
+	for _, section := range snapshotSections {
+		if err = exportSnapshotSection(st, bw, section); err != nil {
+			return
+		}
+	}
+
+	// the empty name ends the stream
+	if err = writeSnapshotBytes(bw, nil, nil); err != nil {
+		return
+	}
+
+	return bw.Flush()
+}
+
+func exportSnapshotSection(st *storage.LevelDBBackend, w io.Writer, section snapshotSection) (err error) {
+	checksum := sha256.New()
+
+	if err = writeSnapshotBytes(w, checksum, []byte(section.Name)); err != nil {
+		return
+	}
+
+	for _, prefix := range section.Prefixes {
+		iterFunc, closeFunc := st.GetIterator(prefix, nil)
+		for {
+			item, hasNext := iterFunc()
+			if !hasNext {
+				break
+			}
+
+			if err = writeSnapshotBytes(w, checksum, item.Key); err != nil {
+				closeFunc()
+				return
+			}
+			if err = writeSnapshotBytes(w, checksum, item.Value); err != nil {
+				closeFunc()
+				return
+			}
+		}
+		closeFunc()
+	}
+
+	if err = writeSnapshotBytes(w, checksum, nil); err != nil {
+		return
+	}
+
+	_, err = w.Write(checksum.Sum(nil))
+
+	return
+}
+
+// ImportSnapshot restores the stream of `ExportSnapshot()` into the storage.
+// The records are stored only when all the checksums match and the genesis
+// block of snapshot is same with the genesis block of the storage; if the
+// storage does not have the genesis block yet, the genesis block of snapshot
+// is used.
+func ImportSnapshot(st *storage.LevelDBBackend, r io.Reader) (err error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(SnapshotMagic))
+	if _, err = io.ReadFull(br, magic); err != nil || string(magic) != SnapshotMagic {
+		return errors.InvalidSnapshot
+	}
+	var version uint32
+	if err = binary.Read(br, binary.BigEndian, &version); err != nil || version != SnapshotVersion {
+		return errors.InvalidSnapshot
+	}
+
+	var genesis Block
+	var hasGenesis bool
+	if genesis, err = GetBlockByHeight(st, common.GenesisBlockHeight); err == nil {
+		hasGenesis = true
+	} else if err.(*errors.Error).Code != errors.StorageRecordDoesNotExist.Code {
+		return
+	}
+
+	var bs *storage.LevelDBBackend
+	if bs, err = st.OpenBatch(); err != nil {
+		return
+	}
+
+	for _, section := range snapshotSections {
+		if err = importSnapshotSection(bs, br, section); err != nil {
+			bs.Discard()
+			return
+		}
+	}
+
+	// the end of stream
+	var end []byte
+	if end, err = readSnapshotBytes(br, nil); err != nil || len(end) > 0 {
+		bs.Discard()
+		return errors.InvalidSnapshot
+	}
+
+	var imported Block
+	if imported, err = GetBlockByHeight(bs, common.GenesisBlockHeight); err != nil {
+		bs.Discard()
+		return errors.InvalidSnapshot
+	}
+	if hasGenesis && imported.Hash != genesis.Hash {
+		bs.Discard()
+		return errors.GenesisNotMatched.Clone().SetData("hash", imported.Hash)
+	}
+
+	return bs.Commit()
+}
+
+func importSnapshotSection(st *storage.LevelDBBackend, r io.Reader, section snapshotSection) (err error) {
+	checksum := sha256.New()
+
+	var name []byte
+	if name, err = readSnapshotBytes(r, checksum); err != nil {
+		return
+	}
+	if string(name) != section.Name {
+		return errors.InvalidSnapshot.Clone().SetData("section", section.Name)
+	}
+
+	for {
+		var key, value []byte
+		if key, err = readSnapshotBytes(r, checksum); err != nil {
+			return
+		}
+		if len(key) < 1 {
+			break
+		}
+		if value, err = readSnapshotBytes(r, checksum); err != nil {
+			return
+		}
+
+		if !section.hasPrefix(key) {
+			return errors.InvalidSnapshot.Clone().SetData("section", section.Name)
+		}
+		if err = st.PutRaw(string(key), value); err != nil {
+			return
+		}
+	}
+
+	expected := make([]byte, sha256.Size)
+	if _, err = io.ReadFull(r, expected); err != nil {
+		return errors.InvalidSnapshot
+	}
+	if !bytes.Equal(expected, checksum.Sum(nil)) {
+		return errors.SnapshotChecksumNotMatched.Clone().SetData("section", section.Name)
+	}
+
+	return
+}
+
+func (s snapshotSection) hasPrefix(key []byte) bool {
+	for _, prefix := range s.Prefixes {
+		if bytes.HasPrefix(key, []byte(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeSnapshotBytes(w io.Writer, checksum hash.Hash, b []byte) (err error) {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(b)))
+
+	if _, err = w.Write(l[:]); err != nil {
+		return
+	}
+	if _, err = w.Write(b); err != nil {
+		return
+	}
+
+	if checksum != nil {
+		checksum.Write(l[:])
+		checksum.Write(b)
+	}
+
+	return
+}
+
+func readSnapshotBytes(r io.Reader, checksum hash.Hash) (b []byte, err error) {
+	var l [4]byte
+	if _, err = io.ReadFull(r, l[:]); err != nil {
+		err = errors.InvalidSnapshot
+		return
+	}
+
+	b = make([]byte, binary.BigEndian.Uint32(l[:]))
+	if _, err = io.ReadFull(r, b); err != nil {
+		err = errors.InvalidSnapshot
+		return
+	}
+
+	if checksum != nil {
+		checksum.Write(l[:])
+		checksum.Write(b)
+	}
+
+	return
+}
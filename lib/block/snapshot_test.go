@@ -0,0 +1,132 @@
+package block
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func makeTestSnapshotBlockchain(t *testing.T) *storage.LevelDBBackend {
+	st := InitTestBlockchain()
+
+	for i := 0; i < 3; i++ {
+		latest := GetLatestBlock(st)
+
+		_, tx := transaction.TestMakeTransaction(networkID, i+1)
+		operations := GetBlockOperationHashes(tx)
+		basis := voting.Basis{Height: latest.Height + 1, BlockHash: latest.Hash}
+		blk := NewBlock("", basis, "", []string{tx.GetHash()}, operations, common.NowISO8601())
+		require.NoError(t, blk.Save(st))
+
+		bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.Confirmed, tx)
+		require.NoError(t, bt.Save(st))
+		require.NoError(t, bt.SaveBlockOperations(st, *blk))
+
+		require.NoError(t, TestMakeBlockAccount().Save(st))
+	}
+
+	return st
+}
+
+func dumpTestStorage(st *storage.LevelDBBackend) map[string]string {
+	dumped := map[string]string{}
+	for _, section := range snapshotSections {
+		for _, prefix := range section.Prefixes {
+			iterFunc, closeFunc := st.GetIterator(prefix, nil)
+			for {
+				item, hasNext := iterFunc()
+				if !hasNext {
+					break
+				}
+				dumped[string(item.Key)] = string(item.Value)
+			}
+			closeFunc()
+		}
+	}
+
+	return dumped
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	st := makeTestSnapshotBlockchain(t)
+	defer st.Close()
+
+	var b bytes.Buffer
+	require.NoError(t, ExportSnapshot(st, &b))
+
+	imported := storage.NewTestStorage()
+	defer imported.Close()
+
+	require.NoError(t, ImportSnapshot(imported, bytes.NewReader(b.Bytes())))
+
+	expected := dumpTestStorage(st)
+	require.NotEmpty(t, expected)
+	require.Equal(t, expected, dumpTestStorage(imported))
+	require.Equal(t, GetLatestBlock(st), GetLatestBlock(imported))
+
+	// importing again into the same storage with the same genesis is fine
+	require.NoError(t, ImportSnapshot(imported, bytes.NewReader(b.Bytes())))
+}
+
+func TestSnapshotChecksumNotMatched(t *testing.T) {
+	st := makeTestSnapshotBlockchain(t)
+	defer st.Close()
+
+	var b bytes.Buffer
+	require.NoError(t, ExportSnapshot(st, &b))
+
+	// corrupt the last byte of the value of the first record in "block" section
+	corrupted := b.Bytes()
+	offset := len(SnapshotMagic) + 4 + 4 + len("block")
+	keyLength := int(corrupted[offset+3])
+	offset += 4 + keyLength
+	valueLength := int(corrupted[offset+2])<<8 | int(corrupted[offset+3])
+	corrupted[offset+4+valueLength-1] ^= 0xff
+
+	imported := storage.NewTestStorage()
+	defer imported.Close()
+
+	err := ImportSnapshot(imported, bytes.NewReader(corrupted))
+	require.Error(t, err)
+	require.Equal(t, errors.SnapshotChecksumNotMatched.Code, err.(*errors.Error).Code)
+
+	// nothing is stored
+	require.Empty(t, dumpTestStorage(imported))
+}
+
+func TestSnapshotGenesisNotMatched(t *testing.T) {
+	st := makeTestSnapshotBlockchain(t)
+	defer st.Close()
+
+	var b bytes.Buffer
+	require.NoError(t, ExportSnapshot(st, &b))
+
+	// the genesis block of the other network
+	other := storage.NewTestStorage()
+	defer other.Close()
+
+	genesisAccount := TestMakeBlockAccount()
+	commonAccount := TestMakeBlockAccount()
+	require.NoError(t, genesisAccount.Save(other))
+	require.NoError(t, commonAccount.Save(other))
+	_, err := MakeGenesisBlock(other, *genesisAccount, *commonAccount, networkID)
+	require.NoError(t, err)
+
+	expected := dumpTestStorage(other)
+
+	err = ImportSnapshot(other, bytes.NewReader(b.Bytes()))
+	require.Error(t, err)
+	require.Equal(t, errors.GenesisNotMatched.Code, err.(*errors.Error).Code)
+	require.Equal(t, expected, dumpTestStorage(other))
+
+	// invalid stream
+	err = ImportSnapshot(other, bytes.NewReader([]byte("showme")))
+	require.Equal(t, errors.InvalidSnapshot, err)
+}
@@ -104,6 +104,7 @@ func TestMakeNewBlock(transactions []string) Block {
 		},
 		"",
 		transactions,
+		nil,
 		common.NowISO8601(),
 	)
 }
@@ -121,6 +122,7 @@ func TestMakeNewBlockWithPrevBlock(prevBlock Block, txs []string) Block {
 		},
 		"",
 		txs,
+		nil,
 		common.NowISO8601(),
 	)
 }
@@ -0,0 +1,89 @@
+package block
+
+import (
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// BlockTimeLock is the amount, which is locked by `operation.TimeLockedPayment`
+// from `Source` to `Target` until the block of `UnlockHeight`. The locks of
+// the same source, target and height are merged into one.
+type BlockTimeLock struct {
+	Target       string        `json:"target"`
+	Source       string        `json:"source"`
+	Amount       common.Amount `json:"amount"`
+	UnlockHeight uint64        `json:"unlock_height"`
+}
+
+func getBlockTimeLockKeyPrefixTarget(target string) string {
+	return fmt.Sprintf("%s%s-", common.BlockTimeLockPrefixTarget, target)
+}
+
+func GetBlockTimeLockKey(target, source string, unlockHeight uint64) string {
+	return fmt.Sprintf("%s%s-%020d", getBlockTimeLockKeyPrefixTarget(target), source, unlockHeight)
+}
+
+// GetBlockTimeLock returns the time lock; if it does not exist, it returns
+// `errors.StorageRecordDoesNotExist`.
+func GetBlockTimeLock(st *storage.LevelDBBackend, target, source string, unlockHeight uint64) (lock BlockTimeLock, err error) {
+	err = st.Get(GetBlockTimeLockKey(target, source, unlockHeight), &lock)
+	return
+}
+
+// GetBlockTimeLocks returns the time locks of `target`.
+func GetBlockTimeLocks(st *storage.LevelDBBackend, target string) (locks []BlockTimeLock, err error) {
+	iterFunc, closeFunc := st.GetIterator(getBlockTimeLockKeyPrefixTarget(target), nil)
+	defer closeFunc()
+
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var lock BlockTimeLock
+		if err = common.DecodeJSONValue(item.Value, &lock); err != nil {
+			return
+		}
+		locks = append(locks, lock)
+	}
+
+	return
+}
+
+// AddBlockTimeLock locks `amount`; if the lock of the same source, target
+// and height exists, the amount is added to it.
+func AddBlockTimeLock(st *storage.LevelDBBackend, target, source string, unlockHeight uint64, amount common.Amount) (lock BlockTimeLock, err error) {
+	key := GetBlockTimeLockKey(target, source, unlockHeight)
+
+	var exists bool
+	if exists, err = st.Has(key); err != nil {
+		return
+	} else if !exists {
+		lock = BlockTimeLock{
+			Target:       target,
+			Source:       source,
+			Amount:       amount,
+			UnlockHeight: unlockHeight,
+		}
+		err = st.New(key, lock)
+		return
+	}
+
+	if err = st.Get(key, &lock); err != nil {
+		return
+	}
+	if lock.Amount, err = lock.Amount.Add(amount); err != nil {
+		return
+	}
+	err = st.Set(key, lock)
+
+	return
+}
+
+// Remove deletes the claimed time lock.
+func (lock BlockTimeLock) Remove(st *storage.LevelDBBackend) error {
+	return st.Remove(GetBlockTimeLockKey(lock.Target, lock.Source, lock.UnlockHeight))
+}
@@ -0,0 +1,52 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+func TestBlockTimeLock(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	target := keypair.Random().Address()
+	source := keypair.Random().Address()
+
+	_, err := GetBlockTimeLock(st, target, source, 10)
+	require.Equal(t, errors.StorageRecordDoesNotExist, err)
+
+	_, err = AddBlockTimeLock(st, target, source, 10, common.Amount(100))
+	require.NoError(t, err)
+
+	{ // same height is merged
+		lock, err := AddBlockTimeLock(st, target, source, 10, common.Amount(50))
+		require.NoError(t, err)
+		require.Equal(t, common.Amount(150), lock.Amount)
+	}
+
+	_, err = AddBlockTimeLock(st, target, source, 20, common.Amount(30))
+	require.NoError(t, err)
+
+	// the lock of the other target
+	_, err = AddBlockTimeLock(st, source, target, 10, common.Amount(1))
+	require.NoError(t, err)
+
+	locks, err := GetBlockTimeLocks(st, target)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(locks))
+	require.Equal(t, common.Amount(150), locks[0].Amount)
+	require.Equal(t, uint64(20), locks[1].UnlockHeight)
+
+	lock, err := GetBlockTimeLock(st, target, source, 10)
+	require.NoError(t, err)
+	require.NoError(t, lock.Remove(st))
+
+	_, err = GetBlockTimeLock(st, target, source, 10)
+	require.Equal(t, errors.StorageRecordDoesNotExist, err)
+}
@@ -34,6 +34,8 @@ type BlockTransaction struct {
 	Operations []string      `json:"operations"`
 	Amount     common.Amount `json:"amount"`
 
+	Memo *transaction.Memo `json:"memo,omitempty"`
+
 	Confirmed string `json:"confirmed"`
 	Created   string `json:"created"`
 	Message   []byte `json:"message"`
@@ -58,6 +60,7 @@ func NewBlockTransactionFromTransaction(blockHash string, blockHeight uint64, co
 		Fee:        tx.B.Fee,
 		Operations: opHashes,
 		Amount:     tx.TotalAmount(true),
+		Memo:       tx.B.Memo,
 		Confirmed:  confirmed,
 		Created:    tx.H.Created,
 
@@ -176,6 +179,12 @@ func (bt BlockTransaction) Transaction() transaction.Transaction {
 }
 
 func (bt BlockTransaction) SaveBlockOperations(st *storage.LevelDBBackend, blk Block) (err error) {
+	return bt.SaveBlockOperationsWithCodec(st, blk, common.DefaultStorageCodec)
+}
+
+// SaveBlockOperationsWithCodec saves the `BlockOperation`s of the transaction
+// encoded by the codec.
+func (bt BlockTransaction) SaveBlockOperationsWithCodec(st *storage.LevelDBBackend, blk Block, codec common.StorageCodec) (err error) {
 	if bt.Transaction().IsEmpty() {
 		err = errors.FailedToSaveBlockOperaton
 		return
@@ -189,7 +198,7 @@ func (bt BlockTransaction) SaveBlockOperations(st *storage.LevelDBBackend, blk B
 		if err != nil {
 			return
 		}
-		if err = bo.Save(st); err != nil {
+		if err = bo.SaveWithCodec(st, codec); err != nil {
 			return
 		}
 		if pop, ok := op.B.(operation.Payable); ok {
@@ -246,20 +255,25 @@ func LoadBlockTransactionsInsideIterator(
 ) {
 
 	return (func() (BlockTransaction, bool, []byte) {
-			item, hasNext := iterFunc()
-			if !hasNext {
-				return BlockTransaction{}, false, item.Key
-			}
-
-			var hash string
-			json.Unmarshal(item.Value, &hash)
-
-			bt, err := GetBlockTransaction(st, hash)
-			if err != nil {
-				return BlockTransaction{}, false, item.Key
+			for {
+				item, hasNext := iterFunc()
+				if !hasNext {
+					return BlockTransaction{}, false, item.Key
+				}
+
+				var hash string
+				json.Unmarshal(item.Value, &hash)
+
+				bt, err := GetBlockTransaction(st, hash)
+				if err == errors.StorageRecordDoesNotExist {
+					// pruned after the iterator was opened; skip it
+					continue
+				} else if err != nil {
+					return BlockTransaction{}, false, item.Key
+				}
+
+				return bt, hasNext, item.Key
 			}
-
-			return bt, hasNext, item.Key
 		}), (func() {
 			closeFunc()
 		})
@@ -0,0 +1,119 @@
+package block
+
+import (
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// BlockTransactionDetail is the `BlockTransaction` with its decoded
+// operations and the block metadata, so the explorer does not need to
+// assemble the transaction from `GetBlockOperationsByTxHash`.
+type BlockTransactionDetail struct {
+	Transaction BlockTransaction
+	Operations  []BlockOperationDetail
+
+	Height    uint64
+	Confirmed string
+	Fee       common.Amount
+
+	// IsProposerTransaction is true when the transaction is the
+	// `ProposerTransaction` of the block.
+	IsProposerTransaction bool
+}
+
+// BlockOperationDetail is the `BlockOperation` with the decoded body.
+type BlockOperationDetail struct {
+	Hash   string
+	Type   operation.OperationType
+	Source string
+	Body   operation.Body
+}
+
+// GetBlockTransactionDetail loads the `BlockTransaction` and decodes the
+// operations in the order of `BlockTransaction.Operations`. If the
+// operations are not saved yet, they are made from the transaction in
+// `TransactionPool`.
+func GetBlockTransactionDetail(st *storage.LevelDBBackend, hash string) (detail BlockTransactionDetail, err error) {
+	var bt BlockTransaction
+	if bt, err = GetBlockTransaction(st, hash); err != nil {
+		return
+	}
+
+	var blk Block
+	if blk, err = GetBlock(st, bt.Block); err != nil {
+		return
+	}
+
+	var bos []BlockOperation
+	if bos, err = getBlockTransactionOperations(st, bt, blk.Height); err != nil {
+		return
+	}
+
+	detail = BlockTransactionDetail{
+		Transaction:           bt,
+		Height:                blk.Height,
+		Confirmed:             bt.Confirmed,
+		Fee:                   bt.Fee,
+		IsProposerTransaction: blk.ProposerTransaction == bt.Hash,
+	}
+
+	for _, bo := range bos {
+		var body operation.Body
+		if body, err = operation.UnmarshalBodyJSON(bo.Type, bo.Body); err != nil {
+			return
+		}
+		detail.Operations = append(detail.Operations, BlockOperationDetail{
+			Hash:   bo.Hash,
+			Type:   bo.Type,
+			Source: bo.Source,
+			Body:   body,
+		})
+	}
+
+	return
+}
+
+func getBlockTransactionOperations(st *storage.LevelDBBackend, bt BlockTransaction, height uint64) (bos []BlockOperation, err error) {
+	for _, opHash := range bt.Operations {
+		var exists bool
+		if exists, err = ExistsBlockOperation(st, opHash); err != nil {
+			return
+		} else if !exists {
+			return makeBlockTransactionOperations(st, bt, height)
+		}
+
+		var bo BlockOperation
+		if bo, err = GetBlockOperation(st, opHash); err != nil {
+			return
+		}
+		bos = append(bos, bo)
+	}
+
+	return
+}
+
+func makeBlockTransactionOperations(st *storage.LevelDBBackend, bt BlockTransaction, height uint64) (bos []BlockOperation, err error) {
+	tx := bt.Transaction()
+	if tx.IsEmpty() {
+		var tp TransactionPool
+		if tp, err = GetTransactionPool(st, bt.Hash); err != nil {
+			if err == errors.StorageRecordDoesNotExist {
+				err = errors.BlockOperationDoesNotExists
+			}
+			return
+		}
+		tx = tp.Transaction()
+	}
+
+	for _, op := range tx.B.Operations {
+		var bo BlockOperation
+		if bo, err = NewBlockOperationFromOperation(op, tx, height); err != nil {
+			return
+		}
+		bos = append(bos, bo)
+	}
+
+	return
+}
@@ -0,0 +1,105 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func TestGetBlockTransactionDetail(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	kp := keypair.Random()
+	tx := transaction.TestMakeTransactionWithKeypair(networkID, 3, kp)
+
+	// proposer transaction
+	commonKP := keypair.Random()
+	opb := operation.NewCollectTxFee(commonKP.Address(), common.BaseFee, 1, 2, "showme", 1)
+	op, err := operation.NewOperation(opb)
+	require.NoError(t, err)
+	ptx, err := transaction.NewTransaction(kp.Address(), 0, op)
+	require.NoError(t, err)
+	ptx.Sign(kp, networkID)
+
+	blk := *NewBlock(
+		keypair.Random().Address(),
+		voting.Basis{Height: 2, BlockHash: "showme", TotalTxs: 2, TotalOps: 4},
+		ptx.GetHash(),
+		[]string{tx.GetHash()},
+		nil,
+		common.NowISO8601(),
+	)
+	blk.MustSave(st)
+
+	bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.Confirmed, tx)
+	bt.MustSave(st)
+	require.NoError(t, bt.SaveBlockOperations(st, blk))
+
+	{ // unknown transaction
+		_, err := GetBlockTransactionDetail(st, "findme")
+		require.Equal(t, errors.StorageRecordDoesNotExist, err)
+	}
+
+	{ // compare with the manual reconstruction
+		detail, err := GetBlockTransactionDetail(st, tx.GetHash())
+		require.NoError(t, err)
+		require.Equal(t, tx.GetHash(), detail.Transaction.Hash)
+		require.Equal(t, blk.Height, detail.Height)
+		require.Equal(t, blk.Confirmed, detail.Confirmed)
+		require.Equal(t, tx.B.Fee, detail.Fee)
+		require.False(t, detail.IsProposerTransaction)
+
+		var expected []BlockOperationDetail
+		iterFunc, closeFunc := GetBlockOperationsByTxHash(st, tx.GetHash(), nil)
+		for {
+			bo, hasNext, _ := iterFunc()
+			if !hasNext {
+				break
+			}
+			body, err := operation.UnmarshalBodyJSON(bo.Type, bo.Body)
+			require.NoError(t, err)
+			expected = append(expected, BlockOperationDetail{Hash: bo.Hash, Type: bo.Type, Source: bo.Source, Body: body})
+		}
+		closeFunc()
+
+		require.Equal(t, 3, len(detail.Operations))
+		require.Equal(t, expected, detail.Operations)
+		for i, op := range tx.B.Operations {
+			require.Equal(t, bt.Operations[i], detail.Operations[i].Hash)
+			require.Equal(t, op.B, detail.Operations[i].Body)
+		}
+	}
+
+	{ // proposer transaction, the operations are not saved yet
+		pbt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.Confirmed, ptx)
+		pbt.MustSave(st)
+
+		_, err := GetBlockTransactionDetail(st, ptx.GetHash())
+		require.Equal(t, errors.BlockOperationDoesNotExists, err)
+
+		_, err = SaveTransactionPool(st, ptx)
+		require.NoError(t, err)
+
+		detail, err := GetBlockTransactionDetail(st, ptx.GetHash())
+		require.NoError(t, err)
+		require.True(t, detail.IsProposerTransaction)
+		require.Equal(t, 1, len(detail.Operations))
+		require.Equal(t, operation.TypeCollectTxFee, detail.Operations[0].Type)
+		require.Equal(t, opb, detail.Operations[0].Body)
+
+		// same result after the operations are saved
+		require.NoError(t, pbt.SaveBlockOperations(st, blk))
+		saved, err := GetBlockTransactionDetail(st, ptx.GetHash())
+		require.NoError(t, err)
+		require.Equal(t, detail.Operations, saved.Operations)
+	}
+}
@@ -54,6 +54,31 @@ func TestBlockTransactionSaveAndGet(t *testing.T) {
 	require.Equal(t, len(fetched.Confirmed) > 0, true)
 }
 
+func TestBlockTransactionMemo(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	kp, tx := transaction.TestMakeTransaction(networkID, 1)
+	tx.B.Memo = transaction.NewMemo(transaction.MemoText, "deposit-1234")
+	tx.Sign(kp, networkID)
+
+	block := TestMakeNewBlock([]string{tx.GetHash()})
+	bt := NewBlockTransactionFromTransaction(block.Hash, block.Height, block.Confirmed, tx)
+	require.NoError(t, bt.Save(st))
+
+	fetched, err := GetBlockTransaction(st, bt.Hash)
+	require.NoError(t, err)
+	require.Equal(t, tx.B.Memo, fetched.Memo)
+
+	bo, err := NewBlockOperationFromOperation(tx.B.Operations[0], tx, block.Height)
+	require.NoError(t, err)
+	require.NoError(t, bo.Save(st))
+
+	fetchedOp, err := GetBlockOperation(st, bo.Hash)
+	require.NoError(t, err)
+	require.Equal(t, tx.B.Memo, fetchedOp.Memo)
+}
+
 func TestBlockTransactionSaveExisting(t *testing.T) {
 	st := storage.NewTestStorage()
 
@@ -404,6 +429,10 @@ func TestMultipleBlockTransactionsOrderByBlockHeightAndCursor(t *testing.T) {
 	// To check iteration order by height
 	var transactionOrder []string
 
+	// The transactions of height 2 are saved first, but the blocks are saved
+	// in height order
+	var blocks []Block
+
 	// Make transactions with height 2 first
 	{
 		var createdOrder []string
@@ -423,7 +452,7 @@ func TestMultipleBlockTransactionsOrderByBlockHeightAndCursor(t *testing.T) {
 			bt.MustSave(st)
 		}
 		transactionOrder = append(transactionOrder, createdOrder...)
-		block.MustSave(st)
+		blocks = append(blocks, block)
 	}
 
 	// Make transactions with height 1
@@ -445,6 +474,10 @@ func TestMultipleBlockTransactionsOrderByBlockHeightAndCursor(t *testing.T) {
 		}
 
 		transactionOrder = append(createdOrder, transactionOrder...)
+		blocks = append([]Block{block}, blocks...)
+	}
+
+	for _, block := range blocks {
 		block.MustSave(st)
 	}
 
@@ -0,0 +1,71 @@
+package block
+
+import (
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// BlockValidatorChange is the change of the validator set, which is stored by
+// `operation.ValidatorSetChange`. It takes effect after the block of `Height`
+// is stored.
+type BlockValidatorChange struct {
+	Height   uint64 `json:"height"`
+	Address  string `json:"address"`
+	Endpoint string `json:"endpoint"`
+	Remove   bool   `json:"remove"`
+}
+
+func GetBlockValidatorChangeKey(height uint64, address string) string {
+	return fmt.Sprintf("%s%020d%s", common.BlockValidatorChangePrefixHeight, height, address)
+}
+
+// Save stores the change; the later change of the same validator at the same
+// height overwrites the former.
+func (c BlockValidatorChange) Save(st *storage.LevelDBBackend) (err error) {
+	key := GetBlockValidatorChangeKey(c.Height, c.Address)
+
+	var exists bool
+	if exists, err = st.Has(key); err != nil {
+		return
+	} else if exists {
+		return st.Set(key, c)
+	}
+
+	return st.New(key, c)
+}
+
+// GetBlockValidatorChanges returns the changes from the height of `from` to
+// `to` inclusively in height order.
+func GetBlockValidatorChanges(st *storage.LevelDBBackend, from, to uint64) (changes []BlockValidatorChange, err error) {
+	iterFunc, closeFunc := st.GetIterator(common.BlockValidatorChangePrefixHeight, nil)
+	defer closeFunc()
+
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var c BlockValidatorChange
+		if err = common.DecodeJSONValue(item.Value, &c); err != nil {
+			return
+		}
+		if c.Height < from {
+			continue
+		}
+		if c.Height > to {
+			break
+		}
+		changes = append(changes, c)
+	}
+
+	return
+}
+
+// GetPendingBlockValidatorChanges returns the changes, which are not yet
+// taken effect at the block of `height`.
+func GetPendingBlockValidatorChanges(st *storage.LevelDBBackend, height uint64) ([]BlockValidatorChange, error) {
+	return GetBlockValidatorChanges(st, height+1, ^uint64(0))
+}
@@ -0,0 +1,58 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/storage"
+)
+
+func TestBlockValidatorChanges(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	var saved []BlockValidatorChange
+	for _, height := range []uint64{12, 3, 7} {
+		c := BlockValidatorChange{
+			Height:   height,
+			Address:  keypair.Random().Address(),
+			Endpoint: "https://localhost:12345",
+		}
+		require.NoError(t, c.Save(st))
+		saved = append(saved, c)
+	}
+
+	{ // in height order
+		changes, err := GetBlockValidatorChanges(st, 1, 100)
+		require.NoError(t, err)
+		require.Equal(t, []BlockValidatorChange{saved[1], saved[2], saved[0]}, changes)
+	}
+
+	{ // inclusive range
+		changes, err := GetBlockValidatorChanges(st, 3, 7)
+		require.NoError(t, err)
+		require.Equal(t, []BlockValidatorChange{saved[1], saved[2]}, changes)
+	}
+
+	{ // pending
+		changes, err := GetPendingBlockValidatorChanges(st, 7)
+		require.NoError(t, err)
+		require.Equal(t, []BlockValidatorChange{saved[0]}, changes)
+
+		changes, err = GetPendingBlockValidatorChanges(st, 12)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(changes))
+	}
+
+	{ // overwritten
+		c := saved[0]
+		c.Remove = true
+		require.NoError(t, c.Save(st))
+
+		changes, err := GetPendingBlockValidatorChanges(st, 7)
+		require.NoError(t, err)
+		require.Equal(t, []BlockValidatorChange{c}, changes)
+	}
+}
@@ -10,6 +10,8 @@ type Problem struct {
 	Status   int                        `json:"status"`
 	Detail   string                     `json:"detail,omitempty"`
 	Instance string                     `json:"instance,omitempty"`
+	Code     uint                       `json:"code,omitempty"`
+	Field    string                     `json:"field,omitempty"`
 	Extras   map[string]json.RawMessage `json:"extras,omitempty"`
 }
 
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+// StorageCodec decides the encoding of the values in the storage; see
+// `Config.StorageCodec`.
+type StorageCodec string
+
+const (
+	// StorageCodecJSON encodes the value in JSON without header, so it is
+	// same with the data of the previous versions.
+	StorageCodecJSON StorageCodec = "json"
+
+	// StorageCodecMsgpack encodes the value in MessagePack after
+	// `StorageCodecHeaderMsgpack`. The fields are named by their JSON tags,
+	// but `MarshalJSON()` of the value is not used.
+	StorageCodecMsgpack StorageCodec = "msgpack"
+)
+
+// StorageCodecHeaderMsgpack is the first byte of the value of
+// `StorageCodecMsgpack`. It is never used by MessagePack and can not start
+// JSON, so the legacy JSON value is still decoded.
+const StorageCodecHeaderMsgpack byte = 0xc1
+
+// DefaultStorageCodec is the default codec of the storage values.
+const DefaultStorageCodec = StorageCodecJSON
+
+var errInvalidMsgpack = errors.New("invalid msgpack value")
+
+func (c StorageCodec) IsValid() bool {
+	switch c {
+	case StorageCodecJSON, StorageCodecMsgpack:
+		return true
+	default:
+		return false
+	}
+}
+
+// Encode encodes the value by the codec; the unknown codec is regarded as
+// `StorageCodecJSON`.
+func (c StorageCodec) Encode(v interface{}) (b []byte, err error) {
+	if c != StorageCodecMsgpack {
+		return EncodeJSONValue(v)
+	}
+
+	buf := bytes.NewBuffer([]byte{StorageCodecHeaderMsgpack})
+	if err = msgpack.NewEncoder(buf).UseJSONTag(true).Encode(v); err != nil {
+		return
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeStorageValue decodes the value of any `StorageCodec`; the codec is
+// detected by the header byte.
+func DecodeStorageValue(b []byte, v interface{}) (err error) {
+	if len(b) < 1 || b[0] != StorageCodecHeaderMsgpack {
+		return DecodeJSONValue(b, v)
+	}
+
+	r := bytes.NewReader(b[1:])
+	if err = msgpack.NewDecoder(r).UseJSONTag(true).Decode(v); err != nil {
+		return errInvalidMsgpack
+	} else if r.Len() > 0 {
+		return errInvalidMsgpack
+	}
+
+	return
+}
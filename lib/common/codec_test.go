@@ -0,0 +1,104 @@
+package common
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type codecTestValue struct {
+	Name    string            `json:"name"`
+	Amount  Amount            `json:"amount"`
+	Height  uint64            `json:"height"`
+	Delta   int64             `json:"delta"`
+	Ratio   float64           `json:"ratio"`
+	Body    []byte            `json:"body"`
+	Flag    bool              `json:"flag"`
+	Memo    *string           `json:"memo"`
+	Tags    []string          `json:"tags"`
+	Extra   map[string]uint64 `json:"extra"`
+	Private string            `json:"-"`
+}
+
+func TestStorageCodecRoundTrip(t *testing.T) {
+	memo := strings.Repeat("m", 300)
+	values := []codecTestValue{
+		{},
+		{
+			Name:   "showme",
+			Amount: MaximumBalance,
+			Height: math.MaxUint64,
+			Delta:  math.MinInt64,
+			Ratio:  0.25,
+			Body:   []byte(`{"target":"GABC"}`),
+			Flag:   true,
+			Memo:   &memo,
+			Tags:   []string{"a", strings.Repeat("b", 70000)},
+			Extra:  map[string]uint64{"x": 0, "y": 127, "z": 128, "w": 1 << 40},
+		},
+		{Delta: -1, Height: 255, Ratio: -1.5, Tags: []string{}},
+		{Delta: -33, Height: 65536},
+	}
+
+	for _, codec := range []StorageCodec{StorageCodecJSON, StorageCodecMsgpack} {
+		for _, v := range values {
+			v.Private = "hidden"
+
+			b, err := codec.Encode(v)
+			require.NoError(t, err)
+			require.Equal(t, codec == StorageCodecMsgpack, b[0] == StorageCodecHeaderMsgpack)
+
+			var decoded codecTestValue
+			require.NoError(t, DecodeStorageValue(b, &decoded))
+
+			v.Private = ""
+			require.Equal(t, v, decoded, "codec: %s", codec)
+		}
+	}
+}
+
+func TestStorageCodecMsgpackSmaller(t *testing.T) {
+	v := codecTestValue{Name: "showme", Height: 100, Tags: []string{"a", "b"}}
+
+	j, err := StorageCodecJSON.Encode(v)
+	require.NoError(t, err)
+	m, err := StorageCodecMsgpack.Encode(v)
+	require.NoError(t, err)
+
+	require.True(t, len(m) < len(j))
+}
+
+func TestStorageCodecLegacyJSON(t *testing.T) {
+	v := codecTestValue{Name: "showme", Height: 100}
+
+	// the value stored before `StorageCodec` is plain JSON
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	var decoded codecTestValue
+	require.NoError(t, DecodeStorageValue(b, &decoded))
+	require.Equal(t, v, decoded)
+
+	var s string
+	require.NoError(t, DecodeStorageValue([]byte(`"hash"`), &s))
+	require.Equal(t, "hash", s)
+}
+
+func TestStorageCodecInvalidMsgpack(t *testing.T) {
+	b, err := StorageCodecMsgpack.Encode(codecTestValue{Name: "showme"})
+	require.NoError(t, err)
+
+	var decoded codecTestValue
+	require.Equal(t, errInvalidMsgpack, DecodeStorageValue(b[:len(b)-1], &decoded))
+	require.Equal(t, errInvalidMsgpack, DecodeStorageValue(append(b, 0xc0), &decoded))
+	require.Equal(t, errInvalidMsgpack, DecodeStorageValue([]byte{StorageCodecHeaderMsgpack, 0xdd, 0xff, 0xff, 0xff, 0xff}, &decoded))
+}
+
+func TestStorageCodecIsValid(t *testing.T) {
+	require.True(t, StorageCodecJSON.IsValid())
+	require.True(t, StorageCodecMsgpack.IsValid())
+	require.False(t, StorageCodec("gob").IsValid())
+}
@@ -1,7 +1,11 @@
 package common
 
 import (
+	"fmt"
+	"reflect"
 	"time"
+
+	"boscoin.io/sebak/lib/errors"
 )
 
 //
@@ -9,17 +13,181 @@ import (
 // The Config is included in ISAACStateManager and
 // these timeout features are used in ISAAC consensus.
 //
+// Some fields decide the validity of ballot, the proposer or the round, so
+// all the nodes must have the same value of them: MaxOpsPerBlock,
+// OperationWeights, MaxBlockWeight, InflationPolicy, FeeBurnPolicy,
+// FeePolicy, ProposerLivenessThreshold, ExpiredVotesThreshold and
+// OperationFilter.
+//
 type Config struct {
 	TimeoutINIT   time.Duration
 	TimeoutSIGN   time.Duration
 	TimeoutACCEPT time.Duration
 	BlockTime     time.Duration
 
+	// BallotProposedTimeTolerance is the allowed difference between the
+	// local time and the confirmed and proposed time of ballot.
+	BallotProposedTimeTolerance time.Duration
+
 	TxsLimit int
 	OpsLimit int
 
+	// MaxOpsPerBlock is the maximum number of the operations of all the
+	// transactions in a block; `0` means no limit.
+	MaxOpsPerBlock int
+
+	// OperationWeights decides the weight of each operation type and
+	// MaxBlockWeight is the maximum sum of the weights of the operations in a
+	// block; `0` means no limit. See `OperationWeights`.
+	OperationWeights OperationWeights
+	MaxBlockWeight   uint64
+
+	// MaxOperationBodySize is the maximum size of the serialized operation
+	// body in bytes.
+	MaxOperationBodySize int
+
+	// MaxTransactionSize is the maximum size of the serialized transaction in
+	// bytes.
+	MaxTransactionSize int
+
+	// MaxMessageSize is the maximum size of the inbound message in bytes; the
+	// larger message is rejected before it is read and deserialized. `0`
+	// means no limit.
+	MaxMessageSize int
+
+	// MaxAccountDataNameSize and MaxAccountDataValueSize are the maximum
+	// sizes of the name and the value of account data in bytes.
+	MaxAccountDataNameSize  int
+	MaxAccountDataValueSize int
+
 	RateLimitRuleAPI  RateLimitRule
 	RateLimitRuleNode RateLimitRule
+
+	// InflationSchedule decides the inflation ratio by block height.
+	InflationSchedule InflationSchedule
+
+	// InflationPolicy caps the supply by the inflation; see
+	// `InflationPolicy`.
+	InflationPolicy InflationPolicy
+
+	// FeeBurnPolicy burns the part of the collected fee; see
+	// `FeeBurnPolicy`.
+	FeeBurnPolicy FeeBurnPolicy
+
+	// HealthStaleWindow is the duration to regard the consensus as stalled
+	// if no block is confirmed within it.
+	HealthStaleWindow time.Duration
+
+	// IdempotencyWindow is the duration to remember the idempotency key of
+	// the transaction submission; `0` disables the idempotency key.
+	IdempotencyWindow time.Duration
+
+	// MaxTransactionsInBatch is the maximum number of transactions in the
+	// batch submission.
+	MaxTransactionsInBatch int
+
+	// MinFeeBump is the minimum increment of fee to replace the transaction
+	// of same source and sequenceID in the transaction pool.
+	MinFeeBump Amount
+
+	// RetainedBlocks is the number of the recent blocks, which keep their
+	// `BlockTransaction`s and `BlockOperation`s; the older ones are pruned
+	// and only the block headers and the account states remain. `0` means
+	// the full node, which keeps everything.
+	RetainedBlocks uint64
+
+	// ProposerLivenessThreshold is the number of the consecutive EXPs of the
+	// proposer to skip it in the proposer selection; `0` disables it.
+	ProposerLivenessThreshold uint64
+
+	// FeePolicy decides the base fee of each operation type; see
+	// `FeePolicy`.
+	FeePolicy FeePolicy
+
+	// TransactionSelectionPolicy decides which transactions of the pool the
+	// proposer includes in the ballot first; see
+	// `TransactionSelectionPolicy`.
+	TransactionSelectionPolicy TransactionSelectionPolicy
+
+	// AccountCheckpointInterval is the number of the blocks between the
+	// account-state checkpoints; see `block.BlockAccountCheckpoint`. `0`
+	// disables the checkpoints.
+	AccountCheckpointInterval uint64
+
+	// ExpiredVotesThreshold is the number of EXP votes of SIGN or ACCEPT,
+	// which finishes the voting as EXP without waiting for the rest, so the
+	// round is increased before the timeout; `0` disables it and the voting
+	// finishes as EXP only when YES and NO can not reach the threshold. It
+	// is raised to `validators - threshold + 1`, below which the ballot can
+	// still be agreed.
+	ExpiredVotesThreshold uint64
+
+	// MaxClockSkew is the allowed difference between the clocks of the local
+	// node and the validators, which is checked when connecting them. The
+	// validator over it is reported as skewed, because the block time buffer
+	// depends on the synced clocks; `0` disables it.
+	MaxClockSkew time.Duration
+
+	// ProposerJitter is the maximum jitter, which the proposer waits in
+	// addition to the block time buffer, so the proposers do not send the
+	// ballots at the same moment; `0` disables it. The jitter is derived from
+	// the block hash and the address of proposer, so every node expects the
+	// same jitter of the proposer.
+	ProposerJitter time.Duration
+
+	// SyncOnCommit syncs the confirmed block to the disk before moving to the
+	// next height, so the confirmed block survives the crash.
+	SyncOnCommit bool
+
+	// WriteBufferBlocks is the number of the blocks, whose `BlockOperation`s
+	// are buffered and written at once; `0` or `1` writes them by every
+	// block. WriteBufferInterval is the maximum duration to keep the buffered
+	// `BlockOperation`s. The `BlockOperation`s are made from the confirmed
+	// blocks, so the buffered ones lost by the crash are restored at startup.
+	WriteBufferBlocks   uint64
+	WriteBufferInterval time.Duration
+
+	// GenesisTime overrides the timestamp of the genesis block as the anchor
+	// of the average block time, which adjusts the block time buffer; on the
+	// imported or migrated chain, the timestamp of the genesis block may be a
+	// placeholder. The zero time uses the genesis block.
+	GenesisTime time.Time
+
+	// StorageCodec decides the encoding of the new `BlockOperation`s in the
+	// storage; see `StorageCodec`. The stored ones of any codec are still
+	// readable, so it can be changed without migration.
+	StorageCodec StorageCodec
+
+	// MaxProposalFailures is the number of the consecutive failures of
+	// proposing ballot, after which the node halts the consensus instead of
+	// failing to propose silently; `0` never halts. The successful proposal
+	// resets the count.
+	MaxProposalFailures uint64
+
+	// ProposalDeadline is the deadline of making the proposal ballot at each
+	// height and round, which starts after the block time buffer. If the
+	// ballot is not made in it, like by the slow storage, the node abandons
+	// proposing and votes EXP without waiting for `TimeoutINIT`; it is
+	// counted as the failure of `MaxProposalFailures`. `0` disables it.
+	ProposalDeadline time.Duration
+
+	// OperationFilter decides the operation types, which are accepted in the
+	// transaction; see `OperationFilter`.
+	OperationFilter OperationFilter
+
+	// FinalityConfirmations is the number of the following blocks, after
+	// which the block is treated as final by the integrators; when the new
+	// block is stored, the block of `FinalityConfirmations` before is notified
+	// as final. `0` notifies the new block itself.
+	FinalityConfirmations uint64
+
+	// ReconnectPolicy decides the interval and the retries to connect the
+	// validators; see `ReconnectPolicy`.
+	ReconnectPolicy ReconnectPolicy
+
+	// NetworkParams is verified against the genesis block at startup; see
+	// `NetworkParams`.
+	NetworkParams NetworkParams
 }
 
 func NewConfig() Config {
@@ -29,11 +197,268 @@ func NewConfig() Config {
 	p.TimeoutSIGN = 2 * time.Second
 	p.TimeoutACCEPT = 2 * time.Second
 	p.BlockTime = 5 * time.Second
+	p.BallotProposedTimeTolerance = BallotConfirmedTimeAllowDuration
 
 	p.TxsLimit = 1000
 	p.OpsLimit = 1000
+	p.MaxOpsPerBlock = DefaultMaxOpsPerBlock
+	p.OperationWeights = DefaultOperationWeights
+	p.MaxBlockWeight = DefaultMaxBlockWeight
+	p.MaxOperationBodySize = DefaultMaxOperationBodySize
+	p.MaxTransactionSize = DefaultMaxTransactionSize
+	p.MaxMessageSize = DefaultMaxMessageSize
+	p.MaxAccountDataNameSize = DefaultMaxAccountDataNameSize
+	p.MaxAccountDataValueSize = DefaultMaxAccountDataValueSize
 	p.RateLimitRuleAPI = NewRateLimitRule(RateLimitAPI)
 	p.RateLimitRuleNode = NewRateLimitRule(RateLimitNode)
+	p.InflationSchedule = DefaultInflationSchedule
+	p.InflationPolicy = DefaultInflationPolicy
+	p.FeeBurnPolicy = DefaultFeeBurnPolicy
+	p.HealthStaleWindow = DefaultHealthStaleWindow
+	p.IdempotencyWindow = DefaultIdempotencyWindow
+	p.MaxTransactionsInBatch = DefaultMaxTransactionsInBatch
+	p.MinFeeBump = DefaultMinFeeBump
+	p.FeePolicy = DefaultFeePolicy
+	p.TransactionSelectionPolicy = DefaultTransactionSelectionPolicy
+	p.RetainedBlocks = DefaultRetainedBlocks
+	p.ProposerLivenessThreshold = DefaultProposerLivenessThreshold
+	p.AccountCheckpointInterval = DefaultAccountCheckpointInterval
+	p.ExpiredVotesThreshold = DefaultExpiredVotesThreshold
+	p.MaxClockSkew = DefaultMaxClockSkew
+	p.ProposerJitter = DefaultProposerJitter
+	p.SyncOnCommit = DefaultSyncOnCommit
+	p.WriteBufferBlocks = DefaultWriteBufferBlocks
+	p.WriteBufferInterval = DefaultWriteBufferInterval
+	p.StorageCodec = DefaultStorageCodec
+	p.MaxProposalFailures = DefaultMaxProposalFailures
+	p.ProposalDeadline = DefaultProposalDeadline
+	p.OperationFilter = DefaultOperationFilter
+	p.FinalityConfirmations = DefaultFinalityConfirmations
+	p.ReconnectPolicy = DefaultReconnectPolicy
 
 	return p
 }
+
+// reloadableConfigFields are the fields of `Config`, which can be changed by
+// `Config.Reload()` without restarting the node; the timeouts, the fees and
+// the limits of transaction.
+var reloadableConfigFields = map[string]bool{
+	"TimeoutINIT":             true,
+	"TimeoutSIGN":             true,
+	"TimeoutACCEPT":           true,
+	"BlockTime":               true,
+	"OpsLimit":                true,
+	"MaxOperationBodySize":    true,
+	"MaxTransactionSize":      true,
+	"MaxAccountDataNameSize":  true,
+	"MaxAccountDataValueSize": true,
+	"MinFeeBump":              true,
+	"ProposalDeadline":        true,
+	"FeePolicy":               true,
+}
+
+// Reload validates `newConf` and returns it as the config to replace the
+// running one. Only the fields of `reloadableConfigFields` can be changed;
+// the others, like `NetworkParams` and `GenesisTime`, are decided at startup,
+// so the change of them is rejected with `errors.InvalidConfig`.
+func (c Config) Reload(newConf Config) (reloaded Config, warnings []string, err error) {
+	if warnings, err = newConf.Validate(); err != nil {
+		return
+	}
+
+	current := reflect.ValueOf(c)
+	next := reflect.ValueOf(newConf)
+	for i := 0; i < current.NumField(); i++ {
+		name := current.Type().Field(i).Name
+		if reloadableConfigFields[name] {
+			continue
+		}
+
+		if !reflect.DeepEqual(current.Field(i).Interface(), next.Field(i).Interface()) {
+			err = errors.InvalidConfig.Clone().
+				SetData("error", fmt.Sprintf("%s can not be reloaded", name)).
+				SetField(name)
+			return
+		}
+	}
+
+	reloaded = newConf
+
+	return
+}
+
+// Validate checks the fields of config before the node starts or the config
+// is reloaded, like the timeouts of ISAAC consensus, the fee and inflation
+// policies and the limits; the invalid field is rejected with
+// `errors.InvalidConfig`, which has the name of field. The value which works
+// but is likely a mistake is returned as warning, like the sum of the phase
+// timeouts shorter than `BlockTime`, which expires the round before the block
+// time.
+func (c Config) Validate() (warnings []string, err error) {
+	timeouts := []struct {
+		name    string
+		timeout time.Duration
+	}{
+		{"TimeoutINIT", c.TimeoutINIT},
+		{"TimeoutSIGN", c.TimeoutSIGN},
+		{"TimeoutACCEPT", c.TimeoutACCEPT},
+		{"BlockTime", c.BlockTime},
+	}
+
+	for _, t := range timeouts {
+		if t.timeout <= 0 {
+			err = errors.InvalidConfig.Clone().
+				SetData("error", fmt.Sprintf("%s must be positive: %v", t.name, t.timeout)).
+				SetField(t.name)
+			return
+		}
+	}
+
+	// the collected fee of block can not be lower than `BaseFee` per
+	// transaction; see `operation.CollectTxFee`.
+	for operationType, fee := range c.FeePolicy {
+		if fee < BaseFee {
+			err = errors.InvalidConfig.Clone().
+				SetData("error", fmt.Sprintf("fee of %s must not be lower than BaseFee, %v: %v", operationType, BaseFee, fee)).
+				SetField("FeePolicy")
+			return
+		}
+	}
+
+	for operationType, weight := range c.OperationWeights {
+		if weight < 1 {
+			err = errors.InvalidConfig.Clone().
+				SetData("error", fmt.Sprintf("weight of %s must be positive", operationType)).
+				SetField("OperationWeights")
+			return
+		}
+	}
+
+	if c.InflationPolicy.MaxSupply > MaximumBalance {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("MaxSupply must not be over MaximumBalance, %v: %v", MaximumBalance, c.InflationPolicy.MaxSupply)).
+			SetField("InflationPolicy")
+		return
+	}
+
+	if c.FeeBurnPolicy.Ratio > FeeBurnRatioBase {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("ratio of FeeBurnPolicy must not be over %d: %d", FeeBurnRatioBase, c.FeeBurnPolicy.Ratio)).
+			SetField("FeeBurnPolicy")
+		return
+	}
+
+	if r := c.ReconnectPolicy; r.MinInterval <= 0 || r.MaxInterval < r.MinInterval || r.Jitter < 0 || r.Jitter >= 1 {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("invalid reconnect policy: %+v", r)).
+			SetField("ReconnectPolicy")
+		return
+	}
+
+	if c.ProposerJitter < 0 {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("ProposerJitter must not be negative: %v", c.ProposerJitter)).
+			SetField("ProposerJitter")
+		return
+	}
+
+	if c.IdempotencyWindow < 0 {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("IdempotencyWindow must not be negative: %v", c.IdempotencyWindow)).
+			SetField("IdempotencyWindow")
+		return
+	}
+
+	if c.MaxTransactionsInBatch < 1 {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("MaxTransactionsInBatch must be positive: %d", c.MaxTransactionsInBatch)).
+			SetField("MaxTransactionsInBatch")
+		return
+	}
+
+	if c.ProposalDeadline < 0 {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("ProposalDeadline must not be negative: %v", c.ProposalDeadline)).
+			SetField("ProposalDeadline")
+		return
+	}
+
+	if c.MaxMessageSize < 0 || (c.MaxMessageSize > 0 && c.MaxMessageSize < c.MaxTransactionSize) {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("MaxMessageSize must not be smaller than MaxTransactionSize, %d: %d", c.MaxTransactionSize, c.MaxMessageSize)).
+			SetField("MaxMessageSize")
+		return
+	}
+
+	if c.WriteBufferBlocks > 1 && c.WriteBufferInterval <= 0 {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("WriteBufferInterval must be positive with WriteBufferBlocks: %v", c.WriteBufferInterval)).
+			SetField("WriteBufferInterval")
+		return
+	}
+
+	if c.GenesisTime.After(time.Now()) {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("GenesisTime must not be in the future: %v", c.GenesisTime)).
+			SetField("GenesisTime")
+		return
+	}
+
+	if !c.TransactionSelectionPolicy.IsValid() {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("unknown transaction selection policy: %q", c.TransactionSelectionPolicy)).
+			SetField("TransactionSelectionPolicy")
+		return
+	}
+
+	if !c.StorageCodec.IsValid() {
+		err = errors.InvalidConfig.Clone().
+			SetData("error", fmt.Sprintf("unknown storage codec: %q", c.StorageCodec)).
+			SetField("StorageCodec")
+		return
+	}
+
+	for _, operationType := range c.OperationFilter.Denied {
+		if _, found := InStringArray(c.OperationFilter.Allowed, operationType); found {
+			err = errors.InvalidConfig.Clone().
+				SetData("error", fmt.Sprintf("operation type is both allowed and denied: %q", operationType)).
+				SetField("OperationFilter")
+			return
+		}
+	}
+
+	if c.ProposerJitter >= c.TimeoutINIT {
+		warnings = append(
+			warnings,
+			fmt.Sprintf(
+				"ProposerJitter, %v is not shorter than TimeoutINIT, %v; the proposer may miss the round",
+				c.ProposerJitter,
+				c.TimeoutINIT,
+			),
+		)
+	}
+
+	if c.ProposalDeadline > c.TimeoutINIT {
+		warnings = append(
+			warnings,
+			fmt.Sprintf(
+				"ProposalDeadline, %v is longer than TimeoutINIT, %v; the other nodes may expire the round before it",
+				c.ProposalDeadline,
+				c.TimeoutINIT,
+			),
+		)
+	}
+
+	if sum := c.TimeoutINIT + c.TimeoutSIGN + c.TimeoutACCEPT; sum < c.BlockTime {
+		warnings = append(
+			warnings,
+			fmt.Sprintf(
+				"sum of TimeoutINIT, TimeoutSIGN and TimeoutACCEPT, %v is shorter than BlockTime, %v; the round may expire prematurely",
+				sum,
+				c.BlockTime,
+			),
+		)
+	}
+
+	return
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/errors"
 )
 
 //	TestConfigDefault tests the default timeout values.
@@ -17,9 +19,43 @@ func TestConfigDefault(t *testing.T) {
 	require.Equal(t, 2*time.Second, n.TimeoutSIGN)
 	require.Equal(t, 2*time.Second, n.TimeoutACCEPT)
 	require.Equal(t, 5*time.Second, n.BlockTime)
+	require.Equal(t, BallotConfirmedTimeAllowDuration, n.BallotProposedTimeTolerance)
+	require.Equal(t, DefaultInflationSchedule, n.InflationSchedule)
+	require.Equal(t, DefaultInflationPolicy, n.InflationPolicy)
+	require.Equal(t, DefaultFeeBurnPolicy, n.FeeBurnPolicy)
+	require.Equal(t, DefaultMaxOperationBodySize, n.MaxOperationBodySize)
+	require.Equal(t, DefaultMaxTransactionSize, n.MaxTransactionSize)
+	require.Equal(t, DefaultMaxMessageSize, n.MaxMessageSize)
+	require.Equal(t, DefaultMaxAccountDataNameSize, n.MaxAccountDataNameSize)
+	require.Equal(t, DefaultMaxAccountDataValueSize, n.MaxAccountDataValueSize)
+	require.Equal(t, DefaultHealthStaleWindow, n.HealthStaleWindow)
+	require.Equal(t, DefaultIdempotencyWindow, n.IdempotencyWindow)
+	require.Equal(t, DefaultMaxTransactionsInBatch, n.MaxTransactionsInBatch)
+	require.Equal(t, DefaultMinFeeBump, n.MinFeeBump)
+	require.Equal(t, DefaultRetainedBlocks, n.RetainedBlocks)
+	require.Equal(t, DefaultProposerLivenessThreshold, n.ProposerLivenessThreshold)
+	require.Equal(t, DefaultAccountCheckpointInterval, n.AccountCheckpointInterval)
+	require.Equal(t, DefaultFeePolicy, n.FeePolicy)
+	require.Equal(t, DefaultTransactionSelectionPolicy, n.TransactionSelectionPolicy)
 
 	require.Equal(t, 1000, n.TxsLimit)
 	require.Equal(t, 1000, n.OpsLimit)
+	require.Equal(t, DefaultMaxOpsPerBlock, n.MaxOpsPerBlock)
+	require.Equal(t, DefaultOperationWeights, n.OperationWeights)
+	require.Equal(t, DefaultMaxBlockWeight, n.MaxBlockWeight)
+	require.Equal(t, DefaultReconnectPolicy, n.ReconnectPolicy)
+	require.Equal(t, DefaultExpiredVotesThreshold, n.ExpiredVotesThreshold)
+	require.Equal(t, DefaultMaxClockSkew, n.MaxClockSkew)
+	require.Equal(t, DefaultProposerJitter, n.ProposerJitter)
+	require.Equal(t, DefaultSyncOnCommit, n.SyncOnCommit)
+	require.Equal(t, DefaultWriteBufferBlocks, n.WriteBufferBlocks)
+	require.Equal(t, DefaultWriteBufferInterval, n.WriteBufferInterval)
+	require.True(t, n.GenesisTime.IsZero())
+	require.Equal(t, DefaultStorageCodec, n.StorageCodec)
+	require.Equal(t, DefaultMaxProposalFailures, n.MaxProposalFailures)
+	require.Equal(t, DefaultProposalDeadline, n.ProposalDeadline)
+	require.Equal(t, DefaultOperationFilter, n.OperationFilter)
+	require.Equal(t, DefaultFinalityConfirmations, n.FinalityConfirmations)
 }
 
 //	TestConfigSetAndGet tests setting timeout fields and checking.
@@ -41,3 +77,143 @@ func TestConfigSetAndGet(t *testing.T) {
 	require.Equal(t, 500, n.TxsLimit)
 	require.Equal(t, 200, n.OpsLimit)
 }
+
+//	TestConfigValidate tests the validation of timeout fields.
+func TestConfigValidate(t *testing.T) {
+	{ // default
+		warnings, err := NewConfig().Validate()
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+	}
+
+	invalids := map[string]func(*Config){
+		"TimeoutINIT":   func(c *Config) { c.TimeoutINIT = 0 },
+		"TimeoutSIGN":   func(c *Config) { c.TimeoutSIGN = -1 * time.Second },
+		"TimeoutACCEPT": func(c *Config) { c.TimeoutACCEPT = 0 },
+		"BlockTime":     func(c *Config) { c.BlockTime = -1 },
+
+		"TransactionSelectionPolicy": func(c *Config) { c.TransactionSelectionPolicy = "random" },
+		"OperationWeights":           func(c *Config) { c.OperationWeights = OperationWeights{"payment": 0} },
+		"ReconnectPolicy":            func(c *Config) { c.ReconnectPolicy.MaxInterval = c.ReconnectPolicy.MinInterval - 1 },
+		"ProposerJitter":             func(c *Config) { c.ProposerJitter = -1 },
+		"InflationPolicy":            func(c *Config) { c.InflationPolicy.MaxSupply = MaximumBalance + 1 },
+		"FeeBurnPolicy":              func(c *Config) { c.FeeBurnPolicy.Ratio = FeeBurnRatioBase + 1 },
+		"ProposalDeadline":           func(c *Config) { c.ProposalDeadline = -1 },
+		"IdempotencyWindow":          func(c *Config) { c.IdempotencyWindow = -1 },
+		"MaxTransactionsInBatch":     func(c *Config) { c.MaxTransactionsInBatch = 0 },
+		"GenesisTime":                func(c *Config) { c.GenesisTime = time.Now().Add(time.Hour) },
+		"StorageCodec":               func(c *Config) { c.StorageCodec = "gob" },
+		"MaxMessageSize":             func(c *Config) { c.MaxMessageSize = c.MaxTransactionSize - 1 },
+		"OperationFilter": func(c *Config) {
+			c.OperationFilter = OperationFilter{Allowed: []string{"payment"}, Denied: []string{"payment"}}
+		},
+		"WriteBufferInterval": func(c *Config) {
+			c.WriteBufferBlocks = 10
+			c.WriteBufferInterval = 0
+		},
+	}
+
+	for field, f := range invalids {
+		c := NewConfig()
+		f(&c)
+
+		_, err := c.Validate()
+		require.Error(t, err, field)
+		require.Equal(t, errors.InvalidConfig.Code, err.(*errors.Error).Code, field)
+		require.Equal(t, field, err.(*errors.Error).Field(), field)
+	}
+
+	{ // the fee of operation is lower than `BaseFee`
+		c := NewConfig()
+		c.FeePolicy = FeePolicy{"payment": BaseFee - 1}
+
+		_, err := c.Validate()
+		require.Error(t, err)
+		require.Equal(t, errors.InvalidConfig.Code, err.(*errors.Error).Code)
+		require.Equal(t, "FeePolicy", err.(*errors.Error).Field())
+
+		c.FeePolicy = FeePolicy{"payment": BaseFee, "create-account": BaseFee * 10}
+		_, err = c.Validate()
+		require.NoError(t, err)
+	}
+
+	{ // the sum of phase timeouts is shorter than block time
+		c := NewConfig()
+		c.TimeoutINIT = 1 * time.Second
+		c.TimeoutSIGN = 1 * time.Second
+		c.TimeoutACCEPT = 1 * time.Second
+		c.BlockTime = 5 * time.Second
+		c.ProposalDeadline = c.TimeoutINIT
+
+		warnings, err := c.Validate()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(warnings))
+	}
+
+	{ // the proposer jitter is not shorter than the timeout of INIT
+		c := NewConfig()
+		c.ProposerJitter = c.TimeoutINIT
+
+		warnings, err := c.Validate()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(warnings))
+	}
+
+	{ // the proposal deadline is longer than the timeout of INIT
+		c := NewConfig()
+		c.ProposalDeadline = c.TimeoutINIT + time.Second
+
+		warnings, err := c.Validate()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(warnings))
+	}
+
+	{ // same with block time
+		c := NewConfig()
+		c.BlockTime = c.TimeoutINIT + c.TimeoutSIGN + c.TimeoutACCEPT
+
+		warnings, err := c.Validate()
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+	}
+}
+
+func TestConfigReload(t *testing.T) {
+	c := NewConfig()
+
+	{ // timeouts, fees and limits
+		newConf := NewConfig()
+		newConf.TimeoutINIT = 3 * time.Second
+		newConf.OpsLimit = 10
+		newConf.FeePolicy = FeePolicy{"payment": BaseFee * 2}
+
+		reloaded, _, err := c.Reload(newConf)
+		require.NoError(t, err)
+		require.Equal(t, newConf, reloaded)
+	}
+
+	{ // invalid
+		newConf := NewConfig()
+		newConf.TimeoutINIT = 0
+
+		_, _, err := c.Reload(newConf)
+		require.Equal(t, "TimeoutINIT", err.(*errors.Error).Field())
+	}
+
+	immutables := map[string]func(*Config){
+		"NetworkParams":  func(c *Config) { c.NetworkParams.InitialBalance = 1 },
+		"GenesisTime":    func(c *Config) { c.GenesisTime = time.Now().Add(-time.Hour) },
+		"StorageCodec":   func(c *Config) { c.StorageCodec = StorageCodecMsgpack },
+		"MaxOpsPerBlock": func(c *Config) { c.MaxOpsPerBlock = 1 },
+	}
+
+	for field, f := range immutables {
+		newConf := NewConfig()
+		f(&newConf)
+
+		_, _, err := c.Reload(newConf)
+		require.Error(t, err, field)
+		require.Equal(t, errors.InvalidConfig.Code, err.(*errors.Error).Code, field)
+		require.Equal(t, field, err.(*errors.Error).Field(), field)
+	}
+}
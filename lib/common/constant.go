@@ -11,10 +11,19 @@ const (
 	// transaction will fail validation.
 	BaseFee Amount = 10000
 
+	// DefaultMinFeeBump is the default minimum increment of fee to replace the
+	// transaction in the pool; see `Config.MinFeeBump`.
+	DefaultMinFeeBump Amount = BaseFee
+
 	// BaseReserve is minimum amount of balance for new account. By default, it
 	// is `0.1` BOS.
 	BaseReserve Amount = 1000000
 
+	// EntryReserve is the balance, which the account must keep for each
+	// account data and signer; see `block.MinimumBalance()`. By default, it
+	// is `0.01` BOS.
+	EntryReserve Amount = 100000
+
 	// GenesisBlockHeight set the block height of genesis block
 	GenesisBlockHeight uint64 = 1
 
@@ -30,6 +39,118 @@ const (
 
 	// BlockHeightEndOfInflation sets the block height of inflation end.
 	BlockHeightEndOfInflation uint64 = 36000000
+
+	// DefaultMaxOperationBodySize is the default maximum size of the serialized
+	// operation body in bytes; see `Config.MaxOperationBodySize`. It is large
+	// enough for the contract of `CongressVoting`.
+	DefaultMaxOperationBodySize int = 16 * 1024
+
+	// DefaultMaxTransactionSize is the default maximum size of the serialized
+	// transaction in bytes; see `Config.MaxTransactionSize`.
+	DefaultMaxTransactionSize int = 1024 * 1024
+
+	// DefaultMaxMessageSize is the default maximum size of the inbound
+	// message in bytes; see `Config.MaxMessageSize`. It is generous for the
+	// ballot, which has the hashes of the transactions and the proposer
+	// transaction, and for the transaction of `DefaultMaxTransactionSize`.
+	DefaultMaxMessageSize int = 4 * DefaultMaxTransactionSize
+
+	// DefaultMaxAccountDataNameSize and DefaultMaxAccountDataValueSize are the
+	// default maximum sizes of the name and the value of account data in
+	// bytes; see `operation.ManageData`.
+	DefaultMaxAccountDataNameSize  int = 64
+	DefaultMaxAccountDataValueSize int = 64
+
+	// DefaultHealthStaleWindow is the default duration to regard the
+	// consensus as stalled if no block is confirmed; see
+	// `Config.HealthStaleWindow`.
+	DefaultHealthStaleWindow time.Duration = 1 * time.Minute
+
+	// DefaultIdempotencyWindow is the default duration to remember the
+	// idempotency key of the transaction submission; see
+	// `Config.IdempotencyWindow`.
+	DefaultIdempotencyWindow time.Duration = 10 * time.Minute
+
+	// DefaultMaxTransactionsInBatch is the default maximum number of
+	// transactions in the batch submission; see
+	// `Config.MaxTransactionsInBatch`.
+	DefaultMaxTransactionsInBatch int = 100
+
+	// IdempotencyKeyLimit is the maximum number of the remembered idempotency
+	// keys of the transaction submission.
+	IdempotencyKeyLimit int = 10000
+
+	// BallotSignatureCacheLimit is the maximum number of the cached signature
+	// verifications of ballots; see `ballot.SignatureCache`.
+	BallotSignatureCacheLimit int = 10000
+
+	// BallotSeenSetLimit is the maximum number of the hashes of received
+	// ballots; see `ballot.SeenSet`.
+	BallotSeenSetLimit int = 10000
+
+	// DefaultRetainedBlocks is the default number of the recent blocks, which
+	// keep their transactions and operations; `0` keeps all the blocks. See
+	// `Config.RetainedBlocks`.
+	DefaultRetainedBlocks uint64 = 0
+
+	// DefaultProposerLivenessThreshold is the default number of the
+	// consecutive EXPs to skip the proposer; `0` disables it. See
+	// `Config.ProposerLivenessThreshold`.
+	DefaultProposerLivenessThreshold uint64 = 0
+
+	// DefaultAccountCheckpointInterval is the default number of the blocks
+	// between the account-state checkpoints; see
+	// `Config.AccountCheckpointInterval`.
+	DefaultAccountCheckpointInterval uint64 = 1000
+
+	// DefaultMaxOpsPerBlock is the default maximum number of the operations in
+	// a block; see `Config.MaxOpsPerBlock`.
+	DefaultMaxOpsPerBlock int = 10000
+
+	// DefaultOperationWeight is the weight of the operation type, which is
+	// not in `Config.OperationWeights`.
+	DefaultOperationWeight uint64 = 1
+
+	// DefaultMaxBlockWeight is the default maximum weight of a block; `0`
+	// means no limit. See `Config.MaxBlockWeight`.
+	DefaultMaxBlockWeight uint64 = 0
+
+	// DefaultExpiredVotesThreshold is the default number of EXP votes to
+	// finish the voting as EXP; `0` disables it. See
+	// `Config.ExpiredVotesThreshold`.
+	DefaultExpiredVotesThreshold uint64 = 0
+
+	// DefaultMaxClockSkew is the default allowed difference between the clocks
+	// of the local node and the validators; see `Config.MaxClockSkew`.
+	DefaultMaxClockSkew time.Duration = 10 * time.Second
+
+	// DefaultProposerJitter is the default maximum jitter of the proposer
+	// before proposing; `0` disables it. See `Config.ProposerJitter`.
+	DefaultProposerJitter time.Duration = 0
+
+	// DefaultSyncOnCommit syncs the confirmed block to the disk by default;
+	// see `Config.SyncOnCommit`.
+	DefaultSyncOnCommit bool = true
+
+	// DefaultWriteBufferBlocks and DefaultWriteBufferInterval are the default
+	// write buffer of `BlockOperation`s; by default, they are written by every
+	// block. See `Config.WriteBufferBlocks`.
+	DefaultWriteBufferBlocks   uint64        = 0
+	DefaultWriteBufferInterval time.Duration = 10 * time.Second
+
+	// DefaultMaxProposalFailures is the default number of the consecutive
+	// failures of proposing ballot to halt the consensus; by default it never
+	// halts, the operators opt in by `Config.MaxProposalFailures`.
+	DefaultMaxProposalFailures uint64 = 0
+
+	// DefaultProposalDeadline is the default deadline of proposing ballot; it
+	// is same with the default `Config.TimeoutINIT`. See
+	// `Config.ProposalDeadline`.
+	DefaultProposalDeadline time.Duration = 2 * time.Second
+
+	// DefaultFinalityConfirmations is the default number of the following
+	// blocks to treat the block as final; see `Config.FinalityConfirmations`.
+	DefaultFinalityConfirmations uint64 = 0
 )
 
 var (
@@ -40,9 +161,9 @@ var (
 	// 14 (days) * 24 (hours) * 60 (minutes) * 12 (60 seconds / 5 seconds per block on average)
 	UnfreezingPeriod uint64 = 241920
 
-	// BallotConfirmedTimeAllowDuration is the duration time for ballot from
-	// other nodes. If confirmed time of ballot has too late or ahead by
-	// BallotConfirmedTimeAllowDuration, it will be considered not-wellformed.
+	// BallotConfirmedTimeAllowDuration is the default of
+	// `Config.BallotProposedTimeTolerance`. If confirmed time of ballot has too
+	// late or ahead by the tolerance, it will be considered not-wellformed.
 	// For details, `Ballot.IsWellFormed()`
 	BallotConfirmedTimeAllowDuration time.Duration = time.Minute * time.Duration(1)
 
@@ -0,0 +1,19 @@
+package common
+
+// FeePolicy decides the base fee of each operation by the operation type; the
+// operation type, which is not in the policy, pays `BaseFee`. The minimum fee
+// of transaction is the sum of the base fees of its operations. The fee is
+// checked in consensus, so all the nodes must have the same policy.
+type FeePolicy map[string]Amount
+
+// DefaultFeePolicy is the flat fee policy; every operation pays `BaseFee`.
+var DefaultFeePolicy = FeePolicy{}
+
+// Fee returns the base fee of the operation type.
+func (p FeePolicy) Fee(operationType string) Amount {
+	if fee, found := p[operationType]; found {
+		return fee
+	}
+
+	return BaseFee
+}
@@ -0,0 +1,38 @@
+package common
+
+// FeeBurnRatioBase is the denominator of `FeeBurnPolicy.Ratio`; the ratio is
+// in basis points.
+const FeeBurnRatioBase uint64 = 10000
+
+// FeeBurnPolicy splits the collected fee of block by `Ratio` of
+// `FeeBurnRatioBase`; the burned part is not deposited to any account, so it
+// is removed from the supply, and the rest is deposited to the common
+// account. `Ratio` of `0` burns nothing.
+type FeeBurnPolicy struct {
+	Ratio uint64
+}
+
+// DefaultFeeBurnPolicy burns nothing; the whole fee goes to the common
+// account.
+var DefaultFeeBurnPolicy = FeeBurnPolicy{}
+
+// Burned returns the amount to burn of the collected fee; the fraction is
+// rounded down, so the common account gets the rest.
+func (p FeeBurnPolicy) Burned(fee Amount) Amount {
+	if p.Ratio < 1 || fee < 1 {
+		return 0
+	}
+	if p.Ratio >= FeeBurnRatioBase {
+		return fee
+	}
+
+	// split not to overflow
+	q, r := uint64(fee)/FeeBurnRatioBase, uint64(fee)%FeeBurnRatioBase
+	return Amount(q*p.Ratio + r*p.Ratio/FeeBurnRatioBase)
+}
+
+// Split returns the amount to the common account and the amount to burn.
+func (p FeeBurnPolicy) Split(fee Amount) (distributed, burned Amount) {
+	burned = p.Burned(fee)
+	return fee - burned, burned
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeBurnPolicySplit(t *testing.T) {
+	cases := []struct {
+		ratio       uint64
+		fee         Amount
+		distributed Amount
+		burned      Amount
+	}{
+		{0, 30000, 30000, 0},
+		{0, 0, 0, 0},
+		{2500, 30000, 22500, 7500},
+		{3333, 10000, 6667, 3333},
+		{3333, 10001, 6668, 3333}, // the fraction goes to the common account
+		{5000, 1, 1, 0},
+		{FeeBurnRatioBase, 30000, 0, 30000},
+		{9999, 1000000000000000000, 100000000000000, 999900000000000000}, // not overflowed
+	}
+
+	for _, c := range cases {
+		distributed, burned := FeeBurnPolicy{Ratio: c.ratio}.Split(c.fee)
+		require.Equal(t, c.distributed, distributed, "ratio=%d fee=%d", c.ratio, c.fee)
+		require.Equal(t, c.burned, burned, "ratio=%d fee=%d", c.ratio, c.fee)
+		require.Equal(t, c.fee, distributed+burned)
+	}
+
+	require.Equal(t, Amount(0), DefaultFeeBurnPolicy.Burned(30000))
+}
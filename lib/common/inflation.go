@@ -3,6 +3,7 @@ package common
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 
 	"boscoin.io/sebak/lib/errors"
@@ -10,12 +11,139 @@ import (
 
 // CalculateInflation returns the amount of inflation in every block.
 func CalculateInflation(initialBalance Amount) (a Amount, err error) {
+	return calculateInflation(initialBalance, InflationRatio)
+}
+
+func calculateInflation(initialBalance Amount, ratio float64) (a Amount, err error) {
 	if initialBalance > MaximumBalance {
 		err = errors.MaximumBalanceReached
 		return
 	}
 
-	a = Amount(uint64(math.Round(float64(initialBalance) * InflationRatio)))
+	a = Amount(uint64(math.Round(float64(initialBalance) * ratio)))
+	return
+}
+
+// InflationScheduleStep sets the inflation ratio from the block height.
+type InflationScheduleStep struct {
+	Height uint64
+	Ratio  float64
+}
+
+// InflationSchedule decides the inflation ratio by the block height; the
+// step of the highest `Height`, which is not over the block height is
+// applied. Before the first step, there is no inflation.
+type InflationSchedule []InflationScheduleStep
+
+// DefaultInflationSchedule applies `InflationRatio` from the genesis block.
+var DefaultInflationSchedule = InflationSchedule{
+	{Height: 0, Ratio: InflationRatio},
+}
+
+func (s InflationSchedule) step(height uint64) (step InflationScheduleStep, found bool) {
+	for _, st := range s {
+		if st.Height > height {
+			continue
+		}
+		if !found || st.Height >= step.Height {
+			step = st
+			found = true
+		}
+	}
+
+	return
+}
+
+// Ratio returns the inflation ratio at the block height.
+func (s InflationSchedule) Ratio(height uint64) float64 {
+	step, _ := s.step(height)
+	return step.Ratio
+}
+
+// RatioString returns the inflation ratio at the block height as the form of
+// `InflationRatio2String()`.
+func (s InflationSchedule) RatioString(height uint64) string {
+	return InflationRatio2String(s.Ratio(height))
+}
+
+// CalculateInflation returns the amount of inflation at the block height.
+func (s InflationSchedule) CalculateInflation(height uint64, initialBalance Amount) (a Amount, err error) {
+	return calculateInflation(initialBalance, s.Ratio(height))
+}
+
+// Supply returns the supply before the inflation at the height of voting
+// basis; it is `initialBalance` with the inflations of the voting bases from
+// `GenesisBlockHeight` to `height - 1`, because the ballot of a voting basis
+// makes the next block.
+func (s InflationSchedule) Supply(height uint64, initialBalance Amount) (supply Amount, err error) {
+	steps := append(InflationSchedule{}, s...)
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Height < steps[j].Height })
+
+	supply = initialBalance
+	for i, step := range steps {
+		from, to := step.Height, height
+		if from < GenesisBlockHeight {
+			from = GenesisBlockHeight
+		}
+		if i+1 < len(steps) && steps[i+1].Height < to {
+			to = steps[i+1].Height
+		}
+		if from >= to {
+			continue
+		}
+
+		var a Amount
+		if a, err = calculateInflation(initialBalance, step.Ratio); err != nil {
+			return
+		} else if a < 1 {
+			continue
+		}
+		if a, err = a.MultUint64(to - from); err != nil {
+			return
+		}
+		if supply, err = supply.Add(a); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// InflationPolicy decides whether the proposer transaction includes the
+// inflation of `InflationSchedule`. Once the supply reaches `MaxSupply`, the
+// inflation is omitted, that is, its amount is `0`; the inflation, which
+// passes over `MaxSupply`, is reduced to reach it exactly. `MaxSupply` of `0`
+// means no cap.
+type InflationPolicy struct {
+	MaxSupply Amount
+}
+
+// DefaultInflationPolicy has no cap of supply.
+var DefaultInflationPolicy = InflationPolicy{}
+
+// CalculateInflation returns the amount of inflation at the height of voting
+// basis under the policy.
+func (p InflationPolicy) CalculateInflation(schedule InflationSchedule, height uint64, initialBalance Amount) (a Amount, err error) {
+	if a, err = schedule.CalculateInflation(height, initialBalance); err != nil {
+		return
+	} else if p.MaxSupply < 1 || a < 1 {
+		return
+	}
+
+	var supply Amount
+	if supply, err = schedule.Supply(height, initialBalance); err == errors.MaximumBalanceReached {
+		return 0, nil // over any cap
+	} else if err != nil {
+		return
+	}
+
+	if supply >= p.MaxSupply {
+		return 0, nil
+	}
+	if left := p.MaxSupply - supply; a > left {
+		a = left
+	}
+
 	return
 }
 
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestInflationScheduleDefault(t *testing.T) {
+	initialBalance := Amount(5000000000000000)
+
+	expected, err := CalculateInflation(initialBalance)
+	require.NoError(t, err)
+
+	for _, height := range []uint64{0, 1, BlockHeightEndOfInflation} {
+		require.Equal(t, InflationRatioString, DefaultInflationSchedule.RatioString(height))
+
+		a, err := DefaultInflationSchedule.CalculateInflation(height, initialBalance)
+		require.NoError(t, err)
+		require.Equal(t, expected, a)
+	}
+}
+
+func TestInflationScheduleStepDown(t *testing.T) {
+	initialBalance := Amount(5000000000000000)
+	schedule := InflationSchedule{
+		{Height: 0, Ratio: 0.0000001},
+		{Height: 100, Ratio: 0.00000005},
+		{Height: 200, Ratio: 0},
+	}
+
+	cases := []struct {
+		height   uint64
+		expected Amount
+	}{
+		{0, Amount(500000000)},
+		{99, Amount(500000000)},
+		{100, Amount(250000000)},
+		{199, Amount(250000000)},
+		{200, Amount(0)},
+		{201, Amount(0)},
+	}
+
+	for _, c := range cases {
+		a, err := schedule.CalculateInflation(c.height, initialBalance)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, a, "height=%d", c.height)
+	}
+
+	{ // the order of steps does not matter
+		reversed := InflationSchedule{schedule[2], schedule[1], schedule[0]}
+		for _, c := range cases {
+			a, err := reversed.CalculateInflation(c.height, initialBalance)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, a, "height=%d", c.height)
+		}
+	}
+
+	{ // no inflation before the first step
+		s := InflationSchedule{{Height: 10, Ratio: 0.0000001}}
+		a, err := s.CalculateInflation(9, initialBalance)
+		require.NoError(t, err)
+		require.Equal(t, Amount(0), a)
+	}
+
+	{ // over `MaximumBalance`
+		_, err := schedule.CalculateInflation(0, MaximumBalance+1)
+		require.Error(t, err)
+	}
+}
+
+func TestInflationScheduleSupply(t *testing.T) {
+	initialBalance := Amount(5000000000000000)
+	schedule := InflationSchedule{
+		{Height: 200, Ratio: 0},
+		{Height: 0, Ratio: 0.0000001},
+		{Height: 100, Ratio: 0.00000005},
+	}
+
+	cases := []struct {
+		height   uint64
+		expected Amount
+	}{
+		// no inflation is paid before the voting basis of the genesis block
+		{0, initialBalance},
+		{GenesisBlockHeight, initialBalance},
+		{2, initialBalance + 500000000},
+		{100, initialBalance + 500000000*99},
+		{101, initialBalance + 500000000*99 + 250000000},
+		{300, initialBalance + 500000000*99 + 250000000*100},
+	}
+
+	for _, c := range cases {
+		supply, err := schedule.Supply(c.height, initialBalance)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, supply, "height=%d", c.height)
+	}
+
+	{ // over `MaximumBalance`
+		_, err := DefaultInflationSchedule.Supply(BlockHeightEndOfInflation, MaximumBalance/2)
+		require.Equal(t, errors.MaximumBalanceReached, err)
+	}
+}
+
+func TestInflationPolicyMaxSupply(t *testing.T) {
+	initialBalance := Amount(5000000000000000)
+	schedule := InflationSchedule{{Height: 0, Ratio: 0.0000001}}
+	inflation := Amount(500000000)
+
+	{ // no cap
+		a, err := DefaultInflationPolicy.CalculateInflation(schedule, BlockHeightEndOfInflation, initialBalance)
+		require.NoError(t, err)
+		require.Equal(t, inflation, a)
+	}
+
+	// the supply reaches the cap at the voting basis of height 11
+	policy := InflationPolicy{MaxSupply: initialBalance + inflation*10}
+
+	cases := []struct {
+		height   uint64
+		expected Amount
+	}{
+		{10, inflation},
+		{11, 0},
+		{12, 0},
+	}
+	for _, c := range cases {
+		a, err := policy.CalculateInflation(schedule, c.height, initialBalance)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, a, "height=%d", c.height)
+	}
+
+	{ // the last inflation is reduced to reach the cap
+		policy := InflationPolicy{MaxSupply: initialBalance + inflation*10 - 1}
+		a, err := policy.CalculateInflation(schedule, 10, initialBalance)
+		require.NoError(t, err)
+		require.Equal(t, inflation-1, a)
+	}
+
+	{ // the supply over `MaximumBalance` is over the cap
+		policy := InflationPolicy{MaxSupply: MaximumBalance}
+		a, err := policy.CalculateInflation(DefaultInflationSchedule, BlockHeightEndOfInflation, MaximumBalance/2)
+		require.NoError(t, err)
+		require.Equal(t, Amount(0), a)
+	}
+}
@@ -0,0 +1,52 @@
+package keypair
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/exp/crypto/derivation"
+	stellar "github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
+)
+
+// DerivationPathFormat is the path of the derived keypair by the index. It
+// follows SEP-0005 of Stellar, so the derived keypairs are same with the
+// Stellar wallets from the same seed.
+const DerivationPathFormat = derivation.StellarAccountPathFormat
+
+// MaxDerivationIndex is the maximum index of `Derive`; ed25519 supports only
+// the hardened keys.
+const MaxDerivationIndex uint32 = derivation.FirstHardenedIndex - 1
+
+// Derive derives the keypair of the given index from the master keypair. The
+// derived keypair is always same for the same master and index, so the
+// multiple accounts can be recovered from the single seed.
+//
+// The raw seed of master is used as the seed of SLIP-0010 and the keypair is
+// derived from the path of `DerivationPathFormat`.
+func Derive(master KP, index uint32) (kp *Full, err error) {
+	if index > MaxDerivationIndex {
+		err = derivation.ErrInvalidPath
+		return
+	}
+
+	full, ok := master.(*Full)
+	if !ok {
+		err = stellar.ErrCannotSign
+		return
+	}
+
+	var rawSeed []byte
+	if rawSeed, err = strkey.Decode(strkey.VersionByteSeed, full.Seed()); err != nil {
+		return
+	}
+
+	var key *derivation.Key
+	if key, err = derivation.DeriveForPath(fmt.Sprintf(DerivationPathFormat, index), rawSeed); err != nil {
+		return
+	}
+
+	var derived [32]byte
+	copy(derived[:], key.Key)
+
+	return stellar.FromRawSeed(derived)
+}
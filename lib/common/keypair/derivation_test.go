@@ -0,0 +1,70 @@
+package keypair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerive(t *testing.T) {
+	// test vectors; the derived keypairs must not be changed
+	vectors := []struct {
+		seed    string
+		index   uint32
+		address string
+		secret  string
+	}{
+		{"sebak-test-seed", 0, "GD6DLGVATIDOCVNOTSSYIZGTU3377CXGADD7VXTGN4TAAPBKXV66ONXK", "SABACEAOICJZLFFL3NOJ3VKRIMGNFPQNK2FMPJ4E7WPBZTTFGWIDOR2Q"},
+		{"sebak-test-seed", 1, "GC4MTQ2N5OKTBNRDBV7LIH5GAOAMHNKVVPAOP23XLXU5WNFHAZXNU37G", "SADMTDSESY6SRIG6G54LIATZKAAK5TRNVDE5JVKCBSJJNXVCLSN6DKG5"},
+		{"sebak-test-seed", 2, "GD4BH22ENAZZULGUAQZCEGDHWJSC6W7UAAVR3B4TTZGQIKQZB7VIHCVM", "SAGZ4LEKSUCNTDNQQF2QCZQ2GBIK7OKOPKUHFOX4LJ7QC4R7OUKSWYYM"},
+		{"sebak-test-seed", MaxDerivationIndex, "GDKDAXUIG6DWBQG24SUM25FKI5BXW4HD6J6OVTGHAAAVKTB35D6UGTAU", "SCWJNKFWKHZD4DSLNCROBOM3R4375UZBRN65LJULC3D36BR3TTHHXNVU"},
+		{"findme", 0, "GBCYKSMMZN2DJDLX5DBUP3M3ZDM7BJQHEZUTDHKH3I63OBBNRDIJTSEQ", "SBTYVPSSAHEZNZCXDBU7H66H6LM5IR23JLRIOKIQ5QAYSCZG4BCZFF7O"},
+		{"findme", 1, "GAAJXTPOH2XEGEMHASMKZNCQAJVSAXBRAHQT3547KKW7M6KIBVO5H7ZQ", "SBDZILMXRHLIFCUXPSGIGLYWYOIHX52SLH37ZUG2PJY6Y43QWUEVHFKP"},
+	}
+
+	for _, v := range vectors {
+		kp, err := Derive(Master(v.seed), v.index)
+		require.NoError(t, err)
+		require.Equal(t, v.address, kp.Address())
+		require.Equal(t, v.secret, kp.Seed())
+
+		// reproducible
+		again, err := Derive(Master(v.seed), v.index)
+		require.NoError(t, err)
+		require.Equal(t, kp.Seed(), again.Seed())
+	}
+}
+
+func TestDeriveSignature(t *testing.T) {
+	networkID := []byte("sebak-test-network")
+
+	kp, err := Derive(Master("sebak-test-seed"), 0)
+	require.NoError(t, err)
+	require.NotEqual(t, Master("sebak-test-seed").Address(), kp.Address())
+
+	signature, err := MakeSignature(kp, networkID, "showme")
+	require.NoError(t, err)
+
+	parsed, err := Parse(kp.Address())
+	require.NoError(t, err)
+	require.NoError(t, parsed.Verify(append(networkID, []byte("showme")...), signature))
+
+	other, err := Derive(Master("sebak-test-seed"), 1)
+	require.NoError(t, err)
+	require.Error(t, other.Verify(append(networkID, []byte("showme")...), signature))
+}
+
+func TestDeriveInvalid(t *testing.T) {
+	{ // over the maximum index
+		_, err := Derive(Master("sebak-test-seed"), MaxDerivationIndex+1)
+		require.Error(t, err)
+	}
+
+	{ // master without seed
+		address, err := Parse(Master("sebak-test-seed").Address())
+		require.NoError(t, err)
+
+		_, err = Derive(address, 0)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,111 @@
+package common
+
+import (
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// MerkleProof is the inclusion proof of `Leaf` in the merkle tree. `Siblings`
+// are the base58 encoded hashes from the bottom to the root, and `Lefts`
+// tells whether the sibling of same index is on the left side.
+type MerkleProof struct {
+	Leaf     string   `json:"leaf"`
+	Siblings []string `json:"siblings"`
+	Lefts    []bool   `json:"lefts"`
+}
+
+func merkleLeafHash(leaf string) []byte {
+	return MakeHash(append([]byte{0x00}, []byte(leaf)...))
+}
+
+func merkleNodeHash(left, right []byte) []byte {
+	b := append([]byte{0x01}, left...)
+	return MakeHash(append(b, right...))
+}
+
+// merkleLevels returns the hashes of every level of the tree from the leaves
+// to the root; the last node of the odd level is promoted to the next level
+// without hashing, so no leaf is duplicated.
+func merkleLevels(leaves []string) (levels [][][]byte) {
+	var level [][]byte
+	for _, leaf := range leaves {
+		level = append(level, merkleLeafHash(leaf))
+	}
+	levels = append(levels, level)
+
+	for len(level) > 1 {
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, merkleNodeHash(level[i], level[i+1]))
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	return
+}
+
+// MakeMerkleRoot returns the base58 encoded merkle root of the leaves; if no
+// leaves, it returns empty string.
+func MakeMerkleRoot(leaves []string) string {
+	if len(leaves) < 1 {
+		return ""
+	}
+
+	levels := merkleLevels(leaves)
+	return base58.Encode(levels[len(levels)-1][0])
+}
+
+// NewMerkleProof makes the inclusion proof of `leaf`; if `leaf` is not in
+// `leaves`, it returns false.
+func NewMerkleProof(leaves []string, leaf string) (proof MerkleProof, found bool) {
+	index := -1
+	for i, l := range leaves {
+		if l == leaf {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return
+	}
+
+	proof = MerkleProof{Leaf: leaf, Siblings: []string{}, Lefts: []bool{}}
+	levels := merkleLevels(leaves)
+	for _, level := range levels[:len(levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) { // if not, it is promoted
+			proof.Siblings = append(proof.Siblings, base58.Encode(level[sibling]))
+			proof.Lefts = append(proof.Lefts, sibling < index)
+		}
+		index /= 2
+	}
+
+	return proof, true
+}
+
+// Verify checks the proof against the base58 encoded merkle root.
+func (p MerkleProof) Verify(root string) bool {
+	if len(root) < 1 || len(p.Siblings) != len(p.Lefts) {
+		return false
+	}
+
+	h := merkleLeafHash(p.Leaf)
+	for i, s := range p.Siblings {
+		sibling := base58.Decode(s)
+		if len(sibling) < 1 {
+			return false
+		}
+
+		if p.Lefts[i] {
+			h = merkleNodeHash(sibling, h)
+		} else {
+			h = merkleNodeHash(h, sibling)
+		}
+	}
+
+	return base58.Encode(h) == root
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleProof(t *testing.T) {
+	require.Equal(t, "", MakeMerkleRoot(nil))
+
+	for n := 1; n < 10; n++ {
+		var leaves []string
+		for i := 0; i < n; i++ {
+			leaves = append(leaves, fmt.Sprintf("leaf-%d", i))
+		}
+		root := MakeMerkleRoot(leaves)
+
+		for _, leaf := range leaves {
+			proof, found := NewMerkleProof(leaves, leaf)
+			require.True(t, found)
+			require.True(t, proof.Verify(root), "leaves=%d leaf=%s", n, leaf)
+
+			// the other root
+			require.False(t, proof.Verify(MakeMerkleRoot(append(leaves, "showme"))))
+		}
+
+		// non-member
+		_, found := NewMerkleProof(leaves, "showme")
+		require.False(t, found)
+
+		// forged leaf with the proof of the other leaf
+		proof, _ := NewMerkleProof(leaves, leaves[0])
+		proof.Leaf = "showme"
+		require.False(t, proof.Verify(root))
+	}
+
+	{ // the order of leaves matters
+		require.NotEqual(t, MakeMerkleRoot([]string{"a", "b"}), MakeMerkleRoot([]string{"b", "a"}))
+	}
+
+	{ // the odd leaf is not duplicated
+		require.NotEqual(t, MakeMerkleRoot([]string{"a", "b", "c"}), MakeMerkleRoot([]string{"a", "b", "c", "c"}))
+	}
+}
@@ -0,0 +1,18 @@
+package common
+
+// NetworkParams is the parameters, which distinguish the network like
+// mainnet, testnet and devnet. The empty field is loaded from the genesis
+// block at startup; the configured ones must match with the genesis block.
+type NetworkParams struct {
+	// CommonAccount is the address of common account, which receives the
+	// inflation and the collected transaction fees.
+	CommonAccount string
+
+	// InitialBalance is the balance of genesis account; the inflation is
+	// calculated from it.
+	InitialBalance Amount
+}
+
+func (p NetworkParams) IsEmpty() bool {
+	return len(p.CommonAccount) < 1 && p.InitialBalance == 0
+}
@@ -7,4 +7,5 @@ var BlockTransactionObserver = observable.New()
 var BlockTransactionHistoryObserver = observable.New()
 var BlockObserver = observable.New()
 var BlockOperationObserver = observable.New()
+var BlockOperationEffectObserver = observable.New()
 var SyncBlockWaitObserver = observable.New()
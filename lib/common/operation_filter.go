@@ -0,0 +1,43 @@
+package common
+
+import "strings"
+
+// OperationFilter decides the operation types, which are accepted in the
+// transaction; it is the lever to disable the operation types for a while,
+// like the account creation during an incident. If Allowed is not empty, only
+// the operation types in it are accepted, and the operation types in Denied
+// are always rejected. The filter is checked by `IsWellFormed()` of the
+// transactions in ballot also, so all the nodes must have the same filter.
+type OperationFilter struct {
+	Allowed []string
+	Denied  []string
+}
+
+// DefaultOperationFilter accepts every operation type.
+var DefaultOperationFilter = OperationFilter{}
+
+// ParseOperationTypes parses the comma separated operation types; the empty
+// ones are ignored.
+func ParseOperationTypes(s string) (operationTypes []string) {
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); len(t) > 0 {
+			operationTypes = append(operationTypes, t)
+		}
+	}
+
+	return
+}
+
+// IsAllowed returns whether the operation type is accepted.
+func (f OperationFilter) IsAllowed(operationType string) bool {
+	if _, found := InStringArray(f.Denied, operationType); found {
+		return false
+	}
+
+	if len(f.Allowed) < 1 {
+		return true
+	}
+
+	_, found := InStringArray(f.Allowed, operationType)
+	return found
+}
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationFilter(t *testing.T) {
+	require.True(t, DefaultOperationFilter.IsAllowed("create-account"))
+	require.True(t, DefaultOperationFilter.IsAllowed("payment"))
+
+	denied := OperationFilter{Denied: []string{"create-account"}}
+	require.False(t, denied.IsAllowed("create-account"))
+	require.True(t, denied.IsAllowed("payment"))
+
+	allowed := OperationFilter{Allowed: []string{"payment"}}
+	require.False(t, allowed.IsAllowed("create-account"))
+	require.True(t, allowed.IsAllowed("payment"))
+}
+
+func TestParseOperationTypes(t *testing.T) {
+	require.Empty(t, ParseOperationTypes(""))
+	require.Equal(t, []string{"create-account", "payment"}, ParseOperationTypes(" create-account,, payment "))
+}
@@ -4,6 +4,9 @@ const (
 	BlockPrefixHash                       = string(0x00)
 	BlockPrefixConfirmed                  = string(0x01)
 	BlockPrefixHeight                     = string(0x02)
+	BlockPrefixPrunedHeight               = string(0x03)
+	BlockPrefixHeightWatermark            = string(0x04)
+	BlockPrefixLegacyHeight               = string(0x05)
 	BlockTransactionPrefixHash            = string(0x10)
 	BlockTransactionPrefixSource          = string(0x11)
 	BlockTransactionPrefixConfirmed       = string(0x12)
@@ -18,5 +21,15 @@ const (
 	BlockAccountPrefixCreated             = string(0x31)
 	BlockAccountSequenceIDPrefix          = string(0x32)
 	BlockAccountSequenceIDByAddressPrefix = string(0x33)
+	BlockAccountRollupPrefixAddress       = string(0x34)
+	BlockAccountDataPrefixAddress         = string(0x35)
+	BlockAccountCheckpointPrefixHeight    = string(0x36)
+	BlockAccountCheckpointPrefixAccount   = string(0x37)
+	BlockAccountMergePrefixSource         = string(0x38)
 	TransactionPoolPrefix                 = string(0x40)
+	BlockValidatorChangePrefixHeight      = string(0x50)
+	BlockTimeLockPrefixTarget             = string(0x51)
+	GovernanceProposalPrefixHash          = string(0x52)
+	GovernanceProposalPrefixCloseHeight   = string(0x53)
+	GovernanceVotePrefixProposal          = string(0x54)
 )
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ReconnectPolicy decides how often the validator is connected again. While
+// the validator is connected, it is checked by every `MinInterval`; after the
+// failure, the interval is doubled by every consecutive failure up to
+// `MaxInterval`. `Jitter` is the ratio of the random variation of interval, so
+// the nodes do not retry at the same time. After `MaxRetries` consecutive
+// failures, the validator is not retried anymore; `0` retries forever.
+type ReconnectPolicy struct {
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	Jitter      float64
+	MaxRetries  uint64
+}
+
+// DefaultReconnectPolicy checks the connected validator by every second and
+// retries the disconnected one forever.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	MinInterval: 1 * time.Second,
+	MaxInterval: 1 * time.Minute,
+	Jitter:      0.2,
+	MaxRetries:  0,
+}
+
+// Interval returns the interval without jitter after the `failures`
+// consecutive failures.
+func (p ReconnectPolicy) Interval(failures uint64) time.Duration {
+	d := p.MinInterval
+	for i := uint64(0); i < failures; i++ {
+		if d >= p.MaxInterval/2 {
+			return p.MaxInterval
+		}
+		d *= 2
+	}
+
+	return d
+}
+
+// Backoff returns `Interval()` with jitter.
+func (p ReconnectPolicy) Backoff(failures uint64) time.Duration {
+	d := p.Interval(failures)
+	if p.Jitter <= 0 {
+		return d
+	}
+
+	return d + time.Duration(float64(d)*p.Jitter*(rand.Float64()*2-1))
+}
+
+// Exhausted returns `true` if the validator is not retried anymore after the
+// `failures` consecutive failures.
+func (p ReconnectPolicy) Exhausted(failures uint64) bool {
+	return p.MaxRetries > 0 && failures >= p.MaxRetries
+}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconnectPolicyBackoff(t *testing.T) {
+	p := ReconnectPolicy{
+		MinInterval: 1 * time.Second,
+		MaxInterval: 10 * time.Second,
+	}
+
+	{ // doubled by every failure up to `MaxInterval`
+		expected := []time.Duration{1, 2, 4, 8, 10, 10}
+		for failures, d := range expected {
+			require.Equal(t, d*time.Second, p.Interval(uint64(failures)))
+			require.Equal(t, d*time.Second, p.Backoff(uint64(failures)))
+		}
+		require.Equal(t, p.MaxInterval, p.Interval(1000))
+	}
+
+	{ // jitter
+		p.Jitter = 0.5
+		for i := 0; i < 100; i++ {
+			d := p.Backoff(2)
+			require.True(t, d >= 2*time.Second && d <= 6*time.Second, d)
+		}
+	}
+
+	{ // max retries
+		require.False(t, p.Exhausted(1000))
+
+		p.MaxRetries = 3
+		require.False(t, p.Exhausted(2))
+		require.True(t, p.Exhausted(3))
+	}
+}
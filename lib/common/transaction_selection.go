@@ -0,0 +1,30 @@
+package common
+
+// TransactionSelectionPolicy decides the order of the transactions in the
+// pool, which the proposer takes into the new ballot; see
+// `transaction.Pool.SelectTransactions()`.
+type TransactionSelectionPolicy string
+
+const (
+	// TransactionSelectionFIFO selects the transactions in the order of
+	// receipt. The order depends on the network, so the nodes can select the
+	// different transactions from the same pool.
+	TransactionSelectionFIFO TransactionSelectionPolicy = "fifo"
+
+	// TransactionSelectionFeeRate selects the transaction of higher fee per
+	// operation first; the same fee rate is ordered by sequence ID and then
+	// by hash, so the nodes select the same transactions from the same pool.
+	TransactionSelectionFeeRate TransactionSelectionPolicy = "fee-rate"
+)
+
+// DefaultTransactionSelectionPolicy is the default policy of the proposer.
+const DefaultTransactionSelectionPolicy = TransactionSelectionFeeRate
+
+func (p TransactionSelectionPolicy) IsValid() bool {
+	switch p {
+	case TransactionSelectionFIFO, TransactionSelectionFeeRate:
+		return true
+	default:
+		return false
+	}
+}
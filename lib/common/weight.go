@@ -0,0 +1,21 @@
+package common
+
+// OperationWeights decides the weight of each operation by the operation
+// type; the operation type, which is not in the weights, has
+// `DefaultOperationWeight`. The weight of block is the sum of the weights of
+// its operations and it is limited by `Config.MaxBlockWeight`, so all the
+// nodes must have the same weights.
+type OperationWeights map[string]uint64
+
+// DefaultOperationWeights is the flat weights; every operation has
+// `DefaultOperationWeight`.
+var DefaultOperationWeights = OperationWeights{}
+
+// Weight returns the weight of the operation type.
+func (w OperationWeights) Weight(operationType string) uint64 {
+	if weight, found := w[operationType]; found {
+		return weight
+	}
+
+	return DefaultOperationWeight
+}
@@ -34,6 +34,7 @@ type ISAAC struct {
 	nodesHeight         map[ /* Node.Address() */ string]uint64
 	syncer              SyncController
 	latestReqSyncHeight uint64
+	reputation          *ReputationTracker
 
 	LatestBallot  ballot.Ballot
 	NetworkID     []byte
@@ -61,6 +62,11 @@ func NewISAAC(networkID []byte, node *node.LocalNode, p voting.ThresholdPolicy,
 		nodesHeight:       make(map[string]uint64),
 		syncer:            syncer,
 		LatestBallot:      ballot.Ballot{},
+		reputation:        NewReputationTracker(ReputationWindow),
+	}
+
+	if conf.ProposerLivenessThreshold > 0 {
+		is.proposerSelector = NewLivenessSelector(cm, st, conf.ProposerLivenessThreshold)
 	}
 
 	return
@@ -94,6 +100,8 @@ func (is *ISAAC) CloseConsensus(proposer string, basis voting.Basis, vh voting.H
 		transactionPool.Remove(rr.Transactions[proposer]...)
 	}
 
+	is.observeReputation(rr, proposer, vh)
+
 	delete(is.RunningRounds, roundHash)
 
 	// remove all the same rounds
@@ -107,6 +115,34 @@ func (is *ISAAC) CloseConsensus(proposer string, basis voting.Basis, vh voting.H
 	return
 }
 
+// observeReputation records the closed round to `ReputationTracker`; the
+// validators, which voted SIGN or ACCEPT until now, are on time.
+func (is *ISAAC) observeReputation(rr *RunningRound, proposer string, vh voting.Hole) {
+	if is.reputation == nil {
+		return
+	}
+
+	rr.RLock()
+	defer rr.RUnlock()
+
+	var voted []string
+	if rv, found := rr.Voted[proposer]; found {
+		for _, state := range []ballot.State{ballot.StateSIGN, ballot.StateACCEPT} {
+			for address := range rv.GetResult(state) {
+				voted = append(voted, address)
+			}
+		}
+	}
+
+	is.reputation.Observe(rr.VotingBasis, proposer, is.connectionManager.AllValidators(), voted, vh == voting.EXP)
+}
+
+// Reputation returns the scores of the validators by the closed rounds; see
+// `ReputationTracker`.
+func (is *ISAAC) Reputation() *ReputationTracker {
+	return is.reputation
+}
+
 func (is *ISAAC) SetLatestRound(round voting.Basis) {
 	is.LatestRound = round
 }
@@ -245,7 +281,12 @@ func (is *ISAAC) CanGetVotingResult(b ballot.Ballot) (RoundVoteResult, voting.Ho
 	defer is.RUnlock()
 	runningRound, _ := is.RunningRounds[b.VotingBasis().Index()]
 	if roundVote, err := runningRound.RoundVote(b.Proposer()); err == nil {
-		return roundVote.CanGetVotingResult(is.policy, b.State(), is.log)
+		return roundVote.CanGetVotingResult(
+			is.policy,
+			b.State(),
+			ExpiredVotesThreshold(is.policy, is.Conf.ExpiredVotesThreshold),
+			is.log,
+		)
 	} else {
 		return nil, voting.NOTYET, false
 	}
@@ -282,3 +323,16 @@ func (is *ISAAC) HasSameProposer(b ballot.Ballot) bool {
 func (is *ISAAC) LatestBlock() block.Block {
 	return block.GetLatestBlock(is.storage)
 }
+
+// IsFinal returns whether the block of the height is followed by the
+// `confirmations` blocks in the latest block. ISAAC confirms the block with
+// the BFT agreement, so every stored block is final by itself; it is for the
+// integrators, which have the own confirmation policy.
+func (is *ISAAC) IsFinal(height, confirmations uint64) bool {
+	latest := is.LatestBlock()
+	if height < common.GenesisBlockHeight || height > latest.Height {
+		return false
+	}
+
+	return latest.Height-height >= confirmations
+}
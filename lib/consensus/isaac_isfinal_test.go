@@ -0,0 +1,39 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+)
+
+func TestISAACIsFinal(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	is := ISAAC{storage: st}
+
+	latest := is.LatestBlock()
+	for i := 0; i < 3; i++ {
+		blk := block.TestMakeNewBlockWithPrevBlock(latest, []string{})
+		require.NoError(t, blk.Save(st))
+		latest = blk
+	}
+	require.Equal(t, common.GenesisBlockHeight+3, is.LatestBlock().Height)
+
+	// the latest block is final without confirmations
+	require.True(t, is.IsFinal(latest.Height, 0))
+	require.False(t, is.IsFinal(latest.Height, 1))
+
+	// the threshold is inclusive
+	require.True(t, is.IsFinal(common.GenesisBlockHeight, 3))
+	require.False(t, is.IsFinal(common.GenesisBlockHeight, 4))
+	require.True(t, is.IsFinal(common.GenesisBlockHeight+1, 2))
+	require.False(t, is.IsFinal(common.GenesisBlockHeight+1, 3))
+
+	// unknown heights are never final
+	require.False(t, is.IsFinal(latest.Height+1, 0))
+	require.False(t, is.IsFinal(0, 0))
+}
@@ -2,8 +2,12 @@ package consensus
 
 import (
 	"sort"
+	"sync"
 
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/storage"
 )
 
 type ProposerSelector interface {
@@ -19,3 +23,110 @@ func (s SequentialSelector) Select(blockHeight uint64, roundNumber uint64) strin
 	candidates.Sort()
 	return candidates[(blockHeight+roundNumber)%uint64(len(candidates))]
 }
+
+// LivenessSelectorEpoch is the number of blocks, which the EXP counts of
+// `LivenessSelector` are kept for; the counts are reset at every epoch, so
+// the deprioritized proposer gets the chance again and the counts can be
+// rebuilt from the limited number of blocks.
+const LivenessSelectorEpoch uint64 = 100
+
+// LivenessSelector selects the proposer like `SequentialSelector`, but it
+// skips the proposer, which has the consecutive EXPs more than `threshold`.
+//
+// The EXPs are attributed only by the confirmed blocks and the rounds of the
+// current height, which all the nodes agree on; the block of round `R`
+// means the rounds before `R` at the previous height are expired by their
+// proposers, and the count of the proposer of the block is reset. So every
+// node selects the same proposer without exchanging the liveness.
+type LivenessSelector struct {
+	sync.Mutex
+
+	cm        network.ConnectionManager
+	st        *storage.LevelDBBackend
+	threshold uint64
+
+	height uint64            // the height of cached `counts`
+	counts map[string]uint64 // consecutive EXPs by proposer
+}
+
+func NewLivenessSelector(cm network.ConnectionManager, st *storage.LevelDBBackend, threshold uint64) *LivenessSelector {
+	return &LivenessSelector{
+		cm:        cm,
+		st:        st,
+		threshold: threshold,
+	}
+}
+
+func (s *LivenessSelector) Select(blockHeight uint64, roundNumber uint64) string {
+	s.Lock()
+	defer s.Unlock()
+
+	candidates := sort.StringSlice(s.cm.AllValidators())
+	candidates.Sort()
+
+	counts := copyCounts(s.loadCounts(candidates, blockHeight))
+
+	// the rounds before `roundNumber` are expired
+	for round := uint64(0); round < roundNumber; round++ {
+		counts[s.selectByCounts(candidates, counts, blockHeight, round)]++
+	}
+
+	return s.selectByCounts(candidates, counts, blockHeight, roundNumber)
+}
+
+// selectByCounts selects the next candidate from the sequential one, which is
+// not deprioritized; if all the candidates are deprioritized, it returns the
+// sequential one.
+func (s *LivenessSelector) selectByCounts(candidates []string, counts map[string]uint64, blockHeight, roundNumber uint64) string {
+	n := uint64(len(candidates))
+	index := (blockHeight + roundNumber) % n
+	for i := uint64(0); i < n; i++ {
+		candidate := candidates[(index+i)%n]
+		if counts[candidate] < s.threshold {
+			return candidate
+		}
+	}
+
+	return candidates[index]
+}
+
+// loadCounts returns the EXP counts at `blockHeight`, which are replayed from
+// the first block of the epoch. The latest counts are cached, so usually only
+// the new block is replayed.
+func (s *LivenessSelector) loadCounts(candidates []string, blockHeight uint64) map[string]uint64 {
+	epoch := blockHeight - blockHeight%LivenessSelectorEpoch
+	if s.counts == nil || s.height > blockHeight || s.height < epoch {
+		s.height = epoch
+		s.counts = map[string]uint64{}
+	}
+
+	for height := s.height + 1; height <= blockHeight; height++ {
+		if height <= common.GenesisBlockHeight {
+			continue
+		}
+
+		blk, err := block.GetBlockByHeight(s.st, height)
+		if err != nil {
+			break
+		}
+
+		// `blk` was proposed at the previous height
+		for round := uint64(0); round < blk.Round; round++ {
+			s.counts[s.selectByCounts(candidates, s.counts, height-1, round)]++
+		}
+		delete(s.counts, blk.Proposer)
+
+		s.height = height
+	}
+
+	return s.counts
+}
+
+func copyCounts(counts map[string]uint64) map[string]uint64 {
+	copied := map[string]uint64{}
+	for k, v := range counts {
+		copied[k] = v
+	}
+
+	return copied
+}
@@ -0,0 +1,112 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/voting"
+)
+
+type validatorsConnectionManager struct {
+	network.ConnectionManager
+	validators []string
+}
+
+func (c validatorsConnectionManager) AllValidators() []string {
+	return c.validators
+}
+
+// runDeadProposer confirms the blocks until `untilHeight`; the rounds of
+// `dead` proposer are expired. It returns the number of the expired rounds.
+func runDeadProposer(t *testing.T, st *storage.LevelDBBackend, selector ProposerSelector, dead string, untilHeight uint64) (expired int) {
+	latest := block.GetLatestBlock(st)
+	for latest.Height < untilHeight {
+		var round uint64
+		for selector.Select(latest.Height, round) == dead {
+			round++
+			expired++
+		}
+
+		basis := voting.Basis{
+			Height:    latest.Height + 1,
+			Round:     round,
+			BlockHash: latest.Hash,
+		}
+		blk := block.NewBlock(selector.Select(latest.Height, round), basis, "", []string{}, nil, common.NowISO8601())
+		require.NoError(t, blk.Save(st))
+
+		latest = *blk
+	}
+
+	return
+}
+
+func TestLivenessSelectorDeadProposer(t *testing.T) {
+	cm := validatorsConnectionManager{validators: []string{"nodeA", "nodeB", "nodeC", "nodeD"}}
+	dead := "nodeC"
+	untilHeight := LivenessSelectorEpoch - 1
+
+	var expiredSequential, expiredLiveness int
+	{
+		st := block.InitTestBlockchain()
+		defer st.Close()
+
+		expiredSequential = runDeadProposer(t, st, SequentialSelector{cm}, dead, untilHeight)
+	}
+
+	var selector *LivenessSelector
+	var st *storage.LevelDBBackend
+	{
+		st = block.InitTestBlockchain()
+		defer st.Close()
+
+		selector = NewLivenessSelector(cm, st, 2)
+		expiredLiveness = runDeadProposer(t, st, selector, dead, untilHeight)
+	}
+
+	// the dead proposer is skipped after 2 EXPs
+	require.Equal(t, 2, expiredLiveness)
+	require.True(t, expiredSequential > expiredLiveness)
+
+	// the restarted node selects the same proposers from the blocks
+	restarted := NewLivenessSelector(cm, st, 2)
+	latest := block.GetLatestBlock(st)
+	for round := uint64(0); round < 8; round++ {
+		require.Equal(t, selector.Select(latest.Height, round), restarted.Select(latest.Height, round))
+	}
+	for round := uint64(0); round < 6; round++ { // until the others have 2 EXPs
+		require.NotEqual(t, dead, restarted.Select(latest.Height, round))
+	}
+
+	// at the new epoch, the dead proposer gets the chance again
+	expiredLiveness = runDeadProposer(t, st, selector, dead, untilHeight+LivenessSelectorEpoch)
+	require.Equal(t, 2, expiredLiveness)
+}
+
+func TestLivenessSelectorSameHeight(t *testing.T) {
+	cm := validatorsConnectionManager{validators: []string{"nodeA", "nodeB", "nodeC", "nodeD"}}
+
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	latest := block.GetLatestBlock(st)
+	sequential := SequentialSelector{cm}
+	selector := NewLivenessSelector(cm, st, 1)
+
+	// before any EXP, it is same with `SequentialSelector`
+	require.Equal(t, sequential.Select(latest.Height, 0), selector.Select(latest.Height, 0))
+
+	// every proposer is skipped after it's round expired at the same height,
+	// and then the sequential one is selected
+	selected := map[string]bool{}
+	for round := uint64(0); round < 4; round++ {
+		selected[selector.Select(latest.Height, round)] = true
+	}
+	require.Equal(t, 4, len(selected))
+	require.Equal(t, sequential.Select(latest.Height, 4), selector.Select(latest.Height, 4))
+}
@@ -0,0 +1,152 @@
+package consensus
+
+import (
+	"sort"
+	"sync"
+
+	"boscoin.io/sebak/lib/voting"
+)
+
+// ReputationWindow is the number of the latest closed rounds, which
+// `ReputationTracker` scores the validators by.
+const ReputationWindow int = 100
+
+// ValidatorScore is the responsiveness of the validator in the window of
+// `ReputationTracker`.
+type ValidatorScore struct {
+	Address string `json:"address"`
+	Rounds  int    `json:"rounds"`  // the observed rounds
+	OnTime  int    `json:"on_time"` // the rounds, which the validator voted before closed
+	Expired int    `json:"expired"` // the rounds, which were expired with the validator as proposer
+
+	// Score is the permille of `OnTime` less `Expired` by `Rounds`; it is
+	// 1000 without any observed round. It is calculated by integers, so the
+	// same observations make the same score in every node.
+	Score uint64 `json:"score"`
+}
+
+type reputationRound struct {
+	index      string // `voting.Basis.Index()`
+	validators []string
+	onTime     map[string]bool
+	expiredBy  string // the proposer of the expired round
+}
+
+// ReputationTracker scores the validators by the closed rounds of the local
+// node; the validator, which votes before the round is closed, is on time
+// and the proposer of the expired round is blamed for it. The rounds out of
+// `window` are forgotten, so the recovered validator scores well again.
+//
+// It is informational; the consensus does not use the score.
+type ReputationTracker struct {
+	sync.RWMutex
+
+	window int
+	rounds []reputationRound // the oldest first
+}
+
+func NewReputationTracker(window int) *ReputationTracker {
+	return &ReputationTracker{window: window}
+}
+
+// Observe records the closed round. `voted` is the validators, which voted
+// before the round is closed. If `expired`, the round is attributed to the
+// `proposer`. The round, which is already observed, is ignored.
+func (t *ReputationTracker) Observe(basis voting.Basis, proposer string, validators, voted []string, expired bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.window < 1 {
+		return
+	}
+
+	index := basis.Index()
+	for _, r := range t.rounds {
+		if r.index == index {
+			return
+		}
+	}
+
+	r := reputationRound{
+		index:      index,
+		validators: append([]string{}, validators...),
+		onTime:     map[string]bool{},
+	}
+	for _, v := range voted {
+		r.onTime[v] = true
+	}
+	if expired {
+		r.expiredBy = proposer
+	}
+
+	t.rounds = append(t.rounds, r)
+	if len(t.rounds) > t.window {
+		t.rounds = t.rounds[len(t.rounds)-t.window:]
+	}
+}
+
+// Score returns the score of the validator.
+func (t *ReputationTracker) Score(address string) ValidatorScore {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.score(address)
+}
+
+// Scores returns the scores of all the observed validators ordered by the
+// address.
+func (t *ReputationTracker) Scores() (scores []ValidatorScore) {
+	t.RLock()
+	defer t.RUnlock()
+
+	found := map[string]bool{}
+	var addresses []string
+	for _, r := range t.rounds {
+		for _, v := range r.validators {
+			if !found[v] {
+				found[v] = true
+				addresses = append(addresses, v)
+			}
+		}
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		scores = append(scores, t.score(address))
+	}
+
+	return
+}
+
+func (t *ReputationTracker) score(address string) ValidatorScore {
+	s := ValidatorScore{Address: address}
+	for _, r := range t.rounds {
+		var isValidator bool
+		for _, v := range r.validators {
+			if v == address {
+				isValidator = true
+				break
+			}
+		}
+		if !isValidator {
+			continue
+		}
+
+		s.Rounds++
+		if r.onTime[address] {
+			s.OnTime++
+		}
+		if r.expiredBy == address {
+			s.Expired++
+		}
+	}
+
+	switch {
+	case s.Rounds < 1:
+		s.Score = 1000
+	case s.OnTime > s.Expired:
+		s.Score = uint64(s.OnTime-s.Expired) * 1000 / uint64(s.Rounds)
+	}
+
+	return s
+}
@@ -0,0 +1,123 @@
+package consensus
+
+import (
+	"testing"
+
+	logging "github.com/inconshreveable/log15"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func TestReputationTracker(t *testing.T) {
+	validators := []string{"nodeA", "nodeB", "nodeC", "nodeD"}
+	tracker := NewReputationTracker(10)
+
+	// without observation
+	require.Equal(t, ValidatorScore{Address: "nodeA", Score: 1000}, tracker.Score("nodeA"))
+	require.Empty(t, tracker.Scores())
+
+	// nodeD always lags and nodeC lags sometimes
+	for height := uint64(1); height <= 10; height++ {
+		voted := []string{"nodeA", "nodeB"}
+		if height%2 == 0 {
+			voted = append(voted, "nodeC")
+		}
+		tracker.Observe(voting.Basis{Height: height}, "nodeA", validators, voted, false)
+	}
+
+	require.Equal(t, ValidatorScore{Address: "nodeA", Rounds: 10, OnTime: 10, Score: 1000}, tracker.Score("nodeA"))
+	require.Equal(t, ValidatorScore{Address: "nodeC", Rounds: 10, OnTime: 5, Score: 500}, tracker.Score("nodeC"))
+	require.Equal(t, ValidatorScore{Address: "nodeD", Rounds: 10, Score: 0}, tracker.Score("nodeD"))
+	require.True(t, tracker.Score("nodeB").Score > tracker.Score("nodeC").Score)
+	require.True(t, tracker.Score("nodeC").Score > tracker.Score("nodeD").Score)
+
+	scores := tracker.Scores()
+	require.Equal(t, 4, len(scores))
+	for i, v := range validators {
+		require.Equal(t, v, scores[i].Address)
+	}
+
+	{ // the same round is observed once
+		tracker.Observe(voting.Basis{Height: 10}, "nodeA", validators, validators, false)
+		require.Equal(t, 0, tracker.Score("nodeD").OnTime)
+	}
+
+	{ // the expired round is attributed to the proposer
+		tracker.Observe(voting.Basis{Height: 11}, "nodeB", validators, validators, true)
+		s := tracker.Score("nodeB")
+		require.Equal(t, 10, s.Rounds)
+		require.Equal(t, 10, s.OnTime)
+		require.Equal(t, 1, s.Expired)
+		require.Equal(t, uint64(900), s.Score)
+		require.True(t, tracker.Score("nodeA").Score > s.Score)
+	}
+
+	{ // the old rounds are out of the window, so nodeD recovers
+		for height := uint64(12); height <= 21; height++ {
+			tracker.Observe(voting.Basis{Height: height}, "nodeA", validators, validators, false)
+		}
+		require.Equal(t, ValidatorScore{Address: "nodeD", Rounds: 10, OnTime: 10, Score: 1000}, tracker.Score("nodeD"))
+		require.Equal(t, uint64(1000), tracker.Score("nodeB").Score)
+	}
+}
+
+func TestISAACReputation(t *testing.T) {
+	vt, err := NewDefaultVotingThresholdPolicy(67)
+	require.NoError(t, err)
+
+	validators := []string{"nodeA", "nodeB", "nodeC", "nodeD"}
+	vt.SetValidators(len(validators))
+
+	cm := validatorsConnectionManager{validators: validators}
+	is := ISAAC{
+		policy:            vt,
+		RunningRounds:     map[string]*RunningRound{},
+		connectionManager: cm,
+		proposerSelector:  SequentialSelector{cm},
+		log:               logging.New("module", "consensus"),
+		reputation:        NewReputationTracker(ReputationWindow),
+	}
+	pool := transaction.NewPool()
+
+	vote := func(basis voting.Basis, proposer, source string, state ballot.State, votingHole voting.Hole) {
+		b := ballot.NewBallot(source, proposer, basis, []string{})
+		b.SetVote(state, votingHole)
+		_, err := is.Vote(*b)
+		require.NoError(t, err)
+	}
+
+	for height := uint64(1); height <= 5; height++ {
+		basis := voting.Basis{Height: height, BlockHash: "block-hash"}
+		proposer := is.SelectProposer(basis.Height, basis.Round)
+
+		// nodeD votes after the round is closed
+		for _, source := range []string{"nodeA", "nodeB", "nodeC"} {
+			vote(basis, proposer, source, ballot.StateSIGN, voting.YES)
+		}
+		for _, source := range []string{"nodeA", "nodeB", "nodeC"} {
+			vote(basis, proposer, source, ballot.StateACCEPT, voting.YES)
+		}
+		require.NoError(t, is.CloseConsensus(proposer, basis, voting.YES, pool))
+		vote(basis, proposer, "nodeD", ballot.StateSIGN, voting.YES)
+	}
+
+	{ // the expired round
+		basis := voting.Basis{Height: 6, BlockHash: "block-hash"}
+		proposer := is.SelectProposer(basis.Height, basis.Round)
+		for _, source := range validators {
+			vote(basis, proposer, source, ballot.StateSIGN, voting.EXP)
+		}
+		require.NoError(t, is.CloseConsensus(proposer, basis, voting.EXP, pool))
+		require.Equal(t, 1, is.Reputation().Score(proposer).Expired)
+	}
+
+	timely := is.Reputation().Score("nodeA")
+	laggard := is.Reputation().Score("nodeD")
+	require.Equal(t, 6, timely.Rounds)
+	require.Equal(t, 6, laggard.Rounds)
+	require.Equal(t, 1, laggard.OnTime)
+	require.True(t, timely.Score > laggard.Score)
+}
@@ -65,19 +65,50 @@ func (rv *RoundVote) GetResult(state ballot.State) (result RoundVoteResult) {
 	return result
 }
 
-func (rv *RoundVote) CanGetVotingResult(policy voting.ThresholdPolicy, state ballot.State, log logging.Logger) (RoundVoteResult, voting.Hole, bool) {
+// CanGetVotingResult returns the result of the votes of the state. If
+// `expiredThreshold` is not `0`, the voting finishes as EXP when the EXP
+// votes reach it; see `ExpiredVotesThreshold()`.
+func (rv *RoundVote) CanGetVotingResult(policy voting.ThresholdPolicy, state ballot.State, expiredThreshold int, log logging.Logger) (RoundVoteResult, voting.Hole, bool) {
 	threshold := policy.Threshold()
 	if threshold < 1 {
 		return RoundVoteResult{}, voting.NOTYET, false
 	}
 
 	result := rv.GetResult(state)
+	if expiredThreshold > 0 {
+		if _, _, expired := result.Count(); expired >= expiredThreshold {
+			log.Debug(
+				"expired votes reached",
+				"expiredThreshold", expiredThreshold,
+				"expired", expired,
+				"state", state,
+			)
+			return result, voting.EXP, true
+		}
+	}
+
 	if len(result) < int(threshold) {
 		return result, voting.NOTYET, false
 	}
 
-	var yes, no, expired int
-	for _, votingHole := range result {
+	yes, no, expired := result.Count()
+
+	log.Debug(
+		"check threshold in isaac",
+		"threshold", threshold,
+		"yes", yes,
+		"no", no,
+		"expired", expired,
+		"state", state,
+	)
+
+	votingHole, finished := decideVotingHole(threshold, policy.Validators(), yes, no, expired)
+	return result, votingHole, finished
+}
+
+// Count returns the number of each vote.
+func (r RoundVoteResult) Count() (yes, no, expired int) {
+	for _, votingHole := range r {
 		switch votingHole {
 		case voting.YES:
 			yes++
@@ -88,31 +119,45 @@ func (rv *RoundVote) CanGetVotingResult(policy voting.ThresholdPolicy, state bal
 		}
 	}
 
-	log.Debug(
-		"check threshold in isaac",
-		"threshold", threshold,
-		"yes", yes,
-		"no", no,
-		"expired", expired,
-		"state", state,
-	)
+	return
+}
+
+// ExpiredVotesThreshold returns the number of EXP votes to finish the voting
+// as EXP by `common.Config.ExpiredVotesThreshold`; `0` means disabled. With
+// less than `validators - threshold + 1` EXP votes, YES or NO can still reach
+// the threshold, so the configured one is raised to it.
+func ExpiredVotesThreshold(policy voting.ThresholdPolicy, configured uint64) int {
+	if configured < 1 {
+		return 0
+	}
+
+	least := policy.Validators() - policy.Threshold() + 1
+	if least < 1 {
+		least = 1
+	}
+	if int(configured) < least {
+		return least
+	}
+
+	return int(configured)
+}
 
+func decideVotingHole(threshold, total, yes, no, expired int) (voting.Hole, bool) {
 	if yes >= threshold {
-		return result, voting.YES, true
+		return voting.YES, true
 	} else if no >= threshold {
-		return result, voting.NO, true
+		return voting.NO, true
 	} else {
 		// do nothing
 	}
 
 	// check draw!
-	total := policy.Validators()
 	voted := yes + no + expired
 	if cannotBeOver(total-voted, threshold, yes, no) { // draw
-		return result, voting.EXP, true
+		return voting.EXP, true
 	}
 
-	return result, voting.NOTYET, false
+	return voting.NOTYET, false
 }
 
 func cannotBeOver(remain, threshold, yes, no int) bool {
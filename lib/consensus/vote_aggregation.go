@@ -0,0 +1,91 @@
+package consensus
+
+import (
+	"sort"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// VoteAggregation is the snapshot of the votes for the ballot of `Proposer`
+// in the running round. It is copied from the running round, so it is not
+// changed by the later votes.
+type VoteAggregation struct {
+	Basis      voting.Basis         `json:"basis"`
+	Proposer   string               `json:"proposer"`
+	Threshold  int                  `json:"threshold"`
+	Validators int                  `json:"validators"`
+	SIGN       StateVoteAggregation `json:"sign"`
+	ACCEPT     StateVoteAggregation `json:"accept"`
+}
+
+// StateVoteAggregation is the votes of one state; `Result` is the result of
+// the votes, if `Quorum` is reached, otherwise `voting.NOTYET`.
+type StateVoteAggregation struct {
+	Voters  RoundVoteResult `json:"voters"`
+	Yes     int             `json:"yes"`
+	No      int             `json:"no"`
+	Expired int             `json:"expired"`
+	Quorum  bool            `json:"quorum"`
+	Result  voting.Hole     `json:"result"`
+}
+
+func newStateVoteAggregation(result RoundVoteResult, threshold, validators, expiredThreshold int) StateVoteAggregation {
+	s := StateVoteAggregation{
+		Voters: RoundVoteResult{},
+		Result: voting.NOTYET,
+	}
+	for address, votingHole := range result {
+		s.Voters[address] = votingHole
+	}
+	s.Yes, s.No, s.Expired = s.Voters.Count()
+
+	if threshold < 1 {
+		return s
+	}
+
+	if expiredThreshold > 0 && s.Expired >= expiredThreshold {
+		s.Result, s.Quorum = voting.EXP, true
+	} else if len(s.Voters) >= threshold {
+		s.Result, s.Quorum = decideVotingHole(threshold, validators, s.Yes, s.No, s.Expired)
+	}
+
+	return s
+}
+
+// VoteAggregations returns the snapshots of the votes of the running rounds
+// at `height` and `round` in the order of proposer. It is for debugging the
+// stuck rounds.
+func (is *ISAAC) VoteAggregations(height, round uint64) (aggs []VoteAggregation) {
+	is.RLock()
+	defer is.RUnlock()
+
+	threshold := is.policy.Threshold()
+	validators := is.policy.Validators()
+	expiredThreshold := ExpiredVotesThreshold(is.policy, is.Conf.ExpiredVotesThreshold)
+
+	for _, rr := range is.RunningRounds {
+		if rr.VotingBasis.Height != height || rr.VotingBasis.Round != round {
+			continue
+		}
+
+		rr.RLock()
+		for proposer, rv := range rr.Voted {
+			aggs = append(aggs, VoteAggregation{
+				Basis:      rr.VotingBasis,
+				Proposer:   proposer,
+				Threshold:  threshold,
+				Validators: validators,
+				SIGN:       newStateVoteAggregation(rv.GetResult(ballot.StateSIGN), threshold, validators, expiredThreshold),
+				ACCEPT:     newStateVoteAggregation(rv.GetResult(ballot.StateACCEPT), threshold, validators, expiredThreshold),
+			})
+		}
+		rr.RUnlock()
+	}
+
+	sort.Slice(aggs, func(i, j int) bool {
+		return aggs[i].Proposer < aggs[j].Proposer
+	})
+
+	return
+}
@@ -0,0 +1,85 @@
+package consensus
+
+import (
+	"testing"
+
+	logging "github.com/inconshreveable/log15"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func TestVoteAggregations(t *testing.T) {
+	vt, err := NewDefaultVotingThresholdPolicy(67)
+	require.NoError(t, err)
+
+	validators := []string{"nodeA", "nodeB", "nodeC", "nodeD"}
+	vt.SetValidators(len(validators))
+
+	is := ISAAC{
+		policy:           vt,
+		RunningRounds:    map[string]*RunningRound{},
+		proposerSelector: SequentialSelector{validatorsConnectionManager{validators: validators}},
+		log:              logging.New("module", "consensus"),
+	}
+
+	basis := voting.Basis{Height: 3, Round: 1, BlockHash: "block-hash"}
+	proposer := is.SelectProposer(basis.Height, basis.Round)
+
+	vote := func(source string, state ballot.State, votingHole voting.Hole) {
+		b := ballot.NewBallot(source, proposer, basis, []string{})
+		b.SetVote(state, votingHole)
+		_, err := is.Vote(*b)
+		require.NoError(t, err)
+	}
+
+	// no running round
+	require.Equal(t, 0, len(is.VoteAggregations(basis.Height, basis.Round)))
+
+	vote("nodeA", ballot.StateSIGN, voting.YES)
+	vote("nodeB", ballot.StateSIGN, voting.NO)
+
+	aggs := is.VoteAggregations(basis.Height, basis.Round)
+	require.Equal(t, 1, len(aggs))
+	agg := aggs[0]
+	require.Equal(t, proposer, agg.Proposer)
+	require.Equal(t, basis, agg.Basis)
+	require.Equal(t, 3, agg.Threshold)
+	require.Equal(t, 4, agg.Validators)
+	require.Equal(t, RoundVoteResult{"nodeA": voting.YES, "nodeB": voting.NO}, agg.SIGN.Voters)
+	require.Equal(t, 1, agg.SIGN.Yes)
+	require.Equal(t, 1, agg.SIGN.No)
+	require.False(t, agg.SIGN.Quorum)
+	require.Equal(t, voting.NOTYET, agg.SIGN.Result)
+	require.Equal(t, 0, len(agg.ACCEPT.Voters))
+
+	// the other height and round are not included
+	require.Equal(t, 0, len(is.VoteAggregations(basis.Height, basis.Round+1)))
+	require.Equal(t, 0, len(is.VoteAggregations(basis.Height+1, basis.Round)))
+
+	vote("nodeC", ballot.StateSIGN, voting.YES)
+	vote("nodeD", ballot.StateSIGN, voting.YES)
+
+	// the snapshot is not changed by the later votes
+	require.Equal(t, 2, len(agg.SIGN.Voters))
+
+	agg = is.VoteAggregations(basis.Height, basis.Round)[0]
+	require.Equal(t, 4, len(agg.SIGN.Voters))
+	require.Equal(t, 3, agg.SIGN.Yes)
+	require.True(t, agg.SIGN.Quorum)
+	require.Equal(t, voting.YES, agg.SIGN.Result)
+
+	{ // the draw is also the quorum of EXP
+		vote("nodeA", ballot.StateACCEPT, voting.YES)
+		vote("nodeB", ballot.StateACCEPT, voting.NO)
+		agg = is.VoteAggregations(basis.Height, basis.Round)[0]
+		require.False(t, agg.ACCEPT.Quorum)
+
+		vote("nodeC", ballot.StateACCEPT, voting.EXP)
+		agg = is.VoteAggregations(basis.Height, basis.Round)[0]
+		require.Equal(t, 1, agg.ACCEPT.Expired)
+		require.True(t, agg.ACCEPT.Quorum)
+		require.Equal(t, voting.EXP, agg.ACCEPT.Result)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/voting"
 )
 
 type ISAACVotingThresholdPolicy struct {
@@ -72,6 +73,28 @@ func (vt *ISAACVotingThresholdPolicy) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// BFTRequiredThreshold returns the minimum number of votes for the BFT safety,
+// which is more than 2/3 of the validators; with less votes, the faulty
+// validators can finalize the conflicting blocks.
+func BFTRequiredThreshold(validators int) int {
+	return validators*2/3 + 1
+}
+
+// ValidateThreshold checks the threshold of the policy satisfies the BFT
+// safety with the current validators; if not, it returns
+// `errors.VotingThresholdUnsafe` with the required threshold.
+func ValidateThreshold(p voting.ThresholdPolicy) error {
+	required := BFTRequiredThreshold(p.Validators())
+	if threshold := p.Threshold(); threshold < required {
+		return errors.VotingThresholdUnsafe.Clone().
+			SetData("threshold", threshold).
+			SetData("required", required).
+			SetData("validators", p.Validators())
+	}
+
+	return nil
+}
+
 func NewDefaultVotingThresholdPolicy(threshold int) (vt *ISAACVotingThresholdPolicy, err error) {
 	if threshold <= 0 {
 		err = errors.InvalidVotingThresholdPolicy
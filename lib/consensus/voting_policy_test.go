@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/errors"
 )
 
 func TestThreshold(t *testing.T) {
@@ -14,3 +16,49 @@ func TestThreshold(t *testing.T) {
 	require.Equal(t, 660, vt.Threshold())
 
 }
+
+func TestValidateThreshold(t *testing.T) {
+	cases := []struct {
+		threshold  int
+		validators int
+		safe       bool
+	}{
+		{67, 1, true},
+		{67, 3, true},
+		{66, 3, false},
+		{66, 4, true},
+		{66, 6, false},
+		{67, 6, true},
+		{50, 4, false},
+		{100, 10, true},
+		{67, 100, true},
+		{66, 100, false},
+	}
+
+	for _, c := range cases {
+		vt, err := NewDefaultVotingThresholdPolicy(c.threshold)
+		require.NoError(t, err)
+		vt.SetValidators(c.validators)
+
+		err = ValidateThreshold(vt)
+		if c.safe {
+			require.NoError(t, err, "threshold=%d validators=%d", c.threshold, c.validators)
+			continue
+		}
+
+		require.Error(t, err, "threshold=%d validators=%d", c.threshold, c.validators)
+		require.Equal(t, errors.VotingThresholdUnsafe.Code, err.(*errors.Error).Code)
+		require.Equal(t, BFTRequiredThreshold(c.validators), err.(*errors.Error).Data["required"])
+	}
+}
+
+func TestExpiredVotesThreshold(t *testing.T) {
+	vt, err := NewDefaultVotingThresholdPolicy(67)
+	require.NoError(t, err)
+	vt.SetValidators(10) // threshold is 7
+
+	require.Equal(t, 0, ExpiredVotesThreshold(vt, 0))
+	require.Equal(t, 4, ExpiredVotesThreshold(vt, 1))
+	require.Equal(t, 4, ExpiredVotesThreshold(vt, 4))
+	require.Equal(t, 6, ExpiredVotesThreshold(vt, 6))
+}
@@ -72,6 +72,15 @@ func (o *Error) EncodeRLP(w io.Writer) (err error) {
 	})
 }
 
+// NewError declares new error; the first declared error of the code can be
+// found by `Lookup()`.
 func NewError(code uint, message string) *Error {
+	e := newError(code, message)
+	register(e)
+
+	return e
+}
+
+func newError(code uint, message string) *Error {
 	return &Error{Code: code, Message: message, Data: map[string]interface{}{}}
 }
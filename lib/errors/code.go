@@ -0,0 +1,133 @@
+package errors
+
+// registered keeps the errors declared by `NewError()` by it's code, so the
+// client can find the error by the code with `Lookup()`.
+var registered = map[uint]*Error{}
+
+func register(e *Error) {
+	if _, found := registered[e.Code]; found {
+		return
+	}
+
+	registered[e.Code] = e
+}
+
+// Lookup returns the declared error of the code.
+func Lookup(code uint) (*Error, bool) {
+	e, found := registered[code]
+	return e, found
+}
+
+// ValidationErrorFields is the documented errors of the transaction and
+// operation validation with the field of transaction, which causes the error.
+// The codes are stable, so the clients can decide what to do, like retrying,
+// by the code.
+var ValidationErrorFields = map[*Error]string{
+	HashDoesNotMatch:                          "hash",
+	SignatureVerificationFailed:               "signature",
+	BadPublicAddress:                          "address",
+	InvalidFee:                                "fee",
+	InvalidOperation:                          "operations",
+	TransactionEmptyOperations:                "operations",
+	DuplicatedOperation:                       "operations",
+	UnknownOperationType:                      "type",
+	TypeOperationBodyNotMatched:               "type",
+	BlockAccountDoesNotExists:                 "address",
+	BlockAccountAlreadyExists:                 "target",
+	AccountBalanceUnderZero:                   "amount",
+	MaximumBalanceReached:                     "amount",
+	TransactionInvalidSequenceID:              "sequence_id",
+	TransactionExcessAbilityToPay:             "amount",
+	TransactionSameSourceInBallot:             "source",
+	TransactionHasOverMaxOperations:           "operations",
+	OperationAmountUnderflow:                  "amount",
+	FrozenAccountNoDeposit:                    "target",
+	FrozenAccountCreationWholeUnit:            "amount",
+	FrozenAccountMustWithdrawEverything:       "amount",
+	InsufficientAmountNewAccount:              "amount",
+	OperationBodyInsufficient:                 "body",
+	OperationAmountOverflow:                   "amount",
+	InvalidTransaction:                        "",
+	UnfreezingFromInvalidAccount:              "source",
+	UnfreezingToInvalidLinkedAccount:          "target",
+	UnfreezingNotReachedExpiration:            "source",
+	FrozenAccountMustCreatedFromLinkedAccount: "linked",
+	UnfreezingRequestNotRequested:             "source",
+	UnfreezingRequestAlreadyReceived:          "source",
+	TransactionSameSourceInPool:               "source",
+	TransactionExpired:                        "valid_until_height",
+	OperationBodyTooLarge:                     "body",
+	TransactionTooLarge:                       "",
+	DuplicatedSigner:                          "signatures",
+	SignersThresholdUnreachable:               "threshold",
+	TransactionNotEnoughSignatureWeight:       "signatures",
+	TransactionStaleSequenceID:                "sequence_id",
+	TransactionFutureSequenceID:               "sequence_id",
+	TransactionFeeBumpTooLow:                  "fee",
+	AccountDataNameTooLarge:                   "name",
+	AccountDataValueTooLarge:                  "value",
+	AccountDataDoesNotExist:                   "name",
+	ValidatorSetChangeNotAllowed:              "source",
+	ValidatorSetChangeHeightPassed:            "height",
+	InvalidMemo:                               "memo",
+	MemoTooLarge:                              "memo",
+	TimeLockDoesNotExist:                      "source",
+	TimeLockNotUnlocked:                       "unlock_height",
+	TimeLockHeightPassed:                      "unlock_height",
+	BlockAccountClosed:                        "source",
+	BlockAccountClosedNoDeposit:               "target",
+	AccountMergeTimeLockRemains:               "source",
+	AccountBalanceUnderMinimum:                "amount",
+	OperationTypeDisabled:                     "type",
+	MessageSignatureInvalid:                   "signature",
+	GovernanceProposalDoesNotExist:            "proposal",
+	GovernanceProposalClosed:                  "proposal",
+	GovernanceCloseHeightPassed:               "close_height",
+	GovernanceVoteFromFrozenAccount:           "source",
+	OperationRejectedByValidator:              "body",
+}
+
+var validationErrorFieldsByCode = map[uint]string{}
+
+func init() {
+	for e, field := range ValidationErrorFields {
+		validationErrorFieldsByCode[e.Code] = field
+	}
+}
+
+// SetField sets the field, which causes the error; it overrides the
+// documented field in `ValidationErrorFields`.
+func (o *Error) SetField(field string) *Error {
+	return o.SetData("field", field)
+}
+
+// Field returns the field, which causes the error.
+func (o *Error) Field() string {
+	if field, ok := o.Data["field"].(string); ok {
+		return field
+	}
+
+	return validationErrorFieldsByCode[o.Code]
+}
+
+// Envelope is the JSON representation of error for the clients.
+type Envelope struct {
+	Code    uint   `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// NewEnvelope makes `Envelope` from error; the error, which is not `*Error`,
+// has `0` code.
+func NewEnvelope(err error) Envelope {
+	e, ok := err.(*Error)
+	if !ok {
+		return Envelope{Message: err.Error()}
+	}
+
+	return Envelope{
+		Code:    e.Code,
+		Message: e.Message,
+		Field:   e.Field(),
+	}
+}
@@ -0,0 +1,137 @@
+package errors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidationErrorCodes pins the documented codes and fields of the
+// validation errors; the codes must not be changed.
+func TestValidationErrorCodes(t *testing.T) {
+	documented := []struct {
+		err   *Error
+		code  uint
+		field string
+	}{
+		{HashDoesNotMatch, 101, "hash"},
+		{SignatureVerificationFailed, 102, "signature"},
+		{BadPublicAddress, 103, "address"},
+		{InvalidFee, 104, "fee"},
+		{InvalidOperation, 105, "operations"},
+		{TransactionEmptyOperations, 123, "operations"},
+		{DuplicatedOperation, 125, "operations"},
+		{UnknownOperationType, 126, "type"},
+		{TypeOperationBodyNotMatched, 127, "type"},
+		{BlockAccountDoesNotExists, 128, "address"},
+		{BlockAccountAlreadyExists, 129, "target"},
+		{AccountBalanceUnderZero, 130, "amount"},
+		{MaximumBalanceReached, 131, "amount"},
+		{TransactionInvalidSequenceID, 133, "sequence_id"},
+		{TransactionExcessAbilityToPay, 138, "amount"},
+		{TransactionSameSourceInBallot, 139, "source"},
+		{TransactionHasOverMaxOperations, 151, "operations"},
+		{OperationAmountUnderflow, 152, "amount"},
+		{FrozenAccountNoDeposit, 153, "target"},
+		{FrozenAccountCreationWholeUnit, 154, "amount"},
+		{FrozenAccountMustWithdrawEverything, 155, "amount"},
+		{InsufficientAmountNewAccount, 156, "amount"},
+		{OperationBodyInsufficient, 157, "body"},
+		{OperationAmountOverflow, 158, "amount"},
+		{InvalidTransaction, 164, ""},
+		{UnfreezingFromInvalidAccount, 166, "source"},
+		{UnfreezingToInvalidLinkedAccount, 167, "target"},
+		{UnfreezingNotReachedExpiration, 168, "source"},
+		{FrozenAccountMustCreatedFromLinkedAccount, 169, "linked"},
+		{UnfreezingRequestNotRequested, 170, "source"},
+		{UnfreezingRequestAlreadyReceived, 171, "source"},
+		{TransactionSameSourceInPool, 176, "source"},
+		{TransactionExpired, 182, "valid_until_height"},
+		{OperationBodyTooLarge, 186, "body"},
+		{TransactionTooLarge, 187, ""},
+		{DuplicatedSigner, 188, "signatures"},
+		{SignersThresholdUnreachable, 189, "threshold"},
+		{TransactionNotEnoughSignatureWeight, 190, "signatures"},
+		{TransactionStaleSequenceID, 191, "sequence_id"},
+		{TransactionFutureSequenceID, 192, "sequence_id"},
+		{TransactionFeeBumpTooLow, 193, "fee"},
+		{AccountDataNameTooLarge, 196, "name"},
+		{AccountDataValueTooLarge, 197, "value"},
+		{AccountDataDoesNotExist, 198, "name"},
+		{ValidatorSetChangeNotAllowed, 205, "source"},
+		{ValidatorSetChangeHeightPassed, 206, "height"},
+		{InvalidMemo, 208, "memo"},
+		{MemoTooLarge, 209, "memo"},
+		{TimeLockDoesNotExist, 210, "source"},
+		{TimeLockNotUnlocked, 211, "unlock_height"},
+		{TimeLockHeightPassed, 212, "unlock_height"},
+		{BlockAccountClosed, 214, "source"},
+		{BlockAccountClosedNoDeposit, 215, "target"},
+		{AccountMergeTimeLockRemains, 216, "source"},
+		{AccountBalanceUnderMinimum, 218, "amount"},
+		{OperationTypeDisabled, 219, "type"},
+		{MessageSignatureInvalid, 220, "signature"},
+		{GovernanceProposalDoesNotExist, 224, "proposal"},
+		{GovernanceProposalClosed, 225, "proposal"},
+		{GovernanceCloseHeightPassed, 226, "close_height"},
+		{GovernanceVoteFromFrozenAccount, 227, "source"},
+		{OperationRejectedByValidator, 231, "body"},
+	}
+
+	require.Equal(t, len(documented), len(ValidationErrorFields))
+
+	for _, d := range documented {
+		field, found := ValidationErrorFields[d.err]
+		require.True(t, found, d.err.Message)
+		require.Equal(t, d.field, field, d.err.Message)
+
+		require.Equal(t, d.code, d.err.Code, d.err.Message)
+		require.Equal(t, d.field, d.err.Field(), d.err.Message)
+
+		e, found := Lookup(d.code)
+		require.True(t, found, d.err.Message)
+		require.Equal(t, d.err, e)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	e, found := Lookup(InvalidFee.Code)
+	require.True(t, found)
+	require.True(t, e == InvalidFee)
+
+	{ // not declared
+		_, found := Lookup(117)
+		require.False(t, found)
+	}
+
+	{ // `Newf()` does not override the declared error
+		Newf(InvalidFee, "fee is %d", 0)
+		e, _ := Lookup(InvalidFee.Code)
+		require.True(t, e == InvalidFee)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	{
+		b, err := json.Marshal(NewEnvelope(InvalidFee))
+		require.NoError(t, err)
+		require.Equal(t, `{"code":104,"message":"invalid fee","field":"fee"}`, string(b))
+	}
+
+	{ // without field
+		b, err := json.Marshal(NewEnvelope(NewButKnownMessage))
+		require.NoError(t, err)
+		require.Equal(t, `{"code":106,"message":"received new, but known message"}`, string(b))
+	}
+
+	{ // `SetField()` overrides the documented field
+		e := BadPublicAddress.Clone().SetField("target")
+		require.Equal(t, Envelope{Code: 103, Message: BadPublicAddress.Message, Field: "target"}, NewEnvelope(e))
+		require.Equal(t, "address", BadPublicAddress.Field())
+	}
+
+	{ // not `*Error`
+		require.Equal(t, Envelope{Message: "unknown"}, NewEnvelope(New("unknown")))
+	}
+}
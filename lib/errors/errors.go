@@ -12,7 +12,7 @@ var Wrap = pkgerrors.Wrap
 var Wrapf = pkgerrors.Wrapf
 
 func Newf(err *Error, format string, args ...interface{}) error {
-	return NewError(err.Code, fmt.Sprintf(format, args...))
+	return newError(err.Code, fmt.Sprintf(format, args...))
 }
 
 var (
@@ -95,4 +95,57 @@ var (
 	AlreadyCommittable                        = NewError(177, "already Committable")
 	FailedToSaveBlockOperaton                 = NewError(178, "failed to save BlockOperation")
 	NodeNotFound                              = NewError(179, "Node not found")
+	BallotProposedTimeTooOld                  = NewError(180, "proposed time of ballot is too old")
+	BallotProposedTimeTooFuture               = NewError(181, "proposed time of ballot is too far in the future")
+	TransactionExpired                        = NewError(182, "transaction is expired")
+	BallotUnsupportedVersion                  = NewError(183, "unsupported ballot version")
+	CollectedTxFeeNotMatched                  = NewError(184, "collected fee does not match with the fee of transactions")
+	GenesisNotMatched                         = NewError(185, "genesis block does not match")
+	OperationBodyTooLarge                     = NewError(186, "operation body is too large")
+	TransactionTooLarge                       = NewError(187, "transaction is too large")
+	DuplicatedSigner                          = NewError(188, "duplicated signer found")
+	SignersThresholdUnreachable               = NewError(189, "threshold is over the total weight of signers")
+	TransactionNotEnoughSignatureWeight       = NewError(190, "accumulated weight of signatures does not meet the threshold")
+	TransactionStaleSequenceID                = NewError(191, "sequenceID is already used")
+	TransactionFutureSequenceID               = NewError(192, "sequenceID is ahead of the expected")
+	TransactionFeeBumpTooLow                  = NewError(193, "fee is not enough to replace the transaction in pool")
+	BlockConflict                             = NewError(194, "different block already exists at the same height")
+	InvalidConfig                             = NewError(195, "invalid config")
+	AccountDataNameTooLarge                   = NewError(196, "name of account data is too large")
+	AccountDataValueTooLarge                  = NewError(197, "value of account data is too large")
+	AccountDataDoesNotExist                   = NewError(198, "account data does not exist")
+	BallotAlreadyReceived                     = NewError(199, "ballot already received")
+	InvalidSnapshot                           = NewError(200, "invalid snapshot")
+	SnapshotChecksumNotMatched                = NewError(201, "checksum of snapshot section does not match")
+	BlockAccountCheckpointNotMatched          = NewError(202, "hash of account checkpoint does not match")
+	BallotHasOverMaxOperationsInBallot        = NewError(203, "too many operations in ballot")
+	BallotHasOverMaxWeightInBallot            = NewError(204, "weight of ballot is over the limit")
+	ValidatorSetChangeNotAllowed              = NewError(205, "only genesis account can change the validator set")
+	ValidatorSetChangeHeightPassed            = NewError(206, "height of validator set change is already passed")
+	VotingThresholdUnsafe                     = NewError(207, "threshold does not satisfy the BFT safety")
+	InvalidMemo                               = NewError(208, "invalid memo")
+	MemoTooLarge                              = NewError(209, "text of memo is too large")
+	TimeLockDoesNotExist                      = NewError(210, "time lock does not exist")
+	TimeLockNotUnlocked                       = NewError(211, "time lock is not unlocked yet")
+	TimeLockHeightPassed                      = NewError(212, "unlock height of time lock is already passed")
+	TransactionInclusionTimeout               = NewError(213, "timed out to wait for the transaction inclusion")
+	BlockAccountClosed                        = NewError(214, "account is closed")
+	BlockAccountClosedNoDeposit               = NewError(215, "closed account can not receive deposit")
+	AccountMergeTimeLockRemains               = NewError(216, "account with time locks can not be merged")
+	BlockOperationAlreadyExists               = NewError(217, "operation already exists in block")
+	AccountBalanceUnderMinimum                = NewError(218, "balance can not be under the minimum balance")
+	OperationTypeDisabled                     = NewError(219, "operation type is disabled")
+	MessageSignatureInvalid                   = NewError(220, "signature is invalid, possibly signed for the other network")
+	MessageTooLarge                           = NewError(221, "message is too large")
+	BallotInvalidExpiredReason                = NewError(222, "invalid expired reason of ballot")
+	ProposalDeadlineExceeded                  = NewError(223, "proposing ballot is not finished in the deadline")
+	GovernanceProposalDoesNotExist            = NewError(224, "governance proposal does not exist")
+	GovernanceProposalClosed                  = NewError(225, "governance proposal is already closed")
+	GovernanceCloseHeightPassed               = NewError(226, "close height of governance proposal is already passed")
+	GovernanceVoteFromFrozenAccount           = NewError(227, "frozen account can not vote")
+	CommonAccountDoesNotExist                 = NewError(228, "common account does not exist")
+	IdempotencyKeyConflict                    = NewError(229, "idempotency key is already used for the other request")
+	BlockHeightRegression                     = NewError(230, "block height is lower than the highest stored block")
+	OperationRejectedByValidator              = NewError(231, "operation is rejected by validator")
+	TooManyTransactionsInBatch                = NewError(232, "too many transactions in batch")
 )
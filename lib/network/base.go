@@ -40,6 +40,10 @@ type NetworkClient interface {
 	SendMessage(common.Serializable) ([]byte, error)
 	SendBallot(common.Serializable) ([]byte, error)
 	GetTransactions([]string) ([]byte, error)
+
+	// GetBlockStream requests the blocks from the height of `from` to `to`
+	// and returns the stream of them; the caller must close it.
+	GetBlockStream(from, to uint64) (io.ReadCloser, error)
 }
 
 type MessageBroker interface {
@@ -2,9 +2,11 @@ package network
 
 import (
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"boscoin.io/sebak/lib/common"
@@ -174,6 +176,28 @@ func (c *HTTP2NetworkClient) GetTransactions(txs []string) (retBody []byte, err
 	return
 }
 
+func (c *HTTP2NetworkClient) GetBlockStream(from, to uint64) (body io.ReadCloser, err error) {
+	u := c.resolvePath(UrlPathPrefixNode + "/blocks/stream")
+	u.RawQuery = url.Values{
+		"from": []string{strconv.FormatUint(from, 10)},
+		"to":   []string{strconv.FormatUint(to, 10)},
+	}.Encode()
+
+	var response *http.Response
+	if response, err = c.client.Get(u.String(), c.DefaultHeaders()); err != nil {
+		return
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		err = errors.HTTPProblem.Clone().SetData("status", response.StatusCode)
+		return
+	}
+
+	body = response.Body
+	return
+}
+
 ///
 /// Perform a raw Get request on this peer
 ///
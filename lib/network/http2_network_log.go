@@ -1,6 +1,9 @@
 package network
 
 import (
+	"bufio"
+	"fmt"
+	"net"
 	"net/http"
 
 	logging "github.com/inconshreveable/log15"
@@ -53,6 +56,16 @@ func (l *HTTP2ResponseLog15Writer) Flush() {
 	}
 }
 
+// Hijack is for the websocket handler.
+func (l *HTTP2ResponseLog15Writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := l.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("http: response can not be hijacked")
+	}
+	l.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
 type HTTP2Log15Handler struct {
 	log     logging.Logger
 	handler http.Handler
@@ -2,6 +2,7 @@ package network
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"runtime/debug"
 	"strconv"
@@ -142,3 +143,54 @@ func RateLimitMiddleware(logger logging.Logger, rule common.RateLimitRule) mux.M
 		})
 	}
 }
+
+// maxMessageSizeBody returns `errors.MessageTooLarge` when the body is read
+// over the limit of `http.MaxBytesReader`.
+type maxMessageSizeBody struct {
+	io.ReadCloser
+}
+
+func (b maxMessageSizeBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	if _, ok := err.(*http.MaxBytesError); ok {
+		err = errors.MessageTooLarge
+	}
+
+	return
+}
+
+// MaxMessageSizeMiddleware rejects the request, whose body is over `size`
+// bytes, with `errors.MessageTooLarge` before the handler reads it; the
+// request with the larger `Content-Length` is rejected without reading the
+// body and the connection of the peer is closed. The body without
+// `Content-Length` is read only up to `size`. If `size` is 0, there will be
+// no limit.
+func MaxMessageSizeMiddleware(logger logging.Logger, size int) mux.MiddlewareFunc {
+	if logger == nil {
+		logger = log
+	}
+
+	return func(next http.Handler) http.Handler {
+		if size < 1 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > int64(size) {
+				logger.Debug(
+					"message is too large",
+					"remote", r.RemoteAddr,
+					"path", r.URL.Path,
+					"size", r.ContentLength,
+					"limit", size,
+				)
+				w.Header().Set("Connection", "close")
+				httputils.WriteJSONError(w, errors.MessageTooLarge)
+				return
+			}
+
+			r.Body = maxMessageSizeBody{ReadCloser: http.MaxBytesReader(w, r.Body, int64(size))}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
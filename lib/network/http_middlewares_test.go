@@ -1,8 +1,10 @@
 package network
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -249,3 +251,96 @@ func TestRateLimitMiddleWareUnlimit(t *testing.T) {
 		require.Equal(t, []byte("1"), body)
 	}
 }
+
+// testSizedReader is the body of the given size, which counts the read bytes
+// and hides the size from the request, so it is sent without
+// `Content-Length`.
+type testSizedReader struct {
+	size int
+	read int
+}
+
+func (r *testSizedReader) Read(p []byte) (n int, err error) {
+	if r.read >= r.size {
+		return 0, io.EOF
+	}
+	n = len(p)
+	if left := r.size - r.read; n > left {
+		n = left
+	}
+	r.read += n
+
+	return
+}
+
+func TestMaxMessageSizeMiddleware(t *testing.T) {
+	handlerURL := UrlPathPrefixNode + "/test"
+	limit := 1024
+
+	var handled int
+	var readSize int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		handled++
+
+		body, err := ioutil.ReadAll(r.Body)
+		readSize = len(body)
+		if err != nil {
+			httputils.WriteJSONError(w, err)
+			return
+		}
+		w.Write([]byte("1"))
+	}
+
+	router := mux.NewRouter()
+	router.Use(MaxMessageSizeMiddleware(nil, limit))
+	router.HandleFunc(handlerURL, http.HandlerFunc(handler)).Methods("POST")
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	{ // under the limit
+		resp, err := ts.Client().Post(ts.URL+handlerURL, "application/json", bytes.NewReader(make([]byte, limit)))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, 1, handled)
+		require.Equal(t, limit, readSize)
+	}
+
+	{ // over the limit by `Content-Length`; the handler is not called
+		resp, err := ts.Client().Post(ts.URL+handlerURL, "application/json", bytes.NewReader(make([]byte, limit+1)))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		require.Equal(t, 1, handled)
+	}
+
+	{ // over the limit without `Content-Length`; only the limit is read
+		body := &testSizedReader{size: limit * 1024}
+		resp, err := ts.Client().Post(ts.URL+handlerURL, "application/json", body)
+		require.NoError(t, err)
+
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		require.Equal(t, 2, handled)
+		require.True(t, readSize <= limit)
+
+		var e errors.Error
+		require.NoError(t, json.Unmarshal(b, &e))
+		require.Equal(t, errors.MessageTooLarge.Code, e.Code)
+	}
+
+	{ // 0 means no limit
+		router := mux.NewRouter()
+		router.Use(MaxMessageSizeMiddleware(nil, 0))
+		router.HandleFunc(handlerURL, http.HandlerFunc(handler)).Methods("POST")
+		ts := httptest.NewServer(router)
+		defer ts.Close()
+
+		resp, err := ts.Client().Post(ts.URL+handlerURL, "application/json", bytes.NewReader(make([]byte, limit*2)))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, limit*2, readSize)
+	}
+}
@@ -39,6 +39,12 @@ type Problem struct {
 	//occurrence of the problem.  It may or may not yield further
 	//information if dereferenced.
 	Instance string `json:"instance,omitempty"`
+
+	// "code" and "field" are the extension members from `errors.Envelope`;
+	// "code" is the stable code of `errors.Error` and "field" is the field,
+	// which causes the problem.
+	Code  uint   `json:"code,omitempty"`
+	Field string `json:"field,omitempty"`
 }
 
 func NewProblem(problemType string, title string) Problem {
@@ -64,6 +70,10 @@ func NewErrorProblem(err error, status int) Problem {
 		if detail, ok := e.Data["error"]; ok {
 			p.Detail = detail.(string)
 		}
+
+		envelope := errors.NewEnvelope(e)
+		p.Code = envelope.Code
+		p.Field = envelope.Field
 	} else {
 		p = NewProblem(HttpProblemDefaultType, err.Error())
 	}
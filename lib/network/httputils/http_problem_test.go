@@ -125,6 +125,8 @@ func TestProblem(t *testing.T) {
 			require.Equal(t, float64(p.Status), m["status"])
 			require.Empty(t, m["detail"])
 			require.Empty(t, m["instance"])
+			require.Equal(t, float64(errors.InvalidOperation.Code), m["code"])
+			require.Equal(t, "operations", m["field"])
 		}
 	}
 }
@@ -22,6 +22,9 @@ var (
 		errors.TooManyRequests.Code:               http.StatusTooManyRequests,
 		errors.BlockTransactionDoesNotExists.Code: http.StatusNotFound,
 		errors.BlockAccountDoesNotExists.Code:     http.StatusNotFound,
+		errors.TransactionInclusionTimeout.Code:   http.StatusRequestTimeout,
+		errors.MessageTooLarge.Code:               http.StatusRequestEntityTooLarge,
+		errors.IdempotencyKeyConflict.Code:        http.StatusConflict,
 	}
 )
 
@@ -20,6 +20,10 @@ type MemoryNetwork struct {
 	peers map[ /* endpoint */ string]*MemoryNetwork
 
 	messageBroker MessageBroker
+
+	// router serves the requests of `MemoryTransportClient`, which are not
+	// sent as message, like `GetBlockStream()`.
+	router *mux.Router
 }
 
 func (t *MemoryNetwork) GetClient(endpoint *common.Endpoint) NetworkClient {
@@ -118,6 +122,7 @@ func (prev *MemoryNetwork) NewMemoryNetwork() *MemoryNetwork {
 		receiveChannel: make(chan common.NetworkMessage),
 		close:          make(chan bool),
 		peers:          peers,
+		router:         mux.NewRouter(),
 	}
 
 	n.peers[n.endpoint.String()] = n
@@ -125,8 +130,8 @@ func (prev *MemoryNetwork) NewMemoryNetwork() *MemoryNetwork {
 	return n
 }
 
-func (p *MemoryNetwork) AddHandler(string, http.HandlerFunc) *mux.Route {
-	return &mux.Route{}
+func (p *MemoryNetwork) AddHandler(pattern string, handler http.HandlerFunc) *mux.Route {
+	return p.router.HandleFunc(pattern, handler)
 }
 
 func (p *MemoryNetwork) AddMiddleware(string, ...mux.MiddlewareFunc) error {
@@ -1,6 +1,13 @@
 package network
 
 import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/node"
@@ -56,3 +63,72 @@ func (m *MemoryTransportClient) SendBallot(message common.Serializable) (body []
 func (m *MemoryTransportClient) GetTransactions([]string) ([]byte, error) {
 	return []byte{}, errors.NotImplemented
 }
+
+// GetBlockStream serves the request by the handlers of the server without
+// the actual connection; the response is streamed through the pipe.
+func (m *MemoryTransportClient) GetBlockStream(from, to uint64) (body io.ReadCloser, err error) {
+	u := &url.URL{
+		Path: UrlPathPrefixNode + "/blocks/stream",
+		RawQuery: url.Values{
+			"from": []string{strconv.FormatUint(from, 10)},
+			"to":   []string{strconv.FormatUint(to, 10)},
+		}.Encode(),
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequest("GET", u.String(), nil); err != nil {
+		return
+	}
+
+	pr, pw := io.Pipe()
+	w := newMemoryResponseWriter(pw)
+	go func() {
+		m.server.router.ServeHTTP(w, request)
+		w.WriteHeader(http.StatusOK)
+		pw.Close()
+	}()
+
+	if status := <-w.status; status != http.StatusOK {
+		ioutil.ReadAll(pr)
+		pr.Close()
+		err = errors.HTTPProblem.Clone().SetData("status", status)
+		return
+	}
+
+	body = pr
+	return
+}
+
+// memoryResponseWriter is the `http.ResponseWriter` of
+// `MemoryTransportClient`; the status is sent to `status` at the first write.
+type memoryResponseWriter struct {
+	header http.Header
+	writer io.Writer
+	once   sync.Once
+	status chan int
+}
+
+func newMemoryResponseWriter(writer io.Writer) *memoryResponseWriter {
+	return &memoryResponseWriter{
+		header: http.Header{},
+		writer: writer,
+		status: make(chan int, 1),
+	}
+}
+
+func (w *memoryResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *memoryResponseWriter) WriteHeader(status int) {
+	w.once.Do(func() {
+		w.status <- status
+	})
+}
+
+func (w *memoryResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.writer.Write(b)
+}
+
+func (w *memoryResponseWriter) Flush() {}
@@ -1,7 +1,9 @@
 package network
 
 import (
+	"encoding/json"
 	"errors"
+	"math/rand"
 	"net"
 	"net/http"
 	"sync"
@@ -25,9 +27,44 @@ type ValidatorConnectionManager struct {
 	clients    map[ /* node.Address() */ string]NetworkClient
 	connected  map[ /* node.Address() */ string]bool
 
+	// fanout is the number of validators, which the non-ballot message is
+	// sent to at once; `0` sends to all the validators. The message is kept
+	// in `gossips` and sent to the rest of validators by `reconcile()`.
+	fanout  int
+	gossips map[ /* common.Message.GetHash() */ string]*gossip
+
+	reconnectPolicy common.ReconnectPolicy
+	stateCallbacks  []ConnectionStateCallback
+	sleep           func(time.Duration)
+
+	// maxClockSkew is the allowed difference between the clocks of the local
+	// node and the validator; the validators over it are kept in
+	// `clockSkews`. See `SetMaxClockSkew()`.
+	maxClockSkew time.Duration
+	clockSkews   map[ /* node.Address() */ string]time.Duration
+	now          func() time.Time
+
 	log logging.Logger
 }
 
+// ConnectionStateCallback is called when the validator is newly connected or
+// disconnected; see `ValidatorConnectionManager.AddConnectionStateCallback()`.
+type ConnectionStateCallback func(v *node.Validator, connected bool)
+
+// GossipReconcileInterval is the interval to send the gossips to the rest of
+// validators; see `ValidatorConnectionManager.SetFanout()`.
+var GossipReconcileInterval = 1 * time.Second
+
+// GossipExpire is the duration to keep the gossip; after it the gossip is not
+// sent anymore, even if some validators do not receive it.
+var GossipExpire = 1 * time.Minute
+
+type gossip struct {
+	message common.Message
+	sent    map[ /* node.Address() */ string]bool
+	created time.Time
+}
+
 func NewValidatorConnectionManager(
 	localNode *node.LocalNode,
 	network Network,
@@ -43,9 +80,16 @@ func NewValidatorConnectionManager(
 		policy:     policy,
 		validators: localNode.GetValidators(),
 
-		clients:   map[string]NetworkClient{},
-		connected: map[string]bool{},
-		log:       log.New(logging.Ctx{"node": localNode.Alias()}),
+		clients:    map[string]NetworkClient{},
+		connected:  map[string]bool{},
+		gossips:    map[string]*gossip{},
+		clockSkews: map[string]time.Duration{},
+		log:        log.New(logging.Ctx{"node": localNode.Alias()}),
+
+		reconnectPolicy: common.DefaultReconnectPolicy,
+		sleep:           time.Sleep,
+		maxClockSkew:    common.DefaultMaxClockSkew,
+		now:             time.Now,
 	}
 	cm.connected[localNode.Address()] = true
 
@@ -79,6 +123,60 @@ func (c *ValidatorConnectionManager) GetConnection(address string) (client Netwo
 	return
 }
 
+// SetFanout sets the number of validators, which the message except ballot
+// is sent to at once; `0` sends to all the validators. The ballot is
+// consensus-critical, so it is always sent to all the validators.
+func (c *ValidatorConnectionManager) SetFanout(fanout int) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.fanout = fanout
+}
+
+// SetReconnectPolicy sets the policy to connect the validators again; it
+// should be set before `Start()`.
+func (c *ValidatorConnectionManager) SetReconnectPolicy(policy common.ReconnectPolicy) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.reconnectPolicy = policy
+}
+
+// SetMaxClockSkew sets the allowed difference between the clocks of the local
+// node and the validators; `0` disables the check. It should be set before
+// `Start()`.
+func (c *ValidatorConnectionManager) SetMaxClockSkew(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.maxClockSkew = d
+}
+
+// SkewedValidators returns the clock skews of the validators, which are over
+// the `maxClockSkew` at the last connection; the positive skew means the clock
+// of validator is ahead.
+func (c *ValidatorConnectionManager) SkewedValidators() map[string]time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+
+	skews := map[string]time.Duration{}
+	for address, skew := range c.clockSkews {
+		skews[address] = skew
+	}
+
+	return skews
+}
+
+// AddConnectionStateCallback adds the callback, which is called when the
+// validator is newly connected or disconnected. The callback is called in the
+// goroutine of the validator, so it should not block.
+func (c *ValidatorConnectionManager) AddConnectionStateCallback(f ConnectionStateCallback) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.stateCallbacks = append(c.stateCallbacks, f)
+}
+
 func (c *ValidatorConnectionManager) Start() {
 	c.log.Debug("starting to connect to validators", "validators", c.validators)
 	for _, v := range c.validators {
@@ -87,6 +185,40 @@ func (c *ValidatorConnectionManager) Start() {
 		}
 		go c.connectingValidator(v)
 	}
+
+	go func() {
+		ticker := time.NewTicker(GossipReconcileInterval)
+		for _ = range ticker.C {
+			c.reconcile()
+		}
+	}()
+}
+
+// AddValidator adds the validator to the validator set and starts to connect
+// it; see `RemoveValidator()`.
+func (c *ValidatorConnectionManager) AddValidator(v *node.Validator) {
+	if c.localNode.HasValidators(v.Address()) {
+		return
+	}
+	c.localNode.AddValidators(v)
+
+	if v.Address() != c.localNode.Address() {
+		go c.connectingValidator(v)
+	}
+}
+
+// RemoveValidator removes the validator from the validator set; the
+// connecting goroutine of it stops at the next try.
+func (c *ValidatorConnectionManager) RemoveValidator(address string) {
+	c.localNode.RemoveValidators(address)
+
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.clients, address)
+	delete(c.connected, address)
+	delete(c.clockSkews, address)
+	c.policy.SetConnected(c.countConnectedUnlocked())
 }
 
 // setConnected returns `true` when the validator is newly connected or
@@ -95,6 +227,11 @@ func (c *ValidatorConnectionManager) setConnected(v *node.Validator, connected b
 	c.Lock()
 	defer c.Unlock()
 
+	// the validator is removed while connecting
+	if _, found := c.validators[v.Address()]; !found {
+		return false
+	}
+
 	old, found := c.connected[v.Address()]
 	c.connected[v.Address()] = connected
 
@@ -146,10 +283,27 @@ func (c *ValidatorConnectionManager) countConnectedUnlocked() int {
 	return count
 }
 
+// connectingValidator keeps checking the connection of the validator by
+// `common.ReconnectPolicy`; while the validator fails, the interval is
+// increased and after the `MaxRetries` failures, it stops.
 func (c *ValidatorConnectionManager) connectingValidator(v *node.Validator) {
-	ticker := time.NewTicker(time.Second * 1)
-	for _ = range ticker.C {
+	c.RLock()
+	policy := c.reconnectPolicy
+	c.RUnlock()
+
+	var failures uint64
+	for {
+		if !c.localNode.HasValidators(v.Address()) {
+			c.log.Debug("stop to connect removed validator", "validator", v)
+			return
+		}
+
 		err := c.connectValidator(v)
+		if err == nil {
+			failures = 0
+		} else {
+			failures++
+		}
 
 		if c.setConnected(v, err == nil) {
 			if err == nil {
@@ -157,20 +311,34 @@ func (c *ValidatorConnectionManager) connectingValidator(v *node.Validator) {
 			} else {
 				c.log.Debug("validator is disconnected", "validator", v, "error", err)
 			}
+
+			c.RLock()
+			callbacks := c.stateCallbacks
+			c.RUnlock()
+			for _, f := range callbacks {
+				f(v, err == nil)
+			}
 		}
-	}
 
-	return
+		if policy.Exhausted(failures) {
+			c.log.Warn("stop to connect validator", "validator", v, "failures", failures)
+			return
+		}
+
+		c.sleep(policy.Backoff(failures))
+	}
 }
 
 func (c *ValidatorConnectionManager) connectValidator(v *node.Validator) (err error) {
 	client := c.GetConnection(v.Address())
 
 	var b []byte
+	sent := c.now()
 	b, err = client.Connect(c.localNode)
 	if err != nil {
 		return
 	}
+	received := c.now()
 
 	// load and check validator info; addresses are same?
 	var validator *node.Validator
@@ -183,47 +351,143 @@ func (c *ValidatorConnectionManager) connectValidator(v *node.Validator) (err er
 		return
 	}
 
+	// the time of validator is compared with the middle of the request
+	c.checkClockSkew(v, b, sent.Add(received.Sub(sent)/2))
+
 	return
 }
 
+// checkClockSkew compares the time in the connect response of the validator
+// with the local time. The skewed validator is only reported, not refused;
+// the consensus still works with the skewed clocks under
+// `common.Config.BallotProposedTimeTolerance`, so it should not split the
+// network.
+func (c *ValidatorConnectionManager) checkClockSkew(v *node.Validator, b []byte, local time.Time) {
+	var info struct {
+		Time string `json:"time"`
+	}
+	// the node of the previous version does not send the time
+	if err := json.Unmarshal(b, &info); err != nil || len(info.Time) < 1 {
+		return
+	}
+	remote, err := common.ParseISO8601(info.Time)
+	if err != nil {
+		return
+	}
+	skew := remote.Sub(local)
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.maxClockSkew < 1 || (skew <= c.maxClockSkew && skew >= -c.maxClockSkew) {
+		delete(c.clockSkews, v.Address())
+		return
+	}
+
+	if _, found := c.clockSkews[v.Address()]; !found {
+		c.log.Warn("clock of validator is skewed", "validator", v, "skew", skew, "max", c.maxClockSkew)
+	}
+	c.clockSkews[v.Address()] = skew
+}
+
 func (c *ValidatorConnectionManager) ConnectionWatcher(t Network, conn net.Conn, state http.ConnState) {
 	return
 }
 
 func (c *ValidatorConnectionManager) Broadcast(message common.Message) {
+	if message.GetType() != common.BallotMessage {
+		c.Lock()
+		fanout := c.fanout
+		if fanout > 0 {
+			g := &gossip{message: message, sent: map[string]bool{}, created: time.Now()}
+			c.gossips[message.GetHash()] = g
+			c.sendGossip(g)
+		}
+		c.Unlock()
+
+		if fanout > 0 {
+			return
+		}
+	}
+
 	c.RLock()
 	defer c.RUnlock()
 	for addr, connected := range c.connected {
 		if connected {
-			go func(v *node.Validator) {
-				client := c.GetConnection(v.Address())
-
-				var err error
-				var response []byte
-				if message.GetType() == common.BallotMessage {
-					response, err = client.SendBallot(message)
-				} else if message.GetType() == common.TransactionMessage {
-					response, err = client.SendMessage(message)
-				} else {
-					panic("invalid message")
-				}
-
-				if err != nil {
-					c.log.Error(
-						"failed to broadcast",
-						"error", err,
-						"validator", v,
-						"type", message.GetType(),
-						"message", message.GetHash(),
-						"response", string(response),
-					)
-				}
-			}(c.validators[addr])
+			go c.send(c.validators[addr], message)
 		}
 	}
 	return
 }
 
+// sendGossip sends the gossip to the `fanout` number of connected validators,
+// which did not receive it yet; if all the validators receive it, it is
+// removed.
+func (c *ValidatorConnectionManager) sendGossip(g *gossip) {
+	var targets []string
+	for addr, connected := range c.connected {
+		if !connected || addr == c.localNode.Address() || g.sent[addr] {
+			continue
+		}
+		targets = append(targets, addr)
+	}
+
+	for i, j := range rand.Perm(len(targets)) {
+		if i >= c.fanout {
+			break
+		}
+		addr := targets[j]
+		g.sent[addr] = true
+		go c.send(c.validators[addr], g.message)
+	}
+
+	for addr := range c.validators {
+		if addr != c.localNode.Address() && !g.sent[addr] {
+			return
+		}
+	}
+	delete(c.gossips, g.message.GetHash())
+}
+
+// reconcile sends the gossips to the rest of validators.
+func (c *ValidatorConnectionManager) reconcile() {
+	c.Lock()
+	defer c.Unlock()
+
+	for hash, g := range c.gossips {
+		if time.Since(g.created) > GossipExpire {
+			delete(c.gossips, hash)
+			continue
+		}
+		c.sendGossip(g)
+	}
+}
+
+func (c *ValidatorConnectionManager) send(v *node.Validator, message common.Message) {
+	client := c.GetConnection(v.Address())
+
+	var err error
+	var response []byte
+	if message.GetType() == common.BallotMessage {
+		response, err = client.SendBallot(message)
+	} else if message.GetType() == common.TransactionMessage {
+		response, err = client.SendMessage(message)
+	} else {
+		panic("invalid message")
+	}
+
+	if err != nil {
+		c.log.Error(
+			"failed to broadcast",
+			"error", err,
+			"validator", v,
+			"type", message.GetType(),
+			"message", message.GetHash(),
+			"response", string(response),
+		)
+	}
+}
+
 func (c *ValidatorConnectionManager) GetNode(address string) node.Node {
 	c.RLock()
 	defer c.RUnlock()
@@ -0,0 +1,354 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/voting"
+)
+
+type gossipTestMessage struct {
+	common.Message
+	t    common.MessageType
+	hash string
+}
+
+func (m gossipTestMessage) GetType() common.MessageType { return m.t }
+func (m gossipTestMessage) GetHash() string             { return m.hash }
+
+// gossipTestTopology records the messages received by the endpoints.
+type gossipTestTopology struct {
+	Network
+	sync.Mutex
+	received map[ /* endpoint */ string][]string
+}
+
+func (n *gossipTestTopology) GetClient(endpoint *common.Endpoint) NetworkClient {
+	return &gossipTestClient{topology: n, endpoint: endpoint}
+}
+
+func (n *gossipTestTopology) receive(endpoint *common.Endpoint, message common.Serializable) {
+	n.Lock()
+	defer n.Unlock()
+
+	hash := message.(common.Message).GetHash()
+	n.received[endpoint.String()] = append(n.received[endpoint.String()], hash)
+}
+
+// countReceived returns the number of the endpoints, which received the
+// message.
+func (n *gossipTestTopology) countReceived(hash string) (count int) {
+	n.Lock()
+	defer n.Unlock()
+
+	for _, hashes := range n.received {
+		for _, h := range hashes {
+			if h == hash {
+				count++
+			}
+		}
+	}
+
+	return
+}
+
+func (n *gossipTestTopology) waitReceived(hash string, expected int) int {
+	for i := 0; i < 100; i++ {
+		if n.countReceived(hash) >= expected {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return n.countReceived(hash)
+}
+
+type gossipTestClient struct {
+	NetworkClient
+	topology *gossipTestTopology
+	endpoint *common.Endpoint
+}
+
+func (c *gossipTestClient) SendMessage(message common.Serializable) ([]byte, error) {
+	c.topology.receive(c.endpoint, message)
+	return nil, nil
+}
+
+func (c *gossipTestClient) SendBallot(message common.Serializable) ([]byte, error) {
+	c.topology.receive(c.endpoint, message)
+	return nil, nil
+}
+
+func makeGossipTestConnectionManager(t *testing.T, n int) (*ValidatorConnectionManager, *gossipTestTopology) {
+	topology := &gossipTestTopology{received: map[string][]string{}}
+
+	var validators []*node.Validator
+	for i := 0; i < n; i++ {
+		endpoint, _ := common.NewEndpointFromString(fmt.Sprintf("https://localhost:%d", 10000+i))
+		v, err := node.NewValidator(keypair.Random().Address(), endpoint, "")
+		require.NoError(t, err)
+		validators = append(validators, v)
+	}
+
+	localEndpoint, _ := common.NewEndpointFromString("https://localhost:9999")
+	localNode, err := node.NewLocalNode(keypair.Random(), localEndpoint, "")
+	require.NoError(t, err)
+	localNode.AddValidators(localNode.ConvertToValidator())
+	localNode.AddValidators(validators...)
+
+	cm := NewValidatorConnectionManager(localNode, topology, nil).(*ValidatorConnectionManager)
+	for _, v := range validators {
+		cm.connected[v.Address()] = true
+	}
+
+	return cm, topology
+}
+
+func TestValidatorConnectionManagerGossipFanout(t *testing.T) {
+	cm, topology := makeGossipTestConnectionManager(t, 10)
+	cm.SetFanout(3)
+
+	message := gossipTestMessage{t: common.TransactionMessage, hash: "showme"}
+	cm.Broadcast(message)
+
+	// only fanout
+	require.Equal(t, 3, topology.waitReceived(message.hash, 3))
+	require.Equal(t, 1, len(cm.gossips))
+
+	// reconciled, until everyone receives it
+	for i := 0; i < 2; i++ {
+		cm.reconcile()
+		require.Equal(t, 3*(i+2), topology.waitReceived(message.hash, 3*(i+2)))
+	}
+	cm.reconcile()
+	require.Equal(t, 10, topology.waitReceived(message.hash, 10))
+	require.Equal(t, 0, len(cm.gossips))
+
+	// no duplicated delivery
+	cm.reconcile()
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, 10, topology.countReceived(message.hash))
+	for _, hashes := range topology.received {
+		require.Equal(t, 1, len(hashes))
+	}
+}
+
+func TestValidatorConnectionManagerGossipBallot(t *testing.T) {
+	cm, topology := makeGossipTestConnectionManager(t, 10)
+	cm.SetFanout(3)
+
+	// ballot is sent to all the validators including the local node
+	message := gossipTestMessage{t: common.BallotMessage, hash: "showme"}
+	cm.Broadcast(message)
+
+	require.Equal(t, 11, topology.waitReceived(message.hash, 11))
+	require.Equal(t, 0, len(cm.gossips))
+}
+
+func TestValidatorConnectionManagerGossipDisconnected(t *testing.T) {
+	cm, topology := makeGossipTestConnectionManager(t, 4)
+	cm.SetFanout(1)
+
+	var disconnected string
+	for addr := range cm.validators {
+		if addr != cm.localNode.Address() {
+			disconnected = addr
+			break
+		}
+	}
+	cm.connected[disconnected] = false
+
+	message := gossipTestMessage{t: common.TransactionMessage, hash: "showme"}
+	cm.Broadcast(message)
+	for i := 0; i < 5; i++ {
+		cm.reconcile()
+	}
+	require.Equal(t, 3, topology.waitReceived(message.hash, 3))
+	require.Equal(t, 1, len(cm.gossips))
+
+	// connected again
+	cm.connected[disconnected] = true
+	cm.reconcile()
+	require.Equal(t, 4, topology.waitReceived(message.hash, 4))
+	require.Equal(t, 0, len(cm.gossips))
+
+	{ // expired
+		cm.connected[disconnected] = false
+		message := gossipTestMessage{t: common.TransactionMessage, hash: "findme"}
+		cm.Broadcast(message)
+		cm.gossips[message.hash].created = time.Now().Add(-GossipExpire - time.Second)
+		cm.reconcile()
+		require.Equal(t, 0, len(cm.gossips))
+	}
+}
+
+type reconnectTestPolicy struct {
+	voting.ThresholdPolicy
+	connected int
+}
+
+func (p *reconnectTestPolicy) SetConnected(n int) error {
+	p.connected = n
+	return nil
+}
+
+// reconnectTestNetwork returns the client, which succeeds or fails to connect
+// by `results` in order; after `results`, it always fails.
+type reconnectTestNetwork struct {
+	Network
+	validator *node.Validator
+	results   []bool
+}
+
+func (n *reconnectTestNetwork) GetClient(endpoint *common.Endpoint) NetworkClient {
+	return &reconnectTestClient{network: n}
+}
+
+type reconnectTestClient struct {
+	NetworkClient
+	network *reconnectTestNetwork
+}
+
+func (c *reconnectTestClient) Connect(node.Node) ([]byte, error) {
+	if len(c.network.results) < 1 || !c.network.results[0] {
+		if len(c.network.results) > 0 {
+			c.network.results = c.network.results[1:]
+		}
+		return nil, fmt.Errorf("connection refused")
+	}
+
+	c.network.results = c.network.results[1:]
+	return c.network.validator.Serialize()
+}
+
+func TestValidatorConnectionManagerReconnect(t *testing.T) {
+	endpoint, _ := common.NewEndpointFromString("https://localhost:10000")
+	validator, err := node.NewValidator(keypair.Random().Address(), endpoint, "")
+	require.NoError(t, err)
+
+	localEndpoint, _ := common.NewEndpointFromString("https://localhost:9999")
+	localNode, err := node.NewLocalNode(keypair.Random(), localEndpoint, "")
+	require.NoError(t, err)
+	localNode.AddValidators(localNode.ConvertToValidator(), validator)
+
+	// flapping validator
+	nt := &reconnectTestNetwork{
+		validator: validator,
+		results:   []bool{true, false, false, true, false, false},
+	}
+	policy := &reconnectTestPolicy{}
+	cm := NewValidatorConnectionManager(localNode, nt, policy).(*ValidatorConnectionManager)
+	cm.SetReconnectPolicy(common.ReconnectPolicy{
+		MinInterval: 1 * time.Second,
+		MaxInterval: 3 * time.Second,
+		MaxRetries:  3,
+	})
+
+	var sleeps []time.Duration
+	cm.sleep = func(d time.Duration) {
+		sleeps = append(sleeps, d)
+	}
+
+	var states []bool
+	cm.AddConnectionStateCallback(func(v *node.Validator, connected bool) {
+		require.Equal(t, validator.Address(), v.Address())
+		states = append(states, connected)
+	})
+
+	// it returns after `MaxRetries` consecutive failures
+	cm.connectingValidator(validator)
+
+	require.Equal(
+		t,
+		[]time.Duration{
+			1 * time.Second, // connected
+			2 * time.Second, // 1st failure
+			3 * time.Second, // 2nd failure, limited by `MaxInterval`
+			1 * time.Second, // connected again, reset
+			2 * time.Second,
+			3 * time.Second,
+		},
+		sleeps,
+	)
+	require.Equal(t, []bool{true, false, true, false}, states)
+
+	require.Equal(t, 1, cm.CountConnected())
+	require.Equal(t, 1, policy.connected)
+}
+
+// clockSkewTestClient responds to connect with the time of the validator,
+// which is ahead of the local clock by `skew`.
+type clockSkewTestClient struct {
+	NetworkClient
+	validator *node.Validator
+	now       time.Time
+	skew      time.Duration
+}
+
+func (c *clockSkewTestClient) Connect(node.Node) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"address":  c.validator.Address(),
+		"alias":    c.validator.Alias(),
+		"endpoint": c.validator.Endpoint(),
+		"time":     common.FormatISO8601(c.now.Add(c.skew)),
+	})
+}
+
+type clockSkewTestNetwork struct {
+	Network
+	client *clockSkewTestClient
+}
+
+func (n *clockSkewTestNetwork) GetClient(*common.Endpoint) NetworkClient {
+	return n.client
+}
+
+func TestValidatorConnectionManagerClockSkew(t *testing.T) {
+	endpoint, _ := common.NewEndpointFromString("https://localhost:10000")
+	validator, err := node.NewValidator(keypair.Random().Address(), endpoint, "")
+	require.NoError(t, err)
+
+	localEndpoint, _ := common.NewEndpointFromString("https://localhost:9999")
+	localNode, err := node.NewLocalNode(keypair.Random(), localEndpoint, "")
+	require.NoError(t, err)
+	localNode.AddValidators(localNode.ConvertToValidator(), validator)
+
+	now := time.Now()
+	client := &clockSkewTestClient{validator: validator, now: now}
+	cm := NewValidatorConnectionManager(localNode, &clockSkewTestNetwork{client: client}, &reconnectTestPolicy{}).(*ValidatorConnectionManager)
+	cm.SetMaxClockSkew(10 * time.Second)
+	cm.now = func() time.Time { return now }
+
+	{ // within the bound
+		client.skew = 5 * time.Second
+		require.NoError(t, cm.connectValidator(validator))
+		require.Equal(t, 0, len(cm.SkewedValidators()))
+	}
+
+	{ // large skew is flagged, but still connected
+		client.skew = -time.Minute
+		require.NoError(t, cm.connectValidator(validator))
+		require.Equal(t, map[string]time.Duration{validator.Address(): -time.Minute}, cm.SkewedValidators())
+	}
+
+	{ // synced again
+		client.skew = 0
+		require.NoError(t, cm.connectValidator(validator))
+		require.Equal(t, 0, len(cm.SkewedValidators()))
+	}
+
+	{ // disabled
+		cm.SetMaxClockSkew(0)
+		client.skew = time.Hour
+		require.NoError(t, cm.connectValidator(validator))
+		require.Equal(t, 0, len(cm.SkewedValidators()))
+	}
+}
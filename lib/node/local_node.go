@@ -128,6 +128,15 @@ func (n *LocalNode) AddValidators(validators ...*Validator) error {
 	return nil
 }
 
+func (n *LocalNode) RemoveValidators(addresses ...string) {
+	n.Lock()
+	defer n.Unlock()
+
+	for _, address := range addresses {
+		delete(n.validators, address)
+	}
+}
+
 func (n *LocalNode) MarshalJSON() ([]byte, error) {
 	return json.Marshal(map[string]interface{}{
 		"address":    n.Address(),
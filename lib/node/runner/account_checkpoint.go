@@ -0,0 +1,50 @@
+package runner
+
+import (
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// SaveBlockAccountCheckpoint saves the account-state checkpoint of `blk`, if
+// the height of `blk` is the multiple of `interval`; `0` does nothing. It
+// must be called right after `blk` is committed. Only the snapshot of the
+// storage is taken here and the accounts are copied from it in background, so
+// the next block can be stored without waiting for the checkpoint.
+func SaveBlockAccountCheckpoint(st *storage.LevelDBBackend, blk block.Block, interval uint64, log logging.Logger) (err error) {
+	if interval < 1 || blk.Height%interval != 0 {
+		return
+	}
+
+	var snapshot *storage.LevelDBBackend
+	if snapshot, err = st.OpenSnapshot(); err != nil {
+		return
+	}
+
+	go func() {
+		defer snapshot.Release()
+
+		bs, err := st.OpenBatch()
+		if err != nil {
+			log.Error("failed to open batch for account checkpoint", "height", blk.Height, "error", err)
+			return
+		}
+
+		cp, err := block.SaveBlockAccountCheckpoint(snapshot, bs, blk)
+		if err != nil {
+			bs.Discard()
+			log.Error("failed to save account checkpoint", "height", blk.Height, "error", err)
+			return
+		}
+		if err = bs.Commit(); err != nil {
+			bs.Discard()
+			log.Error("failed to save account checkpoint", "height", blk.Height, "error", err)
+			return
+		}
+
+		log.Debug("account checkpoint saved", "checkpoint", cp)
+	}()
+
+	return
+}
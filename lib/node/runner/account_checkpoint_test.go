@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestSaveBlockAccountCheckpoint(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	genesis := block.GetLatestBlock(st)
+
+	{ // not the multiple of interval
+		require.NoError(t, SaveBlockAccountCheckpoint(st, genesis, 2, log))
+		require.NoError(t, SaveBlockAccountCheckpoint(st, genesis, 0, log))
+		time.Sleep(100 * time.Millisecond)
+
+		_, err := block.GetLatestBlockAccountCheckpoint(st)
+		require.Equal(t, errors.StorageRecordDoesNotExist, err)
+	}
+
+	expected, err := block.MakeBlockAccountStateHash(st)
+	require.NoError(t, err)
+
+	require.NoError(t, SaveBlockAccountCheckpoint(st, genesis, 1, log))
+
+	// the account saved after the block is not in the checkpoint
+	require.NoError(t, block.TestMakeBlockAccount().Save(st))
+
+	var cp block.BlockAccountCheckpoint
+	for i := 0; i < 50; i++ {
+		if cp, err = block.GetBlockAccountCheckpoint(st, genesis.Height); err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	require.Equal(t, genesis.Hash, cp.BlockHash)
+	require.Equal(t, expected, cp.Hash)
+	require.NoError(t, cp.Verify(st))
+}
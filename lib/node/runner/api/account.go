@@ -29,7 +29,11 @@ func (api NetworkHandlerAPI) GetAccountHandler(w http.ResponseWriter, r *http.Re
 		if err != nil {
 			return nil, err
 		}
-		payload = resource.NewAccount(ba)
+		minimum, err := block.MinimumBalance(api.storage, ba)
+		if err != nil {
+			return nil, err
+		}
+		payload = resource.NewAccount(ba).SetMinimumBalance(minimum)
 		return payload, nil
 	}
 
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/keypair"
 	"boscoin.io/sebak/lib/common/observer"
 	"boscoin.io/sebak/lib/errors"
@@ -38,6 +39,24 @@ func TestGetAccountHandler(t *testing.T) {
 		json.Unmarshal(readByte, &recv)
 
 		require.Equal(t, ba.Address, recv["address"], "address is not same")
+		require.Equal(t, "0", recv["minimum_balance"])
+		require.Equal(t, ba.Balance.String(), recv["spendable_balance"])
+	}
+
+	{ // the account data decreases the spendable balance
+		require.NoError(t, block.SaveAccountData(storage, ba.Address, "showme", []byte("findme")))
+
+		url := strings.Replace(GetAccountHandlerPattern, "{id}", ba.Address, -1)
+		respBody := request(ts, url, false)
+		defer respBody.Close()
+
+		readByte, err := ioutil.ReadAll(respBody)
+		require.NoError(t, err)
+		recv := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(readByte, &recv))
+
+		require.Equal(t, common.EntryReserve.String(), recv["minimum_balance"])
+		require.Equal(t, ba.Balance.MustSub(common.EntryReserve).String(), recv["spendable_balance"])
 	}
 
 	{ // unknown address
@@ -3,13 +3,17 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/consensus"
 	"boscoin.io/sebak/lib/network"
 	"boscoin.io/sebak/lib/network/httputils"
 	"boscoin.io/sebak/lib/node"
 	"boscoin.io/sebak/lib/node/runner/api/resource"
 	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
 )
 
 const APIVersionV1 = "v1"
@@ -23,7 +27,15 @@ const (
 	GetTransactionByHashHandlerPattern     = "/transactions/{id}"
 	GetTransactionOperationsHandlerPattern = "/transactions/{id}/operations"
 	PostTransactionPattern                 = "/transactions"
+	PostTransactionsBatchPattern           = "/transactions/batch"
 	GetTransactionHistoryHandlerPattern    = "/transactions/{id}/history"
+	GetTransactionInclusionHandlerPattern  = "/transactions/{id}/inclusion"
+	GetTransactionDetailHandlerPattern     = "/transactions/{id}/detail"
+	GetBlockTimeStatisticsPattern          = "/blocks/time"
+	GetOperationsStreamPattern             = "/operations/stream"
+	GetHealthPattern                       = "/health"
+	GetTransactionPoolHandlerPattern       = "/transaction-pool"
+	GetValidatorChangesHandlerPattern      = "/validator-changes"
 	GetNodeInfoPattern                     = "/"
 )
 
@@ -35,6 +47,26 @@ type NetworkHandlerAPI struct {
 	version        string
 	nodeInfo       node.NodeInfo
 	GetLatestBlock func() block.Block
+
+	GetBlockTimeBuffer func() time.Duration
+
+	// hooks for `GetHealthHandler`
+	GetISAACState       func() consensus.ISAACState
+	SelectProposer      func(blockHeight uint64, round uint64) string
+	GetLastAllConfirmed func() time.Time
+	GetClockSkews       func() map[string]time.Duration
+	GetStopReason       func() (reason string, lastError error)
+	HealthStaleWindow   time.Duration
+
+	// TransactionPool is for `GetTransactionPoolHandler`
+	TransactionPool *transaction.Pool
+
+	// Idempotency is for `PostTransactionsHandler`; nil disables the
+	// idempotency key.
+	Idempotency *IdempotencyCache
+
+	// MaxTransactionsInBatch is for `PostTransactionsBatchHandler`
+	MaxTransactionsInBatch int
 }
 
 func NewNetworkHandlerAPI(localNode *node.LocalNode, network network.Network, storage *storage.LevelDBBackend, urlPrefix string, nodeInfo node.NodeInfo) *NetworkHandlerAPI {
@@ -45,6 +77,9 @@ func NewNetworkHandlerAPI(localNode *node.LocalNode, network network.Network, st
 		urlPrefix: urlPrefix,
 		version:   APIVersionV1,
 		nodeInfo:  nodeInfo,
+
+		HealthStaleWindow:      common.DefaultHealthStaleWindow,
+		MaxTransactionsInBatch: common.DefaultMaxTransactionsInBatch,
 	}
 }
 
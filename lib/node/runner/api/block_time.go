@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network/httputils"
+	"boscoin.io/sebak/lib/storage"
+)
+
+const (
+	DefaultRecentBlocks uint64 = 10
+	MaxRecentBlocks     uint64 = 1000
+)
+
+// BlockTimeStatistics is the statistics of block creation time.
+type BlockTimeStatistics struct {
+	Height        uint64        `json:"height"`         // height of latest block
+	Average       time.Duration `json:"average"`        // average since genesis
+	Recent        uint64        `json:"recent"`         // number of blocks for `RecentAverage`
+	RecentAverage time.Duration `json:"recent_average"` // average of the recent blocks
+	Buffer        time.Duration `json:"buffer"`         // current `blockTimeBuffer` of ISAACStateManager
+}
+
+// GetBlockTimeStatistics calculates the average block time until the latest
+// block. The genesis block is saved with the fixed confirmed time, so the
+// average since genesis starts from the timestamp of genesis block and the
+// recent average does not include the genesis block.
+func GetBlockTimeStatistics(st *storage.LevelDBBackend, latest block.Block, recent uint64) (stats BlockTimeStatistics, err error) {
+	stats.Height = latest.Height
+
+	height := latest.Height - common.GenesisBlockHeight
+	if height < 1 {
+		return
+	}
+
+	var confirmed time.Time
+	if confirmed, err = common.ParseISO8601(latest.Confirmed); err != nil {
+		return
+	}
+
+	var genesis block.Block
+	if genesis, err = block.GetBlockByHeight(st, common.GenesisBlockHeight); err != nil {
+		return
+	}
+	stats.Average = confirmed.Sub(genesis.Header.Timestamp) / time.Duration(height)
+
+	if recent > height-1 {
+		recent = height - 1
+	}
+	stats.Recent = recent
+	if recent < 1 {
+		return
+	}
+
+	var from block.Block
+	if from, err = block.GetBlockByHeight(st, latest.Height-recent); err != nil {
+		return
+	}
+	var fromConfirmed time.Time
+	if fromConfirmed, err = common.ParseISO8601(from.Confirmed); err != nil {
+		return
+	}
+	stats.RecentAverage = confirmed.Sub(fromConfirmed) / time.Duration(recent)
+
+	return
+}
+
+// GetBlockTimeStatisticsHandler returns `BlockTimeStatistics`. The number of
+// recent blocks can be set by `recent` query, which is `DefaultRecentBlocks`
+// by default.
+func (api NetworkHandlerAPI) GetBlockTimeStatisticsHandler(w http.ResponseWriter, r *http.Request) {
+	recent := DefaultRecentBlocks
+	if s := r.URL.Query().Get("recent"); len(s) > 0 {
+		var err error
+		if recent, err = strconv.ParseUint(s, 10, 64); err != nil || recent < 1 || recent > MaxRecentBlocks {
+			httputils.WriteJSONError(w, errors.InvalidQueryString)
+			return
+		}
+	}
+
+	var latest block.Block
+	if api.GetLatestBlock != nil {
+		latest = api.GetLatestBlock()
+	} else {
+		latest = block.GetLatestBlock(api.storage)
+	}
+
+	stats, err := GetBlockTimeStatistics(api.storage, latest, recent)
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+	if api.GetBlockTimeBuffer != nil {
+		stats.Buffer = api.GetBlockTimeBuffer()
+	}
+
+	httputils.MustWriteJSON(w, 200, stats)
+}
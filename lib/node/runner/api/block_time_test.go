@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+)
+
+func TestGetBlockTimeStatistics(t *testing.T) {
+	ts, storage := prepareAPIServer()
+	defer storage.Close()
+	defer ts.Close()
+
+	genesis := block.GetGenesis(storage)
+
+	{ // only genesis block
+		stats, err := GetBlockTimeStatistics(storage, genesis, DefaultRecentBlocks)
+		require.NoError(t, err)
+		require.Equal(t, BlockTimeStatistics{Height: genesis.Height}, stats)
+	}
+
+	// 5 blocks by 4 seconds and 5 blocks by 6 seconds
+	confirmed := genesis.Header.Timestamp
+	latest := genesis
+	for i := 0; i < 10; i++ {
+		if i < 5 {
+			confirmed = confirmed.Add(4 * time.Second)
+		} else {
+			confirmed = confirmed.Add(6 * time.Second)
+		}
+
+		latest = block.TestMakeNewBlockWithPrevBlock(latest, []string{})
+		latest.Confirmed = common.FormatISO8601(confirmed)
+		latest.MustSave(storage)
+	}
+
+	{
+		stats, err := GetBlockTimeStatistics(storage, latest, 5)
+		require.NoError(t, err)
+		require.Equal(t, latest.Height, stats.Height)
+		require.Equal(t, 5*time.Second, stats.Average)
+		require.Equal(t, uint64(5), stats.Recent)
+		require.Equal(t, 6*time.Second, stats.RecentAverage)
+	}
+
+	{ // recent blocks over the height; genesis block is excluded
+		stats, err := GetBlockTimeStatistics(storage, latest, 100)
+		require.NoError(t, err)
+		require.Equal(t, uint64(9), stats.Recent)
+		require.Equal(t, (50*time.Second-4*time.Second)/9, stats.RecentAverage)
+	}
+
+	apiHandler := NetworkHandlerAPI{
+		storage:            storage,
+		GetLatestBlock:     func() block.Block { return latest },
+		GetBlockTimeBuffer: func() time.Duration { return 3 * time.Second },
+	}
+	router := ts.Config.Handler.(*mux.Router)
+	router.HandleFunc(GetBlockTimeStatisticsPattern, apiHandler.GetBlockTimeStatisticsHandler).Methods("GET")
+
+	get := func(query string) *http.Response {
+		resp, err := ts.Client().Get(ts.URL + GetBlockTimeStatisticsPattern + query)
+		require.NoError(t, err)
+		return resp
+	}
+
+	{ // by API
+		resp := get("?recent=2")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var stats BlockTimeStatistics
+		require.NoError(t, json.Unmarshal(b, &stats))
+		require.Equal(t, 5*time.Second, stats.Average)
+		require.Equal(t, uint64(2), stats.Recent)
+		require.Equal(t, 6*time.Second, stats.RecentAverage)
+		require.Equal(t, 3*time.Second, stats.Buffer)
+	}
+
+	{ // default recent blocks
+		resp := get("")
+		defer resp.Body.Close()
+
+		var stats BlockTimeStatistics
+		b, _ := ioutil.ReadAll(resp.Body)
+		require.NoError(t, json.Unmarshal(b, &stats))
+		require.Equal(t, uint64(9), stats.Recent)
+	}
+
+	{ // invalid recent blocks
+		for _, q := range []string{"?recent=0", "?recent=-1", "?recent=findme", "?recent=1001"} {
+			resp := get(q)
+			resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode, q)
+		}
+	}
+}
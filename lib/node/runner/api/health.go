@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/network/httputils"
+)
+
+// Health reports whether the consensus of the node is progressing.
+type Health struct {
+	Healthy          bool          `json:"healthy"`
+	Height           uint64        `json:"height"`          // height of latest block
+	LastBlockTime    string        `json:"last_block_time"` // confirmed time of latest block
+	Round            uint64        `json:"round"`           // current round of ISAACState
+	BallotState      string        `json:"ballot_state"`    // current ballot state of ISAACState
+	IsProposer       bool          `json:"is_proposer"`     // whether the node is the proposer of current round
+	SinceLastConfirm time.Duration `json:"since_last_confirm"`
+
+	// ClockSkews is the clock skews of the validators over
+	// `common.Config.MaxClockSkew`; it does not affect `Healthy`.
+	ClockSkews map[string]time.Duration `json:"clock_skews,omitempty"`
+
+	// StopReason is why the consensus is stopped; the stopped node is not
+	// `Healthy`. LastError is the last error of proposing ballot.
+	StopReason string `json:"stop_reason,omitempty"`
+	LastError  string `json:"last_error,omitempty"`
+}
+
+func (api NetworkHandlerAPI) getHealth(now time.Time) (health Health, err error) {
+	var latest block.Block
+	if api.GetLatestBlock != nil {
+		latest = api.GetLatestBlock()
+	} else {
+		latest = block.GetLatestBlock(api.storage)
+	}
+	health.Height = latest.Height
+	health.LastBlockTime = latest.Confirmed
+
+	if api.GetISAACState != nil {
+		state := api.GetISAACState()
+		health.Round = state.Round
+		health.BallotState = state.BallotState.String()
+		if api.SelectProposer != nil {
+			health.IsProposer = api.SelectProposer(state.Height, state.Round) == api.localNode.Address()
+		}
+	}
+
+	// if no `ALLCONFIRM` happened since the node started, the confirmed time
+	// of latest block is used.
+	var confirmed time.Time
+	if api.GetLastAllConfirmed != nil {
+		confirmed = api.GetLastAllConfirmed()
+	}
+	if confirmed.IsZero() {
+		if confirmed, err = common.ParseISO8601(latest.Confirmed); err != nil {
+			return
+		}
+	}
+	health.SinceLastConfirm = now.Sub(confirmed)
+
+	if api.GetClockSkews != nil {
+		if skews := api.GetClockSkews(); len(skews) > 0 {
+			health.ClockSkews = skews
+		}
+	}
+	if api.GetStopReason != nil {
+		var lastError error
+		if health.StopReason, lastError = api.GetStopReason(); lastError != nil {
+			health.LastError = lastError.Error()
+		}
+	}
+	health.Healthy = len(health.StopReason) < 1 &&
+		(api.HealthStaleWindow < 1 || health.SinceLastConfirm <= api.HealthStaleWindow)
+
+	return
+}
+
+// GetHealthHandler returns `Health` of the node. If no block is confirmed
+// within `HealthStaleWindow`, it responds with 503.
+func (api NetworkHandlerAPI) GetHealthHandler(w http.ResponseWriter, r *http.Request) {
+	health, err := api.getHealth(time.Now())
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if !health.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+
+	httputils.MustWriteJSON(w, status, health)
+}
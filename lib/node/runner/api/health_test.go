@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/node"
+)
+
+func TestGetHealthHandler(t *testing.T) {
+	ts, storage := prepareAPIServer()
+	defer storage.Close()
+	defer ts.Close()
+
+	endpoint, _ := common.ParseEndpoint("http://1.2.3.4:5678")
+	localNode, _ := node.NewLocalNode(keypair.Random(), endpoint, "")
+
+	latest := block.TestMakeNewBlockWithPrevBlock(block.GetLatestBlock(storage), []string{})
+	latest.MustSave(storage)
+
+	var lastAllConfirmed time.Time
+	var clockSkews map[string]time.Duration
+	var stopReason string
+	var lastError error
+	apiHandler := NetworkHandlerAPI{
+		localNode:      localNode,
+		storage:        storage,
+		GetLatestBlock: func() block.Block { return latest },
+		GetISAACState: func() consensus.ISAACState {
+			return consensus.ISAACState{Height: latest.Height + 1, Round: 2, BallotState: ballot.StateSIGN}
+		},
+		SelectProposer: func(uint64, uint64) string {
+			return localNode.Address()
+		},
+		GetLastAllConfirmed: func() time.Time { return lastAllConfirmed },
+		GetClockSkews:       func() map[string]time.Duration { return clockSkews },
+		GetStopReason:       func() (string, error) { return stopReason, lastError },
+		HealthStaleWindow:   time.Minute,
+	}
+	router := ts.Config.Handler.(*mux.Router)
+	router.HandleFunc(GetHealthPattern, apiHandler.GetHealthHandler).Methods("GET")
+
+	get := func() (int, Health) {
+		resp, err := ts.Client().Get(ts.URL + GetHealthPattern)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var health Health
+		require.NoError(t, json.Unmarshal(b, &health))
+		return resp.StatusCode, health
+	}
+
+	{ // healthy
+		lastAllConfirmed = time.Now().Add(-10 * time.Second)
+		status, health := get()
+		require.Equal(t, http.StatusOK, status)
+		require.True(t, health.Healthy)
+		require.Equal(t, latest.Height, health.Height)
+		require.Equal(t, latest.Confirmed, health.LastBlockTime)
+		require.Equal(t, uint64(2), health.Round)
+		require.Equal(t, ballot.StateSIGN.String(), health.BallotState)
+		require.True(t, health.IsProposer)
+		require.True(t, health.SinceLastConfirm >= 10*time.Second)
+	}
+
+	{ // stalled
+		lastAllConfirmed = time.Now().Add(-2 * time.Minute)
+		status, health := get()
+		require.Equal(t, http.StatusServiceUnavailable, status)
+		require.False(t, health.Healthy)
+		require.True(t, health.SinceLastConfirm >= 2*time.Minute)
+	}
+
+	{ // no `ALLCONFIRM` since started; confirmed time of latest block is used
+		lastAllConfirmed = time.Time{}
+		confirmed, err := common.ParseISO8601(latest.Confirmed)
+		require.NoError(t, err)
+
+		health, err := apiHandler.getHealth(confirmed.Add(30 * time.Second))
+		require.NoError(t, err)
+		require.True(t, health.Healthy)
+		require.Equal(t, 30*time.Second, health.SinceLastConfirm)
+
+		health, err = apiHandler.getHealth(confirmed.Add(61 * time.Second))
+		require.NoError(t, err)
+		require.False(t, health.Healthy)
+	}
+
+	{ // skewed validators are reported, but still healthy
+		lastAllConfirmed = time.Now()
+		skewed := keypair.Random().Address()
+		clockSkews = map[string]time.Duration{skewed: -time.Minute}
+
+		status, health := get()
+		require.Equal(t, http.StatusOK, status)
+		require.True(t, health.Healthy)
+		require.Equal(t, map[string]time.Duration{skewed: -time.Minute}, health.ClockSkews)
+	}
+
+	{ // the last error is reported, but still healthy until stopped
+		lastAllConfirmed = time.Now()
+		lastError = errors.StorageCoreError
+
+		status, health := get()
+		require.Equal(t, http.StatusOK, status)
+		require.True(t, health.Healthy)
+		require.Empty(t, health.StopReason)
+		require.Equal(t, errors.StorageCoreError.Error(), health.LastError)
+
+		stopReason = "storage-error"
+		status, health = get()
+		require.Equal(t, http.StatusServiceUnavailable, status)
+		require.False(t, health.Healthy)
+		require.Equal(t, stopReason, health.StopReason)
+	}
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"boscoin.io/sebak/lib/errors"
+)
+
+// IdempotencyKeyHeader is the header of the key, which the client gives to
+// resubmit the transaction safely; see `IdempotencyCache`.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyEntry struct {
+	request [sha256.Size]byte
+	expires time.Time
+	done    chan struct{} // closed when `result` and `err` are set.
+	result  interface{}
+	err     error
+}
+
+// IdempotencyCache remembers the results of the transaction submissions by
+// the idempotency key of client for `window`. The keys are scoped by the
+// source of transaction, so the clients of the different sources can use the
+// same key. The resubmission with the same
+// key in the window gets the original result without being processed again,
+// and the concurrent one waits for the first. The failed submission is not
+// remembered, so it can be retried with the same key.
+//
+// The key can not be used for the different request; it fails with
+// `errors.IdempotencyKeyConflict`. Over `limit` keys, the oldest ones are
+// forgotten.
+type IdempotencyCache struct {
+	sync.Mutex
+
+	window  time.Duration
+	limit   int
+	entries map[string]*idempotencyEntry
+	keys    []string // in the order of added
+	now     func() time.Time
+}
+
+func NewIdempotencyCache(window time.Duration, limit int) *IdempotencyCache {
+	return &IdempotencyCache{
+		window:  window,
+		limit:   limit,
+		entries: map[string]*idempotencyEntry{},
+		now:     time.Now,
+	}
+}
+
+// Do calls `f` for the request of the key of the source only once in the
+// window and returns its result; `found` is true if the result is of the
+// former request.
+func (c *IdempotencyCache) Do(source, key string, request []byte, f func() (interface{}, error)) (result interface{}, found bool, err error) {
+	hashed := sha256.Sum256(request)
+	key = source + "|" + key
+
+	c.Lock()
+	c.expire()
+	if entry, ok := c.entries[key]; ok {
+		c.Unlock()
+		if entry.request != hashed {
+			err = errors.IdempotencyKeyConflict
+			return
+		}
+
+		<-entry.done
+		return entry.result, true, entry.err
+	}
+
+	entry := &idempotencyEntry{
+		request: hashed,
+		expires: c.now().Add(c.window),
+		done:    make(chan struct{}),
+	}
+	c.entries[key] = entry
+	c.keys = append(c.keys, key)
+	c.Unlock()
+
+	entry.result, entry.err = f()
+	close(entry.done)
+
+	if entry.err != nil {
+		c.Lock()
+		c.remove(key, entry)
+		c.Unlock()
+	}
+
+	return entry.result, false, entry.err
+}
+
+// Len returns the number of the remembered keys.
+func (c *IdempotencyCache) Len() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.entries)
+}
+
+// expire forgets the expired keys and the oldest keys over `limit`. The lock
+// must be held.
+func (c *IdempotencyCache) expire() {
+	now := c.now()
+
+	var i int
+	for ; i < len(c.keys); i++ {
+		entry, ok := c.entries[c.keys[i]]
+		if ok && !now.After(entry.expires) && (c.limit < 1 || len(c.keys)-i < c.limit) {
+			break
+		}
+		if ok {
+			delete(c.entries, c.keys[i])
+		}
+	}
+	c.keys = c.keys[i:]
+}
+
+// remove forgets the key, if it is still of the entry. The lock must be held.
+func (c *IdempotencyCache) remove(key string, entry *idempotencyEntry) {
+	if c.entries[key] != entry {
+		return
+	}
+	delete(c.entries, key)
+
+	for i, k := range c.keys {
+		if k == key {
+			c.keys = append(c.keys[:i], c.keys[i+1:]...)
+			break
+		}
+	}
+}
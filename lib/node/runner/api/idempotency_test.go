@@ -0,0 +1,223 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+func TestIdempotencyCache(t *testing.T) {
+	now := time.Now()
+	c := NewIdempotencyCache(time.Minute, 3)
+	c.now = func() time.Time { return now }
+
+	var calls int
+	submit := func(result string, err error) func() (interface{}, error) {
+		return func() (interface{}, error) {
+			calls++
+			return result, err
+		}
+	}
+
+	{ // the first request is processed and the resubmission is not
+		result, found, err := c.Do("source", "key-a", []byte("a"), submit("result-a", nil))
+		require.NoError(t, err)
+		require.False(t, found)
+		require.Equal(t, "result-a", result)
+
+		result, found, err = c.Do("source", "key-a", []byte("a"), submit("result-b", nil))
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, "result-a", result)
+		require.Equal(t, 1, calls)
+	}
+
+	{ // the key of the other request
+		_, _, err := c.Do("source", "key-a", []byte("b"), submit("result-b", nil))
+		require.Equal(t, errors.IdempotencyKeyConflict, err)
+		require.Equal(t, 1, calls)
+	}
+
+	{ // the failed request is not remembered
+		_, _, err := c.Do("source", "key-b", []byte("b"), submit("", errors.InvalidTransaction))
+		require.Equal(t, errors.InvalidTransaction, err)
+
+		result, found, err := c.Do("source", "key-b", []byte("b"), submit("result-b", nil))
+		require.NoError(t, err)
+		require.False(t, found)
+		require.Equal(t, "result-b", result)
+		require.Equal(t, 3, calls)
+	}
+
+	{ // expired after the window
+		now = now.Add(time.Minute + time.Second)
+		_, found, err := c.Do("source", "key-a", []byte("a"), submit("result-a", nil))
+		require.NoError(t, err)
+		require.False(t, found)
+		require.Equal(t, 1, c.Len())
+	}
+
+	{ // the oldest key is forgotten over the limit
+		for _, key := range []string{"key-c", "key-d", "key-e"} {
+			_, _, err := c.Do("source", key, []byte(key), submit(key, nil))
+			require.NoError(t, err)
+		}
+		require.Equal(t, 3, c.Len())
+
+		_, found, _ := c.Do("source", "key-a", []byte("a"), submit("result-a", nil))
+		require.False(t, found)
+	}
+}
+
+func TestIdempotencyCacheScopedBySource(t *testing.T) {
+	c := NewIdempotencyCache(time.Minute, 10)
+
+	submit := func(result string) func() (interface{}, error) {
+		return func() (interface{}, error) { return result, nil }
+	}
+
+	result, found, err := c.Do("source-a", "key", []byte("a"), submit("result-a"))
+	require.NoError(t, err)
+	require.False(t, found)
+	require.Equal(t, "result-a", result)
+
+	// the same key of the other source is not conflicted
+	result, found, err = c.Do("source-b", "key", []byte("b"), submit("result-b"))
+	require.NoError(t, err)
+	require.False(t, found)
+	require.Equal(t, "result-b", result)
+
+	result, found, err = c.Do("source-a", "key", []byte("a"), submit("result-c"))
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "result-a", result)
+	require.Equal(t, 2, c.Len())
+}
+
+func TestIdempotencyCacheConcurrent(t *testing.T) {
+	c := NewIdempotencyCache(time.Minute, 10)
+
+	var lock sync.Mutex
+	var calls int
+	release := make(chan struct{})
+	submit := func() (interface{}, error) {
+		<-release
+		lock.Lock()
+		defer lock.Unlock()
+		calls++
+		return "result", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan interface{}, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, _, err := c.Do("source", "key", []byte("request"), submit)
+			require.NoError(t, err)
+			results <- result
+		}()
+	}
+	close(release)
+	wg.Wait()
+	close(results)
+
+	require.Equal(t, 1, calls)
+	for result := range results {
+		require.Equal(t, "result", result)
+	}
+}
+
+// TestPostTransactionsHandlerIdempotencyKey resubmits the transaction with
+// the same idempotency key; it is handled only once.
+func TestPostTransactionsHandlerIdempotencyKey(t *testing.T) {
+	ts, storage := prepareAPIServer()
+	defer storage.Close()
+	defer ts.Close()
+
+	var handled int
+	handler := func(b []byte, funcs []common.CheckerFunc) (tx transaction.Transaction, err error) {
+		if err = json.Unmarshal(b, &tx); err != nil {
+			err = errors.InvalidMessage
+			return
+		}
+		handled++
+		if handled > 1 { // without idempotency key, the pool rejects it
+			err = errors.NewButKnownMessage
+		}
+		return
+	}
+
+	apiHandler := NetworkHandlerAPI{
+		storage:     storage,
+		Idempotency: NewIdempotencyCache(time.Minute, 10),
+	}
+	router := ts.Config.Handler.(*mux.Router)
+	router.HandleFunc(PostTransactionPattern, func(w http.ResponseWriter, r *http.Request) {
+		apiHandler.PostTransactionsHandler(w, r, handler, nil)
+	}).Methods("POST")
+
+	post := func(key string, body []byte) (int, []byte) {
+		req, err := http.NewRequest("POST", ts.URL+PostTransactionPattern, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if len(key) > 0 {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+
+		resp, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, b
+	}
+
+	kp, tx := transaction.TestMakeTransaction(networkID, 1)
+	body, _ := tx.Serialize()
+
+	status, first := post("key", body)
+	require.Equal(t, http.StatusOK, status)
+
+	for i := 0; i < 3; i++ {
+		status, b := post("key", body)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, first, b)
+	}
+	require.Equal(t, 1, handled)
+
+	{ // the same key for the other transaction of the source
+		other := transaction.TestMakeTransactionWithKeypair(networkID, 1, kp)
+		otherBody, _ := other.Serialize()
+		status, _ := post("key", otherBody)
+		require.Equal(t, http.StatusConflict, status)
+		require.Equal(t, 1, handled)
+	}
+
+	{ // the same key of the other source is processed
+		_, other := transaction.TestMakeTransaction(networkID, 1)
+		otherBody, _ := other.Serialize()
+		status, _ := post("key", otherBody)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Equal(t, 2, handled)
+	}
+
+	{ // without key, it is processed again
+		status, _ := post("", body)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Equal(t, 3, handled)
+	}
+}
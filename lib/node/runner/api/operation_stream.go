@@ -0,0 +1,224 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network/httputils"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// OperationStreamBufferSize is the number of operations, which can be queued
+// for one client of `GetOperationsStreamHandler`. If the client can not
+// receive them in time, the client is dropped.
+var OperationStreamBufferSize = 100
+
+var operationStreamUpgrader = websocket.Upgrader{
+	// the API allows all the origins; see the CORS of `NodeRunner.Ready()`
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// GetOperationsStreamHandler pushes the newly saved `BlockOperation`s to the
+// client. The websocket client receives each operation as a text message and
+// the client of `text/event-stream` receives them as JSON, one per line. The
+// operations can be filtered by `source` and `type` query.
+//
+// With `cursor` query, which is the block height, the saved operations from
+// the `cursor` height are sent first, so the client can resume with the height
+// of the last received operation without missing operations; the operations
+// of `cursor` height can be sent again.
+//
+// The operations are queued by `OperationStreamBufferSize`, so the slow
+// client does not block `BlockOperation.Save()`; the stream of the client is
+// closed when the queue is full.
+func (api NetworkHandlerAPI) GetOperationsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := block.BlockOperationFilter{Source: query.Get("source")}
+	if oType := query.Get("type"); len(oType) > 0 {
+		if !operation.IsValidOperationType(oType) {
+			http.Error(w, errors.InvalidQueryString.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.Type = operation.OperationType(oType)
+	}
+
+	var cursor uint64
+	var hasCursor bool
+	if c := query.Get("cursor"); len(c) > 0 {
+		var err error
+		if cursor, err = strconv.ParseUint(c, 10, 64); err != nil {
+			http.Error(w, errors.InvalidQueryString.Error(), http.StatusBadRequest)
+			return
+		}
+		hasCursor = true
+	}
+
+	isWebsocket := websocket.IsWebSocketUpgrade(r)
+	if !isWebsocket && !httputils.IsEventStream(r) {
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return
+	}
+
+	ops := make(chan block.BlockOperation, OperationStreamBufferSize)
+	dropped := make(chan struct{})
+	var dropOnce sync.Once
+	unsubscribe := block.SubscribeBlockOperation(filter, func(bo block.BlockOperation) {
+		select {
+		case ops <- bo:
+		default:
+			dropOnce.Do(func() { close(dropped) })
+		}
+	})
+	defer unsubscribe()
+
+	// subscribed before the client is responded, so the client can expect
+	// the operations saved after the response.
+	var s operationStream
+	if isWebsocket {
+		conn, err := operationStreamUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return // the upgrader already responded
+		}
+		defer conn.Close()
+		s = newWebsocketOperationStream(conn)
+	} else {
+		es := NewEventStream(w, r, renderEventStream, DefaultContentType)
+		s = eventOperationStream{es: es, done: r.Context().Done()}
+	}
+	s.Start()
+
+	// the operations, which are saved while sending the saved operations, can
+	// be queued, so they are skipped.
+	sent := map[string]struct{}{}
+	if hasCursor {
+		err := walkBlockOperationsFromHeight(api.storage, cursor, filter, func(bo block.BlockOperation) error {
+			sent[bo.Hash] = struct{}{}
+			return s.Send(&bo)
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case bo := <-ops:
+			if _, found := sent[bo.Hash]; found {
+				continue
+			}
+			if err := s.Send(&bo); err != nil {
+				return
+			}
+		case <-dropped:
+			return
+		case <-s.Done():
+			return
+		}
+	}
+}
+
+// operationStream is the connection of `GetOperationsStreamHandler`.
+type operationStream interface {
+	Start()
+	Send(*block.BlockOperation) error
+	Done() <-chan struct{} // closed when the client is gone
+}
+
+type eventOperationStream struct {
+	es   *EventStream
+	done <-chan struct{}
+}
+
+func (s eventOperationStream) Start() {
+	s.es.WriteHeader()
+}
+
+func (s eventOperationStream) Send(bo *block.BlockOperation) error {
+	s.es.Render(bo)
+	return nil
+}
+
+func (s eventOperationStream) Done() <-chan struct{} {
+	return s.done
+}
+
+type websocketOperationStream struct {
+	conn *websocket.Conn
+	done chan struct{}
+}
+
+func newWebsocketOperationStream(conn *websocket.Conn) websocketOperationStream {
+	return websocketOperationStream{conn: conn, done: make(chan struct{})}
+}
+
+// Start reads the connection until it is closed; the client does not send
+// anything, but the close message and the ping must be read.
+func (s websocketOperationStream) Start() {
+	go func() {
+		defer close(s.done)
+		for {
+			if _, _, err := s.conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func (s websocketOperationStream) Send(bo *block.BlockOperation) error {
+	b, err := renderEventStream("", bo)
+	if err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(websocket.TextMessage, b)
+}
+
+func (s websocketOperationStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// walkBlockOperationsFromHeight calls `f` with the saved operations, which
+// are matched with the filter, from the block of the height to the latest
+// block in order.
+func walkBlockOperationsFromHeight(st *storage.LevelDBBackend, height uint64, filter block.BlockOperationFilter, f func(block.BlockOperation) error) (err error) {
+	if height < common.GenesisBlockHeight {
+		height = common.GenesisBlockHeight
+	}
+
+	latest := block.GetLatestBlock(st)
+	for ; height <= latest.Height; height++ {
+		var blk block.Block
+		if blk, err = block.GetBlockByHeight(st, height); err != nil {
+			return
+		}
+
+		for _, txHash := range blk.Transactions {
+			var bt block.BlockTransaction
+			if bt, err = block.GetBlockTransaction(st, txHash); err != nil {
+				return
+			}
+
+			for _, opHash := range bt.Operations {
+				var bo block.BlockOperation
+				if bo, err = block.GetBlockOperation(st, opHash); err != nil {
+					return
+				}
+				if !filter.Match(bo) {
+					continue
+				}
+				if err = f(bo); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	return
+}
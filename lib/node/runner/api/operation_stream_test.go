@@ -0,0 +1,246 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func saveTestBlockOperations(st *storage.LevelDBBackend, kp *keypair.Full, n int) (bos []block.BlockOperation) {
+	// the payments to the different targets, so the random amounts do not
+	// make the same operations
+	var ops []operation.Operation
+	for i := 0; i < n; i++ {
+		ops = append(ops, operation.MakeTestPayment(-1))
+	}
+	tx, _ := transaction.NewTransaction(kp.Address(), 0, ops...)
+	tx.Sign(kp, networkID)
+
+	for _, op := range tx.B.Operations {
+		bo, err := block.NewBlockOperationFromOperation(op, tx, 1)
+		if err != nil {
+			panic(err)
+		}
+		bo.MustSave(st)
+		bos = append(bos, bo)
+	}
+
+	return
+}
+
+// streamedOperation is the operation resource pushed by
+// `GetOperationsStreamHandler`.
+type streamedOperation struct {
+	Hash   string `json:"hash"`
+	Source string `json:"source"`
+}
+
+func TestGetOperationsStreamHandler(t *testing.T) {
+	ts, st := prepareAPIServer()
+	defer st.Close()
+	defer ts.Close()
+
+	apiHandler := NetworkHandlerAPI{storage: st}
+	router := ts.Config.Handler.(*mux.Router)
+	router.HandleFunc(GetOperationsStreamPattern, apiHandler.GetOperationsStreamHandler).Methods("GET")
+
+	readOperation := func(reader *bufio.Reader) streamedOperation {
+		line, err := reader.ReadBytes('\n')
+		require.NoError(t, err)
+
+		var bo streamedOperation
+		require.NoError(t, json.Unmarshal(line, &bo))
+		return bo
+	}
+
+	{ // save triggers the message
+		body := request(ts, GetOperationsStreamPattern, true)
+		defer body.Close()
+		reader := bufio.NewReader(body)
+
+		bos := saveTestBlockOperations(st, keypair.Random(), 2)
+		require.Equal(t, bos[0].Hash, readOperation(reader).Hash)
+		require.Equal(t, bos[1].Hash, readOperation(reader).Hash)
+	}
+
+	{ // filtered by source
+		kpA := keypair.Random()
+		body := request(ts, GetOperationsStreamPattern+"?source="+kpA.Address(), true)
+		defer body.Close()
+		reader := bufio.NewReader(body)
+
+		saveTestBlockOperations(st, keypair.Random(), 2)
+		bos := saveTestBlockOperations(st, kpA, 1)
+
+		bo := readOperation(reader)
+		require.Equal(t, kpA.Address(), bo.Source)
+		require.Equal(t, bos[0].Hash, bo.Hash)
+	}
+
+	{ // resume from cursor
+		_, bts := prepareTxs(st, 2)
+		height := block.GetLatestBlock(st).Height
+
+		body := request(ts, GetOperationsStreamPattern+"?cursor="+strconv.FormatUint(height, 10), true)
+		defer body.Close()
+		reader := bufio.NewReader(body)
+
+		require.Equal(t, bts[0].Operations[0], readOperation(reader).Hash)
+		require.Equal(t, bts[1].Operations[0], readOperation(reader).Hash)
+
+		// and new operation
+		bos := saveTestBlockOperations(st, keypair.Random(), 1)
+		require.Equal(t, bos[0].Hash, readOperation(reader).Hash)
+	}
+
+	{ // invalid query
+		for _, q := range []string{"?type=findme", "?cursor=findme"} {
+			resp, err := ts.Client().Get(ts.URL + GetOperationsStreamPattern + q)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+
+	{ // neither websocket nor event stream
+		resp, err := ts.Client().Get(ts.URL + GetOperationsStreamPattern)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+	}
+}
+
+func TestGetOperationsStreamHandlerWebsocket(t *testing.T) {
+	ts, st := prepareAPIServer()
+	defer st.Close()
+	defer ts.Close()
+
+	apiHandler := NetworkHandlerAPI{storage: st}
+	router := ts.Config.Handler.(*mux.Router)
+	router.HandleFunc(GetOperationsStreamPattern, apiHandler.GetOperationsStreamHandler).Methods("GET")
+
+	dial := func(query string) *websocket.Conn {
+		u := "ws" + strings.TrimPrefix(ts.URL, "http") + GetOperationsStreamPattern + query
+		conn, _, err := websocket.DefaultDialer.Dial(u, nil)
+		require.NoError(t, err)
+		return conn
+	}
+
+	readOperation := func(conn *websocket.Conn) streamedOperation {
+		conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+		messageType, b, err := conn.ReadMessage()
+		require.NoError(t, err)
+		require.Equal(t, websocket.TextMessage, messageType)
+
+		var bo streamedOperation
+		require.NoError(t, json.Unmarshal(b, &bo))
+		return bo
+	}
+
+	{ // resume from cursor and filtered by source
+		kpA := keypair.Random()
+		saveTestBlockOperations(st, kpA, 1) // saved before; not sent without cursor
+
+		_, bts := prepareTxs(st, 1)
+		height := block.GetLatestBlock(st).Height
+
+		conn := dial("?cursor=" + strconv.FormatUint(height, 10))
+		defer conn.Close()
+		require.Equal(t, bts[0].Operations[0], readOperation(conn).Hash)
+
+		filtered := dial("?source=" + kpA.Address())
+		defer filtered.Close()
+
+		saveTestBlockOperations(st, keypair.Random(), 1)
+		bos := saveTestBlockOperations(st, kpA, 1)
+
+		bo := readOperation(filtered)
+		require.Equal(t, kpA.Address(), bo.Source)
+		require.Equal(t, bos[0].Hash, bo.Hash)
+	}
+}
+
+// blockingResponseWriter blocks writing until `release` is closed.
+type blockingResponseWriter struct {
+	*httptest.ResponseRecorder
+	release   chan struct{}
+	flushed   chan struct{}
+	flushOnce *sync.Once
+}
+
+func (w blockingResponseWriter) Write(b []byte) (int, error) {
+	<-w.release
+	return w.ResponseRecorder.Write(b)
+}
+
+func (w blockingResponseWriter) Flush() {
+	w.flushOnce.Do(func() { close(w.flushed) })
+}
+
+func TestGetOperationsStreamHandlerDropSlowClient(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	defer func(size int) { OperationStreamBufferSize = size }(OperationStreamBufferSize)
+	OperationStreamBufferSize = 1
+
+	kp := keypair.Random()
+	apiHandler := NetworkHandlerAPI{storage: st}
+	w := blockingResponseWriter{
+		ResponseRecorder: httptest.NewRecorder(),
+		release:          make(chan struct{}),
+		flushed:          make(chan struct{}),
+		flushOnce:        &sync.Once{},
+	}
+	r := httptest.NewRequest("GET", GetOperationsStreamPattern+"?source="+kp.Address(), nil)
+	r.Header.Set("Accept", "text/event-stream")
+
+	done := make(chan struct{})
+	go func() {
+		apiHandler.GetOperationsStreamHandler(w, r)
+		close(done)
+	}()
+
+	// wait until subscribed
+	select {
+	case <-w.flushed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("stream is not started")
+	}
+
+	saved := make(chan struct{})
+	go func() {
+		saveTestBlockOperations(st, kp, 10)
+		close(saved)
+	}()
+
+	select {
+	case <-saved:
+	case <-time.After(3 * time.Second):
+		t.Fatal("slow client blocks saving operations")
+	}
+
+	close(w.release)
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("slow client was not dropped")
+	}
+}
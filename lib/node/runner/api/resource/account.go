@@ -6,10 +6,12 @@ import (
 	"github.com/nvellon/hal"
 
 	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
 )
 
 type Account struct {
-	ba *block.BlockAccount
+	ba             *block.BlockAccount
+	minimumBalance *common.Amount
 }
 
 func NewAccount(ba *block.BlockAccount) *Account {
@@ -19,13 +21,32 @@ func NewAccount(ba *block.BlockAccount) *Account {
 	return a
 }
 
+// SetMinimumBalance adds `minimum_balance` and `spendable_balance` to the
+// resource; see `block.MinimumBalance()`.
+func (a *Account) SetMinimumBalance(minimum common.Amount) *Account {
+	a.minimumBalance = &minimum
+	return a
+}
+
 func (a Account) GetMap() hal.Entry {
-	return hal.Entry{
+	entry := hal.Entry{
 		"address":     a.ba.Address,
 		"sequence_id": a.ba.SequenceID,
 		"balance":     a.ba.Balance,
 		"linked":      a.ba.Linked,
+		"closed":      a.ba.Closed,
 	}
+
+	if a.minimumBalance != nil {
+		var spendable common.Amount
+		if a.ba.Balance > *a.minimumBalance {
+			spendable = a.ba.Balance - *a.minimumBalance
+		}
+		entry["minimum_balance"] = *a.minimumBalance
+		entry["spendable_balance"] = spendable
+	}
+
+	return entry
 }
 
 func (a Account) Resource() *hal.Resource {
@@ -11,5 +11,8 @@ const (
 	URLTransactionByHash     = APIPrefix + APIVersionV1 + "/transactions/{id}"
 	URLTransactionOperations = APIPrefix + APIVersionV1 + "/transactions/{id}/operations"
 	URLTransactionHistory    = APIPrefix + APIVersionV1 + "/transactions/{id}/history"
+	URLTransactionDetail     = APIPrefix + APIVersionV1 + "/transactions/{id}/detail"
 	URLOperations            = APIPrefix + APIVersionV1 + "/operations/{id}"
+	URLTransactionPool       = APIPrefix + APIVersionV1 + "/transaction-pool"
+	URLValidatorChanges      = APIPrefix + APIVersionV1 + "/validator-changes"
 )
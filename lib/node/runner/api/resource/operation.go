@@ -23,13 +23,18 @@ func NewOperation(bo *block.BlockOperation) *Operation {
 func (o Operation) GetMap() hal.Entry {
 	body, _ := operation.UnmarshalBodyJSON(o.bo.Type, o.bo.Body)
 
-	return hal.Entry{
+	entry := hal.Entry{
 		"hash":    o.bo.Hash,
 		"source":  o.bo.Source,
 		"type":    o.bo.Type,
 		"tx_hash": o.bo.TxHash,
 		"body":    body,
 	}
+	if o.bo.Memo != nil {
+		entry["memo"] = o.bo.Memo
+	}
+
+	return entry
 }
 
 func (o Operation) Resource() *hal.Resource {
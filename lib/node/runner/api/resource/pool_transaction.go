@@ -0,0 +1,49 @@
+package resource
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nvellon/hal"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+// PoolTransaction is the transaction, which is not yet confirmed and waits in
+// the transaction pool.
+type PoolTransaction struct {
+	tx  transaction.PoolTransaction
+	now time.Time
+}
+
+func NewPoolTransaction(tx transaction.PoolTransaction, now time.Time) *PoolTransaction {
+	return &PoolTransaction{
+		tx:  tx,
+		now: now,
+	}
+}
+
+func (t PoolTransaction) GetMap() hal.Entry {
+	return hal.Entry{
+		"hash":            t.tx.GetHash(),
+		"source":          t.tx.Source(),
+		"fee":             t.tx.B.Fee.String(),
+		"sequence_id":     t.tx.B.SequenceID,
+		"created":         t.tx.H.Created,
+		"operation_count": len(t.tx.B.Operations),
+		"received":        common.FormatISO8601(t.tx.Received),
+		"age":             t.now.Sub(t.tx.Received).String(),
+	}
+}
+
+func (t PoolTransaction) Resource() *hal.Resource {
+	r := hal.NewResource(t, t.LinkSelf())
+	r.AddLink("account", hal.NewLink(strings.Replace(URLAccounts, "{id}", t.tx.Source(), -1)))
+	r.AddLink("transaction", hal.NewLink(strings.Replace(URLTransactionByHash, "{id}", t.tx.GetHash(), -1)))
+	return r
+}
+
+func (t PoolTransaction) LinkSelf() string {
+	return URLTransactionPool + "?source=" + t.tx.Source()
+}
@@ -0,0 +1,54 @@
+package resource
+
+import (
+	"strings"
+
+	"github.com/nvellon/hal"
+
+	"boscoin.io/sebak/lib/block"
+)
+
+type TransactionDetail struct {
+	detail *block.BlockTransactionDetail
+}
+
+func NewTransactionDetail(detail *block.BlockTransactionDetail) *TransactionDetail {
+	t := &TransactionDetail{
+		detail: detail,
+	}
+	return t
+}
+
+func (t TransactionDetail) GetMap() hal.Entry {
+	entry := NewTransaction(&t.detail.Transaction).GetMap()
+
+	var ops []hal.Entry
+	for _, op := range t.detail.Operations {
+		ops = append(ops, hal.Entry{
+			"hash":   op.Hash,
+			"source": op.Source,
+			"type":   op.Type,
+			"body":   op.Body,
+		})
+	}
+
+	entry["block"] = t.detail.Transaction.Block
+	entry["block_height"] = t.detail.Height
+	entry["confirmed"] = t.detail.Confirmed
+	entry["fee"] = t.detail.Fee.String()
+	entry["is_proposer_transaction"] = t.detail.IsProposerTransaction
+	entry["operations"] = ops
+
+	return entry
+}
+
+func (t TransactionDetail) Resource() *hal.Resource {
+	r := hal.NewResource(t, t.LinkSelf())
+	r.AddLink("account", hal.NewLink(strings.Replace(URLAccounts, "{id}", t.detail.Transaction.Source, -1)))
+	r.AddLink("transaction", hal.NewLink(strings.Replace(URLTransactionByHash, "{id}", t.detail.Transaction.Hash, -1)))
+	return r
+}
+
+func (t TransactionDetail) LinkSelf() string {
+	return strings.Replace(URLTransactionDetail, "{id}", t.detail.Transaction.Hash, -1)
+}
@@ -0,0 +1,34 @@
+package resource
+
+import (
+	"github.com/nvellon/hal"
+
+	"boscoin.io/sebak/lib/block"
+)
+
+// ValidatorChange is the validator set change, which takes effect after the
+// block of `height`.
+type ValidatorChange struct {
+	c block.BlockValidatorChange
+}
+
+func NewValidatorChange(c block.BlockValidatorChange) *ValidatorChange {
+	return &ValidatorChange{c: c}
+}
+
+func (v ValidatorChange) GetMap() hal.Entry {
+	return hal.Entry{
+		"height":   v.c.Height,
+		"address":  v.c.Address,
+		"endpoint": v.c.Endpoint,
+		"remove":   v.c.Remove,
+	}
+}
+
+func (v ValidatorChange) Resource() *hal.Resource {
+	return hal.NewResource(v, v.LinkSelf())
+}
+
+func (v ValidatorChange) LinkSelf() string {
+	return URLValidatorChanges
+}
@@ -109,6 +109,23 @@ func (s *EventStream) Render(args ...interface{}) {
 	s.flusher.Flush()
 }
 
+// WriteHeader sends the header of the chunked response before the first
+// message, so the client can start reading the stream, which may have no
+// message for a while.
+func (s *EventStream) WriteHeader() {
+	if s.err != nil {
+		return
+	}
+
+	if !s.rendered {
+		s.writer.Header().Set("Content-Type", s.contentType)
+		s.rendered = true
+	}
+
+	s.writer.WriteHeader(http.StatusOK)
+	s.flusher.Flush()
+}
+
 // Run start observing events.
 //
 // Simple use case:
@@ -33,6 +33,7 @@ func prepareAPIServer() (*httptest.Server, *storage.LevelDBBackend) {
 	router.HandleFunc(GetAccountHandlerPattern, apiHandler.GetAccountHandler).Methods("GET")
 	router.HandleFunc(GetAccountHandlerPattern, apiHandler.GetAccountHandler).Methods("GET")
 	router.HandleFunc(GetTransactionOperationsHandlerPattern, apiHandler.GetOperationsByTxHashHandler).Methods("GET")
+	router.HandleFunc(GetTransactionDetailHandlerPattern, apiHandler.GetTransactionDetailHandler).Methods("GET")
 	ts := httptest.NewServer(router)
 	return ts, storage
 }
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network/httputils"
+	"boscoin.io/sebak/lib/node/runner/api/resource"
+)
+
+// GetTransactionDetailHandler responds the transaction with its decoded
+// operations, the block height, the confirmed time and the fee. It also
+// works for the proposer transaction.
+func (api NetworkHandlerAPI) GetTransactionDetailHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key := vars["id"]
+
+	found, err := block.ExistsBlockTransaction(api.storage, key)
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+	if !found {
+		httputils.WriteJSONError(w, errors.BlockTransactionDoesNotExists)
+		return
+	}
+
+	detail, err := block.GetBlockTransactionDetail(api.storage, key)
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+
+	httputils.MustWriteJSON(w, 200, resource.NewTransactionDetail(&detail))
+}
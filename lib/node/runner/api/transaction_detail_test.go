@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+)
+
+func TestGetTransactionDetailHandler(t *testing.T) {
+	ts, storage := prepareAPIServer()
+	defer storage.Close()
+	defer ts.Close()
+
+	_, btList := prepareTxs(storage, 1)
+	bt := btList[0]
+
+	{ // unknown transaction
+		url := strings.Replace(GetTransactionDetailHandlerPattern, "{id}", "findme", -1)
+		req, _ := http.NewRequest("GET", ts.URL+url, nil)
+		resp, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	url := strings.Replace(GetTransactionDetailHandlerPattern, "{id}", bt.Hash, -1)
+	respBody := request(ts, url, false)
+	defer respBody.Close()
+
+	readByte, err := ioutil.ReadAll(respBody)
+	require.NoError(t, err)
+	recv := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(readByte, &recv))
+
+	blk, err := block.GetBlock(storage, bt.Block)
+	require.NoError(t, err)
+
+	require.Equal(t, bt.Hash, recv["hash"])
+	require.Equal(t, bt.Block, recv["block"])
+	require.Equal(t, float64(blk.Height), recv["block_height"])
+	require.Equal(t, bt.Confirmed, recv["confirmed"])
+	require.Equal(t, bt.Fee.String(), recv["fee"])
+	require.Equal(t, false, recv["is_proposer_transaction"])
+
+	ops := recv["operations"].([]interface{})
+	require.Equal(t, len(bt.Operations), len(ops))
+	for i, opHash := range bt.Operations {
+		bo, err := block.GetBlockOperation(storage, opHash)
+		require.NoError(t, err)
+
+		op := ops[i].(map[string]interface{})
+		require.Equal(t, bo.Hash, op["hash"])
+		require.Equal(t, string(bo.Type), op["type"])
+		require.Equal(t, bo.Source, op["source"])
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(bo.Body, &body))
+		require.Equal(t, body, op["body"])
+	}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network/httputils"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// TransactionInclusionTimeout is the default timeout of
+// `GetTransactionInclusionHandler`; the `timeout` query can not be over
+// MaxTransactionInclusionTimeout.
+var (
+	TransactionInclusionTimeout    = 30 * time.Second
+	MaxTransactionInclusionTimeout = 5 * time.Minute
+)
+
+// GetTransactionInclusionHandler waits until the transaction is included in
+// the block, so the client does not need to poll the transaction.
+//
+// By default, it responds the first `block.TransactionInclusion` of the
+// transaction as JSON; if the transaction is not included within `timeout`
+// query, it responds `errors.TransactionInclusionTimeout`. With `once=false`
+// query, the inclusions of all the operations of the transaction are streamed
+// as JSON, one per line, until the timeout.
+//
+// The transaction, which is already included, is responded at once.
+func (api NetworkHandlerAPI) GetTransactionInclusionHandler(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["id"]
+	query := r.URL.Query()
+
+	timeout := TransactionInclusionTimeout
+	if t := query.Get("timeout"); len(t) > 0 {
+		var err error
+		if timeout, err = time.ParseDuration(t); err != nil || timeout <= 0 {
+			httputils.WriteJSONError(w, errors.InvalidQueryString)
+			return
+		}
+		if timeout > MaxTransactionInclusionTimeout {
+			timeout = MaxTransactionInclusionTimeout
+		}
+	}
+
+	oneShot := true
+	if o := query.Get("once"); len(o) > 0 {
+		var err error
+		if oneShot, err = strconv.ParseBool(o); err != nil {
+			httputils.WriteJSONError(w, errors.InvalidQueryString)
+			return
+		}
+	}
+
+	type inclusion struct {
+		ti  block.TransactionInclusion
+		err error
+	}
+
+	// subscribe before loading the saved operations, so the operations saved
+	// in between are not missed.
+	inclusions := make(chan inclusion, OperationStreamBufferSize)
+	unsubscribe := block.SubscribeTransactionInclusion(key, timeout, oneShot, func(ti block.TransactionInclusion, err error) {
+		select {
+		case inclusions <- inclusion{ti: ti, err: err}:
+		default:
+		}
+	})
+	defer unsubscribe()
+
+	saved, err := getTransactionInclusions(api.storage, key)
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+
+	if oneShot {
+		if len(saved) > 0 {
+			httputils.WriteJSON(w, http.StatusOK, saved[0])
+			return
+		}
+
+		select {
+		case i := <-inclusions:
+			if i.err != nil {
+				httputils.WriteJSONError(w, i.err)
+				return
+			}
+			httputils.WriteJSON(w, http.StatusOK, i.ti)
+		case <-r.Context().Done():
+		}
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", DefaultContentType)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	render := func(ti block.TransactionInclusion) error {
+		b, err := json.Marshal(ti)
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "%s\n", b); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	sent := map[string]struct{}{}
+	for _, ti := range saved {
+		sent[ti.Operation] = struct{}{}
+		if err := render(ti); err != nil {
+			return
+		}
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case i := <-inclusions:
+			if i.err != nil {
+				return
+			}
+			if _, found := sent[i.ti.Operation]; found {
+				continue
+			}
+			if err := render(i.ti); err != nil {
+				return
+			}
+		case <-deadline.C:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// getTransactionInclusions returns the inclusions of the saved operations of
+// the transaction.
+func getTransactionInclusions(st *storage.LevelDBBackend, txHash string) (tis []block.TransactionInclusion, err error) {
+	iterFunc, closeFunc := block.GetBlockOperationsByTxHash(st, txHash, nil)
+	defer closeFunc()
+
+	for {
+		bo, hasNext, _ := iterFunc()
+		if !hasNext {
+			break
+		}
+		tis = append(tis, block.TransactionInclusion{TxHash: bo.TxHash, Operation: bo.Hash, Height: bo.Height})
+	}
+
+	return
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common/observer"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+func waitTransactionInclusionSubscribed(t *testing.T, txHash string) {
+	event := fmt.Sprintf("txhash-%s", txHash)
+	for i := 0; i < 100; i++ {
+		observer.BlockOperationObserver.RLock()
+		n := len(observer.BlockOperationObserver.Callbacks[event])
+		observer.BlockOperationObserver.RUnlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Fail(t, "not subscribed", txHash)
+}
+
+func saveTestTransactionInBlock(t *testing.T, st *storage.LevelDBBackend, tx transaction.Transaction) block.Block {
+	blk := block.TestMakeNewBlockWithPrevBlock(block.GetLatestBlock(st), []string{tx.GetHash()})
+	blk.MustSave(st)
+	bt := block.NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.Confirmed, tx)
+	bt.MustSave(st)
+	require.NoError(t, bt.SaveBlockOperations(st, blk))
+
+	return blk
+}
+
+func TestGetTransactionInclusionHandler(t *testing.T) {
+	ts, st := prepareAPIServer()
+	defer st.Close()
+	defer ts.Close()
+
+	apiHandler := NetworkHandlerAPI{storage: st}
+	router := ts.Config.Handler.(*mux.Router)
+	router.HandleFunc(GetTransactionInclusionHandlerPattern, apiHandler.GetTransactionInclusionHandler).Methods("GET")
+
+	get := func(url string) *http.Response {
+		resp, err := ts.Client().Get(ts.URL + url)
+		require.NoError(t, err)
+		return resp
+	}
+
+	{ // the submitted transaction is included after the request
+		_, tx, _ := prepareTxWithoutSave(st)
+		url := fmt.Sprintf("/transactions/%s/inclusion", tx.GetHash())
+
+		responses := make(chan *http.Response)
+		go func() {
+			responses <- get(url)
+		}()
+		waitTransactionInclusionSubscribed(t, tx.GetHash())
+
+		blk := saveTestTransactionInBlock(t, st, *tx)
+
+		resp := <-responses
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var ti block.TransactionInclusion
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&ti))
+		require.Equal(t, tx.GetHash(), ti.TxHash)
+		require.Equal(t, blk.Height, ti.Height)
+
+		{ // already included
+			resp := get(url)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var ti block.TransactionInclusion
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&ti))
+			require.Equal(t, blk.Height, ti.Height)
+		}
+	}
+
+	{ // timeout
+		_, tx, _ := prepareTxWithoutSave(st)
+		resp := get(fmt.Sprintf("/transactions/%s/inclusion?timeout=50ms", tx.GetHash()))
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusRequestTimeout, resp.StatusCode)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(b), errors.TransactionInclusionTimeout.Message)
+	}
+
+	{ // invalid query
+		resp := get("/transactions/findme/inclusion?timeout=killme")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+
+	{ // stream all the operations
+		_, tx := transaction.TestMakeTransaction(networkID, 2)
+		resp := get(fmt.Sprintf("/transactions/%s/inclusion?once=false&timeout=1s", tx.GetHash()))
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		blk := saveTestTransactionInBlock(t, st, tx)
+
+		reader := bufio.NewReader(resp.Body)
+		operations := map[string]struct{}{}
+		for i := 0; i < 2; i++ {
+			line, err := reader.ReadBytes('\n')
+			require.NoError(t, err)
+
+			var ti block.TransactionInclusion
+			require.NoError(t, json.Unmarshal(line, &ti))
+			require.Equal(t, blk.Height, ti.Height)
+			operations[ti.Operation] = struct{}{}
+		}
+		require.Equal(t, 2, len(operations))
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network/httputils"
+	"boscoin.io/sebak/lib/node/runner/api/resource"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// GetTransactionPoolHandler returns the transactions, which are received but
+// not yet confirmed, in the order of receipt. The `source` query filters the
+// transactions by source account and `cursor` and `limit` paginate them; the
+// cursor is the hash of the last transaction of the previous page.
+func (api NetworkHandlerAPI) GetTransactionPoolHandler(w http.ResponseWriter, r *http.Request) {
+	options, err := storage.NewDefaultListOptionsFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, errors.InvalidQueryString.Error(), http.StatusBadRequest)
+		return
+	}
+	source := r.URL.Query().Get("source")
+
+	var txs []resource.Resource
+	var cursor []byte
+
+	now := time.Now()
+	for _, tx := range api.TransactionPool.List(source, string(options.Cursor()), options.Limit()) {
+		txs = append(txs, resource.NewPoolTransaction(tx, now))
+		cursor = []byte(tx.GetHash())
+	}
+
+	query := url.Values{}
+	if len(source) > 0 {
+		query.Set("source", source)
+	}
+	if len(cursor) > 0 {
+		query.Set("cursor", string(cursor))
+	}
+	if options.Limit() > 0 {
+		query.Set("limit", strconv.FormatUint(options.Limit(), 10))
+	}
+
+	self := r.URL.String()
+	next := GetTransactionPoolHandlerPattern + "?" + query.Encode()
+	list := resource.NewResourceList(txs, self, next, "")
+
+	httputils.MustWriteJSON(w, 200, list)
+}
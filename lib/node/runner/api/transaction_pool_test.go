@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/transaction"
+)
+
+func TestGetTransactionPoolHandler(t *testing.T) {
+	ts, storage := prepareAPIServer()
+	defer storage.Close()
+	defer ts.Close()
+
+	pool := transaction.NewPool()
+	apiHandler := NetworkHandlerAPI{storage: storage, TransactionPool: pool}
+	router := ts.Config.Handler.(*mux.Router)
+	router.HandleFunc(GetTransactionPoolHandlerPattern, apiHandler.GetTransactionPoolHandler).Methods("GET")
+
+	get := func(query string) (records []interface{}, next string) {
+		resp, err := ts.Client().Get(ts.URL + GetTransactionPoolHandlerPattern + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var recv map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &recv))
+
+		records, _ = recv["_embedded"].(map[string]interface{})["records"].([]interface{})
+		next = recv["_links"].(map[string]interface{})["next"].(map[string]interface{})["href"].(string)
+		return
+	}
+
+	records, _ := get("")
+	require.Equal(t, 0, len(records))
+
+	var txs []transaction.Transaction
+	for i := 0; i < 3; i++ {
+		_, tx := transaction.TestMakeTransaction(networkID, 1)
+		require.True(t, pool.Add(tx))
+		txs = append(txs, tx)
+	}
+
+	{ // submitted transactions appear
+		records, _ := get("")
+		require.Equal(t, 3, len(records))
+		for i, r := range records {
+			m := r.(map[string]interface{})
+			require.Equal(t, txs[i].GetHash(), m["hash"])
+			require.Equal(t, txs[i].Source(), m["source"])
+			require.Equal(t, txs[i].B.SequenceID, uint64(m["sequence_id"].(float64)))
+			require.Equal(t, txs[i].B.Fee.String(), m["fee"])
+			require.NotEmpty(t, m["received"])
+			require.NotEmpty(t, m["age"])
+		}
+	}
+
+	{ // source
+		records, _ := get("?source=" + txs[1].Source())
+		require.Equal(t, 1, len(records))
+		require.Equal(t, txs[1].GetHash(), records[0].(map[string]interface{})["hash"])
+	}
+
+	{ // pagination by the next link
+		records, next := get("?limit=2")
+		require.Equal(t, 2, len(records))
+		require.Equal(t, txs[1].GetHash(), records[1].(map[string]interface{})["hash"])
+
+		records, _ = get(next[len(GetTransactionPoolHandlerPattern):])
+		require.Equal(t, 1, len(records))
+		require.Equal(t, txs[2].GetHash(), records[0].(map[string]interface{})["hash"])
+	}
+
+	{ // confirmed transactions are removed from the pool and disappear
+		pool.Remove(txs[0].GetHash())
+		records, _ := get("")
+		require.Equal(t, 2, len(records))
+		for _, r := range records {
+			require.NotEqual(t, txs[0].GetHash(), r.(map[string]interface{})["hash"])
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"net/http"
 
+	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/network/httputils"
 	"boscoin.io/sebak/lib/node/runner/api/resource"
 	"boscoin.io/sebak/lib/transaction"
@@ -27,6 +30,10 @@ func NewTeeReadCloser(origin io.ReadCloser, w io.Writer) io.ReadCloser {
 	}
 }
 
+// PostTransactionsHandler submits the transaction. With the
+// `IdempotencyKeyHeader` header, the resubmission of the accepted transaction
+// gets the original result without being processed again; see
+// `NetworkHandlerAPI.Idempotency`.
 func (api NetworkHandlerAPI) PostTransactionsHandler(
 	w http.ResponseWriter,
 	r *http.Request,
@@ -41,13 +48,94 @@ func (api NetworkHandlerAPI) PostTransactionsHandler(
 		return
 	}
 
+	submit := func() (interface{}, error) {
+		tx, err := handler(body, funcs)
+		if err != nil {
+			return nil, err
+		}
+		return resource.NewTransactionPost(tx), nil
+	}
+
+	var payload interface{}
+	key := r.Header.Get(IdempotencyKeyHeader)
 	var tx transaction.Transaction
-	if tx, err = handler(body, funcs); err != nil {
+	if len(key) > 0 && api.Idempotency != nil && json.Unmarshal(body, &tx) == nil {
+		// the source is not verified yet, but the forged transaction fails and
+		// is not remembered
+		payload, _, err = api.Idempotency.Do(tx.B.Source, key, body, submit)
+	} else {
+		payload, err = submit()
+	}
+	if err != nil {
 		httputils.WriteJSONError(w, err)
 		return
 	}
 
-	if err = httputils.WriteJSON(w, 200, resource.NewTransactionPost(tx)); err != nil {
+	if err = httputils.WriteJSON(w, 200, payload); err != nil {
+		httputils.WriteJSONError(w, err)
+	}
+}
+
+// TransactionBatchItem is the result of each transaction in the batch
+// submission.
+type TransactionBatchItem struct {
+	Hash   string        `json:"hash"`
+	Status string        `json:"status"`
+	Error  *errors.Error `json:"error,omitempty"`
+}
+
+// PostTransactionsBatchHandler accepts the JSON array of transactions. Each
+// transaction is handled like `PostTransactionsHandler` and the invalid
+// transaction does not fail the others. The batch over
+// `MaxTransactionsInBatch` is refused as a whole.
+func (api NetworkHandlerAPI) PostTransactionsBatchHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+	handler func([]byte, []common.CheckerFunc) (transaction.Transaction, error),
+	funcs []common.CheckerFunc,
+) {
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
 		httputils.WriteJSONError(w, err)
+		return
+	}
+
+	var messages []json.RawMessage
+	if err = json.Unmarshal(body, &messages); err != nil || len(messages) < 1 {
+		httputils.WriteJSONError(w, errors.InvalidMessage)
+		return
 	}
+	if len(messages) > api.MaxTransactionsInBatch {
+		httputils.WriteJSONError(w, errors.TooManyTransactionsInBatch.Clone().
+			SetData("limit", api.MaxTransactionsInBatch).
+			SetData("transactions", len(messages)))
+		return
+	}
+
+	items := make([]TransactionBatchItem, 0, len(messages))
+	for _, message := range messages {
+		item := TransactionBatchItem{Status: block.TransactionHistoryStatusSubmitted}
+
+		var tx transaction.Transaction
+		if tx, err = handler(message, funcs); err != nil {
+			var rejected transaction.Transaction
+			if json.Unmarshal(message, &rejected) == nil {
+				item.Hash = rejected.GetHash()
+			}
+			item.Status = block.TransactionHistoryStatusRejected
+			if e, ok := err.(*errors.Error); ok {
+				item.Error = e
+			} else {
+				item.Error = errors.InvalidTransaction.Clone().SetData("error", err.Error())
+			}
+		} else {
+			item.Hash = tx.GetHash()
+		}
+
+		items = append(items, item)
+	}
+
+	httputils.MustWriteJSON(w, 200, items)
 }
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+func TestPostTransactionsBatchHandler(t *testing.T) {
+	ts, storage := prepareAPIServer()
+	defer storage.Close()
+	defer ts.Close()
+
+	conf := common.NewConfig()
+	var handled int
+	handler := func(b []byte, funcs []common.CheckerFunc) (tx transaction.Transaction, err error) {
+		handled++
+		if err = json.Unmarshal(b, &tx); err != nil {
+			err = errors.InvalidMessage
+			return
+		}
+		if err = tx.IsWellFormed(networkID, conf); err != nil {
+			tx = transaction.Transaction{}
+		}
+		return
+	}
+
+	apiHandler := NetworkHandlerAPI{storage: storage, MaxTransactionsInBatch: 3}
+	router := ts.Config.Handler.(*mux.Router)
+	router.HandleFunc(PostTransactionsBatchPattern, func(w http.ResponseWriter, r *http.Request) {
+		apiHandler.PostTransactionsBatchHandler(w, r, handler, nil)
+	}).Methods("POST")
+
+	post := func(body []byte) *http.Response {
+		resp, err := ts.Client().Post(ts.URL+PostTransactionsBatchPattern, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		return resp
+	}
+
+	_, valid := transaction.TestMakeTransaction(networkID, 1)
+	_, invalid := transaction.TestMakeTransaction(networkID, 1)
+	invalid.H.Signature = valid.H.Signature // signature of other transaction
+
+	validJSON, _ := valid.Serialize()
+	invalidJSON, _ := invalid.Serialize()
+	body, _ := json.Marshal([]json.RawMessage{validJSON, invalidJSON, json.RawMessage(`"findme"`)})
+
+	{ // mixed batch: the valid one is accepted
+		resp := post(body)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var items []TransactionBatchItem
+		require.NoError(t, json.Unmarshal(b, &items))
+		require.Equal(t, 3, len(items))
+
+		require.Equal(t, valid.GetHash(), items[0].Hash)
+		require.Equal(t, block.TransactionHistoryStatusSubmitted, items[0].Status)
+		require.Nil(t, items[0].Error)
+
+		require.Equal(t, invalid.GetHash(), items[1].Hash)
+		require.Equal(t, block.TransactionHistoryStatusRejected, items[1].Status)
+		require.Equal(t, errors.InvalidTransaction.Code, items[1].Error.Code)
+
+		require.Equal(t, block.TransactionHistoryStatusRejected, items[2].Status)
+		require.NotNil(t, items[2].Error)
+	}
+
+	{ // not an array
+		resp := post(validJSON)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+
+	{ // empty batch
+		resp := post([]byte(`[]`))
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+
+	{ // over `MaxTransactionsInBatch`; nothing is handled
+		handled = 0
+		body, _ := json.Marshal([]json.RawMessage{validJSON, validJSON, validJSON, validJSON})
+		resp := post(body)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, 0, handled)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(b), errors.TooManyTransactionsInBatch.Message)
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/network/httputils"
+	"boscoin.io/sebak/lib/node/runner/api/resource"
+)
+
+// GetPendingValidatorChangesHandler returns the validator set changes, which
+// are not yet taken effect at the latest block, in height order.
+func (api NetworkHandlerAPI) GetPendingValidatorChangesHandler(w http.ResponseWriter, r *http.Request) {
+	latest := block.GetLatestBlock(api.storage)
+
+	changes, err := block.GetPendingBlockValidatorChanges(api.storage, latest.Height)
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+
+	var rs []resource.Resource
+	for _, c := range changes {
+		rs = append(rs, resource.NewValidatorChange(c))
+	}
+
+	list := resource.NewResourceList(rs, r.URL.String(), "", "")
+
+	httputils.MustWriteJSON(w, 200, list)
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"net/http"
+	"strconv"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+const GetBlockStreamPattern = "/blocks/stream"
+
+// MaxBlockStreamRange is the maximum number of blocks in one request of
+// `GetBlockStreamHandler`.
+const MaxBlockStreamRange uint64 = 1000
+
+// GetBlockStreamHandler streams the blocks from the height of `from` to `to`
+// in the query string. If `to` is over the latest block, it stops at the
+// latest block. Every block is written as `NodeItemBlock` and followed by its
+// `ProposerTransaction` and transactions as `NodeItemTransaction` in the order
+// of `Block.Transactions`, so the receiver can verify and store the block
+// before the next one arrives; see `ImportBlockStream()`.
+func (nh NetworkHandlerNode) GetBlockStreamHandler(w http.ResponseWriter, r *http.Request) {
+	from, err := strconv.ParseUint(r.URL.Query().Get("from"), 10, 64)
+	if err != nil || from < common.GenesisBlockHeight {
+		http.Error(w, errors.InvalidQueryString.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := strconv.ParseUint(r.URL.Query().Get("to"), 10, 64)
+	if err != nil || to < from || to-from >= MaxBlockStreamRange {
+		http.Error(w, errors.InvalidQueryString.Error(), http.StatusBadRequest)
+		return
+	}
+
+	latest := block.GetLatestBlock(nh.storage)
+	if from > latest.Height {
+		http.Error(w, errors.BlockNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if to > latest.Height {
+		to = latest.Height
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-SEBAK-RESULT-COUNT", strconv.FormatUint(to-from+1, 10))
+
+	flusher, _ := w.(http.Flusher)
+	for height := from; height <= to; height++ {
+		if err := nh.writeStreamBlock(w, height); err != nil {
+			nh.renderNodeItem(w, NodeItemError, err)
+			return
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+func (nh NetworkHandlerNode) writeStreamBlock(w http.ResponseWriter, height uint64) (err error) {
+	var blk block.Block
+	if blk, err = block.GetBlockByHeight(nh.storage, height); err != nil {
+		return
+	}
+
+	var tps []block.TransactionPool
+	for _, hash := range append([]string{blk.ProposerTransaction}, blk.Transactions...) {
+		var tp block.TransactionPool
+		if tp, err = block.GetTransactionPool(nh.storage, hash); err != nil {
+			return
+		}
+		tps = append(tps, tp)
+	}
+
+	nh.renderNodeItem(w, NodeItemBlock, blk)
+	for _, tp := range tps {
+		nh.writeNodeItem(w, NodeItemTransaction, tp.Message)
+	}
+
+	return
+}
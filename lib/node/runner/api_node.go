@@ -32,6 +32,8 @@ type NetworkHandlerNode struct {
 	transactionPool *transaction.Pool
 	urlPrefix       string
 	conf            common.Config
+	messageMetrics  *MessageMetrics
+	configFunc      func() common.Config
 }
 
 func NewNetworkHandlerNode(localNode *node.LocalNode, network network.Network, storage *storage.LevelDBBackend, consensus *consensus.ISAAC, transactionPool *transaction.Pool, urlPrefix string, conf common.Config) *NetworkHandlerNode {
@@ -46,6 +48,26 @@ func NewNetworkHandlerNode(localNode *node.LocalNode, network network.Network, s
 	}
 }
 
+// SetMessageMetrics sets the `MessageMetrics` to count the dropped
+// transactions.
+func (api *NetworkHandlerNode) SetMessageMetrics(m *MessageMetrics) {
+	api.messageMetrics = m
+}
+
+// SetConfigFunc sets the function to get the running config, so the reloaded
+// config is used to check the received transactions; see
+// `NodeRunner.ReloadConfig()`.
+func (api *NetworkHandlerNode) SetConfigFunc(f func() common.Config) {
+	api.configFunc = f
+}
+
+func (api NetworkHandlerNode) config() common.Config {
+	if api.configFunc == nil {
+		return api.conf
+	}
+	return api.configFunc()
+}
+
 func (api NetworkHandlerNode) HandlerURLPattern(pattern string) string {
 	return fmt.Sprintf("%s%s", api.urlPrefix, pattern)
 }
@@ -78,7 +100,7 @@ func (api NetworkHandlerNode) ConnectHandler(w http.ResponseWriter, r *http.Requ
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		http.Error(w, "Error reading request body", httputils.StatusCode(err))
 		return
 	}
 
@@ -122,7 +144,8 @@ func (api NetworkHandlerNode) ReceiveTransaction(body []byte, funcs []common.Che
 		NetworkID:       api.consensus.NetworkID,
 		Message:         message,
 		Log:             log,
-		Conf:            api.conf,
+		Conf:            api.config(),
+		Metrics:         api.messageMetrics,
 	}
 
 	err := common.RunChecker(checker, common.DefaultDeferFunc)
@@ -141,7 +164,7 @@ func (api NetworkHandlerNode) MessageHandler(w http.ResponseWriter, r *http.Requ
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		http.Error(w, "Error reading request body", httputils.StatusCode(err))
 		return
 	}
 
@@ -161,7 +184,7 @@ func (api NetworkHandlerNode) BallotHandler(w http.ResponseWriter, r *http.Reque
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		http.Error(w, "Error reading request body", httputils.StatusCode(err))
 		return
 	}
 
@@ -188,6 +211,7 @@ func NodeInfoWithRequest(localNode *node.LocalNode, r *http.Request) (b []byte,
 		"endpoint":   endpoint,
 		"state":      localNode.State().String(),
 		"validators": localNode.GetValidators(),
+		"time":       common.NowISO8601(), // to check the clock skew; see `network.ValidatorConnectionManager`
 	}
 
 	b, err = json.Marshal(info)
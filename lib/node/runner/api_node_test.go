@@ -207,6 +207,15 @@ func TestHTTP2NetworkConnect(t *testing.T) {
 	nodeStr := removeWhiteSpaces(string(o))
 
 	returnMsg, _ := c0.Connect(nodeRunner.Node())
+
+	// the time of node is added to check the clock skew
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal(returnMsg, &info))
+	_, err := common.ParseISO8601(info["time"].(string))
+	require.NoError(t, err)
+	delete(info, "time")
+
+	returnMsg, _ = json.Marshal(info)
 	returnStr := removeWhiteSpaces(string(returnMsg))
 
 	require.Equal(t, returnStr, nodeStr, "The connectNode and the return should be the same.")
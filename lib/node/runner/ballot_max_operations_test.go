@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// TestProposeNewBallotMaxOpsPerBlock checks the proposer stops taking the
+// transactions when `MaxOpsPerBlock` would be exceeded; the rest remain in the
+// pool.
+func TestProposeNewBallotMaxOpsPerBlock(t *testing.T) {
+	p := &ballotCheckerProposedTransaction{}
+	p.Prepare()
+
+	// every transaction has one operation
+	p.MakeBallot(3)
+	p.nr.Conf.MaxOpsPerBlock = 2
+
+	blt, err := p.nr.proposeNewBallot(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, blt.TransactionsLength())
+
+	for _, hash := range p.txHashes {
+		require.True(t, p.nr.TransactionPool.Has(hash))
+	}
+
+	// no limit
+	p.nr.Conf.MaxOpsPerBlock = 0
+	blt, err = p.nr.proposeNewBallot(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, blt.TransactionsLength())
+}
+
+// TestINITBallotOverMaxOpsPerBlock checks the ballot, which has more
+// operations than `MaxOpsPerBlock`, is voted NO.
+func TestINITBallotOverMaxOpsPerBlock(t *testing.T) {
+	p := &ballotCheckerProposedTransaction{}
+	p.Prepare()
+
+	vote := func(maxOpsPerBlock int) voting.Hole {
+		p.nr.Conf.MaxOpsPerBlock = maxOpsPerBlock
+		return voteINITBallot(t, p, p.MakeBallot(3))
+	}
+
+	require.Equal(t, voting.NO, vote(2))
+	require.Equal(t, voting.YES, vote(3))
+}
+
+// voteINITBallot runs the checkers of INIT ballot and returns the vote.
+func voteINITBallot(t *testing.T, p *ballotCheckerProposedTransaction, blt *ballot.Ballot) voting.Hole {
+	b, _ := blt.Serialize()
+	ballotMessage := common.NetworkMessage{Type: common.BallotMessage, Data: b}
+
+	baseChecker := &BallotChecker{
+		DefaultChecker: common.DefaultChecker{Funcs: DefaultHandleBaseBallotCheckerFuncs},
+		NodeRunner:     p.nr,
+		LocalNode:      p.nr.Node(),
+		NetworkID:      p.nr.NetworkID(),
+		Message:        ballotMessage,
+		Log:            p.nr.Log(),
+		VotingHole:     voting.NOTYET,
+	}
+	require.NoError(t, common.RunChecker(baseChecker, common.DefaultDeferFunc))
+
+	checker := &BallotChecker{
+		DefaultChecker: common.DefaultChecker{Funcs: DefaultHandleINITBallotCheckerFuncs},
+		NodeRunner:     p.nr,
+		LocalNode:      p.nr.Node(),
+		NetworkID:      p.nr.NetworkID(),
+		Message:        ballotMessage,
+		Ballot:         baseChecker.Ballot,
+		VotingHole:     voting.NOTYET,
+		Log:            p.nr.Log(),
+	}
+	require.NoError(t, common.RunChecker(checker, common.DefaultDeferFunc))
+
+	return checker.VotingHole
+}
+
+// makeWeightedTransaction makes the valid transaction of the new account; the
+// heavy one creates account and the light one pays to the genesis account.
+func makeWeightedTransaction(p *ballotCheckerProposedTransaction, heavy bool) transaction.Transaction {
+	kp := keypair.Random()
+	account := block.NewBlockAccount(kp.Address(), common.BaseReserve.MustMult(2))
+	account.MustSave(p.nr.Storage())
+
+	var tx transaction.Transaction
+	if heavy {
+		tx = transaction.MakeTransactionCreateAccount(networkID, kp, keypair.Random().Address(), common.BaseReserve)
+	} else {
+		tx = transaction.TestMakeTransactionWithKeypair(networkID, 1, kp, block.GenesisKP)
+	}
+	tx.B.SequenceID = account.SequenceID
+	tx.Sign(kp, networkID)
+
+	return tx
+}
+
+// makeBallotWithTransactions is `ballotCheckerProposedTransaction.MakeBallot()`
+// with the given transactions.
+func makeBallotWithTransactions(p *ballotCheckerProposedTransaction, txs ...transaction.Transaction) *ballot.Ballot {
+	rd := voting.Basis{
+		Round:     0,
+		Height:    p.genesisBlock.Height,
+		BlockHash: p.genesisBlock.Hash,
+		TotalTxs:  p.genesisBlock.TotalTxs,
+		TotalOps:  p.genesisBlock.TotalOps,
+	}
+
+	var hashes []string
+	for _, tx := range txs {
+		hashes = append(hashes, tx.GetHash())
+		p.nr.TransactionPool.Add(tx)
+	}
+
+	blt := ballot.NewBallot(p.proposerNode.Address(), p.proposerNode.Address(), rd, hashes)
+
+	opc, _ := ballot.NewCollectTxFeeFromBallot(*blt, p.commonAccount.Address, txs...)
+	opi, _ := ballot.NewInflationFromBallotWithSchedule(*blt, p.commonAccount.Address, p.initialBalance, p.nr.Conf.InflationSchedule)
+	ptx, err := ballot.NewProposerTransactionFromBallot(*blt, opc, opi)
+	if err != nil {
+		panic(err)
+	}
+
+	blt.SetProposerTransaction(ptx)
+	blt.SetVote(ballot.StateINIT, voting.YES)
+	blt.Sign(p.proposerNode.Keypair(), networkID)
+
+	return blt
+}
+
+// TestProposeNewBallotMaxBlockWeight checks the proposer stops taking the
+// transactions when `MaxBlockWeight` would be exceeded, even if the number of
+// operations is under `MaxOpsPerBlock`.
+func TestProposeNewBallotMaxBlockWeight(t *testing.T) {
+	p := &ballotCheckerProposedTransaction{}
+	p.Prepare()
+
+	p.nr.Conf.TransactionSelectionPolicy = common.TransactionSelectionFIFO
+	p.nr.Conf.OperationWeights = common.OperationWeights{string(operation.TypeCreateAccount): 5}
+	p.nr.Conf.MaxBlockWeight = 7
+
+	heavy := makeWeightedTransaction(p, true)
+	lights := []transaction.Transaction{
+		makeWeightedTransaction(p, false),
+		makeWeightedTransaction(p, false),
+		makeWeightedTransaction(p, false),
+	}
+
+	for _, tx := range append([]transaction.Transaction{heavy}, lights...) {
+		require.True(t, p.nr.TransactionPool.Add(tx))
+	}
+
+	// 5 + 1 + 1; the last light one is over the weight
+	blt, err := p.nr.proposeNewBallot(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{heavy.GetHash(), lights[0].GetHash(), lights[1].GetHash()}, blt.Transactions())
+	require.True(t, p.nr.TransactionPool.Has(lights[2].GetHash()))
+}
+
+// TestINITBallotOverMaxBlockWeight checks the ballot over `MaxBlockWeight` is
+// voted NO, even if the number of operations is under `MaxOpsPerBlock`.
+func TestINITBallotOverMaxBlockWeight(t *testing.T) {
+	p := &ballotCheckerProposedTransaction{}
+	p.Prepare()
+
+	p.nr.Conf.OperationWeights = common.OperationWeights{string(operation.TypeCreateAccount): 5}
+	p.nr.Conf.MaxBlockWeight = 7
+
+	{ // 5 + 1 + 1, just at the limit
+		blt := makeBallotWithTransactions(
+			p,
+			makeWeightedTransaction(p, true),
+			makeWeightedTransaction(p, false),
+			makeWeightedTransaction(p, false),
+		)
+		require.Equal(t, voting.YES, voteINITBallot(t, p, blt))
+	}
+
+	{ // 5 + 1 + 1 + 1; 4 operations are under `MaxOpsPerBlock`
+		p.nr.Conf.MaxOpsPerBlock = 4
+		blt := makeBallotWithTransactions(
+			p,
+			makeWeightedTransaction(p, true),
+			makeWeightedTransaction(p, false),
+			makeWeightedTransaction(p, false),
+			makeWeightedTransaction(p, false),
+		)
+		require.Equal(t, voting.NO, voteINITBallot(t, p, blt))
+	}
+
+	{ // 1 + 1 + 1 + 1 + 1 + 1 + 1; only light operations
+		var txs []transaction.Transaction
+		for i := 0; i < 7; i++ {
+			txs = append(txs, makeWeightedTransaction(p, false))
+		}
+		p.nr.Conf.MaxOpsPerBlock = 0
+		require.Equal(t, voting.YES, voteINITBallot(t, p, makeBallotWithTransactions(p, txs...)))
+	}
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/consensus"
+)
+
+// cancelAfterContext is canceled when `Err()` is called `after` times, so the
+// proposal can be canceled at each step of the assembly.
+type cancelAfterContext struct {
+	context.Context
+	sync.Mutex
+
+	after int
+	calls int
+}
+
+func (c *cancelAfterContext) Err() error {
+	c.Lock()
+	defer c.Unlock()
+
+	c.calls++
+	if c.calls >= c.after {
+		return context.Canceled
+	}
+	return nil
+}
+
+// TestProposeNewBallotCancel cancels `proposeNewBallot()` at every step of the
+// assembly; the canceled proposal makes no ballot and the transactions are
+// left in the pool.
+func TestProposeNewBallotCancel(t *testing.T) {
+	p := &ballotCheckerProposedTransaction{}
+	p.Prepare()
+	p.MakeBallot(3)
+
+	cm := p.nr.ConnectionManager().(*TestConnectionManager)
+
+	var canceled int
+	for after := 1; ; after++ {
+		ctx := &cancelAfterContext{Context: context.Background(), after: after}
+		blt, err := p.nr.proposeNewBallot(ctx, 0)
+		if err == nil {
+			require.Equal(t, 3, blt.TransactionsLength())
+			break
+		}
+
+		require.Equal(t, context.Canceled, err)
+		require.Equal(t, ballot.Ballot{}, blt)
+		require.Equal(t, 0, len(cm.Messages()))
+		for _, hash := range p.txHashes {
+			require.True(t, p.nr.TransactionPool.Has(hash))
+		}
+		canceled++
+	}
+
+	// canceled in the middle of checking the transactions
+	require.True(t, canceled > 3+len(p.txHashes))
+	require.Equal(t, 1, len(cm.Messages()))
+}
+
+// TestISAACStateManagerCancelProposal checks the proposal is canceled by the
+// later state and by `Stop()`.
+func TestISAACStateManagerCancelProposal(t *testing.T) {
+	nr, _, _ := createNodeRunnerForTesting(2, common.NewConfig(), nil)
+	sm := nr.isaacStateManager
+	sm.EnableStepMode()
+	sm.Start()
+
+	state := consensus.ISAACState{Height: 1, Round: 1, BallotState: ballot.StateINIT}
+
+	{ // the earlier state does not cancel
+		ctx, finish := sm.startProposal(state)
+		sm.TransitISAACState(1, 0, ballot.StateSIGN)
+		require.NoError(t, ctx.Err())
+
+		sm.TransitISAACState(1, 2, ballot.StateINIT)
+		require.Equal(t, context.Canceled, ctx.Err())
+		finish()
+	}
+
+	{ // canceled by stop
+		ctx, finish := sm.startProposal(state)
+		defer finish()
+		require.NoError(t, ctx.Err())
+
+		sm.Stop()
+		require.Equal(t, context.Canceled, ctx.Err())
+	}
+
+	{ // the proposal after stop is canceled at once
+		ctx, finish := sm.startProposal(state)
+		defer finish()
+		require.Equal(t, context.Canceled, ctx.Err())
+	}
+}
@@ -78,8 +78,14 @@ func (p *ballotCheckerProposedTransaction) MakeBallot(numberOfTxs int) (blt *bal
 
 	blt = ballot.NewBallot(p.proposerNode.Address(), p.proposerNode.Address(), rd, p.txHashes)
 
-	opc, _ := ballot.NewCollectTxFeeFromBallot(*blt, p.commonAccount.Address, p.txs...)
-	opi, _ := ballot.NewInflationFromBallot(*blt, p.commonAccount.Address, p.initialBalance)
+	opc, _ := ballot.NewCollectTxFeeFromBallotWithPolicy(*blt, p.commonAccount.Address, p.nr.Conf.FeeBurnPolicy, p.txs...)
+	opi, _ := ballot.NewInflationFromBallotWithPolicy(
+		*blt,
+		p.commonAccount.Address,
+		p.initialBalance,
+		p.nr.Conf.InflationSchedule,
+		p.nr.Conf.InflationPolicy,
+	)
 
 	ptx, err := ballot.NewProposerTransactionFromBallot(*blt, opc, opi)
 	if err != nil {
@@ -881,7 +887,9 @@ func TestCheckInflationBlockIncrease(t *testing.T) {
 
 	nr := nodeRunners[0]
 
-	nr.ISAACStateManager().Conf.BlockTime = 0
+	conf := nr.ISAACStateManager().config()
+	conf.BlockTime = 0
+	nr.ISAACStateManager().setConfig(conf)
 	validators := nr.ConnectionManager().AllValidators()
 	require.Equal(t, 1, len(validators))
 	require.Equal(t, nr.localNode.Address(), validators[0])
@@ -889,7 +897,7 @@ func TestCheckInflationBlockIncrease(t *testing.T) {
 	isaac := nr.Consensus()
 
 	getCommonAccountBalance := func() common.Amount {
-		commonAccount, _ := block.GetBlockAccount(nr.Storage(), nr.CommonAccountAddress)
+		commonAccount, _ := block.GetBlockAccount(nr.Storage(), nr.NetworkParams().CommonAccount)
 		return commonAccount.Balance
 	}
 
@@ -933,11 +941,11 @@ func TestCheckInflationBlockIncrease(t *testing.T) {
 
 	t.Logf(
 		"CalculateInflation(initial balance, inflation ratio): initial balance=%v inflation ratio=%s",
-		nr.InitialBalance,
+		nr.NetworkParams().InitialBalance,
 		common.InflationRatioString,
 	)
 
-	inflationAmount, err := common.CalculateInflation(nr.InitialBalance)
+	inflationAmount, err := common.CalculateInflation(nr.NetworkParams().InitialBalance)
 	require.NoError(t, err)
 
 	var previous common.Amount
@@ -1040,3 +1048,326 @@ func TestProposedTransactionReachedBlockHeightEndOfInflation(t *testing.T) {
 		require.Equal(t, errors.InvalidOperation, err)
 	}
 }
+
+func TestProposedTransactionWithInflationSchedule(t *testing.T) {
+	p := &ballotCheckerProposedTransaction{}
+	p.Prepare()
+
+	height := p.genesisBlock.Height
+	p.nr.Conf.InflationSchedule = common.InflationSchedule{
+		{Height: 0, Ratio: common.InflationRatio},
+		{Height: height, Ratio: common.InflationRatio / 2},
+	}
+
+	runChecker := func(blt *ballot.Ballot) error {
+		b, _ := blt.Serialize()
+		ballotMessage := common.NetworkMessage{Type: common.BallotMessage, Data: b}
+
+		baseChecker := &BallotChecker{
+			DefaultChecker: common.DefaultChecker{Funcs: DefaultHandleBaseBallotCheckerFuncs},
+			NodeRunner:     p.nr,
+			LocalNode:      p.nr.Node(),
+			NetworkID:      p.nr.NetworkID(),
+			Message:        ballotMessage,
+			Log:            p.nr.Log(),
+			VotingHole:     voting.NOTYET,
+		}
+		if err := common.RunChecker(baseChecker, common.DefaultDeferFunc); err != nil {
+			return err
+		}
+
+		checker := &BallotChecker{
+			DefaultChecker: common.DefaultChecker{Funcs: DefaultHandleINITBallotCheckerFuncs},
+			NodeRunner:     p.nr,
+			LocalNode:      p.nr.Node(),
+			NetworkID:      p.nr.NetworkID(),
+			Message:        ballotMessage,
+			Ballot:         baseChecker.Ballot,
+			VotingHole:     voting.NOTYET,
+			Log:            p.nr.Log(),
+		}
+		return common.RunChecker(checker, common.DefaultDeferFunc)
+	}
+
+	{ // inflation follows the schedule
+		blt := p.MakeBallot(1)
+
+		opb, err := blt.ProposerTransaction().Inflation()
+		require.NoError(t, err)
+
+		expected, err := common.CalculateInflation(p.initialBalance)
+		require.NoError(t, err)
+		require.Equal(t, expected/2, opb.Amount)
+		require.Equal(t, common.InflationRatio2String(common.InflationRatio/2), opb.Ratio)
+
+		require.NoError(t, runChecker(blt))
+	}
+
+	{ // inflation by the previous step is rejected
+		p.nr.Conf.InflationSchedule = common.DefaultInflationSchedule
+		blt := p.MakeBallot(1)
+
+		p.nr.Conf.InflationSchedule = common.InflationSchedule{
+			{Height: 0, Ratio: common.InflationRatio},
+			{Height: height, Ratio: common.InflationRatio / 2},
+		}
+		require.Equal(t, errors.InvalidOperation, runChecker(blt))
+	}
+}
+
+// TestProposedTransactionWithInflationPolicy checks the inflation is omitted
+// at and after the height, where the supply reaches
+// `common.InflationPolicy.MaxSupply`, and the validators accept it.
+func TestProposedTransactionWithInflationPolicy(t *testing.T) {
+	p := &ballotCheckerProposedTransaction{}
+	p.Prepare()
+
+	// the supply of the test network is already `common.MaximumBalance`
+	p.initialBalance = common.Amount(5000000000000000)
+	p.nr.networkParams.InitialBalance = p.initialBalance
+
+	runChecker := func(blt *ballot.Ballot) error {
+		b, _ := blt.Serialize()
+		ballotMessage := common.NetworkMessage{Type: common.BallotMessage, Data: b}
+
+		baseChecker := &BallotChecker{
+			DefaultChecker: common.DefaultChecker{Funcs: DefaultHandleBaseBallotCheckerFuncs},
+			NodeRunner:     p.nr,
+			LocalNode:      p.nr.Node(),
+			NetworkID:      p.nr.NetworkID(),
+			Message:        ballotMessage,
+			Log:            p.nr.Log(),
+			VotingHole:     voting.NOTYET,
+		}
+		if err := common.RunChecker(baseChecker, common.DefaultDeferFunc); err != nil {
+			return err
+		}
+
+		checker := &BallotChecker{
+			DefaultChecker: common.DefaultChecker{Funcs: DefaultHandleINITBallotCheckerFuncs},
+			NodeRunner:     p.nr,
+			LocalNode:      p.nr.Node(),
+			NetworkID:      p.nr.NetworkID(),
+			Message:        ballotMessage,
+			Ballot:         baseChecker.Ballot,
+			VotingHole:     voting.NOTYET,
+			Log:            p.nr.Log(),
+		}
+		return common.RunChecker(checker, common.DefaultDeferFunc)
+	}
+
+	setHeight := func(height uint64) {
+		genesisBlock := p.genesisBlock
+		genesisBlock.Height = height
+		genesisBlock.Hash = base58.Encode(common.MustMakeObjectHash(genesisBlock))
+		p.genesisBlock = genesisBlock
+		p.genesisBlock.Save(p.nr.Storage())
+	}
+
+	inflationAmount := func(blt *ballot.Ballot) common.Amount {
+		opb, err := blt.ProposerTransaction().Inflation()
+		require.NoError(t, err)
+		return opb.Amount
+	}
+
+	expected, err := common.CalculateInflation(p.initialBalance)
+	require.NoError(t, err)
+
+	capHeight := uint64(10)
+	supply, err := p.nr.Conf.InflationSchedule.Supply(capHeight, p.initialBalance)
+	require.NoError(t, err)
+	require.Equal(t, p.initialBalance+expected*common.Amount(capHeight-common.GenesisBlockHeight), supply)
+
+	{ // before the cap, the last inflation is reduced to reach the cap
+		setHeight(capHeight - 1)
+		p.nr.Conf.InflationPolicy = common.InflationPolicy{MaxSupply: supply - expected/2}
+
+		blt := p.MakeBallot(1)
+		require.Equal(t, expected/2, inflationAmount(blt))
+		require.NoError(t, runChecker(blt))
+	}
+
+	p.nr.Conf.InflationPolicy = common.InflationPolicy{MaxSupply: supply}
+	for _, height := range []uint64{capHeight, capHeight + 1} { // at and after the cap
+		setHeight(height)
+
+		// the inflation over the cap is rejected
+		p.nr.Conf.InflationPolicy = common.DefaultInflationPolicy
+		blt := p.MakeBallot(1)
+		require.Equal(t, expected, inflationAmount(blt))
+		p.nr.Conf.InflationPolicy = common.InflationPolicy{MaxSupply: supply}
+		require.Equal(t, errors.InvalidOperation, runChecker(blt), "height=%d", height)
+
+		blt = p.MakeBallot(1)
+		require.Equal(t, common.Amount(0), inflationAmount(blt), "height=%d", height)
+		require.NoError(t, runChecker(blt), "height=%d", height)
+	}
+}
+
+// TestProposedTransactionWithFeeBurnPolicy checks the collected fee is split
+// by `common.FeeBurnPolicy`, the validators reject the different split and
+// only the rest of the burned fee is paid into the common account.
+func TestProposedTransactionWithFeeBurnPolicy(t *testing.T) {
+	p := &ballotCheckerProposedTransaction{}
+	p.Prepare()
+
+	runChecker := func(blt *ballot.Ballot) error {
+		b, _ := blt.Serialize()
+		ballotMessage := common.NetworkMessage{Type: common.BallotMessage, Data: b}
+
+		baseChecker := &BallotChecker{
+			DefaultChecker: common.DefaultChecker{Funcs: DefaultHandleBaseBallotCheckerFuncs},
+			NodeRunner:     p.nr,
+			LocalNode:      p.nr.Node(),
+			NetworkID:      p.nr.NetworkID(),
+			Message:        ballotMessage,
+			Log:            p.nr.Log(),
+			VotingHole:     voting.NOTYET,
+		}
+		if err := common.RunChecker(baseChecker, common.DefaultDeferFunc); err != nil {
+			return err
+		}
+
+		checker := &BallotChecker{
+			DefaultChecker: common.DefaultChecker{Funcs: DefaultHandleINITBallotCheckerFuncs},
+			NodeRunner:     p.nr,
+			LocalNode:      p.nr.Node(),
+			NetworkID:      p.nr.NetworkID(),
+			Message:        ballotMessage,
+			Ballot:         baseChecker.Ballot,
+			VotingHole:     voting.NOTYET,
+			Log:            p.nr.Log(),
+		}
+		return common.RunChecker(checker, common.DefaultDeferFunc)
+	}
+
+	collectTxFee := func(blt *ballot.Ballot) operation.CollectTxFee {
+		opb, err := blt.ProposerTransaction().CollectTxFee()
+		require.NoError(t, err)
+		return opb
+	}
+
+	{ // by default, nothing is burned
+		blt := p.MakeBallot(4)
+		opb := collectTxFee(blt)
+		require.Equal(t, common.BaseFee.MustMult(4), opb.Amount)
+		require.Equal(t, common.Amount(0), opb.Burned)
+		require.NoError(t, runChecker(blt))
+	}
+
+	p.nr.Conf.FeeBurnPolicy = common.FeeBurnPolicy{Ratio: 2500}
+
+	blt := p.MakeBallot(4)
+	fee := common.BaseFee.MustMult(4)
+	opb := collectTxFee(blt)
+	require.Equal(t, fee, opb.Amount)
+	require.Equal(t, fee/4, opb.Burned)
+	require.Equal(t, fee-fee/4, opb.GetAmount())
+	require.NoError(t, runChecker(blt))
+
+	// the validator with the different ratio rejects it
+	for _, ratio := range []uint64{0, 5000} {
+		invalid := p.MakeBallot(4)
+		p.nr.Conf.FeeBurnPolicy = common.FeeBurnPolicy{Ratio: ratio}
+		require.Equal(t, errors.InvalidOperation, runChecker(invalid), "ratio=%d", ratio)
+		p.nr.Conf.FeeBurnPolicy = common.FeeBurnPolicy{Ratio: 2500}
+	}
+
+	{ // the proposer, which does not burn, is rejected
+		ptx := blt.ProposerTransaction()
+		ptx.B.Operations = append([]operation.Operation{}, ptx.B.Operations...)
+		ptx.B.Operations[0].B = operation.NewCollectTxFee(opb.Target, opb.Amount, opb.Txs, opb.Height, opb.BlockHash, opb.TotalTxs)
+		invalid := *blt
+		invalid.SetProposerTransaction(ptx)
+		invalid.Sign(p.proposerNode.Keypair(), networkID)
+		require.Equal(t, errors.InvalidOperation, runChecker(&invalid))
+	}
+
+	{ // only the rest of the burned fee is paid into the common account
+		st := p.nr.Storage()
+		before, err := block.GetBlockAccount(st, p.commonAccount.Address)
+		require.NoError(t, err)
+
+		opi, err := blt.ProposerTransaction().Inflation()
+		require.NoError(t, err)
+
+		blk := block.TestMakeNewBlockWithPrevBlock(p.genesisBlock, blt.Transactions())
+		require.NoError(t, FinishProposerTransaction(st, blk, blt.ProposerTransaction(), p.nr.Log()))
+
+		after, err := block.GetBlockAccount(st, p.commonAccount.Address)
+		require.NoError(t, err)
+		require.Equal(t, before.Balance+opi.Amount+fee-fee/4, after.Balance)
+	}
+}
+
+func TestProposedTransactionNotMatchedWithExpected(t *testing.T) {
+	p := &ballotCheckerProposedTransaction{}
+	p.Prepare()
+
+	runChecker := func(blt *ballot.Ballot) voting.Hole {
+		b, _ := blt.Serialize()
+		ballotMessage := common.NetworkMessage{Type: common.BallotMessage, Data: b}
+
+		baseChecker := &BallotChecker{
+			DefaultChecker: common.DefaultChecker{Funcs: DefaultHandleBaseBallotCheckerFuncs},
+			NodeRunner:     p.nr,
+			LocalNode:      p.nr.Node(),
+			NetworkID:      p.nr.NetworkID(),
+			Message:        ballotMessage,
+			Log:            p.nr.Log(),
+			VotingHole:     voting.NOTYET,
+		}
+		require.NoError(t, common.RunChecker(baseChecker, common.DefaultDeferFunc))
+
+		checker := &BallotChecker{
+			DefaultChecker: common.DefaultChecker{Funcs: DefaultHandleINITBallotCheckerFuncs},
+			NodeRunner:     p.nr,
+			LocalNode:      p.nr.Node(),
+			NetworkID:      p.nr.NetworkID(),
+			Message:        ballotMessage,
+			Ballot:         baseChecker.Ballot,
+			VotingHole:     voting.NOTYET,
+			Log:            p.nr.Log(),
+		}
+		require.NoError(t, common.RunChecker(checker, common.DefaultDeferFunc))
+
+		return checker.VotingHole
+	}
+
+	// tamper replaces the operation of the proposer transaction and signs the
+	// ballot again, so only the recomputed operations can catch it.
+	tamper := func(blt *ballot.Ballot, i int, opb operation.Body) {
+		ptx := blt.ProposerTransaction()
+		ptx.B.Operations[i].B = opb
+		ptx.H.Hash = ptx.B.MakeHashString()
+		blt.SetProposerTransaction(ptx)
+		blt.Sign(p.proposerNode.Keypair(), networkID)
+	}
+
+	{ // matched
+		blt := p.MakeBallot(2)
+		require.Equal(t, voting.YES, runChecker(blt))
+	}
+
+	{ // `CollectTxFee` is tampered
+		blt := p.MakeBallot(2)
+		opb, err := blt.ProposerTransaction().CollectTxFee()
+		require.NoError(t, err)
+		opb.TotalOps = 100
+		tamper(blt, 0, opb)
+
+		require.NoError(t, blt.ProposerTransaction().IsWellFormedWithBallot(networkID, *blt, common.NewConfig()))
+		require.Equal(t, voting.NO, runChecker(blt))
+	}
+
+	{ // `Inflation` is tampered
+		blt := p.MakeBallot(2)
+		opb, err := blt.ProposerTransaction().Inflation()
+		require.NoError(t, err)
+		opb.TotalOps = 100
+		tamper(blt, 1, opb)
+
+		require.NoError(t, blt.ProposerTransaction().IsWellFormedWithBallot(networkID, *blt, common.NewConfig()))
+		require.Equal(t, voting.NO, runChecker(blt))
+	}
+}
@@ -0,0 +1,32 @@
+package runner
+
+import (
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+)
+
+// notifyFinalizedBlock emits `ConsensusEventBlockFinalized` of the block,
+// which reaches `common.Config.FinalityConfirmations` by the new block.
+func (nr *NodeRunner) notifyFinalizedBlock(latest block.Block) {
+	confirmations := nr.Config().FinalityConfirmations
+	if latest.Height < common.GenesisBlockHeight+confirmations {
+		return
+	}
+
+	finalized := latest
+	if confirmations > 0 {
+		var err error
+		if finalized, err = block.GetBlockByHeight(nr.Storage(), latest.Height-confirmations); err != nil {
+			nr.Log().Error("failed to get the finalized block", "height", latest.Height-confirmations, "error", err)
+			return
+		}
+	}
+
+	nr.ConsensusEvents().Emit(ConsensusEvent{
+		Type:      ConsensusEventBlockFinalized,
+		Height:    finalized.Height,
+		Round:     finalized.Round,
+		Proposer:  finalized.Proposer,
+		BlockHash: finalized.Hash,
+	})
+}
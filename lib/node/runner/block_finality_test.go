@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+)
+
+// TestNotifyFinalizedBlock checks the block is notified as final when it is
+// followed by `FinalityConfirmations` blocks.
+func TestNotifyFinalizedBlock(t *testing.T) {
+	run := func(confirmations uint64, newBlocks int) (blocks []block.Block, finalized []ConsensusEvent) {
+		conf := common.NewConfig()
+		conf.FinalityConfirmations = confirmations
+		nr, _, _ := createNodeRunnerForTesting(1, conf, nil)
+
+		nr.ConsensusEvents().Subscribe(func(event ConsensusEvent) {
+			if event.Type == ConsensusEventBlockFinalized {
+				finalized = append(finalized, event)
+			}
+		})
+
+		latest := nr.Consensus().LatestBlock()
+		blocks = append(blocks, latest)
+		for i := 0; i < newBlocks; i++ {
+			latest = block.TestMakeNewBlockWithPrevBlock(latest, []string{})
+			require.NoError(t, latest.Save(nr.Storage()))
+			blocks = append(blocks, latest)
+
+			nr.notifyFinalizedBlock(latest)
+		}
+
+		return
+	}
+
+	{ // the new block is final by itself
+		blocks, finalized := run(0, 2)
+		require.Equal(t, 2, len(finalized))
+		require.Equal(t, blocks[1].Height, finalized[0].Height)
+		require.Equal(t, blocks[1].Hash, finalized[0].BlockHash)
+		require.Equal(t, blocks[2].Hash, finalized[1].BlockHash)
+	}
+
+	{ // under the confirmations, nothing is notified
+		_, finalized := run(3, 2)
+		require.Equal(t, 0, len(finalized))
+	}
+
+	{ // the genesis block reaches the confirmations by the 3rd new block
+		blocks, finalized := run(3, 4)
+		require.Equal(t, 2, len(finalized))
+		require.Equal(t, common.GenesisBlockHeight, finalized[0].Height)
+		require.Equal(t, blocks[0].Hash, finalized[0].BlockHash)
+		require.Equal(t, blocks[1].Height, finalized[1].Height)
+		require.Equal(t, blocks[1].Hash, finalized[1].BlockHash)
+		require.Equal(t, blocks[1].Proposer, finalized[1].Proposer)
+	}
+}
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"sync"
 	"time"
 
 	logging "github.com/inconshreveable/log15"
@@ -12,11 +13,28 @@ import (
 )
 
 type SavingBlockOperations struct {
+	sync.Mutex
+
 	st  *storage.LevelDBBackend
 	log logging.Logger
 
 	saveBlock    chan block.Block
 	checkedBlock uint64 // block.Block.Height
+
+	// retainedBlocks is the number of the recent blocks, which are not
+	// pruned; `0` does not prune; see `common.Config.RetainedBlocks`.
+	retainedBlocks uint64
+
+	// writeBufferBlocks and writeBufferInterval decide when the buffered
+	// `BlockOperation`s are flushed; see `common.Config.WriteBufferBlocks`.
+	writeBufferBlocks   uint64
+	writeBufferInterval time.Duration
+	buffer              *storage.LevelDBBackend
+	buffered            []block.Block
+	bufferedAt          time.Time
+
+	// codec encodes the `BlockOperation`s; see `common.Config.StorageCodec`.
+	codec common.StorageCodec
 }
 
 func NewSavingBlockOperations(st *storage.LevelDBBackend, logger logging.Logger) *SavingBlockOperations {
@@ -30,9 +48,39 @@ func NewSavingBlockOperations(st *storage.LevelDBBackend, logger logging.Logger)
 		log:          logger,
 		saveBlock:    make(chan block.Block, 10),
 		checkedBlock: common.GenesisBlockHeight,
+		codec:        common.DefaultStorageCodec,
 	}
 }
 
+// SetStorageCodec sets the codec of the `BlockOperation`s to be saved.
+func (sb *SavingBlockOperations) SetStorageCodec(codec common.StorageCodec) *SavingBlockOperations {
+	sb.codec = codec
+
+	return sb
+}
+
+// SetRetainedBlocks sets the number of the recent blocks, which are not
+// pruned; `0` does not prune.
+func (sb *SavingBlockOperations) SetRetainedBlocks(n uint64) *SavingBlockOperations {
+	sb.retainedBlocks = n
+
+	return sb
+}
+
+// SetWriteBuffer sets the number of the blocks and the maximum duration to
+// buffer the `BlockOperation`s before writing them; if `blocks` is `0` or `1`,
+// they are written by every block.
+func (sb *SavingBlockOperations) SetWriteBuffer(blocks uint64, interval time.Duration) *SavingBlockOperations {
+	sb.writeBufferBlocks = blocks
+	sb.writeBufferInterval = interval
+
+	return sb
+}
+
+func (sb *SavingBlockOperations) isBuffering() bool {
+	return sb.writeBufferBlocks > 1
+}
+
 func (sb *SavingBlockOperations) getNextBlock(height uint64) (nextBlock block.Block, err error) {
 	if height < sb.checkedBlock {
 		height = sb.checkedBlock
@@ -65,6 +113,15 @@ func (sb *SavingBlockOperations) continuousCheck() {
 // check checks whether `BlockOperation`s of latest `block.Block` are saved; if
 // not it will try to catch up to the last.
 func (sb *SavingBlockOperations) check() (err error) {
+	// the pruned blocks do not have `BlockTransaction`s to check.
+	var pruned uint64
+	if pruned, err = block.GetPrunedBlockHeight(sb.st); err != nil {
+		return
+	}
+	if pruned > sb.checkedBlock {
+		sb.checkedBlock = pruned
+	}
+
 	var checked bool
 	var blk block.Block
 	for {
@@ -81,18 +138,12 @@ func (sb *SavingBlockOperations) check() (err error) {
 
 		sb.log.Debug("check block", "block", blk)
 
-		var st *storage.LevelDBBackend
-		if st, err = sb.st.OpenBatch(); err != nil {
-			break
-		}
-
-		if err = sb.CheckByBlock(st, blk); err != nil {
-			sb.log.Error("failed to check block", "block", blk, "height", blk.Height)
-			st.Discard()
+		var isBuffered bool
+		if isBuffered, err = sb.checkBlock(blk); err != nil {
 			break
-		}
-		if err = st.Commit(); err != nil {
-			st.Discard()
+		} else if isBuffered {
+			// the buffered block and the next blocks will be written by
+			// `flush()`.
 			break
 		}
 		sb.log.Debug("checked block", "block", blk)
@@ -103,6 +154,36 @@ func (sb *SavingBlockOperations) check() (err error) {
 	return
 }
 
+// checkBlock saves the missing `BlockOperation`s of the block; the block in
+// the write buffer is skipped, so it is not written twice.
+func (sb *SavingBlockOperations) checkBlock(blk block.Block) (isBuffered bool, err error) {
+	sb.Lock()
+	defer sb.Unlock()
+
+	for _, b := range sb.buffered {
+		if b.Height == blk.Height {
+			return true, nil
+		}
+	}
+
+	var st *storage.LevelDBBackend
+	if st, err = sb.st.OpenBatch(); err != nil {
+		return
+	}
+
+	if err = sb.CheckByBlock(st, blk); err != nil {
+		sb.log.Error("failed to check block", "block", blk, "height", blk.Height)
+		st.Discard()
+		return
+	}
+	if err = st.Commit(); err != nil {
+		st.Discard()
+		return
+	}
+
+	return
+}
+
 func (sb *SavingBlockOperations) savingBlockOperationsWorker(id int, st *storage.LevelDBBackend, blk block.Block, txs <-chan string, errChan chan<- error) {
 	for hash := range txs {
 		errChan <- sb.CheckTransactionByBlock(st, blk, hash)
@@ -180,7 +261,7 @@ func (sb *SavingBlockOperations) CheckTransactionByBlock(st *storage.LevelDBBack
 		}
 
 		if !exists {
-			bt.SaveBlockOperations(st, blk)
+			bt.SaveBlockOperationsWithCodec(st, blk, sb.codec)
 			sb.log.Debug("saved missing BlockOperation", "block", blk, "transaction", hash, "operation", op)
 		}
 	}
@@ -198,12 +279,38 @@ func (sb *SavingBlockOperations) Start() {
 func (sb *SavingBlockOperations) startSaving() {
 	sb.log.Debug("start saving")
 
+	var flushTick <-chan time.Time
+	if sb.isBuffering() {
+		ticker := time.NewTicker(sb.writeBufferInterval)
+		defer ticker.Stop()
+		flushTick = ticker.C
+	}
+
 	for {
 		select {
+		case <-flushTick:
+			if err := sb.flushExpired(); err != nil {
+				sb.log.Error("failed to flush BlockOperation", "error", err)
+			}
 		case blk := <-sb.saveBlock:
+			if sb.isBuffering() {
+				if err := sb.saveBuffered(blk); err != nil {
+					// NOTE if failed, the `continuousCheck()` will fill the missings.
+					sb.log.Error("failed to save BlockOperation", "block", blk, "error", err)
+				}
+				continue
+			}
+
 			if err := sb.save(blk); err != nil {
 				// NOTE if failed, the `continuousCheck()` will fill the missings.
 				sb.log.Error("failed to save BlockOperation", "block", blk, "error", err)
+				continue
+			}
+
+			// NOTE the old blocks are pruned after `BlockOperation`s of new
+			// block are saved, so pruning does not run with saving.
+			if err := sb.prune(blk.Height); err != nil {
+				sb.log.Error("failed to prune blocks", "block", blk, "error", err)
 			}
 		}
 	}
@@ -216,6 +323,9 @@ func (sb *SavingBlockOperations) Save(blk block.Block) {
 }
 
 func (sb *SavingBlockOperations) save(blk block.Block) (err error) {
+	sb.Lock()
+	defer sb.Unlock()
+
 	sb.log.Debug("start to save BlockOperation", "block", blk)
 	defer func() {
 		sb.log.Debug("end to save BlockOperation", "block", blk, "error", err)
@@ -234,3 +344,116 @@ func (sb *SavingBlockOperations) save(blk block.Block) (err error) {
 
 	return
 }
+
+// saveBuffered saves the `BlockOperation`s of the block into the write
+// buffer; when the buffer has `writeBufferBlocks` blocks, it is flushed. The
+// buffered `BlockOperation`s are not written until flushed, but the block is
+// already committed, so if they are lost by the crash, `Check()` restores
+// them from the block.
+func (sb *SavingBlockOperations) saveBuffered(blk block.Block) (err error) {
+	sb.Lock()
+	defer sb.Unlock()
+
+	if sb.buffer == nil {
+		var st *storage.LevelDBBackend
+		if st, err = sb.st.OpenBatch(); err != nil {
+			return
+		}
+		sb.buffer = st
+		sb.bufferedAt = time.Now()
+	}
+
+	if err = sb.CheckByBlock(sb.buffer, blk); err != nil {
+		// NOTE the discarded blocks will be filled by `continuousCheck()`.
+		sb.discardBuffer()
+		return
+	}
+	sb.buffered = append(sb.buffered, blk)
+
+	if uint64(len(sb.buffered)) < sb.writeBufferBlocks {
+		return
+	}
+
+	return sb.flush()
+}
+
+// Flush writes the buffered `BlockOperation`s.
+func (sb *SavingBlockOperations) Flush() error {
+	sb.Lock()
+	defer sb.Unlock()
+
+	return sb.flush()
+}
+
+// flushExpired flushes the buffer, which is kept over `writeBufferInterval`.
+func (sb *SavingBlockOperations) flushExpired() error {
+	sb.Lock()
+	defer sb.Unlock()
+
+	if sb.buffer == nil || time.Since(sb.bufferedAt) < sb.writeBufferInterval {
+		return nil
+	}
+
+	return sb.flush()
+}
+
+func (sb *SavingBlockOperations) flush() (err error) {
+	if sb.buffer == nil {
+		return
+	}
+
+	last := sb.buffered[len(sb.buffered)-1]
+	if err = sb.buffer.Commit(); err != nil {
+		sb.discardBuffer()
+		return
+	}
+	sb.log.Debug("flushed BlockOperation", "blocks", len(sb.buffered), "height", last.Height)
+
+	sb.buffer = nil
+	sb.buffered = nil
+
+	// NOTE the old blocks are pruned after flushed, so the buffered
+	// `BlockOperation`s are not written into the pruned blocks.
+	if err := sb.prune(last.Height); err != nil {
+		sb.log.Error("failed to prune blocks", "block", last, "error", err)
+	}
+
+	return
+}
+
+func (sb *SavingBlockOperations) discardBuffer() {
+	if sb.buffer != nil {
+		sb.buffer.Discard()
+	}
+
+	sb.buffer = nil
+	sb.buffered = nil
+}
+
+// prune prunes the blocks older than the retained blocks from `height`.
+func (sb *SavingBlockOperations) prune(height uint64) (err error) {
+	if sb.retainedBlocks < 1 || height <= sb.retainedBlocks {
+		return
+	}
+
+	var st *storage.LevelDBBackend
+	if st, err = sb.st.OpenBatch(); err != nil {
+		return
+	}
+
+	var pruned int
+	if pruned, err = block.PruneBlocks(st, height-sb.retainedBlocks); err != nil {
+		st.Discard()
+		return
+	}
+	if err = st.Commit(); err != nil {
+		st.Discard()
+		return
+	}
+
+	if pruned > 0 {
+		sb.log.Debug("blocks pruned", "height", height, "pruned", pruned)
+	}
+
+	return
+}
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -105,6 +106,31 @@ func TestSavingBlockOperation(t *testing.T) {
 	}
 }
 
+func TestSavingBlockOperationStorageCodec(t *testing.T) {
+	p := &TestSavingBlockOperationHelper{}
+	p.Prepare()
+	defer p.Done()
+
+	blk := p.makeBlock(block.GetGenesis(p.st))
+
+	sb := NewSavingBlockOperations(p.st, nil).SetStorageCodec(common.StorageCodecMsgpack)
+	require.NoError(t, sb.CheckByBlock(p.st, blk))
+
+	for _, txHash := range blk.Transactions {
+		bt, err := block.GetBlockTransaction(p.st, txHash)
+		require.NoError(t, err)
+		for _, opHash := range bt.Operations {
+			b, err := p.st.GetRaw(block.GetBlockOperationKey(opHash))
+			require.NoError(t, err)
+			require.Equal(t, common.StorageCodecHeaderMsgpack, b[0])
+
+			bo, err := block.GetBlockOperation(p.st, opHash)
+			require.NoError(t, err)
+			require.Equal(t, txHash, bo.TxHash)
+		}
+	}
+}
+
 func TestSavingBlockOperationMissingInBlock(t *testing.T) {
 	p := &TestSavingBlockOperationHelper{}
 	p.Prepare()
@@ -148,3 +174,267 @@ func TestSavingBlockOperationMissingInBlock(t *testing.T) {
 		}
 	}
 }
+
+func TestSavingBlockOperationPrune(t *testing.T) {
+	p := &TestSavingBlockOperationHelper{}
+	p.Prepare()
+	defer p.Done()
+
+	genesisAccount, err := block.GetBlockAccount(p.st, block.GenesisKP.Address())
+	require.NoError(t, err)
+
+	var blocks []block.Block
+	prev := block.GetGenesis(p.st)
+	for i := 0; i < 5; i++ {
+		prev = p.makeBlock(prev)
+		blocks = append(blocks, prev)
+	}
+
+	sb := NewSavingBlockOperations(p.st, nil).SetRetainedBlocks(2)
+	require.NoError(t, sb.Check())
+
+	sources := map[string]string{} // block hash: source
+	for _, blk := range blocks {
+		bt, err := block.GetBlockTransaction(p.st, blk.Transactions[0])
+		require.NoError(t, err)
+		sources[blk.Hash] = bt.Source
+	}
+
+	// the iterator, which is opened before pruning, still works
+	iterFunc, closeFunc := block.GetBlockTransactionsByConfirmed(p.st, nil)
+	defer closeFunc()
+
+	require.NoError(t, sb.prune(blocks[4].Height))
+
+	pruned, err := block.GetPrunedBlockHeight(p.st)
+	require.NoError(t, err)
+	require.Equal(t, blocks[2].Height, pruned)
+
+	var iterated int
+	for {
+		bt, hasNext, _ := iterFunc()
+		if !hasNext {
+			break
+		}
+		require.True(t, bt.Block == blocks[3].Hash || bt.Block == blocks[4].Hash || bt.Block == block.GetGenesis(p.st).Hash)
+		iterated++
+	}
+	require.Equal(t, 1+(len(blocks[3].Transactions)+1)*2, iterated)
+
+	countOperationsBySource := func(source string) (n int) {
+		iterFunc, closeFunc := block.GetBlockOperationsBySource(p.st, source, nil)
+		defer closeFunc()
+		for {
+			_, hasNext, _ := iterFunc()
+			if !hasNext {
+				return
+			}
+			n++
+		}
+	}
+
+	countTransactionsByBlock := func(hash string) (n int) {
+		iterFunc, closeFunc := block.GetBlockTransactionsByBlock(p.st, hash, nil)
+		defer closeFunc()
+		for {
+			_, hasNext, _ := iterFunc()
+			if !hasNext {
+				return
+			}
+			n++
+		}
+	}
+
+	for i, blk := range blocks {
+		// blocks are not pruned
+		_, err := block.GetBlockByHeight(p.st, blk.Height)
+		require.NoError(t, err)
+
+		txHashes := append([]string{blk.ProposerTransaction}, blk.Transactions...)
+		if i < 3 { // pruned
+			for _, hash := range txHashes {
+				exists, err := block.ExistsBlockTransaction(p.st, hash)
+				require.NoError(t, err)
+				require.False(t, exists)
+
+				iterFunc, closeFunc := block.GetBlockOperationsByTxHash(p.st, hash, nil)
+				_, hasNext, _ := iterFunc()
+				closeFunc()
+				require.False(t, hasNext)
+			}
+			require.Equal(t, 0, countOperationsBySource(sources[blk.Hash]))
+			require.Equal(t, 0, countTransactionsByBlock(blk.Hash))
+			continue
+		}
+
+		for _, hash := range txHashes {
+			bt, err := block.GetBlockTransaction(p.st, hash)
+			require.NoError(t, err)
+			for _, opHash := range bt.Operations {
+				exists, err := block.ExistsBlockOperation(p.st, opHash)
+				require.NoError(t, err)
+				require.True(t, exists)
+			}
+		}
+		require.Equal(t, len(blk.Transactions), countOperationsBySource(sources[blk.Hash]))
+		require.Equal(t, len(txHashes), countTransactionsByBlock(blk.Hash))
+	}
+
+	// account state is kept
+	account, err := block.GetBlockAccount(p.st, block.GenesisKP.Address())
+	require.NoError(t, err)
+	require.Equal(t, genesisAccount.Balance, account.Balance)
+
+	// genesis block is not pruned
+	_, err = block.GetBlockTransaction(p.st, block.GetGenesis(p.st).Transactions[0])
+	require.NoError(t, err)
+
+	// checking again skips the pruned blocks
+	require.NoError(t, NewSavingBlockOperations(p.st, nil).Check())
+	exists, err := block.ExistsBlockTransaction(p.st, blocks[0].Transactions[0])
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func (p *TestSavingBlockOperationHelper) existsBlockOperations(blk block.Block) bool {
+	for _, txHash := range blk.Transactions {
+		bt, err := block.GetBlockTransaction(p.st, txHash)
+		if err != nil {
+			panic(err)
+		}
+		for _, opHash := range bt.Operations {
+			if exists, err := block.ExistsBlockOperation(p.st, opHash); err != nil {
+				panic(err)
+			} else if !exists {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func TestSavingBlockOperationWriteBuffer(t *testing.T) {
+	p := &TestSavingBlockOperationHelper{}
+	p.Prepare()
+	defer p.Done()
+
+	sb := NewSavingBlockOperations(p.st, nil).SetWriteBuffer(3, time.Minute)
+
+	blk0 := p.makeBlock(block.GetGenesis(p.st))
+	blk1 := p.makeBlock(blk0)
+	blk2 := p.makeBlock(blk1)
+
+	require.NoError(t, sb.saveBuffered(blk0))
+	require.NoError(t, sb.saveBuffered(blk1))
+
+	// not yet flushed
+	require.False(t, p.existsBlockOperations(blk0))
+	require.False(t, p.existsBlockOperations(blk1))
+
+	// `check()` skips the buffered blocks
+	require.NoError(t, sb.Check())
+	require.False(t, p.existsBlockOperations(blk0))
+	require.False(t, p.existsBlockOperations(blk1))
+
+	// reaches `writeBufferBlocks`, the buffer is flushed
+	require.NoError(t, sb.saveBuffered(blk2))
+	require.Nil(t, sb.buffer)
+	require.Equal(t, 0, len(sb.buffered))
+	for _, blk := range []block.Block{blk0, blk1, blk2} {
+		require.True(t, p.existsBlockOperations(blk))
+	}
+}
+
+func TestSavingBlockOperationWriteBufferExpired(t *testing.T) {
+	p := &TestSavingBlockOperationHelper{}
+	p.Prepare()
+	defer p.Done()
+
+	sb := NewSavingBlockOperations(p.st, nil).SetWriteBuffer(3, time.Minute)
+
+	blk := p.makeBlock(block.GetGenesis(p.st))
+	require.NoError(t, sb.saveBuffered(blk))
+
+	// not expired
+	require.NoError(t, sb.flushExpired())
+	require.False(t, p.existsBlockOperations(blk))
+
+	sb.bufferedAt = time.Now().Add(-time.Minute)
+	require.NoError(t, sb.flushExpired())
+	require.True(t, p.existsBlockOperations(blk))
+}
+
+// TestSavingBlockOperationWriteBufferCrash checks the buffered
+// `BlockOperation`s lost by the crash are restored by `Check()` at the next
+// startup.
+func TestSavingBlockOperationWriteBufferCrash(t *testing.T) {
+	p := &TestSavingBlockOperationHelper{}
+	p.Prepare()
+	defer p.Done()
+
+	sb := NewSavingBlockOperations(p.st, nil).SetWriteBuffer(2, time.Minute)
+
+	blk0 := p.makeBlock(block.GetGenesis(p.st))
+	blk1 := p.makeBlock(blk0)
+	blk2 := p.makeBlock(blk1)
+
+	// flushed at the boundary
+	require.NoError(t, sb.saveBuffered(blk0))
+	require.NoError(t, sb.saveBuffered(blk1))
+
+	// crashed before the next flush; the buffer is never written
+	require.NoError(t, sb.saveBuffered(blk2))
+	require.True(t, p.existsBlockOperations(blk0))
+	require.True(t, p.existsBlockOperations(blk1))
+	require.False(t, p.existsBlockOperations(blk2))
+
+	// the new `SavingBlockOperations` restores the lost ones from the block
+	require.NoError(t, NewSavingBlockOperations(p.st, nil).SetWriteBuffer(2, time.Minute).Check())
+	for _, blk := range []block.Block{blk0, blk1, blk2} {
+		require.True(t, p.existsBlockOperations(blk))
+	}
+}
+
+func benchmarkSavingBlockOperations(writeBufferBlocks uint64, b *testing.B) {
+	p := &TestSavingBlockOperationHelper{}
+	p.Prepare()
+	defer p.Done()
+
+	var blocks []block.Block
+	prev := block.GetGenesis(p.st)
+	for i := 0; i < b.N; i++ {
+		prev = p.makeBlock(prev)
+		blocks = append(blocks, prev)
+	}
+
+	sb := NewSavingBlockOperations(p.st, nil).SetWriteBuffer(writeBufferBlocks, time.Minute)
+
+	b.ResetTimer()
+	for _, blk := range blocks {
+		var err error
+		if sb.isBuffering() {
+			err = sb.saveBuffered(blk)
+		} else {
+			err = sb.save(blk)
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := sb.Flush(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkSavingBlockOperationsWithoutWriteBuffer(b *testing.B) {
+	benchmarkSavingBlockOperations(0, b)
+}
+
+func BenchmarkSavingBlockOperationsWithWriteBuffer_10(b *testing.B) {
+	benchmarkSavingBlockOperations(10, b)
+}
+
+func BenchmarkSavingBlockOperationsWithWriteBuffer_100(b *testing.B) {
+	benchmarkSavingBlockOperations(100, b)
+}
@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"bufio"
+	"io"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+type streamBlock struct {
+	blk block.Block
+	ptx *ballot.ProposerTransaction
+	txs []transaction.Transaction
+}
+
+// ImportBlockStream reads the blocks from the stream of
+// `GetBlockStreamHandler` and stores them one by one. Every block must be the
+// next of the latest block and is verified by `VerifyBlock()` before it is
+// stored by `FinishBlock()`. It returns the number of the stored blocks; if a
+// block is invalid, the former blocks are kept.
+func (nr *NodeRunner) ImportBlockStream(r io.Reader) (imported uint64, err error) {
+	var current *streamBlock
+	finish := func() error {
+		if current == nil {
+			return nil
+		}
+		if err := nr.importStreamBlock(current); err != nil {
+			return err
+		}
+		imported++
+		current = nil
+		return nil
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var itemType NodeItemDataType
+			var item interface{}
+			if itemType, item, err = UnmarshalNodeItemResponse(line); err != nil {
+				return
+			}
+
+			switch itemType {
+			case NodeItemBlock:
+				if err = finish(); err != nil {
+					return
+				}
+				current = &streamBlock{blk: item.(block.Block)}
+			case NodeItemTransaction:
+				if current == nil {
+					err = errors.InvalidMessage
+					return
+				}
+				tx := item.(transaction.Transaction)
+				if current.ptx == nil {
+					current.ptx = &ballot.ProposerTransaction{Transaction: tx}
+				} else {
+					current.txs = append(current.txs, tx)
+				}
+			case NodeItemError:
+				err = item.(*errors.Error)
+				return
+			default:
+				err = errors.InvalidMessage
+				return
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		} else if readErr != nil {
+			err = readErr
+			return
+		}
+	}
+
+	err = finish()
+	return
+}
+
+func (nr *NodeRunner) importStreamBlock(sb *streamBlock) (err error) {
+	txs := make([]*transaction.Transaction, len(sb.txs))
+	for i := range sb.txs {
+		txs[i] = &sb.txs[i]
+	}
+
+	if err = VerifyBlock(nr.storage, nr.networkID, nr.Config(), nr.localNode, block.GetLatestBlock(nr.storage), sb.blk, sb.ptx, txs); err != nil {
+		return
+	}
+	if err = nr.FinishBlock(sb.blk, *sb.ptx, txs, nr.log); err != nil {
+		return
+	}
+
+	nr.log.Debug("block imported from stream", "height", sb.blk.Height, "hash", sb.blk.Hash)
+
+	return
+}
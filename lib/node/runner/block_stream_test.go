@@ -0,0 +1,272 @@
+package runner
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// createBlockStreamNodeRunners creates the node runners, which know each
+// other over the memory network, with their own storages.
+func createBlockStreamNodeRunners(n int) (nrs []*NodeRunner) {
+	var net *network.MemoryNetwork
+	var nets []*network.MemoryNetwork
+	var nodes []*node.LocalNode
+	for i := 0; i < n; i++ {
+		_, s, v := network.CreateMemoryNetwork(net)
+		net = s
+		nets = append(nets, s)
+		nodes = append(nodes, v)
+	}
+
+	for _, localNode := range nodes {
+		for _, v := range nodes {
+			localNode.AddValidators(v.ConvertToValidator())
+		}
+	}
+
+	for i, localNode := range nodes {
+		policy, _ := consensus.NewDefaultVotingThresholdPolicy(66)
+		connectionManager := network.NewValidatorConnectionManager(localNode, nets[i], policy)
+
+		st := block.InitTestBlockchain()
+		is, _ := consensus.NewISAAC(networkID, localNode, policy, connectionManager, st, common.NewConfig(), nil)
+		nr, err := NewNodeRunner(string(networkID), localNode, policy, nets[i], is, st, common.NewConfig())
+		if err != nil {
+			panic(err)
+		}
+		nr.Ready()
+		nrs = append(nrs, nr)
+	}
+
+	return
+}
+
+// makeBlocks stores the empty blocks proposed by the node runner.
+func makeBlocks(t *testing.T, nr *NodeRunner, n int) {
+	for i := 0; i < n; i++ {
+		latest := block.GetLatestBlock(nr.Storage())
+		basis := voting.Basis{
+			Height:    latest.Height,
+			BlockHash: latest.Hash,
+			TotalTxs:  latest.TotalTxs,
+			TotalOps:  latest.TotalOps,
+		}
+
+		params := nr.NetworkParams()
+		blt := ballot.NewBallot(nr.localNode.Address(), nr.localNode.Address(), basis, []string{})
+		opc, err := ballot.NewCollectTxFeeFromBallotWithPolicy(*blt, params.CommonAccount, nr.Conf.FeeBurnPolicy)
+		require.NoError(t, err)
+		opi, err := ballot.NewInflationFromBallotWithPolicy(
+			*blt,
+			params.CommonAccount,
+			params.InitialBalance,
+			nr.Conf.InflationSchedule,
+			nr.Conf.InflationPolicy,
+		)
+		require.NoError(t, err)
+		ptx, err := ballot.NewProposerTransactionFromBallot(*blt, opc, opi)
+		require.NoError(t, err)
+		blt.SetProposerTransaction(ptx)
+		blt.SetVote(ballot.StateINIT, voting.YES)
+		blt.Sign(nr.localNode.Keypair(), networkID)
+		blt.SetVote(ballot.StateACCEPT, voting.YES)
+		blt.Sign(nr.localNode.Keypair(), networkID)
+
+		_, err = finishBallot(nr.Storage(), *blt, nr.TransactionPool, nr.Log(), nr.Log())
+		require.NoError(t, err)
+	}
+}
+
+func TestBlockStreamSync(t *testing.T) {
+	nrs := createBlockStreamNodeRunners(2)
+	nrA, nrB := nrs[0], nrs[1]
+	defer nrA.Storage().Close()
+	defer nrB.Storage().Close()
+
+	require.Equal(t, block.GetLatestBlock(nrA.Storage()).Hash, block.GetLatestBlock(nrB.Storage()).Hash)
+
+	makeBlocks(t, nrA, 50)
+	latest := block.GetLatestBlock(nrA.Storage())
+	require.Equal(t, common.GenesisBlockHeight+50, latest.Height)
+
+	client := nrB.ConnectionManager().GetConnection(nrA.localNode.Address())
+	require.NotNil(t, client)
+
+	stream, err := client.GetBlockStream(common.GenesisBlockHeight+1, latest.Height)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var advanced []uint64
+	nrB.ConsensusEvents().Subscribe(func(event ConsensusEvent) {
+		if event.Type == ConsensusEventHeightAdvanced {
+			advanced = append(advanced, event.Height)
+		}
+	})
+
+	imported, err := nrB.ImportBlockStream(stream)
+	require.NoError(t, err)
+	require.Equal(t, uint64(50), imported)
+
+	// the imported blocks are finished like the ones by the consensus
+	require.Equal(t, 50, len(advanced))
+	require.Equal(t, latest.Height, advanced[len(advanced)-1])
+	require.Equal(t, latest.Height, nrB.validatorSetHeight)
+
+	for height := common.GenesisBlockHeight; height <= latest.Height; height++ {
+		expected, err := block.GetBlockByHeight(nrA.Storage(), height)
+		require.NoError(t, err)
+		blk, err := block.GetBlockByHeight(nrB.Storage(), height)
+		require.NoError(t, err)
+		require.Equal(t, expected.Hash, blk.Hash)
+	}
+
+	expected, err := block.GetBlockAccount(nrA.Storage(), block.CommonKP.Address())
+	require.NoError(t, err)
+	account, err := block.GetBlockAccount(nrB.Storage(), block.CommonKP.Address())
+	require.NoError(t, err)
+	require.Equal(t, expected.Balance, account.Balance)
+
+	{ // over the maximum range
+		_, err := client.GetBlockStream(common.GenesisBlockHeight, common.GenesisBlockHeight+MaxBlockStreamRange)
+		require.Error(t, err)
+		require.Equal(t, errors.HTTPProblem.Code, err.(*errors.Error).Code)
+	}
+}
+
+func TestBlockStreamWrongBlock(t *testing.T) {
+	nrs := createBlockStreamNodeRunners(2)
+	nrA, nrB := nrs[0], nrs[1]
+	defer nrA.Storage().Close()
+	defer nrB.Storage().Close()
+
+	makeBlocks(t, nrA, 10)
+	client := nrB.ConnectionManager().GetConnection(nrA.localNode.Address())
+
+	{ // not linked to the latest block
+		stream, err := client.GetBlockStream(5, 10)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		imported, err := nrB.ImportBlockStream(stream)
+		require.Equal(t, errors.WrongBlockFound, err)
+		require.Equal(t, uint64(0), imported)
+		require.Equal(t, common.GenesisBlockHeight, block.GetLatestBlock(nrB.Storage()).Height)
+	}
+
+	{ // the hash of block is changed
+		stream, err := client.GetBlockStream(2, 3)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(stream)
+		require.NoError(t, err)
+		stream.Close()
+
+		blk, err := block.GetBlockByHeight(nrA.Storage(), 3)
+		require.NoError(t, err)
+		b = bytes.Replace(b, []byte(`"hash":"`+blk.Hash+`"`), []byte(`"hash":"findme"`), 1)
+
+		imported, err := nrB.ImportBlockStream(bytes.NewReader(b))
+		require.Equal(t, errors.HashDoesNotMatch, err)
+		require.Equal(t, uint64(1), imported)
+		require.Equal(t, uint64(2), block.GetLatestBlock(nrB.Storage()).Height)
+	}
+}
+
+func TestBlockStreamUnknownProposer(t *testing.T) {
+	nrs := createBlockStreamNodeRunners(2)
+	nrA, nrB := nrs[0], nrs[1]
+	defer nrA.Storage().Close()
+	defer nrB.Storage().Close()
+
+	makeBlocks(t, nrA, 3)
+	client := nrB.ConnectionManager().GetConnection(nrA.localNode.Address())
+
+	nrB.localNode.RemoveValidators(nrA.localNode.Address())
+
+	stream, err := client.GetBlockStream(common.GenesisBlockHeight+1, common.GenesisBlockHeight+3)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	imported, err := nrB.ImportBlockStream(stream)
+	require.Equal(t, errors.WrongBlockFound.Code, err.(*errors.Error).Code)
+	require.Equal(t, nrA.localNode.Address(), err.(*errors.Error).Data["proposer"])
+	require.Equal(t, uint64(0), imported)
+}
+
+func TestBlockStreamWrongProposerTransaction(t *testing.T) {
+	nrs := createBlockStreamNodeRunners(2)
+	nrA, nrB := nrs[0], nrs[1]
+	defer nrA.Storage().Close()
+	defer nrB.Storage().Close()
+
+	makeBlocks(t, nrA, 1)
+
+	{ // the inflation is not derived from the genesis balance
+		latest := block.GetLatestBlock(nrA.Storage())
+		basis := voting.Basis{
+			Height:    latest.Height,
+			BlockHash: latest.Hash,
+			TotalTxs:  latest.TotalTxs,
+			TotalOps:  latest.TotalOps,
+		}
+		blt := GenerateEmptyTxBallot(nrA.localNode, basis, ballot.StateACCEPT, nrA.localNode, nrA.Conf)
+		_, err := finishBallot(nrA.Storage(), *blt, nrA.TransactionPool, nrA.Log(), nrA.Log())
+		require.NoError(t, err)
+	}
+
+	client := nrB.ConnectionManager().GetConnection(nrA.localNode.Address())
+	stream, err := client.GetBlockStream(common.GenesisBlockHeight+1, common.GenesisBlockHeight+2)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	imported, err := nrB.ImportBlockStream(stream)
+	require.Equal(t, errors.InvalidProposerTransaction, err)
+	require.Equal(t, uint64(1), imported)
+	require.Equal(t, common.GenesisBlockHeight+1, block.GetLatestBlock(nrB.Storage()).Height)
+}
+
+func TestBlockStreamLegacyBlockVersion(t *testing.T) {
+	nrs := createBlockStreamNodeRunners(2)
+	nrA, nrB := nrs[0], nrs[1]
+	defer nrA.Storage().Close()
+	defer nrB.Storage().Close()
+
+	makeBlocks(t, nrA, 1)
+
+	legacyHeight, err := block.GetBlockLegacyHeight(nrB.Storage())
+	require.NoError(t, err)
+	require.Equal(t, common.GenesisBlockHeight, legacyHeight)
+
+	client := nrB.ConnectionManager().GetConnection(nrA.localNode.Address())
+	stream, err := client.GetBlockStream(common.GenesisBlockHeight+1, common.GenesisBlockHeight+1)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(stream)
+	require.NoError(t, err)
+	stream.Close()
+
+	// the block of version 0 is hashed without the operations root
+	blk, err := block.GetBlockByHeight(nrA.Storage(), common.GenesisBlockHeight+1)
+	require.NoError(t, err)
+	legacy := blk
+	legacy.Version = 0
+	legacy.Hash = legacy.MakeHash()
+	b = bytes.Replace(b, []byte(`"version":1`), []byte(`"version":0`), 1)
+	b = bytes.Replace(b, []byte(`"hash":"`+blk.Hash+`"`), []byte(`"hash":"`+legacy.Hash+`"`), 1)
+
+	imported, err := nrB.ImportBlockStream(bytes.NewReader(b))
+	require.Equal(t, errors.WrongBlockFound.Code, err.(*errors.Error).Code)
+	require.Equal(t, uint32(0), err.(*errors.Error).Data["version"])
+	require.Equal(t, uint64(0), imported)
+	require.Equal(t, common.GenesisBlockHeight, block.GetLatestBlock(nrB.Storage()).Height)
+}
@@ -1,10 +1,17 @@
 package runner
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/voting"
 )
 
 func TestCalculateAverageBlockTime(t *testing.T) {
@@ -14,12 +21,38 @@ func TestCalculateAverageBlockTime(t *testing.T) {
 	nBlocksInOneDay := 720 * 24
 	height := uint64(nBlocksInOneDay)
 
-	blockTime := calculateAverageBlockTime(lastDay, height)
+	blockTime := calculateAverageBlockTime(lastDay, now, height)
 	require.True(t, blockTime > 4900*time.Millisecond)
 	require.True(t, blockTime < 5100*time.Millisecond)
 
 }
 
+// TestCalculateAverageBlockTimeGenesisTime checks `Config.GenesisTime`
+// replaces the placeholder timestamp of the genesis block as the anchor of
+// the average block time.
+func TestCalculateAverageBlockTimeGenesisTime(t *testing.T) {
+	height := uint64(720 * 24)
+	lastDay := time.Now().AddDate(0, 0, -1)
+
+	{ // without override, the genesis block is the anchor
+		nr, _, _ := createNodeRunnerForTesting(1, common.NewConfig(), nil)
+		require.Equal(t, block.GetGenesis(nr.Storage()).Header.Timestamp, nr.isaacStateManager.genesis)
+	}
+
+	conf := common.NewConfig()
+	conf.GenesisTime = lastDay
+	nr, _, _ := createNodeRunnerForTesting(1, conf, nil)
+	require.Equal(t, lastDay, nr.isaacStateManager.genesis)
+
+	// the placeholder timestamp skews the average
+	placeholder := time.Unix(0, 0)
+	require.True(t, calculateAverageBlockTime(placeholder, time.Now(), height) > time.Hour)
+
+	blockTime := calculateAverageBlockTime(nr.isaacStateManager.genesis, time.Now(), height)
+	require.True(t, blockTime > 4900*time.Millisecond)
+	require.True(t, blockTime < 5100*time.Millisecond)
+}
+
 func TestCalculateBlockTimeBuffer(t *testing.T) {
 	require.Equal(t, 1*time.Second, calculateBlockTimeBuffer(
 		5*time.Second,
@@ -49,3 +82,161 @@ func TestCalculateBlockTimeBuffer(t *testing.T) {
 		1*time.Second,
 	))
 }
+
+func TestCalculateBlockTimeBufferClockSkew(t *testing.T) {
+	{ // proposed time is in the future; `untilNow` is negative
+		require.Equal(t, 6*time.Second, calculateBlockTimeBuffer(
+			5*time.Second,
+			3*time.Second,
+			-10*time.Second,
+			1*time.Second,
+		))
+
+		require.Equal(t, 4*time.Second, calculateBlockTimeBuffer(
+			5*time.Second,
+			7*time.Second,
+			-1*time.Hour,
+			1*time.Second,
+		))
+
+		require.Equal(t, 5*time.Second, calculateBlockTimeBuffer(
+			5*time.Second,
+			5*time.Second,
+			-1*time.Millisecond,
+			1*time.Second,
+		))
+	}
+
+	{ // proposed time is far in the past
+		require.Equal(t, time.Duration(0), calculateBlockTimeBuffer(
+			5*time.Second,
+			3*time.Second,
+			24*time.Hour,
+			1*time.Second,
+		))
+
+		require.Equal(t, time.Duration(0), calculateBlockTimeBuffer(
+			5*time.Second,
+			7*time.Second,
+			24*time.Hour,
+			1*time.Second,
+		))
+	}
+}
+
+func TestCalculateProposerJitter(t *testing.T) {
+	max := 500 * time.Millisecond
+
+	// disabled
+	require.Equal(t, time.Duration(0), calculateProposerJitter("block-hash", "node", 0))
+
+	// deterministic by block hash and node
+	jitter := calculateProposerJitter("block-hash", "node", max)
+	for i := 0; i < 10; i++ {
+		require.Equal(t, jitter, calculateProposerJitter("block-hash", "node", max))
+	}
+
+	// bounded and de-correlated by block hash and node
+	jitters := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		for _, node := range []string{"node0", "node1"} {
+			j := calculateProposerJitter(fmt.Sprintf("block-hash-%d", i), node, max)
+			require.True(t, j >= 0)
+			require.True(t, j < max)
+			jitters[j] = true
+		}
+	}
+	require.True(t, len(jitters) > 100)
+}
+
+type fakeClock struct {
+	sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	c.now = now
+}
+
+// TestSetBlockTimeBufferWithClock checks `SetBlockTimeBuffer()` with the fake
+// `Clock`, so the buffer is calculated exactly by `calculateBlockTimeBuffer()`.
+func TestSetBlockTimeBufferWithClock(t *testing.T) {
+	conf := common.NewConfig()
+	conf.BlockTime = 5 * time.Second
+	nr, _, _ := createNodeRunnerForTesting(1, conf, nil)
+	sm := nr.isaacStateManager
+
+	latest := nr.Consensus().LatestBlock()
+	require.Equal(t, uint64(1), latest.Height)
+	confirmed, err := common.ParseISO8601(latest.Confirmed)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name         string
+		sinceGenesis time.Duration // the average block time at the height 1
+		untilNow     time.Duration
+		expected     time.Duration
+	}{
+		{"slow", 7 * time.Second, 3 * time.Second, 1 * time.Second},
+		{"fast", 3 * time.Second, 3 * time.Second, 3 * time.Second},
+		{"on goal", 5 * time.Second, 2 * time.Second, 3 * time.Second},
+		{"late", 3 * time.Second, 10 * time.Second, 0},
+		{"clock skew", 3 * time.Second, -10 * time.Second, 6 * time.Second},
+	}
+
+	for _, c := range cases {
+		now := confirmed.Add(c.untilNow)
+		sm.genesis = now.Add(-c.sinceGenesis)
+		sm.SetClock(&fakeClock{now: now})
+
+		sm.SetBlockTimeBuffer()
+		require.Equal(t, c.expected, sm.BlockTimeBuffer(), c.name)
+		require.Equal(
+			t,
+			calculateBlockTimeBuffer(conf.BlockTime, c.sinceGenesis, c.untilNow, 1*time.Second),
+			sm.BlockTimeBuffer(),
+			c.name,
+		)
+	}
+}
+
+// TestStateManagerClockAllConfirm drives the round by `Step()` with the fake
+// `Clock`; ALLCONFIRM records the time of the clock and updates
+// `blockTimeBuffer` by it.
+func TestStateManagerClockAllConfirm(t *testing.T) {
+	conf := common.NewConfig()
+	conf.BlockTime = 5 * time.Second
+	nr, _, _ := createNodeRunnerForTesting(3, conf, nil)
+	confirmed, err := common.ParseISO8601(nr.Consensus().LatestBlock().Confirmed)
+	require.NoError(t, err)
+
+	clock := &fakeClock{now: confirmed}
+	sm := nr.isaacStateManager
+	sm.genesis = confirmed.Add(-7 * time.Second)
+	sm.SetClock(clock)
+	sm.EnableStepMode()
+
+	nr.StartStateManager()
+	defer nr.StopStateManager()
+	require.True(t, sm.Step())
+	require.Equal(t, ballot.StateINIT, sm.State().BallotState)
+
+	// the consensus of the round takes 2 seconds
+	now := confirmed.Add(2 * time.Second)
+	clock.Set(now)
+	nr.TransitISAACState(voting.Basis{Height: 1, Round: 0}, ballot.StateALLCONFIRM)
+	require.True(t, sm.Step())
+	require.Equal(t, ballot.StateALLCONFIRM, sm.State().BallotState)
+
+	require.Equal(t, now, sm.LastAllConfirmed())
+	// average is 9 seconds, slower than the goal
+	require.Equal(t, 2*time.Second, sm.BlockTimeBuffer())
+}
@@ -65,7 +65,34 @@ func BallotUnmarshal(c common.Checker, args ...interface{}) (err error) {
 		return
 	}
 
-	if err = b.IsWellFormed(checker.NetworkID, checker.NodeRunner.Conf); err != nil {
+	// the duplicated ballot is dropped before the verification
+	seen := checker.NodeRunner.BallotSeenSet()
+	if seen.Seen(b) {
+		err = errors.BallotAlreadyReceived
+		return
+	}
+
+	// the ballot with the invalid signature, like the one for the other
+	// network, is dropped before the other checks
+	if err = b.VerifyNetworkID(checker.NetworkID, checker.NodeRunner.BallotSignatureCache()); err != nil {
+		if err == errors.MessageSignatureInvalid {
+			checker.NodeRunner.MessageMetrics().increaseInvalidSignatures(common.BallotMessage)
+		}
+		return
+	}
+
+	err = b.IsWellFormedWithCache(
+		checker.NetworkID,
+		checker.NodeRunner.Config(),
+		checker.NodeRunner.BallotSignatureCache(),
+	)
+	if err != nil {
+		return
+	}
+
+	// the same ballot may be verified concurrently; only the first one passes
+	if !seen.Add(b) {
+		err = errors.BallotAlreadyReceived
 		return
 	}
 
@@ -94,7 +121,13 @@ func BallotValidateOperationBodyCollectTxFee(c common.Checker, args ...interface
 	}
 
 	// check common account
-	if opb.Target != checker.NodeRunner.CommonAccountAddress {
+	if opb.Target != checker.NodeRunner.NetworkParams().CommonAccount {
+		err = errors.InvalidOperation
+		return
+	}
+
+	// the burned fee should follow the `FeeBurnPolicy`
+	if opb.Burned != checker.NodeRunner.Config().FeeBurnPolicy.Burned(opb.Amount) {
 		err = errors.InvalidOperation
 		return
 	}
@@ -112,23 +145,29 @@ func BallotValidateOperationBodyInflation(c common.Checker, args ...interface{})
 	}
 
 	// check common account
-	if opb.Target != checker.NodeRunner.CommonAccountAddress {
+	if opb.Target != checker.NodeRunner.NetworkParams().CommonAccount {
 		err = errors.InvalidOperation
 		return
 	}
-	if opb.InitialBalance != checker.NodeRunner.InitialBalance {
+	if opb.InitialBalance != checker.NodeRunner.NetworkParams().InitialBalance {
 		err = errors.InvalidOperation
 		return
 	}
 
-	if opb.Ratio != common.InflationRatioString {
+	height := checker.NodeRunner.Consensus().LatestBlock().Height
+	conf := checker.NodeRunner.Config()
+	if opb.Ratio != conf.InflationSchedule.RatioString(height) {
 		err = errors.InvalidOperation
 		return
 	}
 
 	var expectedInflation common.Amount
-	if checker.NodeRunner.Consensus().LatestBlock().Height <= common.BlockHeightEndOfInflation {
-		expectedInflation, err = common.CalculateInflation(checker.NodeRunner.InitialBalance)
+	if height <= common.BlockHeightEndOfInflation {
+		expectedInflation, err = conf.InflationPolicy.CalculateInflation(
+			conf.InflationSchedule,
+			height,
+			checker.NodeRunner.NetworkParams().InitialBalance,
+		)
 		if err != nil {
 			return
 		}
@@ -391,7 +430,7 @@ func getMissingTransaction(checker *BallotChecker) (err error) {
 			err = errors.TransactionNotFound
 			return
 		}
-		if err = tx.IsWellFormed(checker.NetworkID, checker.NodeRunner.Conf); err != nil {
+		if err = tx.IsWellFormed(checker.NetworkID, checker.NodeRunner.Config()); err != nil {
 			return
 		}
 
@@ -432,9 +471,13 @@ func BallotGetMissingTransaction(c common.Checker, args ...interface{}) (err err
 var INITBallotTransactionCheckerFuncs = []common.CheckerFunc{
 	IsNew,
 	CheckMissingTransaction,
+	BallotTransactionsOperationsLimit,
 	BallotTransactionsSameSource,
+	BallotTransactionsMergedAccount,
+	BallotTransactionsOperationsNew,
 	BallotTransactionsSourceCheck,
 	BallotTransactionsOperationBodyCollectTxFee,
+	BallotTransactionsProposerTransaction,
 	BallotTransactionsAllValid,
 }
 
@@ -506,7 +549,14 @@ func SIGNBallotBroadcast(c common.Checker, args ...interface{}) (err error) {
 
 	}
 	checker.NodeRunner.ConnectionManager().Broadcast(newBallot)
-	checker.Log.Debug("ballot will be broadcasted", "newBallot", newBallot)
+	checker.NodeRunner.ConsensusEvents().Emit(ConsensusEvent{
+		Type:        ConsensusEventVoteCast,
+		Height:      newBallot.VotingBasis().Height,
+		Round:       newBallot.VotingBasis().Round,
+		Proposer:    newBallot.Proposer(),
+		BallotState: ballot.StateSIGN,
+		Vote:        checker.VotingHole,
+	})
 
 	return
 }
@@ -538,7 +588,14 @@ func ACCEPTBallotBroadcast(c common.Checker, args ...interface{}) (err error) {
 
 	}
 	checker.NodeRunner.ConnectionManager().Broadcast(newBallot)
-	checker.Log.Debug("ballot will be broadcasted", "newBallot", newBallot)
+	checker.NodeRunner.ConsensusEvents().Emit(ConsensusEvent{
+		Type:        ConsensusEventVoteCast,
+		Height:      newBallot.VotingBasis().Height,
+		Round:       newBallot.VotingBasis().Round,
+		Proposer:    newBallot.Proposer(),
+		BallotState: ballot.StateACCEPT,
+		Vote:        checker.FinishedVotingHole,
+	})
 
 	return
 }
@@ -589,20 +646,16 @@ func FinishedBallotStore(c common.Checker, args ...interface{}) (err error) {
 			return
 		}
 
-		if err = bs.Commit(); err != nil {
-			if err != errors.NotCommittable {
-				bs.Discard()
-				return
-			}
+		if err = checker.NodeRunner.commitBlock(bs); err != nil {
+			return
 		}
 
-		checker.Log.Debug("ballot was stored", "block", *theBlock)
-		checker.NodeRunner.SavingBlockOperations().Save(*theBlock)
+		checker.NodeRunner.finishedBlock(*theBlock, checker.Log)
 		checker.NodeRunner.TransitISAACState(ballotRound, ballot.StateALLCONFIRM)
 
 		err = NewCheckerStopCloseConsensus(checker, "ballot got consensus and will be stored")
 	} else {
-		checker.NodeRunner.isaacStateManager.IncreaseRound()
+		checker.NodeRunner.isaacStateManager.IncreaseRoundOf(ballotRound.Height, ballotRound.Round)
 		err = NewCheckerStopCloseConsensus(checker, "ballot got consensus")
 	}
 
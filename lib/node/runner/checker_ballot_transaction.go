@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"context"
 	"sync"
 
 	"boscoin.io/sebak/lib/ballot"
@@ -29,6 +30,15 @@ type BallotTransactionChecker struct {
 	validTransactionsMap  map[string]bool
 	CheckTransactionsOnly bool
 	transactionCache      *TransactionCache
+	ctx                   context.Context // cancels the checks of the new ballot; see `NodeRunner.makeNewBallot()`.
+}
+
+// canceled returns the error of `ctx`, if it is canceled.
+func (checker *BallotTransactionChecker) canceled() error {
+	if checker.ctx == nil {
+		return nil
+	}
+	return checker.ctx.Err()
 }
 
 func (checker *BallotTransactionChecker) InvalidTransactions() (invalids []string) {
@@ -61,9 +71,16 @@ func IsNew(c common.Checker, args ...interface{}) (err error) {
 
 	var validTransactions []string
 	for _, hash := range checker.Transactions {
+		if err = checker.canceled(); err != nil {
+			return
+		}
+
 		// check transaction is already stored
 		var found bool
-		if found, err = block.ExistsBlockTransaction(checker.NodeRunner.Storage(), hash); err != nil || found {
+		if found, err = block.ExistsBlockTransaction(checker.NodeRunner.Storage(), hash); err != nil {
+			// the storage error is not from the transaction
+			return
+		} else if found {
 			if !checker.CheckTransactionsOnly {
 				err = errors.NewButKnownMessage
 				return
@@ -111,6 +128,10 @@ func BallotTransactionsSameSource(c common.Checker, args ...interface{}) (err er
 	var tx transaction.Transaction
 	var found bool
 	for _, hash := range checker.ValidTransactions {
+		if err = checker.canceled(); err != nil {
+			return
+		}
+
 		if tx, found, err = checker.transactionCache.Get(hash); err != nil {
 			return
 		} else if !found {
@@ -134,6 +155,142 @@ func BallotTransactionsSameSource(c common.Checker, args ...interface{}) (err er
 	return
 }
 
+// BallotTransactionsMergedAccount checks there are transactions, which deposit
+// to the account closed by `operation.AccountMerge` in the same
+// `Transactions`; the deposit after the merge would be left in the closed
+// account.
+func BallotTransactionsMergedAccount(c common.Checker, args ...interface{}) (err error) {
+	checker := c.(*BallotTransactionChecker)
+
+	var txs []transaction.Transaction
+	merged := map[string]bool{}
+	for _, hash := range checker.ValidTransactions {
+		if err := checker.canceled(); err != nil {
+			return err
+		}
+
+		tx, found, err := checker.transactionCache.Get(hash)
+		if err != nil {
+			return err
+		} else if !found {
+			continue
+		}
+		txs = append(txs, tx)
+
+		if _, ok := tx.AccountMerge(); ok {
+			merged[tx.B.Source] = true
+		}
+	}
+
+	if len(merged) < 1 {
+		return
+	}
+
+	var validTransactions []string
+	for _, tx := range txs {
+		if hasDepositTo(tx, merged) {
+			if !checker.CheckTransactionsOnly {
+				err = errors.BlockAccountClosedNoDeposit
+				return
+			}
+			continue
+		}
+		validTransactions = append(validTransactions, tx.GetHash())
+	}
+	checker.setValidTransactions(validTransactions)
+
+	return
+}
+
+// BallotTransactionsOperationsNew checks the operations of the transactions
+// are not stored in the prior blocks and are not duplicated in the
+// `Transactions`. The `block.BlockOperation` of same hash can not be saved
+// again, so the replayed operation is rejected before any writes of the block,
+// not in the middle of storing it.
+func BallotTransactionsOperationsNew(c common.Checker, args ...interface{}) (err error) {
+	checker := c.(*BallotTransactionChecker)
+
+	var validTransactions []string
+	hashes := map[string]bool{}
+	for _, hash := range checker.ValidTransactions {
+		if err := checker.canceled(); err != nil {
+			return err
+		}
+
+		tx, found, err := checker.transactionCache.Get(hash)
+		if err != nil {
+			return err
+		} else if !found {
+			continue
+		}
+
+		var opHashes []string
+		if opHashes, err = newBlockOperationHashes(checker.NodeRunner.Storage(), tx, hashes); err != nil {
+			if e, ok := err.(*errors.Error); !ok || e.Code != errors.BlockOperationAlreadyExists.Code {
+				// the storage error is not from the transaction
+				return err
+			} else if !checker.CheckTransactionsOnly {
+				return err
+			}
+			continue
+		}
+
+		for _, opHash := range opHashes {
+			hashes[opHash] = true
+		}
+		validTransactions = append(validTransactions, hash)
+	}
+	checker.setValidTransactions(validTransactions)
+
+	return
+}
+
+// newBlockOperationHashes returns the hashes of `block.BlockOperation` of the
+// transaction; if one of them is in `hashes` or is already stored, it
+// returns `errors.BlockOperationAlreadyExists`.
+func newBlockOperationHashes(st *storage.LevelDBBackend, tx transaction.Transaction, hashes map[string]bool) (opHashes []string, err error) {
+	seen := map[string]bool{}
+	for _, op := range tx.B.Operations {
+		opHash := block.NewBlockOperationKey(op.MakeHashString(), tx.GetHash())
+		if hashes[opHash] || seen[opHash] {
+			err = errors.BlockOperationAlreadyExists.Clone().SetData("hash", opHash)
+			return
+		}
+
+		var exists bool
+		if exists, err = block.ExistsBlockOperation(st, opHash); err != nil {
+			return
+		} else if exists {
+			err = errors.BlockOperationAlreadyExists.Clone().SetData("hash", opHash)
+			return
+		}
+
+		seen[opHash] = true
+		opHashes = append(opHashes, opHash)
+	}
+
+	return
+}
+
+func hasDepositTo(tx transaction.Transaction, targets map[string]bool) bool {
+	for _, op := range tx.B.Operations {
+		var target string
+		if pop, ok := op.B.(operation.Payable); ok {
+			target = pop.TargetAddress()
+		} else if pop, ok := op.B.(operation.AccountMerge); ok {
+			target = pop.Target
+		} else {
+			continue
+		}
+
+		if targets[target] {
+			return true
+		}
+	}
+
+	return false
+}
+
 // BallotTransactionsSourceCheck calls `Transaction.Validate()`.
 func BallotTransactionsSourceCheck(c common.Checker, args ...interface{}) (err error) {
 	checker := c.(*BallotTransactionChecker)
@@ -142,6 +299,10 @@ func BallotTransactionsSourceCheck(c common.Checker, args ...interface{}) (err e
 	var found bool
 	var validTransactions []string
 	for _, hash := range checker.ValidTransactions {
+		if err = checker.canceled(); err != nil {
+			return
+		}
+
 		if tx, found, err = checker.transactionCache.Get(hash); err != nil {
 			return
 		} else if !found {
@@ -164,6 +325,42 @@ func BallotTransactionsSourceCheck(c common.Checker, args ...interface{}) (err e
 	return
 }
 
+// BallotTransactionsOperationsLimit checks the total number and the total
+// weight of the operations in the transactions of ballot do not exceed
+// `common.Config.MaxOpsPerBlock` and `common.Config.MaxBlockWeight`.
+func BallotTransactionsOperationsLimit(c common.Checker, args ...interface{}) (err error) {
+	checker := c.(*BallotTransactionChecker)
+
+	conf := checker.NodeRunner.Config()
+	if conf.MaxOpsPerBlock < 1 && conf.MaxBlockWeight < 1 {
+		return
+	}
+
+	var ops int
+	var weight uint64
+	var tx transaction.Transaction
+	var found bool
+	for _, hash := range checker.Transactions {
+		if tx, found, err = checker.transactionCache.Get(hash); err != nil {
+			return
+		} else if !found {
+			err = errors.TransactionNotFound
+			return
+		}
+
+		if ops += len(tx.B.Operations); conf.MaxOpsPerBlock > 0 && ops > conf.MaxOpsPerBlock {
+			err = errors.BallotHasOverMaxOperationsInBallot
+			return
+		}
+		if weight += tx.Weight(conf.OperationWeights); conf.MaxBlockWeight > 0 && weight > conf.MaxBlockWeight {
+			err = errors.BallotHasOverMaxWeightInBallot
+			return
+		}
+	}
+
+	return
+}
+
 // BallotTransactionsOperationBodyCollectTxFee validates the
 // `BallotTransactionsOperationBodyCollectTxFee.Amount` is matched with the
 // collected fee of all transactions.
@@ -204,6 +401,78 @@ func BallotTransactionsOperationBodyCollectTxFee(c common.Checker, args ...inter
 	return
 }
 
+// BallotTransactionsProposerTransaction recomputes the `CollectTxFee` and
+// `Inflation` from the transactions and the voting basis of ballot like the
+// proposer does with `ballot.NewCollectTxFeeFromBallotWithPolicy()` and
+// `ballot.NewInflationFromBallotWithPolicy()`. If the proposer transaction does not
+// match with them, it returns `errors.InvalidProposerTransaction`, so the
+// proposer can not claim the different fee or inflation.
+func BallotTransactionsProposerTransaction(c common.Checker, args ...interface{}) (err error) {
+	checker := c.(*BallotTransactionChecker)
+
+	var txs []transaction.Transaction
+	for _, hash := range checker.Transactions {
+		var tx transaction.Transaction
+		var found bool
+		if tx, found, err = checker.transactionCache.Get(hash); err != nil {
+			return
+		} else if !found {
+			err = errors.TransactionNotFound
+			return
+		}
+		txs = append(txs, tx)
+	}
+
+	params := checker.NodeRunner.NetworkParams()
+
+	conf := checker.NodeRunner.Config()
+
+	var expectedCollectTxFee operation.CollectTxFee
+	expectedCollectTxFee, err = ballot.NewCollectTxFeeFromBallotWithPolicy(
+		checker.Ballot,
+		params.CommonAccount,
+		conf.FeeBurnPolicy,
+		txs...,
+	)
+	if err != nil {
+		return
+	}
+
+	var expectedInflation operation.Inflation
+	expectedInflation, err = ballot.NewInflationFromBallotWithPolicy(
+		checker.Ballot,
+		params.CommonAccount,
+		params.InitialBalance,
+		conf.InflationSchedule,
+		conf.InflationPolicy,
+	)
+	if err != nil {
+		return
+	}
+
+	ptx := checker.Ballot.ProposerTransaction()
+
+	var opc operation.CollectTxFee
+	if opc, err = ptx.CollectTxFee(); err != nil {
+		return
+	}
+	if opc != expectedCollectTxFee {
+		err = errors.InvalidProposerTransaction
+		return
+	}
+
+	var opi operation.Inflation
+	if opi, err = ptx.Inflation(); err != nil {
+		return
+	}
+	if opi != expectedInflation {
+		err = errors.InvalidProposerTransaction
+		return
+	}
+
+	return
+}
+
 // BallotTransactionsAllValid checks all the transactions are valid or not.
 func BallotTransactionsAllValid(c common.Checker, args ...interface{}) (err error) {
 	checker := c.(*BallotTransactionChecker)
@@ -243,9 +512,28 @@ func ValidateTx(st *storage.LevelDBBackend, tx transaction.Transaction) (err err
 		return
 	}
 
-	// check, sequenceID is based on latest sequenceID
-	if !tx.IsValidSequenceID(ba.SequenceID) {
-		err = errors.TransactionInvalidSequenceID
+	// check, source is not closed by `operation.AccountMerge`
+	if ba.Closed {
+		err = errors.BlockAccountClosed
+		return
+	}
+
+	// check, the signatures meet the threshold of multiple signers
+	if ba.Threshold > 0 && ba.SignersWeight(tx.Signers()...) < ba.Threshold {
+		err = errors.TransactionNotEnoughSignatureWeight
+		return
+	}
+
+	// check, transaction is not expired; it will be included in the next block
+	if tx.B.ValidUntilHeight > 0 && tx.IsExpired(block.GetLatestBlock(st).Height+1) {
+		err = errors.TransactionExpired
+		return
+	}
+
+	// check, sequenceID is based on latest sequenceID; the stale one is
+	// rejected, and the future one is held in `Pool` until the gap is filled,
+	// see `MessageValidate`
+	if err = tx.CheckSequenceID(ba.SequenceID); err != nil {
 		return
 	}
 
@@ -264,6 +552,20 @@ func ValidateTx(st *storage.LevelDBBackend, tx transaction.Transaction) (err err
 		return
 	}
 
+	// check, the balance after paying is not under the minimum balance of the
+	// account after the transaction, which may add the account data and the
+	// signers; the merged account is closed, so it does not need to keep it
+	if _, merge := tx.AccountMerge(); !merge {
+		var minimum common.Amount
+		if minimum, err = block.MinimumBalanceAfter(st, ba, tx.B.Operations); err != nil {
+			return
+		}
+		if bac.Balance-totalAmount < minimum {
+			err = errors.AccountBalanceUnderMinimum
+			return
+		}
+	}
+
 	for _, op := range tx.B.Operations {
 		if err = ValidateOp(st, ba, op); err != nil {
 
@@ -334,6 +636,9 @@ func ValidateOp(st *storage.LevelDBBackend, source *block.BlockAccount, op opera
 		if taccount.Linked != "" {
 			return errors.FrozenAccountNoDeposit
 		}
+		if taccount.Closed {
+			return errors.BlockAccountClosedNoDeposit
+		}
 		if source.Linked != "" {
 			// If it's a frozen account, everything must be withdrawn
 			var expected common.Amount
@@ -370,6 +675,124 @@ func ValidateOp(st *storage.LevelDBBackend, source *block.BlockAccount, op opera
 		if bo.Type == operation.TypeUnfreezingRequest {
 			return errors.UnfreezingRequestAlreadyReceived
 		}
+	case operation.TypeSetSigners:
+		if _, ok := op.B.(operation.SetSigners); !ok {
+			return errors.TypeOperationBodyNotMatched
+		}
+	case operation.TypeManageData:
+		pop, ok := op.B.(operation.ManageData)
+		if !ok {
+			return errors.TypeOperationBodyNotMatched
+		}
+		// the deleting data should exist
+		if pop.IsDelete() {
+			if exists, err := block.ExistsAccountData(st, source.Address, pop.Name); err != nil {
+				return err
+			} else if !exists {
+				return errors.AccountDataDoesNotExist
+			}
+		}
+	case operation.TypeValidatorSetChange:
+		pop, ok := op.B.(operation.ValidatorSetChange)
+		if !ok {
+			return errors.TypeOperationBodyNotMatched
+		}
+		// only the genesis account can change the validator set
+		var genesis *block.BlockAccount
+		if genesis, err = GetGenesisAccount(st); err != nil {
+			return
+		}
+		if source.Address != genesis.Address {
+			return errors.ValidatorSetChangeNotAllowed
+		}
+		if pop.Height <= block.GetLatestBlock(st).Height {
+			return errors.ValidatorSetChangeHeightPassed
+		}
+	case operation.TypeTimeLockedPayment:
+		pop, ok := op.B.(operation.TimeLockedPayment)
+		if !ok {
+			return errors.TypeOperationBodyNotMatched
+		}
+		var taccount *block.BlockAccount
+		if taccount, err = block.GetBlockAccount(st, pop.Target); err != nil {
+			return errors.BlockAccountDoesNotExists
+		}
+		if taccount.Linked != "" {
+			return errors.FrozenAccountNoDeposit
+		}
+		if taccount.Closed {
+			return errors.BlockAccountClosedNoDeposit
+		}
+		// frozen account can withdraw only by unfreezing payment
+		if source.Linked != "" {
+			return errors.FrozenAccountMustWithdrawEverything
+		}
+		if pop.UnlockHeight <= block.GetLatestBlock(st).Height {
+			return errors.TimeLockHeightPassed
+		}
+	case operation.TypeTimeLockClaim:
+		pop, ok := op.B.(operation.TimeLockClaim)
+		if !ok {
+			return errors.TypeOperationBodyNotMatched
+		}
+		if exists, err := st.Has(block.GetBlockTimeLockKey(source.Address, pop.Source, pop.UnlockHeight)); err != nil {
+			return err
+		} else if !exists {
+			return errors.TimeLockDoesNotExist
+		}
+		if block.GetLatestBlock(st).Height < pop.UnlockHeight {
+			return errors.TimeLockNotUnlocked
+		}
+	case operation.TypeAccountMerge:
+		pop, ok := op.B.(operation.AccountMerge)
+		if !ok {
+			return errors.TypeOperationBodyNotMatched
+		}
+		var taccount *block.BlockAccount
+		if taccount, err = block.GetBlockAccount(st, pop.Target); err != nil {
+			return errors.BlockAccountDoesNotExists
+		}
+		if taccount.Linked != "" {
+			return errors.FrozenAccountNoDeposit
+		}
+		if taccount.Closed {
+			return errors.BlockAccountClosedNoDeposit
+		}
+		// frozen account can withdraw only by unfreezing payment
+		if source.Linked != "" {
+			return errors.FrozenAccountMustWithdrawEverything
+		}
+		// the time locks for the source can not be claimed after closed
+		var locks []block.BlockTimeLock
+		if locks, err = block.GetBlockTimeLocks(st, source.Address); err != nil {
+			return
+		} else if len(locks) > 0 {
+			return errors.AccountMergeTimeLockRemains
+		}
+	case operation.TypeGovernanceProposal:
+		pop, ok := op.B.(operation.GovernanceProposal)
+		if !ok {
+			return errors.TypeOperationBodyNotMatched
+		}
+		if pop.CloseHeight <= block.GetLatestBlock(st).Height {
+			return errors.GovernanceCloseHeightPassed
+		}
+	case operation.TypeGovernanceVote:
+		pop, ok := op.B.(operation.GovernanceVote)
+		if !ok {
+			return errors.TypeOperationBodyNotMatched
+		}
+		// frozen account is only for the unfreezing
+		if source.Linked != "" {
+			return errors.GovernanceVoteFromFrozenAccount
+		}
+		var proposal block.GovernanceProposal
+		if proposal, err = block.GetGovernanceProposal(st, pop.Proposal); err != nil {
+			return
+		}
+		if proposal.Closed || proposal.CloseHeight <= block.GetLatestBlock(st).Height {
+			return errors.GovernanceProposalClosed
+		}
 	case operation.TypeCongressVoting, operation.TypeCongressVotingResult:
 		// Nothing to do
 		return
@@ -12,6 +12,7 @@
 package runner
 
 import (
+	"context"
 	"testing"
 
 	"boscoin.io/sebak/lib/block"
@@ -21,7 +22,7 @@ import (
 	"boscoin.io/sebak/lib/storage"
 	"boscoin.io/sebak/lib/transaction"
 	"boscoin.io/sebak/lib/transaction/operation"
-
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/stretchr/testify/require"
 )
 
@@ -114,11 +115,30 @@ func TestValidateTxWrongSequenceID(t *testing.T) {
 		},
 	}
 	tx.H.Hash = tx.B.MakeHashString()
-	require.Equal(t, ValidateTx(st, tx), errors.TransactionInvalidSequenceID)
+
+	expected, err := block.GetNextSequenceID(st, kps.Address())
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), expected)
+
+	// stale
+	require.Equal(t, ValidateTx(st, tx), errors.TransactionStaleSequenceID)
+	// future
 	tx.B.SequenceID = 2
-	require.Equal(t, ValidateTx(st, tx), errors.TransactionInvalidSequenceID)
+	require.Equal(t, ValidateTx(st, tx), errors.TransactionFutureSequenceID)
+	// exact
 	tx.B.SequenceID = 1
 	require.Nil(t, ValidateTx(st, tx))
+
+	// after withdrawal, the next sequenceID is expected
+	bas.Withdraw(common.BaseFee)
+	bas.MustSave(st)
+	expected, err = block.GetNextSequenceID(st, kps.Address())
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), expected)
+	require.Equal(t, ValidateTx(st, tx), errors.TransactionStaleSequenceID)
+
+	_, err = block.GetNextSequenceID(st, keypair.Random().Address())
+	require.Error(t, err)
 }
 
 // Check sending the whole balance
@@ -178,6 +198,158 @@ func TestValidateTxOverBalance(t *testing.T) {
 	require.Nil(t, ValidateTx(st, tx))
 }
 
+// Check the balance can not go under the minimum balance of the account data
+func TestValidateTxMinimumBalance(t *testing.T) {
+	kps := keypair.Random()
+	kpt := keypair.Random()
+
+	st := storage.NewTestStorage()
+	defer st.Close()
+	bas := block.BlockAccount{
+		Address:    kps.Address(),
+		Balance:    common.Amount(1 * common.AmountPerCoin),
+		SequenceID: 1,
+	}
+	bat := block.BlockAccount{
+		Address: kpt.Address(),
+		Balance: common.Amount(1 * common.AmountPerCoin),
+	}
+	bas.MustSave(st)
+	bat.MustSave(st)
+
+	opbody := operation.Payment{Target: kpt.Address(), Amount: bas.Balance.MustSub(common.BaseFee)}
+	tx := transaction.Transaction{
+		H: transaction.Header{
+			Created: common.NowISO8601(),
+		},
+		B: transaction.Body{
+			Source:     kps.Address(),
+			Fee:        common.BaseFee,
+			SequenceID: 1,
+			Operations: []operation.Operation{
+				operation.Operation{
+					H: operation.Header{Type: operation.TypePayment},
+					B: opbody,
+				},
+			},
+		},
+	}
+	tx.H.Hash = tx.B.MakeHashString()
+	require.Nil(t, ValidateTx(st, tx))
+
+	// the account data requires the minimum balance
+	require.NoError(t, block.SaveAccountData(st, kps.Address(), "showme", []byte("findme")))
+	require.Equal(t, errors.AccountBalanceUnderMinimum, ValidateTx(st, tx))
+
+	minimum, err := block.MinimumBalance(st, &bas)
+	require.NoError(t, err)
+	require.Equal(t, common.EntryReserve, minimum)
+
+	// the spendable balance can be paid
+	spendable, err := block.SpendableBalance(st, &bas)
+	require.NoError(t, err)
+	opbody.Amount = spendable.MustSub(common.BaseFee)
+	tx.B.Operations[0].B = opbody
+	require.Nil(t, ValidateTx(st, tx))
+
+	opbody.Amount++
+	tx.B.Operations[0].B = opbody
+	require.Equal(t, errors.AccountBalanceUnderMinimum, ValidateTx(st, tx))
+}
+
+// Check the minimum balance counts the account data and the signers added by
+// the transaction itself
+func TestValidateTxMinimumBalanceAfterOperations(t *testing.T) {
+	kps := keypair.Random()
+	kpt := keypair.Random()
+
+	st := storage.NewTestStorage()
+	defer st.Close()
+	bas := block.BlockAccount{
+		Address:    kps.Address(),
+		Balance:    common.Amount(1 * common.AmountPerCoin),
+		SequenceID: 1,
+	}
+	bat := block.BlockAccount{
+		Address: kpt.Address(),
+		Balance: common.Amount(1 * common.AmountPerCoin),
+	}
+	bas.MustSave(st)
+	bat.MustSave(st)
+
+	newTx := func(amount common.Amount, bodies ...operation.Body) transaction.Transaction {
+		ops := []operation.Operation{}
+		for _, body := range bodies {
+			op, err := operation.NewOperation(body)
+			require.NoError(t, err)
+			ops = append(ops, op)
+		}
+		payment, err := operation.NewOperation(operation.Payment{Target: kpt.Address(), Amount: amount})
+		require.NoError(t, err)
+		ops = append(ops, payment)
+
+		tx := transaction.Transaction{
+			H: transaction.Header{
+				Created: common.NowISO8601(),
+			},
+			B: transaction.Body{
+				Source:     kps.Address(),
+				Fee:        common.BaseFee.MustMult(len(ops)),
+				SequenceID: 1,
+				Operations: ops,
+			},
+		}
+		tx.H.Hash = tx.B.MakeHashString()
+		return tx
+	}
+
+	fee := common.BaseFee.MustMult(2)
+	data := operation.NewManageData("showme", []byte("findme"))
+
+	{ // adding data together with the payment of the whole balance
+		tx := newTx(bas.Balance.MustSub(fee), data)
+		require.Equal(t, errors.AccountBalanceUnderMinimum, ValidateTx(st, tx))
+
+		minimum, err := block.MinimumBalanceAfter(st, &bas, tx.B.Operations)
+		require.NoError(t, err)
+		require.Equal(t, common.EntryReserve, minimum)
+
+		tx = newTx(bas.Balance.MustSub(fee).MustSub(common.EntryReserve), data)
+		require.Nil(t, ValidateTx(st, tx))
+
+		tx = newTx(bas.Balance.MustSub(fee).MustSub(common.EntryReserve)+1, data)
+		require.Equal(t, errors.AccountBalanceUnderMinimum, ValidateTx(st, tx))
+	}
+
+	{ // same data set twice is counted once
+		fee := common.BaseFee.MustMult(3)
+		tx := newTx(bas.Balance.MustSub(fee).MustSub(common.EntryReserve), data, operation.NewManageData("showme", []byte("again")))
+		require.Nil(t, ValidateTx(st, tx))
+	}
+
+	{ // the signers set by the transaction
+		fee := common.BaseFee.MustMult(3)
+		signers := operation.NewSetSigners(1, operation.Signer{Address: kps.Address(), Weight: 1})
+		tx := newTx(bas.Balance.MustSub(fee).MustSub(common.EntryReserve), data, signers)
+		require.Equal(t, errors.AccountBalanceUnderMinimum, ValidateTx(st, tx))
+
+		tx = newTx(bas.Balance.MustSub(fee).MustSub(common.EntryReserve.MustMult(2)), data, signers)
+		require.Nil(t, ValidateTx(st, tx))
+	}
+
+	{ // deleting the existing data releases the minimum balance
+		require.NoError(t, block.SaveAccountData(st, kps.Address(), "showme", []byte("findme")))
+		require.Equal(t, errors.AccountBalanceUnderMinimum, ValidateTx(st, newTx(bas.Balance.MustSub(common.BaseFee))))
+
+		tx := newTx(bas.Balance.MustSub(fee), operation.NewManageData("showme", nil))
+		require.Nil(t, ValidateTx(st, tx))
+
+		// the existing data is not counted again
+		tx = newTx(bas.Balance.MustSub(fee).MustSub(common.EntryReserve), data)
+		require.Nil(t, ValidateTx(st, tx))
+	}
+}
+
 // Test creating an already existing account
 func TestValidateOpCreateExistsAccount(t *testing.T) {
 	kps := keypair.Random()
@@ -221,3 +393,653 @@ func TestValidateOpCreateExistsAccount(t *testing.T) {
 	bas.MustSave(st1)
 	require.Nil(t, ValidateTx(st1, tx))
 }
+
+// Test the transaction, which expires between submission and proposal
+func TestValidateTxExpiredBeforeProposal(t *testing.T) {
+	nr, _, _ := createNodeRunnerForTesting(1, common.NewConfig(), nil)
+	st := nr.Storage()
+
+	latestBlock := block.GetLatestBlock(st)
+	genesisAccount, _ := block.GetBlockAccount(st, block.GenesisKP.Address())
+
+	tx, _, _ := GetCreateAccountTransaction(genesisAccount.SequenceID, uint64(common.BaseReserve))
+	tx.B.ValidUntilHeight = latestBlock.Height + 1
+	tx.Sign(block.GenesisKP, networkID)
+
+	// submission; it can be included in the next block
+	require.NoError(t, tx.IsWellFormed(networkID, nr.Conf))
+	require.NoError(t, ValidateTx(st, tx))
+	require.True(t, nr.TransactionPool.Add(tx))
+
+	// new block is stored before proposal
+	blk := block.TestMakeNewBlockWithPrevBlock(latestBlock, []string{})
+	blk.MustSave(st)
+
+	require.Equal(t, errors.TransactionExpired, ValidateTx(st, tx))
+
+	// proposal; expired transaction is dropped from pool
+	blt, err := nr.proposeNewBallot(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, blt.TransactionsLength())
+	require.False(t, nr.TransactionPool.Has(tx.GetHash()))
+}
+
+// Check the accumulated weight of signatures for the account with multiple
+// signers
+func TestValidateTxMultipleSigners(t *testing.T) {
+	kps := keypair.Random()
+	kpt := keypair.Random()
+	kp1 := keypair.Random()
+	kp2 := keypair.Random()
+
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	bas := block.BlockAccount{
+		Address: kps.Address(),
+		Balance: common.Amount(1 * common.AmountPerCoin),
+	}
+	bat := block.BlockAccount{
+		Address: kpt.Address(),
+		Balance: common.Amount(1 * common.AmountPerCoin),
+	}
+	bas.MustSave(st)
+	bat.MustSave(st)
+
+	newTx := func() transaction.Transaction {
+		tx := transaction.Transaction{
+			H: transaction.Header{
+				Created: common.NowISO8601(),
+			},
+			B: transaction.Body{
+				Fee:        common.BaseFee,
+				SequenceID: 0,
+				Operations: []operation.Operation{
+					operation.Operation{
+						H: operation.Header{Type: operation.TypePayment},
+						B: operation.Payment{Target: kpt.Address(), Amount: common.Amount(10000)},
+					},
+				},
+			},
+		}
+		tx.Sign(kps, networkID)
+		return tx
+	}
+
+	// single signer works without additional signatures
+	require.Nil(t, ValidateTx(st, newTx()))
+
+	// set signers
+	opb := operation.NewSetSigners(
+		3,
+		operation.Signer{Address: kps.Address(), Weight: 1},
+		operation.Signer{Address: kp1.Address(), Weight: 1},
+		operation.Signer{Address: kp2.Address(), Weight: 2},
+	)
+	require.NoError(t, opb.IsWellFormed(common.NewConfig()))
+	op, _ := operation.NewOperation(opb)
+	require.NoError(t, ValidateOp(st, &bas, op))
+	require.NoError(t, finishOperation(st, kps.Address(), op, log))
+
+	ba, _ := block.GetBlockAccount(st, kps.Address())
+	require.Equal(t, uint64(3), ba.Threshold)
+	require.Equal(t, opb.Signers, ba.Signers)
+
+	{ // below threshold
+		tx := newTx()
+		require.Equal(t, errors.TransactionNotEnoughSignatureWeight, ValidateTx(st, tx))
+
+		tx.AddSignature(kp1, networkID)
+		require.NoError(t, tx.IsWellFormed(networkID, common.NewConfig()))
+		require.Equal(t, errors.TransactionNotEnoughSignatureWeight, ValidateTx(st, tx))
+	}
+
+	{ // exact threshold
+		tx := newTx()
+		tx.AddSignature(kp2, networkID)
+		require.NoError(t, tx.IsWellFormed(networkID, common.NewConfig()))
+		require.Nil(t, ValidateTx(st, tx))
+	}
+
+	{ // the key of source is always required, even if the others meet the threshold
+		tx := newTx()
+		tx.AddSignature(kp1, networkID)
+		tx.AddSignature(kp2, networkID)
+		require.Nil(t, ValidateTx(st, tx))
+
+		signature, _ := keypair.MakeSignature(kp2, networkID, tx.H.Hash)
+		tx.H.Signature = base58.Encode(signature)
+		require.Error(t, tx.IsWellFormed(networkID, common.NewConfig()))
+	}
+
+	{ // wrong key; valid signature, but not the signer of account
+		tx := newTx()
+		tx.AddSignature(kp1, networkID)
+		tx.AddSignature(keypair.Random(), networkID)
+		require.NoError(t, tx.IsWellFormed(networkID, common.NewConfig()))
+		require.Equal(t, errors.TransactionNotEnoughSignatureWeight, ValidateTx(st, tx))
+	}
+
+	{ // reset to single signer
+		op, _ := operation.NewOperation(operation.NewSetSigners(0))
+		require.NoError(t, finishOperation(st, kps.Address(), op, log))
+		require.Nil(t, ValidateTx(st, newTx()))
+	}
+}
+
+// Check the account data is set, overwritten and deleted by `ManageData`
+func TestValidateOpManageData(t *testing.T) {
+	kps := keypair.Random()
+
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	bas := block.BlockAccount{
+		Address: kps.Address(),
+		Balance: common.Amount(1 * common.AmountPerCoin),
+	}
+	bas.MustSave(st)
+
+	manageData := func(name string, value []byte) (operation.Operation, error) {
+		op, _ := operation.NewOperation(operation.NewManageData(name, value))
+		if err := op.IsWellFormed(common.NewConfig()); err != nil {
+			return op, err
+		}
+		if err := ValidateOp(st, &bas, op); err != nil {
+			return op, err
+		}
+		return op, finishOperation(st, kps.Address(), op, log)
+	}
+
+	{ // set
+		_, err := manageData("showme", []byte("findme"))
+		require.NoError(t, err)
+
+		value, err := block.GetAccountData(st, kps.Address(), "showme")
+		require.NoError(t, err)
+		require.Equal(t, []byte("findme"), value)
+
+		// the other account does not have it
+		_, err = block.GetAccountData(st, keypair.Random().Address(), "showme")
+		require.Equal(t, errors.StorageRecordDoesNotExist, err)
+	}
+
+	{ // overwrite
+		op, err := manageData("showme", []byte("killme"))
+		require.NoError(t, err)
+
+		value, err := block.GetAccountData(st, kps.Address(), "showme")
+		require.NoError(t, err)
+		require.Equal(t, []byte("killme"), value)
+
+		// indexed as `BlockOperation` of source
+		tx, _ := transaction.NewTransaction(kps.Address(), 0, op)
+		bo, err := block.NewBlockOperationFromOperation(op, tx, 2)
+		require.NoError(t, err)
+		require.NoError(t, bo.Save(st))
+
+		iterFunc, closeFunc := block.GetBlockOperationsBySource(st, kps.Address(), nil)
+		saved, hasNext, _ := iterFunc()
+		closeFunc()
+		require.True(t, hasNext)
+		require.Equal(t, operation.TypeManageData, saved.Type)
+
+		opb, err := operation.UnmarshalBodyJSON(saved.Type, saved.Body)
+		require.NoError(t, err)
+		require.Equal(t, op.B, opb)
+	}
+
+	{ // delete
+		_, err := manageData("showme", nil)
+		require.NoError(t, err)
+
+		_, err = block.GetAccountData(st, kps.Address(), "showme")
+		require.Equal(t, errors.StorageRecordDoesNotExist, err)
+
+		// already deleted
+		_, err = manageData("showme", nil)
+		require.Equal(t, errors.AccountDataDoesNotExist, err)
+	}
+
+	{ // oversize
+		_, err := manageData("showme", make([]byte, common.DefaultMaxAccountDataValueSize+1))
+		require.Equal(t, errors.AccountDataValueTooLarge, err)
+
+		_, err = block.GetAccountData(st, kps.Address(), "showme")
+		require.Equal(t, errors.StorageRecordDoesNotExist, err)
+	}
+}
+
+// Check the amount of `TimeLockedPayment` is held until the unlock height and
+// only the target can claim it after the block of the unlock height.
+func TestValidateOpTimeLock(t *testing.T) {
+	kps := keypair.Random()
+	kpt := keypair.Random()
+
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	initial := common.Amount(1 * common.AmountPerCoin)
+	block.NewBlockAccount(kps.Address(), initial).MustSave(st)
+	block.NewBlockAccount(kpt.Address(), initial).MustSave(st)
+
+	latest := block.TestMakeNewBlockWithPrevBlock(block.GetLatestBlock(st), nil)
+	latest.MustSave(st)
+	unlockHeight := latest.Height + 2
+	amount := common.Amount(10000)
+
+	finish := func(kp keypair.KP, opb operation.Body) (*transaction.Transaction, error) {
+		ba, _ := block.GetBlockAccount(st, kp.Address())
+		op, _ := operation.NewOperation(opb)
+		tx, _ := transaction.NewTransaction(kp.Address(), ba.SequenceID, op)
+		tx.Sign(kp, networkID)
+
+		if err := tx.IsWellFormed(networkID, common.NewConfig()); err != nil {
+			return nil, err
+		}
+		if err := ValidateTx(st, tx); err != nil {
+			return nil, err
+		}
+
+		blk := block.TestMakeNewBlockWithPrevBlock(latest, []string{tx.GetHash()})
+		blk.MustSave(st)
+		latest = blk
+
+		return &tx, FinishTransactions(blk, []*transaction.Transaction{&tx}, st)
+	}
+
+	{ // the unlock height should be in future
+		_, err := finish(kps, operation.NewTimeLockedPayment(kpt.Address(), amount, latest.Height))
+		require.Equal(t, errors.TimeLockHeightPassed, err)
+	}
+
+	tx, err := finish(kps, operation.NewTimeLockedPayment(kpt.Address(), amount, unlockHeight))
+	require.NoError(t, err)
+
+	{ // withdrawn from the source, but not deposited to the target
+		bas, _ := block.GetBlockAccount(st, kps.Address())
+		require.Equal(t, initial-amount-tx.B.Fee, bas.Balance)
+		bat, _ := block.GetBlockAccount(st, kpt.Address())
+		require.Equal(t, initial, bat.Balance)
+
+		lock, err := block.GetBlockTimeLock(st, kpt.Address(), kps.Address(), unlockHeight)
+		require.NoError(t, err)
+		require.Equal(t, amount, lock.Amount)
+
+		bt := block.NewBlockTransactionFromTransaction(latest.Hash, latest.Height, latest.Confirmed, *tx)
+		require.NoError(t, bt.SaveBlockOperations(st, latest))
+
+		iterFunc, closeFunc := block.GetBlockOperationsBySource(st, kps.Address(), nil)
+		bo, _, _ := iterFunc()
+		closeFunc()
+		require.Equal(t, operation.TypeTimeLockedPayment, bo.Type)
+		require.Equal(t, tx.GetHash(), bo.TxHash)
+
+		// the transaction is also listed for the target
+		iterTx, closeTx := block.GetBlockTransactionsByAccount(st, kpt.Address(), nil)
+		btt, _, _ := iterTx()
+		closeTx()
+		require.Equal(t, tx.GetHash(), btt.Hash)
+	}
+
+	claim := operation.NewTimeLockClaim(kps.Address(), unlockHeight)
+
+	{ // premature claim
+		require.True(t, latest.Height < unlockHeight)
+		_, err := finish(kpt, claim)
+		require.Equal(t, errors.TimeLockNotUnlocked, err)
+	}
+
+	{ // the other account can not claim
+		_, err := finish(kps, operation.NewTimeLockClaim(kps.Address(), unlockHeight))
+		require.Equal(t, errors.TimeLockDoesNotExist, err)
+	}
+
+	latest = block.TestMakeNewBlockWithPrevBlock(latest, nil)
+	latest.MustSave(st)
+	require.Equal(t, unlockHeight, latest.Height)
+
+	tx, err = finish(kpt, claim)
+	require.NoError(t, err)
+
+	bat, _ := block.GetBlockAccount(st, kpt.Address())
+	require.Equal(t, initial+amount-tx.B.Fee, bat.Balance)
+
+	_, err = block.GetBlockTimeLock(st, kpt.Address(), kps.Address(), unlockHeight)
+	require.Equal(t, errors.StorageRecordDoesNotExist, err)
+
+	{ // claimed only once
+		_, err := finish(kpt, claim)
+		require.Equal(t, errors.TimeLockDoesNotExist, err)
+	}
+}
+
+// Check `AccountMerge` deposits the rest of balance to the target and closes
+// the source, so the closed source can not be used again.
+func TestValidateOpAccountMerge(t *testing.T) {
+	kps := keypair.Random()
+	kpt := keypair.Random()
+	kpo := keypair.Random()
+
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	initial := common.Amount(1 * common.AmountPerCoin)
+	block.NewBlockAccount(kps.Address(), initial).MustSave(st)
+	block.NewBlockAccount(kpt.Address(), initial).MustSave(st)
+	block.NewBlockAccount(kpo.Address(), initial).MustSave(st)
+
+	latest := block.GetLatestBlock(st)
+	amount := common.Amount(10000)
+
+	finish := func(kp keypair.KP, opbs ...operation.Body) (*transaction.Transaction, error) {
+		ba, _ := block.GetBlockAccount(st, kp.Address())
+		var ops []operation.Operation
+		for _, opb := range opbs {
+			op, _ := operation.NewOperation(opb)
+			ops = append(ops, op)
+		}
+		tx, _ := transaction.NewTransaction(kp.Address(), ba.SequenceID, ops...)
+		tx.Sign(kp, networkID)
+
+		if err := tx.IsWellFormed(networkID, common.NewConfig()); err != nil {
+			return nil, err
+		}
+		if err := ValidateTx(st, tx); err != nil {
+			return nil, err
+		}
+
+		blk := block.TestMakeNewBlockWithPrevBlock(latest, []string{tx.GetHash()})
+		blk.MustSave(st)
+		latest = blk
+
+		return &tx, FinishTransactions(blk, []*transaction.Transaction{&tx}, st)
+	}
+
+	merge := operation.NewAccountMerge(kpt.Address())
+
+	{ // the merge must be the last operation
+		_, err := finish(kps, merge, operation.NewPayment(kpo.Address(), amount))
+		require.Equal(t, errors.InvalidOperation, err)
+	}
+
+	{ // can not be merged into itself
+		_, err := finish(kps, operation.NewAccountMerge(kps.Address()))
+		require.Equal(t, errors.InvalidOperation, err)
+	}
+
+	{ // the source has the time lock
+		lock, err := block.AddBlockTimeLock(st, kps.Address(), kpo.Address(), latest.Height+10, amount)
+		require.NoError(t, err)
+
+		_, err = finish(kps, merge)
+		require.Equal(t, errors.AccountMergeTimeLockRemains, err)
+
+		require.NoError(t, lock.Remove(st))
+	}
+
+	tx, err := finish(kps, operation.NewPayment(kpo.Address(), amount), merge)
+	require.NoError(t, err)
+
+	{ // the rest of balance is merged into the target
+		bas, _ := block.GetBlockAccount(st, kps.Address())
+		require.True(t, bas.Closed)
+		require.Equal(t, common.Amount(0), bas.Balance)
+
+		rest := initial - amount - tx.B.Fee
+		bat, _ := block.GetBlockAccount(st, kpt.Address())
+		require.False(t, bat.Closed)
+		require.Equal(t, initial+rest, bat.Balance)
+
+		bao, _ := block.GetBlockAccount(st, kpo.Address())
+		require.Equal(t, initial+amount, bao.Balance)
+
+		merged, err := block.GetBlockAccountMerge(st, kps.Address())
+		require.NoError(t, err)
+		require.Equal(t, kpt.Address(), merged.Target)
+		require.Equal(t, rest, merged.Amount)
+
+		// the merge is indexed with the merged amount
+		bt := block.NewBlockTransactionFromTransaction(latest.Hash, latest.Height, latest.Confirmed, *tx)
+		require.NoError(t, bt.SaveBlockOperations(st, latest))
+
+		iterFunc, closeFunc := block.GetBlockOperationsByTxHash(st, tx.GetHash(), nil)
+		var types []operation.OperationType
+		for {
+			bo, hasNext, _ := iterFunc()
+			if !hasNext {
+				break
+			}
+			types = append(types, bo.Type)
+		}
+		closeFunc()
+		require.Contains(t, types, operation.TypeAccountMerge)
+
+		rollup, err := block.GetBlockAccountRollup(st, kpt.Address())
+		require.NoError(t, err)
+		require.Equal(t, rest, rollup.TotalReceived)
+	}
+
+	{ // the closed account can not be the source
+		_, err := finish(kps, operation.NewPayment(kpo.Address(), amount))
+		require.Equal(t, errors.BlockAccountClosed, err)
+	}
+
+	{ // the closed account can not receive deposit
+		_, err := finish(kpo, operation.NewPayment(kps.Address(), amount))
+		require.Equal(t, errors.BlockAccountClosedNoDeposit, err)
+
+		_, err = finish(kpo, operation.NewAccountMerge(kps.Address()))
+		require.Equal(t, errors.BlockAccountClosedNoDeposit, err)
+	}
+}
+
+// Check the transaction, which deposits to the account merged in the same
+// ballot, is excluded.
+func TestBallotTransactionsMergedAccount(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	kps := keypair.Random()
+	kpt := keypair.Random()
+	kpo := keypair.Random()
+
+	pool := transaction.NewPool()
+	newTx := func(kp keypair.KP, opb operation.Body) transaction.Transaction {
+		op, _ := operation.NewOperation(opb)
+		tx, _ := transaction.NewTransaction(kp.Address(), 0, op)
+		tx.Sign(kp, networkID)
+		pool.Add(tx)
+
+		return tx
+	}
+
+	txMerge := newTx(kps, operation.NewAccountMerge(kpt.Address()))
+	txPayment := newTx(kpo, operation.NewPayment(kps.Address(), common.Amount(10000)))
+	txOther := newTx(kpt, operation.NewPayment(kpo.Address(), common.Amount(10000)))
+
+	newChecker := func(checkTransactionsOnly bool) *BallotTransactionChecker {
+		checker := &BallotTransactionChecker{
+			CheckTransactionsOnly: checkTransactionsOnly,
+			transactionCache:      NewTransactionCache(st, pool),
+		}
+		checker.setValidTransactions([]string{txMerge.GetHash(), txPayment.GetHash(), txOther.GetHash()})
+
+		return checker
+	}
+
+	{
+		checker := newChecker(true)
+		require.NoError(t, BallotTransactionsMergedAccount(checker))
+		require.Equal(t, []string{txMerge.GetHash(), txOther.GetHash()}, checker.ValidTransactions)
+	}
+
+	{
+		checker := newChecker(false)
+		require.Equal(t, errors.BlockAccountClosedNoDeposit, BallotTransactionsMergedAccount(checker))
+	}
+}
+
+// Check the transaction, whose operation is already stored or duplicated, is
+// excluded before storing the block.
+func TestBallotTransactionsOperationsNew(t *testing.T) {
+	nr, _, _ := createNodeRunnerForTesting(1, common.NewConfig(), nil)
+	st := nr.Storage()
+
+	pool := transaction.NewPool()
+	newTx := func(kp keypair.KP, opbs ...operation.Body) transaction.Transaction {
+		var ops []operation.Operation
+		for _, opb := range opbs {
+			op, _ := operation.NewOperation(opb)
+			ops = append(ops, op)
+		}
+		tx, _ := transaction.NewTransaction(kp.Address(), 0, ops...)
+		tx.Sign(kp, networkID)
+		pool.Add(tx)
+
+		return tx
+	}
+
+	data := operation.NewManageData("showme", []byte("findme"))
+	txDuplicated := newTx(keypair.Random(), data, data)
+	txPrior := newTx(keypair.Random(), operation.NewPayment(keypair.Random().Address(), common.Amount(10000)))
+	txNew := newTx(keypair.Random(), operation.NewPayment(keypair.Random().Address(), common.Amount(10000)))
+
+	// the operation of `txPrior` is stored by the prior block
+	bo, err := block.NewBlockOperationFromOperation(txPrior.B.Operations[0], txPrior, 1)
+	require.NoError(t, err)
+	require.NoError(t, bo.Save(st))
+
+	newChecker := func(checkTransactionsOnly bool, hashes ...string) *BallotTransactionChecker {
+		checker := &BallotTransactionChecker{
+			NodeRunner:            nr,
+			CheckTransactionsOnly: checkTransactionsOnly,
+			transactionCache:      NewTransactionCache(st, pool),
+		}
+		checker.setValidTransactions(hashes)
+
+		return checker
+	}
+
+	{
+		checker := newChecker(true, txDuplicated.GetHash(), txPrior.GetHash(), txNew.GetHash(), txNew.GetHash())
+		require.NoError(t, BallotTransactionsOperationsNew(checker))
+		require.Equal(t, []string{txNew.GetHash()}, checker.ValidTransactions)
+	}
+
+	for _, hash := range []string{txDuplicated.GetHash(), txPrior.GetHash()} {
+		checker := newChecker(false, txNew.GetHash(), hash)
+		err := BallotTransactionsOperationsNew(checker)
+		require.Error(t, err)
+		require.Equal(t, errors.BlockOperationAlreadyExists.Code, err.(*errors.Error).Code)
+	}
+
+	{ // the same transaction twice in the ballot
+		checker := newChecker(false, txNew.GetHash(), txNew.GetHash())
+		err := BallotTransactionsOperationsNew(checker)
+		require.Error(t, err)
+		require.Equal(t, errors.BlockOperationAlreadyExists.Code, err.(*errors.Error).Code)
+	}
+
+	{
+		checker := newChecker(false, txNew.GetHash())
+		require.NoError(t, BallotTransactionsOperationsNew(checker))
+		require.Equal(t, []string{txNew.GetHash()}, checker.ValidTransactions)
+	}
+}
+
+func TestValidateOpGovernance(t *testing.T) {
+	kps := keypair.Random()
+	kpy := keypair.Random()
+	kpn := keypair.Random()
+	kpf := keypair.Random()
+
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	initial := common.Amount(1 * common.AmountPerCoin)
+	block.NewBlockAccount(kps.Address(), initial).MustSave(st)
+	block.NewBlockAccount(kpy.Address(), initial*3).MustSave(st)
+	block.NewBlockAccount(kpn.Address(), initial*2).MustSave(st)
+	block.NewBlockAccountLinked(kpf.Address(), initial*10, kps.Address()).MustSave(st)
+
+	latest := block.GetLatestBlock(st)
+	closeHeight := latest.Height + 3
+
+	finish := func(kp keypair.KP, opb operation.Body) (*transaction.Transaction, error) {
+		ba, _ := block.GetBlockAccount(st, kp.Address())
+		op, _ := operation.NewOperation(opb)
+		tx, _ := transaction.NewTransaction(kp.Address(), ba.SequenceID, op)
+		tx.Sign(kp, networkID)
+
+		if err := tx.IsWellFormed(networkID, common.NewConfig()); err != nil {
+			return nil, err
+		}
+		if err := ValidateTx(st, tx); err != nil {
+			return nil, err
+		}
+
+		blk := block.TestMakeNewBlockWithPrevBlock(latest, []string{tx.GetHash()})
+		blk.MustSave(st)
+		latest = blk
+
+		return &tx, FinishTransactions(blk, []*transaction.Transaction{&tx}, st)
+	}
+
+	{ // the close height should be in future
+		_, err := finish(kps, operation.NewGovernanceProposal("raise the base fee", nil, latest.Height))
+		require.Equal(t, errors.GovernanceCloseHeightPassed, err)
+	}
+
+	{ // unknown proposal
+		_, err := finish(kpy, operation.NewGovernanceVote("unknown", operation.GovernanceChoiceYes))
+		require.Equal(t, errors.GovernanceProposalDoesNotExist, err)
+	}
+
+	opb := operation.NewGovernanceProposal("raise the base fee", []byte("base fee to 20000"), closeHeight)
+	tx, err := finish(kps, opb)
+	require.NoError(t, err)
+
+	op, _ := operation.NewOperation(opb)
+	hash := block.NewBlockOperationKey(op.MakeHashString(), tx.GetHash())
+	{
+		p, err := block.GetGovernanceProposal(st, hash)
+		require.NoError(t, err)
+		require.Equal(t, kps.Address(), p.Source)
+		require.Equal(t, closeHeight, p.CloseHeight)
+		require.Equal(t, latest.Height, p.Height)
+		require.False(t, p.Closed)
+	}
+
+	{ // frozen account can not vote
+		_, err := finish(kpf, operation.NewGovernanceVote(hash, operation.GovernanceChoiceYes))
+		require.Equal(t, errors.GovernanceVoteFromFrozenAccount, err)
+	}
+
+	_, err = finish(kpy, operation.NewGovernanceVote(hash, operation.GovernanceChoiceYes))
+	require.NoError(t, err)
+	require.True(t, latest.Height < closeHeight)
+
+	// the vote at the close height is counted
+	_, err = finish(kpn, operation.NewGovernanceVote(hash, operation.GovernanceChoiceNo))
+	require.NoError(t, err)
+	require.Equal(t, closeHeight, latest.Height)
+
+	{
+		p, err := block.GetGovernanceProposal(st, hash)
+		require.NoError(t, err)
+		require.True(t, p.Closed)
+
+		bay, _ := block.GetBlockAccount(st, kpy.Address())
+		ban, _ := block.GetBlockAccount(st, kpn.Address())
+		require.Equal(t, bay.Balance, p.Yes)
+		require.Equal(t, ban.Balance, p.No)
+		require.Equal(t, common.Amount(0), p.Abstain)
+		require.True(t, p.Passed)
+	}
+
+	{ // closed proposal
+		_, err := finish(kps, operation.NewGovernanceVote(hash, operation.GovernanceChoiceNo))
+		require.Equal(t, errors.GovernanceProposalClosed, err)
+	}
+}
@@ -38,6 +38,11 @@ type MessageChecker struct {
 	TransactionPool *transaction.Pool
 	Storage         *storage.LevelDBBackend
 	Transaction     transaction.Transaction
+	Metrics         *MessageMetrics
+
+	// held is set by `MessageValidate` when the transaction has the future
+	// sequenceID; it is held in `Pool` instead of being rejected.
+	held bool
 }
 
 // TransactionUnmarshal makes `Transaction` from
@@ -50,6 +55,13 @@ func TransactionUnmarshal(c common.Checker, args ...interface{}) (err error) {
 		return
 	}
 
+	if err = tx.VerifyNetworkID(checker.NetworkID); err != nil {
+		if err == errors.MessageSignatureInvalid {
+			checker.Metrics.increaseInvalidSignatures(common.TransactionMessage)
+		}
+		return
+	}
+
 	if err = tx.IsWellFormed(checker.NetworkID, checker.Conf); err != nil {
 		return
 	}
@@ -103,23 +115,34 @@ func SaveTransactionHistory(c common.Checker, args ...interface{}) (err error) {
 }
 
 // SameSource checks there are transactions which has same source in the
-// `Pool`.
+// `Pool`. The transaction of same source and sequenceID can be accepted only
+// if it can replace the existing one by the higher fee; see `Pool.Replace`.
+// The transaction of the other sequenceID is left to `MessageValidate`.
 func MessageHasSameSource(c common.Checker, args ...interface{}) (err error) {
 	checker := c.(*MessageChecker)
 
-	if checker.TransactionPool.IsSameSource(checker.Transaction.Source()) {
-		err = errors.TransactionSameSourceInPool
+	if !checker.TransactionPool.IsSameSource(checker.Transaction.Source()) {
 		return
 	}
 
+	err = checker.TransactionPool.IsReplaceable(checker.Transaction, checker.Conf.MinFeeBump)
+	if err == errors.TransactionSameSourceInPool {
+		err = nil
+	}
+
 	return
 }
 
-// MessageValidate validates.
+// MessageValidate validates. The transaction with the future sequenceID is
+// not rejected, but it will be held by `PushIntoTransactionPool`.
 func MessageValidate(c common.Checker, args ...interface{}) (err error) {
 	checker := c.(*MessageChecker)
 
 	if err = ValidateTx(checker.Storage, checker.Transaction); err != nil {
+		if err == errors.TransactionFutureSequenceID {
+			checker.held = true
+			err = nil
+		}
 		return
 	}
 
@@ -132,7 +155,25 @@ func PushIntoTransactionPool(c common.Checker, args ...interface{}) (err error)
 	checker := c.(*MessageChecker)
 
 	tx := checker.Transaction
-	checker.TransactionPool.Add(tx)
+	if checker.held {
+		// the held transaction is also saved in storage like the others,
+		// because it can be proposed by the other nodes after it is released.
+		if !checker.TransactionPool.Hold(tx) {
+			err = errors.TransactionFutureSequenceID
+			return
+		}
+		checker.Log.Debug("hold transaction in TransactionPool", "sequenceID", tx.B.SequenceID)
+	} else if checker.TransactionPool.IsSameSource(tx.Source()) && !checker.TransactionPool.Has(tx.GetHash()) {
+		// the replaced transaction is kept in storage, because the other nodes
+		// may still request it for the running ballot.
+		var replaced transaction.Transaction
+		if replaced, err = checker.TransactionPool.Replace(tx, checker.Conf.MinFeeBump); err != nil {
+			return
+		}
+		checker.Log.Debug("replace transaction in TransactionPool", "replaced", replaced.GetHash())
+	} else {
+		checker.TransactionPool.Add(tx)
+	}
 
 	if _, err = block.SaveTransactionPool(checker.Storage, tx); err != nil {
 		return
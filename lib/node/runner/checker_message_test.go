@@ -107,3 +107,111 @@ func TestMessageCheckerWithInvalidHash(t *testing.T) {
 	require.EqualError(t, err, "unexpected end of JSON input")
 	require.NotEqual(t, checker.Transaction, invalidTx)
 }
+
+func TestMessageCheckerReplaceByFee(t *testing.T) {
+	kp, tx := transaction.TestMakeTransaction(networkID, 1)
+
+	nodeRunner, localNode := MakeNodeRunner()
+	newChecker := func(tx transaction.Transaction) *MessageChecker {
+		return &MessageChecker{
+			Consensus:       nodeRunner.Consensus(),
+			Storage:         nodeRunner.Storage(),
+			TransactionPool: nodeRunner.TransactionPool,
+			LocalNode:       localNode,
+			NetworkID:       networkID,
+			Log:             nodeRunner.Log(),
+			Conf:            nodeRunner.Conf,
+			Transaction:     tx,
+		}
+	}
+
+	require.NoError(t, MessageHasSameSource(newChecker(tx)))
+	require.NoError(t, PushIntoTransactionPool(newChecker(tx)))
+
+	{ // insufficient bump
+		bumped := tx
+		bumped.B.Fee = tx.B.Fee.MustAdd(nodeRunner.Conf.MinFeeBump - 1)
+		bumped.Sign(kp, networkID)
+		require.Equal(t, errors.TransactionFeeBumpTooLow, MessageHasSameSource(newChecker(bumped)))
+	}
+
+	{ // mismatched sequenceID is left to `MessageValidate`
+		bumped := tx
+		bumped.B.Fee = tx.B.Fee.MustAdd(nodeRunner.Conf.MinFeeBump)
+		bumped.B.SequenceID = tx.B.SequenceID + 1
+		bumped.Sign(kp, networkID)
+		require.NoError(t, MessageHasSameSource(newChecker(bumped)))
+	}
+
+	{ // replaced
+		bumped := tx
+		bumped.B.Fee = tx.B.Fee.MustAdd(nodeRunner.Conf.MinFeeBump)
+		bumped.Sign(kp, networkID)
+		require.NoError(t, MessageHasSameSource(newChecker(bumped)))
+		require.NoError(t, PushIntoTransactionPool(newChecker(bumped)))
+
+		require.False(t, nodeRunner.TransactionPool.Has(tx.GetHash()))
+		require.True(t, nodeRunner.TransactionPool.Has(bumped.GetHash()))
+
+		// the replaced one is kept in storage
+		exists, err := block.ExistsTransactionPool(nodeRunner.Storage(), tx.GetHash())
+		require.NoError(t, err)
+		require.True(t, exists)
+	}
+}
+
+// The transaction with the future sequenceID is held in `Pool` instead of
+// being rejected, and the stale one is still rejected.
+func TestMessageCheckerHoldFutureSequenceID(t *testing.T) {
+	nodeRunner, localNode := MakeNodeRunner()
+	newChecker := func(tx transaction.Transaction) *MessageChecker {
+		return &MessageChecker{
+			Consensus:       nodeRunner.Consensus(),
+			Storage:         nodeRunner.Storage(),
+			TransactionPool: nodeRunner.TransactionPool,
+			LocalNode:       localNode,
+			NetworkID:       networkID,
+			Log:             nodeRunner.Log(),
+			Conf:            nodeRunner.Conf,
+			Transaction:     tx,
+		}
+	}
+
+	expected, err := block.GetNextSequenceID(nodeRunner.Storage(), block.GenesisKP.Address())
+	require.NoError(t, err)
+
+	current, _, _ := GetCreateAccountTransaction(expected, uint64(common.BaseReserve))
+	future, _, _ := GetCreateAccountTransaction(expected+1, uint64(common.BaseReserve))
+
+	{ // future
+		checker := newChecker(future)
+		require.NoError(t, MessageValidate(checker))
+		require.NoError(t, PushIntoTransactionPool(checker))
+
+		require.True(t, nodeRunner.TransactionPool.IsHeld(future.GetHash()))
+		require.False(t, nodeRunner.TransactionPool.Has(future.GetHash()))
+
+		exists, err := block.ExistsTransactionPool(nodeRunner.Storage(), future.GetHash())
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		// same sequenceID is already held
+		again, _, _ := GetCreateAccountTransaction(expected+1, uint64(common.BaseReserve))
+		checker = newChecker(again)
+		require.NoError(t, MessageValidate(checker))
+		require.Equal(t, errors.TransactionFutureSequenceID, PushIntoTransactionPool(checker))
+	}
+
+	{ // current
+		checker := newChecker(current)
+		require.NoError(t, MessageHasSameSource(checker))
+		require.NoError(t, MessageValidate(checker))
+		require.NoError(t, PushIntoTransactionPool(checker))
+		require.True(t, nodeRunner.TransactionPool.Has(current.GetHash()))
+	}
+
+	if expected > 0 { // stale
+		stale, _, _ := GetCreateAccountTransaction(expected-1, uint64(common.BaseReserve))
+		require.Equal(t, errors.TransactionStaleSequenceID, MessageValidate(newChecker(stale)))
+	}
+}
@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"sync"
+	"time"
+
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/voting"
+)
+
+type ConsensusEventType string
+
+const (
+	// ConsensusEventProposalMade is emitted when the local node proposes the
+	// new ballot.
+	ConsensusEventProposalMade ConsensusEventType = "proposal-made"
+	// ConsensusEventVoteCast is emitted when the local node broadcasts the
+	// vote of SIGN or ACCEPT, including EXP.
+	ConsensusEventVoteCast ConsensusEventType = "vote-cast"
+	// ConsensusEventRoundExpired is emitted when the round is given up and the
+	// next round starts.
+	ConsensusEventRoundExpired ConsensusEventType = "round-expired"
+	// ConsensusEventHeightAdvanced is emitted when the new block is stored.
+	ConsensusEventHeightAdvanced ConsensusEventType = "height-advanced"
+	// ConsensusEventBlockFinalized is emitted when the block is followed by
+	// `common.Config.FinalityConfirmations` blocks.
+	ConsensusEventBlockFinalized ConsensusEventType = "block-finalized"
+	// ConsensusEventHalted is emitted when the consensus of the local node is
+	// halted by the error; see `StopReason`.
+	ConsensusEventHalted ConsensusEventType = "halted"
+)
+
+// ConsensusEvent is the structured event of ISAAC consensus of the local
+// node. `Height` and `Round` are the voting basis of the ballot, except
+// `ConsensusEventHeightAdvanced` and `ConsensusEventBlockFinalized`, whose
+// `Height` and `Round` are of the block.
+//  * `Proposer`: the proposer of the round
+//  * `BallotState` and `Vote`: only for `ConsensusEventVoteCast`
+//  * `BlockHash`: only for `ConsensusEventHeightAdvanced` and
+//    `ConsensusEventBlockFinalized`
+//  * `Reason`: for `ConsensusEventHalted`, and for `ConsensusEventVoteCast`
+//    of EXP, the `ballot.ExpiredReason`
+type ConsensusEvent struct {
+	Type        ConsensusEventType `json:"type"`
+	Height      uint64             `json:"height"`
+	Round       uint64             `json:"round"`
+	Proposer    string             `json:"proposer,omitempty"`
+	BallotState ballot.State       `json:"ballot_state,omitempty"`
+	Vote        voting.Hole        `json:"vote,omitempty"`
+	BlockHash   string             `json:"block_hash,omitempty"`
+	Reason      string             `json:"reason,omitempty"`
+	Time        time.Time          `json:"time"`
+}
+
+// ConsensusEventEmitter delivers the `ConsensusEvent`s to the subscribers.
+// The subscribers are called synchronously in the consensus, so they must not
+// block; the slow consumer like the analytics pipeline should queue the
+// events by itself.
+type ConsensusEventEmitter struct {
+	sync.RWMutex
+
+	lastID      int
+	subscribers []consensusEventSubscriber
+}
+
+type consensusEventSubscriber struct {
+	id int
+	f  func(ConsensusEvent)
+}
+
+func NewConsensusEventEmitter() *ConsensusEventEmitter {
+	return &ConsensusEventEmitter{}
+}
+
+// Subscribe adds the subscriber; the returned function removes it.
+func (e *ConsensusEventEmitter) Subscribe(f func(ConsensusEvent)) (unsubscribe func()) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.lastID++
+	id := e.lastID
+	e.subscribers = append(e.subscribers, consensusEventSubscriber{id: id, f: f})
+
+	return func() {
+		e.Lock()
+		defer e.Unlock()
+
+		for i, s := range e.subscribers {
+			if s.id == id {
+				e.subscribers = append(e.subscribers[:i:i], e.subscribers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Emit sets the time of the event and calls the subscribers in the order of
+// subscription.
+func (e *ConsensusEventEmitter) Emit(event ConsensusEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	e.RLock()
+	subscribers := e.subscribers
+	e.RUnlock()
+
+	for _, s := range subscribers {
+		s.f(event)
+	}
+}
+
+// NewConsensusEventLogger returns the subscriber, which writes the events to
+// the text logger.
+func NewConsensusEventLogger(logger logging.Logger) func(ConsensusEvent) {
+	return func(event ConsensusEvent) {
+		ctx := []interface{}{"height", event.Height, "round", event.Round}
+		if len(event.Proposer) > 0 {
+			ctx = append(ctx, "proposer", event.Proposer)
+		}
+
+		switch event.Type {
+		case ConsensusEventVoteCast:
+			ctx = append(ctx, "ballotState", event.BallotState, "vote", event.Vote)
+		case ConsensusEventHeightAdvanced, ConsensusEventBlockFinalized:
+			ctx = append(ctx, "block", event.BlockHash)
+		case ConsensusEventHalted:
+			ctx = append(ctx, "reason", event.Reason)
+		}
+
+		logger.Debug(string(event.Type), ctx...)
+	}
+}
@@ -0,0 +1,136 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/voting"
+)
+
+type consensusEventRecorder struct {
+	sync.Mutex
+	events []ConsensusEvent
+}
+
+func (r *consensusEventRecorder) record(event ConsensusEvent) {
+	r.Lock()
+	defer r.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *consensusEventRecorder) Types() (types []ConsensusEventType) {
+	r.Lock()
+	defer r.Unlock()
+	for _, event := range r.events {
+		types = append(types, event.Type)
+	}
+	return
+}
+
+func TestConsensusEventEmitter(t *testing.T) {
+	emitter := NewConsensusEventEmitter()
+
+	var first, second consensusEventRecorder
+	emitter.Subscribe(first.record)
+	unsubscribe := emitter.Subscribe(second.record)
+
+	emitter.Emit(ConsensusEvent{Type: ConsensusEventProposalMade})
+	unsubscribe()
+	emitter.Emit(ConsensusEvent{Type: ConsensusEventRoundExpired})
+
+	require.Equal(t, []ConsensusEventType{ConsensusEventProposalMade, ConsensusEventRoundExpired}, first.Types())
+	require.Equal(t, []ConsensusEventType{ConsensusEventProposalMade}, second.Types())
+	require.False(t, first.events[0].Time.IsZero())
+}
+
+// TestConsensusEventSimulation runs one round like
+// `TestISAACSimulationProposer` and then expires the next round.
+func TestConsensusEventSimulation(t *testing.T) {
+	nr, nodes, _ := createNodeRunnerForTesting(5, common.NewConfig(), nil)
+	tx, _ := GetTransaction()
+
+	var recorder consensusEventRecorder
+	nr.ConsensusEvents().Subscribe(recorder.record)
+
+	proposer := nr.localNode
+	nr.TransactionPool.Add(tx)
+
+	_, err := nr.proposeNewBallot(context.Background(), 0)
+	require.NoError(t, err)
+
+	b := nr.Consensus().LatestBlock()
+	round := voting.Basis{
+		Round:     0,
+		Height:    b.Height,
+		BlockHash: b.Hash,
+		TotalTxs:  b.TotalTxs,
+	}
+
+	conf := common.NewConfig()
+	for _, state := range []ballot.State{ballot.StateSIGN, ballot.StateACCEPT} {
+		for _, n := range nodes[1:] {
+			err = ReceiveBallot(nr, GenerateBallot(proposer, round, tx, state, n, conf))
+			if _, ok := err.(CheckerStopCloseConsensus); ok {
+				break
+			}
+			require.NoError(t, err)
+		}
+	}
+	_, ok := err.(CheckerStopCloseConsensus)
+	require.True(t, ok)
+
+	require.Equal(
+		t,
+		[]ConsensusEventType{
+			ConsensusEventProposalMade,
+			ConsensusEventVoteCast,
+			ConsensusEventHeightAdvanced,
+			ConsensusEventBlockFinalized,
+		},
+		recorder.Types(),
+	)
+
+	proposal := recorder.events[0]
+	require.Equal(t, b.Height, proposal.Height)
+	require.Equal(t, uint64(0), proposal.Round)
+	require.Equal(t, proposer.Address(), proposal.Proposer)
+
+	vote := recorder.events[1]
+	require.Equal(t, b.Height, vote.Height)
+	require.Equal(t, ballot.StateACCEPT, vote.BallotState)
+	require.Equal(t, voting.YES, vote.Vote)
+
+	latest := nr.Consensus().LatestBlock()
+	advanced := recorder.events[2]
+	require.Equal(t, b.Height+1, advanced.Height)
+	require.Equal(t, latest.Hash, advanced.BlockHash)
+	require.Equal(t, proposer.Address(), advanced.Proposer)
+
+	// without `FinalityConfirmations`, the new block is final by itself
+	finalized := recorder.events[3]
+	require.Equal(t, advanced.Height, finalized.Height)
+	require.Equal(t, latest.Hash, finalized.BlockHash)
+
+	// the next round is expired
+	state := consensus.ISAACState{Height: latest.Height, Round: 0, BallotState: ballot.StateINIT}
+	nr.isaacStateManager.setState(state)
+	nr.isaacStateManager.broadcastExpiredBallot(state, ballot.ExpiredReasonTimeout)
+	nr.isaacStateManager.IncreaseRound()
+
+	require.Equal(t, ConsensusEventVoteCast, recorder.events[4].Type)
+	require.Equal(t, ballot.StateSIGN, recorder.events[4].BallotState)
+	require.Equal(t, voting.EXP, recorder.events[4].Vote)
+	require.Equal(t, string(ballot.ExpiredReasonTimeout), recorder.events[4].Reason)
+	require.Equal(t, latest.Height, recorder.events[4].Height)
+
+	require.Equal(t, ConsensusEventRoundExpired, recorder.events[5].Type)
+	require.Equal(t, latest.Height, recorder.events[5].Height)
+	require.Equal(t, uint64(0), recorder.events[5].Round)
+	require.Equal(t, 6, len(recorder.Types()))
+}
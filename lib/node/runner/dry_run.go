@@ -0,0 +1,242 @@
+package runner
+
+import (
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// DryRunAccountEffect is the projected change of an account by the
+// transaction.
+type DryRunAccountEffect struct {
+	Address string        `json:"address"`
+	Before  common.Amount `json:"before"`
+	After   common.Amount `json:"after"`
+	Created bool          `json:"created"`
+}
+
+// DryRunResult is the projected effects of the transaction.
+type DryRunResult struct {
+	Hash       string                `json:"hash"`
+	Fee        common.Amount         `json:"fee"`
+	SequenceID uint64                `json:"sequence_id"` // the resulting sequence ID of source account
+	Effects    []DryRunAccountEffect `json:"effects"`
+}
+
+// DryRunTransaction runs the transaction against the current account state
+// and returns the projected effects without storing anything. The transaction
+// is validated by `Transaction.IsWellFormed()` and `ValidateTx()` like the
+// incoming transaction. Only the balance effects are projected; the operation
+// type unknown to the dry run is refused by `errors.UnknownOperationType`.
+func DryRunTransaction(st *storage.LevelDBBackend, networkID []byte, conf common.Config, tx transaction.Transaction) (result DryRunResult, err error) {
+	// `ValidateTx()` may remove records, so it runs inside the batch, which
+	// will be discarded.
+	var bs *storage.LevelDBBackend
+	if bs, err = st.OpenBatch(); err != nil {
+		return
+	}
+	defer bs.Discard()
+
+	result, _, err = dryRunTransaction(bs, networkID, conf, tx)
+
+	return
+}
+
+// DryRunTransactions runs the ordered transactions like `DryRunTransaction()`;
+// each transaction runs against the account state changed by the previous
+// transactions. `accounts` overrides the starting account state in the
+// storage.
+//
+// If the batch fails, it returns the index of the failed transaction with the
+// error and the results of the previous transactions; if not, `failed` is
+// `-1`.
+func DryRunTransactions(st *storage.LevelDBBackend, networkID []byte, conf common.Config, txs []transaction.Transaction, accounts ...*block.BlockAccount) (results []DryRunResult, failed int, err error) {
+	failed = -1
+
+	var bs *storage.LevelDBBackend
+	if bs, err = st.OpenBatch(); err != nil {
+		return
+	}
+	defer bs.Discard()
+
+	for _, ba := range accounts {
+		if err = saveDryRunAccount(bs, ba); err != nil {
+			return
+		}
+	}
+
+	for i, tx := range txs {
+		var result DryRunResult
+		var changed map[string]*block.BlockAccount
+		if result, changed, err = dryRunTransaction(bs, networkID, conf, tx); err != nil {
+			failed = i
+			return
+		}
+
+		for _, ba := range changed {
+			if err = saveDryRunAccount(bs, ba); err != nil {
+				failed = i
+				return
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return
+}
+
+// saveDryRunAccount stores the account into the batch of dry run; unlike
+// `BlockAccount.Save()`, it does not trigger `observer.BlockAccountObserver`,
+// so the projected state is not broadcasted.
+func saveDryRunAccount(bs *storage.LevelDBBackend, ba *block.BlockAccount) (err error) {
+	key := block.GetBlockAccountKey(ba.Address)
+
+	var exists bool
+	if exists, err = bs.Has(key); err != nil {
+		return
+	} else if exists {
+		err = bs.Set(key, ba)
+	} else {
+		err = bs.New(key, ba)
+	}
+	if err != nil {
+		return
+	}
+
+	bac := block.BlockAccountSequenceID{
+		SequenceID: ba.SequenceID,
+		Address:    ba.Address,
+		Balance:    ba.GetBalance(),
+	}
+
+	return bac.Save(bs)
+}
+
+func dryRunTransaction(bs *storage.LevelDBBackend, networkID []byte, conf common.Config, tx transaction.Transaction) (result DryRunResult, accounts map[string]*block.BlockAccount, err error) {
+	if err = tx.IsWellFormed(networkID, conf); err != nil {
+		return
+	}
+
+	if err = ValidateTx(bs, tx); err != nil {
+		return
+	}
+
+	accounts = map[string]*block.BlockAccount{}
+	var effects []DryRunAccountEffect
+	getAccount := func(address string, create bool) (*block.BlockAccount, error) {
+		if ba, found := accounts[address]; found {
+			return ba, nil
+		}
+
+		ba, err := block.GetBlockAccount(bs, address)
+		if err != nil {
+			if !create {
+				return nil, errors.BlockAccountDoesNotExists
+			}
+			ba = block.NewBlockAccount(address, 0)
+		} else if create {
+			return nil, errors.BlockAccountAlreadyExists
+		}
+
+		accounts[address] = ba
+		effects = append(effects, DryRunAccountEffect{
+			Address: address,
+			Before:  ba.Balance,
+			Created: create,
+		})
+
+		return ba, nil
+	}
+
+	var source *block.BlockAccount
+	if source, err = getAccount(tx.B.Source, false); err != nil {
+		return
+	}
+
+	for _, op := range tx.B.Operations {
+		var target *block.BlockAccount
+		switch op.H.Type {
+		case operation.TypeCreateAccount:
+			pop, ok := op.B.(operation.CreateAccount)
+			if !ok {
+				err = errors.TypeOperationBodyNotMatched
+				return
+			}
+			if target, err = getAccount(pop.TargetAddress(), true); err != nil {
+				return
+			}
+			target.Linked = pop.Linked
+			err = target.Deposit(pop.GetAmount())
+		case operation.TypePayment:
+			pop, ok := op.B.(operation.Payment)
+			if !ok {
+				err = errors.TypeOperationBodyNotMatched
+				return
+			}
+			if target, err = getAccount(pop.TargetAddress(), false); err != nil {
+				return
+			}
+			err = target.Deposit(pop.GetAmount())
+		case operation.TypeTimeLockClaim:
+			pop, ok := op.B.(operation.TimeLockClaim)
+			if !ok {
+				err = errors.TypeOperationBodyNotMatched
+				return
+			}
+			var lock block.BlockTimeLock
+			if lock, err = block.GetBlockTimeLock(bs, source.Address, pop.Source, pop.UnlockHeight); err != nil {
+				return
+			}
+			err = source.Deposit(lock.Amount)
+		case operation.TypeTimeLockedPayment:
+			// withdrawn from the source by `Transaction.TotalAmount()`
+		case operation.TypeAccountMerge:
+			// merged after withdrawing the source
+		case operation.TypeCongressVoting,
+			operation.TypeCongressVotingResult,
+			operation.TypeUnfreezingRequest,
+			operation.TypeSetSigners,
+			operation.TypeManageData,
+			operation.TypeValidatorSetChange,
+			operation.TypeGovernanceProposal,
+			operation.TypeGovernanceVote:
+			// does not change the balance
+		default:
+			err = errors.UnknownOperationType.Clone().SetData("type", op.H.Type)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	if err = source.Withdraw(tx.TotalAmount(true)); err != nil {
+		return
+	}
+
+	if pop, ok := tx.AccountMerge(); ok {
+		var target *block.BlockAccount
+		if target, err = getAccount(pop.TargetAddress(), false); err != nil {
+			return
+		}
+		if _, err = block.MergeBlockAccount(bs, source, target); err != nil {
+			return
+		}
+	}
+
+	for i, effect := range effects {
+		effects[i].After = accounts[effect.Address].Balance
+	}
+
+	result = DryRunResult{
+		Hash:       tx.GetHash(),
+		Fee:        tx.B.Fee,
+		SequenceID: source.SequenceID,
+		Effects:    effects,
+	}
+
+	return
+}
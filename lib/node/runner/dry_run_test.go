@@ -0,0 +1,177 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func TestDryRunTransaction(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	conf := common.NewConfig()
+
+	genesisAccount, _ := block.GetBlockAccount(st, block.GenesisKP.Address())
+	amount := common.BaseReserve.MustMult(3)
+	tx, _, kpNewAccount := GetCreateAccountTransaction(genesisAccount.SequenceID, uint64(amount))
+
+	result, err := DryRunTransaction(st, networkID, conf, tx)
+	require.NoError(t, err)
+	require.Equal(t, tx.GetHash(), result.Hash)
+	require.Equal(t, tx.B.Fee, result.Fee)
+	require.Equal(t, genesisAccount.SequenceID+1, result.SequenceID)
+	require.Equal(t, 2, len(result.Effects))
+
+	// nothing is stored
+	exists, err := block.ExistsBlockAccount(st, kpNewAccount.Address())
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	notChanged, _ := block.GetBlockAccount(st, block.GenesisKP.Address())
+	require.Equal(t, genesisAccount.Balance, notChanged.Balance)
+	require.Equal(t, genesisAccount.SequenceID, notChanged.SequenceID)
+
+	// apply the transaction actually
+	blk := block.TestMakeNewBlockWithPrevBlock(block.GetLatestBlock(st), []string{tx.GetHash()})
+	require.NoError(t, FinishTransactions(blk, []*transaction.Transaction{&tx}, st))
+
+	for _, effect := range result.Effects {
+		ba, err := block.GetBlockAccount(st, effect.Address)
+		require.NoError(t, err)
+		require.Equal(t, effect.After, ba.Balance)
+	}
+
+	applied, _ := block.GetBlockAccount(st, block.GenesisKP.Address())
+	require.Equal(t, applied.SequenceID, result.SequenceID)
+	require.Equal(t, genesisAccount.Balance.MustSub(amount).MustSub(tx.B.Fee), applied.Balance)
+
+	created, _ := block.GetBlockAccount(st, kpNewAccount.Address())
+	require.Equal(t, amount, created.Balance)
+}
+
+func TestDryRunTransactionInvalid(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	conf := common.NewConfig()
+
+	{ // unknown source account
+		kp := keypair.Random()
+		tx := transaction.MakeTransactionCreateAccount(networkID, kp, keypair.Random().Address(), common.BaseReserve)
+
+		_, err := DryRunTransaction(st, networkID, conf, tx)
+		require.Equal(t, errors.BlockAccountDoesNotExists, err)
+	}
+
+	{ // invalid sequence ID
+		genesisAccount, _ := block.GetBlockAccount(st, block.GenesisKP.Address())
+		tx, _, _ := GetCreateAccountTransaction(genesisAccount.SequenceID+1, uint64(common.BaseReserve))
+
+		_, err := DryRunTransaction(st, networkID, conf, tx)
+		require.Equal(t, errors.TransactionFutureSequenceID, err)
+	}
+
+	{ // bad signature
+		genesisAccount, _ := block.GetBlockAccount(st, block.GenesisKP.Address())
+		tx, _, _ := GetCreateAccountTransaction(genesisAccount.SequenceID, uint64(common.BaseReserve))
+		tx.H.Signature = ""
+
+		_, err := DryRunTransaction(st, networkID, conf, tx)
+		require.Error(t, err)
+	}
+}
+
+// TestDryRunTransactionNoBalanceEffect runs the operation which does not
+// change the balance; only the fee is withdrawn from the source.
+func TestDryRunTransactionNoBalanceEffect(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	conf := common.NewConfig()
+
+	genesisAccount, _ := block.GetBlockAccount(st, block.GenesisKP.Address())
+	op, _ := operation.NewOperation(operation.NewManageData("showme", []byte("findme")))
+	tx, _ := transaction.NewTransaction(genesisAccount.Address, genesisAccount.SequenceID, op)
+	tx.Sign(block.GenesisKP, networkID)
+
+	result, err := DryRunTransaction(st, networkID, conf, tx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Effects))
+	require.Equal(t, genesisAccount.Balance, result.Effects[0].Before)
+	require.Equal(t, genesisAccount.Balance.MustSub(tx.B.Fee), result.Effects[0].After)
+
+	// nothing is stored
+	_, err = block.GetAccountData(st, genesisAccount.Address, "showme")
+	require.Equal(t, errors.StorageRecordDoesNotExist, err)
+}
+
+func TestDryRunTransactions(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	conf := common.NewConfig()
+
+	// the source account is given as starting state; it is not in the storage
+	kp := keypair.Random()
+	balance := common.BaseReserve.MustMult(3)
+	source := block.NewBlockAccount(kp.Address(), balance)
+	target := block.NewBlockAccount(keypair.Random().Address(), common.BaseReserve)
+
+	makePayment := func(sequenceID uint64, amount common.Amount) transaction.Transaction {
+		op, err := operation.NewOperation(operation.NewPayment(target.Address, amount))
+		require.NoError(t, err)
+		tx, err := transaction.NewTransaction(kp.Address(), sequenceID, op)
+		require.NoError(t, err)
+		tx.Sign(kp, networkID)
+		return tx
+	}
+
+	txs := []transaction.Transaction{
+		makePayment(source.SequenceID, common.BaseReserve),
+		makePayment(source.SequenceID+1, common.BaseReserve),
+		makePayment(source.SequenceID+2, common.BaseReserve), // fee is not left
+		makePayment(source.SequenceID+3, 1),
+	}
+
+	results, failed, err := DryRunTransactions(st, networkID, conf, txs, source, target)
+	require.Equal(t, errors.TransactionExcessAbilityToPay, err)
+	require.Equal(t, 2, failed)
+	require.Equal(t, 2, len(results))
+
+	require.Equal(t, source.SequenceID+1, results[0].SequenceID)
+	require.Equal(t, source.SequenceID+2, results[1].SequenceID)
+	require.Equal(t, balance, results[0].Effects[0].Before)
+	require.Equal(t, results[0].Effects[0].After, results[1].Effects[0].Before)
+	require.Equal(
+		t,
+		balance.MustSub(common.BaseReserve.MustAdd(common.BaseFee).MustMult(2)),
+		results[1].Effects[0].After,
+	)
+
+	// wrong sequence ID in the third transaction
+	txs[2] = makePayment(source.SequenceID+3, 1)
+	results, failed, err = DryRunTransactions(st, networkID, conf, txs, source, target)
+	require.Equal(t, errors.TransactionFutureSequenceID, err)
+	require.Equal(t, 2, failed)
+	require.Equal(t, 2, len(results))
+
+	// nothing is stored
+	exists, err := block.ExistsBlockAccount(st, kp.Address())
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// passed
+	txs[2] = makePayment(source.SequenceID+2, 1)
+	results, failed, err = DryRunTransactions(st, networkID, conf, txs, source, target)
+	require.NoError(t, err)
+	require.Equal(t, -1, failed)
+	require.Equal(t, len(txs), len(results))
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// generateExpiredBallot makes the EXP ballot of `state` like
+// `ISAACStateManager.broadcastExpiredBallot()`.
+func generateExpiredBallot(proposer *node.LocalNode, basis voting.Basis, state ballot.State, sender *node.LocalNode) *ballot.Ballot {
+	b := ballot.NewBallot(sender.Address(), proposer.Address(), basis, []string{})
+	b.SetVote(state, voting.EXP)
+
+	opi, _ := ballot.NewInflationFromBallot(*b, proposer.Address(), common.BaseReserve)
+	opc, _ := ballot.NewCollectTxFeeFromBallot(*b, proposer.Address())
+	ptx, _ := ballot.NewProposerTransactionFromBallot(*b, opc, opi)
+	b.SetProposerTransaction(ptx)
+	b.SignByProposer(proposer.Keypair(), networkID)
+	b.Sign(sender.Keypair(), networkID)
+
+	return b
+}
+
+// TestExpiredVotesThresholdIncreaseRound injects the ACCEPT EXP votes and
+// checks the round is increased, when the EXP votes reach
+// `ExpiredVotesThreshold`, without waiting for the timeout.
+func TestExpiredVotesThresholdIncreaseRound(t *testing.T) {
+	run := func(expiredVotesThreshold uint64) (expired []ConsensusEvent) {
+		nr, nodes, _ := createNodeRunnerForTesting(5, common.NewConfig(), nil)
+		nr.Consensus().Conf.ExpiredVotesThreshold = expiredVotesThreshold
+
+		nr.ConsensusEvents().Subscribe(func(event ConsensusEvent) {
+			if event.Type == ConsensusEventRoundExpired {
+				expired = append(expired, event)
+			}
+		})
+
+		b := nr.Consensus().LatestBlock()
+		basis := voting.Basis{
+			Height:    b.Height,
+			BlockHash: b.Hash,
+			TotalTxs:  b.TotalTxs,
+			TotalOps:  b.TotalOps,
+		}
+
+		// 5 validators and the threshold is 4
+		require.NoError(t, ReceiveBallot(nr, generateExpiredBallot(nr.localNode, basis, ballot.StateACCEPT, nodes[1])))
+		require.Equal(t, 0, len(expired))
+
+		err := ReceiveBallot(nr, generateExpiredBallot(nr.localNode, basis, ballot.StateACCEPT, nodes[2]))
+		if len(expired) > 0 {
+			_, ok := err.(CheckerStopCloseConsensus)
+			require.True(t, ok)
+		} else {
+			require.NoError(t, err)
+		}
+
+		return
+	}
+
+	// disabled; 2 EXP votes can not finish the voting
+	require.Equal(t, 0, len(run(0)))
+
+	// raised to `validators - threshold + 1`, 2
+	require.Equal(t, 1, len(run(1)))
+
+	{
+		expired := run(2)
+		require.Equal(t, 1, len(expired))
+		require.Equal(t, uint64(0), expired[0].Round)
+	}
+
+	require.Equal(t, 0, len(run(3)))
+}
@@ -21,7 +21,10 @@ func finishBallot(st *storage.LevelDBBackend, b ballot.Ballot, transactionPool *
 	transactionCache := NewTransactionCache(st, transactionPool)
 
 	var nOps int
-	for _, hash := range b.B.Proposed.Transactions {
+	pTxHashes := b.B.Proposed.Transactions
+	proposedTransactions := make([]*transaction.Transaction, 0, len(pTxHashes))
+	operationTransactions := make([]transaction.Transaction, 0, len(pTxHashes)+1)
+	for _, hash := range pTxHashes {
 		tx, found, err := transactionCache.Get(hash)
 		if err != nil {
 			return nil, err
@@ -30,7 +33,10 @@ func finishBallot(st *storage.LevelDBBackend, b ballot.Ballot, transactionPool *
 			return nil, errors.TransactionNotFound
 		}
 		nOps += len(tx.B.Operations)
+		proposedTransactions = append(proposedTransactions, &tx)
+		operationTransactions = append(operationTransactions, tx)
 	}
+	operationTransactions = append(operationTransactions, b.ProposerTransaction().Transaction)
 
 	r := b.VotingBasis()
 	r.Height++                                      // next block
@@ -42,6 +48,7 @@ func finishBallot(st *storage.LevelDBBackend, b ballot.Ballot, transactionPool *
 		r,
 		b.ProposerTransaction().GetHash(),
 		b.Transactions(),
+		block.GetBlockOperationHashes(operationTransactions...),
 		b.ProposerConfirmed(),
 	)
 
@@ -60,20 +67,6 @@ func finishBallot(st *storage.LevelDBBackend, b ballot.Ballot, transactionPool *
 		"proposer", blk.Proposer,
 	)
 
-	pTxHashes := b.B.Proposed.Transactions
-	proposedTransactions := make([]*transaction.Transaction, 0, len(pTxHashes))
-	for _, hash := range pTxHashes {
-		tx, found, err := transactionCache.Get(hash)
-		if err != nil {
-			return nil, err
-		}
-		if !found {
-			err = errors.TransactionNotFound
-			return nil, err
-		}
-		proposedTransactions = append(proposedTransactions, &tx)
-	}
-
 	if err = FinishTransactions(*blk, proposedTransactions, st); err != nil {
 		return nil, err
 	}
@@ -97,6 +90,10 @@ func FinishTransactions(blk block.Block, transactions []*transaction.Transaction
 				log.Error("failed to finish operation", "block", blk, "bt", bt, "op", op, "error", err)
 				return err
 			}
+			if err = finishGovernance(st, blk, *tx, op); err != nil {
+				log.Error("failed to finish governance operation", "block", blk, "bt", bt, "op", op, "error", err)
+				return err
+			}
 		}
 
 		var baSource *block.BlockAccount
@@ -109,11 +106,28 @@ func FinishTransactions(blk block.Block, transactions []*transaction.Transaction
 			return
 		}
 
+		// the rest of balance is merged after the fee and the other
+		// operations are paid
+		if pop, ok := tx.AccountMerge(); ok {
+			if err = finishAccountMerge(st, baSource, pop, log); err != nil {
+				return
+			}
+		}
+
 		if err = baSource.Save(st); err != nil {
 			return
 		}
 	}
 
+	// the proposals are tallied after the votes of the close height are stored
+	var proposals []block.GovernanceProposal
+	if proposals, err = block.TallyGovernanceProposals(st, blk.Height); err != nil {
+		return
+	}
+	for _, p := range proposals {
+		log.Debug("governance proposal closed", "proposal", p.Hash, "passed", p.Passed, "yes", p.Yes, "no", p.No, "abstain", p.Abstain)
+	}
+
 	return
 }
 
@@ -141,12 +155,76 @@ func finishOperation(st *storage.LevelDBBackend, source string, op operation.Ope
 			return errors.UnknownOperationType
 		}
 		return finishUnfreezeRequest(st, source, pop, log)
+	case operation.TypeSetSigners:
+		pop, ok := op.B.(operation.SetSigners)
+		if !ok {
+			return errors.UnknownOperationType
+		}
+		return finishSetSigners(st, source, pop, log)
+	case operation.TypeManageData:
+		pop, ok := op.B.(operation.ManageData)
+		if !ok {
+			return errors.UnknownOperationType
+		}
+		return finishManageData(st, source, pop, log)
+	case operation.TypeValidatorSetChange:
+		pop, ok := op.B.(operation.ValidatorSetChange)
+		if !ok {
+			return errors.UnknownOperationType
+		}
+		return finishValidatorSetChange(st, source, pop, log)
+	case operation.TypeTimeLockedPayment:
+		pop, ok := op.B.(operation.TimeLockedPayment)
+		if !ok {
+			return errors.UnknownOperationType
+		}
+		return finishTimeLockedPayment(st, source, pop, log)
+	case operation.TypeTimeLockClaim:
+		pop, ok := op.B.(operation.TimeLockClaim)
+		if !ok {
+			return errors.UnknownOperationType
+		}
+		return finishTimeLockClaim(st, source, pop, log)
+	case operation.TypeAccountMerge:
+		// merged by `FinishTransactions()` after withdrawing the source
+		return
+	case operation.TypeGovernanceProposal, operation.TypeGovernanceVote:
+		// stored by `finishGovernance()` with the transaction
+		return
 	default:
 		err = errors.UnknownOperationType
 		return
 	}
 }
 
+// finishGovernance stores the proposal and the vote; the proposal is referred
+// by the hash of its `block.BlockOperation`, so it needs the transaction.
+func finishGovernance(st *storage.LevelDBBackend, blk block.Block, tx transaction.Transaction, op operation.Operation) (err error) {
+	switch op.H.Type {
+	case operation.TypeGovernanceProposal:
+		pop, ok := op.B.(operation.GovernanceProposal)
+		if !ok {
+			return errors.UnknownOperationType
+		}
+		hash := block.NewBlockOperationKey(op.MakeHashString(), tx.GetHash())
+		return block.NewGovernanceProposal(hash, tx.B.Source, pop, blk.Height).Save(st)
+	case operation.TypeGovernanceVote:
+		pop, ok := op.B.(operation.GovernanceVote)
+		if !ok {
+			return errors.UnknownOperationType
+		}
+		v := block.GovernanceVote{
+			Proposal: pop.Proposal,
+			Voter:    tx.B.Source,
+			Choice:   pop.Choice,
+			Height:   blk.Height,
+		}
+		return v.Save(st)
+	}
+
+	return
+}
+
 func finishCreateAccount(st *storage.LevelDBBackend, source string, op operation.CreateAccount, log logging.Logger) (err error) {
 	if _, err = block.GetBlockAccount(st, source); err != nil {
 		err = errors.BlockAccountDoesNotExists
@@ -272,3 +350,82 @@ func finishInflation(st *storage.LevelDBBackend, opb operation.Inflation, log lo
 func finishUnfreezeRequest(st *storage.LevelDBBackend, source string, opb operation.UnfreezeRequest, log logging.Logger) (err error) {
 	return
 }
+
+func finishSetSigners(st *storage.LevelDBBackend, source string, opb operation.SetSigners, log logging.Logger) (err error) {
+	var baSource *block.BlockAccount
+	if baSource, err = block.GetBlockAccount(st, source); err != nil {
+		err = errors.BlockAccountDoesNotExists
+		return
+	}
+
+	baSource.Threshold = opb.Threshold
+	baSource.Signers = opb.Signers
+	if err = baSource.Save(st); err != nil {
+		return
+	}
+
+	return
+}
+
+func finishManageData(st *storage.LevelDBBackend, source string, opb operation.ManageData, log logging.Logger) (err error) {
+	return block.SaveAccountData(st, source, opb.Name, opb.Value)
+}
+
+// finishValidatorSetChange stores the change; it is applied to the validator
+// set by `NodeRunner.ApplyValidatorChanges()` after the block of its height.
+func finishValidatorSetChange(st *storage.LevelDBBackend, source string, opb operation.ValidatorSetChange, log logging.Logger) (err error) {
+	return block.BlockValidatorChange{
+		Height:   opb.Height,
+		Address:  opb.Address,
+		Endpoint: opb.Endpoint,
+		Remove:   opb.Remove,
+	}.Save(st)
+}
+
+// finishTimeLockedPayment locks the amount for the target; the amount is
+// withdrawn from the source with the other `operation.Payable`s.
+func finishTimeLockedPayment(st *storage.LevelDBBackend, source string, opb operation.TimeLockedPayment, log logging.Logger) (err error) {
+	_, err = block.AddBlockTimeLock(st, opb.Target, source, opb.UnlockHeight, opb.Amount)
+	return
+}
+
+// finishTimeLockClaim deposits the amount of the time lock to the claiming
+// source and removes the lock.
+func finishTimeLockClaim(st *storage.LevelDBBackend, source string, opb operation.TimeLockClaim, log logging.Logger) (err error) {
+	var lock block.BlockTimeLock
+	if lock, err = block.GetBlockTimeLock(st, source, opb.Source, opb.UnlockHeight); err != nil {
+		err = errors.TimeLockDoesNotExist
+		return
+	}
+
+	var baSource *block.BlockAccount
+	if baSource, err = block.GetBlockAccount(st, source); err != nil {
+		err = errors.BlockAccountDoesNotExists
+		return
+	}
+
+	if err = baSource.Deposit(lock.Amount); err != nil {
+		return
+	}
+	if err = baSource.Save(st); err != nil {
+		return
+	}
+
+	return lock.Remove(st)
+}
+
+// finishAccountMerge deposits the whole balance of the source to the target
+// and closes the source; the source is saved by the caller.
+func finishAccountMerge(st *storage.LevelDBBackend, baSource *block.BlockAccount, opb operation.AccountMerge, log logging.Logger) (err error) {
+	var baTarget *block.BlockAccount
+	if baTarget, err = block.GetBlockAccount(st, opb.Target); err != nil {
+		err = errors.BlockAccountDoesNotExists
+		return
+	}
+
+	if _, err = block.MergeBlockAccount(st, baSource, baTarget); err != nil {
+		return
+	}
+
+	return baTarget.Save(st)
+}
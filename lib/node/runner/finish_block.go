@@ -0,0 +1,307 @@
+package runner
+
+import (
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// VerifyBlock checks the block received from the other node, like by the sync
+// or the block stream, before it is stored by `FinishBlock()`:
+//  * the block is the next of `prevBlk`
+//  * the block is of `block.BlockVersion` above the legacy height; see
+//    `block.GetBlockLegacyHeight()`
+//  * the proposer is the known validator; see `isKnownProposer()`
+//  * the proposer transaction and the transactions are of the block, they are
+//    well-formed and the transactions are valid by `ValidateTx()`
+//  * `CollectTxFee` and `Inflation` of the proposer transaction are same with
+//    the ones derived from the transactions, like
+//    `BallotTransactionsProposerTransaction()` does in consensus
+//  * the hash of block is same with the one made from them
+func VerifyBlock(
+	st *storage.LevelDBBackend,
+	networkID []byte,
+	conf common.Config,
+	localNode *node.LocalNode,
+	prevBlk block.Block,
+	blk block.Block,
+	ptx *ballot.ProposerTransaction,
+	txs []*transaction.Transaction,
+) (err error) {
+	if blk.Height != prevBlk.Height+1 || blk.PrevBlockHash != prevBlk.Hash {
+		return errors.WrongBlockFound
+	}
+
+	if blk.Version < block.BlockVersion {
+		var legacyHeight uint64
+		if legacyHeight, err = block.GetBlockLegacyHeight(st); err != nil {
+			return
+		} else if blk.Height > legacyHeight {
+			return errors.WrongBlockFound.Clone().SetData("version", blk.Version)
+		}
+	}
+
+	var known bool
+	if known, err = isKnownProposer(st, localNode, blk); err != nil {
+		return
+	} else if !known {
+		return errors.WrongBlockFound.Clone().SetData("proposer", blk.Proposer)
+	}
+
+	if ptx == nil || ptx.GetHash() != blk.ProposerTransaction || ptx.Source() != blk.Proposer {
+		return errors.InvalidProposerTransaction
+	}
+	if ptx.B.MakeHashString() != ptx.H.Hash {
+		return errors.HashDoesNotMatch
+	}
+	if err = ptx.IsWellFormed(networkID, conf); err != nil {
+		return
+	}
+
+	if len(txs) != len(blk.Transactions) {
+		return errors.TransactionNotFound
+	}
+	for i, tx := range txs {
+		if tx.GetHash() != blk.Transactions[i] || tx.B.MakeHashString() != tx.H.Hash {
+			return errors.HashDoesNotMatch
+		}
+		if err = tx.IsWellFormed(networkID, conf); err != nil {
+			return
+		}
+		if err = ValidateTx(st, *tx); err != nil {
+			return
+		}
+	}
+
+	nOps := len(ptx.B.Operations)
+	for _, tx := range txs {
+		nOps += len(tx.B.Operations)
+	}
+	if blk.TotalTxs != prevBlk.TotalTxs+uint64(len(txs)+1) || blk.TotalOps != prevBlk.TotalOps+uint64(nOps) {
+		return errors.WrongBlockFound
+	}
+
+	if err = verifyBlockProposerTransaction(st, networkID, conf, prevBlk, blk, *ptx, txs); err != nil {
+		return
+	}
+
+	operationTxs := make([]transaction.Transaction, 0, len(txs)+1)
+	for _, tx := range txs {
+		operationTxs = append(operationTxs, *tx)
+	}
+	operationTxs = append(operationTxs, ptx.Transaction)
+
+	r := voting.Basis{
+		Round:     blk.Round,
+		Height:    blk.Height,
+		BlockHash: prevBlk.Hash,
+		TotalTxs:  blk.TotalTxs,
+		TotalOps:  blk.TotalOps,
+	}
+	expected := block.NewBlockWithVersion(blk.Version, blk.Proposer, r, blk.ProposerTransaction, blk.Transactions, block.GetBlockOperationHashes(operationTxs...), blk.Confirmed)
+	if expected.Hash != blk.Hash {
+		return errors.HashDoesNotMatch
+	}
+
+	return
+}
+
+// isKnownProposer checks the proposer of block is one of the validators of
+// local node or the validators changed by the validator set changes before
+// the block. The validator set of the past height can not be restored, so the
+// removed validator is still known for the blocks before.
+func isKnownProposer(st *storage.LevelDBBackend, localNode *node.LocalNode, blk block.Block) (bool, error) {
+	if localNode.HasValidators(blk.Proposer) {
+		return true, nil
+	}
+
+	changes, err := block.GetBlockValidatorChanges(st, common.GenesisBlockHeight, blk.Height-1)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range changes {
+		if c.Address == blk.Proposer {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// verifyBlockProposerTransaction derives `CollectTxFee` and `Inflation` from
+// the transactions and the previous block like the proposer does, so the
+// proposer transaction of the received block can not claim the different
+// fee or inflation.
+func verifyBlockProposerTransaction(
+	st *storage.LevelDBBackend,
+	networkID []byte,
+	conf common.Config,
+	prevBlk block.Block,
+	blk block.Block,
+	ptx ballot.ProposerTransaction,
+	txs []*transaction.Transaction,
+) (err error) {
+	var params common.NetworkParams
+	if params, err = LoadNetworkParams(st, networkID, conf.NetworkParams); err != nil {
+		return
+	}
+
+	basis := voting.Basis{
+		Round:     blk.Round,
+		Height:    prevBlk.Height,
+		BlockHash: prevBlk.Hash,
+		TotalTxs:  prevBlk.TotalTxs,
+		TotalOps:  prevBlk.TotalOps,
+	}
+	blt := ballot.NewBallot(blk.Proposer, blk.Proposer, basis, blk.Transactions)
+
+	feeTxs := make([]transaction.Transaction, len(txs))
+	for i, tx := range txs {
+		feeTxs[i] = *tx
+	}
+
+	var expectedCollectTxFee operation.CollectTxFee
+	if expectedCollectTxFee, err = ballot.NewCollectTxFeeFromBallotWithPolicy(*blt, params.CommonAccount, conf.FeeBurnPolicy, feeTxs...); err != nil {
+		return
+	}
+	var expectedInflation operation.Inflation
+	expectedInflation, err = ballot.NewInflationFromBallotWithPolicy(
+		*blt,
+		params.CommonAccount,
+		params.InitialBalance,
+		conf.InflationSchedule,
+		conf.InflationPolicy,
+	)
+	if err != nil {
+		return
+	}
+
+	var opc operation.CollectTxFee
+	if opc, err = ptx.CollectTxFee(); err != nil {
+		return
+	} else if opc != expectedCollectTxFee {
+		return errors.InvalidProposerTransaction
+	}
+
+	var opi operation.Inflation
+	if opi, err = ptx.Inflation(); err != nil {
+		return
+	} else if opi != expectedInflation {
+		return errors.InvalidProposerTransaction
+	}
+
+	return
+}
+
+// FinishBlock stores the block verified by `VerifyBlock()` with it's
+// transactions and proposer transaction at once, and runs the same steps after
+// the commit as the consensus does; see `finishedBlock()`. If the block is
+// already stored, it returns `errors.BlockAlreadyExists`.
+func (nr *NodeRunner) FinishBlock(
+	blk block.Block,
+	ptx ballot.ProposerTransaction,
+	txs []*transaction.Transaction,
+	log logging.Logger,
+) (err error) {
+	var bs *storage.LevelDBBackend
+	if bs, err = nr.storage.OpenBatch(); err != nil {
+		return
+	}
+
+	if err = blk.Save(bs); err != nil {
+		bs.Discard()
+		return
+	}
+	if err = FinishTransactions(blk, txs, bs); err != nil {
+		bs.Discard()
+		return
+	}
+	for _, tx := range txs {
+		if _, err = block.SaveTransactionPool(bs, *tx); err != nil {
+			bs.Discard()
+			return
+		}
+	}
+	if err = FinishProposerTransaction(bs, blk, ptx, log); err != nil {
+		bs.Discard()
+		return
+	}
+	if err = nr.commitBlock(bs); err != nil {
+		return
+	}
+
+	nr.finishedBlock(blk, log)
+
+	return
+}
+
+// commitBlock commits the batch of the confirmed block; it must be durable
+// before moving to the next height, see `common.Config.SyncOnCommit`.
+func (nr *NodeRunner) commitBlock(bs *storage.LevelDBBackend) (err error) {
+	commit := bs.Commit
+	if nr.Config().SyncOnCommit {
+		commit = bs.CommitSync
+	}
+	if err = commit(); err != nil {
+		if err != errors.NotCommittable {
+			bs.Discard()
+			return
+		}
+		err = nil
+	}
+
+	return
+}
+
+// finishedBlock runs after the block is committed, whether it is confirmed by
+// the consensus or received by the sync or the block stream; the events are
+// emitted, the validator set changes are applied, the held transactions are
+// released and the `BlockOperation`s are saved and the old blocks are pruned.
+func (nr *NodeRunner) finishedBlock(blk block.Block, log logging.Logger) {
+	nr.ConsensusEvents().Emit(ConsensusEvent{
+		Type:      ConsensusEventHeightAdvanced,
+		Height:    blk.Height,
+		Round:     blk.Round,
+		Proposer:  blk.Proposer,
+		BlockHash: blk.Hash,
+	})
+	nr.notifyFinalizedBlock(blk)
+	if err := SaveBlockAccountCheckpoint(nr.Storage(), blk, nr.Config().AccountCheckpointInterval, log); err != nil {
+		log.Error("failed to start account checkpoint", "block", blk, "error", err)
+	}
+	if err := nr.ApplyValidatorChanges(); err != nil {
+		log.Error("failed to apply validator set changes", "block", blk, "error", err)
+	}
+	nr.releaseHeldTransactions(blk, log)
+	nr.SavingBlockOperations().Save(blk)
+	nr.BallotSignatureCache().EvictLowerOrEqual(blk.Height)
+	nr.BallotSeenSet().EvictLowerOrEqual(blk.Height)
+}
+
+// releaseHeldTransactions releases the held transactions in `Pool`, which
+// have the next sequenceID of the source account after the block; the
+// transactions of the block are removed from `Pool` first, so the released
+// ones can be proposed in the next block.
+func (nr *NodeRunner) releaseHeldTransactions(blk block.Block, log logging.Logger) {
+	if nr.TransactionPool.HeldLen() < 1 {
+		return
+	}
+
+	nr.TransactionPool.Remove(blk.Transactions...)
+
+	released := nr.TransactionPool.Release(func(source string) (uint64, bool) {
+		sequenceID, err := block.GetNextSequenceID(nr.Storage(), source)
+		return sequenceID, err == nil
+	})
+	for _, tx := range released {
+		log.Debug("held transaction is released", "transaction", tx.GetHash(), "sequenceID", tx.B.SequenceID)
+	}
+}
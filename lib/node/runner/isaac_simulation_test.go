@@ -6,12 +6,14 @@
 package runner
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"boscoin.io/sebak/lib/ballot"
 	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/voting"
 )
 
@@ -35,7 +37,7 @@ func TestISAACSimulationProposer(t *testing.T) {
 
 	// Generate proposed ballot in nr
 	roundNumber := uint64(0)
-	_, err = nr.proposeNewBallot(roundNumber)
+	_, err = nr.proposeNewBallot(context.Background(), roundNumber)
 	require.NoError(t, err)
 
 	b := nr.Consensus().LatestBlock()
@@ -46,6 +48,7 @@ func TestISAACSimulationProposer(t *testing.T) {
 		TotalTxs:  b.TotalTxs,
 	}
 	require.True(t, nr.TransactionPool.Has(tx.GetHash()))
+	require.Equal(t, 1, len(nr.TransactionPool.List(tx.Source(), "", 0)))
 
 	conf := common.NewConfig()
 
@@ -92,4 +95,53 @@ func TestISAACSimulationProposer(t *testing.T) {
 	require.Equal(t, proposer.Address(), block.Proposer)
 	require.Equal(t, 1, len(block.Transactions))
 	require.Equal(t, tx.GetHash(), block.Transactions[0])
+
+	// the confirmed transaction is no longer listed in the pool
+	require.Equal(t, 0, len(nr.TransactionPool.List(tx.Source(), "", 0)))
+}
+
+/*
+TestISAACSimulationDuplicatedBallots indicates the following:
+	1. The node is the proposer of this round.
+	2. The node receives the same SIGN ballot from the other validators several times, like gossip.
+	3. Only the first one is verified and voted; the others are dropped.
+*/
+func TestISAACSimulationDuplicatedBallots(t *testing.T) {
+	nr, nodes, _ := createNodeRunnerForTesting(5, common.NewConfig(), nil)
+	tx, _ := GetTransaction()
+
+	proposer := nr.localNode
+	nr.TransactionPool.Add(tx)
+
+	roundNumber := uint64(0)
+	_, err := nr.proposeNewBallot(context.Background(), roundNumber)
+	require.NoError(t, err)
+
+	b := nr.Consensus().LatestBlock()
+	round := voting.Basis{
+		Round:     roundNumber,
+		Height:    b.Height,
+		BlockHash: b.Hash,
+		TotalTxs:  b.TotalTxs,
+	}
+
+	conf := common.NewConfig()
+
+	ballotSIGN1 := GenerateBallot(proposer, round, tx, ballot.StateSIGN, nodes[1], conf)
+	require.NoError(t, ReceiveBallot(nr, ballotSIGN1))
+
+	for i := 0; i < 3; i++ {
+		err = ReceiveBallot(nr, ballotSIGN1)
+		require.Equal(t, errors.BallotAlreadyReceived, err)
+	}
+
+	require.True(t, nr.BallotSeenSet().Seen(*ballotSIGN1))
+
+	rr := nr.Consensus().RunningRounds[round.Index()]
+	require.Equal(t, 1, len(rr.Voted[proposer.Address()].GetResult(ballot.StateSIGN)))
+
+	// the ballot of the other node is not dropped
+	ballotSIGN2 := GenerateBallot(proposer, round, tx, ballot.StateSIGN, nodes[2], conf)
+	require.NoError(t, ReceiveBallot(nr, ballotSIGN2))
+	require.Equal(t, 2, len(rr.Voted[proposer.Address()].GetResult(ballot.StateSIGN)))
 }
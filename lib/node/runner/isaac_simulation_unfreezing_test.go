@@ -6,6 +6,7 @@
 package runner
 
 import (
+	"context"
 	"testing"
 
 	"boscoin.io/sebak/lib/ballot"
@@ -75,7 +76,7 @@ func TestUnfreezingSimulation(t *testing.T) {
 
 	nr.TransactionPool.Add(tx6)
 	roundNumber := uint64(0)
-	_, err := nr.proposeNewBallot(roundNumber)
+	_, err := nr.proposeNewBallot(context.Background(), roundNumber)
 	require.NoError(t, err)
 
 	require.False(t, nr.TransactionPool.Has(tx6.GetHash()))
@@ -115,7 +116,7 @@ func MakeConsensusAndBlock(t *testing.T, tx transaction.Transaction, nr *NodeRun
 
 	// Generate proposed ballot in nodeRunner
 	round := uint64(0)
-	_, err := nr.proposeNewBallot(round)
+	_, err := nr.proposeNewBallot(context.Background(), round)
 	require.NoError(t, err)
 
 	b := nr.Consensus().LatestBlock()
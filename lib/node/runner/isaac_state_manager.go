@@ -1,6 +1,10 @@
 package runner
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -8,9 +12,64 @@ import (
 	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/voting"
 )
 
+// ISAACStateManagerStopTimeout is the maximum time for `Stop()` to wait for
+// the loop of `Start()` to return.
+var ISAACStateManagerStopTimeout = 5 * time.Second
+
+// StopReason is the cause of stopping the loop of `ISAACStateManager.Start()`.
+type StopReason string
+
+const (
+	// StopReasonNone means the ISAACStateManager is not stopped.
+	StopReasonNone StopReason = ""
+
+	// StopReasonManual is stopped by `ISAACStateManager.Stop()`.
+	StopReasonManual StopReason = "manual"
+
+	// StopReasonProposalFailures is stopped by the consecutive failures of
+	// proposing ballot; see `common.Config.MaxProposalFailures`.
+	StopReasonProposalFailures StopReason = "proposal-failures"
+
+	// StopReasonStorageError is stopped by the storage error in proposing
+	// ballot; the next proposals will fail too.
+	StopReasonStorageError StopReason = "storage-error"
+)
+
+// Clock is the source of the current time of the ISAACStateManager; the
+// tests replace it to make the time-based calculations deterministic. See
+// `ISAACStateManager.SetClock()`.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the default `Clock` by the local time.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// stateTimer is the timeout of the ballot state in the loop of `Start()`;
+// `*time.Timer` or `*stepTimer`.
+type stateTimer interface {
+	Reset(time.Duration) bool
+}
+
+// stepTimer is the timer of the step mode. It does not count the wall clock;
+// it only keeps the last timeout and it is fired by `Step()`.
+type stepTimer struct {
+	timeout time.Duration
+}
+
+func (t *stepTimer) Reset(d time.Duration) bool {
+	t.timeout = d
+	return true
+}
+
 // ISAACStateManager manages the ISAACState.
 // The most important function `Start()` is called in StartStateManager() function in node_runner.go by goroutine.
 type ISAACStateManager struct {
@@ -18,13 +77,25 @@ type ISAACStateManager struct {
 
 	nr              *NodeRunner
 	state           consensus.ISAACState
-	stateTransit    chan consensus.ISAACState
+	stateTransit    chan struct{}         // notifies the loop of `Start()` that `pending` is updated.
+	pending         *consensus.ISAACState // the latest requested ISAACState, not yet received by the loop.
 	stop            chan struct{}
+	done            chan struct{}              // closed when the loop of `Start()` returns.
 	blockTimeBuffer time.Duration              // the time to wait to adjust the block creation time.
 	transitSignal   func(consensus.ISAACState) // the function is called when the ISAACState is changed.
-	genesis         time.Time                  // the time at which the GenesisBlock was saved. It is used for calculating `blockTimeBuffer`.
-
-	Conf common.Config
+	genesis         time.Time                  // the time at which the GenesisBlock was saved or `Config.GenesisTime`. It is used for calculating `blockTimeBuffer`.
+	allConfirmed    time.Time                  // the local time of the last `ALLCONFIRM`.
+	metrics         *ISAACStateMetrics
+	stopReason      StopReason // the cause of the last stop; see `StopReason`.
+	lastError       error      // the last error of proposing ballot.
+	failures        uint64     // the number of the consecutive failures of proposing ballot.
+	stepMode        bool       // see `EnableStepMode()`.
+	stepTimer       *stepTimer // the timer of the running step mode.
+	clock           Clock
+	proposing       *consensus.ISAACState // the state of the in-flight proposal.
+	cancelProposal  context.CancelFunc    // cancels the in-flight proposal; see `startProposal()`.
+
+	conf common.Config
 }
 
 func NewISAACStateManager(nr *NodeRunner, conf common.Config) *ISAACStateManager {
@@ -35,51 +106,105 @@ func NewISAACStateManager(nr *NodeRunner, conf common.Config) *ISAACStateManager
 			Height:      0,
 			BallotState: ballot.StateINIT,
 		},
-		stateTransit:    make(chan consensus.ISAACState),
+		stateTransit:    make(chan struct{}, 1),
 		stop:            make(chan struct{}),
 		blockTimeBuffer: 2 * time.Second,
 		transitSignal:   func(consensus.ISAACState) {},
-		Conf:            conf,
+		metrics:         &ISAACStateMetrics{},
+		clock:           realClock{},
+		conf:            conf,
 	}
 
-	genesisBlock := block.GetGenesis(nr.storage)
-	p.genesis = genesisBlock.Header.Timestamp
+	if conf.GenesisTime.IsZero() {
+		genesisBlock := block.GetGenesis(nr.storage)
+		p.genesis = genesisBlock.Header.Timestamp
+	} else {
+		p.genesis = conf.GenesisTime
+	}
 
 	return p
 }
 
+// config returns the running config; see `NodeRunner.ReloadConfig()`.
+func (sm *ISAACStateManager) config() common.Config {
+	sm.RLock()
+	defer sm.RUnlock()
+	return sm.conf
+}
+
+func (sm *ISAACStateManager) setConfig(conf common.Config) {
+	sm.Lock()
+	defer sm.Unlock()
+	sm.conf = conf
+}
+
+// SetClock replaces the `Clock` of the ISAACStateManager.
+func (sm *ISAACStateManager) SetClock(clock Clock) {
+	sm.Lock()
+	defer sm.Unlock()
+	sm.clock = clock
+}
+
+func (sm *ISAACStateManager) now() time.Time {
+	sm.RLock()
+	defer sm.RUnlock()
+	return sm.clock.Now()
+}
+
 func (sm *ISAACStateManager) SetBlockTimeBuffer() {
 	sm.nr.Log().Debug("begin ISAACStateManager.SetBlockTimeBuffer()", "ISAACState", sm.State())
 	b := sm.nr.Consensus().LatestBlock()
+	now := sm.now()
 	ballotProposedTime := getBallotProposedTime(b.Confirmed)
-	sm.blockTimeBuffer = calculateBlockTimeBuffer(
-		sm.Conf.BlockTime,
-		calculateAverageBlockTime(sm.genesis, b.Height),
-		time.Now().Sub(ballotProposedTime),
+	untilNow := now.Sub(ballotProposedTime)
+	if untilNow < 0 {
+		sm.nr.Log().Warn(
+			"proposed time of latest block is ahead of local time",
+			"skew", -untilNow,
+			"confirmed", b.Confirmed,
+			"height", b.Height,
+		)
+	}
+	blockTime := sm.config().BlockTime
+	buffer := calculateBlockTimeBuffer(
+		blockTime,
+		calculateAverageBlockTime(sm.genesis, now, b.Height),
+		untilNow,
 		1*time.Second,
 	)
+	sm.Lock()
+	sm.blockTimeBuffer = buffer
+	sm.Unlock()
+
 	sm.nr.Log().Debug(
 		"calculated blockTimeBuffer",
-		"blockTimeBuffer", sm.blockTimeBuffer,
-		"blockTime", sm.Conf.BlockTime,
+		"blockTimeBuffer", buffer,
+		"blockTime", blockTime,
 		"genesis", sm.genesis,
 		"height", b.Height,
 		"confirmed", b.Confirmed,
-		"now", time.Now(),
+		"now", now,
 	)
 
 	return
 }
 
+// BlockTimeBuffer returns the current `blockTimeBuffer`.
+func (sm *ISAACStateManager) BlockTimeBuffer() time.Duration {
+	sm.RLock()
+	defer sm.RUnlock()
+	return sm.blockTimeBuffer
+}
+
 func getBallotProposedTime(timeStr string) time.Time {
 	ballotProposedTime, _ := common.ParseISO8601(timeStr)
 	return ballotProposedTime
 }
 
-func calculateAverageBlockTime(genesis time.Time, blockHeight uint64) time.Duration {
+func calculateAverageBlockTime(genesis, now time.Time, blockHeight uint64) time.Duration {
 	genesisBlockHeight := uint64(1)
 	height := blockHeight - genesisBlockHeight
-	sinceGenesis := time.Now().Sub(genesis)
+	sinceGenesis := now.Sub(genesis)
 
 	if height == 0 {
 		return sinceGenesis
@@ -88,9 +213,17 @@ func calculateAverageBlockTime(genesis time.Time, blockHeight uint64) time.Durat
 	}
 }
 
+// calculateBlockTimeBuffer returns the time to wait before proposing the
+// next ballot. `untilNow` can be negative when the clock of proposer is ahead
+// of the local clock; it is treated as zero, so the buffer is at most
+// `goal + delta`.
 func calculateBlockTimeBuffer(goal, average, untilNow, delta time.Duration) time.Duration {
 	var blockTimeBuffer time.Duration
 
+	if untilNow < 0 {
+		untilNow = 0
+	}
+
 	epsilon := 50 * time.Millisecond
 	if average >= goal {
 		if average-goal < epsilon {
@@ -111,33 +244,114 @@ func calculateBlockTimeBuffer(goal, average, untilNow, delta time.Duration) time
 	return blockTimeBuffer
 }
 
+// proposerJitter returns the jitter of the proposer for the block after the
+// latest block.
+func (sm *ISAACStateManager) proposerJitter(proposer string) time.Duration {
+	return calculateProposerJitter(sm.nr.Consensus().LatestBlock().Hash, proposer, sm.config().ProposerJitter)
+}
+
+// calculateProposerJitter returns the jitter in [0, max), which the proposer
+// waits in addition to `blockTimeBuffer`. The random source is seeded by the
+// block hash and the proposer address, so the jitter of the proposer is same
+// in every node, but differs by block and by proposer.
+func calculateProposerJitter(blockHash, proposer string, max time.Duration) time.Duration {
+	if max < 1 {
+		return 0
+	}
+
+	seed := sha256.Sum256([]byte(blockHash + proposer))
+	r := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(seed[:8]))))
+
+	return time.Duration(r.Int63n(int64(max)))
+}
+
 func (sm *ISAACStateManager) SetTransitSignal(f func(consensus.ISAACState)) {
 	sm.Lock()
 	defer sm.Unlock()
 	sm.transitSignal = f
 }
 
+// TransitISAACState requests the loop of `Start()` to transit to the given
+// ISAACState. The requests are coalesced; if the loop is busy, only the
+// latest one is delivered, so the loop ends at the highest requested state.
 func (sm *ISAACStateManager) TransitISAACState(height uint64, round uint64, ballotState ballot.State) {
-	sm.RLock()
-	current := sm.state
-	sm.RUnlock()
-
 	target := consensus.ISAACState{
 		Height:      height,
 		Round:       round,
 		BallotState: ballotState,
 	}
 
-	if current.IsLater(target) {
-		go func() {
-			sm.stateTransit <- target
-		}()
+	sm.Lock()
+	defer sm.Unlock()
+
+	select {
+	case <-sm.stop: // already stopped, nobody will receive it.
+		return
+	default:
+	}
+
+	if !sm.state.IsLater(target) {
+		return
+	}
+	if sm.pending != nil && !sm.pending.IsLater(target) {
+		return
+	}
+	if sm.proposing != nil && sm.proposing.IsLater(target) {
+		sm.cancelProposal()
+	}
+	sm.metrics.requestTransit(sm.pending != nil)
+	sm.pending = &target
+
+	select {
+	case sm.stateTransit <- struct{}{}:
+	default: // the loop is already notified
 	}
 }
 
+// takePendingState returns the pending ISAACState and clears it.
+func (sm *ISAACStateManager) takePendingState() (state consensus.ISAACState, found bool) {
+	sm.Lock()
+	defer sm.Unlock()
+
+	if sm.pending == nil {
+		return
+	}
+	state, found = *sm.pending, true
+	sm.pending = nil
+	sm.metrics.clearTransitPending()
+
+	return
+}
+
+// Metrics returns the counters of timeouts and round changes.
+func (sm *ISAACStateManager) Metrics() *ISAACStateMetrics {
+	return sm.metrics
+}
+
 func (sm *ISAACStateManager) IncreaseRound() {
+	sm.increaseRound(sm.State())
+}
+
+// IncreaseRoundOf increases the round, which is expired by the voting result;
+// if the node already moved to the next round by timeout, it is ignored, so
+// the round is not increased twice.
+func (sm *ISAACStateManager) IncreaseRoundOf(height, round uint64) {
 	state := sm.State()
-	sm.nr.Log().Debug("begin ISAACStateManager.IncreaseRound()", "height", state.Height, "round", state.Round, "state", state.BallotState)
+	if state.Height > height || (state.Height == height && state.Round > round) {
+		return
+	}
+
+	sm.increaseRound(consensus.ISAACState{Height: height, Round: round})
+}
+
+func (sm *ISAACStateManager) increaseRound(state consensus.ISAACState) {
+	sm.metrics.increaseRoundIncreases()
+	sm.nr.ConsensusEvents().Emit(ConsensusEvent{
+		Type:     ConsensusEventRoundExpired,
+		Height:   state.Height,
+		Round:    state.Round,
+		Proposer: sm.nr.Consensus().SelectProposer(state.Height, state.Round),
+	})
 	sm.TransitISAACState(state.Height, state.Round+1, ballot.StateINIT)
 }
 
@@ -153,50 +367,150 @@ func (sm *ISAACStateManager) NextHeight() {
 func (sm *ISAACStateManager) Start() {
 	sm.nr.localNode.SetConsensus()
 	sm.nr.Log().Debug("begin ISAACStateManager.Start()", "ISAACState", sm.State())
+
+	sm.Lock()
+	if sm.done != nil {
+		sm.Unlock()
+		return
+	}
+	select {
+	case <-sm.stop: // stopped before, so starts again with new one
+		sm.stop = make(chan struct{})
+		sm.stopReason = StopReasonNone
+		sm.failures = 0
+	default:
+	}
+	stop := sm.stop
+	done := make(chan struct{})
+	sm.done = done
+
+	if sm.stepMode {
+		// nothing runs in background, so `Stop()` does not need to wait.
+		sm.stepTimer = &stepTimer{timeout: time.Duration(1 * time.Hour)}
+		close(done)
+		sm.Unlock()
+		return
+	}
+	sm.Unlock()
+
 	go func() {
+		defer close(done)
+
 		timer := time.NewTimer(time.Duration(1 * time.Hour))
+		defer timer.Stop()
 		for {
+			// `stop` takes precedence over the other signals.
 			select {
-			case <-timer.C:
-				sm.nr.Log().Debug("timeout", "ISAACState", sm.State())
-				if sm.State().BallotState == ballot.StateACCEPT {
-					sm.SetBlockTimeBuffer()
-					sm.IncreaseRound()
-					break
-				}
-				go sm.broadcastExpiredBallot(sm.State())
-				sm.setBallotState(sm.State().BallotState.Next())
-				sm.resetTimer(timer, sm.State().BallotState)
-				sm.transitSignal(sm.State())
-
-			case state := <-sm.stateTransit:
-				switch state.BallotState {
-				case ballot.StateINIT:
-					sm.proposeOrWait(timer, state)
-				case ballot.StateSIGN:
-					sm.setState(state)
-					sm.transitSignal(state)
-					timer.Reset(sm.Conf.TimeoutSIGN)
-				case ballot.StateACCEPT:
-					sm.setState(state)
-					sm.transitSignal(state)
-					timer.Reset(sm.Conf.TimeoutACCEPT)
-				case ballot.StateALLCONFIRM:
-					sm.setState(state)
-					sm.transitSignal(state)
-					sm.SetBlockTimeBuffer()
-					sm.NextHeight()
-				}
+			case <-stop:
+				return
+			default:
+			}
 
-			case <-sm.stop:
+			select {
+			case <-timer.C:
+				sm.handleTimeout(timer)
+			case <-sm.stateTransit:
+				sm.handleStateTransit(timer)
+			case <-stop:
 				return
 			}
 		}
 	}()
 }
 
-func (sm *ISAACStateManager) broadcastExpiredBallot(state consensus.ISAACState) {
-	sm.nr.Log().Debug("begin broadcastExpiredBallot", "ISAACState", state)
+// EnableStepMode makes the ISAACStateManager to advance only by `Step()`
+// instead of the timers; it is for debugging the consensus in the tests, so
+// there is no config to enable it. It must be called before `Start()`.
+func (sm *ISAACStateManager) EnableStepMode() {
+	sm.Lock()
+	defer sm.Unlock()
+	sm.stepMode = true
+}
+
+// Step handles one event of the loop of `Start()` in the step mode; the
+// pending state transit if it exists, otherwise the timeout of the current
+// ballot state. Everything of the event, including the broadcast, is done
+// before it returns. It returns false when the step mode is not enabled or
+// the ISAACStateManager is not running.
+func (sm *ISAACStateManager) Step() bool {
+	sm.RLock()
+	timer := sm.stepTimer
+	running := sm.stepMode && sm.done != nil
+	sm.RUnlock()
+
+	if !running || timer == nil {
+		return false
+	}
+
+	select {
+	case <-sm.stateTransit:
+		sm.handleStateTransit(timer)
+	default:
+		sm.handleTimeout(timer)
+	}
+
+	return true
+}
+
+// StepTimeout returns the timeout of the current ballot state, which `Step()`
+// fires when no state transit is pending; it is zero if not in the step mode.
+func (sm *ISAACStateManager) StepTimeout() time.Duration {
+	sm.RLock()
+	defer sm.RUnlock()
+
+	if sm.stepTimer == nil {
+		return 0
+	}
+	return sm.stepTimer.timeout
+}
+
+func (sm *ISAACStateManager) isStepMode() bool {
+	sm.RLock()
+	defer sm.RUnlock()
+	return sm.stepMode
+}
+
+func (sm *ISAACStateManager) handleTimeout(timer stateTimer) {
+	sm.nr.Log().Debug("timeout", "ISAACState", sm.State())
+	sm.metrics.increaseTimeout(sm.State().BallotState)
+	if sm.State().BallotState == ballot.StateACCEPT {
+		sm.SetBlockTimeBuffer()
+		sm.IncreaseRound()
+		return
+	}
+	if sm.isStepMode() {
+		sm.broadcastExpiredBallot(sm.State(), ballot.ExpiredReasonTimeout)
+	} else {
+		go sm.broadcastExpiredBallot(sm.State(), ballot.ExpiredReasonTimeout)
+	}
+	sm.setBallotState(sm.State().BallotState.Next())
+	sm.resetTimer(timer, sm.State().BallotState)
+	sm.transitSignal(sm.State())
+}
+
+func (sm *ISAACStateManager) handleStateTransit(timer stateTimer) {
+	state, found := sm.takePendingState()
+	if !found {
+		return
+	}
+	switch state.BallotState {
+	case ballot.StateINIT:
+		sm.proposeOrWait(timer, state)
+	case ballot.StateSIGN, ballot.StateACCEPT:
+		sm.setState(state)
+		sm.transitSignal(state)
+		sm.resetTimer(timer, state.BallotState)
+	case ballot.StateALLCONFIRM:
+		sm.setState(state)
+		sm.setAllConfirmed(sm.now())
+		sm.transitSignal(state)
+		sm.SetBlockTimeBuffer()
+		sm.NextHeight()
+	}
+}
+
+func (sm *ISAACStateManager) broadcastExpiredBallot(state consensus.ISAACState, reason ballot.ExpiredReason) {
+	sm.nr.Log().Debug("begin broadcastExpiredBallot", "ISAACState", state, "reason", reason)
 	b := sm.nr.consensus.LatestBlock()
 	basis := voting.Basis{
 		Round:     state.Round,
@@ -210,53 +524,309 @@ func (sm *ISAACStateManager) broadcastExpiredBallot(state consensus.ISAACState)
 
 	newExpiredBallot := ballot.NewBallot(sm.nr.localNode.Address(), proposerAddr, basis, []string{})
 	newExpiredBallot.SetVote(state.BallotState.Next(), voting.EXP)
-
-	opc, _ := ballot.NewCollectTxFeeFromBallot(*newExpiredBallot, sm.nr.CommonAccountAddress)
-	opi, _ := ballot.NewInflationFromBallot(*newExpiredBallot, sm.nr.CommonAccountAddress, sm.nr.InitialBalance)
+	newExpiredBallot.SetExpiredReason(reason)
+
+	opc, _ := ballot.NewCollectTxFeeFromBallot(*newExpiredBallot, sm.nr.NetworkParams().CommonAccount)
+	conf := sm.nr.Config()
+	opi, _ := ballot.NewInflationFromBallotWithPolicy(
+		*newExpiredBallot,
+		sm.nr.NetworkParams().CommonAccount,
+		sm.nr.NetworkParams().InitialBalance,
+		conf.InflationSchedule,
+		conf.InflationPolicy,
+	)
 	ptx, _ := ballot.NewProposerTransactionFromBallot(*newExpiredBallot, opc, opi)
 
 	newExpiredBallot.SetProposerTransaction(ptx)
 	newExpiredBallot.SignByProposer(sm.nr.localNode.Keypair(), sm.nr.networkID)
 	newExpiredBallot.Sign(sm.nr.localNode.Keypair(), sm.nr.networkID)
 
-	sm.nr.Log().Debug("broadcast", "ballot", *newExpiredBallot)
+	sm.metrics.increaseExpiredBallots()
 	sm.nr.ConnectionManager().Broadcast(*newExpiredBallot)
+	sm.nr.ConsensusEvents().Emit(ConsensusEvent{
+		Type:        ConsensusEventVoteCast,
+		Height:      basis.Height,
+		Round:       basis.Round,
+		Proposer:    proposerAddr,
+		BallotState: state.BallotState.Next(),
+		Vote:        voting.EXP,
+		Reason:      string(reason),
+	})
 }
 
-func (sm *ISAACStateManager) resetTimer(timer *time.Timer, state ballot.State) {
+func (sm *ISAACStateManager) resetTimer(timer stateTimer, state ballot.State) {
+	conf := sm.config()
 	switch state {
 	case ballot.StateINIT:
-		timer.Reset(sm.Conf.TimeoutINIT)
+		timer.Reset(conf.TimeoutINIT)
 	case ballot.StateSIGN:
-		timer.Reset(sm.Conf.TimeoutSIGN)
+		timer.Reset(conf.TimeoutSIGN)
 	case ballot.StateACCEPT:
-		timer.Reset(sm.Conf.TimeoutACCEPT)
+		timer.Reset(conf.TimeoutACCEPT)
 	}
 }
 
 // In proposeOrWait,
 // if nr.localNode is proposer, it proposes new ballot,
 // but if not, it waits for receiving ballot from the other proposer.
-func (sm *ISAACStateManager) proposeOrWait(timer *time.Timer, state consensus.ISAACState) {
+func (sm *ISAACStateManager) proposeOrWait(timer stateTimer, state consensus.ISAACState) {
 	timer.Reset(time.Duration(1 * time.Hour))
 	proposer := sm.nr.Consensus().SelectProposer(state.Height, state.Round)
 	log.Debug("selected proposer", "proposer", proposer)
 
 	if proposer == sm.nr.localNode.Address() {
-		time.Sleep(sm.blockTimeBuffer)
-		if _, err := sm.nr.proposeNewBallot(state.Round); err == nil {
-			log.Debug("propose new ballot", "proposer", proposer, "round", state.Round, "ballotState", ballot.StateSIGN)
+		if !sm.waitBlockTimeBuffer(state) {
+			log.Debug("cancelled to propose new ballot", "proposer", proposer, "height", state.Height, "round", state.Round)
+			return
+		}
+		err := sm.proposeInDeadline(state)
+		if err == context.Canceled {
+			// the round is changed or the manager is stopped; it is not the
+			// failure of proposing.
+			log.Debug("cancelled to propose new ballot", "proposer", proposer, "height", state.Height, "round", state.Round)
 		} else {
-			log.Error("failed to proposeNewBallot", "height", sm.nr.consensus.LatestBlock().Height, "error", err)
+			if err != nil {
+				log.Error("failed to proposeNewBallot", "height", sm.nr.consensus.LatestBlock().Height, "error", err)
+			}
+			sm.setProposalError(state, err)
+		}
+
+		if err == errors.ProposalDeadlineExceeded {
+			// INIT is expired at once, so the node votes EXP without waiting
+			// for `TimeoutINIT`.
+			timer.Reset(0)
+		} else {
+			timer.Reset(sm.config().TimeoutINIT)
 		}
-		timer.Reset(sm.Conf.TimeoutINIT)
 	} else {
-		timer.Reset(sm.blockTimeBuffer + sm.Conf.TimeoutINIT)
+		// the other nodes expect the same jitter of the proposer
+		timer.Reset(sm.BlockTimeBuffer() + sm.proposerJitter(proposer) + sm.config().TimeoutINIT)
 	}
 	sm.setState(state)
 	sm.transitSignal(state)
 }
 
+// proposeInDeadline proposes the new ballot of the state. If the ballot is not
+// made in `common.Config.ProposalDeadline`, it is abandoned and
+// `errors.ProposalDeadlineExceeded` is returned; the ballot made after the
+// deadline is not broadcasted. In the step mode, the deadline is not applied.
+//
+// If the later state is requested by `TransitISAACState()` or the manager is
+// stopped while proposing, the proposal is canceled and `context.Canceled` is
+// returned.
+func (sm *ISAACStateManager) proposeInDeadline(state consensus.ISAACState) error {
+	ctx, finish := sm.startProposal(state)
+	defer finish()
+
+	deadline := sm.config().ProposalDeadline
+	if deadline <= 0 || sm.isStepMode() {
+		_, err := sm.nr.proposeNewBallot(ctx, state.Round)
+		return err
+	}
+
+	type proposal struct {
+		ballot ballot.Ballot
+		err    error
+	}
+
+	made := make(chan proposal, 1)
+	go func() {
+		b, err := sm.nr.makeNewBallot(ctx, state.Round)
+		made <- proposal{ballot: b, err: err}
+	}()
+
+	timer := time.NewTimer(deadline)
+	defer timer.Stop()
+
+	select {
+	case p := <-made:
+		if p.err != nil {
+			return p.err
+		}
+		sm.nr.broadcastNewBallot(p.ballot)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		sm.nr.Log().Warn(
+			"abandoned to propose new ballot by deadline",
+			"height", state.Height,
+			"round", state.Round,
+			"deadline", deadline,
+		)
+		return errors.ProposalDeadlineExceeded
+	}
+}
+
+// startProposal returns the context of the proposal of the state, which is
+// canceled by the later state or the stop; `finish` must be called after the
+// proposal.
+func (sm *ISAACStateManager) startProposal(state consensus.ISAACState) (ctx context.Context, finish func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sm.Lock()
+	sm.proposing = &state
+	sm.cancelProposal = cancel
+	select {
+	case <-sm.stop:
+		cancel()
+	default:
+	}
+	sm.Unlock()
+
+	return ctx, func() {
+		sm.Lock()
+		sm.proposing = nil
+		sm.cancelProposal = nil
+		sm.Unlock()
+		cancel()
+	}
+}
+
+// setProposalError records the result of `proposeNewBallot()` of the given
+// state. The storage error or the consecutive failures of
+// `common.Config.MaxProposalFailures` halt the consensus; the loop of
+// `Start()` is stopped without waiting for it, because it is called inside
+// the loop, and `ConsensusEventHalted` is emitted.
+func (sm *ISAACStateManager) setProposalError(state consensus.ISAACState, err error) {
+	sm.Lock()
+	if err == nil {
+		sm.failures = 0
+		sm.Unlock()
+		return
+	}
+
+	sm.lastError = err
+	sm.failures++
+	failures := sm.failures
+
+	var reason StopReason
+	if e, ok := err.(*errors.Error); ok && e.Code == errors.StorageCoreError.Code {
+		reason = StopReasonStorageError
+	} else if sm.conf.MaxProposalFailures > 0 && failures >= sm.conf.MaxProposalFailures {
+		reason = StopReasonProposalFailures
+	}
+
+	var halted bool
+	if len(reason) > 0 {
+		halted = sm.closeStop(reason) != nil
+	}
+	sm.Unlock()
+
+	if !halted {
+		return
+	}
+
+	sm.nr.Log().Crit(
+		"consensus halted",
+		"reason", reason,
+		"failures", failures,
+		"height", state.Height,
+		"round", state.Round,
+		"error", err,
+	)
+	sm.nr.ConsensusEvents().Emit(ConsensusEvent{
+		Type:   ConsensusEventHalted,
+		Height: state.Height,
+		Round:  state.Round,
+		Reason: string(reason),
+	})
+}
+
+// closeStop closes `stop` with the reason and returns `done` of the running
+// loop; it returns nil if the loop is not running. The lock must be held.
+func (sm *ISAACStateManager) closeStop(reason StopReason) chan struct{} {
+	done := sm.done
+	if done == nil {
+		return nil
+	}
+	close(sm.stop)
+	if sm.cancelProposal != nil {
+		sm.cancelProposal()
+	}
+	sm.done = nil
+	sm.pending = nil
+	sm.metrics.clearTransitPending()
+	sm.stopReason = reason
+
+	return done
+}
+
+// StopReason returns the cause of the last stop of the loop of `Start()`;
+// it is `StopReasonNone` while the loop is running.
+func (sm *ISAACStateManager) StopReason() StopReason {
+	sm.RLock()
+	defer sm.RUnlock()
+	return sm.stopReason
+}
+
+// LastError returns the last error of proposing ballot; it is kept after the
+// next proposal succeeds.
+func (sm *ISAACStateManager) LastError() error {
+	sm.RLock()
+	defer sm.RUnlock()
+	return sm.lastError
+}
+
+// waitBlockTimeBuffer waits `blockTimeBuffer` with the proposer jitter before
+// proposing the ballot of the given state. It returns false when the waiting is cancelled by `Stop()`
+// or by the newer state transit; the newer state is left pending for the
+// loop of `Start()`. In the step mode, it does not wait.
+func (sm *ISAACStateManager) waitBlockTimeBuffer(state consensus.ISAACState) bool {
+	if sm.isStepMode() {
+		return true
+	}
+
+	sm.RLock()
+	stop := sm.stop
+	buffer := sm.blockTimeBuffer
+	sm.RUnlock()
+
+	timer := time.NewTimer(buffer + sm.proposerJitter(sm.nr.localNode.Address()))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return true
+		case <-stop:
+			return false
+		case <-sm.stateTransit:
+			sm.Lock()
+			pending := sm.pending
+			if pending != nil && !state.IsLater(*pending) {
+				sm.pending = nil // not newer than the proposing state
+				pending = nil
+				sm.metrics.dropTransit()
+			}
+			sm.Unlock()
+
+			if pending != nil {
+				// notify the loop again to handle the newer state.
+				select {
+				case sm.stateTransit <- struct{}{}:
+				default:
+				}
+				return false
+			}
+		}
+	}
+}
+
+// LastAllConfirmed returns the local time of the last `ALLCONFIRM`. It is
+// zero if no block is confirmed since the ISAACStateManager is started.
+func (sm *ISAACStateManager) LastAllConfirmed() time.Time {
+	sm.RLock()
+	defer sm.RUnlock()
+	return sm.allConfirmed
+}
+
+func (sm *ISAACStateManager) setAllConfirmed(t time.Time) {
+	sm.Lock()
+	defer sm.Unlock()
+	sm.allConfirmed = t
+}
+
 func (sm *ISAACStateManager) State() consensus.ISAACState {
 	sm.RLock()
 	defer sm.RUnlock()
@@ -281,8 +851,22 @@ func (sm *ISAACStateManager) setBallotState(ballotState ballot.State) {
 	return
 }
 
+// Stop stops the loop of `Start()` and waits until it returns, so the
+// in-flight `proposeNewBallot()` is finished, or abandoned by
+// `common.Config.ProposalDeadline`, before `Stop()` returns. If the
+// loop does not return in `ISAACStateManagerStopTimeout`, it gives up
+// waiting.
 func (sm *ISAACStateManager) Stop() {
-	go func() {
-		sm.stop <- struct{}{}
-	}()
+	sm.Lock()
+	done := sm.closeStop(StopReasonManual)
+	sm.Unlock()
+	if done == nil {
+		return
+	}
+
+	select {
+	case <-done:
+	case <-time.After(ISAACStateManagerStopTimeout):
+		sm.nr.Log().Warn("timed out to wait ISAACStateManager to be stopped", "timeout", ISAACStateManagerStopTimeout)
+	}
 }
@@ -0,0 +1,192 @@
+package runner
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"boscoin.io/sebak/lib/ballot"
+)
+
+var (
+	isaacStateTimeoutDesc = prometheus.NewDesc(
+		"sebak_isaac_state_timeouts_total",
+		"The number of timeouts of ISAAC ballot state.",
+		[]string{"state"},
+		nil,
+	)
+	isaacStateExpiredBallotsDesc = prometheus.NewDesc(
+		"sebak_isaac_state_expired_ballots_total",
+		"The number of broadcasted expired(EXP) ballots.",
+		nil,
+		nil,
+	)
+	isaacStateRoundIncreasesDesc = prometheus.NewDesc(
+		"sebak_isaac_state_round_increases_total",
+		"The number of round increases.",
+		nil,
+		nil,
+	)
+	isaacStateTransitRequestsDesc = prometheus.NewDesc(
+		"sebak_isaac_state_transit_requests_total",
+		"The number of requested state transitions.",
+		nil,
+		nil,
+	)
+	isaacStateTransitCoalescedDesc = prometheus.NewDesc(
+		"sebak_isaac_state_transit_coalesced_total",
+		"The number of requested state transitions, which are replaced by the later one before delivered.",
+		nil,
+		nil,
+	)
+	isaacStateTransitPendingDesc = prometheus.NewDesc(
+		"sebak_isaac_state_transit_pending",
+		"1 if the requested state transition is waiting for the loop.",
+		nil,
+		nil,
+	)
+)
+
+// ISAACStateMetrics counts the timeouts, the expired ballots, the round
+// increases and the state transition requests of `ISAACStateManager`. The counters are updated atomically, so
+// they can be collected without locking the state manager. It implements
+// `prometheus.Collector`.
+type ISAACStateMetrics struct {
+	timeoutINIT    uint64
+	timeoutSIGN    uint64
+	timeoutACCEPT  uint64
+	expiredBallots uint64
+	roundIncreases uint64
+
+	transitRequests  uint64
+	transitCoalesced uint64
+	transitPending   uint64 // 1 or 0
+}
+
+func (m *ISAACStateMetrics) increaseTimeout(state ballot.State) {
+	switch state {
+	case ballot.StateINIT:
+		atomic.AddUint64(&m.timeoutINIT, 1)
+	case ballot.StateSIGN:
+		atomic.AddUint64(&m.timeoutSIGN, 1)
+	case ballot.StateACCEPT:
+		atomic.AddUint64(&m.timeoutACCEPT, 1)
+	}
+}
+
+func (m *ISAACStateMetrics) increaseExpiredBallots() {
+	atomic.AddUint64(&m.expiredBallots, 1)
+}
+
+func (m *ISAACStateMetrics) increaseRoundIncreases() {
+	atomic.AddUint64(&m.roundIncreases, 1)
+}
+
+// requestTransit counts the request of the state transition; `replaced` is
+// true if it replaces the pending request, which is not yet delivered.
+func (m *ISAACStateMetrics) requestTransit(replaced bool) {
+	atomic.AddUint64(&m.transitRequests, 1)
+	if replaced {
+		atomic.AddUint64(&m.transitCoalesced, 1)
+	}
+	atomic.StoreUint64(&m.transitPending, 1)
+}
+
+// dropTransit counts the pending request, which is dropped without delivered.
+func (m *ISAACStateMetrics) dropTransit() {
+	atomic.AddUint64(&m.transitCoalesced, 1)
+	atomic.StoreUint64(&m.transitPending, 0)
+}
+
+// clearTransitPending is called when the pending request is delivered or the
+// loop is stopped.
+func (m *ISAACStateMetrics) clearTransitPending() {
+	atomic.StoreUint64(&m.transitPending, 0)
+}
+
+// Timeouts returns the number of timeouts of the given ballot state.
+func (m *ISAACStateMetrics) Timeouts(state ballot.State) uint64 {
+	switch state {
+	case ballot.StateINIT:
+		return atomic.LoadUint64(&m.timeoutINIT)
+	case ballot.StateSIGN:
+		return atomic.LoadUint64(&m.timeoutSIGN)
+	case ballot.StateACCEPT:
+		return atomic.LoadUint64(&m.timeoutACCEPT)
+	default:
+		return 0
+	}
+}
+
+// ExpiredBallots returns the number of broadcasted expired ballots.
+func (m *ISAACStateMetrics) ExpiredBallots() uint64 {
+	return atomic.LoadUint64(&m.expiredBallots)
+}
+
+// RoundIncreases returns the number of round increases.
+func (m *ISAACStateMetrics) RoundIncreases() uint64 {
+	return atomic.LoadUint64(&m.roundIncreases)
+}
+
+// TransitRequests returns the number of requested state transitions.
+func (m *ISAACStateMetrics) TransitRequests() uint64 {
+	return atomic.LoadUint64(&m.transitRequests)
+}
+
+// TransitCoalesced returns the number of requested state transitions, which
+// are replaced by the later request or dropped before delivered to the loop
+// of `ISAACStateManager.Start()`.
+func (m *ISAACStateMetrics) TransitCoalesced() uint64 {
+	return atomic.LoadUint64(&m.transitCoalesced)
+}
+
+// TransitPending returns the occupancy of the pending state transition; at
+// most one request is kept, so it is 0 or 1.
+func (m *ISAACStateMetrics) TransitPending() uint64 {
+	return atomic.LoadUint64(&m.transitPending)
+}
+
+func (m *ISAACStateMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- isaacStateTimeoutDesc
+	ch <- isaacStateExpiredBallotsDesc
+	ch <- isaacStateRoundIncreasesDesc
+	ch <- isaacStateTransitRequestsDesc
+	ch <- isaacStateTransitCoalescedDesc
+	ch <- isaacStateTransitPendingDesc
+}
+
+func (m *ISAACStateMetrics) Collect(ch chan<- prometheus.Metric) {
+	for _, state := range []ballot.State{ballot.StateINIT, ballot.StateSIGN, ballot.StateACCEPT} {
+		ch <- prometheus.MustNewConstMetric(
+			isaacStateTimeoutDesc,
+			prometheus.CounterValue,
+			float64(m.Timeouts(state)),
+			state.String(),
+		)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		isaacStateExpiredBallotsDesc,
+		prometheus.CounterValue,
+		float64(m.ExpiredBallots()),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		isaacStateRoundIncreasesDesc,
+		prometheus.CounterValue,
+		float64(m.RoundIncreases()),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		isaacStateTransitRequestsDesc,
+		prometheus.CounterValue,
+		float64(m.TransitRequests()),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		isaacStateTransitCoalescedDesc,
+		prometheus.CounterValue,
+		float64(m.TransitCoalesced()),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		isaacStateTransitPendingDesc,
+		prometheus.GaugeValue,
+		float64(m.TransitPending()),
+	)
+}
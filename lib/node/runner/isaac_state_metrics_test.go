@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+)
+
+// 1. All 3 Nodes.
+// 2. Not proposer itself.
+// 3. INIT and SIGN are expired, so the node broadcasts the EXP ballots.
+// 4. ACCEPT is expired, so the round is increased.
+// 5. INIT of the next round is expired again.
+func TestISAACStateMetrics(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = 200 * time.Millisecond
+	conf.TimeoutSIGN = 200 * time.Millisecond
+	conf.TimeoutACCEPT = 200 * time.Millisecond
+
+	recv := make(chan struct{})
+	nr, _, _ := createNodeRunnerForTesting(3, conf, recv)
+	nr.Consensus().SetProposerSelector(OtherSelector{nr.ConnectionManager()})
+
+	metrics := nr.isaacStateManager.Metrics()
+
+	nr.StartStateManager()
+
+	<-recv // B(`SIGN`, `EXP`)
+	<-recv // B(`ACCEPT`, `EXP`)
+	<-recv // B(`SIGN`, `EXP`) of next round
+	nr.StopStateManager()
+
+	require.Equal(t, uint64(2), metrics.Timeouts(ballot.StateINIT))
+	require.Equal(t, uint64(1), metrics.Timeouts(ballot.StateSIGN))
+	require.Equal(t, uint64(1), metrics.Timeouts(ballot.StateACCEPT))
+	require.Equal(t, uint64(3), metrics.ExpiredBallots())
+	require.Equal(t, uint64(1), metrics.RoundIncreases())
+	require.Equal(t, uint64(1), nr.isaacStateManager.State().Round)
+
+	// collected by prometheus
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(metrics))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	collected := map[string]float64{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			name := family.GetName()
+			for _, label := range m.GetLabel() {
+				name += "/" + label.GetValue()
+			}
+			if m.GetGauge() != nil {
+				collected[name] = m.GetGauge().GetValue()
+			} else {
+				collected[name] = m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	// the transit requests depend on the timing of the loop
+	require.Equal(t, float64(metrics.TransitRequests()), collected["sebak_isaac_state_transit_requests_total"])
+	require.Equal(t, float64(metrics.TransitCoalesced()), collected["sebak_isaac_state_transit_coalesced_total"])
+	require.Equal(t, float64(0), collected["sebak_isaac_state_transit_pending"])
+	require.True(t, metrics.TransitRequests() > 0)
+	delete(collected, "sebak_isaac_state_transit_requests_total")
+	delete(collected, "sebak_isaac_state_transit_coalesced_total")
+	delete(collected, "sebak_isaac_state_transit_pending")
+
+	require.Equal(t, map[string]float64{
+		"sebak_isaac_state_timeouts_total/INIT":   2,
+		"sebak_isaac_state_timeouts_total/SIGN":   1,
+		"sebak_isaac_state_timeouts_total/ACCEPT": 1,
+		"sebak_isaac_state_expired_ballots_total": 3,
+		"sebak_isaac_state_round_increases_total": 1,
+	}, collected)
+}
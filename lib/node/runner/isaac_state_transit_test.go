@@ -2,14 +2,20 @@
 package runner
 
 import (
+	"bytes"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+	leveldbOpt "github.com/syndtr/goleveldb/leveldb/opt"
 
 	"boscoin.io/sebak/lib/ballot"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
 	"boscoin.io/sebak/lib/voting"
 )
 
@@ -383,3 +389,581 @@ func TestStateTransitFromTimeoutSignToAccept(t *testing.T) {
 		require.Equal(t, voting.YES, b.Vote())
 	}
 }
+
+// 1. The loop of `ISAACStateManager` is blocked in the transit signal.
+// 1. `Stop()` waits until the loop returns.
+// 1. After `Stop()`, the late transits are ignored.
+func TestStateManagerStopWaitsLoop(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+
+	nr, _, _ := createNodeRunnerForTesting(3, conf, nil)
+	nr.Consensus().SetProposerSelector(OtherSelector{nr.ConnectionManager()})
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var signals []consensus.ISAACState
+	nr.isaacStateManager.SetTransitSignal(func(state consensus.ISAACState) {
+		signals = append(signals, state)
+		if len(signals) == 1 {
+			close(entered)
+			<-release
+		}
+	})
+
+	nr.StartStateManager()
+	<-entered
+
+	nr.isaacStateManager.RLock()
+	done := nr.isaacStateManager.done
+	nr.isaacStateManager.RUnlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		nr.StopStateManager()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		require.Fail(t, "Stop() returned before the loop exits")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		require.Fail(t, "Stop() did not return")
+	}
+
+	select {
+	case <-done:
+	default:
+		require.Fail(t, "loop is not finished")
+	}
+
+	state := nr.isaacStateManager.State()
+	nr.isaacStateManager.TransitISAACState(state.Height, state.Round, ballot.StateSIGN)
+	nr.isaacStateManager.TransitISAACState(state.Height+1, 0, ballot.StateINIT)
+	time.Sleep(200 * time.Millisecond)
+
+	require.Equal(t, 1, len(signals))
+	require.Equal(t, state, nr.isaacStateManager.State())
+	require.Equal(t, StopReasonManual, nr.StopReason())
+
+	// `Stop()` again does nothing.
+	nr.StopStateManager()
+}
+
+// 1. `ISAACStateManager` is stopped.
+// 1. TransitISAACState() is called many times.
+// 1. It returns without waiting the stopped loop and nothing is pending.
+func TestStateManagerTransitAfterStopNoLeak(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+
+	nr, _, _ := createNodeRunnerForTesting(3, conf, nil)
+	nr.Consensus().SetProposerSelector(OtherSelector{nr.ConnectionManager()})
+
+	nr.StartStateManager()
+	nr.StopStateManager()
+
+	state := nr.isaacStateManager.State()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := uint64(1); i <= 100; i++ {
+			nr.isaacStateManager.TransitISAACState(state.Height+i, 0, ballot.StateINIT)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "TransitISAACState() is blocked by the stopped loop")
+	}
+
+	_, found := nr.isaacStateManager.takePendingState()
+	require.False(t, found)
+	require.Equal(t, state, nr.isaacStateManager.State())
+}
+
+// 1. The loop of `ISAACStateManager` is blocked in the transit signal.
+// 1. The bursts of TransitISAACState() are requested.
+// 1. After the loop is released, only the highest state is delivered.
+func TestStateManagerCoalesceTransits(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+
+	nr, _, _ := createNodeRunnerForTesting(3, conf, nil)
+	nr.Consensus().SetProposerSelector(OtherSelector{nr.ConnectionManager()})
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	settled := make(chan struct{})
+	var signals []consensus.ISAACState
+	nr.isaacStateManager.SetTransitSignal(func(state consensus.ISAACState) {
+		signals = append(signals, state)
+		switch len(signals) {
+		case 1:
+			close(entered)
+			<-release
+		case 2:
+			close(settled)
+		}
+	})
+
+	nr.StartStateManager()
+	defer nr.StopStateManager()
+	<-entered
+
+	state := nr.isaacStateManager.State()
+	highest := consensus.ISAACState{
+		Height:      state.Height + 3,
+		Round:       2,
+		BallotState: ballot.StateACCEPT,
+	}
+
+	var wg sync.WaitGroup
+	for h := uint64(1); h <= 3; h++ {
+		for r := uint64(0); r <= 2; r++ {
+			for _, s := range []ballot.State{ballot.StateINIT, ballot.StateSIGN, ballot.StateACCEPT} {
+				wg.Add(1)
+				go func(h, r uint64, s ballot.State) {
+					defer wg.Done()
+					nr.isaacStateManager.TransitISAACState(state.Height+h, r, s)
+				}(h, r, s)
+			}
+		}
+	}
+	wg.Wait()
+	close(release)
+
+	select {
+	case <-settled:
+	case <-time.After(time.Second):
+		require.Fail(t, "the highest state is not delivered")
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	require.Equal(t, highest, nr.isaacStateManager.State())
+
+	nr.StopStateManager()
+	require.Equal(t, 2, len(signals))
+	require.Equal(t, highest, signals[1])
+}
+
+// 1. The loop of `ISAACStateManager` is blocked in the transit signal.
+// 1. The bursts of TransitISAACState() are requested by many goroutines.
+// 1. No goroutine waits the blocked loop and only one request is pending.
+func TestStateManagerTransitBurstMetrics(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+
+	nr, _, _ := createNodeRunnerForTesting(3, conf, nil)
+	nr.Consensus().SetProposerSelector(OtherSelector{nr.ConnectionManager()})
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	settled := make(chan struct{})
+	var signals int
+	nr.isaacStateManager.SetTransitSignal(func(state consensus.ISAACState) {
+		signals++
+		switch signals {
+		case 1:
+			close(entered)
+			<-release
+		case 2:
+			close(settled)
+		}
+	})
+
+	nr.StartStateManager()
+	defer nr.StopStateManager()
+	<-entered
+
+	metrics := nr.isaacStateManager.Metrics()
+	requests := metrics.TransitRequests()
+	coalesced := metrics.TransitCoalesced()
+	require.Equal(t, uint64(0), metrics.TransitPending())
+
+	state := nr.isaacStateManager.State()
+	burst := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := uint64(1); i <= 200; i++ {
+		wg.Add(1)
+		go func(i uint64) {
+			defer wg.Done()
+			nr.isaacStateManager.TransitISAACState(state.Height+i, 0, ballot.StateINIT)
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		close(burst)
+	}()
+
+	select {
+	case <-burst:
+	case <-time.After(time.Second):
+		require.Fail(t, "TransitISAACState() is blocked by the loop")
+	}
+
+	require.Equal(t, uint64(1), metrics.TransitPending())
+
+	// the accepted requests except the pending one are coalesced
+	accepted := metrics.TransitRequests() - requests
+	require.True(t, accepted >= 1 && accepted <= 200)
+	require.Equal(t, accepted-1, metrics.TransitCoalesced()-coalesced)
+
+	close(release)
+	select {
+	case <-settled:
+	case <-time.After(time.Second):
+		require.Fail(t, "the pending state is not delivered")
+	}
+	require.Equal(t, uint64(0), metrics.TransitPending())
+}
+
+// 1. Proposer itself at round 0, but not at round 1.
+// 1. While the proposer waits `blockTimeBuffer`, the round is increased.
+// 1. The stale ballot of round 0 is not proposed.
+func TestStateManagerCancelProposeByNewerState(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+
+	nr, _, cm := createNodeRunnerForTesting(3, conf, nil)
+	nr.Consensus().SetProposerSelector(SelfThenOtherSelector{nr.ConnectionManager()})
+	nr.isaacStateManager.blockTimeBuffer = time.Second
+
+	nr.StartStateManager()
+	defer nr.StopStateManager()
+	time.Sleep(200 * time.Millisecond)
+
+	nr.isaacStateManager.TransitISAACState(1, 1, ballot.StateINIT)
+	time.Sleep(1500 * time.Millisecond)
+
+	require.Equal(t, 0, len(cm.Messages()))
+	require.Equal(
+		t,
+		consensus.ISAACState{Height: 1, Round: 1, BallotState: ballot.StateINIT},
+		nr.isaacStateManager.State(),
+	)
+}
+
+// 1. Proposer itself.
+// 1. While the proposer waits `blockTimeBuffer`, `Stop()` is called.
+// 1. `Stop()` does not wait `blockTimeBuffer` and no ballot is proposed.
+func TestStateManagerCancelProposeByStop(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+
+	nr, _, cm := createNodeRunnerForTesting(3, conf, nil)
+	nr.isaacStateManager.blockTimeBuffer = 2 * time.Second
+
+	nr.StartStateManager()
+	time.Sleep(200 * time.Millisecond)
+
+	started := time.Now()
+	nr.StopStateManager()
+	require.True(t, time.Since(started) < time.Second)
+
+	time.Sleep(2 * time.Second)
+	require.Equal(t, 0, len(cm.Messages()))
+}
+
+// brokenStorageCore fails to check the keys under the prefix.
+type brokenStorageCore struct {
+	storage.LevelDBCore
+	prefix string
+}
+
+func (c brokenStorageCore) Has(key []byte, opt *leveldbOpt.ReadOptions) (bool, error) {
+	if bytes.HasPrefix(key, []byte(c.prefix)) {
+		return false, leveldb.ErrClosed
+	}
+
+	return c.LevelDBCore.Has(key, opt)
+}
+
+// 1. The node is the proposer and the storage is broken.
+// 1. `proposeNewBallot()` fails with the storage error.
+// 1. `ISAACStateManager` is stopped and reports the cause.
+func TestStateManagerStopByStorageError(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+
+	nr, _, cm := createNodeRunnerForTesting(3, conf, nil)
+	nr.isaacStateManager.blockTimeBuffer = 0
+
+	tx, _ := GetTransaction()
+	require.True(t, nr.TransactionPool.Add(tx))
+	st := nr.Storage()
+	st.Core = brokenStorageCore{LevelDBCore: st.Core, prefix: common.BlockTransactionPrefixHash}
+
+	nr.StartStateManager()
+	for i := 0; i < 100 && nr.StopReason() == StopReasonNone; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.Equal(t, StopReasonStorageError, nr.StopReason())
+	require.Error(t, nr.LastError())
+	require.Equal(t, errors.StorageCoreError.Code, nr.LastError().(*errors.Error).Code)
+	require.Equal(t, 0, len(cm.Messages()))
+
+	// `Stop()` does not override the cause
+	nr.StopStateManager()
+	require.Equal(t, StopReasonStorageError, nr.StopReason())
+}
+
+// 1. `proposeNewBallot()` fails `Config.MaxProposalFailures` times.
+// 1. `ISAACStateManager` is stopped by `StopReasonProposalFailures`.
+// 1. The success resets the failures and the restart clears the cause.
+func TestStateManagerStopByProposalFailures(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+	conf.MaxProposalFailures = 2
+
+	nr, _, _ := createNodeRunnerForTesting(3, conf, nil)
+	nr.Consensus().SetProposerSelector(OtherSelector{nr.ConnectionManager()})
+	sm := nr.isaacStateManager
+
+	sm.Start()
+	defer sm.Stop()
+
+	state := sm.State()
+	sm.setProposalError(state, errors.TransactionNotFound)
+	sm.setProposalError(state, nil)
+	sm.setProposalError(state, errors.TransactionNotFound)
+	require.Equal(t, StopReasonNone, nr.StopReason())
+	require.Equal(t, errors.TransactionNotFound, nr.LastError())
+
+	sm.setProposalError(state, errors.TransactionNotFound)
+	require.Equal(t, StopReasonProposalFailures, nr.StopReason())
+
+	sm.Start()
+	require.Equal(t, StopReasonNone, nr.StopReason())
+	require.Equal(t, errors.TransactionNotFound, nr.LastError())
+}
+
+// 1. The node is the proposer and every proposal fails.
+// 1. The consensus is halted after `Config.MaxProposalFailures` failures.
+// 1. `ConsensusEventHalted` is emitted once.
+func TestStateManagerHaltByProposalFailures(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+	conf.MaxProposalFailures = 3
+
+	nr, _, cm := createNodeRunnerForTesting(3, conf, nil)
+	nr.Consensus().SetProposerSelector(FixedSelector{nr.localNode.Address()})
+	sm := nr.isaacStateManager
+	sm.blockTimeBuffer = 0
+
+	// the inflation can not be calculated, so `proposeNewBallot()` fails.
+	nr.networkParams.InitialBalance = common.MaximumBalance + 1
+
+	var halted []ConsensusEvent
+	nr.ConsensusEvents().Subscribe(func(event ConsensusEvent) {
+		if event.Type == ConsensusEventHalted {
+			halted = append(halted, event)
+		}
+	})
+
+	sm.Start()
+	defer sm.Stop()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for round := uint64(0); round < conf.MaxProposalFailures; round++ {
+		require.Equal(t, StopReasonNone, nr.StopReason())
+		sm.proposeOrWait(timer, consensus.ISAACState{Height: 1, Round: round, BallotState: ballot.StateINIT})
+		require.Equal(t, errors.MaximumBalanceReached, nr.LastError())
+	}
+
+	require.Equal(t, StopReasonProposalFailures, nr.StopReason())
+	require.Equal(t, 1, len(halted))
+	require.Equal(t, string(StopReasonProposalFailures), halted[0].Reason)
+	require.Equal(t, conf.MaxProposalFailures-1, halted[0].Round)
+	require.Equal(t, 0, len(cm.Messages()))
+
+	// the halted node does not handle the new state
+	sm.TransitISAACState(2, 0, ballot.StateINIT)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, uint64(1), sm.State().Height)
+}
+
+// slowStorageCore delays to check the keys under the prefix.
+type slowStorageCore struct {
+	storage.LevelDBCore
+	prefix string
+	delay  time.Duration
+}
+
+func (c slowStorageCore) Has(key []byte, opt *leveldbOpt.ReadOptions) (bool, error) {
+	if bytes.HasPrefix(key, []byte(c.prefix)) {
+		time.Sleep(c.delay)
+	}
+
+	return c.LevelDBCore.Has(key, opt)
+}
+
+// 1. The node is the proposer and the storage is slow.
+// 1. Making the proposal ballot is not finished in `Config.ProposalDeadline`.
+// 1. The node votes EXP at the deadline without waiting for `TimeoutINIT`.
+// 1. The ballot made after the deadline is not broadcasted.
+func TestStateManagerProposalDeadline(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+	conf.ProposalDeadline = 200 * time.Millisecond
+	delay := time.Second
+
+	recv := make(chan struct{})
+	nr, _, cm := createNodeRunnerForTesting(3, conf, recv)
+	nr.Consensus().SetProposerSelector(FixedSelector{nr.localNode.Address()})
+	nr.isaacStateManager.blockTimeBuffer = 0
+
+	tx, _ := GetTransaction()
+	require.True(t, nr.TransactionPool.Add(tx))
+	st := nr.Storage()
+	st.Core = slowStorageCore{LevelDBCore: st.Core, prefix: common.BlockTransactionPrefixHash, delay: delay}
+
+	started := time.Now()
+	nr.StartStateManager()
+	defer nr.StopStateManager()
+
+	select {
+	case <-recv:
+	case <-time.After(delay):
+		require.Fail(t, "EXP is not voted at the deadline")
+	}
+	require.True(t, time.Since(started) < delay)
+
+	require.Equal(t, 1, len(cm.Messages()))
+	b, ok := cm.Messages()[0].(ballot.Ballot)
+	require.True(t, ok)
+	require.Equal(t, voting.EXP, b.Vote())
+	require.Equal(t, ballot.StateSIGN, b.State())
+	require.Equal(t, ballot.ExpiredReasonTimeout, b.ExpiredReason())
+	require.Equal(t, errors.ProposalDeadlineExceeded, nr.LastError())
+
+	// the abandoned ballot is not broadcasted after it is made
+	select {
+	case <-recv:
+		require.Fail(t, "abandoned ballot is broadcasted")
+	case <-time.After(delay * 2):
+	}
+	require.Equal(t, 1, len(cm.Messages()))
+}
+
+// 1. The ISAACStateManager is in the step mode.
+// 1. Without `Step()`, the requested state is not transited.
+// 1. `Step()` drives the full round, INIT -> SIGN -> ACCEPT -> ALLCONFIRM.
+// 1. After ALLCONFIRM, `Step()` starts the next height.
+func TestStateManagerStepMode(t *testing.T) {
+	conf := common.NewConfig()
+
+	nr, _, cm := createNodeRunnerForTesting(3, conf, nil)
+	sm := nr.isaacStateManager
+
+	require.False(t, sm.Step()) // not in the step mode
+
+	sm.EnableStepMode()
+	require.False(t, sm.Step()) // not started
+
+	nr.StartStateManager()
+	defer nr.StopStateManager()
+
+	// the pending INIT by `StartStateManager()`
+	require.Equal(t, uint64(0), sm.State().Height)
+	require.True(t, sm.Step())
+	require.Equal(t, consensus.ISAACState{Height: 1, Round: 0, BallotState: ballot.StateINIT}, sm.State())
+	require.Equal(t, conf.TimeoutINIT, sm.StepTimeout())
+
+	require.Equal(t, 1, len(cm.Messages()))
+	b, ok := cm.Messages()[0].(ballot.Ballot)
+	require.True(t, ok)
+	require.Equal(t, ballot.StateINIT, b.State())
+	require.Equal(t, nr.localNode.Address(), b.Proposer())
+
+	for _, s := range []ballot.State{ballot.StateSIGN, ballot.StateACCEPT} {
+		previous := sm.State()
+		nr.TransitISAACState(voting.Basis{Height: 1, Round: 0}, s)
+		require.Equal(t, previous, sm.State()) // not yet transited
+
+		require.True(t, sm.Step())
+		require.Equal(t, consensus.ISAACState{Height: 1, Round: 0, BallotState: s}, sm.State())
+	}
+	require.Equal(t, conf.TimeoutACCEPT, sm.StepTimeout())
+
+	nr.TransitISAACState(voting.Basis{Height: 1, Round: 0}, ballot.StateALLCONFIRM)
+	require.True(t, sm.Step())
+	require.Equal(t, consensus.ISAACState{Height: 1, Round: 0, BallotState: ballot.StateALLCONFIRM}, sm.State())
+	require.False(t, sm.LastAllConfirmed().IsZero())
+
+	// ALLCONFIRM requests the next height
+	require.True(t, sm.Step())
+	require.Equal(t, consensus.ISAACState{Height: 2, Round: 0, BallotState: ballot.StateINIT}, sm.State())
+	require.Equal(t, 2, len(cm.Messages()))
+
+	nr.StopStateManager()
+	require.False(t, sm.Step())
+}
+
+// 1. The ISAACStateManager is in the step mode and the node is not proposer.
+// 1. Without the pending state, `Step()` fires the timeout of the current state.
+// 1. The node votes EXP and the round is increased at ACCEPT.
+func TestStateManagerStepModeTimeout(t *testing.T) {
+	conf := common.NewConfig()
+
+	nr, _, cm := createNodeRunnerForTesting(3, conf, nil)
+	nr.Consensus().SetProposerSelector(OtherSelector{nr.ConnectionManager()})
+	sm := nr.isaacStateManager
+	sm.EnableStepMode()
+
+	nr.StartStateManager()
+	defer nr.StopStateManager()
+
+	require.True(t, sm.Step())
+	require.Equal(t, consensus.ISAACState{Height: 1, Round: 0, BallotState: ballot.StateINIT}, sm.State())
+	require.Equal(t, 0, len(cm.Messages()))
+
+	for i, s := range []ballot.State{ballot.StateSIGN, ballot.StateACCEPT} {
+		require.True(t, sm.Step())
+		require.Equal(t, s, sm.State().BallotState)
+
+		// the EXP ballot is broadcasted before `Step()` returns
+		require.Equal(t, i+1, len(cm.Messages()))
+		b, ok := cm.Messages()[i].(ballot.Ballot)
+		require.True(t, ok)
+		require.Equal(t, s, b.State())
+		require.Equal(t, voting.EXP, b.Vote())
+	}
+	require.Equal(t, uint64(1), sm.Metrics().Timeouts(ballot.StateINIT))
+	require.Equal(t, uint64(1), sm.Metrics().Timeouts(ballot.StateSIGN))
+
+	// the timeout of ACCEPT increases the round
+	require.True(t, sm.Step())
+	require.True(t, sm.Step())
+	require.Equal(t, consensus.ISAACState{Height: 1, Round: 1, BallotState: ballot.StateINIT}, sm.State())
+}
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -40,7 +41,7 @@ func TestISAACBallotWithEmptyTransactionVoting(t *testing.T) {
 	require.Equal(t, uint64(1), latestBlock.TotalTxs)
 
 	// Generate proposed ballot in nr
-	_, err := nr.proposeNewBallot(0)
+	_, err := nr.proposeNewBallot(context.Background(), 0)
 	require.NoError(t, err)
 
 	round := voting.Basis{
@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"boscoin.io/sebak/lib/common"
+)
+
+var (
+	messageInvalidSignaturesDesc = prometheus.NewDesc(
+		"sebak_message_invalid_signatures_total",
+		"The number of inbound messages with the invalid signature, possibly signed for the other network.",
+		[]string{"type"},
+		nil,
+	)
+)
+
+// MessageMetrics counts the inbound ballots and transactions, which are
+// dropped by `errors.MessageSignatureInvalid`; the peer, which is configured
+// with the other network or sends the forged messages, can be found by it. It implements
+// `prometheus.Collector`.
+type MessageMetrics struct {
+	ballotInvalidSignatures      uint64
+	transactionInvalidSignatures uint64
+}
+
+func NewMessageMetrics() *MessageMetrics {
+	return &MessageMetrics{}
+}
+
+// increaseInvalidSignatures does nothing with the nil `MessageMetrics`.
+func (m *MessageMetrics) increaseInvalidSignatures(messageType common.MessageType) {
+	if m == nil {
+		return
+	}
+
+	switch messageType {
+	case common.BallotMessage:
+		atomic.AddUint64(&m.ballotInvalidSignatures, 1)
+	case common.TransactionMessage:
+		atomic.AddUint64(&m.transactionInvalidSignatures, 1)
+	}
+}
+
+// InvalidSignatures returns the number of the dropped messages of the given
+// type by `errors.MessageSignatureInvalid`.
+func (m *MessageMetrics) InvalidSignatures(messageType common.MessageType) uint64 {
+	switch messageType {
+	case common.BallotMessage:
+		return atomic.LoadUint64(&m.ballotInvalidSignatures)
+	case common.TransactionMessage:
+		return atomic.LoadUint64(&m.transactionInvalidSignatures)
+	default:
+		return 0
+	}
+}
+
+func (m *MessageMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- messageInvalidSignaturesDesc
+}
+
+func (m *MessageMetrics) Collect(ch chan<- prometheus.Metric) {
+	for _, messageType := range []common.MessageType{common.BallotMessage, common.TransactionMessage} {
+		ch <- prometheus.MustNewConstMetric(
+			messageInvalidSignaturesDesc,
+			prometheus.CounterValue,
+			float64(m.InvalidSignatures(messageType)),
+			string(messageType),
+		)
+	}
+}
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// TestMessageSignatureInvalid feeds the ballot and the transaction signed for
+// the other network; they are dropped by `errors.MessageSignatureInvalid` and
+// counted.
+func TestMessageSignatureInvalid(t *testing.T) {
+	otherNetworkID := []byte("sebak-other-network")
+
+	conf := common.NewConfig()
+	nr, nodes, _ := createNodeRunnerForTesting(3, conf, nil)
+	metrics := nr.MessageMetrics()
+
+	latest := nr.Consensus().LatestBlock()
+	basis := voting.Basis{
+		Height:    latest.Height,
+		BlockHash: latest.Hash,
+		TotalTxs:  latest.TotalTxs,
+		TotalOps:  latest.TotalOps,
+	}
+
+	{ // ballot
+		tx, _ := GetTransaction()
+		b := GenerateBallot(nr.localNode, basis, tx, ballot.StateSIGN, nodes[1], conf)
+		b.Sign(nodes[1].Keypair(), otherNetworkID)
+
+		err := ReceiveBallot(nr, b)
+		require.Equal(t, errors.MessageSignatureInvalid, err)
+		require.Equal(t, uint64(1), metrics.InvalidSignatures(common.BallotMessage))
+
+		// the ballot for the network is still accepted
+		b = GenerateBallot(nr.localNode, basis, tx, ballot.StateSIGN, nodes[1], conf)
+		require.NoError(t, ReceiveBallot(nr, b))
+		require.Equal(t, uint64(1), metrics.InvalidSignatures(common.BallotMessage))
+	}
+
+	{ // transaction
+		tx, _ := GetTransaction()
+		tx.Sign(block.GenesisKP, otherNetworkID)
+		body, err := tx.Serialize()
+		require.NoError(t, err)
+
+		checker := &MessageChecker{
+			DefaultChecker:  common.DefaultChecker{Funcs: HandleTransactionCheckerFuncsWithoutBroadcast},
+			Consensus:       nr.Consensus(),
+			TransactionPool: nr.TransactionPool,
+			Storage:         nr.Storage(),
+			LocalNode:       nr.Node(),
+			NetworkID:       networkID,
+			Message:         common.NewNetworkMessage(common.TransactionMessage, body),
+			Log:             nr.Log(),
+			Conf:            nr.Conf,
+			Metrics:         metrics,
+		}
+		err = common.RunChecker(checker, common.DefaultDeferFunc)
+		require.Equal(t, errors.MessageSignatureInvalid, err)
+		require.Equal(t, uint64(1), metrics.InvalidSignatures(common.TransactionMessage))
+		require.False(t, nr.TransactionPool.Has(tx.GetHash()))
+	}
+
+	// collected by prometheus
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(metrics))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(families))
+	require.Equal(t, "sebak_message_invalid_signatures_total", families[0].GetName())
+	for _, m := range families[0].GetMetric() {
+		require.Equal(t, float64(1), m.GetCounter().GetValue())
+	}
+}
@@ -8,8 +8,10 @@
 package runner
 
 import (
+	"context"
 	"net/http"
 	"net/http/pprof"
+	"sync"
 	"time"
 
 	ghandlers "github.com/gorilla/handlers"
@@ -19,6 +21,7 @@ import (
 	"boscoin.io/sebak/lib/ballot"
 	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/observer"
 	"boscoin.io/sebak/lib/consensus"
 	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/network"
@@ -65,6 +68,13 @@ var DefaultHandleACCEPTBallotCheckerFuncs = []common.CheckerFunc{
 	FinishedBallotStore,
 }
 
+// clockSkewReporter is implemented by the `network.ConnectionManager`, which
+// checks the clocks of the validators, like
+// `network.ValidatorConnectionManager`.
+type clockSkewReporter interface {
+	SkewedValidators() map[string]time.Duration
+}
+
 type NodeRunner struct {
 	networkID         []byte
 	localNode         *node.LocalNode
@@ -85,12 +95,23 @@ type NodeRunner struct {
 
 	log logging.Logger
 
-	CommonAccountAddress string
-	InitialBalance       common.Amount
-
 	Conf                  common.Config
+	confLock              sync.RWMutex // guards `Conf` against `ReloadConfig()`
+	networkParams         common.NetworkParams
 	nodeInfo              node.NodeInfo
 	savingBlockOperations *SavingBlockOperations
+	ballotSignatureCache  *ballot.SignatureCache
+	ballotSeenSet         *ballot.SeenSet
+	consensusEvents       *ConsensusEventEmitter
+	messageMetrics        *MessageMetrics
+
+	// blockHeightRegressionHandler is kept to be unsubscribed from
+	// `observer.BlockObserver` by `Stop()`.
+	blockHeightRegressionHandler func(...interface{})
+
+	// validatorSetHeight is the height of the last block, which the validator
+	// set changes are applied to; see `ApplyValidatorChanges()`.
+	validatorSetHeight uint64
 }
 
 func NewNodeRunner(
@@ -112,8 +133,15 @@ func NewNodeRunner(
 		storage:         storage,
 		log:             log.New(logging.Ctx{"node": localNode.Alias()}),
 		Conf:            conf,
+
+		ballotSignatureCache: ballot.NewSignatureCache(common.BallotSignatureCacheLimit),
+		ballotSeenSet:        ballot.NewSeenSet(common.BallotSeenSetLimit),
+		consensusEvents:      NewConsensusEventEmitter(),
+		messageMetrics:       NewMessageMetrics(),
 	}
 	nr.localNode.SetBooting()
+	nr.consensusEvents.Subscribe(NewConsensusEventLogger(nr.log))
+	nr.blockHeightRegressionHandler = nr.logBlockHeightRegression
 
 	nr.isaacStateManager = NewISAACStateManager(nr, conf)
 
@@ -124,7 +152,9 @@ func NewNodeRunner(
 	nr.savingBlockOperations = NewSavingBlockOperations(
 		nr.Storage(),
 		nr.Log(),
-	)
+	).SetRetainedBlocks(conf.RetainedBlocks).
+		SetWriteBuffer(conf.WriteBufferBlocks, conf.WriteBufferInterval).
+		SetStorageCodec(conf.StorageCodec)
 
 	if err = nr.savingBlockOperations.Check(); err != nil {
 		nr.log.Error("failed to check BlockOperations", "error", err)
@@ -136,21 +166,16 @@ func NewNodeRunner(
 	nr.SetHandleSIGNBallotCheckerFuncs(DefaultHandleSIGNBallotCheckerFuncs...)
 	nr.SetHandleACCEPTBallotCheckerFuncs(DefaultHandleACCEPTBallotCheckerFuncs...)
 
-	{
-		// find common account
-		var commonAccount *block.BlockAccount
-		if commonAccount, err = GetCommonAccount(nr.storage); err != nil {
-			return
-		}
-		nr.CommonAccountAddress = commonAccount.Address
-		nr.log.Debug("common account found", "address", nr.CommonAccountAddress)
+	if nr.networkParams, err = LoadNetworkParams(nr.storage, nr.networkID, conf.NetworkParams); err != nil {
+		nr.log.Error("network parameters do not match with genesis block", "error", err)
+		return
+	}
+	nr.log.Debug("network parameters loaded", "params", nr.networkParams)
+	nr.networkParams.InitialBalance.Invariant()
 
-		// get the initial balance of geness account
-		if nr.InitialBalance, err = GetGenesisBalance(nr.storage); err != nil {
-			return
-		}
-		nr.log.Debug("initial balance found", "amount", nr.InitialBalance)
-		nr.InitialBalance.Invariant()
+	if err = nr.ApplyValidatorChanges(); err != nil {
+		nr.log.Error("failed to apply validator set changes", "error", err)
+		return
 	}
 
 	nr.nodeInfo = NewNodeInfo(nr)
@@ -169,6 +194,19 @@ func (nr *NodeRunner) Ready() {
 		nr.log.Error("`network.RateLimitMiddleware` for `RouterNameNode` has an error", "err", err)
 		return
 	}
+
+	// the inbound messages of validators and clients are limited before
+	// they are read
+	maxMessageSizeMiddleware := network.MaxMessageSizeMiddleware(nr.log, nr.Conf.MaxMessageSize)
+	if err := nr.network.AddMiddleware(network.RouterNameNode, maxMessageSizeMiddleware); err != nil {
+		nr.log.Error("`network.MaxMessageSizeMiddleware` for `RouterNameNode` has an error", "err", err)
+		return
+	}
+	if err := nr.network.AddMiddleware(network.RouterNameAPI, maxMessageSizeMiddleware); err != nil {
+		nr.log.Error("`network.MaxMessageSizeMiddleware` for `RouterNameAPI` has an error", "err", err)
+		return
+	}
+
 	if err := nr.network.AddMiddleware(network.RouterNameMetric, rateLimitMiddlewareAPI); err != nil {
 		nr.log.Error("`network.RateLimitMiddleware` for `RouterNameMetric` router has an error", "err", err)
 		return
@@ -187,7 +225,7 @@ func (nr *NodeRunner) Ready() {
 	{ //CORS
 		allowedOrigins := ghandlers.AllowedOrigins([]string{"*"})
 		allowedMethods := ghandlers.AllowedMethods([]string{"GET", "POST"})
-		allowedHeaders := ghandlers.AllowedHeaders([]string{"Content-Type", "X-Requested-With", "Cache-Control", "Access-Control"})
+		allowedHeaders := ghandlers.AllowedHeaders([]string{"Content-Type", "X-Requested-With", "Cache-Control", "Access-Control", api.IdempotencyKeyHeader})
 
 		cors := ghandlers.CORS(allowedOrigins, allowedMethods, allowedHeaders)
 		err := nr.network.AddMiddleware(network.RouterNameAPI, cors)
@@ -207,6 +245,8 @@ func (nr *NodeRunner) Ready() {
 		network.UrlPathPrefixNode,
 		nr.Conf,
 	)
+	nodeHandler.SetMessageMetrics(nr.messageMetrics)
+	nodeHandler.SetConfigFunc(nr.Config)
 
 	nr.network.AddHandler(nodeHandler.HandlerURLPattern(NodeInfoHandlerPattern), nodeHandler.NodeInfoHandler)
 	nr.network.AddHandler(nodeHandler.HandlerURLPattern(ConnectHandlerPattern), nodeHandler.ConnectHandler).
@@ -221,6 +261,8 @@ func (nr *NodeRunner) Ready() {
 	nr.network.AddHandler(nodeHandler.HandlerURLPattern(GetBlocksPattern), nodeHandler.GetBlocksHandler).
 		Methods("GET", "POST").
 		MatcherFunc(common.PostAndJSONMatcher)
+	nr.network.AddHandler(nodeHandler.HandlerURLPattern(GetBlockStreamPattern), nodeHandler.GetBlockStreamHandler).
+		Methods("GET")
 	nr.network.AddHandler(nodeHandler.HandlerURLPattern(GetTransactionPattern), nodeHandler.GetNodeTransactionsHandler).
 		Methods("GET", "POST").
 		MatcherFunc(common.PostAndJSONMatcher)
@@ -236,6 +278,22 @@ func (nr *NodeRunner) Ready() {
 		nr.nodeInfo,
 	)
 	apiHandler.GetLatestBlock = nr.Consensus().LatestBlock
+	apiHandler.GetBlockTimeBuffer = nr.isaacStateManager.BlockTimeBuffer
+	apiHandler.GetISAACState = nr.isaacStateManager.State
+	apiHandler.SelectProposer = nr.Consensus().SelectProposer
+	apiHandler.GetLastAllConfirmed = nr.isaacStateManager.LastAllConfirmed
+	apiHandler.HealthStaleWindow = nr.Conf.HealthStaleWindow
+	apiHandler.GetStopReason = func() (string, error) {
+		return string(nr.StopReason()), nr.LastError()
+	}
+	if reporter, ok := nr.connectionManager.(clockSkewReporter); ok {
+		apiHandler.GetClockSkews = reporter.SkewedValidators
+	}
+	apiHandler.TransactionPool = nr.TransactionPool
+	apiHandler.MaxTransactionsInBatch = nr.Conf.MaxTransactionsInBatch
+	if nr.Conf.IdempotencyWindow > 0 {
+		apiHandler.Idempotency = api.NewIdempotencyCache(nr.Conf.IdempotencyWindow, common.IdempotencyKeyLimit)
+	}
 
 	nr.network.AddHandler(
 		apiHandler.HandlerURLPattern(api.GetAccountHandlerPattern),
@@ -249,6 +307,35 @@ func (nr *NodeRunner) Ready() {
 		apiHandler.HandlerURLPattern(api.GetAccountOperationsHandlerPattern),
 		apiHandler.GetOperationsByAccountHandler,
 	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetOperationsStreamPattern),
+		apiHandler.GetOperationsStreamHandler,
+	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetHealthPattern),
+		apiHandler.GetHealthHandler,
+	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetTransactionPoolHandlerPattern),
+		apiHandler.GetTransactionPoolHandler,
+	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetValidatorChangesHandlerPattern),
+		apiHandler.GetPendingValidatorChangesHandler,
+	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetBlockTimeStatisticsPattern),
+		apiHandler.GetBlockTimeStatisticsHandler,
+	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.PostTransactionsBatchPattern),
+		func(w http.ResponseWriter, r *http.Request) {
+			apiHandler.PostTransactionsBatchHandler(
+				w, r,
+				nodeHandler.ReceiveTransaction, HandleTransactionCheckerFuncs,
+			)
+		},
+	).Methods("POST", "OPTIONS").MatcherFunc(common.PostAndJSONMatcher)
 	nr.network.AddHandler(
 		apiHandler.HandlerURLPattern(api.GetTransactionByHashHandlerPattern),
 		apiHandler.GetTransactionByHashHandler,
@@ -261,6 +348,14 @@ func (nr *NodeRunner) Ready() {
 		apiHandler.HandlerURLPattern(api.GetTransactionHistoryHandlerPattern),
 		apiHandler.GetTransactionHistoryHandler,
 	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetTransactionInclusionHandlerPattern),
+		apiHandler.GetTransactionInclusionHandler,
+	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetTransactionDetailHandlerPattern),
+		apiHandler.GetTransactionDetailHandler,
+	).Methods("GET", "OPTIONS")
 
 	TransactionsHandler := func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "POST" {
@@ -298,6 +393,8 @@ func (nr *NodeRunner) Start() (err error) {
 	nr.log.Debug("NodeRunner started")
 	nr.Ready()
 
+	observer.BlockObserver.On(block.EventBlockHeightRegression, nr.blockHeightRegressionHandler)
+
 	go nr.handleMessages()
 	go nr.ConnectValidators()
 	go nr.InitRound()
@@ -311,10 +408,31 @@ func (nr *NodeRunner) Start() (err error) {
 }
 
 func (nr *NodeRunner) Stop() {
+	observer.BlockObserver.Off(block.EventBlockHeightRegression, nr.blockHeightRegressionHandler)
+
 	nr.network.Stop()
 	nr.isaacStateManager.Stop()
 }
 
+// logBlockHeightRegression alerts the block rejected by the height
+// watermark; the node is not stopped, but it must be checked by the operator,
+// because the peer or the sync tried to go back to the lower height.
+func (nr *NodeRunner) logBlockHeightRegression(args ...interface{}) {
+	watermark, _ := args[0].(uint64)
+	blk, ok := args[1].(*block.Block)
+	if !ok {
+		return
+	}
+
+	nr.log.Crit(
+		"block height regressed",
+		"watermark", watermark,
+		"height", blk.Height,
+		"block", blk.Hash,
+		"proposer", blk.Proposer,
+	)
+}
+
 func (nr *NodeRunner) Node() *node.LocalNode {
 	return nr.localNode
 }
@@ -323,6 +441,12 @@ func (nr *NodeRunner) NetworkID() []byte {
 	return nr.networkID
 }
 
+// NetworkParams returns the network parameters verified with the genesis
+// block.
+func (nr *NodeRunner) NetworkParams() common.NetworkParams {
+	return nr.networkParams
+}
+
 func (nr *NodeRunner) Network() network.Network {
 	return nr.network
 }
@@ -351,10 +475,66 @@ func (nr *NodeRunner) SavingBlockOperations() *SavingBlockOperations {
 	return nr.savingBlockOperations
 }
 
+func (nr *NodeRunner) BallotSignatureCache() *ballot.SignatureCache {
+	return nr.ballotSignatureCache
+}
+
+func (nr *NodeRunner) BallotSeenSet() *ballot.SeenSet {
+	return nr.ballotSeenSet
+}
+
+// ConsensusEvents returns the emitter of the consensus events; the external
+// systems can subscribe to it.
+func (nr *NodeRunner) ConsensusEvents() *ConsensusEventEmitter {
+	return nr.consensusEvents
+}
+
+// Config returns the running config; it may be replaced by `ReloadConfig()`,
+// so the config is read by it while the node is running.
+func (nr *NodeRunner) Config() common.Config {
+	nr.confLock.RLock()
+	defer nr.confLock.RUnlock()
+
+	return nr.Conf
+}
+
+// ReloadConfig replaces the running config with `conf`; only the timeouts,
+// the fees and the limits can be changed, see `common.Config.Reload()`. The
+// new timeouts are used from the next timer of `ISAACStateManager`, so the
+// running round is not disturbed, and the new fees and limits are used from
+// the next validation.
+func (nr *NodeRunner) ReloadConfig(conf common.Config) (warnings []string, err error) {
+	nr.confLock.Lock()
+	defer nr.confLock.Unlock()
+
+	var reloaded common.Config
+	if reloaded, warnings, err = nr.Conf.Reload(conf); err != nil {
+		return
+	}
+
+	nr.Conf = reloaded
+	nr.isaacStateManager.setConfig(reloaded)
+	nr.log.Info("config reloaded", "config", reloaded)
+
+	return
+}
+
+// MessageMetrics returns the counters of the dropped inbound messages.
+func (nr *NodeRunner) MessageMetrics() *MessageMetrics {
+	return nr.messageMetrics
+}
+
 func (nr *NodeRunner) ISAACStateManager() *ISAACStateManager {
 	return nr.isaacStateManager
 }
 
+// VoteAggregations returns the snapshots of the votes of the current height
+// and round; see `consensus.ISAAC.VoteAggregations()`.
+func (nr *NodeRunner) VoteAggregations() []consensus.VoteAggregation {
+	state := nr.isaacStateManager.State()
+	return nr.consensus.VoteAggregations(state.Height, state.Round)
+}
+
 func (nr *NodeRunner) ConnectValidators() {
 	ticker := time.NewTicker(time.Millisecond * 5)
 	for _ = range ticker.C {
@@ -524,6 +704,18 @@ func (nr *NodeRunner) StopStateManager() {
 	return
 }
 
+// StopReason returns why the consensus of the node is stopped; see
+// `ISAACStateManager.StopReason()`.
+func (nr *NodeRunner) StopReason() StopReason {
+	return nr.isaacStateManager.StopReason()
+}
+
+// LastError returns the last error of proposing ballot; see
+// `ISAACStateManager.LastError()`.
+func (nr *NodeRunner) LastError() error {
+	return nr.isaacStateManager.LastError()
+}
+
 func (nr *NodeRunner) TransitISAACState(round voting.Basis, ballotState ballot.State) {
 	nr.isaacStateManager.TransitISAACState(round.Height, round.Round, ballotState)
 }
@@ -531,10 +723,34 @@ func (nr *NodeRunner) TransitISAACState(round voting.Basis, ballotState ballot.S
 var NewBallotTransactionCheckerFuncs = []common.CheckerFunc{
 	IsNew,
 	BallotTransactionsSameSource,
+	BallotTransactionsMergedAccount,
+	BallotTransactionsOperationsNew,
 	BallotTransactionsSourceCheck,
 }
 
-func (nr *NodeRunner) proposeNewBallot(round uint64) (ballot.Ballot, error) {
+// proposeNewBallot makes the new ballot of the round and broadcasts it. If
+// `ctx` is canceled, like by the round change, it aborts and returns the
+// error of `ctx`; nothing is broadcasted.
+func (nr *NodeRunner) proposeNewBallot(ctx context.Context, round uint64) (ballot.Ballot, error) {
+	theBallot, err := nr.makeNewBallot(ctx, round)
+	if err != nil {
+		return ballot.Ballot{}, err
+	}
+
+	nr.broadcastNewBallot(theBallot)
+
+	return theBallot, nil
+}
+
+// makeNewBallot makes the signed proposal ballot of the round without
+// broadcasting it. `ctx` is checked between the steps and while the
+// transactions are checked.
+func (nr *NodeRunner) makeNewBallot(ctx context.Context, round uint64) (ballot.Ballot, error) {
+	if err := ctx.Err(); err != nil {
+		return ballot.Ballot{}, err
+	}
+
+	conf := nr.Config()
 	b := nr.consensus.LatestBlock()
 	basis := voting.Basis{
 		Round:     round,
@@ -545,8 +761,14 @@ func (nr *NodeRunner) proposeNewBallot(round uint64) (ballot.Ballot, error) {
 	}
 
 	// collect incoming transactions from `Pool`
-	availableTransactions := nr.TransactionPool.AvailableTransactions(nr.Conf.TxsLimit)
+	availableTransactions := nr.TransactionPool.SelectTransactions(
+		conf.TransactionSelectionPolicy,
+		conf.TxsLimit,
+	)
 	nr.log.Debug("new round proposed", "block-basis", basis, "transactions", availableTransactions)
+	if err := ctx.Err(); err != nil {
+		return ballot.Ballot{}, err
+	}
 
 	transactionsChecker := &BallotTransactionChecker{
 		DefaultChecker:        common.DefaultChecker{Funcs: NewBallotTransactionCheckerFuncs},
@@ -557,36 +779,82 @@ func (nr *NodeRunner) proposeNewBallot(round uint64) (ballot.Ballot, error) {
 		CheckTransactionsOnly: true,
 		VotingHole:            voting.NOTYET,
 		transactionCache:      NewTransactionCache(nr.Storage(), nr.TransactionPool),
+		ctx:                   ctx,
 	}
 
+	// the invalid transactions are just excluded by `CheckTransactionsOnly`,
+	// so the error is not from the transactions, like the storage error.
 	if err := common.RunChecker(transactionsChecker, common.DefaultDeferFunc); err != nil {
 		if _, ok := err.(common.CheckerErrorStop); !ok {
-			nr.log.Error("error occurred in BallotTransactionChecker", "error", err)
+			if err != ctx.Err() {
+				nr.log.Error("error occurred in BallotTransactionChecker", "error", err)
+			}
+			return ballot.Ballot{}, err
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return ballot.Ballot{}, err
+	}
+
 	// remove invalid transactions
 	nr.TransactionPool.Remove(transactionsChecker.InvalidTransactions()...)
 
-	proposerAddr := nr.consensus.SelectProposer(b.Height, round)
-	theBallot := ballot.NewBallot(nr.localNode.Address(), proposerAddr, basis, transactionsChecker.ValidTransactions)
-	theBallot.SetVote(ballot.StateINIT, voting.YES)
-
+	// the transactions over `MaxOpsPerBlock` or `MaxBlockWeight` are left in
+	// the pool for the next ballot.
+	var validHashes []string
 	var validTransactions []transaction.Transaction
+	var ops int
+	var weight uint64
 	for _, hash := range transactionsChecker.ValidTransactions {
-		if tx, found := nr.TransactionPool.Get(hash); !found {
+		tx, found := nr.TransactionPool.Get(hash)
+		if !found {
 			return ballot.Ballot{}, errors.TransactionNotFound
-		} else {
-			validTransactions = append(validTransactions, tx)
 		}
+		if conf.MaxOpsPerBlock > 0 && ops+len(tx.B.Operations) > conf.MaxOpsPerBlock {
+			break
+		}
+		w := tx.Weight(conf.OperationWeights)
+		if conf.MaxBlockWeight > 0 && weight+w > conf.MaxBlockWeight {
+			break
+		}
+
+		ops += len(tx.B.Operations)
+		weight += w
+		validHashes = append(validHashes, hash)
+		validTransactions = append(validTransactions, tx)
 	}
 
-	opc, err := ballot.NewCollectTxFeeFromBallot(*theBallot, nr.CommonAccountAddress, validTransactions...)
+	proposerAddr := nr.consensus.SelectProposer(b.Height, round)
+	theBallot := ballot.NewBallot(nr.localNode.Address(), proposerAddr, basis, validHashes)
+	theBallot.SetVote(ballot.StateINIT, voting.YES)
+
+	if err := ctx.Err(); err != nil {
+		return ballot.Ballot{}, err
+	}
+
+	// the fee and the inflation must not be sent to the missing account
+	if err := VerifyCommonAccount(nr.storage, nr.networkParams.CommonAccount); err != nil {
+		return ballot.Ballot{}, err
+	}
+
+	opc, err := ballot.NewCollectTxFeeFromBallotWithPolicy(
+		*theBallot,
+		nr.networkParams.CommonAccount,
+		conf.FeeBurnPolicy,
+		validTransactions...,
+	)
 	if err != nil {
 		return ballot.Ballot{}, err
 	}
 
-	opi, err := ballot.NewInflationFromBallot(*theBallot, nr.CommonAccountAddress, nr.InitialBalance)
+	opi, err := ballot.NewInflationFromBallotWithPolicy(
+		*theBallot,
+		nr.networkParams.CommonAccount,
+		nr.networkParams.InitialBalance,
+		conf.InflationSchedule,
+		conf.InflationPolicy,
+	)
 	if err != nil {
 		return ballot.Ballot{}, err
 	}
@@ -601,11 +869,19 @@ func (nr *NodeRunner) proposeNewBallot(round uint64) (ballot.Ballot, error) {
 
 	nr.log.Debug("new ballot created", "ballot", theBallot)
 
-	nr.ConnectionManager().Broadcast(*theBallot)
-
 	return *theBallot, nil
 }
 
+func (nr *NodeRunner) broadcastNewBallot(theBallot ballot.Ballot) {
+	nr.ConnectionManager().Broadcast(theBallot)
+	nr.consensusEvents.Emit(ConsensusEvent{
+		Type:     ConsensusEventProposalMade,
+		Height:   theBallot.VotingBasis().Height,
+		Round:    theBallot.VotingBasis().Round,
+		Proposer: theBallot.Proposer(),
+	})
+}
+
 func (nr *NodeRunner) NodeInfo() node.NodeInfo {
 	return nr.nodeInfo
 }
@@ -7,13 +7,16 @@ import (
 	"testing"
 	"time"
 
+	logging "github.com/inconshreveable/log15"
 	"github.com/stretchr/testify/require"
 
 	"boscoin.io/sebak/lib/ballot"
 	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/common/observer"
 	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/network"
 	"boscoin.io/sebak/lib/node"
 	"boscoin.io/sebak/lib/voting"
@@ -313,3 +316,89 @@ func TestExpiredBallotCheckProposer(t *testing.T) {
 
 	require.Equal(t, voting.NO, checker.VotingHole)
 }
+
+// TestNodeRunnerReloadConfig checks the reloaded timeout is used by the next
+// `resetTimer()` and the immutable fields can not be reloaded.
+func TestNodeRunnerReloadConfig(t *testing.T) {
+	conf := common.NewConfig()
+	conf.TimeoutSIGN = time.Hour
+	nr, _, _ := createNodeRunnerForTesting(3, conf, nil)
+	sm := nr.ISAACStateManager()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	sm.resetTimer(timer, ballot.StateSIGN)
+	select {
+	case <-timer.C:
+		require.Fail(t, "timer must not be expired before reloading")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	newConf := nr.Config()
+	newConf.TimeoutSIGN = 10 * time.Millisecond
+	newConf.TimeoutACCEPT = 3 * time.Second
+	newConf.FeePolicy = common.FeePolicy{"payment": common.BaseFee * 2}
+	_, err := nr.ReloadConfig(newConf)
+	require.NoError(t, err)
+	require.Equal(t, newConf.TimeoutSIGN, nr.Config().TimeoutSIGN)
+	require.Equal(t, newConf.TimeoutACCEPT, nr.Config().TimeoutACCEPT)
+	require.Equal(t, newConf.FeePolicy, nr.Config().FeePolicy)
+
+	sm.resetTimer(timer, ballot.StateSIGN)
+	select {
+	case <-timer.C:
+	case <-time.After(time.Second):
+		require.Fail(t, "reloaded timeout is not used")
+	}
+
+	{ // immutable
+		immutable := nr.Config()
+		immutable.NetworkParams.CommonAccount = keypair.Random().Address()
+		_, err := nr.ReloadConfig(immutable)
+		require.Error(t, err)
+		require.Equal(t, "NetworkParams", err.(*errors.Error).Field())
+
+		immutable = nr.Config()
+		immutable.TimeoutSIGN = time.Hour
+		immutable.GenesisTime = time.Now().Add(-time.Hour)
+		_, err = nr.ReloadConfig(immutable)
+		require.Error(t, err)
+		require.Equal(t, "GenesisTime", err.(*errors.Error).Field())
+
+		// nothing is reloaded
+		require.Equal(t, newConf.TimeoutSIGN, nr.Config().TimeoutSIGN)
+	}
+}
+
+func TestNodeRunnerBlockHeightRegression(t *testing.T) {
+	nr, _ := MakeNodeRunner()
+
+	var records []*logging.Record
+	nr.log = logging.New()
+	nr.log.SetHandler(logging.FuncHandler(func(r *logging.Record) error {
+		records = append(records, r)
+		return nil
+	}))
+
+	genesis := block.GetLatestBlock(nr.Storage())
+	rejected := block.TestMakeNewBlockWithPrevBlock(genesis, []string{})
+
+	// subscribed like `Start()`
+	observer.BlockObserver.On(block.EventBlockHeightRegression, nr.blockHeightRegressionHandler)
+	observer.BlockObserver.Trigger(block.EventBlockHeightRegression, rejected.Height+1, &rejected)
+
+	require.Equal(t, 1, len(records))
+	require.Equal(t, logging.LvlCrit, records[0].Lvl)
+	require.Equal(t, []interface{}{
+		"watermark", rejected.Height + 1,
+		"height", rejected.Height,
+		"block", rejected.Hash,
+		"proposer", rejected.Proposer,
+	}, records[0].Ctx)
+
+	// unsubscribed like `Stop()`
+	observer.BlockObserver.Off(block.EventBlockHeightRegression, nr.blockHeightRegressionHandler)
+	observer.BlockObserver.Trigger(block.EventBlockHeightRegression, rejected.Height+1, &rejected)
+	require.Equal(t, 1, len(records))
+}
@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+)
+
+func simulationConfig() common.Config {
+	conf := common.NewConfig()
+	conf.TimeoutINIT = 500 * time.Millisecond
+	conf.TimeoutSIGN = 500 * time.Millisecond
+	conf.TimeoutACCEPT = 500 * time.Millisecond
+	conf.BlockTime = 100 * time.Millisecond
+
+	return conf
+}
+
+// Run 4 nodes to several heights with the latency and check they agree on
+// every block.
+func TestSimulationAgreement(t *testing.T) {
+	sim, err := NewSimulation(4, simulationConfig(), SimulationConfig{
+		Latency:       5 * time.Millisecond,
+		LatencyJitter: 10 * time.Millisecond,
+		Seed:          1,
+	})
+	require.NoError(t, err)
+	defer sim.Stop()
+
+	sim.Start()
+	require.NoError(t, sim.WaitHeight(3, 30*time.Second))
+
+	// the transaction is included in the same block by all the nodes
+	tx, _, kpNewAccount := GetCreateAccountTransaction(0, uint64(common.BaseReserve))
+	require.NoError(t, sim.SubmitTransaction(tx))
+
+	require.NoError(t, sim.WaitHeight(6, 30*time.Second))
+	require.NoError(t, sim.CheckAgreement())
+
+	for _, nr := range sim.NodeRunners() {
+		exists, err := block.ExistsBlockAccount(nr.Storage(), kpNewAccount.Address())
+		require.NoError(t, err)
+		require.True(t, exists)
+	}
+}
+
+// The transaction with the future sequenceID is held by all the nodes and it
+// is proposed after the transaction of the missing sequenceID is stored.
+func TestSimulationHeldTransaction(t *testing.T) {
+	sim, err := NewSimulation(4, simulationConfig(), SimulationConfig{Seed: 1})
+	require.NoError(t, err)
+	defer sim.Stop()
+
+	missing, _, kpMissing := GetCreateAccountTransaction(0, uint64(common.BaseReserve))
+	held, _, kpHeld := GetCreateAccountTransaction(1, uint64(common.BaseReserve))
+
+	require.NoError(t, sim.SubmitTransaction(held))
+	for _, nr := range sim.NodeRunners() {
+		require.True(t, nr.TransactionPool.IsHeld(held.GetHash()))
+	}
+
+	sim.Start()
+	require.NoError(t, sim.WaitHeight(3, 30*time.Second))
+
+	// not proposed before the gap is filled
+	for _, nr := range sim.NodeRunners() {
+		exists, err := block.ExistsBlockAccount(nr.Storage(), kpHeld.Address())
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+
+	require.NoError(t, sim.SubmitTransaction(missing))
+	require.NoError(t, sim.WaitHeight(8, 30*time.Second))
+	require.NoError(t, sim.CheckAgreement())
+
+	for _, nr := range sim.NodeRunners() {
+		for _, address := range []string{kpMissing.Address(), kpHeld.Address()} {
+			exists, err := block.ExistsBlockAccount(nr.Storage(), address)
+			require.NoError(t, err)
+			require.True(t, exists)
+		}
+
+		bm, err := block.GetBlockTransaction(nr.Storage(), missing.GetHash())
+		require.NoError(t, err)
+		bh, err := block.GetBlockTransaction(nr.Storage(), held.GetHash())
+		require.NoError(t, err)
+
+		blkMissing, err := block.GetBlock(nr.Storage(), bm.Block)
+		require.NoError(t, err)
+		blkHeld, err := block.GetBlock(nr.Storage(), bh.Block)
+		require.NoError(t, err)
+		require.True(t, blkHeld.Height > blkMissing.Height)
+
+		require.Equal(t, 0, nr.TransactionPool.HeldLen())
+	}
+}
+
+// Kill the proposer of the first block; the round is expired and the next
+// proposer confirms the block, and the rest 3 nodes keep going.
+func TestSimulationKillProposer(t *testing.T) {
+	sim, err := NewSimulation(4, simulationConfig(), SimulationConfig{Seed: 1})
+	require.NoError(t, err)
+	defer sim.Stop()
+
+	height := common.GenesisBlockHeight + 1
+	killed := sim.Proposer(height, 0)
+	sim.Kill(killed)
+
+	sim.Start()
+	require.NoError(t, sim.WaitHeight(height+2, 30*time.Second))
+	require.NoError(t, sim.CheckAgreement())
+
+	var blk block.Block
+	for _, nr := range sim.NodeRunners() {
+		if nr.Node().Address() == killed {
+			continue
+		}
+		blk, err = block.GetBlockByHeight(nr.Storage(), height)
+		require.NoError(t, err)
+		require.NotEqual(t, killed, blk.Proposer)
+		require.True(t, blk.Round > 0)
+	}
+}
@@ -0,0 +1,401 @@
+package runner
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// SimulationConfig decides how the messages between the nodes of
+// `Simulation` are delivered.
+type SimulationConfig struct {
+	// Latency is the delay of every message between the nodes and
+	// LatencyJitter is the maximum random delay added to it, so the messages
+	// can be reordered.
+	Latency       time.Duration
+	LatencyJitter time.Duration
+
+	// DropRate is the probability in [0, 1] to drop the message between the
+	// nodes; the message to the sender itself is never dropped.
+	DropRate float64
+
+	// Seed is the seed of the random source of the jitter and the drop, so
+	// the same scenario can be reproduced.
+	Seed int64
+}
+
+// Simulation runs the multiple `NodeRunner`s in the process without
+// networking; the messages are delivered in memory by `SimulationConfig`.
+// Each node has the own storage with the same genesis block and uses
+// `consensus.SequentialSelector`, so the proposer can be known by
+// `Proposer()` and killed by `Kill()`.
+//
+// The transactions are submitted to all the live nodes by
+// `SubmitTransaction()` instead of being broadcasted, and the syncer is not
+// set, so the node, which misses the ballots, can fall behind.
+type Simulation struct {
+	sync.RWMutex
+
+	conf        SimulationConfig
+	rand        *rand.Rand
+	nodeRunners []*NodeRunner
+	inboxes     map[ /* node address */ string]chan common.NetworkMessage
+	killed      map[ /* node address */ string]bool
+	stop        chan struct{}
+	stopped     bool
+	wg          sync.WaitGroup
+}
+
+// simulationConnectionManager delivers the broadcasted messages through
+// `Simulation`; the rest is done by the embedded `ConnectionManager`.
+type simulationConnectionManager struct {
+	network.ConnectionManager
+
+	sim *Simulation
+}
+
+func (c *simulationConnectionManager) Broadcast(message common.Message) {
+	c.sim.broadcast(c.GetNodeAddress(), message)
+}
+
+func (c *simulationConnectionManager) AllConnected() []string {
+	return c.sim.liveAddresses()
+}
+
+func (c *simulationConnectionManager) CountConnected() int {
+	return len(c.sim.liveAddresses())
+}
+
+// NewSimulation creates the `n` nodes, which are the validators of each
+// other, with the threshold `66`.
+func NewSimulation(n int, conf common.Config, simConf SimulationConfig) (sim *Simulation, err error) {
+	sim = &Simulation{
+		conf:    simConf,
+		rand:    rand.New(rand.NewSource(simConf.Seed)),
+		inboxes: map[string]chan common.NetworkMessage{},
+		killed:  map[string]bool{},
+		stop:    make(chan struct{}),
+	}
+
+	var ns []*network.MemoryNetwork
+	var net *network.MemoryNetwork
+	var nodes []*node.LocalNode
+	for i := 0; i < n; i++ {
+		_, s, v := network.CreateMemoryNetwork(net)
+		net = s
+		ns = append(ns, s)
+		nodes = append(nodes, v)
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			nodes[i].AddValidators(nodes[j].ConvertToValidator())
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		localNode := nodes[i]
+		policy, _ := consensus.NewDefaultVotingThresholdPolicy(66)
+
+		connectionManager := &simulationConnectionManager{
+			ConnectionManager: network.NewValidatorConnectionManager(localNode, ns[i], policy),
+			sim:               sim,
+		}
+
+		st := block.InitTestBlockchain()
+
+		var is *consensus.ISAAC
+		if is, err = consensus.NewISAAC(networkID, localNode, policy, connectionManager, st, conf, nil); err != nil {
+			return
+		}
+
+		var nr *NodeRunner
+		if nr, err = NewNodeRunner(string(networkID), localNode, policy, ns[i], is, st, conf); err != nil {
+			return
+		}
+		nr.isaacStateManager.blockTimeBuffer = 0
+
+		sim.nodeRunners = append(sim.nodeRunners, nr)
+		sim.inboxes[localNode.Address()] = make(chan common.NetworkMessage, 1000)
+	}
+
+	return
+}
+
+// NodeRunners returns all the nodes including the killed ones.
+func (s *Simulation) NodeRunners() []*NodeRunner {
+	return s.nodeRunners
+}
+
+// SetConfig changes the delivery of the next messages.
+func (s *Simulation) SetConfig(simConf SimulationConfig) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.conf = simConf
+	s.rand = rand.New(rand.NewSource(simConf.Seed))
+}
+
+// Start starts the consensus of all the nodes from the next height of the
+// genesis block; the nodes killed before starting are not started.
+func (s *Simulation) Start() {
+	for _, nr := range s.nodeRunners {
+		s.wg.Add(1)
+		go s.handleMessages(nr)
+	}
+
+	for _, nr := range s.nodeRunners {
+		if s.IsKilled(nr.Node().Address()) {
+			continue
+		}
+		nr.consensus.SetLatestRound(voting.Basis{})
+		nr.StartStateManager()
+	}
+}
+
+// Stop stops all the nodes and closes their storages.
+func (s *Simulation) Stop() {
+	s.Lock()
+	if s.stopped {
+		s.Unlock()
+		return
+	}
+	s.stopped = true
+	close(s.stop)
+	s.Unlock()
+
+	s.wg.Wait()
+
+	for _, nr := range s.nodeRunners {
+		nr.StopStateManager()
+	}
+	for _, nr := range s.nodeRunners {
+		nr.Storage().Close()
+	}
+}
+
+// Kill stops the consensus of the node; the messages from and to the node
+// are dropped.
+func (s *Simulation) Kill(address string) {
+	s.Lock()
+	s.killed[address] = true
+	s.Unlock()
+
+	if nr := s.NodeRunner(address); nr != nil {
+		nr.StopStateManager()
+	}
+}
+
+// IsKilled returns whether the node is killed by `Kill()`.
+func (s *Simulation) IsKilled(address string) bool {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.killed[address]
+}
+
+// NodeRunner returns the node of the address; if not found, it returns nil.
+func (s *Simulation) NodeRunner(address string) *NodeRunner {
+	for _, nr := range s.nodeRunners {
+		if nr.Node().Address() == address {
+			return nr
+		}
+	}
+
+	return nil
+}
+
+// Proposer returns the proposer of the block of the height at the round; the
+// ballot is based on the previous block, so the proposer is selected by the
+// previous height.
+func (s *Simulation) Proposer(height, round uint64) string {
+	return s.nodeRunners[0].Consensus().SelectProposer(height-1, round)
+}
+
+// SubmitTransaction puts the transaction into the transaction pools of all
+// the live nodes like it is received from the client.
+func (s *Simulation) SubmitTransaction(tx transaction.Transaction) (err error) {
+	var body []byte
+	if body, err = tx.Serialize(); err != nil {
+		return
+	}
+
+	for _, nr := range s.nodeRunners {
+		if s.IsKilled(nr.Node().Address()) {
+			continue
+		}
+
+		checker := &MessageChecker{
+			DefaultChecker:  common.DefaultChecker{Funcs: HandleTransactionCheckerFuncsWithoutBroadcast},
+			Consensus:       nr.Consensus(),
+			TransactionPool: nr.TransactionPool,
+			Storage:         nr.Storage(),
+			LocalNode:       nr.Node(),
+			NetworkID:       nr.NetworkID(),
+			Message:         common.NewNetworkMessage(common.TransactionMessage, body),
+			Log:             nr.Log(),
+			Conf:            nr.Config(),
+			Metrics:         nr.MessageMetrics(),
+		}
+		if err = common.RunChecker(checker, common.DefaultDeferFunc); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// WaitHeight waits until all the live nodes confirm the block of the height.
+func (s *Simulation) WaitHeight(height uint64, timeout time.Duration) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	expired := time.After(timeout)
+
+	for {
+		reached := true
+		for _, nr := range s.nodeRunners {
+			if s.IsKilled(nr.Node().Address()) {
+				continue
+			}
+			if nr.Consensus().LatestBlock().Height < height {
+				reached = false
+				break
+			}
+		}
+		if reached {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-expired:
+			return fmt.Errorf("timed out to wait height %d: %v", height, s.heights())
+		}
+	}
+}
+
+// CheckAgreement checks every height has the same block in all the nodes,
+// which confirmed the height; the killed nodes are also checked by the
+// blocks before they are killed.
+func (s *Simulation) CheckAgreement() error {
+	var top uint64
+	for _, nr := range s.nodeRunners {
+		if h := nr.Consensus().LatestBlock().Height; h > top {
+			top = h
+		}
+	}
+
+	for height := common.GenesisBlockHeight; height <= top; height++ {
+		var hash, address string
+		for _, nr := range s.nodeRunners {
+			blk, err := block.GetBlockByHeight(nr.Storage(), height)
+			if err != nil {
+				continue
+			}
+			if len(hash) < 1 {
+				hash, address = blk.Hash, nr.Node().Address()
+				continue
+			}
+			if blk.Hash != hash {
+				return fmt.Errorf(
+					"block of height %d does not match: %s has %s, but %s has %s",
+					height, address, hash, nr.Node().Address(), blk.Hash,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Simulation) heights() map[string]uint64 {
+	heights := map[string]uint64{}
+	for _, nr := range s.nodeRunners {
+		heights[nr.Node().Address()] = nr.Consensus().LatestBlock().Height
+	}
+
+	return heights
+}
+
+func (s *Simulation) liveAddresses() (addresses []string) {
+	s.RLock()
+	defer s.RUnlock()
+
+	for _, nr := range s.nodeRunners {
+		if address := nr.Node().Address(); !s.killed[address] {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return
+}
+
+func (s *Simulation) broadcast(from string, message common.Message) {
+	body, err := message.Serialize()
+	if err != nil {
+		return
+	}
+	m := common.NewNetworkMessage(message.GetType(), body)
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.stopped || s.killed[from] {
+		return
+	}
+
+	for _, nr := range s.nodeRunners {
+		to := nr.Node().Address()
+		if s.killed[to] {
+			continue
+		}
+
+		if to == from {
+			go s.deliver(to, m)
+			continue
+		}
+
+		if s.conf.DropRate > 0 && s.rand.Float64() < s.conf.DropRate {
+			continue
+		}
+
+		delay := s.conf.Latency
+		if s.conf.LatencyJitter > 0 {
+			delay += time.Duration(s.rand.Int63n(int64(s.conf.LatencyJitter)))
+		}
+		time.AfterFunc(delay, func() { s.deliver(to, m) })
+	}
+}
+
+func (s *Simulation) deliver(to string, m common.NetworkMessage) {
+	select {
+	case s.inboxes[to] <- m:
+	case <-s.stop:
+	}
+}
+
+func (s *Simulation) handleMessages(nr *NodeRunner) {
+	defer s.wg.Done()
+
+	inbox := s.inboxes[nr.Node().Address()]
+	for {
+		select {
+		case <-s.stop:
+			return
+		case m := <-inbox:
+			if s.IsKilled(nr.Node().Address()) {
+				continue
+			}
+			nr.handleMessage(m)
+		}
+	}
+}
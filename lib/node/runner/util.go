@@ -64,6 +64,60 @@ func GetCommonAccount(st *storage.LevelDBBackend) (account *block.BlockAccount,
 	return getGenesisAccount(st, 1)
 }
 
+// VerifyCommonAccount checks the common account, which receives the collected
+// fee and the inflation, exists in the storage. It is created by the genesis
+// block, so the missing one means the wrong address; the coins sent to it
+// would be lost.
+func VerifyCommonAccount(st *storage.LevelDBBackend, address string) (err error) {
+	var exists bool
+	if exists, err = block.ExistsBlockAccount(st, address); err != nil {
+		return
+	} else if !exists {
+		err = errors.CommonAccountDoesNotExist.Clone().SetData("address", address)
+		return
+	}
+
+	return
+}
+
+// LoadNetworkParams loads the network parameters from the genesis block. The
+// configured fields of `params` are verified against the genesis block with
+// `networkID`; if not matched, the node can not be started in this network.
+func LoadNetworkParams(st *storage.LevelDBBackend, networkID []byte, params common.NetworkParams) (loaded common.NetworkParams, err error) {
+	var commonAccount *block.BlockAccount
+	if commonAccount, err = GetCommonAccount(st); err != nil {
+		return
+	}
+	loaded.CommonAccount = commonAccount.Address
+
+	if loaded.InitialBalance, err = GetGenesisBalance(st); err != nil {
+		return
+	}
+
+	if params.IsEmpty() {
+		return
+	}
+
+	if len(params.CommonAccount) > 0 {
+		loaded.CommonAccount = params.CommonAccount
+	}
+	if params.InitialBalance != 0 {
+		loaded.InitialBalance = params.InitialBalance
+	}
+
+	if err = block.VerifyGenesis(st, block.GenesisParams{
+		NetworkID:      networkID,
+		InitialBalance: loaded.InitialBalance,
+		CommonAccount:  loaded.CommonAccount,
+	}); err != nil {
+		return
+	}
+
+	err = VerifyCommonAccount(st, loaded.CommonAccount)
+
+	return
+}
+
 func GetGenesisBalance(st *storage.LevelDBBackend) (balance common.Amount, err error) {
 	var bt block.BlockTransaction
 	if bt, err = getGenesisTransaction(st); err != nil {
@@ -110,14 +164,15 @@ func NewNodeInfo(nr *NodeRunner) node.NodeInfo {
 		Validators: localNode.GetValidators(),
 	}
 
+	conf := nr.Config()
 	policy := node.NodePolicy{
 		NetworkID:                 string(nr.NetworkID()),
-		InitialBalance:            nr.InitialBalance,
+		InitialBalance:            nr.NetworkParams().InitialBalance,
 		BaseReserve:               common.BaseReserve,
 		BaseFee:                   common.BaseFee,
-		BlockTime:                 nr.Conf.BlockTime,
-		OperationsLimit:           nr.Conf.OpsLimit,
-		TransactionsLimit:         nr.Conf.TxsLimit,
+		BlockTime:                 conf.BlockTime,
+		OperationsLimit:           conf.OpsLimit,
+		TransactionsLimit:         conf.TxsLimit,
 		GenesisBlockConfirmedTime: common.GenesisBlockConfirmedTime,
 		InflationRatio:            common.InflationRatioString,
 		BlockHeightEndOfInflation: common.BlockHeightEndOfInflation,
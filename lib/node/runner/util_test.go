@@ -1,10 +1,15 @@
 package runner
 
 import (
+	"context"
 	"testing"
 
 	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network"
 	"boscoin.io/sebak/lib/storage"
 
 	"github.com/stretchr/testify/require"
@@ -50,3 +55,131 @@ func TestGetInitialBalance(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, initialBalance, fetchedInitialBalance)
 }
+
+func TestLoadNetworkParams(t *testing.T) {
+	st := storage.NewTestStorage()
+
+	initialBalance := common.Amount(99)
+	genesisAccount := block.NewBlockAccount(block.GenesisKP.Address(), initialBalance)
+	genesisAccount.MustSave(st)
+
+	commonAccount := block.NewBlockAccount(block.CommonKP.Address(), 0)
+	commonAccount.MustSave(st)
+
+	block.MakeGenesisBlock(st, *genesisAccount, *commonAccount, networkID)
+
+	expected := common.NetworkParams{
+		CommonAccount:  commonAccount.Address,
+		InitialBalance: initialBalance,
+	}
+
+	checkMismatch := func(networkID []byte, params common.NetworkParams, field string) {
+		_, err := LoadNetworkParams(st, networkID, params)
+		require.Error(t, err)
+
+		e, ok := err.(*errors.Error)
+		require.True(t, ok)
+		require.Equal(t, errors.GenesisNotMatched.Code, e.Code)
+		require.Equal(t, field, e.Data["field"])
+	}
+
+	{ // not configured; loaded from genesis
+		loaded, err := LoadNetworkParams(st, networkID, common.NetworkParams{})
+		require.NoError(t, err)
+		require.Equal(t, expected, loaded)
+	}
+
+	{ // configured
+		loaded, err := LoadNetworkParams(st, networkID, expected)
+		require.NoError(t, err)
+		require.Equal(t, expected, loaded)
+	}
+
+	{ // partially configured
+		loaded, err := LoadNetworkParams(st, networkID, common.NetworkParams{CommonAccount: commonAccount.Address})
+		require.NoError(t, err)
+		require.Equal(t, expected, loaded)
+	}
+
+	{ // wrong common account
+		params := expected
+		params.CommonAccount = keypair.Random().Address()
+		checkMismatch(networkID, params, "common_account")
+	}
+
+	{ // wrong initial balance
+		checkMismatch(networkID, common.NetworkParams{InitialBalance: initialBalance + 1}, "initial_balance")
+	}
+
+	{ // genesis of the other network
+		checkMismatch([]byte("sebak-other-network"), expected, "network_id")
+	}
+}
+
+func TestVerifyCommonAccount(t *testing.T) {
+	st := block.InitTestBlockchain()
+
+	commonAccount, err := GetCommonAccount(st)
+	require.NoError(t, err)
+	require.NoError(t, VerifyCommonAccount(st, commonAccount.Address))
+
+	address := keypair.Random().Address()
+	err = VerifyCommonAccount(st, address)
+	require.Error(t, err)
+	e, ok := err.(*errors.Error)
+	require.True(t, ok)
+	require.Equal(t, errors.CommonAccountDoesNotExist.Code, e.Code)
+	require.Equal(t, address, e.Data["address"])
+}
+
+// TestMakeNewBallotWrongCommonAccount checks the proposer transaction is not
+// made for the common account, which does not exist.
+func TestMakeNewBallotWrongCommonAccount(t *testing.T) {
+	nr, _, _ := createNodeRunnerForTesting(1, common.NewConfig(), nil)
+
+	{ // correct
+		_, err := nr.makeNewBallot(context.Background(), 0)
+		require.NoError(t, err)
+	}
+
+	{ // wrong
+		nr.networkParams.CommonAccount = keypair.Random().Address()
+		_, err := nr.makeNewBallot(context.Background(), 0)
+		require.Error(t, err)
+		e, ok := err.(*errors.Error)
+		require.True(t, ok)
+		require.Equal(t, errors.CommonAccountDoesNotExist.Code, e.Code)
+	}
+}
+
+// TestNewNodeRunnerWrongNetworkParams checks the node can not be started with
+// the wrong common account against the genesis block.
+func TestNewNodeRunnerWrongNetworkParams(t *testing.T) {
+	newNodeRunner := func(params common.NetworkParams) (*NodeRunner, error) {
+		_, n, localNode := network.CreateMemoryNetwork(nil)
+
+		policy, _ := consensus.NewDefaultVotingThresholdPolicy(66)
+		localNode.AddValidators(localNode.ConvertToValidator())
+		connectionManager := network.NewValidatorConnectionManager(localNode, n, policy)
+
+		conf := common.NewConfig()
+		conf.NetworkParams = params
+
+		st := block.InitTestBlockchain()
+		is, _ := consensus.NewISAAC(networkID, localNode, policy, connectionManager, st, conf, nil)
+
+		return NewNodeRunner(string(networkID), localNode, policy, n, is, st, conf)
+	}
+
+	{ // wrong common account
+		_, err := newNodeRunner(common.NetworkParams{CommonAccount: keypair.Random().Address()})
+		require.Error(t, err)
+		require.Equal(t, errors.GenesisNotMatched.Code, err.(*errors.Error).Code)
+	}
+
+	{ // correct common account
+		nr, err := newNodeRunner(common.NetworkParams{CommonAccount: block.CommonKP.Address()})
+		require.NoError(t, err)
+		require.Equal(t, block.CommonKP.Address(), nr.NetworkParams().CommonAccount)
+	}
+}
@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/node"
+)
+
+// validatorSetChanger is implemented by the `network.ConnectionManager`,
+// which connects the validators added by the validator set changes, like
+// `network.ValidatorConnectionManager`.
+type validatorSetChanger interface {
+	AddValidator(*node.Validator)
+	RemoveValidator(string)
+}
+
+// ApplyValidatorChanges applies the validator set changes of the blocks,
+// which are stored after the last applied one. The changes of the height `H`
+// are applied after the block of `H` is stored, so every node selects the
+// proposer and counts the threshold of the next rounds by the same validator
+// set. If the threshold is not safe with the changed validators, it returns
+// `errors.VotingThresholdUnsafe`.
+func (nr *NodeRunner) ApplyValidatorChanges() (err error) {
+	latest := block.GetLatestBlock(nr.storage)
+	if latest.Height <= nr.validatorSetHeight {
+		return
+	}
+
+	var changes []block.BlockValidatorChange
+	if changes, err = block.GetBlockValidatorChanges(nr.storage, nr.validatorSetHeight+1, latest.Height); err != nil {
+		return
+	}
+
+	changer, hasChanger := nr.connectionManager.(validatorSetChanger)
+	for _, c := range changes {
+		if c.Remove {
+			if hasChanger {
+				changer.RemoveValidator(c.Address)
+			} else {
+				nr.localNode.RemoveValidators(c.Address)
+			}
+			nr.log.Debug("validator removed", "change", c)
+			continue
+		}
+
+		var endpoint *common.Endpoint
+		if endpoint, err = common.ParseEndpoint(c.Endpoint); err != nil {
+			return
+		}
+
+		var v *node.Validator
+		if v, err = node.NewValidator(c.Address, endpoint, ""); err != nil {
+			return
+		}
+		if hasChanger {
+			changer.AddValidator(v)
+		} else {
+			nr.localNode.AddValidators(v)
+		}
+		nr.log.Debug("validator added", "change", c)
+	}
+
+	nr.validatorSetHeight = latest.Height
+	nr.policy.SetValidators(len(nr.localNode.GetValidators()))
+
+	// the threshold may not be safe with the changed validators
+	if len(changes) > 0 {
+		if err = consensus.ValidateThreshold(nr.policy); err != nil {
+			nr.log.Error(
+				"threshold is unsafe for the changed validators",
+				"threshold", nr.policy.Threshold(),
+				"required", consensus.BFTRequiredThreshold(nr.policy.Validators()),
+				"validators", nr.policy.Validators(),
+			)
+			return
+		}
+	}
+
+	return
+}
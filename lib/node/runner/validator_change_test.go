@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// TestValidatorSetChangeAtHeight adds the validator effective at the height
+// `H` and checks the proposer selection and the threshold change only after
+// the block of `H` is stored.
+func TestValidatorSetChangeAtHeight(t *testing.T) {
+	conf := common.NewConfig()
+	nr, _, _ := createNodeRunnerForTesting(3, conf, nil)
+
+	is, err := consensus.NewISAAC(networkID, nr.localNode, nr.policy, nr.connectionManager, nr.storage, conf, nil)
+	require.NoError(t, err)
+
+	genesis, err := GetGenesisAccount(nr.storage)
+	require.NoError(t, err)
+
+	latest := block.GetLatestBlock(nr.storage)
+	h := latest.Height + 2
+
+	kp := keypair.Random()
+	op, err := operation.NewOperation(
+		operation.NewValidatorSetChange(kp.Address(), "https://localhost:12345", false, h),
+	)
+	require.NoError(t, err)
+
+	{ // only the genesis account can change the validator set
+		other := block.NewBlockAccount(keypair.Random().Address(), common.Amount(1))
+		require.Equal(t, errors.ValidatorSetChangeNotAllowed, ValidateOp(nr.storage, other, op))
+	}
+	{ // the height should be in future
+		passed, err := operation.NewOperation(
+			operation.NewValidatorSetChange(kp.Address(), "https://localhost:12345", false, latest.Height),
+		)
+		require.NoError(t, err)
+		require.Equal(t, errors.ValidatorSetChangeHeightPassed, ValidateOp(nr.storage, genesis, passed))
+	}
+
+	require.NoError(t, ValidateOp(nr.storage, genesis, op))
+	require.NoError(t, finishOperation(nr.storage, genesis.Address, op, nr.log))
+
+	pending, err := block.GetPendingBlockValidatorChanges(nr.storage, latest.Height)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(pending))
+	require.Equal(t, kp.Address(), pending[0].Address)
+
+	proposers := func(height uint64) map[string]bool {
+		selected := map[string]bool{}
+		for round := uint64(0); round < 4; round++ {
+			selected[is.SelectProposer(height, round)] = true
+		}
+		return selected
+	}
+
+	for latest.Height < h {
+		require.False(t, proposers(latest.Height)[kp.Address()])
+		require.Equal(t, 3, nr.policy.Validators())
+
+		blk := block.TestMakeNewBlockWithPrevBlock(latest, nil)
+		blk.MustSave(nr.storage)
+		require.NoError(t, nr.ApplyValidatorChanges())
+		latest = blk
+	}
+
+	// the new validator is selected from the height `H`
+	require.True(t, proposers(h)[kp.Address()])
+	require.Equal(t, 4, nr.policy.Validators())
+	require.True(t, nr.localNode.HasValidators(kp.Address()))
+
+	pending, err = block.GetPendingBlockValidatorChanges(nr.storage, latest.Height)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(pending))
+}
+
+// TestValidatorSetChangeUnsafeThreshold checks the threshold is validated
+// again, when the validator set is changed.
+func TestValidatorSetChangeUnsafeThreshold(t *testing.T) {
+	// with the threshold 66, 5 validators are safe, but 6 are not
+	nr, _, _ := createNodeRunnerForTesting(5, common.NewConfig(), nil)
+	require.NoError(t, consensus.ValidateThreshold(nr.policy))
+
+	latest := block.GetLatestBlock(nr.storage)
+	require.NoError(t, block.BlockValidatorChange{
+		Height:   latest.Height + 1,
+		Address:  keypair.Random().Address(),
+		Endpoint: "https://localhost:12345",
+	}.Save(nr.storage))
+
+	blk := block.TestMakeNewBlockWithPrevBlock(latest, nil)
+	blk.MustSave(nr.storage)
+
+	err := nr.ApplyValidatorChanges()
+	require.Error(t, err)
+	require.Equal(t, errors.VotingThresholdUnsafe.Code, err.(*errors.Error).Code)
+	require.Equal(t, 6, nr.policy.Validators())
+}
@@ -90,10 +90,20 @@ func (bb *BatchCore) Discard() {
 }
 
 func (bb *BatchCore) Commit() (err error) {
+	return bb.commit(nil)
+}
+
+// CommitSync writes the batch like `Commit()`, but it returns after the write
+// is synced to the disk, so the committed records survive the crash.
+func (bb *BatchCore) CommitSync() (err error) {
+	return bb.commit(&leveldbOpt.WriteOptions{Sync: true})
+}
+
+func (bb *BatchCore) commit(opt *leveldbOpt.WriteOptions) (err error) {
 	bb.Lock()
 	defer bb.Unlock()
 
-	err = bb.core.Write(bb.batch, nil)
+	err = bb.core.Write(bb.batch, opt)
 	if err != nil {
 		return
 	}
@@ -110,3 +110,18 @@ func TestBatchBackendDelete(t *testing.T) {
 		require.Equal(t, errors.StorageRecordDoesNotExist, err)
 	}
 }
+
+func TestBatchBackendCommitSync(t *testing.T) {
+	st := NewTestStorage()
+	defer st.Close()
+
+	bt, err := st.OpenBatch()
+	require.NoError(t, err)
+	require.NoError(t, bt.New("showme", 1))
+
+	require.NoError(t, bt.CommitSync())
+
+	var fetched int
+	require.NoError(t, st.Get("showme", &fetched))
+	require.Equal(t, 1, fetched)
+}
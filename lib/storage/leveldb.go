@@ -95,6 +95,33 @@ func (st *LevelDBBackend) OpenBatch() (*LevelDBBackend, error) {
 	}, nil
 }
 
+// OpenSnapshot returns the read-only backend of the current state; taking the
+// snapshot is cheap, so the long read can be done without blocking the
+// writes. `Release()` must be called after use.
+func (st *LevelDBBackend) OpenSnapshot() (*LevelDBBackend, error) {
+	snapshot, err := st.DB.GetSnapshot()
+	if err != nil {
+		return nil, setLevelDBCoreError(err)
+	}
+
+	return &LevelDBBackend{
+		DB:   st.DB,
+		Core: &SnapshotCore{snapshot: snapshot},
+	}, nil
+}
+
+// Release releases the snapshot of `OpenSnapshot()`.
+func (st *LevelDBBackend) Release() error {
+	sc, ok := st.Core.(*SnapshotCore)
+	if !ok {
+		return errors.NotCommittable
+	}
+
+	sc.Release()
+
+	return nil
+}
+
 func (st *LevelDBBackend) Discard() error {
 	var committable Committable
 	var ok bool
@@ -117,6 +144,16 @@ func (st *LevelDBBackend) Commit() error {
 	return setLevelDBCoreError(committable.Commit())
 }
 
+// CommitSync commits like `Commit()` and syncs the batch to the disk before
+// returning; the other committables are committed by `Commit()`.
+func (st *LevelDBBackend) CommitSync() error {
+	if bc, ok := st.Core.(*BatchCore); ok {
+		return setLevelDBCoreError(bc.CommitSync())
+	}
+
+	return st.Commit()
+}
+
 func (st *LevelDBBackend) makeKey(key string) []byte {
 	return []byte(key)
 }
@@ -148,6 +185,12 @@ func (st *LevelDBBackend) GetRaw(k string) (b []byte, err error) {
 	return
 }
 
+// PutRaw stores the raw bytes without encoding; unlike `New()` and `Set()`,
+// it does not check whether the record exists or not.
+func (st *LevelDBBackend) PutRaw(k string, b []byte) (err error) {
+	return setLevelDBCoreError(st.Core.Put(st.makeKey(k), b, nil))
+}
+
 func (st *LevelDBBackend) Get(k string, i interface{}) (err error) {
 	var b []byte
 	if b, err = st.GetRaw(k); err != nil {
@@ -288,7 +331,34 @@ func (st *LevelDBBackend) Remove(k string) (err error) {
 	return
 }
 
+// GetIterator returns the iterator of the items under the prefix. The
+// iteration stops after `ListOptions.Limit()` or `ListOptions.MaxItems()`
+// items, whichever is smaller; see `IterItem` for the truncation.
 func (st *LevelDBBackend) GetIterator(prefix string, option ListOptions) (func() (IterItem, bool), func()) {
+	var dbRange *leveldbUtil.Range
+	if len(prefix) > 0 {
+		dbRange = leveldbUtil.BytesPrefix(st.makeKey(prefix))
+	}
+
+	return st.getIterator(dbRange, option)
+}
+
+// GetIteratorRange is like `GetIterator()`, but it iterates only the items
+// under the prefix from `prefix+start` inclusively to `prefix+limit`
+// exclusively. The empty `start` or `limit` means the boundary of the prefix.
+func (st *LevelDBBackend) GetIteratorRange(prefix, start, limit string, option ListOptions) (func() (IterItem, bool), func()) {
+	dbRange := leveldbUtil.BytesPrefix(st.makeKey(prefix))
+	if len(start) > 0 {
+		dbRange.Start = st.makeKey(prefix + start)
+	}
+	if len(limit) > 0 {
+		dbRange.Limit = st.makeKey(prefix + limit)
+	}
+
+	return st.getIterator(dbRange, option)
+}
+
+func (st *LevelDBBackend) getIterator(dbRange *leveldbUtil.Range, option ListOptions) (func() (IterItem, bool), func()) {
 	var reverse = false
 	var cursor []byte
 	var limit uint64 = 0
@@ -296,11 +366,9 @@ func (st *LevelDBBackend) GetIterator(prefix string, option ListOptions) (func()
 		reverse = option.Reverse()
 		cursor = option.Cursor()
 		limit = option.Limit()
-	}
-
-	var dbRange *leveldbUtil.Range
-	if len(prefix) > 0 {
-		dbRange = leveldbUtil.BytesPrefix(st.makeKey(prefix))
+		if maxItems := option.MaxItems(); maxItems != 0 && (limit == 0 || maxItems < limit) {
+			limit = maxItems
+		}
 	}
 
 	iter := st.Core.NewIterator(dbRange, nil)
@@ -343,7 +411,7 @@ func (st *LevelDBBackend) GetIterator(prefix string, option ListOptions) (func()
 			if limit != 0 && n >= limit {
 				defer iter.Release()
 				n++
-				return IterItem{N: n, Key: iter.Key(), Value: iter.Value()}, false
+				return IterItem{N: n, Key: iter.Key(), Value: iter.Value(), Truncated: true}, false
 			}
 			n++
 			return IterItem{N: n, Key: iter.Key(), Value: iter.Value()}, true
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
 	"sort"
 	"testing"
@@ -236,6 +237,40 @@ func TestLevelDBIteratorSeek(t *testing.T) {
 	return
 }
 
+func TestLevelDBIteratorRange(t *testing.T) {
+	st := NewTestStorage()
+	defer st.Close()
+
+	for _, prefix := range []string{"a-", "b-"} {
+		for i := 0; i < 10; i++ {
+			st.New(fmt.Sprintf("%s%d", prefix, i), 0)
+		}
+	}
+
+	collect := func(start, limit string, option ListOptions) (collected []string) {
+		it, closeFunc := st.GetIteratorRange("a-", start, limit, option)
+		defer closeFunc()
+		for {
+			v, hasNext := it()
+			if !hasNext {
+				break
+			}
+			collected = append(collected, string(v.Key))
+		}
+		return
+	}
+
+	if expected, collected := []string{"a-3", "a-4", "a-5"}, collect("3", "6", nil); !reflect.DeepEqual(expected, collected) {
+		t.Errorf("failed to fetch the range; expected=%v collected=%v", expected, collected)
+	}
+	if expected, collected := []string{"a-8", "a-9"}, collect("8", "", nil); !reflect.DeepEqual(expected, collected) {
+		t.Errorf("failed to fetch the range without limit; expected=%v collected=%v", expected, collected)
+	}
+	if expected, collected := []string{"a-1", "a-0"}, collect("", "2", &DefaultListOptions{reverse: true}); !reflect.DeepEqual(expected, collected) {
+		t.Errorf("failed to fetch the range in reverse; expected=%v collected=%v", expected, collected)
+	}
+}
+
 func TestLevelDBIteratorLimit(t *testing.T) {
 	st := NewTestStorage()
 	defer st.Close()
@@ -273,6 +308,82 @@ func TestLevelDBIteratorLimit(t *testing.T) {
 	return
 }
 
+func TestLevelDBIteratorMaxItems(t *testing.T) {
+	st := NewTestStorage()
+	defer st.Close()
+
+	total := 250
+
+	var expected []string
+	for i := 0; i < total; i++ {
+		key := fmt.Sprintf("%03d", i)
+		st.New(key, 0)
+
+		expected = append(expected, key)
+	}
+
+	collect := func(options ListOptions) (collected []string, last IterItem) {
+		it, closeFunc := st.GetIterator("", options)
+		defer closeFunc()
+
+		for {
+			v, hasNext := it()
+			if !hasNext {
+				last = v
+				return
+			}
+			collected = append(collected, string(v.Key))
+		}
+	}
+
+	{ // truncated by MaxItems and resumed by the cursor
+		var collected []string
+		var cursor []byte
+		for i := 0; i < 2; i++ {
+			c, last := collect(NewDefaultListOptions(false, cursor, 0).SetMaxItems(100))
+			require.Equal(t, 100, len(c))
+			require.True(t, last.Truncated)
+			require.Equal(t, expected[(i+1)*100], string(last.Key))
+
+			collected = append(collected, c...)
+			cursor = last.Key
+		}
+
+		// the last page reaches the end of data
+		c, last := collect(NewDefaultListOptions(false, cursor, 0).SetMaxItems(100))
+		require.Equal(t, 50, len(c))
+		require.False(t, last.Truncated)
+		require.Nil(t, last.Key)
+
+		collected = append(collected, c...)
+		require.Equal(t, expected, collected)
+	}
+
+	{ // MaxItems is over the limit
+		c, last := collect(NewDefaultListOptions(false, nil, 10).SetMaxItems(100))
+		require.Equal(t, expected[:10], c)
+		require.True(t, last.Truncated)
+	}
+
+	{ // limit is over MaxItems
+		c, last := collect(NewDefaultListOptions(false, nil, 200).SetMaxItems(20))
+		require.Equal(t, expected[:20], c)
+		require.True(t, last.Truncated)
+	}
+
+	{ // MaxItems is exactly the number of the items
+		c, last := collect(NewDefaultListOptions(false, nil, 0).SetMaxItems(uint64(total)))
+		require.Equal(t, expected, c)
+		require.False(t, last.Truncated)
+	}
+
+	{ // from query
+		options, err := NewDefaultListOptionsFromQuery(url.Values{"limit": []string{"100000"}})
+		require.NoError(t, err)
+		require.Equal(t, DefaultMaxItemsListOptions, options.MaxItems())
+	}
+}
+
 func TestLevelDBIteratorReverseOrder(t *testing.T) {
 	st := NewTestStorage()
 	defer st.Close()
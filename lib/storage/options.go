@@ -9,6 +9,11 @@ import (
 
 var DefaultMaxLimitListOptions uint64 = 100
 
+// DefaultMaxItemsListOptions is the maximum number of the items, which are
+// iterated by the `ListOptions` from the query regardless of the `limit`
+// query, so the huge `limit` does not load the unbounded items into memory.
+var DefaultMaxItemsListOptions uint64 = 1000
+
 type ListOptions interface {
 	Reverse() bool
 	SetReverse(bool) ListOptions
@@ -16,6 +21,8 @@ type ListOptions interface {
 	SetCursor([]byte) ListOptions
 	Limit() uint64
 	SetLimit(uint64) ListOptions
+	MaxItems() uint64
+	SetMaxItems(uint64) ListOptions
 	Template() string
 	URLValues() url.Values
 	Encode() string
@@ -25,6 +32,9 @@ type DefaultListOptions struct {
 	reverse bool
 	cursor  []byte
 	limit   uint64
+
+	// maxItems is not exposed to the query; `0` means no limit.
+	maxItems uint64
 }
 
 func NewDefaultListOptions(reverse bool, cursor []byte, limit uint64) *DefaultListOptions {
@@ -61,9 +71,10 @@ func NewDefaultListOptionsFromQuery(v url.Values) (options *DefaultListOptions,
 	}
 
 	options = &DefaultListOptions{
-		reverse: reverse,
-		cursor:  cursor,
-		limit:   limit,
+		reverse:  reverse,
+		cursor:   cursor,
+		limit:    limit,
+		maxItems: DefaultMaxItemsListOptions,
 	}
 
 	return
@@ -96,6 +107,15 @@ func (o *DefaultListOptions) SetLimit(l uint64) ListOptions {
 	return o
 }
 
+func (o DefaultListOptions) MaxItems() uint64 {
+	return o.maxItems
+}
+
+func (o *DefaultListOptions) SetMaxItems(m uint64) ListOptions {
+	o.maxItems = m
+	return o
+}
+
 func (o DefaultListOptions) Template() string {
 	return "{?cursor,limit,order}"
 }
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	leveldbIterator "github.com/syndtr/goleveldb/leveldb/iterator"
+	leveldbOpt "github.com/syndtr/goleveldb/leveldb/opt"
+	leveldbUtil "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// SnapshotCore is the read-only `LevelDBCore` of the point-in-time state of
+// the database; the writes after the snapshot is taken are not visible.
+type SnapshotCore struct {
+	snapshot *leveldb.Snapshot
+}
+
+func (sc *SnapshotCore) Has(key []byte, opt *leveldbOpt.ReadOptions) (bool, error) {
+	return sc.snapshot.Has(key, opt)
+}
+
+func (sc *SnapshotCore) Get(key []byte, opt *leveldbOpt.ReadOptions) ([]byte, error) {
+	return sc.snapshot.Get(key, opt)
+}
+
+func (sc *SnapshotCore) NewIterator(r *leveldbUtil.Range, opt *leveldbOpt.ReadOptions) leveldbIterator.Iterator {
+	return sc.snapshot.NewIterator(r, opt)
+}
+
+func (sc *SnapshotCore) Put([]byte, []byte, *leveldbOpt.WriteOptions) error {
+	return leveldb.ErrReadOnly
+}
+
+func (sc *SnapshotCore) Write(*leveldb.Batch, *leveldbOpt.WriteOptions) error {
+	return leveldb.ErrReadOnly
+}
+
+func (sc *SnapshotCore) Delete([]byte, *leveldbOpt.WriteOptions) error {
+	return leveldb.ErrReadOnly
+}
+
+// Release releases the snapshot; after release, it can not be used.
+func (sc *SnapshotCore) Release() {
+	sc.snapshot.Release()
+}
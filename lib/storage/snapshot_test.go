@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestSnapshotBackend(t *testing.T) {
+	st := NewTestStorage()
+	defer st.Close()
+
+	require.NoError(t, st.New("showme", 1))
+
+	snapshot, err := st.OpenSnapshot()
+	require.NoError(t, err)
+	defer snapshot.Release()
+
+	require.NoError(t, st.Set("showme", 2))
+	require.NoError(t, st.New("findme", 3))
+
+	var v int
+	require.NoError(t, snapshot.Get("showme", &v))
+	require.Equal(t, 1, v)
+
+	exists, err := snapshot.Has("findme")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// read-only
+	require.Error(t, snapshot.New("findme", 4))
+	require.Equal(t, errors.NotCommittable, snapshot.Commit())
+
+	require.NoError(t, st.Get("showme", &v))
+	require.Equal(t, 2, v)
+}
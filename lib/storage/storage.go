@@ -12,10 +12,15 @@ var SupportedStorageType []string = []string{
 	"file",
 }
 
+// IterItem is the item of `LevelDBBackend.GetIterator()`. When the iteration
+// is stopped by `ListOptions.Limit()` or `ListOptions.MaxItems()`, the next
+// item is returned with `false` and `Truncated`; its `Key` is the cursor to
+// resume the iteration. At the end of data, the empty item is returned.
 type IterItem struct {
-	N     uint64
-	Key   []byte
-	Value []byte
+	N         uint64
+	Key       []byte
+	Value     []byte
+	Truncated bool
 }
 
 type Item struct {
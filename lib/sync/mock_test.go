@@ -6,9 +6,14 @@ import (
 	"net"
 	"net/http"
 
+	"github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/network"
 	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/transaction"
 )
 
 type mockConnectionManager struct {
@@ -71,3 +76,12 @@ type mockValidator struct {
 func (v mockValidator) Validate(ctx context.Context, si *SyncInfo) error {
 	return v.validateFunc(ctx, si)
 }
+
+type mockBlockFinisher struct {
+	finished []block.Block
+}
+
+func (f *mockBlockFinisher) FinishBlock(blk block.Block, _ ballot.ProposerTransaction, _ []*transaction.Transaction, _ log15.Logger) error {
+	f.finished = append(f.finished, blk)
+	return nil
+}
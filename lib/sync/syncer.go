@@ -94,7 +94,7 @@ func NewSyncer(st *storage.LevelDBBackend,
 	})
 	s.fetcher = fetcher
 
-	validator := NewBlockValidator(nw, st, networkID, cfg, func(v *BlockValidator) {
+	validator := NewBlockValidator(nw, st, networkID, localNode, cfg, func(v *BlockValidator) {
 		v.logger = s.logger
 	})
 	s.validator = validator
@@ -102,6 +102,15 @@ func NewSyncer(st *storage.LevelDBBackend,
 	return s
 }
 
+// SetBlockFinisher sets the `BlockFinisher` of `BlockValidator`; the node
+// runner is created after the syncer, so it is set before `Start()`.
+func (s *Syncer) SetBlockFinisher(f BlockFinisher) *Syncer {
+	if v, ok := s.validator.(*BlockValidator); ok {
+		v.SetBlockFinisher(f)
+	}
+	return s
+}
+
 func (s *Syncer) Stop() error {
 	s.cancelFunc()
 	c := make(chan struct{})
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/inconshreveable/log15"
+
 	"boscoin.io/sebak/lib/ballot"
 	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/transaction"
@@ -44,3 +46,9 @@ type Fetcher interface {
 type Validator interface {
 	Validate(context.Context, *SyncInfo) error
 }
+
+// BlockFinisher stores the verified block and runs the same steps after it
+// like the consensus does; it is `runner.NodeRunner`.
+type BlockFinisher interface {
+	FinishBlock(block.Block, ballot.ProposerTransaction, []*transaction.Transaction, log15.Logger) error
+}
@@ -11,19 +11,23 @@ import (
 	"boscoin.io/sebak/lib/common/observer"
 	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/node"
 	"boscoin.io/sebak/lib/node/runner"
 	"boscoin.io/sebak/lib/storage"
-	"boscoin.io/sebak/lib/voting"
 
 	"github.com/inconshreveable/log15"
 )
 
 //TODO(anarcher) another name is Finisher
 
+var errBlockFinisherNotSet = errors.New("block finisher is not set")
+
 type BlockValidator struct {
 	network   network.Network
 	storage   *storage.LevelDBBackend
+	localNode *node.LocalNode
 	commonCfg common.Config
+	finisher  BlockFinisher
 
 	networkID []byte
 
@@ -33,10 +37,11 @@ type BlockValidator struct {
 
 type BlockValidatorOption func(*BlockValidator)
 
-func NewBlockValidator(nw network.Network, ldb *storage.LevelDBBackend, networkID []byte, cfg common.Config, opts ...BlockValidatorOption) *BlockValidator {
+func NewBlockValidator(nw network.Network, ldb *storage.LevelDBBackend, networkID []byte, localNode *node.LocalNode, cfg common.Config, opts ...BlockValidatorOption) *BlockValidator {
 	v := &BlockValidator{
 		network:              nw,
 		storage:              ldb,
+		localNode:            localNode,
 		networkID:            networkID,
 		prevBlockWaitTimeout: 10 * time.Minute,
 		commonCfg:            cfg,
@@ -51,6 +56,13 @@ func NewBlockValidator(nw network.Network, ldb *storage.LevelDBBackend, networkI
 	return v
 }
 
+// SetBlockFinisher sets the `BlockFinisher` to store the blocks; it must be
+// set before the blocks are validated.
+func (v *BlockValidator) SetBlockFinisher(f BlockFinisher) *BlockValidator {
+	v.finisher = f
+	return v
+}
+
 func (v *BlockValidator) Validate(ctx context.Context, syncInfo *SyncInfo) error {
 	exists, err := v.existsBlock(ctx, v.storage, syncInfo.Height)
 	if err != nil {
@@ -84,63 +96,39 @@ func (v *BlockValidator) validate(ctx context.Context, syncInfo *SyncInfo) error
 		return err
 	}
 
-	if err := v.validateTxs(ctx, syncInfo); err != nil {
-		return err
-	}
-
-	if err := v.validateBlock(ctx, syncInfo, prevBlk); err != nil {
-		return err
-	}
-
-	return nil
+	return runner.VerifyBlock(
+		v.storage,
+		v.networkID,
+		v.commonCfg,
+		v.localNode,
+		*prevBlk,
+		*syncInfo.Block,
+		syncInfo.Ptx,
+		syncInfo.Txs,
+	)
 }
 
 func (v *BlockValidator) finishBlock(ctx context.Context, syncInfo *SyncInfo) error {
-	bs, err := v.storage.OpenBatch()
-	if err != nil {
-		return err
-	}
-
-	if exists, err := v.existsBlock(ctx, bs, syncInfo.Height); err != nil {
-		bs.Discard()
+	if exists, err := v.existsBlock(ctx, v.storage, syncInfo.Height); err != nil {
 		return err
 	} else if exists == true {
 		v.logger.Info("This block exists", "height", syncInfo.Height)
 		return nil
 	}
 
-	//TODO(anarcher): using leveldb.Tx or leveldb.Batch?
 	blk := *syncInfo.Block
-	if err := blk.Save(bs); err != nil {
+	if v.finisher == nil {
+		return errBlockFinisherNotSet
+	}
+	if err := v.finisher.FinishBlock(blk, *syncInfo.Ptx, syncInfo.Txs, v.logger); err != nil {
 		if err == errors.BlockAlreadyExists {
 			return nil
 		}
 		return err
 	}
 
-	if err := runner.FinishTransactions(blk, syncInfo.Txs, bs); err != nil {
-		bs.Discard()
-		return err
-	}
-	for _, tx := range syncInfo.Txs {
-		if _, err := block.SaveTransactionPool(bs, *tx); err != nil {
-			return err
-		}
-	}
-
-	ptx := syncInfo.Ptx
-	if err := runner.FinishProposerTransaction(bs, blk, *ptx, v.logger); err != nil {
-		bs.Discard()
-		return err
-	}
-
 	v.logger.Debug(fmt.Sprintf("finish to sync block height: %v", syncInfo.Height), "height", syncInfo.Height, "hash", blk.Hash)
 
-	if err := bs.Commit(); err != nil {
-		bs.Discard()
-		return err
-	}
-
 	select {
 	case <-ctx.Done():
 		return nil
@@ -152,57 +140,6 @@ func (v *BlockValidator) finishBlock(ctx context.Context, syncInfo *SyncInfo) er
 	return nil
 }
 
-func (v *BlockValidator) validateBlock(ctx context.Context, si *SyncInfo, prevBlk *block.Block) error {
-	var txs []string
-	for _, tx := range si.Txs {
-		txs = append(txs, tx.H.Hash)
-	}
-
-	r := voting.Basis{
-		Round:     si.Block.Round,
-		Height:    si.Height,
-		BlockHash: prevBlk.Hash,
-		TotalTxs:  si.Block.TotalTxs,
-		TotalOps:  si.Block.TotalOps,
-	}
-
-	blk := block.NewBlock(si.Block.Proposer, r, si.Block.ProposerTransaction, txs, si.Block.Confirmed)
-
-	if blk.Hash != si.Block.Hash {
-		err := errors.HashDoesNotMatch
-		return err
-	}
-
-	return nil
-}
-
-func (v *BlockValidator) validateTxs(ctx context.Context, si *SyncInfo) error {
-	// proposer transaction
-	if si.Ptx != nil {
-		if err := si.Ptx.IsWellFormed(v.networkID, v.commonCfg); err != nil {
-			return err
-		}
-	}
-	// transactions
-	for _, tx := range si.Txs {
-		hash := tx.B.MakeHashString()
-		if hash != tx.H.Hash {
-			err := errors.HashDoesNotMatch
-			return err
-		}
-
-		if err := tx.IsWellFormed(v.networkID, v.commonCfg); err != nil {
-			return err
-		}
-
-		if err := runner.ValidateTx(v.storage, *tx); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (v *BlockValidator) existsBlock(ctx context.Context, st *storage.LevelDBBackend, height uint64) (bool, error) {
 	select {
 	case <-ctx.Done():
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"boscoin.io/sebak/lib/ballot"
 	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/network"
@@ -15,9 +16,9 @@ func TestValidator(t *testing.T) {
 	defer st.Close()
 
 	networkID := []byte("test-network")
-	_, nw, _ := network.CreateMemoryNetwork(nil)
+	_, nw, localNode := network.CreateMemoryNetwork(nil)
 
-	v := NewBlockValidator(nw, st, networkID, common.NewConfig())
+	v := NewBlockValidator(nw, st, networkID, localNode, common.NewConfig())
 
 	ctx := context.Background()
 
@@ -34,3 +35,31 @@ func TestValidator(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestValidatorBlockFinisher(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	networkID := []byte("test-network")
+	_, nw, localNode := network.CreateMemoryNetwork(nil)
+
+	v := NewBlockValidator(nw, st, networkID, localNode, common.NewConfig())
+
+	ctx := context.Background()
+
+	bk := block.GetLatestBlock(st)
+	bk2 := block.TestMakeNewBlockWithPrevBlock(bk, nil)
+
+	si := &SyncInfo{
+		Height: bk2.Height,
+		Block:  &bk2,
+		Ptx:    &ballot.ProposerTransaction{},
+	}
+
+	require.Equal(t, errBlockFinisherNotSet, v.finishBlock(ctx, si))
+
+	finisher := &mockBlockFinisher{}
+	v.SetBlockFinisher(finisher)
+	require.NoError(t, v.finishBlock(ctx, si))
+	require.Equal(t, []block.Block{bk2}, finisher.finished)
+}
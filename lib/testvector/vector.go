@@ -0,0 +1,428 @@
+// Package testvector provides the canonical test vectors of the messages,
+// which are signed by the wallets and the nodes. The hash and the signature
+// depend on the exact byte layout of the message, so the other
+// implementations can check their encoding and signing against `vectors.json`
+// of this package.
+//
+// The vectors are made by `Generate()` from the fixed keypairs and times; the
+// test of this package fails if the current code makes the different vectors,
+// so the accidental change of the wire format is caught. If the change is
+// intended, `vectors.json` should be regenerated by `Regenerate()`, with
+// `go test ./lib/testvector -update-vectors`.
+package testvector
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+	"boscoin.io/sebak/lib/voting"
+)
+
+type Kind string
+
+const (
+	KindOperation   Kind = "operation"
+	KindTransaction Kind = "transaction"
+	KindBallot      Kind = "ballot"
+)
+
+const (
+	// NetworkID is the network ID, which the vectors are signed for.
+	NetworkID = "sebak-test-vector-network"
+
+	// The seeds of the keypairs by `keypair.Master()`.
+	SourceSeed   = "sebak-test-vector-source"
+	TargetSeed   = "sebak-test-vector-target"
+	ProposerSeed = "sebak-test-vector-proposer"
+	NodeSeed     = "sebak-test-vector-node"
+	CommonSeed   = "sebak-test-vector-common"
+)
+
+// Created is the time of the messages in the vectors.
+var Created = common.FormatISO8601(time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC))
+
+// Vector is the message with the expected hash and signatures.
+type Vector struct {
+	Name string `json:"name"`
+	Kind Kind   `json:"kind"`
+
+	// Signer is the seed of the keypair, which signs the message; empty for
+	// the operation.
+	Signer string `json:"signer,omitempty"`
+
+	// Message is the JSON of the message like it is sent to the node.
+	Message json.RawMessage `json:"message"`
+
+	// CanonicalBytes is the hex of the RLP encoding of the body, which the
+	// hash is made from; see `common.MakeObjectHash()`. The hash of the
+	// expired ballot with the reason mixes in the reason also; see
+	// `ballot.Ballot.MakeHashString()`.
+	CanonicalBytes string `json:"canonical_bytes"`
+
+	Hash              string `json:"hash"`
+	Signature         string `json:"signature,omitempty"`          // signed of <networkID> + `Hash`
+	ProposerSignature string `json:"proposer_signature,omitempty"` // signed of <networkID> + hash of proposed ballot
+}
+
+// Generate makes the vectors by the current code.
+func Generate() (vectors []Vector, err error) {
+	target := keypair.Master(TargetSeed)
+
+	payment := operation.NewPayment(target.Address(), common.Amount(100000))
+	createAccount := operation.NewCreateAccount(target.Address(), common.BaseReserve, "")
+
+	for _, o := range []struct {
+		name string
+		opb  operation.Body
+	}{
+		{"payment", payment},
+		{"create-account", createAccount},
+	} {
+		var v Vector
+		if v, err = newOperationVector(o.name, o.opb); err != nil {
+			return
+		}
+		vectors = append(vectors, v)
+	}
+
+	var paymentTx transaction.Transaction
+	for _, o := range []struct {
+		name       string
+		sequenceID uint64
+		opb        operation.Body
+	}{
+		{"payment", 0, payment},
+		{"create-account", 1, createAccount},
+	} {
+		var tx transaction.Transaction
+		if tx, err = newTransaction(SourceSeed, o.sequenceID, o.opb); err != nil {
+			return
+		}
+		if o.name == "payment" {
+			paymentTx = tx
+		}
+
+		var v Vector
+		if v, err = newTransactionVector(o.name, SourceSeed, tx); err != nil {
+			return
+		}
+		vectors = append(vectors, v)
+	}
+
+	{ // the ballot of proposer
+		var b ballot.Ballot
+		if b, err = newProposerBallot(paymentTx); err != nil {
+			return
+		}
+
+		var v Vector
+		if v, err = newBallotVector("init", ProposerSeed, b); err != nil {
+			return
+		}
+		vectors = append(vectors, v)
+	}
+
+	{ // the expired ballot by timeout
+		var b ballot.Ballot
+		if b, err = newExpiredBallot(); err != nil {
+			return
+		}
+
+		var v Vector
+		if v, err = newBallotVector("expired", NodeSeed, b); err != nil {
+			return
+		}
+		vectors = append(vectors, v)
+	}
+
+	return
+}
+
+func newOperationVector(name string, opb operation.Body) (v Vector, err error) {
+	var op operation.Operation
+	if op, err = operation.NewOperation(opb); err != nil {
+		return
+	}
+
+	var message, canonical []byte
+	if message, err = op.Serialize(); err != nil {
+		return
+	}
+	if canonical, err = rlp.EncodeToBytes(op); err != nil {
+		return
+	}
+
+	v = Vector{
+		Name:           name,
+		Kind:           KindOperation,
+		Message:        message,
+		CanonicalBytes: hex.EncodeToString(canonical),
+		Hash:           op.MakeHashString(),
+	}
+
+	return
+}
+
+func newTransaction(seed string, sequenceID uint64, opbs ...operation.Body) (tx transaction.Transaction, err error) {
+	var ops []operation.Operation
+	for _, opb := range opbs {
+		var op operation.Operation
+		if op, err = operation.NewOperation(opb); err != nil {
+			return
+		}
+		ops = append(ops, op)
+	}
+
+	kp := keypair.Master(seed)
+	if tx, err = transaction.NewTransaction(kp.Address(), sequenceID, ops...); err != nil {
+		return
+	}
+	tx.H.Created = Created
+	tx.Sign(kp, []byte(NetworkID))
+
+	return
+}
+
+func newTransactionVector(name, seed string, tx transaction.Transaction) (v Vector, err error) {
+	var message, canonical []byte
+	if message, err = tx.Serialize(); err != nil {
+		return
+	}
+	if canonical, err = rlp.EncodeToBytes(tx.B); err != nil {
+		return
+	}
+
+	v = Vector{
+		Name:           name,
+		Kind:           KindTransaction,
+		Signer:         seed,
+		Message:        message,
+		CanonicalBytes: hex.EncodeToString(canonical),
+		Hash:           tx.GetHash(),
+		Signature:      tx.H.Signature,
+	}
+
+	return
+}
+
+var basis = voting.Basis{
+	Round:     0,
+	Height:    10,
+	BlockHash: "3R2ZGURJRSEUkHpAJVTTR8dc9C8HWmMZZeBhgzpPFTau",
+	TotalTxs:  10,
+	TotalOps:  20,
+}
+
+// newProposerTransaction makes the `ballot.ProposerTransaction` like the
+// proposer, but with the fixed time.
+func newProposerTransaction(b ballot.Ballot, txs ...transaction.Transaction) (ptx ballot.ProposerTransaction, err error) {
+	commonAccount := keypair.Master(CommonSeed).Address()
+
+	var opc operation.CollectTxFee
+	if opc, err = ballot.NewCollectTxFeeFromBallot(b, commonAccount, txs...); err != nil {
+		return
+	}
+
+	var opi operation.Inflation
+	if opi, err = ballot.NewInflationFromBallot(b, commonAccount, common.Amount(5000000000000000000)); err != nil {
+		return
+	}
+
+	if ptx, err = ballot.NewProposerTransactionFromBallot(b, opc, opi); err != nil {
+		return
+	}
+	ptx.H.Created = Created
+
+	return
+}
+
+// signBallot signs the ballot like `ballot.Ballot.Sign()`, but with the fixed
+// time.
+func signBallot(b *ballot.Ballot, seed string) (err error) {
+	kp := keypair.Master(seed)
+	networkID := []byte(NetworkID)
+
+	ptx := b.ProposerTransaction()
+	ptx.Sign(keypair.Master(ProposerSeed), networkID)
+	b.SetProposerTransaction(ptx)
+
+	b.B.Proposed.Confirmed = Created
+	var signature []byte
+	if signature, err = keypair.MakeSignature(keypair.Master(ProposerSeed), networkID, string(common.MustMakeObjectHash(b.B.Proposed))); err != nil {
+		return
+	}
+	b.H.ProposerSignature = base58.Encode(signature)
+
+	b.B.Confirmed = Created
+	b.B.Source = kp.Address()
+	b.H.Hash = b.MakeHashString()
+	if signature, err = keypair.MakeSignature(kp, networkID, b.H.Hash); err != nil {
+		return
+	}
+	b.H.Signature = base58.Encode(signature)
+
+	return
+}
+
+func newProposerBallot(txs ...transaction.Transaction) (b ballot.Ballot, err error) {
+	proposer := keypair.Master(ProposerSeed).Address()
+
+	var hashes []string
+	for _, tx := range txs {
+		hashes = append(hashes, tx.GetHash())
+	}
+
+	b = *ballot.NewBallot(proposer, proposer, basis, hashes)
+
+	var ptx ballot.ProposerTransaction
+	if ptx, err = newProposerTransaction(b, txs...); err != nil {
+		return
+	}
+	b.SetProposerTransaction(ptx)
+
+	err = signBallot(&b, ProposerSeed)
+
+	return
+}
+
+func newExpiredBallot() (b ballot.Ballot, err error) {
+	proposer := keypair.Master(ProposerSeed).Address()
+	node := keypair.Master(NodeSeed).Address()
+
+	b = *ballot.NewBallot(node, proposer, basis, []string{})
+	b.SetVote(ballot.StateSIGN, voting.EXP)
+	b.SetExpiredReason(ballot.ExpiredReasonTimeout)
+
+	var ptx ballot.ProposerTransaction
+	if ptx, err = newProposerTransaction(b); err != nil {
+		return
+	}
+	b.SetProposerTransaction(ptx)
+
+	err = signBallot(&b, NodeSeed)
+
+	return
+}
+
+func newBallotVector(name, seed string, b ballot.Ballot) (v Vector, err error) {
+	var message, canonical []byte
+	if message, err = b.Serialize(); err != nil {
+		return
+	}
+	if canonical, err = rlp.EncodeToBytes(b.B); err != nil {
+		return
+	}
+
+	v = Vector{
+		Name:              name,
+		Kind:              KindBallot,
+		Signer:            seed,
+		Message:           message,
+		CanonicalBytes:    hex.EncodeToString(canonical),
+		Hash:              b.GetHash(),
+		Signature:         b.H.Signature,
+		ProposerSignature: b.H.ProposerSignature,
+	}
+
+	return
+}
+
+// Check decodes the message of the vector by the current code and checks the
+// hash and the signatures of it.
+func Check(v Vector) (err error) {
+	networkID := []byte(NetworkID)
+
+	var hash string
+	switch v.Kind {
+	case KindOperation:
+		var op operation.Operation
+		if err = json.Unmarshal(v.Message, &op); err != nil {
+			return
+		}
+		hash = op.MakeHashString()
+	case KindTransaction:
+		var tx transaction.Transaction
+		if err = json.Unmarshal(v.Message, &tx); err != nil {
+			return
+		}
+		if hash = tx.B.MakeHashString(); hash != tx.GetHash() {
+			return fmt.Errorf("%s/%s: hash of message does not match", v.Kind, v.Name)
+		}
+		if err = tx.VerifyNetworkID(networkID); err != nil {
+			return
+		}
+	case KindBallot:
+		var b ballot.Ballot
+		if b, err = ballot.NewBallotFromJSON(v.Message); err != nil {
+			return
+		}
+		if hash = b.MakeHashString(); hash != b.GetHash() {
+			return fmt.Errorf("%s/%s: hash of message does not match", v.Kind, v.Name)
+		}
+		if err = b.VerifySource(networkID); err != nil {
+			return
+		}
+		if err = b.VerifyProposer(networkID); err != nil {
+			return
+		}
+	default:
+		return fmt.Errorf("%s/%s: unknown kind", v.Kind, v.Name)
+	}
+
+	if hash != v.Hash {
+		return fmt.Errorf("%s/%s: expected hash %s, but %s", v.Kind, v.Name, v.Hash, hash)
+	}
+
+	return
+}
+
+// Write writes the vectors in the format of `vectors.json`.
+func Write(w io.Writer, vectors []Vector) (err error) {
+	var b []byte
+	if b, err = json.MarshalIndent(vectors, "", "  "); err != nil {
+		return
+	}
+	_, err = w.Write(append(b, '\n'))
+
+	return
+}
+
+// Read reads the vectors of `Write()`.
+func Read(r io.Reader) (vectors []Vector, err error) {
+	var b []byte
+	if b, err = ioutil.ReadAll(r); err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &vectors)
+
+	return
+}
+
+// Regenerate writes the vectors by the current code into `path`.
+func Regenerate(path string) (err error) {
+	var vectors []Vector
+	if vectors, err = Generate(); err != nil {
+		return
+	}
+
+	var f *os.File
+	if f, err = os.Create(path); err != nil {
+		return
+	}
+	defer f.Close()
+
+	return Write(f, vectors)
+}
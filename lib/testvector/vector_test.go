@@ -0,0 +1,64 @@
+package testvector
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateVectors = flag.Bool("update-vectors", false, "regenerate vectors.json")
+
+const vectorsPath = "vectors.json"
+
+// TestVectors checks the current code makes the same vectors with
+// `vectors.json`. If it is broken, the hash and the signature of the existing
+// messages are broken; `vectors.json` should be regenerated by
+// `-update-vectors` only if the change is intended.
+func TestVectors(t *testing.T) {
+	if *updateVectors {
+		require.NoError(t, Regenerate(vectorsPath))
+	}
+
+	vectors, err := Generate()
+	require.NoError(t, err)
+
+	var generated bytes.Buffer
+	require.NoError(t, Write(&generated, vectors))
+
+	expected, err := ioutil.ReadFile(vectorsPath)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), generated.String())
+
+	// generated twice, the same vectors
+	again, err := Generate()
+	require.NoError(t, err)
+	require.Equal(t, vectors, again)
+}
+
+func TestVectorsCheck(t *testing.T) {
+	f, err := os.Open(vectorsPath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	vectors, err := Read(f)
+	require.NoError(t, err)
+
+	names := map[Kind][]string{}
+	for _, v := range vectors {
+		require.NoError(t, Check(v), v.Name)
+		names[v.Kind] = append(names[v.Kind], v.Name)
+	}
+	require.Equal(t, []string{"payment", "create-account"}, names[KindOperation])
+	require.Equal(t, []string{"payment", "create-account"}, names[KindTransaction])
+	require.Equal(t, []string{"init", "expired"}, names[KindBallot])
+
+	{ // the modified message does not pass
+		v := vectors[2]
+		v.Message = bytes.Replace(v.Message, []byte(`"sequence_id": 0`), []byte(`"sequence_id": 1`), 1)
+		require.Error(t, Check(v))
+	}
+}
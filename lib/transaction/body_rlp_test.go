@@ -0,0 +1,137 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+var updateGolden = flag.Bool("update-golden", false, "update the golden files of the RLP encoding of body")
+
+// makeGoldenTransactions makes the transactions, which do not have any random
+// or time-dependent field in `Body`.
+func makeGoldenTransactions() map[string]Transaction {
+	source := keypair.Master("sebak-golden-source").Address()
+	target := keypair.Master("sebak-golden-target").Address()
+	signer := keypair.Master("sebak-golden-signer").Address()
+
+	newTx := func(sequenceID uint64, validUntilHeight uint64, opbs ...operation.Body) Transaction {
+		var ops []operation.Operation
+		for _, opb := range opbs {
+			op, err := operation.NewOperation(opb)
+			if err != nil {
+				panic(err)
+			}
+			ops = append(ops, op)
+		}
+
+		tx, err := NewTransaction(source, sequenceID, ops...)
+		if err != nil {
+			panic(err)
+		}
+		tx.B.ValidUntilHeight = validUntilHeight
+		tx.H.Hash = tx.B.MakeHashString()
+
+		return tx
+	}
+
+	return map[string]Transaction{
+		"payment": newTx(
+			0, 0,
+			operation.NewPayment(target, common.Amount(100000)),
+		),
+		"create-account": newTx(
+			1, 0,
+			operation.NewCreateAccount(target, common.BaseReserve, ""),
+		),
+		"create-account-linked": newTx(
+			2, 0,
+			operation.NewCreateAccount(target, common.Unit, source),
+		),
+		"valid-until-height": newTx(
+			3, 100,
+			operation.NewPayment(target, common.Amount(100000)),
+		),
+		"set-signers": newTx(
+			4, 0,
+			operation.NewSetSigners(
+				2,
+				operation.Signer{Address: source, Weight: 1},
+				operation.Signer{Address: signer, Weight: 1},
+			),
+		),
+		"memo": func() Transaction {
+			tx := newTx(
+				6, 0,
+				operation.NewPayment(target, common.Amount(100000)),
+			)
+			tx.B.Memo = NewMemo(MemoID, "1234")
+			tx.H.Hash = tx.B.MakeHashString()
+			return tx
+		}(),
+		"multiple-operations": newTx(
+			5, 0,
+			operation.NewPayment(target, common.Amount(100000)),
+			operation.NewPayment(signer, common.Amount(200000)),
+			operation.NewUnfreezeRequest(),
+		),
+	}
+}
+
+// TestBodyRLPGolden pins the RLP encoding of `Body`, which is hashed. If this
+// test is broken, the hash and the signature of the existing transactions are
+// broken; the golden files should be updated by `-update-golden` only if the
+// change of hash is intended.
+func TestBodyRLPGolden(t *testing.T) {
+	for name, tx := range makeGoldenTransactions() {
+		b, err := rlp.EncodeToBytes(tx.B)
+		require.NoError(t, err, name)
+
+		path := filepath.Join("testdata", name+".golden")
+		if *updateGolden {
+			require.NoError(t, ioutil.WriteFile(path, []byte(hex.EncodeToString(b)+"\n"), 0644), name)
+		}
+
+		golden, err := ioutil.ReadFile(path)
+		require.NoError(t, err, name)
+		require.Equal(t, strings.TrimSpace(string(golden)), hex.EncodeToString(b), name)
+
+		// the hash is made from the RLP encoding
+		require.Equal(t, base58.Encode(common.MakeHash(b)), tx.GetHash(), name)
+		require.Equal(t, common.MustMakeObjectHashString(tx.B), tx.GetHash(), name)
+	}
+}
+
+// TestBodyRLPIndependentOfJSON checks the RLP encoding of `Body` is not
+// changed by the JSON roundtrip of transaction.
+func TestBodyRLPIndependentOfJSON(t *testing.T) {
+	kp := keypair.Master("sebak-golden-source").(*keypair.Full)
+
+	for name, tx := range makeGoldenTransactions() {
+		tx.Sign(kp, []byte("sebak-golden-network"))
+		expected, err := rlp.EncodeToBytes(tx.B)
+		require.NoError(t, err, name)
+
+		serialized, err := tx.Serialize()
+		require.NoError(t, err, name)
+
+		var tx2 Transaction
+		require.NoError(t, tx2.UnmarshalJSON(serialized), name)
+
+		b, err := rlp.EncodeToBytes(tx2.B)
+		require.NoError(t, err, name)
+		require.Equal(t, expected, b, name)
+		require.Equal(t, tx.GetHash(), tx2.GetHash(), name)
+	}
+}
@@ -0,0 +1,107 @@
+package transaction
+
+import (
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// TransactionBuilderCheckerFuncs are the checkers of `TransactionBuilder.Build`;
+// they are same with `TransactionWellFormedCheckerFuncs` except
+// `CheckVerifySignature`, because the built transaction is not signed yet.
+var TransactionBuilderCheckerFuncs = []common.CheckerFunc{
+	CheckOverOperationsLimit,
+	CheckTransactionSize,
+	CheckOperationBodySize,
+	CheckMemo,
+	CheckSequenceID,
+	CheckSource,
+	CheckBaseFee,
+	CheckOperationTypes,
+	CheckOperations,
+}
+
+// TransactionBuilder builds the `Transaction` step by step;
+//
+//	tx, err := NewTransactionBuilder(source).
+//	    AddOperation(op).
+//	    Sequence(sequenceID).
+//	    Build(conf)
+//	tx.Sign(kp, networkID)
+//
+// If the fee is not set, the minimum fee by `common.Config.FeePolicy` is used;
+// see `Transaction.TotalBaseFee()`.
+type TransactionBuilder struct {
+	source           string
+	sequenceID       uint64
+	fee              common.Amount
+	validUntilHeight uint64
+	memo             *Memo
+	operations       []operation.Operation
+}
+
+func NewTransactionBuilder(source string) *TransactionBuilder {
+	return &TransactionBuilder{source: source}
+}
+
+func (b *TransactionBuilder) AddOperation(ops ...operation.Operation) *TransactionBuilder {
+	b.operations = append(b.operations, ops...)
+
+	return b
+}
+
+func (b *TransactionBuilder) Fee(fee common.Amount) *TransactionBuilder {
+	b.fee = fee
+
+	return b
+}
+
+func (b *TransactionBuilder) Sequence(sequenceID uint64) *TransactionBuilder {
+	b.sequenceID = sequenceID
+
+	return b
+}
+
+func (b *TransactionBuilder) ValidUntil(height uint64) *TransactionBuilder {
+	b.validUntilHeight = height
+
+	return b
+}
+
+func (b *TransactionBuilder) Memo(memoType MemoType, value string) *TransactionBuilder {
+	b.memo = NewMemo(memoType, value)
+
+	return b
+}
+
+// Build makes the `Transaction` and checks it like `Transaction.IsWellFormed`
+// without the signature; the returned transaction should be signed by the
+// source.
+func (b *TransactionBuilder) Build(conf common.Config) (tx Transaction, err error) {
+	if len(b.operations) < 1 {
+		err = errors.TransactionEmptyOperations
+		return
+	}
+
+	body := Body{
+		Source:           b.source,
+		Fee:              b.fee,
+		SequenceID:       b.sequenceID,
+		Operations:       append([]operation.Operation{}, b.operations...),
+		ValidUntilHeight: b.validUntilHeight,
+		Memo:             b.memo,
+	}
+
+	tx = Transaction{H: Header{Created: common.NowISO8601()}, B: body}
+	if tx.B.Fee == 0 {
+		tx.B.Fee = tx.TotalBaseFee(conf.FeePolicy)
+	}
+	tx.H.Hash = tx.B.MakeHashString()
+
+	if err = tx.runCheckers(TransactionBuilderCheckerFuncs, nil, conf); err != nil {
+		tx = Transaction{}
+		return
+	}
+
+	return
+}
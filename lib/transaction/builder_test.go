@@ -0,0 +1,113 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func makeBuilderTestPayment(t *testing.T, target string, amount common.Amount) operation.Operation {
+	op, err := operation.NewOperation(operation.NewPayment(target, amount))
+	require.NoError(t, err)
+
+	return op
+}
+
+func TestTransactionBuilderAutoFee(t *testing.T) {
+	conf := common.NewConfig()
+	networkID := []byte("sebak-unittest-builder")
+	kp := keypair.Random()
+
+	tx, err := NewTransactionBuilder(kp.Address()).
+		AddOperation(makeBuilderTestPayment(t, keypair.Random().Address(), common.Amount(1))).
+		AddOperation(makeBuilderTestPayment(t, keypair.Random().Address(), common.Amount(2))).
+		Sequence(3).
+		Build(conf)
+	require.NoError(t, err)
+
+	require.Equal(t, kp.Address(), tx.B.Source)
+	require.Equal(t, uint64(3), tx.B.SequenceID)
+	require.Equal(t, 2, len(tx.B.Operations))
+	require.Equal(t, common.BaseFee.MustMult(2), tx.B.Fee)
+	require.Equal(t, tx.B.MakeHashString(), tx.GetHash())
+
+	// not signed yet
+	require.Error(t, tx.IsWellFormed(networkID, conf))
+
+	tx.Sign(kp, networkID)
+	require.NoError(t, tx.IsWellFormed(networkID, conf))
+
+	{ // the fee set is kept
+		fee := common.BaseFee.MustMult(3)
+		tx, err := NewTransactionBuilder(kp.Address()).
+			AddOperation(makeBuilderTestPayment(t, keypair.Random().Address(), common.Amount(1))).
+			Fee(fee).
+			ValidUntil(10).
+			Build(conf)
+		require.NoError(t, err)
+		require.Equal(t, fee, tx.B.Fee)
+		require.Equal(t, uint64(10), tx.B.ValidUntilHeight)
+	}
+}
+
+func TestTransactionBuilderValidation(t *testing.T) {
+	conf := common.NewConfig()
+	kp := keypair.Random()
+	target := keypair.Random().Address()
+
+	{ // no operations
+		_, err := NewTransactionBuilder(kp.Address()).Build(conf)
+		require.Equal(t, errors.TransactionEmptyOperations, err)
+	}
+
+	{ // lower fee than minimum
+		_, err := NewTransactionBuilder(kp.Address()).
+			AddOperation(
+				makeBuilderTestPayment(t, target, common.Amount(1)),
+				makeBuilderTestPayment(t, keypair.Random().Address(), common.Amount(1)),
+			).
+			Fee(common.BaseFee).
+			Build(conf)
+		require.Equal(t, errors.InvalidFee, err)
+	}
+
+	{ // invalid source
+		_, err := NewTransactionBuilder("showme").
+			AddOperation(makeBuilderTestPayment(t, target, common.Amount(1))).
+			Build(conf)
+		require.Equal(t, errors.BadPublicAddress, err)
+	}
+
+	{ // payment to itself
+		_, err := NewTransactionBuilder(kp.Address()).
+			AddOperation(makeBuilderTestPayment(t, kp.Address(), common.Amount(1))).
+			Build(conf)
+		require.Equal(t, errors.InvalidOperation, err)
+	}
+
+	{ // duplicated operations
+		_, err := NewTransactionBuilder(kp.Address()).
+			AddOperation(makeBuilderTestPayment(t, target, common.Amount(1))).
+			AddOperation(makeBuilderTestPayment(t, target, common.Amount(2))).
+			Build(conf)
+		require.Equal(t, errors.DuplicatedOperation, err)
+	}
+
+	{ // over the operations limit
+		conf := common.NewConfig()
+		conf.OpsLimit = 1
+
+		_, err := NewTransactionBuilder(kp.Address()).
+			AddOperation(
+				makeBuilderTestPayment(t, target, common.Amount(1)),
+				makeBuilderTestPayment(t, keypair.Random().Address(), common.Amount(1)),
+			).
+			Build(conf)
+		require.Equal(t, errors.TransactionHasOverMaxOperations, err)
+	}
+}
@@ -40,6 +40,53 @@ func CheckOverOperationsLimit(c common.Checker, args ...interface{}) (err error)
 	return
 }
 
+// CheckTransactionSize checks the size of serialized transaction is not over
+// `Config.MaxTransactionSize`.
+func CheckTransactionSize(c common.Checker, args ...interface{}) (err error) {
+	checker := c.(*Checker)
+
+	var b []byte
+	if b, err = checker.Transaction.Serialize(); err != nil {
+		return
+	}
+	if len(b) > checker.Conf.MaxTransactionSize {
+		err = errors.TransactionTooLarge
+		return
+	}
+
+	return
+}
+
+// CheckOperationBodySize checks the size of each serialized operation body is
+// not over `Config.MaxOperationBodySize`.
+func CheckOperationBodySize(c common.Checker, args ...interface{}) (err error) {
+	checker := c.(*Checker)
+
+	for _, op := range checker.Transaction.B.Operations {
+		var b []byte
+		if b, err = op.B.Serialize(); err != nil {
+			return
+		}
+		if len(b) > checker.Conf.MaxOperationBodySize {
+			err = errors.OperationBodyTooLarge
+			return
+		}
+	}
+
+	return
+}
+
+// CheckMemo checks the format of `Body.Memo`, if it is set.
+func CheckMemo(c common.Checker, args ...interface{}) (err error) {
+	checker := c.(*Checker)
+
+	if memo := checker.Transaction.B.Memo; memo != nil {
+		err = memo.IsWellFormed()
+	}
+
+	return
+}
+
 func CheckSequenceID(c common.Checker, args ...interface{}) (err error) {
 	//checker := c.(*Checker)
 	return
@@ -47,7 +94,7 @@ func CheckSequenceID(c common.Checker, args ...interface{}) (err error) {
 
 func CheckBaseFee(c common.Checker, args ...interface{}) (err error) {
 	checker := c.(*Checker)
-	if checker.Transaction.B.Fee < checker.Transaction.TotalBaseFee() {
+	if checker.Transaction.B.Fee < checker.Transaction.TotalBaseFee(checker.Conf.FeePolicy) {
 		err = errors.InvalidFee
 		return
 	}
@@ -68,6 +115,10 @@ func CheckOperationTypes(c common.Checker, args ...interface{}) (err error) {
 			err = errors.InvalidOperation
 			return
 		}
+		if !checker.Conf.OperationFilter.IsAllowed(string(op.H.Type)) {
+			err = errors.OperationTypeDisabled.Clone().SetData("type", op.H.Type)
+			return
+		}
 	}
 
 	return
@@ -77,7 +128,19 @@ func CheckOperations(c common.Checker, args ...interface{}) (err error) {
 	checker := c.(*Checker)
 
 	var hashes []string
-	for _, op := range checker.Transaction.B.Operations {
+	for i, op := range checker.Transaction.B.Operations {
+		// the balance is merged after the other operations, so the merge
+		// must be the last one
+		if pop, ok := op.B.(operation.AccountMerge); ok {
+			if i != len(checker.Transaction.B.Operations)-1 || checker.Transaction.B.Source == pop.Target {
+				err = errors.InvalidOperation
+				return
+			}
+			if err = op.IsWellFormed(checker.Conf); err != nil {
+				return
+			}
+		}
+
 		if pop, ok := op.B.(operation.Payable); ok {
 			if checker.Transaction.B.Source == pop.TargetAddress() {
 				err = errors.InvalidOperation
@@ -96,6 +159,17 @@ func CheckOperations(c common.Checker, args ...interface{}) (err error) {
 
 			hashes = append(hashes, u)
 		}
+
+		// the time lock can be claimed only once
+		if pop, ok := op.B.(operation.TimeLockClaim); ok {
+			u := fmt.Sprintf("%s-%s-%d", op.H.Type, pop.Source, pop.UnlockHeight)
+			if _, found := common.InStringArray(hashes, u); found {
+				err = errors.DuplicatedOperation
+				return
+			}
+
+			hashes = append(hashes, u)
+		}
 	}
 
 	return
@@ -115,5 +189,28 @@ func CheckVerifySignature(c common.Checker, args ...interface{}) (err error) {
 	if err != nil {
 		return
 	}
+
+	// the additional signatures; whether their weight meets the threshold of
+	// source account is checked with the account state.
+	signers := []string{checker.Transaction.B.Source}
+	for _, s := range checker.Transaction.H.Signatures {
+		if _, found := common.InStringArray(signers, s.Signer); found {
+			err = errors.DuplicatedSigner
+			return
+		}
+		signers = append(signers, s.Signer)
+
+		if kp, err = keypair.Parse(s.Signer); err != nil {
+			return
+		}
+		err = kp.Verify(
+			append(checker.NetworkID, []byte(checker.Transaction.H.Hash)...),
+			base58.Decode(s.Signature),
+		)
+		if err != nil {
+			return
+		}
+	}
+
 	return
 }
@@ -0,0 +1,112 @@
+package transaction
+
+import (
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// OperationInfo is the decoded operation of `TransactionInfo`.
+type OperationInfo struct {
+	Hash string                  `json:"hash"`
+	Type operation.OperationType `json:"type"`
+	Body operation.Body          `json:"body"`
+}
+
+// TransactionInfo is the result of `InspectTransaction()`.
+//  * `ValidSignature`: the signatures of the source and the additional
+//    signers are verified with the network id
+//  * `Findings`: the errors of the checks of `IsWellFormed()`; the `check`
+//    of the error data is the name of the failed check
+type TransactionInfo struct {
+	Hash             string          `json:"hash"`
+	Source           string          `json:"source"`
+	Fee              common.Amount   `json:"fee"`
+	SequenceID       uint64          `json:"sequence_id"`
+	Created          string          `json:"created"`
+	ValidUntilHeight uint64          `json:"valid_until_height,omitempty"`
+	Memo             *Memo           `json:"memo,omitempty"`
+	Operations       []OperationInfo `json:"operations"`
+	Signers          []string        `json:"signers"`
+	ValidSignature   bool            `json:"valid_signature"`
+	Findings         []*errors.Error `json:"findings,omitempty"`
+}
+
+// IsWellFormed returns whether no check of `IsWellFormed()` fails.
+func (info TransactionInfo) IsWellFormed() bool {
+	return len(info.Findings) < 1
+}
+
+// InspectTransaction decodes the serialized transaction and runs the checks
+// of `Transaction.IsWellFormed()` with the default `common.Config` without
+// touching the storage. Unlike `IsWellFormed()`, it does not stop at the
+// first failed check, but reports all of them in `TransactionInfo.Findings`.
+// Only if the bytes can not be decoded, it returns
+// `errors.InvalidTransaction`.
+func InspectTransaction(b []byte, networkID []byte) (info TransactionInfo, err error) {
+	var tx Transaction
+	if err = json.Unmarshal(b, &tx); err != nil {
+		err = errors.InvalidTransaction.Clone().SetData("error", err.Error())
+		return
+	}
+
+	info = TransactionInfo{
+		Hash:             tx.GetHash(),
+		Source:           tx.B.Source,
+		Fee:              tx.B.Fee,
+		SequenceID:       tx.B.SequenceID,
+		Created:          tx.H.Created,
+		ValidUntilHeight: tx.B.ValidUntilHeight,
+		Memo:             tx.B.Memo,
+		Signers:          tx.Signers(),
+		ValidSignature:   true,
+	}
+
+	for _, op := range tx.B.Operations {
+		info.Operations = append(info.Operations, OperationInfo{
+			Hash: op.MakeHashString(),
+			Type: op.H.Type,
+			Body: op.B,
+		})
+	}
+
+	checker := &Checker{
+		DefaultChecker: common.DefaultChecker{Funcs: TransactionWellFormedCheckerFuncs},
+		NetworkID:      networkID,
+		Transaction:    tx,
+		Conf:           common.NewConfig(),
+	}
+
+	for _, f := range TransactionWellFormedCheckerFuncs {
+		ferr := f(checker)
+		if ferr == nil {
+			continue
+		}
+
+		name := checkerFuncName(f)
+		if name == checkerFuncName(CheckVerifySignature) {
+			info.ValidSignature = false
+		}
+
+		e, ok := ferr.(*errors.Error)
+		if ok {
+			e = e.Clone()
+		} else {
+			e = errors.InvalidTransaction.Clone().SetData("error", ferr.Error())
+		}
+		info.Findings = append(info.Findings, e.SetData("check", name))
+	}
+
+	return
+}
+
+func checkerFuncName(f common.CheckerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+
+	return name[strings.LastIndex(name, ".")+1:]
+}
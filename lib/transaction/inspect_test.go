@@ -0,0 +1,92 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func TestInspectTransaction(t *testing.T) {
+	networkID := []byte("sebak-unittest-inspect")
+	kp, tx := TestMakeTransaction(networkID, 2)
+
+	b, err := tx.Serialize()
+	require.NoError(t, err)
+
+	info, err := InspectTransaction(b, networkID)
+	require.NoError(t, err)
+	require.True(t, info.IsWellFormed())
+	require.True(t, info.ValidSignature)
+	require.Equal(t, tx.GetHash(), info.Hash)
+	require.Equal(t, kp.Address(), info.Source)
+	require.Equal(t, tx.B.Fee, info.Fee)
+	require.Equal(t, tx.B.SequenceID, info.SequenceID)
+	require.Equal(t, []string{kp.Address()}, info.Signers)
+	require.Equal(t, 2, len(info.Operations))
+	for i, op := range tx.B.Operations {
+		require.Equal(t, op.MakeHashString(), info.Operations[i].Hash)
+		require.Equal(t, operation.TypePayment, info.Operations[i].Type)
+		require.Equal(t, op.B, info.Operations[i].Body)
+	}
+
+	{ // the other network
+		info, err := InspectTransaction(b, []byte("other-network"))
+		require.NoError(t, err)
+		require.False(t, info.ValidSignature)
+		require.Equal(t, 1, len(info.Findings))
+		require.Equal(t, "CheckVerifySignature", info.Findings[0].Data["check"])
+	}
+}
+
+func TestInspectTransactionFindings(t *testing.T) {
+	networkID := []byte("sebak-unittest-inspect")
+	_, tx := TestMakeTransaction(networkID, 1)
+
+	// the fee is lowered after signed, so both of the fee and the signature
+	// are reported
+	tx.B.Fee = common.Amount(0)
+	b, err := tx.Serialize()
+	require.NoError(t, err)
+
+	info, err := InspectTransaction(b, networkID)
+	require.NoError(t, err)
+	require.False(t, info.IsWellFormed())
+	require.False(t, info.ValidSignature)
+	require.Equal(t, common.Amount(0), info.Fee)
+
+	var codes []uint
+	var checks []interface{}
+	for _, f := range info.Findings {
+		codes = append(codes, f.Code)
+		checks = append(checks, f.Data["check"])
+	}
+	require.Contains(t, codes, errors.InvalidFee.Code)
+	require.Contains(t, checks, "CheckBaseFee")
+	require.Contains(t, checks, "CheckVerifySignature")
+
+	// the shared errors are not changed
+	require.Empty(t, errors.InvalidFee.Data)
+}
+
+func TestInspectTransactionCorrupted(t *testing.T) {
+	networkID := []byte("sebak-unittest-inspect")
+	_, tx := TestMakeTransaction(networkID, 1)
+
+	b, err := tx.Serialize()
+	require.NoError(t, err)
+
+	for _, corrupted := range [][]byte{
+		nil,
+		b[:len(b)/2],
+		[]byte("not json"),
+		[]byte(`{"T":"transaction","H":{},"B":{"operations":[{"H":{"type":"unknown"},"B":{}}]}}`),
+	} {
+		_, err := InspectTransaction(corrupted, networkID)
+		require.Error(t, err)
+		require.Equal(t, errors.InvalidTransaction.Code, err.(*errors.Error).Code)
+	}
+}
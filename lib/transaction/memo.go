@@ -0,0 +1,67 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"strconv"
+
+	"boscoin.io/sebak/lib/errors"
+)
+
+// MemoType decides the format of `Memo.Value`.
+//  * `MemoNone`: no value
+//  * `MemoText`: the text up to `MaxMemoTextSize` bytes
+//  * `MemoID`: the decimal of uint64
+//  * `MemoHash`: the hex of 32 bytes hash
+type MemoType string
+
+const (
+	MemoNone MemoType = "none"
+	MemoText MemoType = "text"
+	MemoID   MemoType = "id"
+	MemoHash MemoType = "hash"
+)
+
+// MaxMemoTextSize is the maximum size of the text memo in bytes.
+const MaxMemoTextSize = 28
+
+// memoHashSize is the size of the hash memo in bytes.
+const memoHashSize = 32
+
+// Memo is the additional information of transaction, like the ID of deposit
+// of exchange. It is included in the hash of transaction, so it can not be
+// altered after signing.
+type Memo struct {
+	Type  MemoType `json:"type"`
+	Value string   `json:"value"`
+}
+
+func NewMemo(memoType MemoType, value string) *Memo {
+	return &Memo{Type: memoType, Value: value}
+}
+
+// IsWellFormed checks the `Value` is valid for the `Type`; the too large text
+// is `errors.MemoTooLarge` and the others are `errors.InvalidMemo`.
+func (m Memo) IsWellFormed() error {
+	switch m.Type {
+	case MemoNone:
+		if len(m.Value) > 0 {
+			return errors.InvalidMemo
+		}
+	case MemoText:
+		if len(m.Value) > MaxMemoTextSize {
+			return errors.MemoTooLarge
+		}
+	case MemoID:
+		if _, err := strconv.ParseUint(m.Value, 10, 64); err != nil {
+			return errors.InvalidMemo
+		}
+	case MemoHash:
+		if b, err := hex.DecodeString(m.Value); err != nil || len(b) != memoHashSize {
+			return errors.InvalidMemo
+		}
+	default:
+		return errors.InvalidMemo
+	}
+
+	return nil
+}
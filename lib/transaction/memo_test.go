@@ -0,0 +1,97 @@
+package transaction
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestMemoIsWellFormed(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+
+	cases := []struct {
+		memo Memo
+		err  error
+	}{
+		{Memo{Type: MemoNone}, nil},
+		{Memo{Type: MemoNone, Value: "x"}, errors.InvalidMemo},
+		{Memo{Type: MemoText, Value: ""}, nil},
+		{Memo{Type: MemoText, Value: "deposit-1234"}, nil},
+		{Memo{Type: MemoText, Value: strings.Repeat("a", MaxMemoTextSize)}, nil},
+		{Memo{Type: MemoText, Value: strings.Repeat("a", MaxMemoTextSize+1)}, errors.MemoTooLarge},
+		{Memo{Type: MemoID, Value: "18446744073709551615"}, nil},
+		{Memo{Type: MemoID, Value: "18446744073709551616"}, errors.InvalidMemo},
+		{Memo{Type: MemoID, Value: "-1"}, errors.InvalidMemo},
+		{Memo{Type: MemoID, Value: "abc"}, errors.InvalidMemo},
+		{Memo{Type: MemoHash, Value: hash}, nil},
+		{Memo{Type: MemoHash, Value: hash[2:]}, errors.InvalidMemo},
+		{Memo{Type: MemoHash, Value: strings.Repeat("zz", 32)}, errors.InvalidMemo},
+		{Memo{Type: "unknown", Value: "x"}, errors.InvalidMemo},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.err, c.memo.IsWellFormed(), "%+v", c.memo)
+	}
+}
+
+func TestTransactionMemo(t *testing.T) {
+	conf := common.NewConfig()
+	networkID := []byte("sebak-unittest-memo")
+	kp, tx := TestMakeTransaction(networkID, 1)
+
+	{ // without memo, it is not serialized
+		b, err := tx.Serialize()
+		require.NoError(t, err)
+		require.NotContains(t, string(b), "memo")
+	}
+
+	withoutMemo := tx.GetHash()
+
+	tx.B.Memo = NewMemo(MemoText, "deposit-1234")
+	tx.Sign(kp, networkID)
+	require.NoError(t, tx.IsWellFormed(networkID, conf))
+	require.NotEqual(t, withoutMemo, tx.GetHash())
+
+	{ // the memo is kept by serialization
+		b, err := tx.Serialize()
+		require.NoError(t, err)
+
+		var decoded Transaction
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		require.Equal(t, tx.B.Memo, decoded.B.Memo)
+		require.Equal(t, tx.GetHash(), decoded.GetHash())
+	}
+
+	{ // the altered memo breaks the signature
+		altered := tx
+		altered.B.Memo = NewMemo(MemoText, "deposit-5678")
+		altered.H.Hash = altered.B.MakeHashString()
+		require.Error(t, altered.IsWellFormed(networkID, conf))
+	}
+
+	{ // oversize text
+		tx.B.Memo = NewMemo(MemoText, strings.Repeat("a", MaxMemoTextSize+1))
+		tx.Sign(kp, networkID)
+		require.Equal(t, errors.MemoTooLarge, tx.IsWellFormed(networkID, conf))
+	}
+
+	{ // builder
+		built, err := NewTransactionBuilder(kp.Address()).
+			AddOperation(tx.B.Operations...).
+			Memo(MemoID, "1234").
+			Build(conf)
+		require.NoError(t, err)
+		require.Equal(t, NewMemo(MemoID, "1234"), built.B.Memo)
+
+		_, err = NewTransactionBuilder(kp.Address()).
+			AddOperation(tx.B.Operations...).
+			Memo(MemoID, "not-id").
+			Build(conf)
+		require.Equal(t, errors.InvalidMemo, err)
+	}
+}
@@ -0,0 +1,36 @@
+package operation
+
+import (
+	"encoding/json"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+)
+
+// AccountMerge deposits the whole remaining balance of the source to `Target`
+// and closes the source; the closed account can not be the source of the
+// further transactions. The balance is merged after the fee and the other
+// operations of the transaction are paid, so it must be the last operation.
+type AccountMerge struct {
+	Target string `json:"target"`
+}
+
+func NewAccountMerge(target string) AccountMerge {
+	return AccountMerge{
+		Target: target,
+	}
+}
+
+func (o AccountMerge) Serialize() (encoded []byte, err error) {
+	return json.Marshal(o)
+}
+
+// Implement transaction/operation : IsWellFormed
+func (o AccountMerge) IsWellFormed(common.Config) (err error) {
+	_, err = keypair.Parse(o.Target)
+	return
+}
+
+func (o AccountMerge) TargetAddress() string {
+	return o.Target
+}
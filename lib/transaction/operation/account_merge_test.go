@@ -0,0 +1,34 @@
+package operation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+)
+
+func TestAccountMergeIsWellFormed(t *testing.T) {
+	conf := common.NewConfig()
+
+	require.NoError(t, NewAccountMerge(keypair.Random().Address()).IsWellFormed(conf))
+
+	// invalid target
+	require.Error(t, NewAccountMerge("invalid").IsWellFormed(conf))
+}
+
+func TestAccountMergeSerialize(t *testing.T) {
+	op, err := NewOperation(NewAccountMerge(keypair.Random().Address()))
+	require.NoError(t, err)
+	require.Equal(t, TypeAccountMerge, op.H.Type)
+
+	b, err := op.Serialize()
+	require.NoError(t, err)
+
+	var decoded Operation
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, op.H.Type, decoded.H.Type)
+	require.Equal(t, op.B, decoded.B)
+}
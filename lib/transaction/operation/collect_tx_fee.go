@@ -2,6 +2,9 @@ package operation
 
 import (
 	"encoding/json"
+	"io"
+
+	"github.com/ethereum/go-ethereum/rlp"
 
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/keypair"
@@ -11,6 +14,9 @@ import (
 // OperationBodyTransactionFee is the operation to send the collected transacton
 // fee to certain account. To prevent the hash duplication of transaction,
 // OperationBodyTransactionFee has block related data.
+//
+// `Amount` is the whole collected fee; `Burned` of it is burned by
+// `common.FeeBurnPolicy` and only the rest is deposited to `Target`.
 type CollectTxFee struct {
 	Target    string        `json:"target"`
 	Amount    common.Amount `json:"amount"`
@@ -19,6 +25,7 @@ type CollectTxFee struct {
 	BlockHash string        `json:"block-hash"`
 	TotalTxs  uint64        `json:"total-txs"`
 	TotalOps  uint64        `json:"total-ops"`
+	Burned    common.Amount `json:"burned,omitempty"`
 }
 
 func NewCollectTxFee(
@@ -39,6 +46,25 @@ func NewCollectTxFee(
 	}
 }
 
+// EncodeRLP encodes `CollectTxFee` without `Burned` if it is not set, so the
+// hash of the operation without burning is same with before.
+func (o CollectTxFee) EncodeRLP(w io.Writer) error {
+	fields := []interface{}{
+		o.Target,
+		o.Amount,
+		o.Txs,
+		o.Height,
+		o.BlockHash,
+		o.TotalTxs,
+		o.TotalOps,
+	}
+	if o.Burned > 0 {
+		fields = append(fields, o.Burned)
+	}
+
+	return rlp.Encode(w, fields)
+}
+
 func (o CollectTxFee) IsWellFormed(common.Config) (err error) {
 	if _, err = keypair.Parse(o.Target); err != nil {
 		return
@@ -69,6 +95,11 @@ func (o CollectTxFee) IsWellFormed(common.Config) (err error) {
 		return
 	}
 
+	if o.Burned > o.Amount {
+		err = errors.InvalidOperation
+		return
+	}
+
 	return
 }
 
@@ -76,8 +107,10 @@ func (o CollectTxFee) TargetAddress() string {
 	return o.Target
 }
 
+// GetAmount returns the amount deposited to `Target`, that is, `Amount`
+// without `Burned`.
 func (o CollectTxFee) GetAmount() common.Amount {
-	return o.Amount
+	return o.Amount - o.Burned
 }
 
 func (o CollectTxFee) Serialize() (encoded []byte, err error) {
@@ -0,0 +1,39 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestCollectTxFeeBurned(t *testing.T) {
+	conf := common.NewConfig()
+	target := keypair.Random().Address()
+	fee := common.BaseFee.MustMult(2)
+
+	o := NewCollectTxFee(target, fee, 2, 1, "block-hash", 1)
+	require.NoError(t, o.IsWellFormed(conf))
+	require.Equal(t, fee, o.GetAmount())
+
+	{ // without burning, the hash is same with the one before `Burned`
+		expected := common.MustMakeObjectHash([]interface{}{
+			o.Target, o.Amount, o.Txs, o.Height, o.BlockHash, o.TotalTxs, o.TotalOps,
+		})
+		require.Equal(t, expected, common.MustMakeObjectHash(o))
+	}
+
+	burned := o
+	burned.Burned = common.BaseFee
+	require.NoError(t, burned.IsWellFormed(conf))
+	require.Equal(t, fee-common.BaseFee, burned.GetAmount())
+	require.NotEqual(t, common.MustMakeObjectHash(o), common.MustMakeObjectHash(burned))
+
+	{ // can not burn more than collected
+		burned.Burned = fee + 1
+		require.Equal(t, errors.InvalidOperation, burned.IsWellFormed(conf))
+	}
+}
@@ -0,0 +1,88 @@
+package operation
+
+import (
+	"encoding/json"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// GovernanceProposal opens the proposal, which is voted by
+// `GovernanceVote` until the block of `CloseHeight`. `Contract` is the
+// content of the proposal; the network does not interpret it. The proposal is
+// tallied after the block of `CloseHeight` is stored.
+type GovernanceProposal struct {
+	Title       string `json:"title"`
+	Contract    []byte `json:"contract"`
+	CloseHeight uint64 `json:"close_height"`
+}
+
+func NewGovernanceProposal(title string, contract []byte, closeHeight uint64) GovernanceProposal {
+	return GovernanceProposal{
+		Title:       title,
+		Contract:    contract,
+		CloseHeight: closeHeight,
+	}
+}
+
+func (o GovernanceProposal) Serialize() (encoded []byte, err error) {
+	return json.Marshal(o)
+}
+
+// Implement transaction/operation : IsWellFormed
+func (o GovernanceProposal) IsWellFormed(common.Config) (err error) {
+	if len(o.Title) < 1 {
+		err = errors.OperationBodyInsufficient
+		return
+	}
+	if o.CloseHeight <= common.GenesisBlockHeight {
+		err = errors.InvalidOperation
+		return
+	}
+
+	return
+}
+
+type GovernanceChoice string
+
+const (
+	GovernanceChoiceYes     GovernanceChoice = "yes"
+	GovernanceChoiceNo      GovernanceChoice = "no"
+	GovernanceChoiceAbstain GovernanceChoice = "abstain"
+)
+
+// GovernanceVote votes `Choice` on the proposal of `Proposal`, the hash of
+// the `block.BlockOperation` of `GovernanceProposal`. The later vote of the
+// same source replaces the former.
+type GovernanceVote struct {
+	Proposal string           `json:"proposal"`
+	Choice   GovernanceChoice `json:"choice"`
+}
+
+func NewGovernanceVote(proposal string, choice GovernanceChoice) GovernanceVote {
+	return GovernanceVote{
+		Proposal: proposal,
+		Choice:   choice,
+	}
+}
+
+func (o GovernanceVote) Serialize() (encoded []byte, err error) {
+	return json.Marshal(o)
+}
+
+// Implement transaction/operation : IsWellFormed
+func (o GovernanceVote) IsWellFormed(common.Config) (err error) {
+	if len(o.Proposal) < 1 {
+		err = errors.OperationBodyInsufficient
+		return
+	}
+
+	switch o.Choice {
+	case GovernanceChoiceYes, GovernanceChoiceNo, GovernanceChoiceAbstain:
+	default:
+		err = errors.InvalidOperation
+		return
+	}
+
+	return
+}
@@ -0,0 +1,66 @@
+package operation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestGovernanceProposalIsWellFormed(t *testing.T) {
+	conf := common.NewConfig()
+
+	{
+		o := NewGovernanceProposal("raise the base fee", []byte("base fee to 20000"), 10)
+		require.NoError(t, o.IsWellFormed(conf))
+	}
+
+	{ // without title
+		o := NewGovernanceProposal("", []byte("base fee to 20000"), 10)
+		require.Equal(t, errors.OperationBodyInsufficient, o.IsWellFormed(conf))
+	}
+
+	{ // genesis height can not be the close height
+		o := NewGovernanceProposal("raise the base fee", nil, common.GenesisBlockHeight)
+		require.Equal(t, errors.InvalidOperation, o.IsWellFormed(conf))
+	}
+}
+
+func TestGovernanceVoteIsWellFormed(t *testing.T) {
+	conf := common.NewConfig()
+
+	for _, choice := range []GovernanceChoice{GovernanceChoiceYes, GovernanceChoiceNo, GovernanceChoiceAbstain} {
+		require.NoError(t, NewGovernanceVote("proposal", choice).IsWellFormed(conf))
+	}
+
+	{ // without proposal
+		o := NewGovernanceVote("", GovernanceChoiceYes)
+		require.Equal(t, errors.OperationBodyInsufficient, o.IsWellFormed(conf))
+	}
+
+	{ // unknown choice
+		o := NewGovernanceVote("proposal", GovernanceChoice("maybe"))
+		require.Equal(t, errors.InvalidOperation, o.IsWellFormed(conf))
+	}
+}
+
+func TestGovernanceSerialize(t *testing.T) {
+	for _, opb := range []Body{
+		NewGovernanceProposal("raise the base fee", []byte("base fee to 20000"), 10),
+		NewGovernanceVote("proposal", GovernanceChoiceNo),
+	} {
+		op, err := NewOperation(opb)
+		require.NoError(t, err)
+
+		b, err := op.Serialize()
+		require.NoError(t, err)
+
+		var decoded Operation
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		require.Equal(t, op.H.Type, decoded.H.Type)
+		require.Equal(t, op.B, decoded.B)
+	}
+}
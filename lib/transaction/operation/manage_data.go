@@ -0,0 +1,50 @@
+package operation
+
+import (
+	"encoding/json"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// ManageData sets the named value of the source account; if `Value` is empty,
+// the value of `Name` is deleted. The sizes of `Name` and `Value` are limited
+// by `Config.MaxAccountDataNameSize` and `Config.MaxAccountDataValueSize`.
+type ManageData struct {
+	Name  string `json:"name"`
+	Value []byte `json:"value"`
+}
+
+func NewManageData(name string, value []byte) ManageData {
+	return ManageData{
+		Name:  name,
+		Value: value,
+	}
+}
+
+func (o ManageData) Serialize() (encoded []byte, err error) {
+	return json.Marshal(o)
+}
+
+// IsDelete returns true if the operation deletes the value.
+func (o ManageData) IsDelete() bool {
+	return len(o.Value) < 1
+}
+
+// Implement transaction/operation : IsWellFormed
+func (o ManageData) IsWellFormed(conf common.Config) (err error) {
+	if len(o.Name) < 1 {
+		err = errors.InvalidOperation
+		return
+	}
+	if len(o.Name) > conf.MaxAccountDataNameSize {
+		err = errors.AccountDataNameTooLarge
+		return
+	}
+	if len(o.Value) > conf.MaxAccountDataValueSize {
+		err = errors.AccountDataValueTooLarge
+		return
+	}
+
+	return
+}
@@ -19,6 +19,14 @@ const (
 	TypeCollectTxFee         OperationType = "collect-tx-fee"
 	TypeInflation            OperationType = "inflation"
 	TypeUnfreezingRequest    OperationType = "unfreezing-request"
+	TypeSetSigners           OperationType = "set-signers"
+	TypeManageData           OperationType = "manage-data"
+	TypeValidatorSetChange   OperationType = "validator-set-change"
+	TypeTimeLockedPayment    OperationType = "time-locked-payment"
+	TypeTimeLockClaim        OperationType = "time-lock-claim"
+	TypeAccountMerge         OperationType = "account-merge"
+	TypeGovernanceProposal   OperationType = "governance-proposal"
+	TypeGovernanceVote       OperationType = "governance-vote"
 )
 
 func IsValidOperationType(oType string) bool {
@@ -29,6 +37,14 @@ func IsValidOperationType(oType string) bool {
 		string(TypeCongressVotingResult),
 		string(TypeCollectTxFee),
 		string(TypeInflation),
+		string(TypeSetSigners),
+		string(TypeManageData),
+		string(TypeValidatorSetChange),
+		string(TypeTimeLockedPayment),
+		string(TypeTimeLockClaim),
+		string(TypeAccountMerge),
+		string(TypeGovernanceProposal),
+		string(TypeGovernanceVote),
 	}, oType)
 	return b
 }
@@ -39,6 +55,14 @@ var KindsNormalTransaction map[OperationType]struct{} = map[OperationType]struct
 	TypeCongressVoting:       struct{}{},
 	TypeCongressVotingResult: struct{}{},
 	TypeUnfreezingRequest:    struct{}{},
+	TypeSetSigners:           struct{}{},
+	TypeManageData:           struct{}{},
+	TypeValidatorSetChange:   struct{}{},
+	TypeTimeLockedPayment:    struct{}{},
+	TypeTimeLockClaim:        struct{}{},
+	TypeAccountMerge:         struct{}{},
+	TypeGovernanceProposal:   struct{}{},
+	TypeGovernanceVote:       struct{}{},
 }
 
 type Operation struct {
@@ -63,6 +87,22 @@ func NewOperation(opb Body) (op Operation, err error) {
 		t = TypeCongressVoting
 	case CongressVotingResult:
 		t = TypeCongressVotingResult
+	case SetSigners:
+		t = TypeSetSigners
+	case ManageData:
+		t = TypeManageData
+	case ValidatorSetChange:
+		t = TypeValidatorSetChange
+	case TimeLockedPayment:
+		t = TypeTimeLockedPayment
+	case TimeLockClaim:
+		t = TypeTimeLockClaim
+	case AccountMerge:
+		t = TypeAccountMerge
+	case GovernanceProposal:
+		t = TypeGovernanceProposal
+	case GovernanceVote:
+		t = TypeGovernanceVote
 	default:
 		err = errors.UnknownOperationType
 		return
@@ -110,8 +150,14 @@ func (o Operation) MakeHashString() string {
 	return base58.Encode(o.MakeHash())
 }
 
+// IsWellFormed checks the body of operation and then runs the validators of
+// the operation type; see `RegisterValidator()`.
 func (o Operation) IsWellFormed(conf common.Config) (err error) {
-	return o.B.IsWellFormed(conf)
+	if err = o.B.IsWellFormed(conf); err != nil {
+		return
+	}
+
+	return runValidators(o, conf)
 }
 
 func (o Operation) Serialize() (encoded []byte, err error) {
@@ -193,6 +239,54 @@ func UnmarshalBodyJSON(t OperationType, b []byte) (body Body, err error) {
 			return
 		}
 		body = ob
+	case TypeSetSigners:
+		var ob SetSigners
+		if err = json.Unmarshal(b, &ob); err != nil {
+			return
+		}
+		body = ob
+	case TypeManageData:
+		var ob ManageData
+		if err = json.Unmarshal(b, &ob); err != nil {
+			return
+		}
+		body = ob
+	case TypeValidatorSetChange:
+		var ob ValidatorSetChange
+		if err = json.Unmarshal(b, &ob); err != nil {
+			return
+		}
+		body = ob
+	case TypeTimeLockedPayment:
+		var ob TimeLockedPayment
+		if err = json.Unmarshal(b, &ob); err != nil {
+			return
+		}
+		body = ob
+	case TypeTimeLockClaim:
+		var ob TimeLockClaim
+		if err = json.Unmarshal(b, &ob); err != nil {
+			return
+		}
+		body = ob
+	case TypeAccountMerge:
+		var ob AccountMerge
+		if err = json.Unmarshal(b, &ob); err != nil {
+			return
+		}
+		body = ob
+	case TypeGovernanceProposal:
+		var ob GovernanceProposal
+		if err = json.Unmarshal(b, &ob); err != nil {
+			return
+		}
+		body = ob
+	case TypeGovernanceVote:
+		var ob GovernanceVote
+		if err = json.Unmarshal(b, &ob); err != nil {
+			return
+		}
+		body = ob
 	default:
 		err = errors.InvalidOperation
 		return
@@ -2,12 +2,15 @@ package operation
 
 import (
 	"encoding/json"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
 )
 
 func TestMakeHashOfOperationBodyPayment(t *testing.T) {
@@ -91,3 +94,105 @@ func TestOperationBodyCongressVotingResult(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+func TestOperationBodySetSigners(t *testing.T) {
+	conf := common.NewConfig()
+	kp0 := keypair.Random()
+	kp1 := keypair.Random()
+
+	{ // reset to single signer
+		require.NoError(t, NewSetSigners(0).IsWellFormed(conf))
+		require.Error(t, NewSetSigners(0, Signer{kp0.Address(), 1}).IsWellFormed(conf))
+	}
+
+	{ // exact threshold
+		opb := NewSetSigners(3, Signer{kp0.Address(), 1}, Signer{kp1.Address(), 2})
+		require.NoError(t, opb.IsWellFormed(conf))
+
+		op, err := NewOperation(opb)
+		require.NoError(t, err)
+		require.Equal(t, TypeSetSigners, op.H.Type)
+
+		b, err := op.Serialize()
+		require.NoError(t, err)
+		var unmarshaled Operation
+		require.NoError(t, json.Unmarshal(b, &unmarshaled))
+		require.Equal(t, opb, unmarshaled.B)
+	}
+
+	{ // threshold over total weight
+		opb := NewSetSigners(4, Signer{kp0.Address(), 1}, Signer{kp1.Address(), 2})
+		require.Equal(t, errors.SignersThresholdUnreachable, opb.IsWellFormed(conf))
+	}
+
+	{ // duplicated signer
+		opb := NewSetSigners(2, Signer{kp0.Address(), 1}, Signer{kp0.Address(), 1})
+		require.Equal(t, errors.DuplicatedSigner, opb.IsWellFormed(conf))
+	}
+
+	{ // total weight overflows
+		opb := NewSetSigners(1, Signer{kp0.Address(), math.MaxUint64}, Signer{kp1.Address(), 1})
+		err := opb.IsWellFormed(conf)
+		require.Error(t, err)
+		require.Equal(t, errors.InvalidOperation.Code, err.(*errors.Error).Code)
+	}
+
+	{ // zero weight
+		opb := NewSetSigners(1, Signer{kp0.Address(), 1}, Signer{kp1.Address(), 0})
+		require.Equal(t, errors.InvalidOperation, opb.IsWellFormed(conf))
+	}
+
+	{ // invalid address
+		opb := NewSetSigners(1, Signer{"showme", 1})
+		require.Error(t, opb.IsWellFormed(conf))
+	}
+}
+
+func TestOperationBodyManageData(t *testing.T) {
+	conf := common.NewConfig()
+
+	{ // set
+		opb := NewManageData("showme", []byte("findme"))
+		require.NoError(t, opb.IsWellFormed(conf))
+		require.False(t, opb.IsDelete())
+
+		op, err := NewOperation(opb)
+		require.NoError(t, err)
+		require.Equal(t, TypeManageData, op.H.Type)
+
+		b, err := op.Serialize()
+		require.NoError(t, err)
+		var unmarshaled Operation
+		require.NoError(t, json.Unmarshal(b, &unmarshaled))
+		require.Equal(t, opb, unmarshaled.B)
+	}
+
+	{ // delete
+		opb := NewManageData("showme", nil)
+		require.NoError(t, opb.IsWellFormed(conf))
+		require.True(t, opb.IsDelete())
+	}
+
+	{ // empty name
+		opb := NewManageData("", []byte("findme"))
+		require.Equal(t, errors.InvalidOperation, opb.IsWellFormed(conf))
+	}
+
+	{ // exact size
+		opb := NewManageData(
+			strings.Repeat("n", conf.MaxAccountDataNameSize),
+			[]byte(strings.Repeat("v", conf.MaxAccountDataValueSize)),
+		)
+		require.NoError(t, opb.IsWellFormed(conf))
+	}
+
+	{ // oversize name
+		opb := NewManageData(strings.Repeat("n", conf.MaxAccountDataNameSize+1), []byte("findme"))
+		require.Equal(t, errors.AccountDataNameTooLarge, opb.IsWellFormed(conf))
+	}
+
+	{ // oversize value
+		opb := NewManageData("showme", []byte(strings.Repeat("v", conf.MaxAccountDataValueSize+1)))
+		require.Equal(t, errors.AccountDataValueTooLarge, opb.IsWellFormed(conf))
+	}
+}
@@ -0,0 +1,82 @@
+package operation
+
+import (
+	"encoding/json"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// Signer is the key which can sign the transactions of account with the
+// weight.
+type Signer struct {
+	Address string `json:"address"`
+	Weight  uint64 `json:"weight"`
+}
+
+// SetSigners sets the signers and the threshold of the source account. The
+// transaction of the account must be signed by the signers whose accumulated
+// weight meets `Threshold`. If `Threshold` is 0, the account is signed only by
+// its own key.
+//
+// The key of the account still signs every transaction by
+// `transaction.Header.Signature`; its weight is counted only when it is one of
+// `Signers`. `transaction.Transaction.IsWellFormed()` verifies the signatures
+// only, and the threshold is checked against the account state by
+// `ValidateTx()` of the node runner.
+type SetSigners struct {
+	Threshold uint64   `json:"threshold"`
+	Signers   []Signer `json:"signers"`
+}
+
+func NewSetSigners(threshold uint64, signers ...Signer) SetSigners {
+	return SetSigners{
+		Threshold: threshold,
+		Signers:   signers,
+	}
+}
+
+func (o SetSigners) Serialize() (encoded []byte, err error) {
+	return json.Marshal(o)
+}
+
+// Implement transaction/operation : IsWellFormed
+func (o SetSigners) IsWellFormed(common.Config) (err error) {
+	if o.Threshold < 1 {
+		if len(o.Signers) > 0 {
+			err = errors.InvalidOperation
+		}
+		return
+	}
+
+	var total uint64
+	var addresses []string
+	for _, s := range o.Signers {
+		if _, err = keypair.Parse(s.Address); err != nil {
+			return
+		}
+		if s.Weight < 1 {
+			err = errors.InvalidOperation
+			return
+		}
+		if _, found := common.InStringArray(addresses, s.Address); found {
+			err = errors.DuplicatedSigner
+			return
+		}
+		addresses = append(addresses, s.Address)
+
+		if total+s.Weight < total {
+			err = errors.InvalidOperation.Clone().SetData("error", "total weight of signers overflows")
+			return
+		}
+		total += s.Weight
+	}
+
+	if total < o.Threshold {
+		err = errors.SignersThresholdUnreachable
+		return
+	}
+
+	return
+}
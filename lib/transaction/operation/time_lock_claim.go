@@ -0,0 +1,42 @@
+package operation
+
+import (
+	"encoding/json"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// TimeLockClaim deposits the amount of the time lock, which is locked by
+// `Source` for the source of transaction with `UnlockHeight`, to the source of
+// transaction. It is valid only after the block of `UnlockHeight` is stored.
+type TimeLockClaim struct {
+	Source       string `json:"source"`
+	UnlockHeight uint64 `json:"unlock_height"`
+}
+
+func NewTimeLockClaim(source string, unlockHeight uint64) TimeLockClaim {
+	return TimeLockClaim{
+		Source:       source,
+		UnlockHeight: unlockHeight,
+	}
+}
+
+func (o TimeLockClaim) Serialize() (encoded []byte, err error) {
+	return json.Marshal(o)
+}
+
+// Implement transaction/operation : IsWellFormed
+func (o TimeLockClaim) IsWellFormed(common.Config) (err error) {
+	if _, err = keypair.Parse(o.Source); err != nil {
+		return
+	}
+
+	if o.UnlockHeight <= common.GenesisBlockHeight {
+		err = errors.InvalidOperation
+		return
+	}
+
+	return
+}
@@ -0,0 +1,58 @@
+package operation
+
+import (
+	"encoding/json"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// TimeLockedPayment withdraws `Amount` from the source like `Payment`, but
+// the amount is held in the time lock instead of being deposited to `Target`.
+// After the block of `UnlockHeight` is stored, `Target` can claim it by
+// `TimeLockClaim`.
+type TimeLockedPayment struct {
+	Target       string        `json:"target"`
+	Amount       common.Amount `json:"amount"`
+	UnlockHeight uint64        `json:"unlock_height"`
+}
+
+func NewTimeLockedPayment(target string, amount common.Amount, unlockHeight uint64) TimeLockedPayment {
+	return TimeLockedPayment{
+		Target:       target,
+		Amount:       amount,
+		UnlockHeight: unlockHeight,
+	}
+}
+
+func (o TimeLockedPayment) Serialize() (encoded []byte, err error) {
+	return json.Marshal(o)
+}
+
+// Implement transaction/operation : IsWellFormed
+func (o TimeLockedPayment) IsWellFormed(common.Config) (err error) {
+	if _, err = keypair.Parse(o.Target); err != nil {
+		return
+	}
+
+	if int64(o.Amount) < 1 {
+		err = errors.OperationAmountUnderflow
+		return
+	}
+
+	if o.UnlockHeight <= common.GenesisBlockHeight {
+		err = errors.InvalidOperation
+		return
+	}
+
+	return
+}
+
+func (o TimeLockedPayment) TargetAddress() string {
+	return o.Target
+}
+
+func (o TimeLockedPayment) GetAmount() common.Amount {
+	return o.Amount
+}
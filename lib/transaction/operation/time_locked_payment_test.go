@@ -0,0 +1,77 @@
+package operation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestTimeLockedPaymentIsWellFormed(t *testing.T) {
+	kp := keypair.Random()
+	conf := common.NewConfig()
+
+	{
+		o := NewTimeLockedPayment(kp.Address(), common.Amount(100), 10)
+		require.NoError(t, o.IsWellFormed(conf))
+	}
+
+	{ // invalid target
+		o := NewTimeLockedPayment("invalid", common.Amount(100), 10)
+		require.Error(t, o.IsWellFormed(conf))
+	}
+
+	{ // zero amount
+		o := NewTimeLockedPayment(kp.Address(), common.Amount(0), 10)
+		require.Equal(t, errors.OperationAmountUnderflow, o.IsWellFormed(conf))
+	}
+
+	{ // genesis height can not be locked
+		o := NewTimeLockedPayment(kp.Address(), common.Amount(100), common.GenesisBlockHeight)
+		require.Equal(t, errors.InvalidOperation, o.IsWellFormed(conf))
+	}
+}
+
+func TestTimeLockClaimIsWellFormed(t *testing.T) {
+	kp := keypair.Random()
+	conf := common.NewConfig()
+
+	{
+		o := NewTimeLockClaim(kp.Address(), 10)
+		require.NoError(t, o.IsWellFormed(conf))
+	}
+
+	{ // invalid source
+		o := NewTimeLockClaim("invalid", 10)
+		require.Error(t, o.IsWellFormed(conf))
+	}
+
+	{ // genesis height can not be locked
+		o := NewTimeLockClaim(kp.Address(), common.GenesisBlockHeight)
+		require.Equal(t, errors.InvalidOperation, o.IsWellFormed(conf))
+	}
+}
+
+func TestTimeLockSerialize(t *testing.T) {
+	kp := keypair.Random()
+
+	for _, opb := range []Body{
+		NewTimeLockedPayment(kp.Address(), common.Amount(100), 10),
+		NewTimeLockClaim(kp.Address(), 10),
+	} {
+		op, err := NewOperation(opb)
+		require.NoError(t, err)
+
+		b, err := op.Serialize()
+		require.NoError(t, err)
+
+		var decoded Operation
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		require.Equal(t, op.H.Type, decoded.H.Type)
+		require.Equal(t, op.B, decoded.B)
+	}
+}
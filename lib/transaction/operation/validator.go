@@ -0,0 +1,76 @@
+package operation
+
+import (
+	"sync"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// Validator is the custom validation of operation, which is registered by
+// `RegisterValidator()`, like allowing the payment only to the verified
+// accounts. It returns `*errors.Error` to reject the operation; the other
+// error is wrapped by `errors.OperationRejectedByValidator`.
+type Validator func(Operation, common.Config) error
+
+type validatorEntry struct {
+	id int
+	f  Validator
+}
+
+var validators = struct {
+	sync.RWMutex
+	lastID  int
+	entries map[OperationType][]validatorEntry
+}{
+	entries: map[OperationType][]validatorEntry{},
+}
+
+// RegisterValidator adds the validator of the operation type; the returned
+// function removes it. The validators are called by `Operation.IsWellFormed()`
+// in the order of registration, only after the built-in validation passes.
+//
+// `IsWellFormed()` is a part of consensus, so every validator of the network
+// must register the same validators.
+func RegisterValidator(t OperationType, f Validator) (unregister func()) {
+	validators.Lock()
+	defer validators.Unlock()
+
+	validators.lastID++
+	id := validators.lastID
+	validators.entries[t] = append(validators.entries[t], validatorEntry{id: id, f: f})
+
+	return func() {
+		validators.Lock()
+		defer validators.Unlock()
+
+		entries := validators.entries[t]
+		for i, e := range entries {
+			if e.id == id {
+				validators.entries[t] = append(entries[:i:i], entries[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func runValidators(o Operation, conf common.Config) (err error) {
+	validators.RLock()
+	entries := validators.entries[o.H.Type]
+	validators.RUnlock()
+
+	for _, e := range entries {
+		if err = e.f(o, conf); err == nil {
+			continue
+		}
+
+		if _, ok := err.(*errors.Error); !ok {
+			err = errors.OperationRejectedByValidator.Clone().
+				SetData("type", o.H.Type).
+				SetData("error", err.Error())
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,55 @@
+package operation
+
+import (
+	"encoding/json"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// ValidatorSetChange adds the validator of `Address` and `Endpoint` to the
+// validator set, or removes it if `Remove` is set. The change takes effect
+// after the block of `Height` is stored, so the proposer selection and the
+// threshold of the blocks after `Height` use the changed validator set. Only
+// the genesis account can change the validator set.
+type ValidatorSetChange struct {
+	Address  string `json:"address"`
+	Endpoint string `json:"endpoint"`
+	Remove   bool   `json:"remove"`
+	Height   uint64 `json:"height"`
+}
+
+func NewValidatorSetChange(address, endpoint string, remove bool, height uint64) ValidatorSetChange {
+	return ValidatorSetChange{
+		Address:  address,
+		Endpoint: endpoint,
+		Remove:   remove,
+		Height:   height,
+	}
+}
+
+func (o ValidatorSetChange) Serialize() (encoded []byte, err error) {
+	return json.Marshal(o)
+}
+
+// Implement transaction/operation : IsWellFormed
+func (o ValidatorSetChange) IsWellFormed(common.Config) (err error) {
+	if _, err = keypair.Parse(o.Address); err != nil {
+		return
+	}
+	if o.Height <= common.GenesisBlockHeight {
+		err = errors.InvalidOperation
+		return
+	}
+
+	// the added validator should be connected by endpoint
+	if !o.Remove {
+		if _, err = common.ParseEndpoint(o.Endpoint); err != nil {
+			err = errors.InvalidOperation
+			return
+		}
+	}
+
+	return
+}
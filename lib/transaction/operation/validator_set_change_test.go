@@ -0,0 +1,57 @@
+package operation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestValidatorSetChangeIsWellFormed(t *testing.T) {
+	kp := keypair.Random()
+	conf := common.NewConfig()
+
+	{ // add
+		o := NewValidatorSetChange(kp.Address(), "https://localhost:12345", false, 10)
+		require.NoError(t, o.IsWellFormed(conf))
+	}
+
+	{ // remove does not need endpoint
+		o := NewValidatorSetChange(kp.Address(), "", true, 10)
+		require.NoError(t, o.IsWellFormed(conf))
+	}
+
+	{ // invalid address
+		o := NewValidatorSetChange("invalid", "https://localhost:12345", false, 10)
+		require.Error(t, o.IsWellFormed(conf))
+	}
+
+	{ // add without endpoint
+		o := NewValidatorSetChange(kp.Address(), "", false, 10)
+		require.Equal(t, errors.InvalidOperation, o.IsWellFormed(conf))
+	}
+
+	{ // genesis height can not be changed
+		o := NewValidatorSetChange(kp.Address(), "https://localhost:12345", false, common.GenesisBlockHeight)
+		require.Equal(t, errors.InvalidOperation, o.IsWellFormed(conf))
+	}
+}
+
+func TestValidatorSetChangeSerialize(t *testing.T) {
+	kp := keypair.Random()
+
+	op, err := NewOperation(NewValidatorSetChange(kp.Address(), "https://localhost:12345", false, 10))
+	require.NoError(t, err)
+	require.Equal(t, TypeValidatorSetChange, op.H.Type)
+
+	b, err := op.Serialize()
+	require.NoError(t, err)
+
+	var decoded Operation
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, op.B, decoded.B)
+}
@@ -0,0 +1,76 @@
+package operation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// TestRegisterValidator registers the validator, which rejects the payment
+// over the threshold.
+func TestRegisterValidator(t *testing.T) {
+	conf := common.NewConfig()
+	threshold := common.Amount(1000)
+
+	var called int
+	unregister := RegisterValidator(TypePayment, func(op Operation, _ common.Config) error {
+		called++
+		if op.B.(Payment).Amount > threshold {
+			return errors.OperationRejectedByValidator.Clone().SetData("threshold", threshold)
+		}
+		return nil
+	})
+
+	require.NoError(t, MakeTestPayment(int(threshold)).IsWellFormed(conf))
+	require.Equal(t, 1, called)
+
+	err := MakeTestPayment(int(threshold) + 1).IsWellFormed(conf)
+	require.Error(t, err)
+	require.Equal(t, errors.OperationRejectedByValidator.Code, err.(*errors.Error).Code)
+	require.Equal(t, threshold, err.(*errors.Error).Data["threshold"])
+	require.Equal(t, 2, called)
+
+	{ // the built-in validation runs first
+		err := MakeTestPayment(0).IsWellFormed(conf)
+		require.Equal(t, errors.OperationAmountUnderflow, err)
+		require.Equal(t, 2, called)
+	}
+
+	{ // the other operation type is not affected
+		op, err := NewOperation(NewCreateAccount(keypair.Random().Address(), threshold*common.Amount(1000), ""))
+		require.NoError(t, err)
+		require.NoError(t, op.IsWellFormed(conf))
+		require.Equal(t, 2, called)
+	}
+
+	unregister()
+	require.NoError(t, MakeTestPayment(int(threshold)+1).IsWellFormed(conf))
+	require.Equal(t, 2, called)
+}
+
+func TestRegisterValidatorUntypedError(t *testing.T) {
+	conf := common.NewConfig()
+
+	unregister := RegisterValidator(TypePayment, func(Operation, common.Config) error {
+		return fmt.Errorf("not verified")
+	})
+	defer unregister()
+
+	var calledNext bool
+	defer RegisterValidator(TypePayment, func(Operation, common.Config) error {
+		calledNext = true
+		return nil
+	})()
+
+	err := MakeTestPayment(100).IsWellFormed(conf)
+	require.Error(t, err)
+	require.Equal(t, errors.OperationRejectedByValidator.Code, err.(*errors.Error).Code)
+	require.Equal(t, "not verified", err.(*errors.Error).Data["error"])
+	require.Equal(t, "body", err.(*errors.Error).Field())
+	require.False(t, calledNext)
+}
@@ -1,22 +1,47 @@
 package transaction
 
 import (
+	"sort"
+	"strings"
 	"sync"
+	"time"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
 )
 
 type Pool struct {
 	sync.RWMutex
 
 	Pool    map[ /* Transaction.GetHash() */ string]Transaction
-	Sources map[ /* Transaction.Source() */ string]bool
+	Sources map[ /* Transaction.Source() */ string]string // Transaction.GetHash()
 	hashes  []string // Transaction.GetHash()
+
+	received map[ /* Transaction.GetHash() */ string]time.Time
+
+	// held has the transactions with the future sequenceID; they are not
+	// available for the new ballot until they are released by `Release`.
+	held map[ /* Transaction.Source() */ string]map[ /* SequenceID */ uint64]Transaction
+}
+
+// maxHeldTransactionsPerSource is the maximum number of the held transactions
+// of one source account.
+const maxHeldTransactionsPerSource int = 16
+
+// PoolTransaction is the transaction in the pool with the local time, when it
+// was added to the pool.
+type PoolTransaction struct {
+	Transaction
+	Received time.Time
 }
 
 func NewPool() *Pool {
 	return &Pool{
-		Pool:    map[string]Transaction{},
-		Sources: map[string]bool{},
-		hashes:  []string{},
+		Pool:     map[string]Transaction{},
+		Sources:  map[string]string{},
+		hashes:   []string{},
+		received: map[string]time.Time{},
+		held:     map[string]map[uint64]Transaction{},
 	}
 }
 
@@ -52,8 +77,9 @@ func (tp *Pool) Add(tx Transaction) bool {
 	defer tp.Unlock()
 
 	tp.Pool[tx.GetHash()] = tx
-	tp.Sources[tx.Source()] = true
+	tp.Sources[tx.Source()] = tx.GetHash()
 	tp.hashes = append(tp.hashes, tx.GetHash())
+	tp.received[tx.GetHash()] = time.Now()
 
 	return true
 }
@@ -68,8 +94,11 @@ func (tp *Pool) Remove(hashes ...string) {
 
 	for _, hash := range hashes {
 		if tx, found := tp.Pool[hash]; found {
-			delete(tp.Sources, tx.Source())
+			if tp.Sources[tx.Source()] == hash {
+				delete(tp.Sources, tx.Source())
+			}
 			delete(tp.Pool, hash)
+			delete(tp.received, hash)
 			for i, h := range tp.hashes {
 				if h == hash {
 					tp.hashes = append(tp.hashes[:i], tp.hashes[i+1:]...)
@@ -80,6 +109,106 @@ func (tp *Pool) Remove(hashes ...string) {
 	}
 }
 
+// Hold keeps the transaction, which has the future sequenceID of the source
+// account, until the transactions of the gap are stored; see `Release`. It
+// returns false if the transaction of same source and sequenceID is already
+// held or the source has too many held transactions.
+func (tp *Pool) Hold(tx Transaction) bool {
+	tp.Lock()
+	defer tp.Unlock()
+
+	txs, found := tp.held[tx.Source()]
+	if !found {
+		txs = map[uint64]Transaction{}
+		tp.held[tx.Source()] = txs
+	}
+
+	if _, found = txs[tx.B.SequenceID]; found {
+		return false
+	}
+	if len(txs) >= maxHeldTransactionsPerSource {
+		return false
+	}
+
+	txs[tx.B.SequenceID] = tx
+
+	return true
+}
+
+// IsHeld checks the transaction is held by `Hold`.
+func (tp *Pool) IsHeld(hash string) bool {
+	tp.RLock()
+	defer tp.RUnlock()
+
+	for _, txs := range tp.held {
+		for _, tx := range txs {
+			if tx.GetHash() == hash {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// HeldLen returns the number of the held transactions.
+func (tp *Pool) HeldLen() int {
+	tp.RLock()
+	defer tp.RUnlock()
+
+	var n int
+	for _, txs := range tp.held {
+		n += len(txs)
+	}
+
+	return n
+}
+
+// Release moves the held transaction, which has the next sequenceID of the
+// source account by `expected`, into the pool, so it can be proposed; only if
+// the pool does not have the transaction of same source. The stale ones and
+// the ones of the unknown account are dropped.
+func (tp *Pool) Release(expected func(source string) (uint64, bool)) (released []Transaction) {
+	tp.RLock()
+	sources := make([]string, 0, len(tp.held))
+	for source := range tp.held {
+		sources = append(sources, source)
+	}
+	tp.RUnlock()
+
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		sequenceID, found := expected(source)
+
+		tp.Lock()
+		txs := tp.held[source]
+		for s := range txs {
+			if !found || s < sequenceID {
+				delete(txs, s)
+			}
+		}
+
+		if tx, ok := txs[sequenceID]; ok {
+			if _, exists := tp.Sources[source]; !exists {
+				delete(txs, sequenceID)
+				tp.Pool[tx.GetHash()] = tx
+				tp.Sources[source] = tx.GetHash()
+				tp.hashes = append(tp.hashes, tx.GetHash())
+				tp.received[tx.GetHash()] = time.Now()
+				released = append(released, tx)
+			}
+		}
+
+		if len(txs) < 1 {
+			delete(tp.held, source)
+		}
+		tp.Unlock()
+	}
+
+	return
+}
+
 func (tp *Pool) AvailableTransactions(transactionLimit int) []string {
 	if transactionLimit < 1 {
 		return nil
@@ -99,6 +228,103 @@ func (tp *Pool) AvailableTransactions(transactionLimit int) []string {
 	return ret
 }
 
+// SelectTransactions returns the hashes of the transactions for the new
+// ballot in the order of `policy`; the number of the hashes is
+// `transactionLimit` at most. With `common.TransactionSelectionFeeRate`, the
+// result depends only on the transactions in the pool, so the nodes, which
+// have the same pool, select the same transactions.
+func (tp *Pool) SelectTransactions(policy common.TransactionSelectionPolicy, transactionLimit int) []string {
+	if policy != common.TransactionSelectionFeeRate {
+		return tp.AvailableTransactions(transactionLimit)
+	}
+
+	if transactionLimit < 1 {
+		return nil
+	}
+
+	tp.RLock()
+	txs := make([]Transaction, 0, len(tp.hashes))
+	for _, hash := range tp.hashes {
+		txs = append(txs, tp.Pool[hash])
+	}
+	tp.RUnlock()
+
+	sort.SliceStable(txs, func(i, j int) bool {
+		return compareByFeeRate(txs[i], txs[j]) < 0
+	})
+
+	if len(txs) > transactionLimit {
+		txs = txs[:transactionLimit]
+	}
+
+	ret := make([]string, len(txs))
+	for i, tx := range txs {
+		ret[i] = tx.GetHash()
+	}
+
+	return ret
+}
+
+// compareByFeeRate returns negative if `a` comes before `b`; the higher fee
+// per operation first, and then the lower sequence ID and the lower hash.
+func compareByFeeRate(a, b Transaction) int {
+	// `a.Fee / len(a.Operations)` and `b.Fee / len(b.Operations)` are compared
+	// without the division.
+	ra := uint64(a.B.Fee) * uint64(len(b.B.Operations))
+	rb := uint64(b.B.Fee) * uint64(len(a.B.Operations))
+	switch {
+	case ra > rb:
+		return -1
+	case ra < rb:
+		return 1
+	}
+
+	switch {
+	case a.B.SequenceID < b.B.SequenceID:
+		return -1
+	case a.B.SequenceID > b.B.SequenceID:
+		return 1
+	}
+
+	return strings.Compare(a.GetHash(), b.GetHash())
+}
+
+// List returns the transactions in the pool in the order of insertion. If
+// `source` is not empty, only the transactions of the source are returned.
+// The list starts after the transaction of `cursor` hash and has `limit`
+// transactions at most; `0` means no limit. The pool is locked only while the
+// transactions are copied, so the caller does not block the insertion.
+func (tp *Pool) List(source, cursor string, limit uint64) (txs []PoolTransaction) {
+	tp.RLock()
+	defer tp.RUnlock()
+
+	hashes := tp.hashes
+	if len(source) > 0 {
+		hashes = nil
+		if hash, found := tp.Sources[source]; found {
+			hashes = []string{hash}
+		}
+	}
+
+	started := len(cursor) < 1
+	for _, hash := range hashes {
+		if !started {
+			started = hash == cursor
+			continue
+		}
+		if limit > 0 && uint64(len(txs)) >= limit {
+			break
+		}
+
+		txs = append(txs, PoolTransaction{
+			Transaction: tp.Pool[hash],
+			Received:    tp.received[hash],
+		})
+	}
+
+	return
+}
+
 func (tp *Pool) IsSameSource(source string) (found bool) {
 	tp.RLock()
 	defer tp.RUnlock()
@@ -107,3 +333,66 @@ func (tp *Pool) IsSameSource(source string) (found bool) {
 
 	return
 }
+
+// Replace replaces the transaction of same source and sequenceID in the pool
+// by the given transaction, only if the fee is higher than the existing one by
+// `minBump` at least. The replaced transaction keeps the order of the existing
+// one.
+func (tp *Pool) Replace(tx Transaction, minBump common.Amount) (old Transaction, err error) {
+	tp.Lock()
+	defer tp.Unlock()
+
+	if old, err = tp.replaceable(tx, minBump); err != nil {
+		return
+	}
+
+	delete(tp.Pool, old.GetHash())
+	delete(tp.received, old.GetHash())
+	tp.Pool[tx.GetHash()] = tx
+	tp.received[tx.GetHash()] = time.Now()
+	tp.Sources[tx.Source()] = tx.GetHash()
+	for i, h := range tp.hashes {
+		if h == old.GetHash() {
+			tp.hashes[i] = tx.GetHash()
+			break
+		}
+	}
+
+	return
+}
+
+// IsReplaceable checks the transaction can replace the transaction of same
+// source in the pool; see `Replace`.
+func (tp *Pool) IsReplaceable(tx Transaction, minBump common.Amount) (err error) {
+	tp.RLock()
+	defer tp.RUnlock()
+
+	_, err = tp.replaceable(tx, minBump)
+
+	return
+}
+
+func (tp *Pool) replaceable(tx Transaction, minBump common.Amount) (old Transaction, err error) {
+	hash, found := tp.Sources[tx.Source()]
+	if !found {
+		err = errors.TransactionNotFound
+		return
+	}
+	old = tp.Pool[hash]
+
+	if old.B.SequenceID != tx.B.SequenceID {
+		err = errors.TransactionSameSourceInPool
+		return
+	}
+
+	var required common.Amount
+	if required, err = old.B.Fee.Add(minBump); err != nil {
+		return
+	}
+	if tx.B.Fee <= old.B.Fee || tx.B.Fee < required {
+		err = errors.TransactionFeeBumpTooLow
+		return
+	}
+
+	return
+}
@@ -0,0 +1,267 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestPoolReplace(t *testing.T) {
+	networkID := []byte("sebak-unittest-pool")
+	minBump := common.BaseFee
+
+	kp, tx := TestMakeTransaction(networkID, 1)
+	_, other := TestMakeTransaction(networkID, 1)
+
+	newTx := func(fee common.Amount, sequenceID uint64) Transaction {
+		n := tx
+		n.B.Fee = fee
+		n.B.SequenceID = sequenceID
+		n.Sign(kp, networkID)
+		return n
+	}
+
+	pool := NewPool()
+	require.True(t, pool.Add(tx))
+	require.True(t, pool.Add(other))
+
+	{ // no transaction of same source
+		_, unknown := TestMakeTransaction(networkID, 1)
+		_, err := pool.Replace(unknown, minBump)
+		require.Equal(t, errors.TransactionNotFound, err)
+	}
+
+	{ // mismatched sequenceID
+		n := newTx(tx.B.Fee.MustAdd(minBump), tx.B.SequenceID+1)
+		require.Equal(t, errors.TransactionSameSourceInPool, pool.IsReplaceable(n, minBump))
+		_, err := pool.Replace(n, minBump)
+		require.Equal(t, errors.TransactionSameSourceInPool, err)
+		require.True(t, pool.Has(tx.GetHash()))
+	}
+
+	{ // insufficient bump
+		n := newTx(tx.B.Fee.MustAdd(minBump-1), tx.B.SequenceID)
+		require.Equal(t, errors.TransactionFeeBumpTooLow, pool.IsReplaceable(n, minBump))
+		_, err := pool.Replace(n, minBump)
+		require.Equal(t, errors.TransactionFeeBumpTooLow, err)
+
+		// same fee can not replace even without minimum bump
+		n = newTx(tx.B.Fee, tx.B.SequenceID)
+		require.Equal(t, errors.TransactionFeeBumpTooLow, pool.IsReplaceable(n, 0))
+		require.True(t, pool.Has(tx.GetHash()))
+	}
+
+	{ // successful replacement
+		n := newTx(tx.B.Fee.MustAdd(minBump), tx.B.SequenceID)
+		require.NoError(t, pool.IsReplaceable(n, minBump))
+
+		replaced, err := pool.Replace(n, minBump)
+		require.NoError(t, err)
+		require.Equal(t, tx.GetHash(), replaced.GetHash())
+
+		require.False(t, pool.Has(tx.GetHash()))
+		require.True(t, pool.Has(n.GetHash()))
+		require.True(t, pool.IsSameSource(kp.Address()))
+		require.Equal(t, 2, pool.Len())
+
+		// the order is kept
+		require.Equal(t, []string{n.GetHash(), other.GetHash()}, pool.AvailableTransactions(10))
+
+		pool.Remove(n.GetHash())
+		require.False(t, pool.IsSameSource(kp.Address()))
+	}
+}
+
+func TestPoolList(t *testing.T) {
+	networkID := []byte("sebak-unittest-pool")
+
+	pool := NewPool()
+	require.Equal(t, 0, len(pool.List("", "", 0)))
+
+	var txs []Transaction
+	for i := 0; i < 5; i++ {
+		_, tx := TestMakeTransaction(networkID, 1)
+		require.True(t, pool.Add(tx))
+		txs = append(txs, tx)
+	}
+
+	hashes := func(l []PoolTransaction) (r []string) {
+		for _, tx := range l {
+			r = append(r, tx.GetHash())
+		}
+		return
+	}
+
+	{ // all, in the order of insertion
+		l := pool.List("", "", 0)
+		require.Equal(t, 5, len(l))
+		for i, tx := range l {
+			require.Equal(t, txs[i].GetHash(), tx.GetHash())
+			require.False(t, tx.Received.IsZero())
+		}
+	}
+
+	{ // source
+		l := pool.List(txs[2].Source(), "", 0)
+		require.Equal(t, []string{txs[2].GetHash()}, hashes(l))
+
+		require.Equal(t, 0, len(pool.List("unknown", "", 0)))
+	}
+
+	{ // cursor and limit
+		l := pool.List("", "", 2)
+		require.Equal(t, []string{txs[0].GetHash(), txs[1].GetHash()}, hashes(l))
+
+		l = pool.List("", l[1].GetHash(), 2)
+		require.Equal(t, []string{txs[2].GetHash(), txs[3].GetHash()}, hashes(l))
+
+		l = pool.List("", l[1].GetHash(), 2)
+		require.Equal(t, []string{txs[4].GetHash()}, hashes(l))
+
+		require.Equal(t, 0, len(pool.List("", txs[4].GetHash(), 2)))
+	}
+
+	{ // removed
+		pool.Remove(txs[1].GetHash())
+		l := pool.List("", "", 0)
+		require.Equal(t, 4, len(l))
+		require.NotContains(t, hashes(l), txs[1].GetHash())
+		require.Equal(t, 0, len(pool.List(txs[1].Source(), "", 0)))
+	}
+}
+
+func TestPoolSelectTransactions(t *testing.T) {
+	networkID := []byte("sebak-unittest-pool")
+
+	newTx := func(n int, fee common.Amount, sequenceID uint64) Transaction {
+		kp, tx := TestMakeTransaction(networkID, n)
+		tx.B.Fee = fee
+		tx.B.SequenceID = sequenceID
+		tx.Sign(kp, networkID)
+		return tx
+	}
+
+	high := newTx(1, common.BaseFee*3, 0)      // fee rate, 3x
+	highMulti := newTx(2, common.BaseFee*6, 0) // fee rate, 3x
+	middle := newTx(2, common.BaseFee*4, 0)    // fee rate, 2x
+	lowOld := newTx(1, common.BaseFee, 0)      // fee rate, 1x
+	lowNew := newTx(1, common.BaseFee, 1)      // fee rate, 1x, higher sequence ID
+	lowSame := newTx(1, common.BaseFee, 1)     // same with `lowNew` except hash
+	txs := []Transaction{lowNew, middle, lowSame, high, lowOld, highMulti}
+
+	expected := []string{}
+	{
+		sameRate := []string{high.GetHash(), highMulti.GetHash()}
+		if sameRate[0] > sameRate[1] {
+			sameRate[0], sameRate[1] = sameRate[1], sameRate[0]
+		}
+		sameSequence := []string{lowNew.GetHash(), lowSame.GetHash()}
+		if sameSequence[0] > sameSequence[1] {
+			sameSequence[0], sameSequence[1] = sameSequence[1], sameSequence[0]
+		}
+
+		expected = append(expected, sameRate...)
+		expected = append(expected, middle.GetHash(), lowOld.GetHash())
+		expected = append(expected, sameSequence...)
+	}
+
+	pool := NewPool()
+	for _, tx := range txs {
+		require.True(t, pool.Add(tx))
+	}
+
+	{ // by fee rate, sequence ID and hash
+		require.Equal(t, expected, pool.SelectTransactions(common.TransactionSelectionFeeRate, 10))
+		require.Equal(t, expected[:3], pool.SelectTransactions(common.TransactionSelectionFeeRate, 3))
+		require.Equal(t, 0, len(pool.SelectTransactions(common.TransactionSelectionFeeRate, 0)))
+	}
+
+	{ // the same result from the pool of the different order of receipt
+		reversed := NewPool()
+		for i := len(txs) - 1; i >= 0; i-- {
+			require.True(t, reversed.Add(txs[i]))
+		}
+		require.Equal(t, expected, reversed.SelectTransactions(common.TransactionSelectionFeeRate, 10))
+	}
+
+	{ // by the order of receipt
+		var fifo []string
+		for _, tx := range txs {
+			fifo = append(fifo, tx.GetHash())
+		}
+		require.Equal(t, fifo, pool.SelectTransactions(common.TransactionSelectionFIFO, 10))
+	}
+}
+
+func TestPoolHoldAndRelease(t *testing.T) {
+	networkID := []byte("sebak-unittest-pool")
+
+	kp, tx := TestMakeTransaction(networkID, 1)
+	newTx := func(sequenceID uint64) Transaction {
+		n := tx
+		n.B.SequenceID = sequenceID
+		n.Sign(kp, networkID)
+		return n
+	}
+
+	current := newTx(3)
+	next := newTx(4)
+	later := newTx(5)
+	stale := newTx(2)
+
+	pool := NewPool()
+	require.True(t, pool.Add(current))
+	require.True(t, pool.Hold(next))
+	require.True(t, pool.Hold(later))
+	require.True(t, pool.Hold(stale))
+
+	// same source and sequenceID can not be held again
+	require.False(t, pool.Hold(newTx(4)))
+
+	// the held ones are not available
+	require.Equal(t, 3, pool.HeldLen())
+	require.True(t, pool.IsHeld(next.GetHash()))
+	require.False(t, pool.Has(next.GetHash()))
+	require.Equal(t, []string{current.GetHash()}, pool.AvailableTransactions(10))
+
+	sequenceIDs := map[string]uint64{kp.Address(): 4}
+	expected := func(source string) (sequenceID uint64, found bool) {
+		sequenceID, found = sequenceIDs[source]
+		return
+	}
+
+	{ // the transaction of same source is still in the pool; the stale one is dropped
+		require.Equal(t, 0, len(pool.Release(expected)))
+		require.Equal(t, 2, pool.HeldLen())
+		require.False(t, pool.IsHeld(stale.GetHash()))
+	}
+
+	{ // released
+		pool.Remove(current.GetHash())
+		released := pool.Release(expected)
+		require.Equal(t, 1, len(released))
+		require.Equal(t, next.GetHash(), released[0].GetHash())
+
+		require.True(t, pool.Has(next.GetHash()))
+		require.True(t, pool.IsSameSource(kp.Address()))
+		require.Equal(t, []string{next.GetHash()}, pool.AvailableTransactions(10))
+		require.Equal(t, 1, pool.HeldLen())
+	}
+
+	{ // the unknown account
+		pool.Remove(next.GetHash())
+		delete(sequenceIDs, kp.Address())
+		require.Equal(t, 0, len(pool.Release(expected)))
+		require.Equal(t, 0, pool.HeldLen())
+	}
+
+	{ // too many held transactions of one source
+		for i := 0; i < maxHeldTransactionsPerSource; i++ {
+			require.True(t, pool.Hold(newTx(uint64(10+i))))
+		}
+		require.False(t, pool.Hold(newTx(uint64(10+maxHeldTransactionsPerSource))))
+	}
+}
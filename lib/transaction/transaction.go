@@ -1,9 +1,12 @@
 package transaction
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
+	"io"
 
 	"github.com/btcsuite/btcutil/base58"
+	"github.com/ethereum/go-ethereum/rlp"
 
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/keypair"
@@ -32,6 +35,16 @@ type Header struct {
 	// has to validate it anyway.
 	Hash      string `json:"-"`
 	Signature string `json:"signature"`
+	// Signatures are the additional signatures for the account, which has
+	// multiple signers; see `operation.SetSigners`. `Signature` of the source
+	// is still required.
+	Signatures []Signature `json:"signatures,omitempty"`
+}
+
+// Signature is signed by `Signer` of <networkID> + `Hash` of transaction.
+type Signature struct {
+	Signer    string `json:"signer"`
+	Signature string `json:"signature"`
 }
 
 type Body struct {
@@ -39,6 +52,30 @@ type Body struct {
 	Fee        common.Amount         `json:"fee"`
 	SequenceID uint64                `json:"sequence_id"`
 	Operations []operation.Operation `json:"operations"`
+	// ValidUntilHeight is the last block height, which the transaction can be
+	// included in. 0 means no expiry.
+	ValidUntilHeight uint64 `json:"valid_until_height,omitempty"`
+	// Memo is the optional information of transaction; see `Memo`.
+	Memo *Memo `json:"memo,omitempty"`
+}
+
+// EncodeRLP encodes `Body` without `ValidUntilHeight` and `Memo` if they are
+// not set, so the hash of the transaction without them is same with before.
+func (tb Body) EncodeRLP(w io.Writer) error {
+	fields := []interface{}{
+		tb.Source,
+		tb.Fee,
+		tb.SequenceID,
+		tb.Operations,
+	}
+	if tb.ValidUntilHeight > 0 || tb.Memo != nil {
+		fields = append(fields, tb.ValidUntilHeight)
+	}
+	if tb.Memo != nil {
+		fields = append(fields, *tb.Memo)
+	}
+
+	return rlp.Encode(w, fields)
 }
 
 func (tb Body) MakeHash() []byte {
@@ -87,6 +124,9 @@ func NewTransaction(source string, sequenceID uint64, ops ...operation.Operation
 
 var TransactionWellFormedCheckerFuncs = []common.CheckerFunc{
 	CheckOverOperationsLimit,
+	CheckTransactionSize,
+	CheckOperationBodySize,
+	CheckMemo,
 	CheckSequenceID,
 	CheckSource,
 	CheckBaseFee,
@@ -95,11 +135,38 @@ var TransactionWellFormedCheckerFuncs = []common.CheckerFunc{
 	CheckVerifySignature,
 }
 
+// VerifyNetworkID checks the transaction is signed by the source for the
+// network before the other checks; the signature mixes in the network ID, so
+// the transaction signed for the other network can not be told from the
+// forged one and both are rejected with `errors.MessageSignatureInvalid`. The malformed signature is left to `IsWellFormed()`.
+func (tx Transaction) VerifyNetworkID(networkID []byte) (err error) {
+	var kp keypair.KP
+	if kp, err = keypair.Parse(tx.B.Source); err != nil {
+		return
+	}
+
+	signature := base58.Decode(tx.H.Signature)
+	if len(signature) != ed25519.SignatureSize {
+		return
+	}
+
+	if err = kp.Verify(append(networkID, []byte(tx.H.Hash)...), signature); err != nil {
+		err = errors.MessageSignatureInvalid
+		return
+	}
+
+	return
+}
+
 func (tx Transaction) IsWellFormed(networkID []byte, conf common.Config) (err error) {
 	// TODO check `Version` format with SemVer
 
+	return tx.runCheckers(TransactionWellFormedCheckerFuncs, networkID, conf)
+}
+
+func (tx Transaction) runCheckers(funcs []common.CheckerFunc, networkID []byte, conf common.Config) (err error) {
 	checker := &Checker{
-		DefaultChecker: common.DefaultChecker{Funcs: TransactionWellFormedCheckerFuncs},
+		DefaultChecker: common.DefaultChecker{Funcs: funcs},
 		NetworkID:      networkID,
 		Transaction:    tx,
 		Conf:           conf,
@@ -126,6 +193,26 @@ func (tx Transaction) IsValidSequenceID(sequenceID uint64) bool {
 	return tx.B.SequenceID == sequenceID
 }
 
+// CheckSequenceID checks the sequenceID of transaction against the expected
+// one of source account. The stale sequenceID can not be valid anymore, but
+// the future one can be valid after the transactions of the gap are stored.
+func (tx Transaction) CheckSequenceID(expected uint64) error {
+	switch {
+	case tx.B.SequenceID < expected:
+		return errors.TransactionStaleSequenceID
+	case tx.B.SequenceID > expected:
+		return errors.TransactionFutureSequenceID
+	default:
+		return nil
+	}
+}
+
+// IsExpired checks the transaction can not be included in the block of the
+// given height by `ValidUntilHeight`.
+func (tx Transaction) IsExpired(height uint64) bool {
+	return tx.B.ValidUntilHeight > 0 && height > tx.B.ValidUntilHeight
+}
+
 func (tx Transaction) GetHash() string {
 	return tx.H.Hash
 }
@@ -161,9 +248,35 @@ func (tx Transaction) TotalAmount(withFee bool) common.Amount {
 	return amount
 }
 
-// TotalBaseFee returns the minimum fee of transaction.
-func (tx Transaction) TotalBaseFee() common.Amount {
-	return common.BaseFee.MustMult(len(tx.B.Operations))
+// AccountMerge returns the `operation.AccountMerge` of transaction; it must be
+// the last operation.
+func (tx Transaction) AccountMerge() (operation.AccountMerge, bool) {
+	if len(tx.B.Operations) < 1 {
+		return operation.AccountMerge{}, false
+	}
+
+	pop, ok := tx.B.Operations[len(tx.B.Operations)-1].B.(operation.AccountMerge)
+	return pop, ok
+}
+
+// TotalBaseFee returns the minimum fee of transaction, the sum of the base
+// fees of the operations by the fee policy.
+func (tx Transaction) TotalBaseFee(policy common.FeePolicy) common.Amount {
+	var fee common.Amount
+	for _, op := range tx.B.Operations {
+		fee = fee.MustAdd(policy.Fee(string(op.H.Type)))
+	}
+
+	return fee
+}
+
+// Weight returns the sum of the weights of the operations by `weights`.
+func (tx Transaction) Weight(weights common.OperationWeights) (weight uint64) {
+	for _, op := range tx.B.Operations {
+		weight += weights.Weight(string(op.H.Type))
+	}
+
+	return
 }
 
 func (tx Transaction) Serialize() (encoded []byte, err error) {
@@ -186,6 +299,29 @@ func (tx *Transaction) Sign(kp keypair.KP, networkID []byte) {
 	return
 }
 
+// AddSignature adds the additional signature by the other signer of source
+// account. It should be called after `Sign`, because `Sign` changes the hash.
+func (tx *Transaction) AddSignature(kp keypair.KP, networkID []byte) {
+	signature, _ := keypair.MakeSignature(kp, networkID, tx.H.Hash)
+	tx.H.Signatures = append(tx.H.Signatures, Signature{
+		Signer:    kp.Address(),
+		Signature: base58.Encode(signature),
+	})
+
+	return
+}
+
+// Signers returns the addresses which signed the transaction, the source and
+// the signers of additional signatures.
+func (tx Transaction) Signers() []string {
+	signers := []string{tx.B.Source}
+	for _, s := range tx.H.Signatures {
+		signers = append(signers, s.Signer)
+	}
+
+	return signers
+}
+
 func (tx Transaction) IsEmpty() bool {
 	return len(tx.GetHash()) < 1
 }
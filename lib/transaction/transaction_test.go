@@ -80,6 +80,61 @@ func (suite *TestSuite) TestIsWellFormedTransactionWithLowerFeeSuite() {
 	}
 }
 
+func (suite *TestSuite) TestIsWellFormedTransactionWithFeePolicySuite() {
+	conf := suite.conf
+	conf.FeePolicy = common.FeePolicy{
+		string(operation.TypeCreateAccount): common.BaseFee.MustMult(5),
+		string(operation.TypeManageData):    common.BaseFee.MustMult(2),
+	}
+
+	kp := keypair.Random()
+	newOperation := func(opb operation.Body) operation.Operation {
+		op, err := operation.NewOperation(opb)
+		require.NoError(suite.T(), err)
+		return op
+	}
+
+	ops := []operation.Operation{
+		newOperation(operation.NewCreateAccount(keypair.Random().Address(), common.BaseReserve, "")),
+		newOperation(operation.NewPayment(keypair.Random().Address(), common.Amount(1))),
+		newOperation(operation.NewPayment(keypair.Random().Address(), common.Amount(1))),
+		newOperation(operation.NewManageData("showme", []byte("findme"))),
+	}
+
+	// 5 + 1 + 1 + 2
+	expected := common.BaseFee.MustMult(9)
+
+	tx, err := NewTransaction(kp.Address(), 0, ops...)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), expected, tx.TotalBaseFee(conf.FeePolicy))
+
+	// the flat fee policy
+	require.Equal(suite.T(), common.BaseFee.MustMult(len(ops)), tx.TotalBaseFee(common.DefaultFeePolicy))
+	require.Equal(suite.T(), common.BaseFee.MustMult(len(ops)), tx.TotalBaseFee(nil))
+
+	{ // the flat fee is lower than the minimum fee
+		tx.Sign(kp, suite.networkID)
+		require.NoError(suite.T(), tx.IsWellFormed(suite.networkID, suite.conf))
+		require.Equal(suite.T(), errors.InvalidFee, tx.IsWellFormed(suite.networkID, conf))
+	}
+
+	{ // same with the minimum fee
+		tx.B.Fee = expected
+		tx.Sign(kp, suite.networkID)
+		require.NoError(suite.T(), tx.IsWellFormed(suite.networkID, conf))
+
+		tx.B.Fee = expected.MustSub(1)
+		tx.Sign(kp, suite.networkID)
+		require.Equal(suite.T(), errors.InvalidFee, tx.IsWellFormed(suite.networkID, conf))
+	}
+
+	{ // the builder uses the minimum fee
+		tx, err := NewTransactionBuilder(kp.Address()).AddOperation(ops...).Build(conf)
+		require.NoError(suite.T(), err)
+		require.Equal(suite.T(), expected, tx.B.Fee)
+	}
+}
+
 func (suite *TestSuite) TestIsWellFormedTransactionWithInvalidSourceAddressSuite() {
 	var err error
 
@@ -132,6 +187,242 @@ func (suite *TestSuite) TestIsWellFormedTransactionMaxOperationsInTransactionSui
 	}
 }
 
+func (suite *TestSuite) TestIsWellFormedTransactionOperationBodySizeSuite() {
+	kp := keypair.Random()
+	makeTx := func(contractSize int) Transaction {
+		op, err := operation.NewOperation(operation.NewCongressVoting(make([]byte, contractSize), 1, 100))
+		require.NoError(suite.T(), err)
+
+		tx, err := NewTransaction(kp.Address(), 0, op)
+		require.NoError(suite.T(), err)
+		tx.Sign(kp, suite.networkID)
+
+		return tx
+	}
+
+	{ // over `MaxOperationBodySize`
+		tx := makeTx(suite.conf.MaxOperationBodySize)
+		err := tx.IsWellFormed(suite.networkID, suite.conf)
+		require.Equal(suite.T(), errors.OperationBodyTooLarge, err)
+	}
+
+	{ // at the boundary
+		tx := makeTx(100)
+		b, err := tx.B.Operations[0].B.Serialize()
+		require.NoError(suite.T(), err)
+
+		conf := suite.conf
+		conf.MaxOperationBodySize = len(b)
+		require.NotEqual(suite.T(), errors.OperationBodyTooLarge, tx.IsWellFormed(suite.networkID, conf))
+
+		conf.MaxOperationBodySize = len(b) - 1
+		require.Equal(suite.T(), errors.OperationBodyTooLarge, tx.IsWellFormed(suite.networkID, conf))
+	}
+
+	{ // normal operation is far below the limit
+		_, tx := TestMakeTransaction(suite.networkID, 1)
+		require.NoError(suite.T(), tx.IsWellFormed(suite.networkID, suite.conf))
+	}
+}
+
+func (suite *TestSuite) TestIsWellFormedTransactionSizeSuite() {
+	_, tx := TestMakeTransaction(suite.networkID, 3)
+
+	b, err := tx.Serialize()
+	require.NoError(suite.T(), err)
+
+	conf := suite.conf
+	{ // just under the limit
+		conf.MaxTransactionSize = len(b) + 1
+		require.NoError(suite.T(), tx.IsWellFormed(suite.networkID, conf))
+	}
+
+	{ // at the limit
+		conf.MaxTransactionSize = len(b)
+		require.NoError(suite.T(), tx.IsWellFormed(suite.networkID, conf))
+	}
+
+	{ // just over the limit
+		conf.MaxTransactionSize = len(b) - 1
+		require.Equal(suite.T(), errors.TransactionTooLarge, tx.IsWellFormed(suite.networkID, conf))
+	}
+}
+
+func (suite *TestSuite) TestValidUntilHeightSuite() {
+	kp, tx := TestMakeTransaction(suite.networkID, 1)
+
+	{ // without `ValidUntilHeight`, the hash and the serialized data are same with before
+		legacy := struct {
+			Source     string
+			Fee        common.Amount
+			SequenceID uint64
+			Operations []operation.Operation
+		}{
+			Source:     tx.B.Source,
+			Fee:        tx.B.Fee,
+			SequenceID: tx.B.SequenceID,
+			Operations: tx.B.Operations,
+		}
+		require.Equal(suite.T(), base58.Encode(common.MustMakeObjectHash(legacy)), tx.GetHash())
+
+		b, err := tx.Serialize()
+		require.NoError(suite.T(), err)
+		require.NotContains(suite.T(), string(b), "valid_until_height")
+
+		require.False(suite.T(), tx.IsExpired(1))
+		require.False(suite.T(), tx.IsExpired(^uint64(0)))
+	}
+
+	{ // with `ValidUntilHeight`, it is covered by hash
+		hashWithoutExpiry := tx.GetHash()
+
+		tx.B.ValidUntilHeight = 10
+		tx.Sign(kp, suite.networkID)
+		require.NotEqual(suite.T(), hashWithoutExpiry, tx.GetHash())
+		require.NoError(suite.T(), tx.IsWellFormed(suite.networkID, suite.conf))
+
+		b, err := tx.Serialize()
+		require.NoError(suite.T(), err)
+
+		var tx2 Transaction
+		require.NoError(suite.T(), json.Unmarshal(b, &tx2))
+		require.Equal(suite.T(), uint64(10), tx2.B.ValidUntilHeight)
+		require.Equal(suite.T(), tx.GetHash(), tx2.GetHash())
+
+		require.False(suite.T(), tx.IsExpired(9))
+		require.False(suite.T(), tx.IsExpired(10))
+		require.True(suite.T(), tx.IsExpired(11))
+	}
+}
+
+func (suite *TestSuite) TestMultipleSignaturesSuite() {
+	_, tx := TestMakeTransaction(suite.networkID, 1)
+	hash := tx.GetHash()
+
+	{ // without additional signatures, it is not serialized
+		b, err := tx.Serialize()
+		require.NoError(suite.T(), err)
+		require.NotContains(suite.T(), string(b), "signatures")
+	}
+
+	kp1 := keypair.Random()
+	kp2 := keypair.Random()
+	tx.AddSignature(kp1, suite.networkID)
+	tx.AddSignature(kp2, suite.networkID)
+	require.Equal(suite.T(), hash, tx.GetHash())
+	require.Equal(suite.T(), []string{tx.Source(), kp1.Address(), kp2.Address()}, tx.Signers())
+	require.NoError(suite.T(), tx.IsWellFormed(suite.networkID, suite.conf))
+
+	{ // serialized with additional signatures
+		b, err := tx.Serialize()
+		require.NoError(suite.T(), err)
+
+		var tx2 Transaction
+		require.NoError(suite.T(), json.Unmarshal(b, &tx2))
+		require.Equal(suite.T(), tx.H.Signatures, tx2.H.Signatures)
+		require.NoError(suite.T(), tx2.IsWellFormed(suite.networkID, suite.conf))
+	}
+
+	{ // signed by wrong key
+		wrong := tx
+		wrong.H.Signatures = append([]Signature{}, tx.H.Signatures...)
+		signature, _ := keypair.MakeSignature(keypair.Random(), suite.networkID, tx.GetHash())
+		wrong.H.Signatures[1].Signature = base58.Encode(signature)
+		require.Error(suite.T(), wrong.IsWellFormed(suite.networkID, suite.conf))
+	}
+
+	{ // duplicated signer
+		duplicated := tx
+		duplicated.H.Signatures = append([]Signature{}, tx.H.Signatures...)
+		duplicated.AddSignature(kp1, suite.networkID)
+		require.Equal(suite.T(), errors.DuplicatedSigner, duplicated.IsWellFormed(suite.networkID, suite.conf))
+	}
+}
+
+func (suite *TestSuite) TestDerivedKeypairSuite() {
+	kp, err := keypair.Derive(keypair.Master("sebak-test-seed"), 1)
+	require.NoError(suite.T(), err)
+
+	_, tx := TestMakeTransaction(suite.networkID, 1)
+	tx.Sign(kp, suite.networkID)
+	require.Equal(suite.T(), kp.Address(), tx.Source())
+	require.NoError(suite.T(), tx.IsWellFormed(suite.networkID, suite.conf))
+
+	// signed by the other derived keypair
+	other, _ := keypair.Derive(keypair.Master("sebak-test-seed"), 2)
+	signature, _ := keypair.MakeSignature(other, suite.networkID, tx.GetHash())
+	tx.H.Signature = base58.Encode(signature)
+	require.Error(suite.T(), tx.IsWellFormed(suite.networkID, suite.conf))
+}
+
+func (suite *TestSuite) TestCheckSequenceIDSuite() {
+	_, tx := TestMakeTransaction(suite.networkID, 1)
+	tx.B.SequenceID = 3
+
+	require.Equal(suite.T(), errors.TransactionStaleSequenceID, tx.CheckSequenceID(4))
+	require.NoError(suite.T(), tx.CheckSequenceID(3))
+	require.Equal(suite.T(), errors.TransactionFutureSequenceID, tx.CheckSequenceID(2))
+}
+
+func (suite *TestSuite) TestWeightSuite() {
+	kp := keypair.Random()
+
+	createAccount, _ := operation.NewOperation(operation.NewCreateAccount(keypair.Random().Address(), common.BaseReserve, ""))
+	payment, _ := operation.NewOperation(operation.NewPayment(keypair.Random().Address(), common.Amount(1)))
+
+	tx, err := NewTransaction(kp.Address(), 0, createAccount, payment, payment)
+	require.NoError(suite.T(), err)
+
+	weights := common.OperationWeights{string(operation.TypeCreateAccount): 10}
+	require.Equal(suite.T(), uint64(10+1+1), tx.Weight(weights))
+
+	// the flat weights
+	require.Equal(suite.T(), uint64(3), tx.Weight(common.DefaultOperationWeights))
+	require.Equal(suite.T(), uint64(3), tx.Weight(nil))
+}
+
+func (suite *TestSuite) TestOperationFilterSuite() {
+	kp := keypair.Random()
+
+	createAccount, _ := operation.NewOperation(operation.NewCreateAccount(keypair.Random().Address(), common.BaseReserve, ""))
+	payment, _ := operation.NewOperation(operation.NewPayment(keypair.Random().Address(), common.Amount(1)))
+
+	txCreateAccount, _ := NewTransaction(kp.Address(), 0, createAccount)
+	txCreateAccount.Sign(kp, suite.networkID)
+	txPayment, _ := NewTransaction(kp.Address(), 0, payment)
+	txPayment.Sign(kp, suite.networkID)
+
+	conf := common.NewConfig()
+	require.NoError(suite.T(), txCreateAccount.IsWellFormed(suite.networkID, conf))
+	require.NoError(suite.T(), txPayment.IsWellFormed(suite.networkID, conf))
+
+	// the denied type is rejected
+	conf.OperationFilter = common.OperationFilter{Denied: []string{string(operation.TypeCreateAccount)}}
+	err := txCreateAccount.IsWellFormed(suite.networkID, conf)
+	require.Error(suite.T(), err)
+	require.Equal(suite.T(), errors.OperationTypeDisabled.Code, err.(*errors.Error).Code)
+	require.NoError(suite.T(), txPayment.IsWellFormed(suite.networkID, conf))
+
+	// only the allowed type is accepted
+	conf.OperationFilter = common.OperationFilter{Allowed: []string{string(operation.TypeCreateAccount)}}
+	require.NoError(suite.T(), txCreateAccount.IsWellFormed(suite.networkID, conf))
+	err = txPayment.IsWellFormed(suite.networkID, conf)
+	require.Error(suite.T(), err)
+	require.Equal(suite.T(), errors.OperationTypeDisabled.Code, err.(*errors.Error).Code)
+}
+
 func TestTransaction(t *testing.T) {
 	suite.Run(t, new(TestSuite))
 }
+
+func TestTransactionVerifyNetworkID(t *testing.T) {
+	networkID := []byte("sebak-unittest-network")
+	otherNetworkID := []byte("sebak-unittest-other-network")
+
+	kp, tx := TestMakeTransaction(networkID, 1)
+	require.NoError(t, tx.VerifyNetworkID(networkID))
+
+	tx.Sign(kp, otherNetworkID)
+	require.Equal(t, errors.MessageSignatureInvalid, tx.VerifyNetworkID(networkID))
+	require.NoError(t, tx.VerifyNetworkID(otherNetworkID))
+}